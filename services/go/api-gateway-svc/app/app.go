@@ -17,6 +17,7 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/shared/ready"
 	"golang.org/x/sync/errgroup"
 	"io/fs"
+	"time"
 )
 
 //go:embed db-migrations/*.sql
@@ -36,6 +37,14 @@ const kafkaGroupID = "mds-api-gateway-svc"
 
 const dbScope = "app"
 
+// loginFailureBaseDelay is the delay for responding to the first failed login
+// attempt. It is doubled for each further failed attempt.
+const loginFailureBaseDelay = 250 * time.Millisecond
+
+// loginFailureMaxDelay is the maximum delay for responding to failed login
+// attempts.
+const loginFailureMaxDelay = 4 * time.Second
+
 // Run the gateway.
 func Run(ctx context.Context) error {
 	c, err := parseConfigFromEnv()
@@ -91,25 +100,38 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup Kafka.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
 	eventPort := eventport.NewPort(kafkaConnector)
 	// Setup controller.
 	ctrl := &controller.Controller{
-		Logger:                logger.Named("controller"),
-		PublicAuthTokenSecret: c.PublicAuthTokenSecret,
-		AuthTokenSecret:       c.AuthTokenSecret,
-		Store:                 store.NewMall(redisClient),
-		DB:                    sqlDB,
-		Notifier:              eventPort,
+		Logger:                 logger.Named("controller"),
+		PublicAuthTokenSecret:  c.PublicAuthTokenSecret,
+		AuthTokenSecret:        c.AuthTokenSecret,
+		Store:                  store.NewMall(redisClient),
+		DB:                     sqlDB,
+		Notifier:               eventPort,
+		SessionIdleTimeout:     c.SessionIdleTimeout,
+		SessionAbsoluteTimeout: c.SessionAbsoluteTimeout,
+		LoginLockout: controller.LoginLockoutConfig{
+			MaxFailedAttemptsPerUsername:   c.LoginMaxFailedAttemptsPerUsername,
+			MaxFailedAttemptsPerRemoteAddr: c.LoginMaxFailedAttemptsPerRemoteAddr,
+			LockoutDuration:                c.LoginLockoutDuration,
+			BaseDelay:                      loginFailureBaseDelay,
+			MaxDelay:                       loginFailureMaxDelay,
+		},
 	}
+	// Run controller.
+	eg.Go(func() error {
+		return meh.NilOrWrap(ctrl.Run(egCtx), "run controller", nil)
+	})
 	// Run Kafka connector.
 	eg.Go(func() error {
 		logger := logger.Named("kafka-reader")
 		kafkaReader := kafkautil.NewReader(logger, c.KafkaAddr, kafkaGroupID,
-			[]event.Topic{event.PermissionsTopic, event.UsersTopic})
+			[]event.Topic{event.PermissionsTopic, event.UsersTopic, event.AuthTopic})
 		kafkaWriter := kafkautil.NewWriter(logger.Named("kafka-writer"), c.KafkaAddr)
 		err := kafkautil.RunConnector(egCtx, kafkaConnector, sqlDB, kafkaWriter, kafkaReader, eventPort.HandlerFn(ctrl))
 		if err != nil {
@@ -119,7 +141,7 @@ func Run(ctx context.Context) error {
 	})
 	// Serve public endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("public-endpoints"), c.ServeAddr, c.ForwardAddr, ctrl)
+		err := endpoints.Serve(egCtx, logger.Named("public-endpoints"), c.ServeAddr, c.ForwardAddr, c.TrustedProxies, ctrl)
 		if err != nil {
 			return meh.Wrap(err, "serve public endpoints", nil)
 		}
@@ -127,7 +149,8 @@ func Run(ctx context.Context) error {
 	})
 	// Serve internal endpoints
 	eg.Go(func() error {
-		err := endpoints.ServeInternal(egCtx, logger.Named("internal-endpoints"), c.InternalServeAddr, ctrl)
+		err := endpoints.ServeInternal(egCtx, logger.Named("internal-endpoints"), c.InternalServeAddr, c.AuthTokenSecret,
+			ctrl, kafkaConnector.DeadLetterAdmin(sqlDB))
 		if err != nil {
 			return meh.Wrap(err, "serve internal endpoints", nil)
 		}
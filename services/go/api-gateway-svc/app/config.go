@@ -6,6 +6,9 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/shared/logging"
 	"go.uber.org/zap"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -17,6 +20,20 @@ const (
 	envForwardAddr = "MDS_FORWARD_ADDR"
 	// envPublicAuthTokenSecret for config.PublicAuthTokenSecret.
 	envPublicAuthTokenSecret = "MDS_PUBLIC_AUTH_TOKEN_SECRET"
+	// envSessionIdleTimeout for config.SessionIdleTimeout.
+	envSessionIdleTimeout = "MDS_SESSION_IDLE_TIMEOUT"
+	// envSessionAbsoluteTimeout for config.SessionAbsoluteTimeout.
+	envSessionAbsoluteTimeout = "MDS_SESSION_ABSOLUTE_TIMEOUT"
+	// envLoginMaxFailedAttemptsPerUsername for
+	// config.LoginMaxFailedAttemptsPerUsername.
+	envLoginMaxFailedAttemptsPerUsername = "MDS_LOGIN_MAX_FAILED_ATTEMPTS_PER_USERNAME"
+	// envLoginMaxFailedAttemptsPerRemoteAddr for
+	// config.LoginMaxFailedAttemptsPerRemoteAddr.
+	envLoginMaxFailedAttemptsPerRemoteAddr = "MDS_LOGIN_MAX_FAILED_ATTEMPTS_PER_REMOTE_ADDR"
+	// envLoginLockoutDuration for config.LoginLockoutDuration.
+	envLoginLockoutDuration = "MDS_LOGIN_LOCKOUT_DURATION"
+	// envTrustedProxies for config.TrustedProxies.
+	envTrustedProxies = "MDS_TRUSTED_PROXIES"
 )
 
 type config struct {
@@ -29,6 +46,25 @@ type config struct {
 	ForwardAddr string `json:"forward_addr"`
 	// PublicAuthTokenSecret is the secret to use for signing public JWT tokens.
 	PublicAuthTokenSecret string `json:"public_auth_token_secret"`
+	// SessionIdleTimeout is the duration after which unused sessions expire.
+	SessionIdleTimeout time.Duration `json:"session_idle_timeout"`
+	// SessionAbsoluteTimeout is the duration after which sessions expire,
+	// regardless of being used.
+	SessionAbsoluteTimeout time.Duration `json:"session_absolute_timeout"`
+	// LoginMaxFailedAttemptsPerUsername is the number of failed login attempts
+	// for a username, after which login is locked. Zero or less disables the
+	// limit.
+	LoginMaxFailedAttemptsPerUsername int `json:"login_max_failed_attempts_per_username"`
+	// LoginMaxFailedAttemptsPerRemoteAddr is the number of failed login attempts
+	// from a remote address, after which login is locked. Zero or less disables
+	// the limit.
+	LoginMaxFailedAttemptsPerRemoteAddr int `json:"login_max_failed_attempts_per_remote_addr"`
+	// LoginLockoutDuration is the duration for which login is locked after too
+	// many failed attempts.
+	LoginLockoutDuration time.Duration `json:"login_lockout_duration"`
+	// TrustedProxies are the IP addresses or CIDRs of proxies, whose forwarding
+	// headers are used for determining client addresses.
+	TrustedProxies []string `json:"trusted_proxies"`
 }
 
 func parseConfigFromEnv() (config, error) {
@@ -56,5 +92,61 @@ func parseConfigFromEnv() (config, error) {
 		// For development purposes, we only log a warning.
 		logging.DebugLogger().Warn("no public auth token secret provided", zap.String("env", envPublicAuthTokenSecret))
 	}
+	// Session idle timeout.
+	sessionIdleTimeoutStr := os.Getenv(envSessionIdleTimeout)
+	if sessionIdleTimeoutStr == "" {
+		return config{}, meh.NewBadInputErr("missing session idle timeout", meh.Details{"env": envSessionIdleTimeout})
+	}
+	c.SessionIdleTimeout, err = time.ParseDuration(sessionIdleTimeoutStr)
+	if err != nil {
+		return config{}, meh.NewBadInputErrFromErr(err, "parse session idle timeout", meh.Details{"was": sessionIdleTimeoutStr})
+	}
+	// Session absolute timeout.
+	sessionAbsoluteTimeoutStr := os.Getenv(envSessionAbsoluteTimeout)
+	if sessionAbsoluteTimeoutStr == "" {
+		return config{}, meh.NewBadInputErr("missing session absolute timeout", meh.Details{"env": envSessionAbsoluteTimeout})
+	}
+	c.SessionAbsoluteTimeout, err = time.ParseDuration(sessionAbsoluteTimeoutStr)
+	if err != nil {
+		return config{}, meh.NewBadInputErrFromErr(err, "parse session absolute timeout", meh.Details{"was": sessionAbsoluteTimeoutStr})
+	}
+	// Login max failed attempts per username.
+	loginMaxFailedAttemptsPerUsernameStr := os.Getenv(envLoginMaxFailedAttemptsPerUsername)
+	if loginMaxFailedAttemptsPerUsernameStr == "" {
+		return config{}, meh.NewBadInputErr("missing login max failed attempts per username",
+			meh.Details{"env": envLoginMaxFailedAttemptsPerUsername})
+	}
+	c.LoginMaxFailedAttemptsPerUsername, err = strconv.Atoi(loginMaxFailedAttemptsPerUsernameStr)
+	if err != nil {
+		return config{}, meh.NewBadInputErrFromErr(err, "parse login max failed attempts per username",
+			meh.Details{"was": loginMaxFailedAttemptsPerUsernameStr})
+	}
+	// Login max failed attempts per remote address.
+	loginMaxFailedAttemptsPerRemoteAddrStr := os.Getenv(envLoginMaxFailedAttemptsPerRemoteAddr)
+	if loginMaxFailedAttemptsPerRemoteAddrStr == "" {
+		return config{}, meh.NewBadInputErr("missing login max failed attempts per remote address",
+			meh.Details{"env": envLoginMaxFailedAttemptsPerRemoteAddr})
+	}
+	c.LoginMaxFailedAttemptsPerRemoteAddr, err = strconv.Atoi(loginMaxFailedAttemptsPerRemoteAddrStr)
+	if err != nil {
+		return config{}, meh.NewBadInputErrFromErr(err, "parse login max failed attempts per remote address",
+			meh.Details{"was": loginMaxFailedAttemptsPerRemoteAddrStr})
+	}
+	// Login lockout duration.
+	loginLockoutDurationStr := os.Getenv(envLoginLockoutDuration)
+	if loginLockoutDurationStr == "" {
+		return config{}, meh.NewBadInputErr("missing login lockout duration", meh.Details{"env": envLoginLockoutDuration})
+	}
+	c.LoginLockoutDuration, err = time.ParseDuration(loginLockoutDurationStr)
+	if err != nil {
+		return config{}, meh.NewBadInputErrFromErr(err, "parse login lockout duration", meh.Details{"was": loginLockoutDurationStr})
+	}
+	// Trusted proxies.
+	for _, trustedProxy := range strings.Split(os.Getenv(envTrustedProxies), ",") {
+		trustedProxy = strings.TrimSpace(trustedProxy)
+		if trustedProxy != "" {
+			c.TrustedProxies = append(c.TrustedProxies, trustedProxy)
+		}
+	}
 	return c, nil
 }
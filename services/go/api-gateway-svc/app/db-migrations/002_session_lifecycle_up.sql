@@ -0,0 +1,36 @@
+-- Extend session tokens for session lifecycle management.
+
+alter table session_tokens
+    add id uuid not null default gen_random_uuid();
+
+alter table session_tokens
+    add constraint session_tokens_pk
+        primary key (id);
+
+alter table session_tokens
+    add refresh_token varchar;
+
+alter table session_tokens
+    add last_used_ts timestamp;
+
+update session_tokens
+set last_used_ts = created_ts;
+
+alter table session_tokens
+    alter column last_used_ts set not null;
+
+alter table session_tokens
+    add host varchar not null default '';
+
+alter table session_tokens
+    add user_agent varchar not null default '';
+
+alter table session_tokens
+    add remote_addr varchar not null default '';
+
+comment on column session_tokens.refresh_token is 'The token for refreshing the session. Rotated on each refresh.';
+comment on column session_tokens.last_used_ts is 'The timestamp of the last request made with the session, used for idle timeout.';
+
+create unique index session_tokens_refresh_token_ix on session_tokens (refresh_token);
+
+create index session_tokens_user_ix on session_tokens ("user");
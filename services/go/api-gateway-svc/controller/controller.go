@@ -5,9 +5,12 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"go.uber.org/zap"
+	"time"
 )
 
 // Controller manages all core operations of the gateway.
@@ -18,6 +21,20 @@ type Controller struct {
 	Store                 Store
 	DB                    pgutil.DBTxSupplier
 	Notifier              Notifier
+	// SessionIdleTimeout is the duration after which sessions expire, if not being
+	// used.
+	SessionIdleTimeout time.Duration
+	// SessionAbsoluteTimeout is the duration after which sessions expire,
+	// regardless of being used.
+	SessionAbsoluteTimeout time.Duration
+	// LoginLockout configures brute-force protection for logging in.
+	LoginLockout LoginLockoutConfig
+}
+
+// Run periodic operations until the given context is done.
+func (c *Controller) Run(lifetime context.Context) error {
+	c.runPeriodicExpiredSessionsCleanup(lifetime)
+	return nil
 }
 
 // Store is an interface for store.Mall.
@@ -25,18 +42,61 @@ type Store interface {
 	// PermissionsByUserID retrieves a permission.Permission list for the user with
 	// the given id.
 	PermissionsByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]permission.Permission, error)
-	// UserIDBySessionToken returns the user id for the given session token. If the
-	// token was not found, a meh.ErrNotFound will be returned.
-	UserIDBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (uuid.UUID, error)
-	// StoreSessionTokenForUser stores the given token for the user with the given
-	// id.
-	StoreSessionTokenForUser(ctx context.Context, tx pgx.Tx, token string, userID uuid.UUID) error
+	// SessionBySessionToken returns the store.Session for the given session token.
+	// If the token was not found, a meh.ErrNotFound will be returned.
+	SessionBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (store.Session, error)
+	// CreateSession creates the given store.CreateSession and returns the created
+	// store.Session.
+	CreateSession(ctx context.Context, tx pgx.Tx, create store.CreateSession) (store.Session, error)
+	// SessionByID retrieves the store.Session with the given id.
+	SessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (store.Session, error)
+	// SessionByRefreshTokenAndLock retrieves the store.Session with the given
+	// refresh token and locks it.
+	SessionByRefreshTokenAndLock(ctx context.Context, tx pgx.Tx, refreshToken string) (store.Session, error)
+	// SessionsByUser retrieves all sessions for the user with the given id.
+	SessionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]store.Session, error)
+	// UpdateSessionTokensByID sets the token and refresh token of the session with
+	// the given id and marks it as used at the given timestamp.
+	UpdateSessionTokensByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, token string, refreshToken string, usedAt time.Time) error
+	// TouchSessionByID marks the session with the given id as used at the given
+	// timestamp.
+	TouchSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, usedAt time.Time) error
+	// DeleteSessionByID deletes the session with the given id.
+	DeleteSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) error
+	// DeleteExpiredSessions deletes all sessions that were either created before
+	// createdBefore or last used before lastUsedBefore and returns them.
+	DeleteExpiredSessions(ctx context.Context, tx pgx.Tx, createdBefore time.Time, lastUsedBefore time.Time) ([]store.Session, error)
 	// GetAndDeleteUserIDBySessionToken gets and then deletes the mapping of the
 	// given session token to a user id.
 	GetAndDeleteUserIDBySessionToken(ctx context.Context, tx pgx.Tx, token string) (uuid.UUID, error)
 	// DeleteSessionTokensByUser deletes all session tokens for the given user from
 	// the database and from cache.
 	DeleteSessionTokensByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error
+	// AuthTokenBySession retrieves the cached auth.Token for the given
+	// store.Session. If none is cached, a meh.ErrNotFound is returned.
+	AuthTokenBySession(ctx context.Context, session store.Session) (auth.Token, error)
+	// AuthTokenGenerationByUser retrieves the current generation of cached auth
+	// tokens for the user with the given id.
+	AuthTokenGenerationByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// StoreAuthTokenForSession caches the given auth.Token for the given
+	// store.Session, if the generation of cached auth tokens for the user still
+	// equals the given one.
+	StoreAuthTokenForSession(ctx context.Context, session store.Session, token auth.Token, generation int64) error
+	// DeleteAuthTokensByUser removes all cached auth tokens for sessions of the
+	// user with the given id and increments the generation.
+	DeleteAuthTokensByUser(ctx context.Context, userID uuid.UUID) error
+	// IncrementLoginFailures increments the failed login counters for the given
+	// username and remote address and returns the new counts.
+	IncrementLoginFailures(ctx context.Context, username string, remoteAddr string, ttl time.Duration) (store.LoginFailureCounts, error)
+	// DecrementLoginFailures decrements the failed login counters for the given
+	// username and remote address.
+	DecrementLoginFailures(ctx context.Context, username string, remoteAddr string) error
+	// ResetLoginFailuresByUsername resets the failed login counter for the given
+	// username.
+	ResetLoginFailuresByUsername(ctx context.Context, username string) error
+	// ResetLoginFailuresByRemoteAddr resets the failed login counter for the given
+	// remote address.
+	ResetLoginFailuresByRemoteAddr(ctx context.Context, remoteAddr string) error
 	// PassByUsername retrieves the hashed password for the user with the given
 	// username.
 	PassByUsername(ctx context.Context, tx pgx.Tx, username string) ([]byte, error)
@@ -62,4 +122,9 @@ type Notifier interface {
 	NotifyUserLoggedIn(ctx context.Context, tx pgx.Tx, userID uuid.UUID, username string, requestMetadata AuthRequestMetadata) error
 	// NotifyUserLoggedOut notifies that a user has logged out.
 	NotifyUserLoggedOut(ctx context.Context, tx pgx.Tx, userID uuid.UUID, username string, requestMetadata AuthRequestMetadata) error
+	// NotifyUserLoginFailed notifies about a failed login attempt.
+	NotifyUserLoginFailed(ctx context.Context, tx pgx.Tx, username string, userID uuid.NullUUID,
+		reason event.UserLoginFailedReason, requestMetadata AuthRequestMetadata) error
+	// NotifySessionRevoked notifies that the given store.Session was revoked.
+	NotifySessionRevoked(ctx context.Context, tx pgx.Tx, session store.Session, reason event.SessionRevokedReason, revokedBy uuid.NullUUID) error
 }
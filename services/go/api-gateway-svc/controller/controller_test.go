@@ -5,6 +5,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
@@ -31,10 +33,19 @@ func NewMockController() *ControllerMock {
 		Notifier: &NotifierMock{},
 	}
 	ctrl.Ctrl = &Controller{
-		Logger:   ctrl.Logger,
-		DB:       ctrl.DB,
-		Store:    ctrl.Store,
-		Notifier: ctrl.Notifier,
+		Logger:                 ctrl.Logger,
+		DB:                     ctrl.DB,
+		Store:                  ctrl.Store,
+		Notifier:               ctrl.Notifier,
+		SessionIdleTimeout:     time.Hour,
+		SessionAbsoluteTimeout: 24 * time.Hour,
+		LoginLockout: LoginLockoutConfig{
+			MaxFailedAttemptsPerUsername:   5,
+			MaxFailedAttemptsPerRemoteAddr: 20,
+			LockoutDuration:                15 * time.Minute,
+			BaseDelay:                      time.Millisecond,
+			MaxDelay:                       8 * time.Millisecond,
+		},
 	}
 	return ctrl
 }
@@ -44,8 +55,50 @@ type StoreMock struct {
 	mock.Mock
 }
 
-func (m *StoreMock) StoreSessionTokenForUser(ctx context.Context, tx pgx.Tx, token string, userID uuid.UUID) error {
-	return m.Called(ctx, tx, token, userID).Error(0)
+func (m *StoreMock) CreateSession(ctx context.Context, tx pgx.Tx, create store.CreateSession) (store.Session, error) {
+	args := m.Called(ctx, tx, create)
+	return args.Get(0).(store.Session), args.Error(1)
+}
+
+func (m *StoreMock) SessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (store.Session, error) {
+	args := m.Called(ctx, tx, sessionID)
+	return args.Get(0).(store.Session), args.Error(1)
+}
+
+func (m *StoreMock) SessionByRefreshTokenAndLock(ctx context.Context, tx pgx.Tx, refreshToken string) (store.Session, error) {
+	args := m.Called(ctx, tx, refreshToken)
+	return args.Get(0).(store.Session), args.Error(1)
+}
+
+func (m *StoreMock) SessionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]store.Session, error) {
+	args := m.Called(ctx, tx, userID)
+	var sessions []store.Session
+	if a := args.Get(0); a != nil {
+		sessions = a.([]store.Session)
+	}
+	return sessions, args.Error(1)
+}
+
+func (m *StoreMock) UpdateSessionTokensByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, token string,
+	refreshToken string, usedAt time.Time) error {
+	return m.Called(ctx, tx, sessionID, token, refreshToken, usedAt).Error(0)
+}
+
+func (m *StoreMock) TouchSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, usedAt time.Time) error {
+	return m.Called(ctx, tx, sessionID, usedAt).Error(0)
+}
+
+func (m *StoreMock) DeleteSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) error {
+	return m.Called(ctx, tx, sessionID).Error(0)
+}
+
+func (m *StoreMock) DeleteExpiredSessions(ctx context.Context, tx pgx.Tx, createdBefore time.Time, lastUsedBefore time.Time) ([]store.Session, error) {
+	args := m.Called(ctx, tx, createdBefore, lastUsedBefore)
+	var sessions []store.Session
+	if a := args.Get(0); a != nil {
+		sessions = a.([]store.Session)
+	}
+	return sessions, args.Error(1)
 }
 
 func (m *StoreMock) GetAndDeleteUserIDBySessionToken(ctx context.Context, tx pgx.Tx, token string) (uuid.UUID, error) {
@@ -84,9 +137,44 @@ func (m *StoreMock) PermissionsByUserID(ctx context.Context, tx pgx.Tx, userID u
 	return p, args.Error(1)
 }
 
-func (m *StoreMock) UserIDBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (uuid.UUID, error) {
+func (m *StoreMock) SessionBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (store.Session, error) {
 	args := m.Called(ctx, txSupplier, token)
-	return args.Get(0).(uuid.UUID), args.Error(1)
+	return args.Get(0).(store.Session), args.Error(1)
+}
+
+func (m *StoreMock) AuthTokenBySession(ctx context.Context, session store.Session) (auth.Token, error) {
+	args := m.Called(ctx, session)
+	return args.Get(0).(auth.Token), args.Error(1)
+}
+
+func (m *StoreMock) AuthTokenGenerationByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *StoreMock) StoreAuthTokenForSession(ctx context.Context, session store.Session, token auth.Token, generation int64) error {
+	return m.Called(ctx, session, token, generation).Error(0)
+}
+
+func (m *StoreMock) DeleteAuthTokensByUser(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *StoreMock) IncrementLoginFailures(ctx context.Context, username string, remoteAddr string, ttl time.Duration) (store.LoginFailureCounts, error) {
+	args := m.Called(ctx, username, remoteAddr, ttl)
+	return args.Get(0).(store.LoginFailureCounts), args.Error(1)
+}
+
+func (m *StoreMock) DecrementLoginFailures(ctx context.Context, username string, remoteAddr string) error {
+	return m.Called(ctx, username, remoteAddr).Error(0)
+}
+
+func (m *StoreMock) ResetLoginFailuresByUsername(ctx context.Context, username string) error {
+	return m.Called(ctx, username).Error(0)
+}
+
+func (m *StoreMock) ResetLoginFailuresByRemoteAddr(ctx context.Context, remoteAddr string) error {
+	return m.Called(ctx, remoteAddr).Error(0)
 }
 
 func (m *StoreMock) PassByUsername(ctx context.Context, tx pgx.Tx, username string) ([]byte, error) {
@@ -120,3 +208,13 @@ func (m *NotifierMock) NotifyUserLoggedOut(ctx context.Context, tx pgx.Tx, userI
 	requestMetadata AuthRequestMetadata) error {
 	return m.Called(ctx, tx, userID, username, requestMetadata).Error(0)
 }
+
+func (m *NotifierMock) NotifyUserLoginFailed(ctx context.Context, tx pgx.Tx, username string, userID uuid.NullUUID,
+	reason event.UserLoginFailedReason, requestMetadata AuthRequestMetadata) error {
+	return m.Called(ctx, tx, username, userID, reason, requestMetadata).Error(0)
+}
+
+func (m *NotifierMock) NotifySessionRevoked(ctx context.Context, tx pgx.Tx, session store.Session, reason event.SessionRevokedReason,
+	revokedBy uuid.NullUUID) error {
+	return m.Called(ctx, tx, session, reason, revokedBy).Error(0)
+}
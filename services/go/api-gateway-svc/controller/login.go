@@ -7,7 +7,11 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 )
 
@@ -21,47 +25,115 @@ type AuthRequestMetadata struct {
 
 // Login tries to log in the user with the given username and password. If login
 // fails, false is returned as second value. Otherwise, the first return value
-// will be the user id and the second one the assigned JWT-token. If the user is
-// inactive, a meh.ErrNotFound is returned.
-func (c *Controller) Login(ctx context.Context, username string, pass string, requestMetadata AuthRequestMetadata) (uuid.UUID, string, bool, error) {
-	var ok bool
+// will be the user id and the second one the assigned SessionTokens. If the user
+// is unknown or inactive, a meh.ErrNotFound is returned. Each attempt is
+// reserved as failed before checking credentials and released if it succeeds or
+// fails because of an internal error.
+// Failed attempts are responded to with progressive delay. If too many attempts
+// failed for the username or remote address, a meh.ErrForbidden is returned.
+func (c *Controller) Login(ctx context.Context, username string, pass string, requestMetadata AuthRequestMetadata) (uuid.UUID, SessionTokens, bool, error) {
 	var userID uuid.UUID
-	var token string
-	var err error
-	// Load actual password for username.
-	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
-		user, err := c.Store.UserWithPassByUsername(ctx, tx, username)
+	var tokens SessionTokens
+	var failedReason event.UserLoginFailedReason
+	reservationReleased := false
+	// Reserve the attempt before checking credentials. Otherwise, parallel
+	// requests could bypass the lockout.
+	remoteHost := remoteHostFromAddr(requestMetadata.RemoteAddr)
+	failureCounts, err := c.Store.IncrementLoginFailures(ctx, username, remoteHost, c.LoginLockout.LockoutDuration)
+	if err != nil {
+		return uuid.Nil, SessionTokens{}, false, meh.Wrap(err, "increment login failures", meh.Details{
+			"username":    username,
+			"remote_host": remoteHost,
+		})
+	}
+	if c.loginLockedOut(failureCounts) {
+		// Release the reservation as locked attempts do not prolong the lockout.
+		err = c.Store.DecrementLoginFailures(ctx, username, remoteHost)
 		if err != nil {
-			return meh.Wrap(err, "user by username", meh.Details{"username": username})
+			return uuid.Nil, SessionTokens{}, false, meh.Wrap(err, "decrement login failures", meh.Details{
+				"username":    username,
+				"remote_host": remoteHost,
+			})
 		}
-		if !user.IsActive {
-			return meh.NewNotFoundErr("user inactive", nil)
+		err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+			err := c.Notifier.NotifyUserLoginFailed(ctx, tx, username, uuid.NullUUID{}, event.UserLoginFailedReasonLockedOut, requestMetadata)
+			if err != nil {
+				return meh.Wrap(err, "notify user login failed", nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return uuid.Nil, SessionTokens{}, false, meh.Wrap(err, "run in tx", nil)
 		}
-		userID = user.ID
-		// Check password.
-		passOK, err := auth.PasswordOK(user.Pass, pass)
+		return uuid.Nil, SessionTokens{}, false, meh.NewForbiddenErr("login locked because of too many failed attempts", meh.Details{
+			"username":                       username,
+			"remote_host":                    remoteHost,
+			"failed_attempts_by_username":    failureCounts.ByUsername - 1,
+			"failed_attempts_by_remote_addr": failureCounts.ByRemoteAddr - 1,
+		})
+	}
+	// Load actual password for username.
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		user, err := c.Store.UserWithPassByUsername(ctx, tx, username)
 		if err != nil {
-			return meh.Wrap(err, "check if password ok", nil)
+			if meh.ErrorCode(err) != meh.ErrNotFound {
+				return meh.Wrap(err, "user by username", meh.Details{"username": username})
+			}
+			failedReason = event.UserLoginFailedReasonUnknownUser
+		} else if !user.IsActive {
+			failedReason = event.UserLoginFailedReasonInactive
+		} else {
+			// Check password.
+			passOK, err := auth.PasswordOK(user.Pass, pass)
+			if err != nil {
+				return meh.Wrap(err, "check if password ok", nil)
+			}
+			if !passOK {
+				failedReason = event.UserLoginFailedReasonWrongPass
+			}
 		}
-		// If password wrong, we are done.
-		if !passOK {
-			ok = false
+		// If failed, we keep the reserved attempt, record it and are done.
+		if failedReason != "" {
+			var failedUserID uuid.NullUUID
+			if failedReason != event.UserLoginFailedReasonUnknownUser {
+				failedUserID = nulls.NewUUID(user.ID)
+			}
+			err = c.recordFailedLogin(ctx, tx, username, failedUserID, failedReason, requestMetadata, failureCounts)
+			if err != nil {
+				return meh.Wrap(err, "record failed login", meh.Details{"username": username})
+			}
 			return nil
 		}
-		ok = true
+		userID = user.ID
 		// Generate public session token.
-		token, err = generatePublicSessionToken(username, c.PublicAuthTokenSecret)
+		token, err := generatePublicSessionToken(username, c.PublicAuthTokenSecret)
 		if err != nil {
 			return meh.Wrap(err, "generate public session token", meh.Details{"username": username})
 		}
-		// Store public session token.
-		err = c.Store.StoreSessionTokenForUser(ctx, tx, token, user.ID)
+		refreshToken, err := generateRefreshToken()
+		if err != nil {
+			return meh.Wrap(err, "generate refresh token", nil)
+		}
+		// Store session.
+		session, err := c.Store.CreateSession(ctx, tx, store.CreateSession{
+			User:         user.ID,
+			Token:        token,
+			RefreshToken: refreshToken,
+			Host:         requestMetadata.Host,
+			UserAgent:    requestMetadata.UserAgent,
+			RemoteAddr:   requestMetadata.RemoteAddr,
+		})
 		if err != nil {
-			return meh.Wrap(err, "store username by session token", meh.Details{
+			return meh.Wrap(err, "create session", meh.Details{
 				"session_token": token,
 				"username":      username,
 			})
 		}
+		tokens = SessionTokens{
+			AccessToken:  token,
+			RefreshToken: refreshToken,
+			ExpiresAt:    c.sessionExpiresAt(session),
+		}
 		// Notify.
 		err = c.Notifier.NotifyUserLoggedIn(ctx, tx, user.ID, user.Username, requestMetadata)
 		if err != nil {
@@ -71,12 +143,48 @@ func (c *Controller) Login(ctx context.Context, username string, pass string, re
 				"request_metadata": requestMetadata,
 			})
 		}
+		// Release the reserved attempt and forget previous failed attempts for the
+		// username.
+		err = c.Store.DecrementLoginFailures(ctx, username, remoteHost)
+		if err != nil {
+			return meh.Wrap(err, "decrement login failures", meh.Details{
+				"username":    username,
+				"remote_host": remoteHost,
+			})
+		}
+		reservationReleased = true
+		err = c.Store.ResetLoginFailuresByUsername(ctx, username)
+		if err != nil {
+			return meh.Wrap(err, "reset login failures by username", meh.Details{"username": username})
+		}
 		return nil
 	})
 	if err != nil {
-		return uuid.Nil, "", false, meh.Wrap(err, "run in tx", nil)
+		if failedReason == "" && !reservationReleased {
+			// Internal errors must not count as failed attempts. Otherwise, users would be
+			// locked out because of errors they are not responsible for.
+			releaseErr := c.Store.DecrementLoginFailures(ctx, username, remoteHost)
+			if releaseErr != nil {
+				mehlog.Log(c.Logger, meh.Wrap(releaseErr, "decrement login failures", meh.Details{
+					"username":    username,
+					"remote_host": remoteHost,
+				}))
+			}
+		}
+		return uuid.Nil, SessionTokens{}, false, meh.Wrap(err, "run in tx", nil)
+	}
+	switch failedReason {
+	case "":
+		return userID, tokens, true, nil
+	case event.UserLoginFailedReasonUnknownUser:
+		c.awaitLoginFailureDelay(ctx, failureCounts.ByUsername)
+		return uuid.Nil, SessionTokens{}, false, meh.NewNotFoundErr("user not found", meh.Details{"username": username})
+	case event.UserLoginFailedReasonInactive:
+		c.awaitLoginFailureDelay(ctx, failureCounts.ByUsername)
+		return uuid.Nil, SessionTokens{}, false, meh.NewNotFoundErr("user inactive", meh.Details{"username": username})
 	}
-	return userID, token, ok, nil
+	c.awaitLoginFailureDelay(ctx, failureCounts.ByUsername)
+	return uuid.Nil, SessionTokens{}, false, nil
 }
 
 // generatePublicSessionToken generates and signs the JWT token, that will be
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"go.uber.org/zap"
+	"net"
+	"time"
+)
+
+// LoginLockoutConfig configures brute-force protection for logging in.
+type LoginLockoutConfig struct {
+	// MaxFailedAttemptsPerUsername is the number of failed login attempts for a
+	// username, after which logging in with it is locked. A value of zero or less
+	// disables the limit.
+	MaxFailedAttemptsPerUsername int
+	// MaxFailedAttemptsPerRemoteAddr is the number of failed login attempts from a
+	// remote address, after which logging in from it is locked. This is usually
+	// higher than MaxFailedAttemptsPerUsername as multiple clients might share the
+	// same address. A value of zero or less disables the limit, which is required
+	// if remote addresses cannot be determined reliably.
+	MaxFailedAttemptsPerRemoteAddr int
+	// LockoutDuration is the duration after which failed attempts are forgotten
+	// if no other attempts occur. This is also the lockout duration.
+	LockoutDuration time.Duration
+	// BaseDelay is the delay for responding to the first failed attempt. It is
+	// doubled for each following one.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay for responding to failed attempts.
+	MaxDelay time.Duration
+}
+
+// remoteHostFromAddr strips the port from the given remote address. If the
+// address does not contain a port, it is returned as it is.
+func remoteHostFromAddr(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// loginLockedOut checks whether the given store.LoginFailureCounts, including
+// the currently reserved attempt, exceed the limits from Controller.LoginLockout.
+func (c *Controller) loginLockedOut(counts store.LoginFailureCounts) bool {
+	if c.LoginLockout.MaxFailedAttemptsPerUsername > 0 &&
+		counts.ByUsername > c.LoginLockout.MaxFailedAttemptsPerUsername {
+		return true
+	}
+	return c.LoginLockout.MaxFailedAttemptsPerRemoteAddr > 0 &&
+		counts.ByRemoteAddr > c.LoginLockout.MaxFailedAttemptsPerRemoteAddr
+}
+
+// loginFailureDelay returns the delay for responding to a failed login attempt
+// with the given number of failed attempts. The delay starts with
+// LoginLockoutConfig.BaseDelay and is doubled for each failed attempt until
+// reaching LoginLockoutConfig.MaxDelay.
+func (c *Controller) loginFailureDelay(failedAttempts int) time.Duration {
+	delay := c.LoginLockout.BaseDelay
+	for i := 1; i < failedAttempts && delay < c.LoginLockout.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.LoginLockout.MaxDelay {
+		delay = c.LoginLockout.MaxDelay
+	}
+	return delay
+}
+
+// awaitLoginFailureDelay waits for the delay, returned by loginFailureDelay for
+// the given number of failed attempts, or until the given context is done.
+func (c *Controller) awaitLoginFailureDelay(ctx context.Context, failedAttempts int) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(c.loginFailureDelay(failedAttempts)):
+	}
+}
+
+// recordFailedLogin notifies about a failed login attempt for the given
+// username. The given store.LoginFailureCounts are the ones from reserving the
+// attempt and used for logging if following attempts will be locked.
+func (c *Controller) recordFailedLogin(ctx context.Context, tx pgx.Tx, username string, userID uuid.NullUUID,
+	reason event.UserLoginFailedReason, requestMetadata AuthRequestMetadata, counts store.LoginFailureCounts) error {
+	err := c.Notifier.NotifyUserLoginFailed(ctx, tx, username, userID, reason, requestMetadata)
+	if err != nil {
+		return meh.Wrap(err, "notify user login failed", meh.Details{
+			"username": username,
+			"reason":   reason,
+		})
+	}
+	nextCounts := store.LoginFailureCounts{
+		ByUsername:   counts.ByUsername + 1,
+		ByRemoteAddr: counts.ByRemoteAddr + 1,
+	}
+	if c.loginLockedOut(nextCounts) {
+		c.Logger.Warn("login locked because of too many failed attempts",
+			zap.String("username", username),
+			zap.String("remote_host", remoteHostFromAddr(requestMetadata.RemoteAddr)),
+			zap.Int("failed_attempts_by_username", counts.ByUsername),
+			zap.Int("failed_attempts_by_remote_addr", counts.ByRemoteAddr))
+	}
+	return nil
+}
+
+// UnlockLogin lifts login lockouts for the given username and/or remote
+// address. At least one of them must be provided. This requires the
+// permission.UnlockUserLogin permission.
+func (c *Controller) UnlockLogin(ctx context.Context, publicToken string, username string, remoteAddr string) error {
+	if username == "" && remoteAddr == "" {
+		return meh.NewBadInputErr("neither username nor remote address provided", nil)
+	}
+	token, _, err := c.authenticatedSession(ctx, publicToken)
+	if err != nil {
+		return meh.Wrap(err, "authenticated session", nil)
+	}
+	err = auth.AssurePermission(token, permission.UnlockUserLogin())
+	if err != nil {
+		return meh.Wrap(err, "assure permission", nil)
+	}
+	if username != "" {
+		err = c.Store.ResetLoginFailuresByUsername(ctx, username)
+		if err != nil {
+			return meh.Wrap(err, "reset login failures by username", meh.Details{"username": username})
+		}
+	}
+	if remoteAddr != "" {
+		remoteHost := remoteHostFromAddr(remoteAddr)
+		err = c.Store.ResetLoginFailuresByRemoteAddr(ctx, remoteHost)
+		if err != nil {
+			return meh.Wrap(err, "reset login failures by remote address", meh.Details{"remote_host": remoteHost})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+func Test_remoteHostFromAddr(t *testing.T) {
+	assert.Equal(t, "10.0.0.12", remoteHostFromAddr("10.0.0.12:48211"), "should strip port")
+	assert.Equal(t, "::1", remoteHostFromAddr("[::1]:48211"), "should strip port for ipv6")
+	assert.Equal(t, "10.0.0.12", remoteHostFromAddr("10.0.0.12"), "should keep address without port")
+}
+
+func TestController_loginFailureDelay(t *testing.T) {
+	c := &Controller{
+		LoginLockout: LoginLockoutConfig{
+			BaseDelay: 100 * time.Millisecond,
+			MaxDelay:  time.Second,
+		},
+	}
+	assert.Equal(t, 100*time.Millisecond, c.loginFailureDelay(1), "should return base delay for first attempt")
+	assert.Equal(t, 200*time.Millisecond, c.loginFailureDelay(2), "should double delay")
+	assert.Equal(t, 800*time.Millisecond, c.loginFailureDelay(4), "should double delay")
+	assert.Equal(t, time.Second, c.loginFailureDelay(5), "should cap at max delay")
+	assert.Equal(t, time.Second, c.loginFailureDelay(100), "should cap at max delay")
+}
+
+// ControllerUnlockLoginSuite tests Controller.UnlockLogin.
+type ControllerUnlockLoginSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	sampleToken      string
+	sampleUser       store.UserWithPass
+	sampleSession    store.Session
+	sampleUsername   string
+	sampleRemoteAddr string
+}
+
+func (suite *ControllerUnlockLoginSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.sampleToken = "knee"
+	suite.sampleUser = store.UserWithPass{
+		User: store.User{
+			ID:       testutil.NewUUIDV4(),
+			Username: "bunch",
+			IsActive: true,
+		},
+	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      suite.sampleToken,
+		CreatedAt:  time.Now().Add(-time.Hour),
+		LastUsedAt: time.Now(),
+	}
+	suite.sampleUsername = "fever"
+	suite.sampleRemoteAddr = "10.0.0.12"
+}
+
+func (suite *ControllerUnlockLoginSuite) expectAuthenticated(permissions []permission.Permission) {
+	suite.ctrl.Store.On("SessionBySessionToken", mock.Anything, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", mock.Anything, suite.sampleSession).
+		Return(auth.Token{
+			UserID:          suite.sampleUser.ID,
+			Username:        suite.sampleUser.Username,
+			IsAuthenticated: true,
+			Permissions:     permissions,
+		}, nil)
+}
+
+func (suite *ControllerUnlockLoginSuite) TestNothingToUnlock() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, "", "")
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUnlockLoginSuite) TestNotAuthenticated() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(store.Session{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, suite.sampleUsername, "")
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrUnauthorized, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUnlockLoginSuite) TestMissingPermission() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated([]permission.Permission{})
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, suite.sampleUsername, "")
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUnlockLoginSuite) TestResetByUsernameFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated([]permission.Permission{{Name: permission.UnlockUserLoginPermissionName}})
+	suite.ctrl.Store.On("ResetLoginFailuresByUsername", timeout, suite.sampleUsername).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, suite.sampleUsername, suite.sampleRemoteAddr)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUnlockLoginSuite) TestResetByRemoteAddrFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated([]permission.Permission{{Name: permission.UnlockUserLoginPermissionName}})
+	suite.ctrl.Store.On("ResetLoginFailuresByRemoteAddr", timeout, suite.sampleRemoteAddr).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, "", suite.sampleRemoteAddr)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUnlockLoginSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated([]permission.Permission{{Name: permission.UnlockUserLoginPermissionName}})
+	suite.ctrl.Store.On("ResetLoginFailuresByUsername", timeout, suite.sampleUsername).
+		Return(nil)
+	suite.ctrl.Store.On("ResetLoginFailuresByRemoteAddr", timeout, suite.sampleRemoteAddr).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UnlockLogin(timeout, suite.sampleToken, suite.sampleUsername, suite.sampleRemoteAddr+":3921")
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UnlockLogin(t *testing.T) {
+	suite.Run(t, new(ControllerUnlockLoginSuite))
+}
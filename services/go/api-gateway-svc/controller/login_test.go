@@ -4,14 +4,17 @@ import (
 	"errors"
 	"github.com/gofrs/uuid"
 	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // Test_generatePublicSessionToken tests generatePublicSessionToken.
@@ -31,6 +34,7 @@ type ControllerLoginSuite struct {
 	sampleUserPassHashed  []byte
 	sampleRequestMetadata AuthRequestMetadata
 	sampleUser            store.UserWithPass
+	sampleSession         store.Session
 }
 
 func (suite *ControllerLoginSuite) SetupSuite() {
@@ -59,11 +63,27 @@ func (suite *ControllerLoginSuite) SetupTest() {
 		},
 		Pass: suite.sampleUserPassHashed,
 	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      "bird",
+		CreatedAt:  time.Now().UTC(),
+		LastUsedAt: time.Now().UTC(),
+		Host:       suite.sampleRequestMetadata.Host,
+		UserAgent:  suite.sampleRequestMetadata.UserAgent,
+		RemoteAddr: suite.sampleRequestMetadata.RemoteAddr,
+	}
 }
 
 func (suite *ControllerLoginSuite) TestTxFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.BeginFail = true
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
@@ -76,9 +96,14 @@ func (suite *ControllerLoginSuite) TestTxFail() {
 
 func (suite *ControllerLoginSuite) TestRetrieveUserFromStoreFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(store.UserWithPass{}, errors.New("sad life"))
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -92,18 +117,26 @@ func (suite *ControllerLoginSuite) TestRetrieveUserFromStoreFail() {
 
 func (suite *ControllerLoginSuite) TestUserInactive() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	user := suite.sampleUser
 	user.IsActive = false
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(user, nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		nulls.NewUUID(user.ID), event.UserLoginFailedReasonInactive, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
 		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, "nonono", suite.sampleRequestMetadata)
 		suite.Require().Error(err, "should fail")
 		suite.Equal(meh.ErrNotFound, meh.ErrorCode(err), "should return correct error code")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
 	}()
 
 	wait()
@@ -111,11 +144,16 @@ func (suite *ControllerLoginSuite) TestUserInactive() {
 
 func (suite *ControllerLoginSuite) TestPasswordCheckFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	user := suite.sampleUser
 	user.Pass = []byte("meow")
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(user, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -129,28 +167,41 @@ func (suite *ControllerLoginSuite) TestPasswordCheckFail() {
 
 func (suite *ControllerLoginSuite) TestPasswordMismatch() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(suite.sampleUser, nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		nulls.NewUUID(suite.sampleUser.ID), event.UserLoginFailedReasonWrongPass, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
 		_, _, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, "nonono", suite.sampleRequestMetadata)
 		suite.Require().NoError(err, "should not fail")
 		suite.False(ok, "should not return ok")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerLoginSuite) TestStoreSessionTokenFail() {
+func (suite *ControllerLoginSuite) TestCreateSessionFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(suite.sampleUser, nil)
-	suite.ctrl.Store.On("StoreSessionTokenForUser", timeout, suite.ctrl.DB.Tx[0], mock.Anything, suite.sampleUser.ID).
-		Return(errors.New("sad life"))
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.Anything).
+		Return(store.Session{}, errors.New("sad life"))
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -164,11 +215,21 @@ func (suite *ControllerLoginSuite) TestStoreSessionTokenFail() {
 
 func (suite *ControllerLoginSuite) TestNotifyFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(suite.sampleUser, nil)
-	suite.ctrl.Store.On("StoreSessionTokenForUser", timeout, suite.ctrl.DB.Tx[0], mock.Anything, suite.sampleUser.ID).
-		Return(nil)
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.MatchedBy(func(create store.CreateSession) bool {
+		return create.User == suite.sampleUser.ID && create.Token != "" && create.RefreshToken != "" &&
+			create.Host == suite.sampleRequestMetadata.Host &&
+			create.UserAgent == suite.sampleRequestMetadata.UserAgent &&
+			create.RemoteAddr == suite.sampleRequestMetadata.RemoteAddr
+	})).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	suite.ctrl.Notifier.On("NotifyUserLoggedIn", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID, suite.sampleUsername, suite.sampleRequestMetadata).
 		Return(errors.New("sad life"))
@@ -185,11 +246,22 @@ func (suite *ControllerLoginSuite) TestNotifyFail() {
 
 func (suite *ControllerLoginSuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
 		Return(suite.sampleUser, nil)
-	suite.ctrl.Store.On("StoreSessionTokenForUser", timeout, suite.ctrl.DB.Tx[0], mock.Anything, suite.sampleUser.ID).
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.MatchedBy(func(create store.CreateSession) bool {
+		return create.User == suite.sampleUser.ID && create.Token != "" && create.RefreshToken != "" &&
+			create.Host == suite.sampleRequestMetadata.Host &&
+			create.UserAgent == suite.sampleRequestMetadata.UserAgent &&
+			create.RemoteAddr == suite.sampleRequestMetadata.RemoteAddr
+	})).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
 		Return(nil)
+	suite.ctrl.Store.On("ResetLoginFailuresByUsername", timeout, suite.sampleUsername).Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	suite.ctrl.Notifier.On("NotifyUserLoggedIn", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID, suite.sampleUsername, suite.sampleRequestMetadata).
 		Return(nil)
@@ -197,11 +269,305 @@ func (suite *ControllerLoginSuite) TestOK() {
 
 	go func() {
 		defer cancel()
-		userID, token, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		userID, tokens, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
 		suite.Require().NoError(err, "should not fail")
 		suite.True(ok, "should return ok")
 		suite.Equal(suite.sampleUser.ID, userID, "should return correct user id")
-		suite.NotEmpty(token, "should return token")
+		suite.NotEmpty(tokens.AccessToken, "should return access token")
+		suite.NotEmpty(tokens.RefreshToken, "should return refresh token")
+		suite.Equal(suite.sampleSession.LastUsedAt.Add(suite.ctrl.Ctrl.SessionIdleTimeout), tokens.ExpiresAt,
+			"should return correct expiry")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestIncrementLoginFailuresFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestLockedOutByUsername() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerUsername + 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		uuid.NullUUID{}, event.UserLoginFailedReasonLockedOut, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(ok, "should not return ok")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestLockedOutByRemoteAddr() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerRemoteAddr + 1}, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		uuid.NullUUID{}, event.UserLoginFailedReasonLockedOut, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestLockedOutNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerUsername + 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		uuid.NullUUID{}, event.UserLoginFailedReasonLockedOut, suite.sampleRequestMetadata).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().Error(err, "should fail")
+		suite.NotEqual(meh.ErrForbidden, meh.ErrorCode(err), "should not return forbidden")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestUnknownUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(store.UserWithPass{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		uuid.NullUUID{}, event.UserLoginFailedReasonUnknownUser, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrNotFound, meh.ErrorCode(err), "should return correct error code")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestNotifyLoginFailedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(suite.sampleUser, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		nulls.NewUUID(suite.sampleUser.ID), event.UserLoginFailedReasonWrongPass, suite.sampleRequestMetadata).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, "nonono", suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestResetLoginFailuresFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.Anything).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil)
+	suite.ctrl.Store.On("ResetLoginFailuresByUsername", timeout, suite.sampleUsername).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoggedIn", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID, suite.sampleUsername, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestLockedOutDecrementLoginFailuresFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerUsername + 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().Error(err, "should fail")
+		suite.NotEqual(meh.ErrForbidden, meh.ErrorCode(err), "should not return forbidden")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestRemoteAddrLimitDisabled() {
+	suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerRemoteAddr = 0
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1000}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(suite.sampleUser, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoginFailed", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername,
+		nulls.NewUUID(suite.sampleUser.ID), event.UserLoginFailedReasonWrongPass, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, "nonono", suite.sampleRequestMetadata)
+		suite.Require().NoError(err, "should not fail")
+		suite.False(ok, "should not return ok")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestUsernameLimitDisabled() {
+	suite.ctrl.Ctrl.LoginLockout.MaxFailedAttemptsPerUsername = 0
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1000, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.Anything).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(nil)
+	suite.ctrl.Store.On("ResetLoginFailuresByUsername", timeout, suite.sampleUsername).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoggedIn", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID, suite.sampleUsername, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, ok, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return ok")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestDecrementLoginFailuresFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("CreateSession", timeout, suite.ctrl.DB.Tx[0], mock.Anything).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(errors.New("sad life")).Twice()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserLoggedIn", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID, suite.sampleUsername, suite.sampleRequestMetadata).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLoginSuite) TestReleaseReservationAfterInternalErrorFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("IncrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr,
+		suite.ctrl.Ctrl.LoginLockout.LockoutDuration).
+		Return(store.LoginFailureCounts{ByUsername: 1, ByRemoteAddr: 1}, nil)
+	suite.ctrl.Store.On("UserWithPassByUsername", timeout, suite.ctrl.DB.Tx[0], suite.sampleUsername).
+		Return(store.UserWithPass{}, errors.New("sad life"))
+	suite.ctrl.Store.On("DecrementLoginFailures", timeout, suite.sampleUsername, suite.sampleRequestMetadata.RemoteAddr).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, _, err := suite.ctrl.Ctrl.Login(timeout, suite.sampleUsername, suite.sampleUserPass, suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
 	}()
 
 	wait()
@@ -8,7 +8,8 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 )
 
-// UpdatePermissionsByUser updates the permissions for the given user.
+// UpdatePermissionsByUser updates the permissions for the given user and
+// invalidates cached auth tokens for the user.
 func (c *Controller) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, updatedPermissions []permission.Permission) error {
 	// Update in store.
 	err := c.Store.UpdatePermissionsByUser(ctx, tx, userID, updatedPermissions)
@@ -18,5 +19,9 @@ func (c *Controller) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, use
 			"permissions": updatedPermissions,
 		})
 	}
+	err = c.invalidateAuthTokensByUser(ctx, tx, userID)
+	if err != nil {
+		return meh.Wrap(err, "invalidate auth tokens by user", meh.Details{"user_id": userID})
+	}
 	return nil
 }
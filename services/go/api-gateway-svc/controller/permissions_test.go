@@ -43,16 +43,35 @@ func (suite *ControllerUpdatePermissionsByUserSuite) TestStoreUpdateFail() {
 	wait()
 }
 
+func (suite *ControllerUpdatePermissionsByUserSuite) TestDeleteAuthTokensFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, tx, suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.sampleUserID).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, tx, suite.sampleUserID, suite.sampleUpdatedPermissions)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
 func (suite *ControllerUpdatePermissionsByUserSuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	tx := &testutil.DBTx{}
 	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, tx, suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.sampleUserID).Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
 		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, tx, suite.sampleUserID, suite.sampleUpdatedPermissions)
 		suite.NoError(err, "should not fail")
+		// Once before and once after commit.
+		suite.ctrl.Store.AssertNumberOfCalls(suite.T(), "DeleteAuthTokensByUser", 2)
 	}()
 
 	wait()
@@ -4,15 +4,18 @@ import (
 	"context"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"math/rand"
+	"time"
 )
 
 // Proxy checks if the user is logged in and generates an internal authentication
 // token, which will be passed with the forwarded request.
 func (c *Controller) Proxy(ctx context.Context, publicToken string) (string, error) {
-	authToken, err := c.gatherProxyToken(ctx, publicToken)
+	authToken, _, err := c.gatherProxyToken(ctx, publicToken)
 	if err != nil {
 		return "", meh.Wrap(err, "gather proxy token", nil)
 	}
@@ -33,25 +36,72 @@ func (c *Controller) Proxy(ctx context.Context, publicToken string) (string, err
 
 // gatherProxyToken builds an auth.Token based on user details. Only the random
 // salt needs to be set, and then it can be signed in Proxy. This is mainly for
-// better code readability.
-func (c *Controller) gatherProxyToken(ctx context.Context, publicToken string) (auth.Token, error) {
+// better code readability. If authenticated, the associated store.Session is
+// returned as well. Expired sessions are treated as not authenticated. Resolved
+// tokens are cached per session, so that user details and permissions only
+// need to be retrieved from the database if not cached.
+func (c *Controller) gatherProxyToken(ctx context.Context, publicToken string) (auth.Token, store.Session, error) {
 	var authToken auth.Token
 	// If no token is provided, we have nothing to do.
 	if publicToken == "" {
-		return authToken, nil
+		return authToken, store.Session{}, nil
 	}
-	// Retrieve user details.
-	userID, err := c.Store.UserIDBySessionToken(ctx, c.DB, publicToken)
+	// Retrieve session.
+	session, err := c.Store.SessionBySessionToken(ctx, c.DB, publicToken)
 	if err != nil {
 		if meh.ErrorCode(err) != meh.ErrNotFound {
-			return auth.Token{}, meh.Wrap(err, "username by session token", meh.Details{"token": publicToken})
+			return auth.Token{}, store.Session{}, meh.Wrap(err, "session by session token", meh.Details{"token": publicToken})
 		}
 		// Not found -> not authenticated.
-		return authToken, nil
+		return authToken, store.Session{}, nil
+	}
+	now := time.Now()
+	if c.sessionExpired(session, now) {
+		// Expired -> not authenticated. Removal is done in periodic cleanup.
+		return authToken, store.Session{}, nil
+	}
+	// Lookup in cache. If the cache is not available, we fall back to the database.
+	isCached := true
+	authToken, err = c.Store.AuthTokenBySession(ctx, session)
+	if err != nil {
+		if meh.ErrorCode(err) != meh.ErrNotFound {
+			mehlog.Log(c.Logger, meh.Wrap(err, "auth token by session", meh.Details{"session_id": session.ID}))
+		}
+		isCached = false
+	}
+	needsTouch := now.Sub(session.LastUsedAt) > sessionTouchInterval
+	if isCached && !needsTouch {
+		return authToken, session, nil
+	}
+	userID := session.User
+	// Retrieve the cache generation before reading from the database, so that
+	// tokens are not cached, if they were invalidated in the meantime.
+	canCache := false
+	var cacheGeneration int64
+	if !isCached {
+		cacheGeneration, err = c.Store.AuthTokenGenerationByUser(ctx, userID)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "auth token generation by user", meh.Details{"user_id": userID}))
+		} else {
+			canCache = true
+		}
 	}
-	authToken.UserID = userID
-	authToken.IsAuthenticated = true
 	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		// Keep session alive.
+		if needsTouch {
+			err := c.Store.TouchSessionByID(ctx, tx, session.ID, now)
+			if err != nil {
+				return meh.Wrap(err, "touch session", meh.Details{"session_id": session.ID})
+			}
+			session.LastUsedAt = now
+		}
+		if isCached {
+			return nil
+		}
+		authToken = auth.Token{
+			UserID:          userID,
+			IsAuthenticated: true,
+		}
 		// Retrieve user details.
 		user, err := c.Store.UserWithPassByID(ctx, tx, userID)
 		if err != nil {
@@ -68,7 +118,13 @@ func (c *Controller) gatherProxyToken(ctx context.Context, publicToken string) (
 		return nil
 	})
 	if err != nil {
-		return auth.Token{}, meh.Wrap(err, "run in tx", nil)
+		return auth.Token{}, store.Session{}, meh.Wrap(err, "run in tx", nil)
+	}
+	if canCache {
+		err = c.Store.StoreAuthTokenForSession(ctx, session, authToken, cacheGeneration)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "store auth token for session", meh.Details{"session_id": session.ID}))
+		}
 	}
-	return authToken, nil
+	return authToken, session, nil
 }
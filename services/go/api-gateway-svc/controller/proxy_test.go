@@ -2,15 +2,16 @@ package controller
 
 import (
 	"errors"
-	"github.com/gofrs/uuid"
 	"github.com/lefinal/meh"
 	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // ControllerProxySuite tests Controller.Proxy.
@@ -19,6 +20,7 @@ type ControllerProxySuite struct {
 	ctrl              *ControllerMock
 	sampleToken       string
 	sampleUser        store.UserWithPass
+	sampleSession     store.Session
 	samplePermissions []permission.Permission
 }
 
@@ -33,6 +35,13 @@ func (suite *ControllerProxySuite) SetupTest() {
 		},
 		Pass: []byte("gold"),
 	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      suite.sampleToken,
+		CreatedAt:  time.Now().Add(-10 * time.Minute),
+		LastUsedAt: time.Now(),
+	}
 	suite.samplePermissions = []permission.Permission{{
 		Name:    permission.UpdatePermissionsPermissionName,
 		Options: nulls.JSONRawMessage{},
@@ -60,10 +69,10 @@ func (suite *ControllerProxySuite) TestEmptyToken() {
 	wait()
 }
 
-func (suite *ControllerProxySuite) TestRetrieveUserIDFail() {
+func (suite *ControllerProxySuite) TestRetrieveSessionFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(uuid.Nil, errors.New("sad life"))
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(store.Session{}, errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -77,8 +86,44 @@ func (suite *ControllerProxySuite) TestRetrieveUserIDFail() {
 
 func (suite *ControllerProxySuite) TestSessionTokenNotFound() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(uuid.Nil, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(store.Session{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.False(authToken.IsAuthenticated)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestSessionIdleTimeout() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.LastUsedAt = time.Now().Add(-suite.ctrl.Ctrl.SessionIdleTimeout)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.False(authToken.IsAuthenticated)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestSessionAbsoluteTimeout() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.CreatedAt = time.Now().Add(-suite.ctrl.Ctrl.SessionAbsoluteTimeout)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -95,8 +140,12 @@ func (suite *ControllerProxySuite) TestSessionTokenNotFound() {
 func (suite *ControllerProxySuite) TestTxFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.DB.BeginFail = true
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(suite.sampleUser.ID, nil)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -111,8 +160,12 @@ func (suite *ControllerProxySuite) TestTxFail() {
 func (suite *ControllerProxySuite) TestRetrieveUserDetailsFromStoreFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(suite.sampleUser.ID, nil)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
 	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
 		Return(store.UserWithPass{}, errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -130,8 +183,12 @@ func (suite *ControllerProxySuite) TestRetrieveUserDetailsFromStoreFail() {
 func (suite *ControllerProxySuite) TestRetrievePermissionsFromStoreFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(suite.sampleUser.ID, nil)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
 	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
 		Return(suite.sampleUser, nil)
 	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
@@ -151,12 +208,17 @@ func (suite *ControllerProxySuite) TestRetrievePermissionsFromStoreFail() {
 func (suite *ControllerProxySuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
-	suite.ctrl.Store.On("UserIDBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
-		Return(suite.sampleUser.ID, nil)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
 	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
 		Return(suite.sampleUser, nil)
 	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
 		Return(suite.samplePermissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, suite.sampleSession, mock.Anything, int64(3)).Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -176,6 +238,191 @@ func (suite *ControllerProxySuite) TestOK() {
 	wait()
 }
 
+func (suite *ControllerProxySuite) TestTouchSessionFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.LastUsedAt = time.Now().Add(-2 * sessionTouchInterval)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
+	suite.ctrl.Store.On("TouchSessionByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleSession.ID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not have committed tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestOKWithTouch() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.LastUsedAt = time.Now().Add(-2 * sessionTouchInterval)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
+	suite.ctrl.Store.On("TouchSessionByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleSession.ID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.samplePermissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, mock.Anything, mock.Anything, int64(3)).Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should have committed tx")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.True(authToken.IsAuthenticated, "should have set is-authenticated in auth token correctly")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestRetrieveCachedAuthTokenFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, errors.New("sad life"))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.samplePermissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, suite.sampleSession, mock.Anything, int64(3)).Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.Equal(suite.samplePermissions, authToken.Permissions, "should have retrieved permissions from database")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestRetrieveAuthTokenGenerationFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(0), errors.New("sad life"))
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.samplePermissions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "StoreAuthTokenForSession")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestStoreAuthTokenInCacheFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(3), nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.samplePermissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, suite.sampleSession, mock.Anything, int64(3)).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestOKCached() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	cachedToken := auth.Token{
+		UserID:          suite.sampleUser.ID,
+		Username:        suite.sampleUser.Username,
+		IsAuthenticated: true,
+		IsAdmin:         suite.sampleUser.IsAdmin,
+		Permissions:     suite.samplePermissions,
+	}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(cachedToken, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.NotEmpty(authToken.RandomSalt, "should have set random salt in auth token")
+		authToken.RandomSalt = nil
+		suite.Equal(cachedToken, authToken, "should return cached auth token")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerProxySuite) TestOKCachedWithTouch() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.LastUsedAt = time.Now().Add(-2 * sessionTouchInterval)
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{UserID: suite.sampleUser.ID, IsAuthenticated: true}, nil)
+	suite.ctrl.Store.On("TouchSessionByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleSession.ID, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		authTokenStr, err := suite.ctrl.Ctrl.Proxy(timeout, suite.sampleToken)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should have committed tx")
+		authToken := suite.parseAuthToken(authTokenStr)
+		suite.True(authToken.IsAuthenticated, "should have set is-authenticated in auth token correctly")
+	}()
+
+	wait()
+}
+
 func TestController_Proxy(t *testing.T) {
 	suite.Run(t, new(ControllerProxySuite))
 }
@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// sessionTouchInterval is the minimum duration between updating the
+// last-used-timestamp of a session. This avoids writing to the database for
+// each request.
+const sessionTouchInterval = time.Minute
+
+// expiredSessionsCleanupInterval is the interval in which expired sessions are
+// removed.
+const expiredSessionsCleanupInterval = time.Minute
+
+// SessionTokens are the tokens handed out to the client for a session.
+type SessionTokens struct {
+	// AccessToken is the public session token for making requests.
+	AccessToken string
+	// RefreshToken is used for refreshing the session and retrieving new tokens.
+	RefreshToken string
+	// ExpiresAt is the timestamp when the session expires if not being used.
+	ExpiresAt time.Time
+}
+
+// Session holds publicly visible details regarding a session.
+type Session struct {
+	// ID identifies the session.
+	ID uuid.UUID
+	// User is the id of the user the session belongs to.
+	User uuid.UUID
+	// CreatedAt is the timestamp when the session was created (logged in).
+	CreatedAt time.Time
+	// LastUsedAt is the timestamp when the session was last used.
+	LastUsedAt time.Time
+	// ExpiresAt is the timestamp when the session expires if not being used.
+	ExpiresAt time.Time
+	// Host from the login request.
+	Host string
+	// UserAgent from the login request.
+	UserAgent string
+	// RemoteAddr from the login request.
+	RemoteAddr string
+	// IsCurrent describes whether this is the session, the request was made with.
+	IsCurrent bool
+}
+
+// generateRefreshToken generates a random refresh token.
+func generateRefreshToken() (string, error) {
+	raw := make([]byte, 64)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", meh.NewInternalErrFromErr(err, "read random bytes", nil)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// sessionExpiresAt returns the timestamp when the given store.Session expires,
+// based on Controller.SessionIdleTimeout and Controller.SessionAbsoluteTimeout.
+func (c *Controller) sessionExpiresAt(session store.Session) time.Time {
+	idleExpiry := session.LastUsedAt.Add(c.SessionIdleTimeout)
+	absoluteExpiry := session.CreatedAt.Add(c.SessionAbsoluteTimeout)
+	if absoluteExpiry.Before(idleExpiry) {
+		return absoluteExpiry
+	}
+	return idleExpiry
+}
+
+// sessionExpired checks whether the given store.Session is expired at the given
+// timestamp.
+func (c *Controller) sessionExpired(session store.Session, now time.Time) bool {
+	return !now.Before(c.sessionExpiresAt(session))
+}
+
+// RefreshSession refreshes the session with the given refresh token. New tokens
+// are generated and the session is marked as used. If the refresh token is
+// unknown or the session is expired, a meh.ErrUnauthorized is returned.
+func (c *Controller) RefreshSession(ctx context.Context, refreshToken string) (uuid.UUID, SessionTokens, error) {
+	var userID uuid.UUID
+	var tokens SessionTokens
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		session, err := c.Store.SessionByRefreshTokenAndLock(ctx, tx, refreshToken)
+		if err != nil {
+			if meh.ErrorCode(err) == meh.ErrNotFound {
+				return meh.NewUnauthorizedErrFromErr(err, "unknown refresh token", nil)
+			}
+			return meh.Wrap(err, "session by refresh token", nil)
+		}
+		now := time.Now()
+		if c.sessionExpired(session, now) {
+			return meh.NewUnauthorizedErr("session expired", meh.Details{"session_id": session.ID})
+		}
+		user, err := c.Store.UserWithPassByID(ctx, tx, session.User)
+		if err != nil {
+			return meh.Wrap(err, "user by id", meh.Details{"user_id": session.User})
+		}
+		// Generate new tokens.
+		newToken, err := generatePublicSessionToken(user.Username, c.PublicAuthTokenSecret)
+		if err != nil {
+			return meh.Wrap(err, "generate public session token", meh.Details{"username": user.Username})
+		}
+		newRefreshToken, err := generateRefreshToken()
+		if err != nil {
+			return meh.Wrap(err, "generate refresh token", nil)
+		}
+		err = c.Store.UpdateSessionTokensByID(ctx, tx, session.ID, newToken, newRefreshToken, now)
+		if err != nil {
+			return meh.Wrap(err, "update session tokens", meh.Details{"session_id": session.ID})
+		}
+		session.LastUsedAt = now
+		userID = session.User
+		tokens = SessionTokens{
+			AccessToken:  newToken,
+			RefreshToken: newRefreshToken,
+			ExpiresAt:    c.sessionExpiresAt(session),
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, SessionTokens{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return userID, tokens, nil
+}
+
+// authenticatedSession resolves the given public token to an auth.Token and the
+// associated store.Session. If not authenticated, a meh.ErrUnauthorized is
+// returned.
+func (c *Controller) authenticatedSession(ctx context.Context, publicToken string) (auth.Token, store.Session, error) {
+	token, session, err := c.gatherProxyToken(ctx, publicToken)
+	if err != nil {
+		return auth.Token{}, store.Session{}, meh.Wrap(err, "gather proxy token", nil)
+	}
+	if !token.IsAuthenticated {
+		return auth.Token{}, store.Session{}, meh.NewUnauthorizedErr("not authenticated", nil)
+	}
+	return token, session, nil
+}
+
+// SessionsByUser retrieves all sessions for the user with the given id. If the
+// user id is not set, the sessions of the user, the public token belongs to,
+// are retrieved. Retrieving sessions of other users requires the
+// permission.ManageAnyUserSessions permission.
+func (c *Controller) SessionsByUser(ctx context.Context, publicToken string, userID uuid.NullUUID) ([]Session, error) {
+	token, currentSession, err := c.authenticatedSession(ctx, publicToken)
+	if err != nil {
+		return nil, meh.Wrap(err, "authenticated session", nil)
+	}
+	if !userID.Valid {
+		userID = nulls.NewUUID(token.UserID)
+	}
+	if userID.UUID != token.UserID {
+		err = auth.AssurePermission(token, permission.ManageAnyUserSessions())
+		if err != nil {
+			return nil, meh.Wrap(err, "assure permission", nil)
+		}
+	}
+	var sessions []Session
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		sSessions, err := c.Store.SessionsByUser(ctx, tx, userID.UUID)
+		if err != nil {
+			return meh.Wrap(err, "sessions by user", meh.Details{"user_id": userID.UUID})
+		}
+		now := time.Now()
+		sessions = make([]Session, 0, len(sSessions))
+		for _, sSession := range sSessions {
+			if c.sessionExpired(sSession, now) {
+				continue
+			}
+			sessions = append(sessions, Session{
+				ID:         sSession.ID,
+				User:       sSession.User,
+				CreatedAt:  sSession.CreatedAt,
+				LastUsedAt: sSession.LastUsedAt,
+				ExpiresAt:  c.sessionExpiresAt(sSession),
+				Host:       sSession.Host,
+				UserAgent:  sSession.UserAgent,
+				RemoteAddr: sSession.RemoteAddr,
+				IsCurrent:  sSession.ID == currentSession.ID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes the session with the given id. Revoking sessions of
+// other users requires the permission.ManageAnyUserSessions permission.
+func (c *Controller) RevokeSession(ctx context.Context, publicToken string, sessionID uuid.UUID) error {
+	token, _, err := c.authenticatedSession(ctx, publicToken)
+	if err != nil {
+		return meh.Wrap(err, "authenticated session", nil)
+	}
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		session, err := c.Store.SessionByID(ctx, tx, sessionID)
+		if err != nil {
+			return meh.Wrap(err, "session by id", meh.Details{"session_id": sessionID})
+		}
+		if session.User != token.UserID {
+			err = auth.AssurePermission(token, permission.ManageAnyUserSessions())
+			if err != nil {
+				return meh.Wrap(err, "assure permission", nil)
+			}
+		}
+		err = c.Store.DeleteSessionByID(ctx, tx, sessionID)
+		if err != nil {
+			return meh.Wrap(err, "delete session", meh.Details{"session_id": sessionID})
+		}
+		err = c.Notifier.NotifySessionRevoked(ctx, tx, session, event.SessionRevokedReasonRevoked, nulls.NewUUID(token.UserID))
+		if err != nil {
+			return meh.Wrap(err, "notify session revoked", meh.Details{"session_id": sessionID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// runPeriodicExpiredSessionsCleanup periodically removes expired sessions until
+// the given context is done. Cleanup interval is taken from
+// expiredSessionsCleanupInterval.
+func (c *Controller) runPeriodicExpiredSessionsCleanup(lifetime context.Context) {
+	for {
+		err := c.deleteExpiredSessions(lifetime)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "delete expired sessions", nil))
+		}
+		select {
+		case <-lifetime.Done():
+			return
+		case <-time.After(expiredSessionsCleanupInterval):
+		}
+	}
+}
+
+// deleteExpiredSessions deletes all sessions that exceeded either
+// Controller.SessionIdleTimeout or Controller.SessionAbsoluteTimeout and
+// notifies about them being revoked.
+func (c *Controller) deleteExpiredSessions(ctx context.Context) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		now := time.Now()
+		createdBefore := now.Add(-c.SessionAbsoluteTimeout)
+		lastUsedBefore := now.Add(-c.SessionIdleTimeout)
+		deleted, err := c.Store.DeleteExpiredSessions(ctx, tx, createdBefore, lastUsedBefore)
+		if err != nil {
+			return meh.Wrap(err, "delete expired sessions", meh.Details{
+				"created_before":   createdBefore,
+				"last_used_before": lastUsedBefore,
+			})
+		}
+		for _, session := range deleted {
+			err = c.Notifier.NotifySessionRevoked(ctx, tx, session, event.SessionRevokedReasonExpired, uuid.NullUUID{})
+			if err != nil {
+				return meh.Wrap(err, "notify session revoked", meh.Details{"session_id": session.ID})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
@@ -0,0 +1,643 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// TestController_sessionExpiresAt tests Controller.sessionExpiresAt.
+func TestController_sessionExpiresAt(t *testing.T) {
+	c := &Controller{
+		SessionIdleTimeout:     time.Hour,
+		SessionAbsoluteTimeout: 24 * time.Hour,
+	}
+	createdAt := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	t.Run("idle", func(t *testing.T) {
+		got := c.sessionExpiresAt(store.Session{
+			CreatedAt:  createdAt,
+			LastUsedAt: createdAt.Add(2 * time.Hour),
+		})
+		assert.Equal(t, createdAt.Add(3*time.Hour), got, "should return correct expiry")
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		got := c.sessionExpiresAt(store.Session{
+			CreatedAt:  createdAt,
+			LastUsedAt: createdAt.Add(23*time.Hour + 30*time.Minute),
+		})
+		assert.Equal(t, createdAt.Add(24*time.Hour), got, "should return correct expiry")
+	})
+}
+
+// ControllerRefreshSessionSuite tests Controller.RefreshSession.
+type ControllerRefreshSessionSuite struct {
+	suite.Suite
+	ctrl               *ControllerMock
+	sampleRefreshToken string
+	sampleSession      store.Session
+	sampleUser         store.UserWithPass
+}
+
+func (suite *ControllerRefreshSessionSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.sampleRefreshToken = "reveal"
+	suite.sampleUser = store.UserWithPass{
+		User: store.User{
+			ID:       testutil.NewUUIDV4(),
+			Username: "invent",
+			IsActive: true,
+		},
+	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      "tooth",
+		CreatedAt:  time.Now().Add(-2 * time.Hour),
+		LastUsedAt: time.Now().Add(-10 * time.Minute),
+	}
+}
+
+func (suite *ControllerRefreshSessionSuite) TestTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRefreshSessionSuite) TestUnknownRefreshToken() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("SessionByRefreshTokenAndLock", timeout, suite.ctrl.DB.Tx[0], suite.sampleRefreshToken).
+		Return(store.Session{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrUnauthorized, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRefreshSessionSuite) TestRetrieveSessionFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("SessionByRefreshTokenAndLock", timeout, suite.ctrl.DB.Tx[0], suite.sampleRefreshToken).
+		Return(store.Session{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Error(err, "should fail")
+		suite.NotEqual(meh.ErrUnauthorized, meh.ErrorCode(err), "should not return unauthorized")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRefreshSessionSuite) TestSessionExpired() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleSession.LastUsedAt = time.Now().Add(-suite.ctrl.Ctrl.SessionIdleTimeout)
+	suite.ctrl.Store.On("SessionByRefreshTokenAndLock", timeout, suite.ctrl.DB.Tx[0], suite.sampleRefreshToken).
+		Return(suite.sampleSession, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrUnauthorized, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRefreshSessionSuite) TestUpdateTokensFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("SessionByRefreshTokenAndLock", timeout, suite.ctrl.DB.Tx[0], suite.sampleRefreshToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("UpdateSessionTokensByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleSession.ID,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRefreshSessionSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	var newToken string
+	var newRefreshToken string
+	suite.ctrl.Store.On("SessionByRefreshTokenAndLock", timeout, suite.ctrl.DB.Tx[0], suite.sampleRefreshToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("UpdateSessionTokensByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleSession.ID,
+		mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			newToken = args.String(3)
+			newRefreshToken = args.String(4)
+		}).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		userID, tokens, err := suite.ctrl.Ctrl.RefreshSession(timeout, suite.sampleRefreshToken)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleUser.ID, userID, "should return correct user id")
+		suite.NotEmpty(tokens.AccessToken, "should return access token")
+		suite.NotEqual(suite.sampleSession.Token, tokens.AccessToken, "should return new access token")
+		suite.Equal(newToken, tokens.AccessToken, "should store returned access token")
+		suite.NotEqual(suite.sampleRefreshToken, tokens.RefreshToken, "should return new refresh token")
+		suite.Equal(newRefreshToken, tokens.RefreshToken, "should store returned refresh token")
+		suite.True(tokens.ExpiresAt.After(time.Now()), "should return expiry in the future")
+	}()
+
+	wait()
+}
+
+func TestController_RefreshSession(t *testing.T) {
+	suite.Run(t, new(ControllerRefreshSessionSuite))
+}
+
+// ControllerSessionsByUserSuite tests Controller.SessionsByUser.
+type ControllerSessionsByUserSuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	sampleToken    string
+	sampleUser     store.UserWithPass
+	sampleSession  store.Session
+	sampleSessions []store.Session
+}
+
+func (suite *ControllerSessionsByUserSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}, {}}
+	suite.sampleToken = "shake"
+	suite.sampleUser = store.UserWithPass{
+		User: store.User{
+			ID:       testutil.NewUUIDV4(),
+			Username: "glory",
+			IsActive: true,
+		},
+	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      suite.sampleToken,
+		CreatedAt:  time.Now().Add(-time.Hour),
+		LastUsedAt: time.Now(),
+	}
+	suite.sampleSessions = []store.Session{
+		suite.sampleSession,
+		{
+			ID:         testutil.NewUUIDV4(),
+			User:       suite.sampleUser.ID,
+			Token:      "sharp",
+			CreatedAt:  time.Now().Add(-2 * time.Hour),
+			LastUsedAt: time.Now().Add(-30 * time.Minute),
+			Host:       "thread",
+			UserAgent:  "mile",
+			RemoteAddr: "meal",
+		},
+		{
+			ID:         testutil.NewUUIDV4(),
+			User:       suite.sampleUser.ID,
+			Token:      "reduce",
+			CreatedAt:  time.Now().Add(-3 * time.Hour),
+			LastUsedAt: time.Now().Add(-2 * time.Hour),
+		},
+	}
+}
+
+func (suite *ControllerSessionsByUserSuite) expectAuthenticated(timeout context.Context, permissions []permission.Permission) {
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(0), nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(permissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, suite.sampleSession, mock.Anything, int64(0)).Return(nil)
+}
+
+func (suite *ControllerSessionsByUserSuite) TestNotAuthenticated() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(store.Session{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.SessionsByUser(timeout, suite.sampleToken, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrUnauthorized, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSessionsByUserSuite) TestOtherUserForbidden() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.SessionsByUser(timeout, suite.sampleToken, nulls.NewUUID(testutil.NewUUIDV4()))
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSessionsByUserSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionsByUser", timeout, suite.ctrl.DB.Tx[1], suite.sampleUser.ID).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.SessionsByUser(timeout, suite.sampleToken, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSessionsByUserSuite) TestOtherUserOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	otherUserID := testutil.NewUUIDV4()
+	suite.expectAuthenticated(timeout, []permission.Permission{{Name: permission.ManageAnyUserSessionsPermissionName}})
+	suite.ctrl.Store.On("SessionsByUser", timeout, suite.ctrl.DB.Tx[1], otherUserID).
+		Return([]store.Session{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.SessionsByUser(timeout, suite.sampleToken, nulls.NewUUID(otherUserID))
+		suite.Require().NoError(err, "should not fail")
+		suite.Empty(got, "should return correct sessions")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSessionsByUserSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionsByUser", timeout, suite.ctrl.DB.Tx[1], suite.sampleUser.ID).
+		Return(suite.sampleSessions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.SessionsByUser(timeout, suite.sampleToken, uuid.NullUUID{})
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[1].IsCommitted, "should commit tx")
+		suite.Require().Len(got, 2, "should skip expired sessions")
+		suite.Equal(suite.sampleSessions[0].ID, got[0].ID, "should return correct first session")
+		suite.True(got[0].IsCurrent, "should mark current session")
+		suite.Equal(Session{
+			ID:         suite.sampleSessions[1].ID,
+			User:       suite.sampleSessions[1].User,
+			CreatedAt:  suite.sampleSessions[1].CreatedAt,
+			LastUsedAt: suite.sampleSessions[1].LastUsedAt,
+			ExpiresAt:  suite.sampleSessions[1].LastUsedAt.Add(suite.ctrl.Ctrl.SessionIdleTimeout),
+			Host:       suite.sampleSessions[1].Host,
+			UserAgent:  suite.sampleSessions[1].UserAgent,
+			RemoteAddr: suite.sampleSessions[1].RemoteAddr,
+			IsCurrent:  false,
+		}, got[1], "should return correct second session")
+	}()
+
+	wait()
+}
+
+func TestController_SessionsByUser(t *testing.T) {
+	suite.Run(t, new(ControllerSessionsByUserSuite))
+}
+
+// ControllerRevokeSessionSuite tests Controller.RevokeSession.
+type ControllerRevokeSessionSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	sampleToken   string
+	sampleUser    store.UserWithPass
+	sampleSession store.Session
+	sampleRevoke  store.Session
+}
+
+func (suite *ControllerRevokeSessionSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}, {}}
+	suite.sampleToken = "grave"
+	suite.sampleUser = store.UserWithPass{
+		User: store.User{
+			ID:       testutil.NewUUIDV4(),
+			Username: "pupil",
+			IsActive: true,
+		},
+	}
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      suite.sampleToken,
+		CreatedAt:  time.Now().Add(-time.Hour),
+		LastUsedAt: time.Now(),
+	}
+	suite.sampleRevoke = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       suite.sampleUser.ID,
+		Token:      "pierce",
+		CreatedAt:  time.Now().Add(-2 * time.Hour),
+		LastUsedAt: time.Now().Add(-30 * time.Minute),
+	}
+}
+
+func (suite *ControllerRevokeSessionSuite) expectAuthenticated(timeout context.Context, permissions []permission.Permission) {
+	suite.ctrl.Store.On("SessionBySessionToken", timeout, suite.ctrl.DB, suite.sampleToken).
+		Return(suite.sampleSession, nil)
+	suite.ctrl.Store.On("AuthTokenBySession", timeout, suite.sampleSession).
+		Return(auth.Token{}, meh.NewNotFoundErr("not found", nil))
+	suite.ctrl.Store.On("AuthTokenGenerationByUser", timeout, suite.sampleUser.ID).
+		Return(int64(0), nil)
+	suite.ctrl.Store.On("UserWithPassByID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(suite.sampleUser, nil)
+	suite.ctrl.Store.On("PermissionsByUserID", timeout, suite.ctrl.DB.Tx[0], suite.sampleUser.ID).
+		Return(permissions, nil)
+	suite.ctrl.Store.On("StoreAuthTokenForSession", timeout, suite.sampleSession, mock.Anything, int64(0)).Return(nil)
+}
+
+func (suite *ControllerRevokeSessionSuite) TestRetrieveSessionFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(store.Session{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRevokeSessionSuite) TestOtherUserForbidden() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleRevoke.User = testutil.NewUUIDV4()
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(suite.sampleRevoke, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.ctrl.DB.Tx[1].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRevokeSessionSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(suite.sampleRevoke, nil)
+	suite.ctrl.Store.On("DeleteSessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[1].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRevokeSessionSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(suite.sampleRevoke, nil)
+	suite.ctrl.Store.On("DeleteSessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifySessionRevoked", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke,
+		event.SessionRevokedReasonRevoked, nulls.NewUUID(suite.sampleUser.ID)).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[1].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRevokeSessionSuite) TestOtherUserOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleRevoke.User = testutil.NewUUIDV4()
+	suite.expectAuthenticated(timeout, []permission.Permission{{Name: permission.ManageAnyUserSessionsPermissionName}})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(suite.sampleRevoke, nil)
+	suite.ctrl.Store.On("DeleteSessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifySessionRevoked", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke,
+		event.SessionRevokedReasonRevoked, nulls.NewUUID(suite.sampleUser.ID)).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[1].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRevokeSessionSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.expectAuthenticated(timeout, []permission.Permission{})
+	suite.ctrl.Store.On("SessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(suite.sampleRevoke, nil)
+	suite.ctrl.Store.On("DeleteSessionByID", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke.ID).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifySessionRevoked", timeout, suite.ctrl.DB.Tx[1], suite.sampleRevoke,
+		event.SessionRevokedReasonRevoked, nulls.NewUUID(suite.sampleUser.ID)).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RevokeSession(timeout, suite.sampleToken, suite.sampleRevoke.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[1].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_RevokeSession(t *testing.T) {
+	suite.Run(t, new(ControllerRevokeSessionSuite))
+}
+
+// ControllerDeleteExpiredSessionsSuite tests Controller.deleteExpiredSessions.
+type ControllerDeleteExpiredSessionsSuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	sampleSessions []store.Session
+}
+
+func (suite *ControllerDeleteExpiredSessionsSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.sampleSessions = []store.Session{
+		{
+			ID:   testutil.NewUUIDV4(),
+			User: testutil.NewUUIDV4(),
+		},
+		{
+			ID:   testutil.NewUUIDV4(),
+			User: testutil.NewUUIDV4(),
+		},
+	}
+}
+
+func (suite *ControllerDeleteExpiredSessionsSuite) TestTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.deleteExpiredSessions(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteExpiredSessionsSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteExpiredSessions", timeout, suite.ctrl.DB.Tx[0], mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.deleteExpiredSessions(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteExpiredSessionsSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteExpiredSessions", timeout, suite.ctrl.DB.Tx[0], mock.Anything, mock.Anything).
+		Return(suite.sampleSessions, nil)
+	suite.ctrl.Notifier.On("NotifySessionRevoked", timeout, suite.ctrl.DB.Tx[0], mock.Anything,
+		event.SessionRevokedReasonExpired, uuid.NullUUID{}).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.deleteExpiredSessions(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteExpiredSessionsSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteExpiredSessions", timeout, suite.ctrl.DB.Tx[0],
+		mock.MatchedBy(func(createdBefore time.Time) bool {
+			return createdBefore.Before(time.Now().Add(-suite.ctrl.Ctrl.SessionAbsoluteTimeout + time.Minute))
+		}),
+		mock.MatchedBy(func(lastUsedBefore time.Time) bool {
+			return lastUsedBefore.Before(time.Now().Add(-suite.ctrl.Ctrl.SessionIdleTimeout + time.Minute))
+		})).
+		Return(suite.sampleSessions, nil)
+	for _, session := range suite.sampleSessions {
+		suite.ctrl.Notifier.On("NotifySessionRevoked", timeout, suite.ctrl.DB.Tx[0], session,
+			event.SessionRevokedReasonExpired, uuid.NullUUID{}).
+			Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.deleteExpiredSessions(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.ctrl.DB.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_deleteExpiredSessions(t *testing.T) {
+	suite.Run(t, new(ControllerDeleteExpiredSessionsSuite))
+}
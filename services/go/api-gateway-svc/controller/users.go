@@ -5,7 +5,9 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 )
 
 // CreateUser creates the given store.User in the store.
@@ -17,7 +19,8 @@ func (c *Controller) CreateUser(ctx context.Context, tx pgx.Tx, user store.UserW
 	return nil
 }
 
-// UpdateUser updates the given store.User in the Store.
+// UpdateUser updates the given store.User in the Store and invalidates cached
+// auth tokens for the user.
 func (c *Controller) UpdateUser(ctx context.Context, tx pgx.Tx, user store.User) error {
 	if !user.IsActive {
 		// Invalidate sessions.
@@ -31,6 +34,10 @@ func (c *Controller) UpdateUser(ctx context.Context, tx pgx.Tx, user store.User)
 	if err != nil {
 		return meh.Wrap(err, "update user in store", meh.Details{"user": user})
 	}
+	err = c.invalidateAuthTokensByUser(ctx, tx, user.ID)
+	if err != nil {
+		return meh.Wrap(err, "invalidate auth tokens by user", meh.Details{"user_id": user.ID})
+	}
 	return nil
 }
 
@@ -49,3 +56,31 @@ func (c *Controller) UpdateUserPassByUserID(ctx context.Context, tx pgx.Tx, user
 	}
 	return nil
 }
+
+// InvalidateAuthTokensByUser invalidates cached auth tokens for sessions of the
+// user with the given id. This is used when the user logged out.
+func (c *Controller) InvalidateAuthTokensByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	err := c.invalidateAuthTokensByUser(ctx, tx, userID)
+	if err != nil {
+		return meh.Wrap(err, "invalidate auth tokens by user", meh.Details{"user_id": userID})
+	}
+	return nil
+}
+
+// invalidateAuthTokensByUser removes cached auth tokens for sessions of the user
+// with the given id. As concurrent requests might cache tokens, built from data
+// before the changes of the current transaction were committed, tokens are
+// removed again after commit using pgutil.AfterCommit.
+func (c *Controller) invalidateAuthTokensByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	err := c.Store.DeleteAuthTokensByUser(ctx, userID)
+	if err != nil {
+		return meh.Wrap(err, "delete auth tokens by user", meh.Details{"user_id": userID})
+	}
+	pgutil.AfterCommit(ctx, tx, func(ctx context.Context) {
+		err := c.Store.DeleteAuthTokensByUser(ctx, userID)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "delete auth tokens by user after commit", meh.Details{"user_id": userID}))
+		}
+	})
+	return nil
+}
@@ -116,12 +116,29 @@ func (suite *ControllerUpdateUserSuite) TestStoreUpdateFail() {
 	wait()
 }
 
+func (suite *ControllerUpdateUserSuite) TestDeleteAuthTokensFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdateUser", timeout, tx, suite.updateUser).Return(nil)
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.updateUser.ID).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateUser(timeout, tx, suite.updateUser)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
 func (suite *ControllerUpdateUserSuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	tx := &testutil.DBTx{}
 	originalUser := suite.updateUser
 	originalUser.Username = "faith"
 	suite.ctrl.Store.On("UpdateUser", timeout, tx, suite.updateUser).Return(nil)
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.updateUser.ID).Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
@@ -204,3 +221,50 @@ func (suite *ControllerUpdateUserPassByUserIDSuite) TestOK() {
 func TestController_UpdateUserPassByUserID(t *testing.T) {
 	suite.Run(t, new(ControllerUpdateUserPassByUserIDSuite))
 }
+
+// ControllerInvalidateAuthTokensByUserSuite tests
+// Controller.InvalidateAuthTokensByUser.
+type ControllerInvalidateAuthTokensByUserSuite struct {
+	suite.Suite
+	ctrl         *ControllerMock
+	sampleUserID uuid.UUID
+}
+
+func (suite *ControllerInvalidateAuthTokensByUserSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.sampleUserID = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerInvalidateAuthTokensByUserSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.sampleUserID).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.InvalidateAuthTokensByUser(timeout, tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerInvalidateAuthTokensByUserSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteAuthTokensByUser", timeout, suite.sampleUserID).Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.InvalidateAuthTokensByUser(timeout, tx, suite.sampleUserID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_InvalidateAuthTokensByUser(t *testing.T) {
+	suite.Run(t, new(ControllerInvalidateAuthTokensByUserSuite))
+}
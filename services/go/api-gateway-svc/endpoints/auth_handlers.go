@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // loginPayload is the payload of a login-request in handleLogin.
@@ -21,11 +22,26 @@ type loginPayload struct {
 	Pass string `json:"pass"`
 }
 
-// loginResponse is the response in handleLogin when login was successful.
+// loginResponse is the response in handleLogin when login was successful. It
+// is also used as response in handleRefresh.
 type loginResponse struct {
-	UserID      uuid.UUID `json:"user_id"`
-	AccessToken string    `json:"access_token"`
-	TokenType   string    `json:"token_type"`
+	UserID       uuid.UUID `json:"user_id"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	TokenType    string    `json:"token_type"`
+}
+
+// loginResponseFromSessionTokens creates a loginResponse from the given
+// controller.SessionTokens.
+func loginResponseFromSessionTokens(userID uuid.UUID, tokens controller.SessionTokens) loginResponse {
+	return loginResponse{
+		UserID:       userID,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		TokenType:    tokenType,
+	}
 }
 
 // tokenType is the type of the access token.
@@ -33,9 +49,9 @@ const tokenType = "Bearer"
 
 // handleLoginStore are the dependencies needed for handleLogin.
 type handleLoginStore interface {
-	// Login returns on success the user id, token as string as well as a boolean
+	// Login returns on success the user id, session tokens as well as a boolean
 	// flag describing whether login was successful.
-	Login(ctx context.Context, username string, pass string, requestMetadata controller.AuthRequestMetadata) (uuid.UUID, string, bool, error)
+	Login(ctx context.Context, username string, pass string, requestMetadata controller.AuthRequestMetadata) (uuid.UUID, controller.SessionTokens, bool, error)
 }
 
 // handleLogin handles a login-request.
@@ -49,8 +65,8 @@ func handleLogin(logger *zap.Logger, s handleLoginStore) gin.HandlerFunc {
 			return
 		}
 		// Login.
-		requestMetadata := extractAuthRequestMetadataFromRequest(c.Request)
-		userID, token, ok, err := s.Login(c.Request.Context(), payload.Username, payload.Pass, requestMetadata)
+		requestMetadata := extractAuthRequestMetadataFromRequest(c)
+		userID, tokens, ok, err := s.Login(c.Request.Context(), payload.Username, payload.Pass, requestMetadata)
 		if err != nil {
 			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "login", meh.Details{
 				"username":         payload.Username,
@@ -62,25 +78,29 @@ func handleLogin(logger *zap.Logger, s handleLoginStore) gin.HandlerFunc {
 			c.Status(http.StatusUnauthorized)
 			return
 		}
-		// Respond with token.
-		c.JSON(http.StatusOK, loginResponse{
-			UserID:      userID,
-			AccessToken: token,
-			TokenType:   tokenType,
-		})
+		// Respond with tokens.
+		c.JSON(http.StatusOK, loginResponseFromSessionTokens(userID, tokens))
 	}
 }
 
 // extractAuthRequestMetadataFromRequest extracts
-// controller.AuthRequestMetadata from the given http.Request.
-func extractAuthRequestMetadataFromRequest(r *http.Request) controller.AuthRequestMetadata {
+// controller.AuthRequestMetadata from the request of the given gin.Context. The
+// remote address is the client IP, which respects forwarding headers only for
+// trusted proxies.
+func extractAuthRequestMetadataFromRequest(c *gin.Context) controller.AuthRequestMetadata {
 	return controller.AuthRequestMetadata{
-		Host:       r.Host,
-		UserAgent:  r.UserAgent(),
-		RemoteAddr: r.RemoteAddr,
+		Host:       c.Request.Host,
+		UserAgent:  c.Request.UserAgent(),
+		RemoteAddr: c.ClientIP(),
 	}
 }
 
+// extractPublicTokenFromRequest extracts the public token from the
+// Authorization-header of the given http.Request.
+func extractPublicTokenFromRequest(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
 // handleLogoutStore are the dependencies for handleLogout.
 type handleLogoutStore interface {
 	Logout(ctx context.Context, publicToken string, requestMetadata controller.AuthRequestMetadata) error
@@ -89,11 +109,9 @@ type handleLogoutStore interface {
 // handleLogout handles a logout-request.
 func handleLogout(logger *zap.Logger, s handleLogoutStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Extract public token from request.
-		authHeader := c.GetHeader("Authorization")
-		publicToken := strings.TrimPrefix(authHeader, "Bearer ")
 		// Logout.
-		err := s.Logout(c.Request.Context(), publicToken, extractAuthRequestMetadataFromRequest(c.Request))
+		publicToken := extractPublicTokenFromRequest(c.Request)
+		err := s.Logout(c.Request.Context(), publicToken, extractAuthRequestMetadataFromRequest(c))
 		if err != nil {
 			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "logout", nil))
 			return
@@ -102,6 +120,80 @@ func handleLogout(logger *zap.Logger, s handleLogoutStore) gin.HandlerFunc {
 	}
 }
 
+// refreshPayload is the payload of a refresh-request in handleRefresh.
+type refreshPayload struct {
+	// RefreshToken as handed out on login or previous refresh.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// handleRefreshStore are the dependencies needed for handleRefresh.
+type handleRefreshStore interface {
+	// RefreshSession refreshes the session with the given refresh token and
+	// returns the user id as well as new session tokens.
+	RefreshSession(ctx context.Context, refreshToken string) (uuid.UUID, controller.SessionTokens, error)
+}
+
+// handleRefresh handles a refresh-request for refreshing a session and
+// retrieving new tokens.
+func handleRefresh(logger *zap.Logger, s handleRefreshStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Parse payload.
+		var payload refreshPayload
+		err := c.BindJSON(&payload)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.NewBadInputErrFromErr(err, "invalid body", nil))
+			return
+		}
+		if payload.RefreshToken == "" {
+			mehgin.LogAndRespondError(logger, c, meh.NewBadInputErr("missing refresh token", nil))
+			return
+		}
+		// Refresh.
+		userID, tokens, err := s.RefreshSession(c.Request.Context(), payload.RefreshToken)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "refresh session", nil))
+			return
+		}
+		c.JSON(http.StatusOK, loginResponseFromSessionTokens(userID, tokens))
+	}
+}
+
+// unlockLoginPayload is the payload for handleUnlockLogin.
+type unlockLoginPayload struct {
+	// Username to lift the lockout for.
+	Username string `json:"username"`
+	// RemoteAddr to lift the lockout for.
+	RemoteAddr string `json:"remote_addr"`
+}
+
+// handleUnlockLoginStore are the dependencies needed for handleUnlockLogin.
+type handleUnlockLoginStore interface {
+	// UnlockLogin lifts login lockouts for the given username and/or remote
+	// address.
+	UnlockLogin(ctx context.Context, publicToken string, username string, remoteAddr string) error
+}
+
+// handleUnlockLogin lifts login lockouts for the username and/or remote address
+// from the unlockLoginPayload.
+func handleUnlockLogin(logger *zap.Logger, s handleUnlockLoginStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Parse payload.
+		var payload unlockLoginPayload
+		err := c.BindJSON(&payload)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.NewBadInputErrFromErr(err, "invalid body", nil))
+			return
+		}
+		// Unlock.
+		err = s.UnlockLogin(c.Request.Context(), extractPublicTokenFromRequest(c.Request), payload.Username, payload.RemoteAddr)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "unlock login", meh.Details{"payload": payload}))
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
 // handleResolvePublicToken expects the public token and resolves it using the
 // controller. The resolved token is then returned as plaintext.
 func handleResolvePublicToken(logger *zap.Logger, s handleProxyController) gin.HandlerFunc {
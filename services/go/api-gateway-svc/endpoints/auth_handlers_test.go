@@ -11,12 +11,15 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // handleLoginSuite tests handleLogin.
@@ -49,7 +52,7 @@ func (suite *handleLoginSuite) TestInvalidBody() {
 
 func (suite *handleLoginSuite) TestLoginFail() {
 	suite.s.On("Login", mock.Anything, suite.sampleRequest.Username, suite.sampleRequest.Pass, mock.Anything).
-		Return(uuid.Nil, "", false, errors.New("sad life"))
+		Return(uuid.Nil, controller.SessionTokens{}, false, errors.New("sad life"))
 	defer suite.s.AssertExpectations(suite.T())
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -62,7 +65,7 @@ func (suite *handleLoginSuite) TestLoginFail() {
 
 func (suite *handleLoginSuite) TestBadLogin() {
 	suite.s.On("Login", mock.Anything, suite.sampleRequest.Username, suite.sampleRequest.Pass, mock.Anything).
-		Return(uuid.Nil, "", false, nil)
+		Return(uuid.Nil, controller.SessionTokens{}, false, nil)
 	defer suite.s.AssertExpectations(suite.T())
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -75,9 +78,13 @@ func (suite *handleLoginSuite) TestBadLogin() {
 
 func (suite *handleLoginSuite) TestOK() {
 	userID := testutil.NewUUIDV4()
-	token := "feed"
+	tokens := controller.SessionTokens{
+		AccessToken:  "feed",
+		RefreshToken: "pair",
+		ExpiresAt:    time.Date(2022, 10, 3, 14, 0, 0, 0, time.UTC),
+	}
 	suite.s.On("Login", mock.Anything, suite.sampleRequest.Username, suite.sampleRequest.Pass, mock.Anything).
-		Return(userID, token, true, nil)
+		Return(userID, tokens, true, nil)
 	defer suite.s.AssertExpectations(suite.T())
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -89,9 +96,11 @@ func (suite *handleLoginSuite) TestOK() {
 	var got loginResponse
 	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid response")
 	suite.Equal(loginResponse{
-		UserID:      userID,
-		AccessToken: token,
-		TokenType:   "Bearer",
+		UserID:       userID,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		TokenType:    "Bearer",
 	}, got, "should return correct response")
 }
 
@@ -102,20 +111,39 @@ func Test_handleLogin(t *testing.T) {
 func Test_extractAuthRequestMetadataFromRequest(t *testing.T) {
 	host := "sheet"
 	userAgent := "yes"
-	remoteAddr := "ocean"
 	req := &http.Request{
 		Host:       host,
-		RemoteAddr: remoteAddr,
+		RemoteAddr: "10.0.0.12:48211",
 		Header: http.Header{
-			"User-Agent": []string{userAgent},
+			"User-Agent":      []string{userAgent},
+			"X-Forwarded-For": []string{"203.0.113.7"},
 		},
 	}
-	got := extractAuthRequestMetadataFromRequest(req)
+	r := gin.New()
+	require.NoError(t, r.SetTrustedProxies(nil), "setting trusted proxies should not fail")
+	c := gin.CreateTestContextOnly(httptest.NewRecorder(), r)
+	c.Request = req
+	got := extractAuthRequestMetadataFromRequest(c)
 	assert.Equal(t, controller.AuthRequestMetadata{
 		Host:       host,
 		UserAgent:  userAgent,
-		RemoteAddr: remoteAddr,
-	}, got, "should extract correct metadata")
+		RemoteAddr: "10.0.0.12",
+	}, got, "should ignore forwarding headers from untrusted proxies")
+}
+
+func Test_extractAuthRequestMetadataFromRequestTrustedProxy(t *testing.T) {
+	req := &http.Request{
+		RemoteAddr: "10.0.0.12:48211",
+		Header: http.Header{
+			"X-Forwarded-For": []string{"203.0.113.7"},
+		},
+	}
+	r := gin.New()
+	require.NoError(t, r.SetTrustedProxies([]string{"10.0.0.0/8"}), "setting trusted proxies should not fail")
+	c := gin.CreateTestContextOnly(httptest.NewRecorder(), r)
+	c.Request = req
+	got := extractAuthRequestMetadataFromRequest(c)
+	assert.Equal(t, "203.0.113.7", got.RemoteAddr, "should use forwarded address from trusted proxy")
 }
 
 // handleLogoutSuite tests handleLogout.
@@ -169,6 +197,159 @@ func Test_handleLogout(t *testing.T) {
 	suite.Run(t, new(handleLogoutSuite))
 }
 
+// handleRefreshSuite tests handleRefresh.
+type handleRefreshSuite struct {
+	suite.Suite
+	s             *StoreMock
+	r             *gin.Engine
+	sampleRequest refreshPayload
+}
+
+func (suite *handleRefreshSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateAPIV1Routes(suite.r, zap.NewNop(), suite.s, "")
+	suite.sampleRequest = refreshPayload{
+		RefreshToken: "bridge",
+	}
+}
+
+func (suite *handleRefreshSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/refresh",
+		Body:   strings.NewReader("{invalid"),
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleRefreshSuite) TestMissingRefreshToken() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/refresh",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(refreshPayload{})),
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleRefreshSuite) TestRefreshFail() {
+	suite.s.On("RefreshSession", mock.Anything, suite.sampleRequest.RefreshToken).
+		Return(uuid.Nil, controller.SessionTokens{}, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/refresh",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRequest)),
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleRefreshSuite) TestOK() {
+	userID := testutil.NewUUIDV4()
+	tokens := controller.SessionTokens{
+		AccessToken:  "swim",
+		RefreshToken: "bottle",
+		ExpiresAt:    time.Date(2022, 10, 3, 14, 0, 0, 0, time.UTC),
+	}
+	suite.s.On("RefreshSession", mock.Anything, suite.sampleRequest.RefreshToken).
+		Return(userID, tokens, nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/refresh",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRequest)),
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got loginResponse
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid response")
+	suite.Equal(loginResponse{
+		UserID:       userID,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		TokenType:    "Bearer",
+	}, got, "should return correct response")
+}
+
+func Test_handleRefresh(t *testing.T) {
+	suite.Run(t, new(handleRefreshSuite))
+}
+
+// handleUnlockLoginSuite tests handleUnlockLogin.
+type handleUnlockLoginSuite struct {
+	suite.Suite
+	s              *StoreMock
+	r              *gin.Engine
+	sampleToken    auth.Token
+	sampleTokenStr string
+	sampleRequest  unlockLoginPayload
+}
+
+func (suite *handleUnlockLoginSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateAPIV1Routes(suite.r, zap.NewNop(), suite.s, "")
+	suite.sampleToken = auth.Token{
+		UserID: testutil.NewUUIDV4(),
+	}
+	var err error
+	suite.sampleTokenStr, err = auth.GenJWTToken(suite.sampleToken, "")
+	if err != nil {
+		panic(err)
+	}
+	suite.sampleRequest = unlockLoginPayload{
+		Username:   "grain",
+		RemoteAddr: "10.0.0.12",
+	}
+}
+
+func (suite *handleUnlockLoginSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/login/unlock",
+		Body:   strings.NewReader("{invalid"),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUnlockLoginSuite) TestUnlockFail() {
+	suite.s.On("UnlockLogin", mock.Anything, suite.sampleTokenStr, suite.sampleRequest.Username, suite.sampleRequest.RemoteAddr).
+		Return(errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/login/unlock",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRequest)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUnlockLoginSuite) TestOK() {
+	suite.s.On("UnlockLogin", mock.Anything, suite.sampleTokenStr, suite.sampleRequest.Username, suite.sampleRequest.RemoteAddr).
+		Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/login/unlock",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRequest)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUnlockLogin(t *testing.T) {
+	suite.Run(t, new(handleUnlockLoginSuite))
+}
+
 // handleResolvePublicTokenSuite tests handleResolvePublicToken.
 type handleResolvePublicTokenSuite struct {
 	suite.Suite
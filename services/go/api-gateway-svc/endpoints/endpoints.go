@@ -7,6 +7,7 @@ import (
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/controller"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"go.uber.org/zap"
 	"net/http"
 	"time"
@@ -16,13 +17,23 @@ import (
 type Store interface {
 	handleLoginStore
 	handleLogoutStore
+	handleRefreshStore
+	handleUnlockLoginStore
+	handleGetSessionsStore
+	handleRevokeSessionStore
 	handleProxyController
 }
 
-// Serve endpoints over HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, serveAddr string, forwardAddr string, ctrl *controller.Controller) error {
+// Serve endpoints over HTTP. Client addresses are only taken from forwarding
+// headers if the request originates from one of the given trusted proxies.
+func Serve(lifetime context.Context, logger *zap.Logger, serveAddr string, forwardAddr string, trustedProxies []string,
+	ctrl *controller.Controller) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	router := httpendpoints.NewEngine(logger)
+	err := router.SetTrustedProxies(trustedProxies)
+	if err != nil {
+		return meh.NewBadInputErrFromErr(err, "set trusted proxies", meh.Details{"trusted_proxies": trustedProxies})
+	}
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{http.MethodOptions, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
@@ -31,7 +42,7 @@ func Serve(lifetime context.Context, logger *zap.Logger, serveAddr string, forwa
 		MaxAge:           12 * time.Hour,
 	}))
 	populateAPIV1Routes(router, logger.Named("api-v1"), ctrl, forwardAddr)
-	err := httpendpoints.Serve(lifetime, router, serveAddr)
+	err = httpendpoints.Serve(lifetime, router, serveAddr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": serveAddr})
 	}
@@ -41,11 +52,18 @@ func Serve(lifetime context.Context, logger *zap.Logger, serveAddr string, forwa
 func populateAPIV1Routes(router *gin.Engine, logger *zap.Logger, s Store, forwardAddr string) {
 	router.POST("/login", handleLogin(logger, s))
 	router.POST("/logout", handleLogout(logger, s))
+	router.POST("/login/unlock", handleUnlockLogin(logger, s))
+	router.POST("/refresh", handleRefresh(logger, s))
+	router.GET("/sessions", handleGetSessions(logger, s))
+	router.DELETE("/sessions/:sessionID", handleRevokeSession(logger, s))
 	router.NoRoute(handleProxy(logger, s, forwardAddr))
 }
 
-// ServeInternal endpoints over HTTP.
-func ServeInternal(lifetime context.Context, logger *zap.Logger, serveAddr string, ctrl *controller.Controller) error {
+// ServeInternal endpoints over HTTP. This includes the admin routes for managing
+// dead letters, as requests for them are forwarded via the internal ingress with
+// the authentication token from the proxy.
+func ServeInternal(lifetime context.Context, logger *zap.Logger, serveAddr string, authSecret string,
+	ctrl *controller.Controller, deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	router := httpendpoints.NewEngine(logger)
 	router.Use(cors.New(cors.Config{
@@ -56,6 +74,7 @@ func ServeInternal(lifetime context.Context, logger *zap.Logger, serveAddr strin
 		MaxAge:           12 * time.Hour,
 	}))
 	populateInternalAPIV1Routes(router, logger.Named("api-v1"), ctrl)
+	kafkautil.PopulateDeadLetterRoutes(router, logger.Named("dead-letters"), authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, router, serveAddr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": serveAddr})
@@ -22,9 +22,31 @@ type StoreMock struct {
 	mock.Mock
 }
 
-func (m *StoreMock) Login(ctx context.Context, username string, pass string, requestMetadata controller.AuthRequestMetadata) (uuid.UUID, string, bool, error) {
+func (m *StoreMock) Login(ctx context.Context, username string, pass string, requestMetadata controller.AuthRequestMetadata) (uuid.UUID, controller.SessionTokens, bool, error) {
 	args := m.Called(ctx, username, pass, requestMetadata)
-	return args.Get(0).(uuid.UUID), args.String(1), args.Bool(2), args.Error(3)
+	return args.Get(0).(uuid.UUID), args.Get(1).(controller.SessionTokens), args.Bool(2), args.Error(3)
+}
+
+func (m *StoreMock) RefreshSession(ctx context.Context, refreshToken string) (uuid.UUID, controller.SessionTokens, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(uuid.UUID), args.Get(1).(controller.SessionTokens), args.Error(2)
+}
+
+func (m *StoreMock) SessionsByUser(ctx context.Context, publicToken string, userID uuid.NullUUID) ([]controller.Session, error) {
+	args := m.Called(ctx, publicToken, userID)
+	var sessions []controller.Session
+	if a := args.Get(0); a != nil {
+		sessions = a.([]controller.Session)
+	}
+	return sessions, args.Error(1)
+}
+
+func (m *StoreMock) RevokeSession(ctx context.Context, publicToken string, sessionID uuid.UUID) error {
+	return m.Called(ctx, publicToken, sessionID).Error(0)
+}
+
+func (m *StoreMock) UnlockLogin(ctx context.Context, publicToken string, username string, remoteAddr string) error {
+	return m.Called(ctx, publicToken, username, remoteAddr).Error(0)
 }
 
 func (m *StoreMock) Logout(ctx context.Context, publicToken string, requestMetadata controller.AuthRequestMetadata) error {
@@ -51,6 +73,22 @@ func Test_populateAPIV1Routes(t *testing.T) {
 			Method: http.MethodPost,
 			Path:   "/logout",
 		},
+		{
+			Method: http.MethodPost,
+			Path:   "/login/unlock",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/refresh",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/sessions",
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/sessions/:sessionID",
+		},
 	}, testutil.RouteInfoFromGin(r.Routes()))
 }
 
@@ -68,7 +106,7 @@ func TestCORS(t *testing.T) {
 	go func() {
 		defer cancel()
 		ctrl := &controller.Controller{}
-		err := Serve(runCtx, zap.NewNop(), listenAddr, "", ctrl)
+		err := Serve(runCtx, zap.NewNop(), listenAddr, "", nil, ctrl)
 		require.NoError(t, err, "serving should not fail")
 	}()
 
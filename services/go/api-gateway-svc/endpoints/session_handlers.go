@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehgin"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/controller"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// publicSession is the public representation of controller.Session.
+type publicSession struct {
+	ID         uuid.UUID `json:"id"`
+	User       uuid.UUID `json:"user"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Host       string    `json:"host"`
+	UserAgent  string    `json:"user_agent"`
+	RemoteAddr string    `json:"remote_addr"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+// publicSessionFromController converts a controller.Session to publicSession.
+func publicSessionFromController(s controller.Session) publicSession {
+	return publicSession{
+		ID:         s.ID,
+		User:       s.User,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Host:       s.Host,
+		UserAgent:  s.UserAgent,
+		RemoteAddr: s.RemoteAddr,
+		IsCurrent:  s.IsCurrent,
+	}
+}
+
+// handleGetSessionsStore are the dependencies needed for handleGetSessions.
+type handleGetSessionsStore interface {
+	// SessionsByUser retrieves all sessions for the user with the given id or the
+	// one, the public token belongs to.
+	SessionsByUser(ctx context.Context, publicToken string, userID uuid.NullUUID) ([]controller.Session, error)
+}
+
+// handleGetSessions retrieves all active sessions of the requesting user or, if
+// specified via query parameter, the user with the given id.
+func handleGetSessions(logger *zap.Logger, s handleGetSessionsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Extract user id.
+		var userID uuid.NullUUID
+		if userIDStr := c.Query("user"); userIDStr != "" {
+			id, err := uuid.FromString(userIDStr)
+			if err != nil {
+				mehgin.LogAndRespondError(logger, c, meh.NewBadInputErrFromErr(err, "parse user id", meh.Details{"was": userIDStr}))
+				return
+			}
+			userID = nulls.NewUUID(id)
+		}
+		// Retrieve.
+		sessions, err := s.SessionsByUser(c.Request.Context(), extractPublicTokenFromRequest(c.Request), userID)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "sessions by user", meh.Details{"user_id": userID}))
+			return
+		}
+		publicSessions := make([]publicSession, 0, len(sessions))
+		for _, session := range sessions {
+			publicSessions = append(publicSessions, publicSessionFromController(session))
+		}
+		c.JSON(http.StatusOK, publicSessions)
+	}
+}
+
+// handleRevokeSessionStore are the dependencies needed for handleRevokeSession.
+type handleRevokeSessionStore interface {
+	// RevokeSession revokes the session with the given id.
+	RevokeSession(ctx context.Context, publicToken string, sessionID uuid.UUID) error
+}
+
+// handleRevokeSession revokes the session with the given id.
+func handleRevokeSession(logger *zap.Logger, s handleRevokeSessionStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Extract session id.
+		sessionIDStr := c.Param("sessionID")
+		sessionID, err := uuid.FromString(sessionIDStr)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.NewBadInputErrFromErr(err, "parse session id", meh.Details{"was": sessionIDStr}))
+			return
+		}
+		// Revoke.
+		err = s.RevokeSession(c.Request.Context(), extractPublicTokenFromRequest(c.Request), sessionID)
+		if err != nil {
+			mehgin.LogAndRespondError(logger, c, meh.Wrap(err, "revoke session", meh.Details{"session_id": sessionID}))
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
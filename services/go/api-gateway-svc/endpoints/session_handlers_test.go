@@ -0,0 +1,194 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/controller"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// handleGetSessionsSuite tests handleGetSessions.
+type handleGetSessionsSuite struct {
+	suite.Suite
+	s              *StoreMock
+	r              *gin.Engine
+	sampleToken    auth.Token
+	sampleTokenStr string
+	sampleSessions []controller.Session
+}
+
+func (suite *handleGetSessionsSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateAPIV1Routes(suite.r, zap.NewNop(), suite.s, "")
+	suite.sampleToken = auth.Token{
+		UserID: testutil.NewUUIDV4(),
+	}
+	var err error
+	suite.sampleTokenStr, err = auth.GenJWTToken(suite.sampleToken, "")
+	if err != nil {
+		panic(err)
+	}
+	suite.sampleSessions = []controller.Session{
+		{
+			ID:         testutil.NewUUIDV4(),
+			User:       suite.sampleToken.UserID,
+			CreatedAt:  time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC),
+			LastUsedAt: time.Date(2022, 10, 3, 13, 0, 0, 0, time.UTC),
+			ExpiresAt:  time.Date(2022, 10, 4, 1, 0, 0, 0, time.UTC),
+			Host:       "tear",
+			UserAgent:  "wire",
+			RemoteAddr: "pole",
+			IsCurrent:  true,
+		},
+		{
+			ID:         testutil.NewUUIDV4(),
+			User:       suite.sampleToken.UserID,
+			CreatedAt:  time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC),
+			LastUsedAt: time.Date(2022, 10, 2, 13, 0, 0, 0, time.UTC),
+			ExpiresAt:  time.Date(2022, 10, 3, 1, 0, 0, 0, time.UTC),
+			Host:       "vessel",
+			UserAgent:  "quarter",
+			RemoteAddr: "lady",
+		},
+	}
+}
+
+func (suite *handleGetSessionsSuite) TestInvalidUserID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/sessions?user=abc",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetSessionsSuite) TestRetrieveFail() {
+	suite.s.On("SessionsByUser", mock.Anything, suite.sampleTokenStr, uuid.NullUUID{}).
+		Return(nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/sessions",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetSessionsSuite) TestOKForOtherUser() {
+	userID := testutil.NewUUIDV4()
+	suite.s.On("SessionsByUser", mock.Anything, suite.sampleTokenStr, nulls.NewUUID(userID)).
+		Return([]controller.Session{}, nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/sessions?user=%s", userID.String()),
+		Token:  suite.sampleToken,
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicSession
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid response")
+	suite.Empty(got, "should return correct response")
+}
+
+func (suite *handleGetSessionsSuite) TestOK() {
+	suite.s.On("SessionsByUser", mock.Anything, suite.sampleTokenStr, uuid.NullUUID{}).
+		Return(suite.sampleSessions, nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/sessions",
+		Token:  suite.sampleToken,
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicSession
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid response")
+	suite.Equal([]publicSession{
+		publicSessionFromController(suite.sampleSessions[0]),
+		publicSessionFromController(suite.sampleSessions[1]),
+	}, got, "should return correct response")
+}
+
+func Test_handleGetSessions(t *testing.T) {
+	suite.Run(t, new(handleGetSessionsSuite))
+}
+
+// handleRevokeSessionSuite tests handleRevokeSession.
+type handleRevokeSessionSuite struct {
+	suite.Suite
+	s               *StoreMock
+	r               *gin.Engine
+	sampleToken     auth.Token
+	sampleTokenStr  string
+	sampleSessionID uuid.UUID
+}
+
+func (suite *handleRevokeSessionSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateAPIV1Routes(suite.r, zap.NewNop(), suite.s, "")
+	suite.sampleToken = auth.Token{
+		UserID: testutil.NewUUIDV4(),
+	}
+	var err error
+	suite.sampleTokenStr, err = auth.GenJWTToken(suite.sampleToken, "")
+	if err != nil {
+		panic(err)
+	}
+	suite.sampleSessionID = testutil.NewUUIDV4()
+}
+
+func (suite *handleRevokeSessionSuite) TestInvalidSessionID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    "/sessions/abc",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleRevokeSessionSuite) TestRevokeFail() {
+	suite.s.On("RevokeSession", mock.Anything, suite.sampleTokenStr, suite.sampleSessionID).
+		Return(errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/sessions/%s", suite.sampleSessionID.String()),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleRevokeSessionSuite) TestOK() {
+	suite.s.On("RevokeSession", mock.Anything, suite.sampleTokenStr, suite.sampleSessionID).
+		Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/sessions/%s", suite.sampleSessionID.String()),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleRevokeSession(t *testing.T) {
+	suite.Run(t, new(handleRevokeSessionSuite))
+}
@@ -23,6 +23,9 @@ type Handler interface {
 	UpdateUserPassByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, newPass []byte) error
 	// UpdatePermissionsByUser updates the permissions for the given user.
 	UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, updatedPermissions []permission.Permission) error
+	// InvalidateAuthTokensByUser invalidates cached auth tokens for sessions of the
+	// user with the given id.
+	InvalidateAuthTokensByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error
 }
 
 // HandlerFn is the handler for Kafka messages.
@@ -33,6 +36,8 @@ func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 			return meh.NilOrWrap(p.handlePermissionsTopic(ctx, tx, handler, message), "handle permissions topic", nil)
 		case event.UsersTopic:
 			return meh.NilOrWrap(p.handleUsersTopic(ctx, tx, handler, message), "handle users topic", nil)
+		case event.AuthTopic:
+			return meh.NilOrWrap(p.handleAuthTopic(ctx, tx, handler, message), "handle auth topic", nil)
 		}
 		return nil
 	}
@@ -131,3 +136,26 @@ func (p *Port) handleUserPassUpdated(ctx context.Context, tx pgx.Tx, handler Han
 	}
 	return nil
 }
+
+// handleAuthTopic handles the event.AuthTopic.
+func (p *Port) handleAuthTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeUserLoggedOut:
+		return meh.NilOrWrap(p.handleUserLoggedOut(ctx, tx, handler, message), "handle user logged out", nil)
+	}
+	return nil
+}
+
+// handleUserLoggedOut handles an event.UserLoggedOut.
+func (p *Port) handleUserLoggedOut(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var userLoggedOutEvent event.UserLoggedOut
+	err := json.Unmarshal(message.RawValue, &userLoggedOutEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", nil)
+	}
+	err = handler.InvalidateAuthTokensByUser(ctx, tx, userLoggedOutEvent.User)
+	if err != nil {
+		return meh.Wrap(err, "invalidate auth tokens by user", meh.Details{"user_id": userLoggedOutEvent.User})
+	}
+	return nil
+}
@@ -38,6 +38,10 @@ func (m *HandlerMock) UpdateUserPassByUserID(ctx context.Context, tx pgx.Tx, use
 	return m.Called(ctx, tx, userID, newPass).Error(0)
 }
 
+func (m *HandlerMock) InvalidateAuthTokensByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	return m.Called(ctx, tx, userID).Error(0)
+}
+
 // portHandlePermissionsUpdatedSuite tests Port.handlePermissionsUpdated.
 type portHandlePermissionsUpdatedSuite struct {
 	suite.Suite
@@ -358,3 +362,79 @@ func (suite *portHandleUserPassUpdatedSuite) TestOK() {
 func TestPort_handleUserPassUpdated(t *testing.T) {
 	suite.Run(t, new(portHandleUserPassUpdatedSuite))
 }
+
+// portHandleUserLoggedOutSuite tests Port.handleUserLoggedOut.
+type portHandleUserLoggedOutSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.UserLoggedOut
+}
+
+func (suite *portHandleUserLoggedOutSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.UserLoggedOut{
+		User:       testutil.NewUUIDV4(),
+		Username:   "shelf",
+		Host:       "pride",
+		UserAgent:  "lamp",
+		RemoteAddr: "tongue",
+	}
+}
+
+func (suite *portHandleUserLoggedOutSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.AuthTopic,
+		EventType: event.TypeUserLoggedOut,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleUserLoggedOutSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, []byte("{invalid"))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleUserLoggedOutSuite) TestInvalidateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("InvalidateAuthTokensByUser", timeout, tx, suite.sampleEvent.User).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleUserLoggedOutSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("InvalidateAuthTokensByUser", timeout, tx, suite.sampleEvent.User).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleUserLoggedOut(t *testing.T) {
+	suite.Run(t, new(portHandleUserLoggedOutSuite))
+}
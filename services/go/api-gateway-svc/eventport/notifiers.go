@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/controller"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 )
@@ -53,3 +54,47 @@ func (p *Port) NotifyUserLoggedOut(ctx context.Context, tx pgx.Tx, userID uuid.U
 	}
 	return nil
 }
+
+// NotifyUserLoginFailed notifies about a failed login attempt via an
+// event.TypeUserLoginFailed event.
+func (p *Port) NotifyUserLoginFailed(ctx context.Context, tx pgx.Tx, username string, userID uuid.NullUUID,
+	reason event.UserLoginFailedReason, requestMetadata controller.AuthRequestMetadata) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.AuthTopic,
+		Key:       username,
+		EventType: event.TypeUserLoginFailed,
+		Value: event.UserLoginFailed{
+			Username:   username,
+			User:       userID,
+			Reason:     reason,
+			Host:       requestMetadata.Host,
+			UserAgent:  requestMetadata.UserAgent,
+			RemoteAddr: requestMetadata.RemoteAddr,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka message", nil)
+	}
+	return nil
+}
+
+// NotifySessionRevoked notifies that the given store.Session was revoked via an
+// event.TypeSessionRevoked event.
+func (p *Port) NotifySessionRevoked(ctx context.Context, tx pgx.Tx, session store.Session, reason event.SessionRevokedReason,
+	revokedBy uuid.NullUUID) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.AuthTopic,
+		Key:       session.User.String(),
+		EventType: event.TypeSessionRevoked,
+		Value: event.SessionRevoked{
+			Session:   session.ID,
+			User:      session.User,
+			Reason:    reason,
+			RevokedBy: revokedBy,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka message", nil)
+	}
+	return nil
+}
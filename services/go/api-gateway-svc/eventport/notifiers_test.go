@@ -2,12 +2,15 @@ package eventport
 
 import (
 	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/controller"
+	"github.com/mobile-directing-system/mds-server/services/go/api-gateway-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // PortNotifyUserLoggedInSuite tests Port.NotifyUserLoggedIn.
@@ -135,3 +138,136 @@ func (suite *PortNotifyUserLoggedOutSuite) TestOK() {
 func TestPort_NotifyUserLoggedOut(t *testing.T) {
 	suite.Run(t, new(PortNotifyUserLoggedOutSuite))
 }
+
+// PortNotifySessionRevokedSuite tests Port.NotifySessionRevoked.
+type PortNotifySessionRevokedSuite struct {
+	suite.Suite
+	port            *PortMock
+	sampleSession   store.Session
+	sampleRevokedBy uuid.NullUUID
+	expectedMessage kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifySessionRevokedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleSession = store.Session{
+		ID:         testutil.NewUUIDV4(),
+		User:       testutil.NewUUIDV4(),
+		Token:      "forest",
+		CreatedAt:  time.Date(2022, 10, 2, 9, 12, 0, 0, time.UTC),
+		LastUsedAt: time.Date(2022, 10, 2, 11, 40, 0, 0, time.UTC),
+		Host:       "quiet",
+		UserAgent:  "steam",
+		RemoteAddr: "chalk",
+	}
+	suite.sampleRevokedBy = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.AuthTopic,
+		Key:       suite.sampleSession.User.String(),
+		EventType: event.TypeSessionRevoked,
+		Value: event.SessionRevoked{
+			Session:   suite.sampleSession.ID,
+			User:      suite.sampleSession.User,
+			Reason:    event.SessionRevokedReasonRevoked,
+			RevokedBy: suite.sampleRevokedBy,
+		},
+	}
+}
+
+func (suite *PortNotifySessionRevokedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifySessionRevoked(timeout, &testutil.DBTx{}, suite.sampleSession,
+			event.SessionRevokedReasonRevoked, suite.sampleRevokedBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifySessionRevokedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifySessionRevoked(timeout, &testutil.DBTx{}, suite.sampleSession,
+			event.SessionRevokedReasonRevoked, suite.sampleRevokedBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should have written correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifySessionRevoked(t *testing.T) {
+	suite.Run(t, new(PortNotifySessionRevokedSuite))
+}
+
+// PortNotifyUserLoginFailedSuite tests Port.NotifyUserLoginFailed.
+type PortNotifyUserLoginFailedSuite struct {
+	suite.Suite
+	port                  *PortMock
+	sampleUsername        string
+	sampleUserID          uuid.NullUUID
+	sampleRequestMetadata controller.AuthRequestMetadata
+	expectedMessage       kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyUserLoginFailedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleUsername = "scrape"
+	suite.sampleUserID = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.sampleRequestMetadata = controller.AuthRequestMetadata{
+		Host:       "spring",
+		UserAgent:  "bucket",
+		RemoteAddr: "noon",
+	}
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.AuthTopic,
+		Key:       suite.sampleUsername,
+		EventType: event.TypeUserLoginFailed,
+		Value: event.UserLoginFailed{
+			Username:   suite.sampleUsername,
+			User:       suite.sampleUserID,
+			Reason:     event.UserLoginFailedReasonWrongPass,
+			Host:       suite.sampleRequestMetadata.Host,
+			UserAgent:  suite.sampleRequestMetadata.UserAgent,
+			RemoteAddr: suite.sampleRequestMetadata.RemoteAddr,
+		},
+	}
+}
+
+func (suite *PortNotifyUserLoginFailedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyUserLoginFailed(timeout, &testutil.DBTx{}, suite.sampleUsername, suite.sampleUserID,
+			event.UserLoginFailedReasonWrongPass, suite.sampleRequestMetadata)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyUserLoginFailedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyUserLoginFailed(timeout, &testutil.DBTx{}, suite.sampleUsername, suite.sampleUserID,
+			event.UserLoginFailedReasonWrongPass, suite.sampleRequestMetadata)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should have written correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyUserLoginFailed(t *testing.T) {
+	suite.Run(t, new(PortNotifyUserLoginFailedSuite))
+}
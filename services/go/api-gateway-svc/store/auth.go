@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/go-redis/redis/v8"
 	"github.com/gofrs/uuid"
@@ -13,70 +14,253 @@ import (
 	"time"
 )
 
-// UserIDBySessionToken returns the user id for the given session token. If the
+// Session is an active session of a user.
+type Session struct {
+	// ID identifies the session.
+	ID uuid.UUID
+	// User is the id of the user the session belongs to.
+	User uuid.UUID
+	// Token is the public session token, used for making requests.
+	Token string
+	// CreatedAt is the timestamp when the session was created (logged in).
+	CreatedAt time.Time
+	// LastUsedAt is the timestamp when the session was last used for a request.
+	LastUsedAt time.Time
+	// Host from the login request.
+	Host string
+	// UserAgent from the login request.
+	UserAgent string
+	// RemoteAddr from the login request.
+	RemoteAddr string
+}
+
+// CreateSession holds all information for creating a Session.
+type CreateSession struct {
+	// User is the id of the user the session belongs to.
+	User uuid.UUID
+	// Token is the public session token.
+	Token string
+	// RefreshToken is the token for refreshing the session.
+	RefreshToken string
+	// Host from the login request.
+	Host string
+	// UserAgent from the login request.
+	UserAgent string
+	// RemoteAddr from the login request.
+	RemoteAddr string
+}
+
+// sessionColumns are the columns to select for scanning a Session via
+// scanSession.
+var sessionColumns = []any{
+	goqu.C("id"),
+	goqu.C("user"),
+	goqu.C("token"),
+	goqu.C("created_ts"),
+	goqu.C("last_used_ts"),
+	goqu.C("host"),
+	goqu.C("user_agent"),
+	goqu.C("remote_addr"),
+}
+
+// scanSession scans a Session from the given pgx.Rows, selected with
+// sessionColumns.
+func scanSession(rows pgx.Rows) (Session, error) {
+	var session Session
+	err := rows.Scan(&session.ID,
+		&session.User,
+		&session.Token,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.Host,
+		&session.UserAgent,
+		&session.RemoteAddr)
+	if err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// SessionBySessionToken returns the Session for the given session token. If the
 // token was not found, a meh.ErrNotFound will be returned.
-func (m *Mall) UserIDBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (uuid.UUID, error) {
-	userIDRaw, err := m.redis.Get(ctx, redisutil.BuildKey(redisSessionTokenPrefix, token)).Result()
+func (m *Mall) SessionBySessionToken(ctx context.Context, txSupplier pgutil.DBTxSupplier, token string) (Session, error) {
+	sessionRaw, err := m.redis.Get(ctx, redisutil.BuildKey(redisSessionTokenPrefix, token)).Bytes()
 	if err != nil {
 		if err != redis.Nil {
-			return uuid.Nil, meh.NewInternalErrFromErr(err, "lookup session token in redis", nil)
+			return Session{}, meh.NewInternalErrFromErr(err, "lookup session token in redis", nil)
 		}
 	} else {
 		// Parse.
-		userID, err := uuid.FromString(userIDRaw)
+		var session Session
+		err = json.Unmarshal(sessionRaw, &session)
 		if err != nil {
-			return uuid.Nil, meh.NewInternalErrFromErr(err, "parse raw user id", meh.Details{"raw": userIDRaw})
+			return Session{}, meh.NewInternalErrFromErr(err, "parse raw session", meh.Details{"raw": string(sessionRaw)})
 		}
-		return userID, nil
+		return session, nil
 	}
 	// Not found -> lookup in database.
 	q, _, err := goqu.From(goqu.T("session_tokens")).
-		Select(goqu.C("user")).
+		Select(sessionColumns...).
 		Where(goqu.C("token").Eq(token)).ToSQL()
 	if err != nil {
-		return uuid.Nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+		return Session{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
 	tx, err := txSupplier.Begin(ctx)
 	if err != nil {
-		return uuid.Nil, meh.NewInternalErrFromErr(err, "begin tx", nil)
+		return Session{}, meh.NewInternalErrFromErr(err, "begin tx", nil)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 	rows, err := tx.Query(ctx, q)
 	if err != nil {
-		return uuid.Nil, mehpg.NewQueryDBErr(err, "query db", q)
+		return Session{}, mehpg.NewQueryDBErr(err, "query db", q)
 	}
 	defer rows.Close()
 	if !rows.Next() {
 		_ = tx.Commit(ctx)
-		return uuid.Nil, meh.NewNotFoundErr("not found", nil)
+		return Session{}, meh.NewNotFoundErr("not found", nil)
 	}
-	var userID uuid.UUID
-	err = rows.Scan(&userID)
+	session, err := scanSession(rows)
 	if err != nil {
-		return uuid.Nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		return Session{}, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
 	rows.Close()
 	err = tx.Commit(ctx)
 	if err != nil {
-		return uuid.Nil, meh.NewInternalErrFromErr(err, "commit tx", nil)
+		return Session{}, meh.NewInternalErrFromErr(err, "commit tx", nil)
 	}
 	// Set in cache.
-	err = m.redis.Set(ctx, redisutil.BuildKey(redisSessionTokenPrefix, token), userID.String(), 0).Err()
+	sessionRaw, err = json.Marshal(session)
 	if err != nil {
-		return uuid.Nil, meh.NewInternalErrFromErr(err, "set token in cache", nil)
+		return Session{}, meh.NewInternalErrFromErr(err, "marshal session for cache", nil)
 	}
-	return userID, nil
+	err = m.redis.Set(ctx, redisutil.BuildKey(redisSessionTokenPrefix, token), sessionRaw, 0).Err()
+	if err != nil {
+		return Session{}, meh.NewInternalErrFromErr(err, "set token in cache", nil)
+	}
+	return session, nil
 }
 
-// StoreSessionTokenForUser stores the given token for the user with the given
-// id.
-func (m *Mall) StoreSessionTokenForUser(ctx context.Context, tx pgx.Tx, token string, userID uuid.UUID) error {
-	// Save to database.
+// CreateSession creates the given CreateSession and returns the created
+// Session.
+func (m *Mall) CreateSession(ctx context.Context, tx pgx.Tx, create CreateSession) (Session, error) {
+	now := time.Now().UTC()
 	q, _, err := goqu.Insert(goqu.T("session_tokens")).Rows(goqu.Record{
-		"user":       userID,
-		"token":      token,
-		"created_ts": time.Now().UTC(),
-	}).ToSQL()
+		"user":          create.User,
+		"token":         create.Token,
+		"refresh_token": create.RefreshToken,
+		"created_ts":    now,
+		"last_used_ts":  now,
+		"host":          create.Host,
+		"user_agent":    create.UserAgent,
+		"remote_addr":   create.RemoteAddr,
+	}).Returning(sessionColumns...).ToSQL()
+	if err != nil {
+		return Session{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return Session{}, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Session{}, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
+	}
+	created, err := scanSession(rows)
+	if err != nil {
+		return Session{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return created, nil
+}
+
+// SessionByID retrieves the Session with the given id. If not found, a
+// meh.ErrNotFound is returned.
+func (m *Mall) SessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (Session, error) {
+	q, _, err := goqu.From(goqu.T("session_tokens")).
+		Select(sessionColumns...).
+		Where(goqu.C("id").Eq(sessionID)).ToSQL()
+	if err != nil {
+		return Session{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return Session{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Session{}, meh.NewNotFoundErr("not found", nil)
+	}
+	session, err := scanSession(rows)
+	if err != nil {
+		return Session{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return session, nil
+}
+
+// SessionByRefreshTokenAndLock retrieves the Session with the given refresh
+// token and locks it. If not found, a meh.ErrNotFound is returned.
+func (m *Mall) SessionByRefreshTokenAndLock(ctx context.Context, tx pgx.Tx, refreshToken string) (Session, error) {
+	q, _, err := goqu.From(goqu.T("session_tokens")).
+		Select(sessionColumns...).
+		Where(goqu.C("refresh_token").Eq(refreshToken)).
+		ForUpdate(goqu.Wait).ToSQL()
+	if err != nil {
+		return Session{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return Session{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Session{}, meh.NewNotFoundErr("not found", nil)
+	}
+	session, err := scanSession(rows)
+	if err != nil {
+		return Session{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return session, nil
+}
+
+// SessionsByUser retrieves all sessions for the user with the given id, ordered
+// descending by creation timestamp.
+func (m *Mall) SessionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]Session, error) {
+	q, _, err := goqu.From(goqu.T("session_tokens")).
+		Select(sessionColumns...).
+		Where(goqu.C("user").Eq(userID)).
+		Order(goqu.C("created_ts").Desc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	sessions := make([]Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// UpdateSessionTokensByID sets the token and refresh token of the session with
+// the given id and marks it as used at the given timestamp. The old token is
+// removed from cache.
+func (m *Mall) UpdateSessionTokensByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, token string,
+	refreshToken string, usedAt time.Time) error {
+	session, err := m.SessionByID(ctx, tx, sessionID)
+	if err != nil {
+		return meh.Wrap(err, "session by id", meh.Details{"session_id": sessionID})
+	}
+	q, _, err := goqu.Update(goqu.T("session_tokens")).Set(goqu.Record{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"last_used_ts":  usedAt.UTC(),
+	}).Where(goqu.C("id").Eq(sessionID)).ToSQL()
 	if err != nil {
 		return meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
@@ -84,6 +268,117 @@ func (m *Mall) StoreSessionTokenForUser(ctx context.Context, tx pgx.Tx, token st
 	if err != nil {
 		return mehpg.NewQueryDBErr(err, "exec query", q)
 	}
+	err = m.deleteSessionTokenFromCache(ctx, session.Token)
+	if err != nil {
+		return meh.Wrap(err, "delete old session token from cache", nil)
+	}
+	return nil
+}
+
+// TouchSessionByID marks the session with the given id as used at the given
+// timestamp.
+func (m *Mall) TouchSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, usedAt time.Time) error {
+	q, _, err := goqu.Update(goqu.T("session_tokens")).Set(goqu.Record{
+		"last_used_ts": usedAt.UTC(),
+	}).Where(goqu.C("id").Eq(sessionID)).
+		Returning(goqu.C("token")).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	var token string
+	err = rows.Scan(&token)
+	if err != nil {
+		return mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	// Remove from cache, so that the updated session is loaded on next lookup.
+	err = m.deleteSessionTokenFromCache(ctx, token)
+	if err != nil {
+		return meh.Wrap(err, "delete session token from cache", nil)
+	}
+	return nil
+}
+
+// DeleteSessionByID deletes the session with the given id from the database
+// and from cache. If not found, a meh.ErrNotFound is returned.
+func (m *Mall) DeleteSessionByID(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) error {
+	q, _, err := goqu.Delete(goqu.T("session_tokens")).
+		Where(goqu.C("id").Eq(sessionID)).
+		Returning(goqu.C("token")).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	var token string
+	err = rows.Scan(&token)
+	if err != nil {
+		return mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	err = m.deleteSessionTokenFromCache(ctx, token)
+	if err != nil {
+		return meh.Wrap(err, "delete session token from cache", nil)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions deletes all sessions that were either created before
+// createdBefore or last used before lastUsedBefore. Deleted sessions are
+// returned.
+func (m *Mall) DeleteExpiredSessions(ctx context.Context, tx pgx.Tx, createdBefore time.Time,
+	lastUsedBefore time.Time) ([]Session, error) {
+	q, _, err := goqu.Delete(goqu.T("session_tokens")).
+		Where(goqu.Or(
+			goqu.C("created_ts").Lt(createdBefore.UTC()),
+			goqu.C("last_used_ts").Lt(lastUsedBefore.UTC()))).
+		Returning(sessionColumns...).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	deleted := make([]Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		deleted = append(deleted, session)
+	}
+	rows.Close()
+	for _, session := range deleted {
+		err = m.deleteSessionTokenFromCache(ctx, session.Token)
+		if err != nil {
+			return nil, meh.Wrap(err, "delete session token from cache", meh.Details{"session_id": session.ID})
+		}
+	}
+	return deleted, nil
+}
+
+// deleteSessionTokenFromCache removes the cached session for the given token.
+func (m *Mall) deleteSessionTokenFromCache(ctx context.Context, token string) error {
+	err := m.redis.Del(ctx, redisutil.BuildKey(redisSessionTokenPrefix, token)).Err()
+	if err != nil && err != redis.Nil {
+		return meh.NewInternalErrFromErr(err, "delete token in redis", nil)
+	}
 	return nil
 }
 
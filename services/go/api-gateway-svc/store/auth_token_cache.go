@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/redisutil"
+	"time"
+)
+
+// authTokenCacheTTL is the duration after which cached auth tokens for a user
+// expire if not refreshed. This limits the time in which stale tokens might be
+// used in case of missed invalidations.
+const authTokenCacheTTL = 15 * time.Minute
+
+// authTokenGenerationTTL is the duration after which the generation counter of
+// cached auth tokens for a user expires if not incremented. It only needs to
+// outlive the time between retrieving the generation and caching a token.
+const authTokenGenerationTTL = 24 * time.Hour
+
+// authTokenCacheKey builds the Redis key for the hash, holding the cached auth
+// tokens of all sessions for the user with the given id.
+func authTokenCacheKey(userID uuid.UUID) string {
+	return redisutil.BuildKey(redisAuthTokenPrefix, userID.String())
+}
+
+// authTokenGenerationKey builds the Redis key for the generation counter of
+// cached auth tokens for the user with the given id.
+func authTokenGenerationKey(userID uuid.UUID) string {
+	return redisutil.BuildKey(redisAuthTokenGenerationPrefix, userID.String())
+}
+
+// AuthTokenBySession retrieves the cached auth.Token for the given Session. If
+// none is cached, a meh.ErrNotFound is returned.
+func (m *Mall) AuthTokenBySession(ctx context.Context, session Session) (auth.Token, error) {
+	tokenRaw, err := m.redis.HGet(ctx, authTokenCacheKey(session.User), session.ID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return auth.Token{}, meh.NewNotFoundErr("not found", nil)
+		}
+		return auth.Token{}, meh.NewInternalErrFromErr(err, "lookup auth token in redis", nil)
+	}
+	var token auth.Token
+	err = json.Unmarshal(tokenRaw, &token)
+	if err != nil {
+		return auth.Token{}, meh.NewInternalErrFromErr(err, "parse raw auth token", meh.Details{"raw": string(tokenRaw)})
+	}
+	return token, nil
+}
+
+// AuthTokenGenerationByUser retrieves the current generation of cached auth
+// tokens for the user with the given id. It must be retrieved before reading
+// the data for the token to cache from the database and then passed to
+// StoreAuthTokenForSession.
+func (m *Mall) AuthTokenGenerationByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	generation, err := m.redis.Get(ctx, authTokenGenerationKey(userID)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, meh.NewInternalErrFromErr(err, "get auth token generation from redis", nil)
+	}
+	return generation, nil
+}
+
+// StoreAuthTokenForSession caches the given auth.Token for the given Session,
+// if the generation of cached auth tokens for the user still equals the given
+// one. Otherwise, the token might have been built from outdated data and is not
+// cached. Cached tokens for a user expire after authTokenCacheTTL.
+func (m *Mall) StoreAuthTokenForSession(ctx context.Context, session Session, token auth.Token, generation int64) error {
+	tokenRaw, err := json.Marshal(token)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "marshal auth token", nil)
+	}
+	key := authTokenCacheKey(session.User)
+	generationKey := authTokenGenerationKey(session.User)
+	err = m.redis.Watch(ctx, func(tx *redis.Tx) error {
+		currentGeneration, err := tx.Get(ctx, generationKey).Int64()
+		if err != nil && err != redis.Nil {
+			return meh.NewInternalErrFromErr(err, "get auth token generation from redis", nil)
+		}
+		if currentGeneration != generation {
+			// Invalidated in the meantime.
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, session.ID.String(), tokenRaw)
+			pipe.Expire(ctx, key, authTokenCacheTTL)
+			return nil
+		})
+		return err
+	}, generationKey)
+	if err != nil {
+		if err == redis.TxFailedErr {
+			// Invalidated in the meantime.
+			return nil
+		}
+		return meh.NewInternalErrFromErr(err, "set auth token in redis", nil)
+	}
+	return nil
+}
+
+// DeleteAuthTokensByUser removes all cached auth tokens for sessions of the
+// user with the given id. It also increments the generation of cached auth
+// tokens for the user, so that tokens, being built concurrently from outdated
+// data, are not cached afterwards.
+func (m *Mall) DeleteAuthTokensByUser(ctx context.Context, userID uuid.UUID) error {
+	generationKey := authTokenGenerationKey(userID)
+	_, err := m.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, generationKey)
+		pipe.Expire(ctx, generationKey, authTokenGenerationTTL)
+		pipe.Del(ctx, authTokenCacheKey(userID))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return meh.NewInternalErrFromErr(err, "delete auth tokens in redis", nil)
+	}
+	return nil
+}
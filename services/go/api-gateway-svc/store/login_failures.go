@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/redisutil"
+	"time"
+)
+
+// LoginFailureCounts holds the number of recent failed login attempts.
+type LoginFailureCounts struct {
+	// ByUsername is the number of failed attempts for the username.
+	ByUsername int
+	// ByRemoteAddr is the number of failed attempts from the remote address.
+	ByRemoteAddr int
+}
+
+// loginFailuresByUsernameKey builds the Redis key for the failed login counter
+// of the given username.
+func loginFailuresByUsernameKey(username string) string {
+	return redisutil.BuildKey(redisLoginFailurePrefix, "username", username)
+}
+
+// loginFailuresByRemoteAddrKey builds the Redis key for the failed login
+// counter of the given remote address.
+func loginFailuresByRemoteAddrKey(remoteAddr string) string {
+	return redisutil.BuildKey(redisLoginFailurePrefix, "remote_addr", remoteAddr)
+}
+
+// IncrementLoginFailures increments the failed login counters for the given
+// username and remote address and returns the new counts. As this happens
+// atomically, it is used for reserving a login attempt before checking
+// credentials. Counters are reset when no attempt is reserved for the given ttl.
+func (m *Mall) IncrementLoginFailures(ctx context.Context, username string, remoteAddr string, ttl time.Duration) (LoginFailureCounts, error) {
+	usernameKey := loginFailuresByUsernameKey(username)
+	remoteAddrKey := loginFailuresByRemoteAddrKey(remoteAddr)
+	var byUsername *redis.IntCmd
+	var byRemoteAddr *redis.IntCmd
+	_, err := m.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		byUsername = pipe.Incr(ctx, usernameKey)
+		pipe.Expire(ctx, usernameKey, ttl)
+		byRemoteAddr = pipe.Incr(ctx, remoteAddrKey)
+		pipe.Expire(ctx, remoteAddrKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return LoginFailureCounts{}, meh.NewInternalErrFromErr(err, "increment failed logins in redis", nil)
+	}
+	return LoginFailureCounts{
+		ByUsername:   int(byUsername.Val()),
+		ByRemoteAddr: int(byRemoteAddr.Val()),
+	}, nil
+}
+
+// decrementLoginFailuresScript decrements the counters with the given keys if
+// they exist and are positive. This avoids creating counters without expiry.
+var decrementLoginFailuresScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	local count = tonumber(redis.call("GET", key))
+	if count ~= nil and count > 0 then
+		redis.call("DECR", key)
+	end
+end
+return 0
+`)
+
+// DecrementLoginFailures decrements the failed login counters for the given
+// username and remote address. This is used for releasing an attempt, reserved
+// via IncrementLoginFailures, that did not fail.
+func (m *Mall) DecrementLoginFailures(ctx context.Context, username string, remoteAddr string) error {
+	keys := []string{loginFailuresByUsernameKey(username), loginFailuresByRemoteAddrKey(remoteAddr)}
+	err := decrementLoginFailuresScript.Run(ctx, m.redis, keys).Err()
+	if err != nil && err != redis.Nil {
+		return meh.NewInternalErrFromErr(err, "decrement failed logins in redis", nil)
+	}
+	return nil
+}
+
+// ResetLoginFailuresByUsername resets the failed login counter for the given
+// username.
+func (m *Mall) ResetLoginFailuresByUsername(ctx context.Context, username string) error {
+	err := m.redis.Del(ctx, loginFailuresByUsernameKey(username)).Err()
+	if err != nil && err != redis.Nil {
+		return meh.NewInternalErrFromErr(err, "delete failed logins by username in redis", nil)
+	}
+	return nil
+}
+
+// ResetLoginFailuresByRemoteAddr resets the failed login counter for the given
+// remote address.
+func (m *Mall) ResetLoginFailuresByRemoteAddr(ctx context.Context, remoteAddr string) error {
+	err := m.redis.Del(ctx, loginFailuresByRemoteAddrKey(remoteAddr)).Err()
+	if err != nil && err != redis.Nil {
+		return meh.NewInternalErrFromErr(err, "delete failed logins by remote address in redis", nil)
+	}
+	return nil
+}
@@ -13,7 +13,10 @@ type Mall struct {
 }
 
 const (
-	redisSessionTokenPrefix = "session_token"
+	redisSessionTokenPrefix        = "session_token"
+	redisAuthTokenPrefix           = "auth_token"
+	redisAuthTokenGenerationPrefix = "auth_token_generation"
+	redisLoginFailurePrefix        = "login_failures"
 )
 
 // NewMall creates a new Mall.
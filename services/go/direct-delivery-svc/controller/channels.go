@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+)
+
+// UpdateDirectChannelsByEntry deletes and recreates the direct-channels for the
+// address book entry with the given id.
+func (c *Controller) UpdateDirectChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, create []store.DirectChannel) error {
+	// Delete old channels.
+	err := c.store.DeleteDirectChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete direct-channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	// Create new channels.
+	for _, newChannel := range create {
+		err = c.store.CreateDirectChannel(ctx, tx, newChannel)
+		if err != nil {
+			return meh.Wrap(err, "create direct-channel in store", meh.Details{"create": create})
+		}
+	}
+	return nil
+}
+
+// DeleteDirectChannelsByEntry deletes all direct-channels for the entry with the
+// given id.
+func (c *Controller) DeleteDirectChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	err := c.store.DeleteDirectChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete direct channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	return nil
+}
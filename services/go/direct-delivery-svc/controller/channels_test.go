@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerUpdateDirectChannelsByEntrySuite tests
+// Controller.UpdateDirectChannelsByEntry.
+type ControllerUpdateDirectChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleEntry    uuid.UUID
+	sampleChannels []store.DirectChannel
+}
+
+func (suite *ControllerUpdateDirectChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleEntry = testutil.NewUUIDV4()
+	genChannel := func() store.DirectChannel {
+		return store.DirectChannel{
+			ID:      testutil.NewUUIDV4(),
+			Entry:   suite.sampleEntry,
+			Label:   "death",
+			Timeout: 776,
+		}
+	}
+	suite.sampleChannels = []store.DirectChannel{
+		genChannel(),
+		genChannel(),
+		genChannel(),
+	}
+}
+
+func (suite *ControllerUpdateDirectChannelsByEntrySuite) TestDeleteInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteDirectChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateDirectChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateDirectChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteDirectChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	suite.ctrl.Store.On("CreateDirectChannel", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateDirectChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateDirectChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteDirectChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	for _, channel := range suite.sampleChannels {
+		suite.ctrl.Store.On("CreateDirectChannel", timeout, suite.tx, channel).
+			Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateDirectChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateDirectChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateDirectChannelsByEntrySuite))
+}
+
+// ControllerDeleteDirectChannelsByEntrySuite tests
+// Controller.DeleteDirectChannelsByEntry.
+type ControllerDeleteDirectChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	sampleEntry uuid.UUID
+}
+
+func (suite *ControllerDeleteDirectChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.sampleEntry = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerDeleteDirectChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteDirectChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteDirectChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteDirectChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteDirectChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteDirectChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_DeleteDirectChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerDeleteDirectChannelsByEntrySuite))
+}
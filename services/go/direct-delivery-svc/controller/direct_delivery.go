@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"sort"
+	"time"
+)
+
+// directDeliveryTimeoutCheckInterval is the check-interval for
+// Controller.runPeriodicDirectDeliveryTimeoutChecks.
+const directDeliveryTimeoutCheckInterval = 10 * time.Second
+
+// PickUpNextDirectDelivery selects the next available direct delivery for the
+// given operation by the user.
+func (c *Controller) PickUpNextDirectDelivery(ctx context.Context, operationID uuid.UUID,
+	by uuid.UUID) (store.AcceptedIntelDeliveryAttempt, bool, error) {
+	var attempt store.AcceptedIntelDeliveryAttempt
+	var ok bool
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		// Assure user member of operation.
+		assignedOperations, err := c.store.OperationsByMember(ctx, tx, by)
+		if err != nil {
+			return meh.Wrap(err, "operations by member", meh.Details{"member_id": by})
+		}
+		isMemberOfOperation := false
+		for _, assignedOperation := range assignedOperations {
+			if assignedOperation == operationID {
+				isMemberOfOperation = true
+				break
+			}
+		}
+		if !isMemberOfOperation {
+			return meh.NewForbiddenErr("user not member of operation", nil)
+		}
+		// Retrieve active deliveries.
+		activeDeliveries, err := c.store.ActiveDirectDeliveriesAndLockOrWait(ctx, tx, nulls.NewUUID(operationID))
+		if err != nil {
+			return meh.Wrap(err, "active direct deliveries from store", nil)
+		}
+		openDeliveries := make([]store.ActiveDirectDelivery, 0)
+		for _, activeDelivery := range activeDeliveries {
+			if activeDelivery.PickedUpAt.Valid {
+				continue
+			}
+			openDeliveries = append(openDeliveries, activeDelivery)
+		}
+		if len(openDeliveries) == 0 {
+			attempt = store.AcceptedIntelDeliveryAttempt{}
+			ok = false
+			return nil
+		}
+		// Choose priority-mode.
+		inProgressDeliveryCount := len(activeDeliveries) - len(openDeliveries)
+		var lowToHigh func(i, j int) bool
+		if inProgressDeliveryCount%2 == 0 {
+			// Use importance.
+			lowToHigh = func(i, j int) bool {
+				return openDeliveries[i].IntelImportance < openDeliveries[j].IntelImportance
+			}
+		} else {
+			// Use created-date.
+			lowToHigh = func(i, j int) bool {
+				return openDeliveries[i].AttemptCreatedAt.After(openDeliveries[j].AttemptCreatedAt)
+			}
+		}
+		sort.Slice(openDeliveries, lowToHigh)
+		attemptID := openDeliveries[len(openDeliveries)-1].Attempt
+		ok = true
+		// Pick up.
+		err = c.store.MarkDirectDeliveryAsPickedUpByAttempt(ctx, tx, attemptID, nulls.NewUUID(by), "picked up")
+		if err != nil {
+			return meh.Wrap(err, "mark direct delivery as picked up in store", meh.Details{
+				"attempt_id": attemptID,
+				"by":         by,
+			})
+		}
+		attempt, err = c.store.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "retrieve picked up intel delivery attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		updatedDirectDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "updated direct delivery from store", meh.Details{"attempt_id": attemptID})
+		}
+		// Notify.
+		err = c.notifier.NotifyDirectDeliveryPickedUp(ctx, tx, attemptID, by, updatedDirectDelivery.PickedUpAt.Time)
+		if err != nil {
+			return meh.Wrap(err, "notify", meh.Details{
+				"attempt_id":   attemptID,
+				"by":           by,
+				"picked_up_at": updatedDirectDelivery.PickedUpAt.Time,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return store.AcceptedIntelDeliveryAttempt{}, false, meh.Wrap(err, "run in tx", nil)
+	}
+	return attempt, ok, nil
+}
+
+// ReleasePickedUpDirectDelivery releases the picked up delivery for the attempt
+// with the given id. If limit is set, the delivery must be picked up by the
+// user with the given id.
+func (c *Controller) ReleasePickedUpDirectDelivery(ctx context.Context, attemptID uuid.UUID, limitToPickedUpBy uuid.NullUUID) error {
+	var notifyForOperation uuid.NullUUID
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		delivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "direct delivery by attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		attempt, err := c.store.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "accepted intel-delivery-attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		// Assure release ok.
+		if delivery.Success.Valid {
+			return meh.NewBadInputErr("delivery not active", meh.Details{
+				"delivery_success":    delivery.Success.Bool,
+				"delivery_success_ts": delivery.SuccessTS,
+			})
+		}
+		if !delivery.PickedUpBy.Valid {
+			return meh.NewBadInputErr("delivery not picked up", nil)
+		}
+		if limitToPickedUpBy.Valid && delivery.PickedUpBy.UUID != limitToPickedUpBy.UUID {
+			return meh.NewForbiddenErr("delivery not picked up by user", meh.Details{
+				"picked_up_by":       delivery.PickedUpBy.UUID,
+				"release_limited_to": limitToPickedUpBy.UUID,
+			})
+		}
+		// Release.
+		err = c.store.MarkDirectDeliveryAsPickedUpByAttempt(ctx, tx, attemptID, uuid.NullUUID{}, "waiting for pickup (released)")
+		if err != nil {
+			return meh.Wrap(err, "unmark direct delivery as picked up in store", meh.Details{"attempt": attemptID})
+		}
+		// Notify.
+		err = c.notifier.NotifyDirectDeliveryReleased(ctx, tx, attemptID, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "notify", nil)
+		}
+		notifyForOperation = nulls.NewUUID(attempt.IntelOperation)
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	if notifyForOperation.Valid {
+		c.connUpdateNotifier.scheduleNotifyUpdatesForOperations(ctx, notifyForOperation.UUID)
+	}
+	return nil
+}
+
+// FinishDirectDelivery finishes the picked up direct delivery for the attempt
+// with the given id. The success-state and note are updated to the given ones.
+// If the limit is set, the delivery is assured to be picked up by the user with
+// the given id.
+func (c *Controller) FinishDirectDelivery(ctx context.Context, attemptID uuid.UUID, success bool, note string,
+	limitToPickedUpBy uuid.NullUUID) error {
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		// Assure picked up.
+		directDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "direct delivery by attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		if !directDelivery.PickedUpBy.Valid {
+			return meh.NewBadInputErr("direct delivery not picked up", nil)
+		}
+		// Check limit.
+		if limitToPickedUpBy.Valid && directDelivery.PickedUpBy.UUID != limitToPickedUpBy.UUID {
+			return meh.NewForbiddenErr("not picked up by this user", meh.Details{
+				"picked_up_by":          directDelivery.PickedUpBy.UUID,
+				"limit_to_picked_up_by": limitToPickedUpBy.UUID,
+			})
+		}
+		// Update.
+		newSuccess := nulls.NewBool(success)
+		err = c.store.UpdateDirectDeliveryStatusByAttempt(ctx, tx, attemptID, newSuccess, note)
+		if err != nil {
+			return meh.Wrap(err, "update direct delivery status by attempt in store", meh.Details{
+				"attempt_id":  attemptID,
+				"new_success": newSuccess,
+				"new_note":    note,
+			})
+		}
+		updatedDirectDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "updated direct delivery from store", meh.Details{"attempt_id": attemptID})
+		}
+		// Notify.
+		err = c.notifier.NotifyDirectDeliveryFinished(ctx, tx, updatedDirectDelivery)
+		if err != nil {
+			return meh.Wrap(err, "notify", meh.Details{"updated_direct_delivery": updatedDirectDelivery})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// HandOverDirectDelivery finishes the picked up direct delivery for the attempt
+// with the given id successfully by recording the hand-over to the recipient.
+// The recipient confirmation is required. If the limit is set, the delivery is
+// assured to be picked up by the user with the given id.
+func (c *Controller) HandOverDirectDelivery(ctx context.Context, attemptID uuid.UUID, handedOverAt time.Time,
+	recipientConfirmation string, note string, limitToPickedUpBy uuid.NullUUID) error {
+	if recipientConfirmation == "" {
+		return meh.NewBadInputErr("missing recipient confirmation", nil)
+	}
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		// Assure picked up and active.
+		directDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "direct delivery by attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		if directDelivery.Success.Valid {
+			return meh.NewBadInputErr("direct delivery not active", meh.Details{
+				"delivery_success":    directDelivery.Success.Bool,
+				"delivery_success_ts": directDelivery.SuccessTS,
+			})
+		}
+		if !directDelivery.PickedUpBy.Valid {
+			return meh.NewBadInputErr("direct delivery not picked up", nil)
+		}
+		// Check limit.
+		if limitToPickedUpBy.Valid && directDelivery.PickedUpBy.UUID != limitToPickedUpBy.UUID {
+			return meh.NewForbiddenErr("not picked up by this user", meh.Details{
+				"picked_up_by":          directDelivery.PickedUpBy.UUID,
+				"limit_to_picked_up_by": limitToPickedUpBy.UUID,
+			})
+		}
+		// Update.
+		err = c.store.MarkDirectDeliveryAsHandedOverByAttempt(ctx, tx, attemptID, handedOverAt, recipientConfirmation, note)
+		if err != nil {
+			return meh.Wrap(err, "mark direct delivery as handed over by attempt in store", meh.Details{
+				"attempt_id":             attemptID,
+				"handed_over_at":         handedOverAt,
+				"recipient_confirmation": recipientConfirmation,
+				"new_note":               note,
+			})
+		}
+		updatedDirectDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "updated direct delivery from store", meh.Details{"attempt_id": attemptID})
+		}
+		// Notify.
+		err = c.notifier.NotifyDirectDeliveryFinished(ctx, tx, updatedDirectDelivery)
+		if err != nil {
+			return meh.Wrap(err, "notify", meh.Details{"updated_direct_delivery": updatedDirectDelivery})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// runPeriodicDirectDeliveryTimeoutChecks periodically checks active picked up
+// direct deliveries for being timed out until the given context is done. Check
+// interval is taken from directDeliveryTimeoutCheckInterval.
+func (c *Controller) runPeriodicDirectDeliveryTimeoutChecks(lifetime context.Context) {
+	for {
+		err := c.releaseTimedOutDirectDeliveries(lifetime)
+		if err != nil {
+			mehlog.Log(c.logger, meh.Wrap(err, "run periodic direct delivery timeout check", nil))
+		}
+		select {
+		case <-lifetime.Done():
+			return
+		case <-time.After(directDeliveryTimeoutCheckInterval):
+		}
+	}
+}
+
+// releaseTimedOutDirectDeliveries checks all active deliveries for being timed
+// out (based on pickedUpTimeout), releases and schedules update notifications
+// accordingly and notifies.
+func (c *Controller) releaseTimedOutDirectDeliveries(ctx context.Context) error {
+	affectedOperations := make(map[uuid.UUID]struct{})
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		activeDeliveries, err := c.store.ActiveDirectDeliveriesAndLockOrWait(ctx, tx, uuid.NullUUID{})
+		if err != nil {
+			return meh.Wrap(err, "active direct deliveries from store", nil)
+		}
+		// Check each delivery for timeout.
+		for _, activeDelivery := range activeDeliveries {
+			if !activeDelivery.PickedUpAt.Valid {
+				continue
+			}
+			if time.Since(activeDelivery.PickedUpAt.Time) < c.pickedUpTimeout {
+				continue
+			}
+			// Release.
+			newNote := fmt.Sprintf("timeout (%s) while being picked up", c.pickedUpTimeout.String())
+			err := c.store.MarkDirectDeliveryAsPickedUpByAttempt(ctx, tx, activeDelivery.Attempt, uuid.NullUUID{}, newNote)
+			if err != nil {
+				return meh.Wrap(err, "(un)mark direct delivery as picked up by attempt in store", meh.Details{
+					"attempt_id":        activeDelivery.Attempt,
+					"picked_up_at":      activeDelivery.PickedUpAt.Time,
+					"picked_up_timeout": c.pickedUpTimeout.String(),
+				})
+			}
+			affectedOperations[activeDelivery.IntelOperation] = struct{}{}
+			updatedIntelDeliveryAttempt, err := c.store.AcceptedIntelDeliveryAttemptByID(ctx, tx, activeDelivery.Attempt)
+			if err != nil {
+				return meh.Wrap(err, "updated intel-delivery-attempt from store",
+					meh.Details{"attempt_id": activeDelivery.Attempt})
+			}
+			// Notify.
+			err = c.notifier.NotifyDirectDeliveryReadyForPickup(ctx, tx, updatedIntelDeliveryAttempt, newNote)
+			if err != nil {
+				return meh.Wrap(err, "notify about timed out direct delivery being available for pickup again",
+					meh.Details{"intel_delivery_attempt": updatedIntelDeliveryAttempt})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	// Notify about updated operations.
+	operations := make([]uuid.UUID, 0, len(affectedOperations))
+	for operation := range affectedOperations {
+		operations = append(operations, operation)
+	}
+	c.connUpdateNotifier.scheduleNotifyUpdatesForOperations(ctx, operations...)
+	return nil
+}
@@ -0,0 +1,1352 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// ControllerPickUpNextDirectDeliverySuite tests
+// Controller.PickUpNextDirectDelivery.
+type ControllerPickUpNextDirectDeliverySuite struct {
+	suite.Suite
+	ctrl                       *ControllerMock
+	tx                         *testutil.DBTx
+	sampleOperationID          uuid.UUID
+	sampleBy                   uuid.UUID
+	sampleOperationsOfBy       []uuid.UUID
+	sampleActiveDeliveries     []store.ActiveDirectDelivery
+	sampleIntelDeliveryAttempt store.AcceptedIntelDeliveryAttempt
+	sampleDirectDelivery       store.DirectDelivery
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) genActiveDelivery(importance int, pickedUp bool) store.ActiveDirectDelivery {
+	var pickedUpAt nulls.Time
+	if pickedUp {
+		pickedUpAt = nulls.NewTime(testutil.NewRandomTime())
+	}
+	return store.ActiveDirectDelivery{
+		Attempt:          testutil.NewUUIDV4(),
+		PickedUpAt:       pickedUpAt,
+		IntelOperation:   testutil.NewUUIDV4(),
+		IntelImportance:  importance,
+		AttemptCreatedAt: testutil.NewRandomTime(),
+	}
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleOperationID = testutil.NewUUIDV4()
+	suite.sampleBy = testutil.NewUUIDV4()
+	suite.sampleOperationsOfBy = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		suite.sampleOperationID,
+		testutil.NewUUIDV4(),
+	}
+	suite.sampleActiveDeliveries = []store.ActiveDirectDelivery{
+		suite.genActiveDelivery(267, true),
+		suite.genActiveDelivery(755, false),
+		suite.genActiveDelivery(445, false),
+		suite.genActiveDelivery(516, true),
+		suite.genActiveDelivery(445, false),
+		suite.genActiveDelivery(516, true),
+		suite.genActiveDelivery(516, true),
+	}
+	suite.sampleIntelDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              suite.sampleActiveDeliveries[0].Attempt,
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  suite.sampleOperationID,
+		IntelImportance: suite.sampleActiveDeliveries[0].IntelImportance,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "defend",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("young"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.sampleDirectDelivery = store.DirectDelivery{
+		Attempt:    suite.sampleIntelDeliveryAttempt.ID,
+		PickedUpBy: nulls.NewUUID(suite.sampleBy),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "ease",
+	}
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestRetrieveOperationsForUserFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestNoMemberOfOperation1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return([]uuid.UUID{
+			testutil.NewUUIDV4(),
+			testutil.NewUUIDV4(),
+			testutil.NewUUIDV4(),
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return error with correct code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestNoMemberOfOperation2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return([]uuid.UUID{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return error with correct code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestRetrieveActiveDirectDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestNoOpenDirectDeliveries1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return([]store.ActiveDirectDelivery{
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.False(ok, "should return false")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestNoOpenDirectDeliveries2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return([]store.ActiveDirectDelivery{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.False(ok, "should return false")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestMarkDirectDeliveryAsPickedUpFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestRetrieveIntelDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestRetrieveDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, mock.Anything, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, mock.Anything, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestPickSortByOldestFirst1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activeDirectDeliveries := []store.ActiveDirectDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(200, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(400, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+	}
+	shouldPick := activeDirectDeliveries[0].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activeDirectDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestPickSortByOldestFirst2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activeDirectDeliveries := []store.ActiveDirectDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(700, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(600, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(800, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activeDirectDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activeDirectDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestPickSortByImportanceFirst1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activeDirectDeliveries := []store.ActiveDirectDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(200, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(400, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  900,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activeDirectDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activeDirectDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestPickSortByImportanceFirst2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activeDirectDeliveries := []store.ActiveDirectDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(100, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(800, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activeDirectDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activeDirectDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextDirectDeliverySuite) TestPickSortByImportanceFirst3() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activeDirectDeliveries := []store.ActiveDirectDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(100, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+	}
+	shouldPick := activeDirectDeliveries[1].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activeDirectDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.sampleDirectDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextDirectDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func TestController_PickUpNextDirectDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerPickUpNextDirectDeliverySuite))
+}
+
+// ControllerReleasePickedUpDirectDeliverySuite tests
+// Controller.ReleasePickedUpDirectDelivery.
+type ControllerReleasePickedUpDirectDeliverySuite struct {
+	suite.Suite
+	ctrl                 *ControllerMock
+	tx                   *testutil.DBTx
+	sampleAttemptID      uuid.UUID
+	sampleDirectDelivery store.DirectDelivery
+	sampleAttempt        store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleDirectDelivery = store.DirectDelivery{
+		Attempt:    suite.sampleAttemptID,
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "upper",
+	}
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             suite.sampleAttemptID,
+		IntelOperation: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestRetrieveDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestRetrieveIntelDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestDeliveryNotActiveAnymore() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.Success = nulls.NewBool(false)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestDeliveryNotPickedUp() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.PickedUpBy = uuid.NullUUID{}
+	suite.sampleDirectDelivery.PickedUpAt = nulls.Time{}
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestLimitToPickedUpByNotOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.PickedUpBy = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, nulls.NewUUID(testutil.NewUUIDV4()))
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestReleaseFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReleased", timeout, suite.tx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpDirectDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReleased", timeout, suite.tx, suite.sampleAttemptID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpDirectDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().NoError(err, "should not fail")
+		// Assure notification-update scheduled.
+		select {
+		case <-timeout.Done():
+			suite.Fail("should schedule notification-update")
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Equal(suite.sampleAttempt.IntelOperation, op, "should schedule notification-update for correct operation")
+		}
+	}()
+
+	wait()
+}
+
+func TestController_ReleasePickedUpDirectDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerReleasePickedUpDirectDeliverySuite))
+}
+
+// controllerReleaseTimedOutDirectDeliveriesSuite tests
+// Controller.releaseTimedOutDirectDeliveries.
+type controllerReleaseTimedOutDirectDeliveriesSuite struct {
+	suite.Suite
+	ctrl                               *ControllerMock
+	tx                                 *testutil.DBTx
+	sampleActiveDelivery               store.ActiveDirectDelivery
+	sampleUpdatedActiveDeliveryAttempt store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleActiveDelivery = store.ActiveDirectDelivery{
+		Attempt:          testutil.NewUUIDV4(),
+		PickedUpAt:       nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout - 10*time.Second)),
+		IntelOperation:   testutil.NewUUIDV4(),
+		IntelImportance:  469,
+		AttemptCreatedAt: testutil.NewRandomTime(),
+	}
+	suite.sampleUpdatedActiveDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             suite.sampleActiveDelivery.Attempt,
+		IntelOperation: suite.sampleActiveDelivery.IntelOperation,
+	}
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) expectNotifyUpdatesForOperations(ctx context.Context, forOperations ...uuid.UUID) {
+	// Special case: none expected.
+	if len(forOperations) == 0 {
+		select {
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Failf("unexpected", "no notify-updates expected but got for %v", op)
+		default:
+			return
+		}
+	}
+	// Regular behavior.
+	operations := make(map[uuid.UUID]int, 0)
+	for _, operation := range forOperations {
+		operations[operation] = operations[operation] + 1
+	}
+	for len(operations) > 0 {
+		select {
+		case <-ctx.Done():
+			suite.Failf("timeout", "expected still updates for %d operations", len(operations))
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			remaining := operations[op]
+			if remaining <= 0 {
+				suite.Failf("unexpected", "unexpected notify-update for operation %v", op)
+			} else {
+				remaining--
+				if remaining <= 0 {
+					delete(operations, op)
+				}
+			}
+		}
+	}
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestRetrieveActiveDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestNoActiveDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestNoPickedUpDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{
+			{},
+			{},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestNoTimedOutDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{
+			{PickedUpAt: nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout / 2))},
+			{PickedUpAt: nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout / 2))},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestReleaseFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestRetrieveUpdatedAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(suite.sampleUpdatedActiveDeliveryAttempt, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReadyForPickup", timeout, suite.tx,
+		suite.sampleUpdatedActiveDeliveryAttempt, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestOKSingle() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActiveDirectDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(suite.sampleUpdatedActiveDeliveryAttempt, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReadyForPickup", timeout, suite.tx,
+		suite.sampleUpdatedActiveDeliveryAttempt, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout, suite.sampleActiveDelivery.IntelOperation)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutDirectDeliveriesSuite) TestOKMulti() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	// Gen data.
+	timedOutCount := 16
+	okCount := 64
+	activeDeliveries := make([]store.ActiveDirectDelivery, 0, timedOutCount+okCount)
+	expectNotifyForOperations := []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+	for timedOutCount > 0 || okCount > 0 {
+		genTimeout := timedOutCount > 0
+		if genTimeout && okCount > 0 {
+			genTimeout = rand.Intn(10) > 7
+		}
+		if genTimeout {
+			timedOutCount--
+			d := store.ActiveDirectDelivery{
+				Attempt:          testutil.NewUUIDV4(),
+				PickedUpAt:       nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout - 10*time.Second)),
+				IntelOperation:   expectNotifyForOperations[timedOutCount%len(expectNotifyForOperations)],
+				IntelImportance:  574,
+				AttemptCreatedAt: testutil.NewRandomTime(),
+			}
+			activeDeliveries = append(activeDeliveries, d)
+			suite.ctrl.Store.On("MarkDirectDeliveryAsPickedUpByAttempt", timeout, suite.tx, d.Attempt, uuid.NullUUID{}, mock.Anything).
+				Return(nil).Once()
+			updatedAttempt := store.AcceptedIntelDeliveryAttempt{
+				ID:             d.Attempt,
+				IntelOperation: d.IntelOperation,
+			}
+			suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, d.Attempt).
+				Return(updatedAttempt, nil).Once()
+			suite.ctrl.Notifier.On("NotifyDirectDeliveryReadyForPickup", timeout, suite.tx, updatedAttempt, mock.Anything).
+				Return(nil).Once()
+		} else {
+			okCount--
+			activeDeliveries = append(activeDeliveries, store.ActiveDirectDelivery{
+				Attempt:          testutil.NewUUIDV4(),
+				PickedUpAt:       nulls.NewTime(time.Now().Add(10 * time.Second)),
+				IntelOperation:   testutil.NewUUIDV4(),
+				IntelImportance:  153,
+				AttemptCreatedAt: testutil.NewRandomTime(),
+			})
+		}
+	}
+	suite.ctrl.Store.On("ActiveDirectDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return(activeDeliveries, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutDirectDeliveries(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout, expectNotifyForOperations...)
+	}()
+
+	wait()
+}
+
+func TestController_releaseTimedOutDirectDeliveries(t *testing.T) {
+	suite.Run(t, new(controllerReleaseTimedOutDirectDeliveriesSuite))
+}
+
+// ControllerHandOverDirectDeliverySuite tests
+// Controller.HandOverDirectDelivery.
+type ControllerHandOverDirectDeliverySuite struct {
+	suite.Suite
+	ctrl                        *ControllerMock
+	tx                          *testutil.DBTx
+	sampleAttemptID             uuid.UUID
+	sampleDirectDelivery        store.DirectDelivery
+	sampleHandedOverAt          time.Time
+	sampleRecipientConfirmation string
+	sampleNote                  string
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleDirectDelivery = store.DirectDelivery{
+		Attempt:    suite.sampleAttemptID,
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "picked up",
+	}
+	suite.sampleHandedOverAt = testutil.NewRandomTime()
+	suite.sampleRecipientConfirmation = "Max Mustermann"
+	suite.sampleNote = "handed over at gate"
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) handOver(ctx context.Context, limitToPickedUpBy uuid.NullUUID) error {
+	return suite.ctrl.Ctrl.HandOverDirectDelivery(ctx, suite.sampleAttemptID, suite.sampleHandedOverAt,
+		suite.sampleRecipientConfirmation, suite.sampleNote, limitToPickedUpBy)
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestMissingRecipientConfirmation() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleRecipientConfirmation = ""
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestRetrieveDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestDeliveryNotActiveAnymore() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.Success = nulls.NewBool(false)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestDeliveryNotPickedUp() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.PickedUpBy = uuid.NullUUID{}
+	suite.sampleDirectDelivery.PickedUpAt = nulls.Time{}
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestLimitToPickedUpByNotOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, nulls.NewUUID(testutil.NewUUIDV4()))
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestMarkAsHandedOverFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsHandedOverByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		suite.sampleHandedOverAt, suite.sampleRecipientConfirmation, suite.sampleNote).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestRetrieveUpdatedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsHandedOverByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		suite.sampleHandedOverAt, suite.sampleRecipientConfirmation, suite.sampleNote).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Twice()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsHandedOverByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		suite.sampleHandedOverAt, suite.sampleRecipientConfirmation, suite.sampleNote).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryFinished", timeout, suite.tx, suite.sampleDirectDelivery).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHandOverDirectDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	updated := suite.sampleDirectDelivery
+	updated.Success = nulls.NewBool(true)
+	updated.HandedOverAt = nulls.NewTime(suite.sampleHandedOverAt)
+	updated.RecipientConfirmation = nulls.NewString(suite.sampleRecipientConfirmation)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("MarkDirectDeliveryAsHandedOverByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		suite.sampleHandedOverAt, suite.sampleRecipientConfirmation, suite.sampleNote).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(updated, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryFinished", timeout, suite.tx, updated).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handOver(timeout, suite.sampleDirectDelivery.PickedUpBy)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_HandOverDirectDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerHandOverDirectDeliverySuite))
+}
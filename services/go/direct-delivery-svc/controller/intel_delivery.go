@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"time"
+)
+
+// CreateIntelDeliveryAttempt creates the given
+// store.AcceptedIntelDeliveryAttempt, direct-delivery and notifies. If the
+// referenced direct channel cannot be found, no attempt will be created.
+func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attempt store.AcceptedIntelDeliveryAttempt) error {
+	channel, err := c.store.DirectChannelByID(ctx, tx, attempt.Channel)
+	if err != nil {
+		if meh.ErrorCode(err) == meh.ErrNotFound {
+			// Ignore.
+			return nil
+		}
+		return meh.Wrap(err, "direct channel by id from store", meh.Details{"channel_id": attempt.Channel})
+	}
+	attempt.AcceptedAt = time.Now()
+	attempt.Info = channel.Info
+	// Add to store.
+	err = c.store.CreateAcceptedIntelDeliveryAttempt(ctx, tx, attempt)
+	if err != nil {
+		return meh.Wrap(err, "create accepted intel delivery attempt", nil)
+	}
+	err = c.store.CreateDirectDelivery(ctx, tx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "create direct delivery", nil)
+	}
+	createdDirectDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "retrieve created direct delivery from store", meh.Details{"attempt_id": attempt.ID})
+	}
+	// Notify about accepted attempt.
+	err = c.notifier.NotifyDirectDeliveryReadyForPickup(ctx, tx, attempt, createdDirectDelivery.Note)
+	if err != nil {
+		return meh.Wrap(err, "notify direct delivery ready for pickup", meh.Details{"attempt_id": attempt.Delivery})
+	}
+	c.connUpdateNotifier.scheduleNotifyUpdatesForOperations(ctx, attempt.IntelOperation)
+	return nil
+}
+
+// UpdateIntelDeliveryAttemptStatus updates the status for the given
+// intel-delivery-attempt. If it is not active anymore but has an associated
+// active direct delivery, it will be marked as failed.
+func (c *Controller) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, newStatus store.AcceptedIntelDeliveryAttemptStatus) error {
+	// Update attempt.
+	err := c.store.UpdateAcceptedIntelDeliveryAttemptStatus(ctx, tx, newStatus)
+	if err != nil {
+		if meh.ErrorCode(err) == meh.ErrNotFound {
+			// Skip.
+			return nil
+		}
+		return meh.Wrap(err, "update accepted intel delivery attempt status in store", meh.Details{"new_status": newStatus})
+	}
+	// Only update direct delivery, if attempt is not active anymore and discard
+	// other changes.
+	if newStatus.IsActive {
+		// No changes for direct deliveries -> skip.
+		return nil
+	}
+	// Attempt not active anymore. Update direct delivery if still active.
+	directDelivery, err := c.store.DirectDeliveryByAttempt(ctx, tx, newStatus.ID)
+	if err != nil {
+		return meh.Wrap(err, "direct delivery for attempt from store", meh.Details{"id": newStatus.ID})
+	}
+	if directDelivery.Success.Valid {
+		// Inactive -> skip.
+		return nil
+	}
+	err = c.store.UpdateDirectDeliveryStatusByAttempt(ctx, tx, newStatus.ID, nulls.NewBool(false), "attempt not active anymore")
+	if err != nil {
+		return meh.Wrap(err, "update direct delivery status by attempt", meh.Details{"attempt_id": newStatus.ID})
+	}
+	// Retrieve updated.
+	directDelivery, err = c.store.DirectDeliveryByAttempt(ctx, tx, newStatus.ID)
+	if err != nil {
+		return meh.Wrap(err, "retrieve updated direct delivery", meh.Details{"attempt_id": newStatus.ID})
+	}
+	// Notify.
+	err = c.notifier.NotifyDirectDeliveryFinished(ctx, tx, directDelivery)
+	if err != nil {
+		return meh.Wrap(err, "notify about finished direct delivery", meh.Details{"direct_delivery": directDelivery})
+	}
+	return nil
+}
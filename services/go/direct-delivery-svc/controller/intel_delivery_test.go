@@ -0,0 +1,418 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerCreateIntelDeliveryAttemptSuite tests
+// Controller.CreateIntelDeliveryAttempt.
+type ControllerCreateIntelDeliveryAttemptSuite struct {
+	suite.Suite
+	ctrl                        *ControllerMock
+	tx                          *testutil.DBTx
+	sampleAttempt               store.AcceptedIntelDeliveryAttempt
+	sampleDirectChannel         store.DirectChannel
+	sampleCreatedDirectDelivery store.DirectDelivery
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 950,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "not",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("these"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.sampleDirectChannel = store.DirectChannel{
+		ID:      suite.sampleAttempt.Channel,
+		Entry:   testutil.NewUUIDV4(),
+		Label:   "thicken",
+		Timeout: 530,
+		Info:    "command post",
+	}
+	suite.sampleCreatedDirectDelivery = store.DirectDelivery{
+		Attempt:    suite.sampleAttempt.ID,
+		PickedUpBy: uuid.NullUUID{},
+		PickedUpAt: nulls.Time{},
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "sun",
+	}
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestDirectChannelByIDFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.DirectChannel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestDirectChannelNotFound() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.DirectChannel{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreateAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleDirectChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreateDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleDirectChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreateDirectDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestRetrieveCreatedDirectDelivery() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleDirectChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreateDirectDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.DirectDelivery{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleDirectChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreateDirectDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleCreatedDirectDelivery, nil)
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReadyForPickup", timeout, suite.tx, mock.Anything,
+		suite.sampleCreatedDirectDelivery.Note).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DirectChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleDirectChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx,
+		mock.MatchedBy(func(create store.AcceptedIntelDeliveryAttempt) bool {
+			return create.Info == suite.sampleDirectChannel.Info
+		})).
+		Return(nil)
+	suite.ctrl.Store.On("CreateDirectDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleCreatedDirectDelivery, nil)
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryReadyForPickup", timeout, suite.tx, mock.Anything,
+		suite.sampleCreatedDirectDelivery.Note).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.NoError(err, "should not fail")
+		// Assure notify request scheduled.
+		select {
+		case <-timeout.Done():
+			suite.Fail("no notify request for operation")
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Equal(op, suite.sampleAttempt.IntelOperation, "should have scheduled notify request for operation")
+		}
+	}()
+
+	wait()
+}
+
+func TestController_CreateIntelDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerCreateIntelDeliveryAttemptSuite))
+}
+
+// ControllerUpdateIntelDeliveryAttemptStatusSuite tests
+// Controller.UpdateIntelDeliveryAttemptStatus.
+type ControllerUpdateIntelDeliveryAttemptStatusSuite struct {
+	suite.Suite
+	ctrl                        *ControllerMock
+	tx                          *testutil.DBTx
+	sampleNewStatus             store.AcceptedIntelDeliveryAttemptStatus
+	sampleDirectDelivery        store.DirectDelivery
+	sampleUpdatedDirectDelivery store.DirectDelivery
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleNewStatus = store.AcceptedIntelDeliveryAttemptStatus{
+		ID:       testutil.NewUUIDV4(),
+		IsActive: false,
+		StatusTS: testutil.NewRandomTime(),
+		Note:     nulls.NewString("lovely"),
+	}
+	suite.sampleDirectDelivery = store.DirectDelivery{
+		Attempt:    suite.sampleNewStatus.ID,
+		PickedUpBy: uuid.NullUUID{},
+		PickedUpAt: nulls.Time{},
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "sun",
+	}
+	suite.sampleUpdatedDirectDelivery = suite.sampleDirectDelivery
+	suite.sampleUpdatedDirectDelivery.Success = nulls.NewBool(false)
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestAttemptNotFound() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(meh.NewNotFoundErr("not found", nil)).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestNewActive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleNewStatus.IsActive = true
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestRetrieveDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil)
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestDirectDeliveryAlreadyFinished() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDirectDelivery.Success = nulls.NewBool(false)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestUpdateDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdateDirectDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestRetrieveUpdatedDirectDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdateDirectDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(store.DirectDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdateDirectDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleUpdatedDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryFinished", timeout, suite.tx, suite.sampleUpdatedDirectDelivery).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleDirectDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdateDirectDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("DirectDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleUpdatedDirectDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyDirectDeliveryFinished", timeout, suite.tx, suite.sampleUpdatedDirectDelivery).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateIntelDeliveryAttemptStatus(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateIntelDeliveryAttemptStatusSuite))
+}
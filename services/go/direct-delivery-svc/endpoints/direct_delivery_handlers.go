@@ -0,0 +1,277 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"net/http"
+	"time"
+)
+
+// publicAcceptedIntelDeliveryAttempt is the public representation of
+// store.AcceptedIntelDeliveryAttempt.
+type publicAcceptedIntelDeliveryAttempt struct {
+	ID              uuid.UUID    `json:"id"`
+	Intel           uuid.UUID    `json:"intel"`
+	IntelOperation  uuid.UUID    `json:"intel_operation"`
+	IntelImportance int          `json:"intel_importance"`
+	AssignedTo      uuid.UUID    `json:"assigned_to"`
+	AssignedToLabel string       `json:"assigned_to_label"`
+	Delivery        uuid.UUID    `json:"delivery"`
+	Channel         uuid.UUID    `json:"channel"`
+	CreatedAt       time.Time    `json:"created_at"`
+	StatusTS        time.Time    `json:"status_ts"`
+	Note            nulls.String `json:"note"`
+	AcceptedAt      time.Time    `json:"accepted_at"`
+	Info            string       `json:"info"`
+}
+
+// publicAcceptedIntelDeliveryAttemptFromStore maps
+// store.AcceptedIntelDeliveryAttempt to publicAcceptedIntelDeliveryAttempt.
+func publicAcceptedIntelDeliveryAttemptFromStore(s store.AcceptedIntelDeliveryAttempt) publicAcceptedIntelDeliveryAttempt {
+	return publicAcceptedIntelDeliveryAttempt{
+		ID:              s.ID,
+		Intel:           s.Intel,
+		IntelOperation:  s.IntelOperation,
+		IntelImportance: s.IntelImportance,
+		AssignedTo:      s.AssignedTo,
+		AssignedToLabel: s.AssignedToLabel,
+		Delivery:        s.Delivery,
+		Channel:         s.Channel,
+		CreatedAt:       s.CreatedAt,
+		StatusTS:        s.StatusTS,
+		Note:            s.Note,
+		AcceptedAt:      s.AcceptedAt,
+		Info:            s.Info,
+	}
+}
+
+// handleGetNextDirectDeliveryStore are the dependencies needed for
+// handleGetNextDirectDelivery.
+type handleGetNextDirectDeliveryStore interface {
+	PickUpNextDirectDelivery(ctx context.Context, operationID uuid.UUID, by uuid.UUID) (store.AcceptedIntelDeliveryAttempt, bool, error)
+}
+
+// handleGetNextDirectDelivery picks up the next direct delivery. If none is
+// found, a http.StatusNoContent will be returned.
+func handleGetNextDirectDelivery(s handleGetNextDirectDeliveryStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract operation id.
+		operationIDStr := c.Param("operationID")
+		operationID, err := uuid.FromString(operationIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse operation id", meh.Details{"was": operationIDStr})
+		}
+		// Check permissions.
+		err = auth.AssurePermission(token, permission.DeliverAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Pick up next.
+		sAttempt, ok, err := s.PickUpNextDirectDelivery(c.Request.Context(), operationID, token.UserID)
+		if err != nil {
+			return meh.Wrap(err, "pick up next direct delivery", meh.Details{
+				"operation_id": operationID,
+				"by":           token.UserID,
+			})
+		}
+		if !ok {
+			c.Status(http.StatusNoContent)
+			return nil
+		}
+		c.JSON(http.StatusOK, publicAcceptedIntelDeliveryAttemptFromStore(sAttempt))
+		return nil
+	}
+}
+
+// handleReleasePickedUpDirectDeliveryStore are the dependencies needed for
+// handleReleasePickedUpDirectDelivery.
+type handleReleasePickedUpDirectDeliveryStore interface {
+	ReleasePickedUpDirectDelivery(ctx context.Context, attemptID uuid.UUID, limitToPickedUpBy uuid.NullUUID) error
+}
+
+// handleReleasePickedUpDirectDelivery releases the given picked up direct
+// delivery.
+func handleReleasePickedUpDirectDelivery(s handleReleasePickedUpDirectDeliveryStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Check permissions.
+		deliveryGranted, err := auth.HasPermission(token, permission.DeliverAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check deliver-permission", nil)
+		}
+		manageDeliveriesGranted, err := auth.HasPermission(token, permission.ManageAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check manage-permission", nil)
+		}
+		if !deliveryGranted && !manageDeliveriesGranted {
+			return meh.NewForbiddenErr("missing permission", nil)
+		}
+		limitToPickedUpBy := nulls.NewUUID(token.UserID)
+		if manageDeliveriesGranted {
+			limitToPickedUpBy = uuid.NullUUID{}
+		}
+		// Extract attempt id.
+		attemptIDStr := c.Param("attemptID")
+		attemptID, err := uuid.FromString(attemptIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
+		}
+		// Release.
+		err = s.ReleasePickedUpDirectDelivery(c.Request.Context(), attemptID, limitToPickedUpBy)
+		if err != nil {
+			return meh.Wrap(err, "release picked up direct delivery", meh.Details{
+				"attempt_id":            attemptID,
+				"limit_to_picked_up_by": limitToPickedUpBy,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// publicFinishDirectDeliveryDetails is the expected payload in
+// handleFinishDirectDelivery with details regarding finishing a direct delivery.
+type publicFinishDirectDeliveryDetails struct {
+	Success bool   `json:"success"`
+	Note    string `json:"note"`
+}
+
+// handleFinishDirectDeliveryStore are the dependencies needed for
+// handleFinishDirectDelivery.
+type handleFinishDirectDeliveryStore interface {
+	FinishDirectDelivery(ctx context.Context, attemptID uuid.UUID, success bool, note string, limitToPickedUpBy uuid.NullUUID) error
+}
+
+// handleFinishDirectDelivery finishes the direct delivery for the given attempt.
+func handleFinishDirectDelivery(s handleFinishDirectDeliveryStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Check permissions.
+		deliveryGranted, err := auth.HasPermission(token, permission.DeliverAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check deliver-permission", nil)
+		}
+		manageDeliveriesGranted, err := auth.HasPermission(token, permission.ManageAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check manage-permission", nil)
+		}
+		if !deliveryGranted && !manageDeliveriesGranted {
+			return meh.NewForbiddenErr("missing permission", nil)
+		}
+		limitToPickedUpBy := nulls.NewUUID(token.UserID)
+		if manageDeliveriesGranted {
+			limitToPickedUpBy = uuid.NullUUID{}
+		}
+		// Extract attempt id.
+		attemptIDStr := c.Param("attemptID")
+		attemptID, err := uuid.FromString(attemptIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
+		}
+		// Parse body.
+		var finishDetails publicFinishDirectDeliveryDetails
+		err = c.BindJSON(&finishDetails)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		// Finish.
+		err = s.FinishDirectDelivery(c.Request.Context(), attemptID, finishDetails.Success, finishDetails.Note, limitToPickedUpBy)
+		if err != nil {
+			return meh.Wrap(err, "finish direct delivery", meh.Details{
+				"attempt_id":            attemptID,
+				"success":               finishDetails.Success,
+				"note":                  finishDetails.Note,
+				"limit_to_picked_up_by": limitToPickedUpBy,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// publicHandOverDirectDeliveryDetails is the expected payload in
+// handleHandOverDirectDelivery with details regarding the hand-over of a direct
+// delivery.
+type publicHandOverDirectDeliveryDetails struct {
+	HandedOverAt          nulls.Time `json:"handed_over_at"`
+	RecipientConfirmation string     `json:"recipient_confirmation"`
+	Note                  string     `json:"note"`
+}
+
+// handleHandOverDirectDeliveryStore are the dependencies needed for
+// handleHandOverDirectDelivery.
+type handleHandOverDirectDeliveryStore interface {
+	HandOverDirectDelivery(ctx context.Context, attemptID uuid.UUID, handedOverAt time.Time, recipientConfirmation string,
+		note string, limitToPickedUpBy uuid.NullUUID) error
+}
+
+// handleHandOverDirectDelivery records the hand-over of the direct delivery for
+// the given attempt. If no hand-over timestamp is provided, the current time is
+// used.
+func handleHandOverDirectDelivery(s handleHandOverDirectDeliveryStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Check permissions.
+		deliveryGranted, err := auth.HasPermission(token, permission.DeliverAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check deliver-permission", nil)
+		}
+		manageDeliveriesGranted, err := auth.HasPermission(token, permission.ManageAnyDirectDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check manage-permission", nil)
+		}
+		if !deliveryGranted && !manageDeliveriesGranted {
+			return meh.NewForbiddenErr("missing permission", nil)
+		}
+		limitToPickedUpBy := nulls.NewUUID(token.UserID)
+		if manageDeliveriesGranted {
+			limitToPickedUpBy = uuid.NullUUID{}
+		}
+		// Extract attempt id.
+		attemptIDStr := c.Param("attemptID")
+		attemptID, err := uuid.FromString(attemptIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
+		}
+		// Parse body.
+		var handOverDetails publicHandOverDirectDeliveryDetails
+		err = c.BindJSON(&handOverDetails)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		handedOverAt := time.Now()
+		if handOverDetails.HandedOverAt.Valid {
+			handedOverAt = handOverDetails.HandedOverAt.Time
+		}
+		// Hand over.
+		err = s.HandOverDirectDelivery(c.Request.Context(), attemptID, handedOverAt, handOverDetails.RecipientConfirmation,
+			handOverDetails.Note, limitToPickedUpBy)
+		if err != nil {
+			return meh.Wrap(err, "hand over direct delivery", meh.Details{
+				"attempt_id":             attemptID,
+				"handed_over_at":         handedOverAt,
+				"recipient_confirmation": handOverDetails.RecipientConfirmation,
+				"note":                   handOverDetails.Note,
+				"limit_to_picked_up_by":  limitToPickedUpBy,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
@@ -0,0 +1,662 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// handleGetNextDirectDeliverySuite tests handleGetNextDirectDelivery.
+type handleGetNextDirectDeliverySuite struct {
+	suite.Suite
+	s                        *StoreMock
+	r                        *gin.Engine
+	sampleToken              auth.Token
+	sampleStoreNextDelivery  store.AcceptedIntelDeliveryAttempt
+	samplePublicNextDelivery publicAcceptedIntelDeliveryAttempt
+}
+
+func (suite *handleGetNextDirectDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}},
+	}
+	suite.sampleStoreNextDelivery = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 884,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "baggage",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("front"),
+		AcceptedAt:      testutil.NewRandomTime(),
+		Info:            "command post",
+	}
+	suite.samplePublicNextDelivery = publicAcceptedIntelDeliveryAttemptFromStore(suite.sampleStoreNextDelivery)
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestInvalidOperationID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/operations/abc/next",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestPickUpNextFail() {
+	suite.s.On("PickUpNextDirectDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, false, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestNoNext() {
+	suite.s.On("PickUpNextDirectDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, false, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusNoContent, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextDirectDeliverySuite) TestOK() {
+	suite.s.On("PickUpNextDirectDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(suite.sampleStoreNextDelivery, true, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got publicAcceptedIntelDeliveryAttempt
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicNextDelivery, got, "should return correct body")
+}
+
+func Test_handleGetNextDirectDelivery(t *testing.T) {
+	suite.Run(t, new(handleGetNextDirectDeliverySuite))
+}
+
+// handleReleasePickedUpDirectDeliverySuite tests
+// handleReleasePickedUpDirectDelivery.
+type handleReleasePickedUpDirectDeliverySuite struct {
+	suite.Suite
+	s               *StoreMock
+	r               *gin.Engine
+	sampleToken     auth.Token
+	sampleAttemptID uuid.UUID
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}},
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/release",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestReleaseFail() {
+	suite.s.On("ReleasePickedUpDirectDelivery", mock.Anything, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestOK() {
+	suite.s.On("ReleasePickedUpDirectDelivery", mock.Anything, suite.sampleAttemptID, mock.Anything).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestOnlyDeliverPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}}
+	suite.s.On("ReleasePickedUpDirectDelivery", mock.Anything, suite.sampleAttemptID, nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestOnlyManagePermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.ManageAnyDirectDeliveryPermissionName}}
+	suite.s.On("ReleasePickedUpDirectDelivery", mock.Anything, suite.sampleAttemptID, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpDirectDeliverySuite) TestDeliverAndManagePermissions() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		{Name: permission.DeliverAnyDirectDeliveryPermissionName},
+		{Name: permission.ManageAnyDirectDeliveryPermissionName},
+	}
+	suite.s.On("ReleasePickedUpDirectDelivery", mock.Anything, suite.sampleAttemptID, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleReleasePickedUpDirectDelivery(t *testing.T) {
+	suite.Run(t, new(handleReleasePickedUpDirectDeliverySuite))
+}
+
+// handleFinishDirectDeliverySuite tests
+// handleFinishDirectDelivery.
+type handleFinishDirectDeliverySuite struct {
+	suite.Suite
+	s                         *StoreMock
+	r                         *gin.Engine
+	sampleToken               auth.Token
+	sampleAttemptID           uuid.UUID
+	samplePublicFinishDetails publicFinishDirectDeliveryDetails
+}
+
+func (suite *handleFinishDirectDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}},
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePublicFinishDetails = publicFinishDirectDeliveryDetails{
+		Success: true,
+		Note:    "seat",
+	}
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/finish",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   strings.NewReader(`{invalid`),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestFinishFail() {
+	suite.s.On("FinishDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestOK() {
+	suite.s.On("FinishDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, mock.Anything).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestOnlyDeliverPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}}
+	suite.s.On("FinishDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestOnlyManagePermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.ManageAnyDirectDeliveryPermissionName}}
+	suite.s.On("FinishDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishDirectDeliverySuite) TestDeliverAndManagePermissions() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		{Name: permission.DeliverAnyDirectDeliveryPermissionName},
+		{Name: permission.ManageAnyDirectDeliveryPermissionName},
+	}
+	suite.s.On("FinishDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleFinishDirectDelivery(t *testing.T) {
+	suite.Run(t, new(handleFinishDirectDeliverySuite))
+}
+
+// handleHandOverDirectDeliverySuite tests handleHandOverDirectDelivery.
+type handleHandOverDirectDeliverySuite struct {
+	suite.Suite
+	s                           *StoreMock
+	r                           *gin.Engine
+	sampleToken                 auth.Token
+	sampleAttemptID             uuid.UUID
+	samplePublicHandOverDetails publicHandOverDirectDeliveryDetails
+}
+
+func (suite *handleHandOverDirectDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}},
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePublicHandOverDetails = publicHandOverDirectDeliveryDetails{
+		HandedOverAt:          nulls.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)),
+		RecipientConfirmation: "Max Mustermann",
+		Note:                  "gate",
+	}
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/hand-over",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   strings.NewReader(`{invalid`),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestHandOverFail() {
+	suite.s.On("HandOverDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicHandOverDetails.HandedOverAt.Time,
+		suite.samplePublicHandOverDetails.RecipientConfirmation, suite.samplePublicHandOverDetails.Note, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestDefaultHandedOverAt() {
+	suite.samplePublicHandOverDetails.HandedOverAt = nulls.Time{}
+	suite.s.On("HandOverDirectDelivery", mock.Anything, suite.sampleAttemptID, mock.AnythingOfType("time.Time"),
+		suite.samplePublicHandOverDetails.RecipientConfirmation, suite.samplePublicHandOverDetails.Note, mock.Anything).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestOnlyDeliverPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.DeliverAnyDirectDeliveryPermissionName}}
+	suite.s.On("HandOverDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicHandOverDetails.HandedOverAt.Time,
+		suite.samplePublicHandOverDetails.RecipientConfirmation, suite.samplePublicHandOverDetails.Note,
+		nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleHandOverDirectDeliverySuite) TestManagePermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.ManageAnyDirectDeliveryPermissionName}}
+	suite.s.On("HandOverDirectDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicHandOverDetails.HandedOverAt.Time,
+		suite.samplePublicHandOverDetails.RecipientConfirmation, suite.samplePublicHandOverDetails.Note, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/hand-over", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicHandOverDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleHandOverDirectDelivery(t *testing.T) {
+	suite.Run(t, new(handleHandOverDirectDeliverySuite))
+}
@@ -0,0 +1,98 @@
+package eventport
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"time"
+)
+
+// NotifyDirectDeliveryReadyForPickup emeits an
+// event.TypeDirectDeliveryReadyForPickup event.
+func (p *Port) NotifyDirectDeliveryReadyForPickup(ctx context.Context, tx pgx.Tx, intelDeliveryAttempt store.AcceptedIntelDeliveryAttempt,
+	directDeliveryNote string) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       intelDeliveryAttempt.ID.String(),
+		EventType: event.TypeDirectDeliveryReadyForPickup,
+		Value: event.DirectDeliveryReadyForPickup{
+			Attempt:                intelDeliveryAttempt.ID,
+			Intel:                  intelDeliveryAttempt.Intel,
+			IntelOperation:         intelDeliveryAttempt.IntelOperation,
+			IntelImportance:        intelDeliveryAttempt.IntelImportance,
+			AttemptAssignedTo:      intelDeliveryAttempt.AssignedTo,
+			AttemptAssignedToLabel: intelDeliveryAttempt.AssignedToLabel,
+			Delivery:               intelDeliveryAttempt.Delivery,
+			Channel:                intelDeliveryAttempt.Channel,
+			Note:                   directDeliveryNote,
+			AttemptAcceptedAt:      intelDeliveryAttempt.AcceptedAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyDirectDeliveryPickedUp emits an event.NotifyDirectDeliveryPickedUp event.
+func (p *Port) NotifyDirectDeliveryPickedUp(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, pickedUpBy uuid.UUID,
+	pickedUpAt time.Time) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeDirectDeliveryPickedUp,
+		Value: event.DirectDeliveryPickedUp{
+			Attempt:    attemptID,
+			PickedUpBy: pickedUpBy,
+			PickedUpAt: pickedUpAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyDirectDeliveryReleased emits an event.NotifyDirectDeliveryReleased event.
+func (p *Port) NotifyDirectDeliveryReleased(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, releasedAt time.Time) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeDirectDeliveryReleased,
+		Value: event.DirectDeliveryReleased{
+			Attempt:    attemptID,
+			ReleasedAt: releasedAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyDirectDeliveryFinished emits an event.NotifyDirectDeliveryFinished event.
+func (p *Port) NotifyDirectDeliveryFinished(ctx context.Context, tx pgx.Tx, directDelivery store.DirectDelivery) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       directDelivery.Attempt.String(),
+		EventType: event.TypeDirectDeliveryFinished,
+		Value: event.DirectDeliveryFinished{
+			Attempt:               directDelivery.Attempt,
+			PickedUpBy:            directDelivery.PickedUpBy,
+			PickedUpAt:            directDelivery.PickedUpAt,
+			Success:               directDelivery.Success.Bool,
+			FinishedAt:            directDelivery.SuccessTS,
+			Note:                  directDelivery.Note,
+			HandedOverAt:          directDelivery.HandedOverAt,
+			RecipientConfirmation: directDelivery.RecipientConfirmation,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package eventport
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/direct-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// PortNotifyDirectDeliveryReadyForPickupSuite tests
+// Port.NotifyDirectDeliveryReadyForPickup.
+type PortNotifyDirectDeliveryReadyForPickupSuite struct {
+	suite.Suite
+	port                       *PortMock
+	sampleIntelDeliveryAttempt store.AcceptedIntelDeliveryAttempt
+	sampleDirectDeliveryNote   string
+	expectedMessage            kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyDirectDeliveryReadyForPickupSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleIntelDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 403,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "explore",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("gaiety"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.sampleDirectDeliveryNote = "overflow"
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       suite.sampleIntelDeliveryAttempt.ID.String(),
+		EventType: event.TypeDirectDeliveryReadyForPickup,
+		Value: event.DirectDeliveryReadyForPickup{
+			Attempt:                suite.sampleIntelDeliveryAttempt.ID,
+			Intel:                  suite.sampleIntelDeliveryAttempt.Intel,
+			IntelOperation:         suite.sampleIntelDeliveryAttempt.IntelOperation,
+			IntelImportance:        suite.sampleIntelDeliveryAttempt.IntelImportance,
+			AttemptAssignedTo:      suite.sampleIntelDeliveryAttempt.AssignedTo,
+			AttemptAssignedToLabel: suite.sampleIntelDeliveryAttempt.AssignedToLabel,
+			Delivery:               suite.sampleIntelDeliveryAttempt.Delivery,
+			Channel:                suite.sampleIntelDeliveryAttempt.Channel,
+			Note:                   suite.sampleDirectDeliveryNote,
+			AttemptAcceptedAt:      suite.sampleIntelDeliveryAttempt.AcceptedAt,
+		},
+	}
+}
+
+func (suite *PortNotifyDirectDeliveryReadyForPickupSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryReadyForPickup(timeout, &testutil.DBTx{},
+			suite.sampleIntelDeliveryAttempt, suite.sampleDirectDeliveryNote)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyDirectDeliveryReadyForPickupSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryReadyForPickup(timeout, &testutil.DBTx{},
+			suite.sampleIntelDeliveryAttempt, suite.sampleDirectDeliveryNote)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyDirectDeliveryReadyForPickup(t *testing.T) {
+	suite.Run(t, new(PortNotifyDirectDeliveryReadyForPickupSuite))
+}
+
+// PortNotifyDirectDeliveryPickedUpSuite tests Port.NotifyDirectDeliveryPickedUp.
+type PortNotifyDirectDeliveryPickedUpSuite struct {
+	suite.Suite
+	port             *PortMock
+	sampleAttemptID  uuid.UUID
+	samplePickedUpBy uuid.UUID
+	samplePickedUpAt time.Time
+	expectedMessage  kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyDirectDeliveryPickedUpSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePickedUpBy = testutil.NewUUIDV4()
+	suite.samplePickedUpAt = testutil.NewRandomTime()
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       suite.sampleAttemptID.String(),
+		EventType: event.TypeDirectDeliveryPickedUp,
+		Value: event.DirectDeliveryPickedUp{
+			Attempt:    suite.sampleAttemptID,
+			PickedUpBy: suite.samplePickedUpBy,
+			PickedUpAt: suite.samplePickedUpAt,
+		},
+	}
+}
+
+func (suite *PortNotifyDirectDeliveryPickedUpSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryPickedUp(timeout, &testutil.DBTx{},
+			suite.sampleAttemptID, suite.samplePickedUpBy, suite.samplePickedUpAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyDirectDeliveryPickedUpSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryPickedUp(timeout, &testutil.DBTx{},
+			suite.sampleAttemptID, suite.samplePickedUpBy, suite.samplePickedUpAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyDirectDeliveryPickedUp(t *testing.T) {
+	suite.Run(t, new(PortNotifyDirectDeliveryPickedUpSuite))
+}
+
+// PortNotifyDirectDeliveryReleasedSuite tests Port.NotifyDirectDeliveryReleased.
+type PortNotifyDirectDeliveryReleasedSuite struct {
+	suite.Suite
+	port             *PortMock
+	sampleAttemptID  uuid.UUID
+	sampleReleasedAt time.Time
+	expectedMessage  kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyDirectDeliveryReleasedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleReleasedAt = testutil.NewRandomTime()
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       suite.sampleAttemptID.String(),
+		EventType: event.TypeDirectDeliveryReleased,
+		Value: event.DirectDeliveryReleased{
+			Attempt:    suite.sampleAttemptID,
+			ReleasedAt: suite.sampleReleasedAt,
+		},
+	}
+}
+
+func (suite *PortNotifyDirectDeliveryReleasedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryReleased(timeout, &testutil.DBTx{}, suite.sampleAttemptID, suite.sampleReleasedAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyDirectDeliveryReleasedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryReleased(timeout, &testutil.DBTx{}, suite.sampleAttemptID, suite.sampleReleasedAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyDirectDeliveryReleased(t *testing.T) {
+	suite.Run(t, new(PortNotifyDirectDeliveryReleasedSuite))
+}
+
+// PortNotifyDirectDeliveryFinishedSuite tests Port.NotifyDirectDeliveryFinished.
+type PortNotifyDirectDeliveryFinishedSuite struct {
+	suite.Suite
+	port                 *PortMock
+	sampleDirectDelivery store.DirectDelivery
+	expectedMessage      kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyDirectDeliveryFinishedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleDirectDelivery = store.DirectDelivery{
+		Attempt:               testutil.NewUUIDV4(),
+		PickedUpBy:            nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt:            nulls.NewTime(testutil.NewRandomTime()),
+		Success:               nulls.NewBool(true),
+		SuccessTS:             testutil.NewRandomTime(),
+		Note:                  "cape",
+		HandedOverAt:          nulls.NewTime(testutil.NewRandomTime()),
+		RecipientConfirmation: nulls.NewString("Max Mustermann"),
+	}
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		Key:       suite.sampleDirectDelivery.Attempt.String(),
+		EventType: event.TypeDirectDeliveryFinished,
+		Value: event.DirectDeliveryFinished{
+			Attempt:               suite.sampleDirectDelivery.Attempt,
+			PickedUpBy:            suite.sampleDirectDelivery.PickedUpBy,
+			PickedUpAt:            suite.sampleDirectDelivery.PickedUpAt,
+			Success:               suite.sampleDirectDelivery.Success.Bool,
+			FinishedAt:            suite.sampleDirectDelivery.SuccessTS,
+			Note:                  suite.sampleDirectDelivery.Note,
+			HandedOverAt:          suite.sampleDirectDelivery.HandedOverAt,
+			RecipientConfirmation: suite.sampleDirectDelivery.RecipientConfirmation,
+		},
+	}
+}
+
+func (suite *PortNotifyDirectDeliveryFinishedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryFinished(timeout, &testutil.DBTx{}, suite.sampleDirectDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyDirectDeliveryFinishedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyDirectDeliveryFinished(timeout, &testutil.DBTx{}, suite.sampleDirectDelivery)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyDirectDeliveryFinished(t *testing.T) {
+	suite.Run(t, new(PortNotifyDirectDeliveryFinishedSuite))
+}
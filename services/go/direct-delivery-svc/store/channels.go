@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"time"
+)
+
+// DirectChannel that is used for delivery-attempts.
+type DirectChannel struct {
+	// ID identifies the channel.
+	ID uuid.UUID
+	// Entry is the id of the referenced address book entry.
+	Entry uuid.UUID
+	// Label is a human-readable label for the channel.
+	Label string
+	// Timeout until delivery-attempts time out for this channel.
+	Timeout time.Duration
+	// Info for how to reach the recipient.
+	Info string
+}
+
+// CreateDirectChannel creates the given DirectChannel in the database.
+func (m *Mall) CreateDirectChannel(ctx context.Context, tx pgx.Tx, create DirectChannel) error {
+	q, _, err := m.dialect.Insert(goqu.T("direct_channels")).Rows(goqu.Record{
+		"id":      create.ID,
+		"entry":   create.Entry,
+		"label":   create.Label,
+		"timeout": create.Timeout,
+		"info":    create.Info,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// DeleteDirectChannelsByEntry deletes the direct channels in the database
+// associated with the address book entry with the given id.
+func (m *Mall) DeleteDirectChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("direct_channels")).
+		Where(goqu.C("entry").Eq(entryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// DirectChannelByID retrieves the DirectChannel from the database with the given
+// id.
+func (m *Mall) DirectChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (DirectChannel, error) {
+	q, _, err := m.dialect.From(goqu.T("direct_channels")).
+		Select(goqu.C("id"),
+			goqu.C("entry"),
+			goqu.C("label"),
+			goqu.C("timeout"),
+			goqu.C("info")).
+		Where(goqu.C("id").Eq(channelID)).ToSQL()
+	if err != nil {
+		return DirectChannel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return DirectChannel{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return DirectChannel{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var channel DirectChannel
+	err = rows.Scan(&channel.ID,
+		&channel.Entry,
+		&channel.Label,
+		&channel.Timeout,
+		&channel.Info)
+	if err != nil {
+		return DirectChannel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return channel, nil
+}
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// DirectDelivery represents a request for delivering intel in-person.
+type DirectDelivery struct {
+	// Attempt is the id of the delivery-attempt this direct-delivery is for.
+	Attempt uuid.UUID
+	// PickedUpBy is the id of the user that is responsible for this delivery.
+	PickedUpBy uuid.NullUUID
+	// PickedUpAt is the timestamp when the user from PickedUpBy was assigned.
+	PickedUpAt nulls.Time
+	// Success is not set, when the delivery is still awaiting results or open.
+	Success nulls.Bool
+	// SuccessTS is the timestamp of the last update of Success.
+	SuccessTS time.Time
+	// Note holds information regarding the delivery and/or Success.
+	Note string
+	// HandedOverAt is the timestamp when the intel was handed over to the
+	// recipient.
+	HandedOverAt nulls.Time
+	// RecipientConfirmation is the confirmation of the recipient for the
+	// hand-over, for example the name of the person that received the intel.
+	RecipientConfirmation nulls.String
+}
+
+// DirectDeliveryByAttempt retrieves the DirectDelivery for the attempt with the
+// given id.
+func (m *Mall) DirectDeliveryByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (DirectDelivery, error) {
+	q, _, err := m.dialect.From(goqu.T("direct_deliveries")).
+		Select(goqu.C("attempt"),
+			goqu.C("picked_up_by"),
+			goqu.C("picked_up_at"),
+			goqu.C("success"),
+			goqu.C("success_ts"),
+			goqu.C("note"),
+			goqu.C("handed_over_at"),
+			goqu.C("recipient_confirmation")).
+		Where(goqu.C("attempt").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return DirectDelivery{}, meh.NewBadInputErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return DirectDelivery{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return DirectDelivery{}, meh.NewNotFoundErr("not found", nil)
+	}
+	var rd DirectDelivery
+	err = rows.Scan(&rd.Attempt,
+		&rd.PickedUpBy,
+		&rd.PickedUpAt,
+		&rd.Success,
+		&rd.SuccessTS,
+		&rd.Note,
+		&rd.HandedOverAt,
+		&rd.RecipientConfirmation)
+	if err != nil {
+		return DirectDelivery{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return rd, nil
+}
+
+// CreateDirectDelivery creates a DirectDelivery for the attempt with the given
+// id.
+func (m *Mall) CreateDirectDelivery(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) error {
+	q, _, err := m.dialect.Insert(goqu.T("direct_deliveries")).Rows(goqu.Record{
+		"attempt":      attemptID,
+		"picked_up_by": uuid.NullUUID{},
+		"picked_up_at": nulls.Time{},
+		"success":      nulls.Bool{},
+		"success_ts":   time.Now(),
+		"note":         "waiting for pickup",
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// MarkDirectDeliveryAsPickedUpByAttempt marks the direct-delivery for the given
+// attempt as picked-up by the user with the given id. If no user id is given,
+// it will be unassigned.
+func (m *Mall) MarkDirectDeliveryAsPickedUpByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID,
+	by uuid.NullUUID, newNote string) error {
+	var pickedUpAt nulls.Time
+	if by.Valid {
+		pickedUpAt = nulls.NewTime(time.Now())
+	}
+	q, _, err := m.dialect.Update(goqu.T("direct_deliveries")).Set(goqu.Record{
+		"picked_up_by": by,
+		"picked_up_at": pickedUpAt,
+		"note":         newNote,
+	}).Where(goqu.C("attempt").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// UpdateDirectDeliveryStatusByAttempt updates the direct-delivery for the given
+// attempt with the given new success-status and note.
+func (m *Mall) UpdateDirectDeliveryStatusByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID,
+	newSuccess nulls.Bool, newNote string) error {
+	q, _, err := m.dialect.Update(goqu.T("direct_deliveries")).Set(goqu.Record{
+		"success":    newSuccess,
+		"success_ts": time.Now(),
+		"note":       newNote,
+	}).Where(goqu.C("attempt").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// MarkDirectDeliveryAsHandedOverByAttempt marks the direct-delivery for the
+// given attempt as successful with the given hand-over details.
+func (m *Mall) MarkDirectDeliveryAsHandedOverByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID,
+	handedOverAt time.Time, recipientConfirmation string, newNote string) error {
+	q, _, err := m.dialect.Update(goqu.T("direct_deliveries")).Set(goqu.Record{
+		"success":                true,
+		"success_ts":             time.Now(),
+		"note":                   newNote,
+		"handed_over_at":         handedOverAt.UTC(),
+		"recipient_confirmation": recipientConfirmation,
+	}).Where(goqu.C("attempt").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// ActiveDirectDelivery is used in Mall.ActiveDirectDeliveriesAndLockOrWait for
+// retrieving all active deliveries in order to choose the next one for
+// assigning to a user.
+type ActiveDirectDelivery struct {
+	// Attempt is the id of the referenced attempt for the direct-delivery.
+	Attempt uuid.UUID
+	// PickedUpAt is the set, when the delivery is picked up.
+	PickedUpAt nulls.Time
+	// IntelOperation is the assigned operation of the referenced intel to deliver.
+	IntelOperation uuid.UUID
+	// IntelImportance is the importance of the intel to deliver for priorization.
+	IntelImportance int
+	// AttemptCreatedAt is the timestamp when the delivery-attempt was created in
+	// order to avoid starvation.
+	AttemptCreatedAt time.Time
+}
+
+// ActiveDirectDeliveriesAndLockOrWait locks and retrieves all direct-deliveries
+// being active (success is NULL) from the database.
+func (m *Mall) ActiveDirectDeliveriesAndLockOrWait(ctx context.Context, tx pgx.Tx, byOperation uuid.NullUUID) ([]ActiveDirectDelivery, error) {
+	qb := m.dialect.From(goqu.T("direct_deliveries")).
+		InnerJoin(goqu.T("accepted_intel_delivery_attempts"),
+			goqu.On(goqu.I("accepted_intel_delivery_attempts.id").Eq(goqu.I("direct_deliveries.attempt")))).
+		Select(goqu.I("direct_deliveries.attempt"),
+			goqu.I("direct_deliveries.picked_up_at"),
+			goqu.I("accepted_intel_delivery_attempts.intel_operation"),
+			goqu.I("accepted_intel_delivery_attempts.intel_importance"),
+			goqu.I("accepted_intel_delivery_attempts.created_at")).
+		ForUpdate(exp.Wait).
+		Where(goqu.I("direct_deliveries.success").IsNull())
+	if byOperation.Valid {
+		qb = qb.Where(goqu.I("accepted_intel_delivery_attempts.intel_operation").Eq(byOperation.UUID))
+	}
+	q, _, err := qb.ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	directDeliveries := make([]ActiveDirectDelivery, 0)
+	for rows.Next() {
+		var rd ActiveDirectDelivery
+		err = rows.Scan(&rd.Attempt,
+			&rd.PickedUpAt,
+			&rd.IntelOperation,
+			&rd.IntelImportance,
+			&rd.AttemptCreatedAt)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		directDeliveries = append(directDeliveries, rd)
+	}
+	return directDeliveries, nil
+}
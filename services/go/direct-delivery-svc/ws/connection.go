@@ -0,0 +1,67 @@
+package ws
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/wsutil"
+)
+
+const (
+	// messageTypeNewDirectDeliveriesAvailable is used in connection.Notify for
+	// notifying about new availabe direct deliveries.
+	messageTypeNewDirectDeliveriesAvailable wsutil.MessageType = "new-direct-deliveries-available"
+)
+
+// publicNewDirectDeliveriesAvailable holds the operation which might have new
+// direct deliveries available.
+type publicNewDirectDeliveriesAvailable struct {
+	Operation uuid.UUID `json:"operation"`
+}
+
+// connection implements controller.Connection and maps notifications from the
+// store-representation to the public one.
+type connection struct {
+	conn wsutil.AutoParserConnection
+}
+
+// newConnection returns a new connection.
+func newConnection(conn wsutil.AutoParserConnection) *connection {
+	return &connection{conn: conn}
+}
+
+// UserID returns the user id from the connection's auth-token.
+func (conn *connection) UserID() uuid.UUID {
+	return conn.conn.AuthToken().UserID
+}
+
+// NotifyNewAvailable sends a message with
+// messageTypeNewDirectDeliveriesAvailable for the operation with the given id.
+func (conn *connection) NotifyNewAvailable(ctx context.Context, operationID uuid.UUID) error {
+	deliverGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.DeliverAnyDirectDelivery())
+	if err != nil {
+		return meh.Wrap(err, "check permission", nil)
+	}
+	manageGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.ManageAnyDirectDelivery())
+	if err != nil {
+		return meh.Wrap(err, "check permission", nil)
+	}
+	if !deliverGranted && !manageGranted {
+		return nil
+	}
+	payload := publicNewDirectDeliveriesAvailable{
+		Operation: operationID,
+	}
+	err = conn.conn.Send(ctx, messageTypeNewDirectDeliveriesAvailable, payload)
+	if err != nil {
+		return meh.Wrap(err, "send over connection", meh.Details{"payload": payload})
+	}
+	return nil
+}
+
+// Done returns the done-channel from the connection.
+func (conn *connection) Done() <-chan struct{} {
+	return conn.conn.Lifetime().Done()
+}
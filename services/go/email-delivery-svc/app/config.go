@@ -0,0 +1,53 @@
+package app
+
+import (
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/basicconfig"
+	"os"
+)
+
+const (
+	// envSMTPAddr for config.SMTPAddr.
+	envSMTPAddr = "MDS_SMTP_ADDR"
+	// envSMTPUsername for config.SMTPUsername.
+	envSMTPUsername = "MDS_SMTP_USERNAME"
+	// envSMTPPassword for config.SMTPPassword.
+	envSMTPPassword = "MDS_SMTP_PASSWORD"
+	// envSMTPFrom for config.SMTPFrom.
+	envSMTPFrom = "MDS_SMTP_FROM"
+)
+
+type config struct {
+	basicconfig.Config
+	// SMTPAddr is the address of the SMTP server to use for sending emails.
+	SMTPAddr string `json:"smtp_addr"`
+	// SMTPUsername is the optional username for authenticating at the SMTP server.
+	SMTPUsername string `json:"smtp_username"`
+	// SMTPPassword is the password for SMTPUsername.
+	SMTPPassword string `json:"-"`
+	// SMTPFrom is the sender address for sent emails.
+	SMTPFrom string `json:"smtp_from"`
+}
+
+func parseConfigFromEnv() (config, error) {
+	var c config
+	var err error
+	c.Config, err = basicconfig.ParseFromEnv()
+	if err != nil {
+		return config{}, meh.Wrap(err, "parse basic config from env", nil)
+	}
+	// SMTP address.
+	c.SMTPAddr = os.Getenv(envSMTPAddr)
+	if c.SMTPAddr == "" {
+		return config{}, meh.NewBadInputErr("missing smtp address", meh.Details{"env": envSMTPAddr})
+	}
+	// SMTP credentials.
+	c.SMTPUsername = os.Getenv(envSMTPUsername)
+	c.SMTPPassword = os.Getenv(envSMTPPassword)
+	// Sender address.
+	c.SMTPFrom = os.Getenv(envSMTPFrom)
+	if c.SMTPFrom == "" {
+		return config{}, meh.NewBadInputErr("missing smtp sender address", meh.Details{"env": envSMTPFrom})
+	}
+	return c, nil
+}
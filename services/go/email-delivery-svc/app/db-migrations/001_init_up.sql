@@ -0,0 +1,89 @@
+-- Activate UUID support.
+create extension if not exists "uuid-ossp";
+
+-- Create users table.
+
+create table users
+(
+    id         uuid primary key not null,
+    username   varchar          not null,
+    first_name varchar          not null,
+    last_name  varchar          not null,
+    is_active  bool             not null
+);
+
+-- Create channels table.
+
+create table email_channels
+(
+    id      uuid primary key not null,
+    entry   uuid             not null,
+    label   varchar          not null,
+    timeout bigint           not null,
+    email   varchar          not null
+);
+
+comment on column email_channels.entry is 'The id of the address book entry.';
+
+create index email_channels_entry_ix on email_channels (entry);
+
+-- Create delivery attempts table.
+
+create table accepted_intel_delivery_attempts
+(
+    id                uuid primary key not null,
+    assigned_to       uuid             not null,
+    assigned_to_label varchar          not null,
+    assigned_to_user  uuid,
+    delivery          uuid             not null,
+    channel           uuid             not null,
+    created_at        timestamp        not null,
+    is_active         bool             not null,
+    status_ts         timestamp        not null,
+    note              varchar,
+    accepted_at       timestamp        not null
+);
+
+comment on column accepted_intel_delivery_attempts.assigned_to is 'The id of the address book entry, the intel is assigned to (for this delivery).';
+comment on column accepted_intel_delivery_attempts.delivery is 'The id of the referenced delivery.';
+comment on column accepted_intel_delivery_attempts.channel is 'The id of the channel to use for delivery.';
+
+create index accepted_intel_delivery_attempts_active_ix on accepted_intel_delivery_attempts (is_active)
+    where is_active = true or is_active is true;
+
+-- Create intel table.
+
+create table intel_to_deliver
+(
+    attempt    uuid      not null primary key references accepted_intel_delivery_attempts (id)
+        on update cascade on delete cascade,
+    id         uuid      not null,
+    created_at timestamp not null,
+    created_by uuid      not null references users (id)
+        on delete restrict on update restrict,
+    operation  uuid      not null,
+    "type"     varchar   not null,
+    "content"  jsonb     not null,
+    importance int       not null
+);
+
+create index intel_to_deliver_id_ix on intel_to_deliver (id);
+
+-- Create email deliveries table.
+
+create table email_deliveries
+(
+    attempt        uuid      not null primary key references accepted_intel_delivery_attempts (id)
+        on update cascade on delete cascade,
+    "to"           varchar   not null,
+    created_at     timestamp not null,
+    sent_at        timestamp,
+    failed_at      timestamp,
+    failure_reason varchar
+);
+
+comment on table email_deliveries is 'Emails to send for accepted attempts. An email is pending as long as neither sent_at nor failed_at is set.';
+comment on column email_deliveries."to" is 'The recipient email address at the time the attempt was accepted.';
+
+create index email_deliveries_pending_ix on email_deliveries (created_at)
+    where sent_at is null and failed_at is null;
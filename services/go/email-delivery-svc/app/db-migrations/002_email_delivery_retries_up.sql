@@ -0,0 +1,10 @@
+-- Track sending and retries of email deliveries.
+
+alter table email_deliveries
+    add column sending_since   timestamp,
+    add column retries         int not null default 0,
+    add column next_attempt_at timestamp;
+
+comment on column email_deliveries.sending_since is 'Set while the email is being sent outside of a transaction. Deliveries stuck in sending are marked as failed as it is unknown whether they were sent.';
+comment on column email_deliveries.retries is 'The number of retries because of transient errors.';
+comment on column email_deliveries.next_attempt_at is 'The timestamp before which the email is not retried.';
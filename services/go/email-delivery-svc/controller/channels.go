@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+)
+
+// UpdateEmailChannelsByEntry deletes and recreates the
+// email-channels for the address book entry with the given id.
+func (c *Controller) UpdateEmailChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, create []store.EmailChannel) error {
+	// Delete old channels.
+	err := c.store.DeleteEmailChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete email-channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	// Create new channels.
+	for _, newChannel := range create {
+		err = c.store.CreateEmailChannel(ctx, tx, newChannel)
+		if err != nil {
+			return meh.Wrap(err, "create email-channel in store", meh.Details{"create": create})
+		}
+	}
+	return nil
+}
+
+// DeleteEmailChannelsByEntry deletes all email-channels for the
+// entry with the given id.
+func (c *Controller) DeleteEmailChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	err := c.store.DeleteEmailChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete email channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	return nil
+}
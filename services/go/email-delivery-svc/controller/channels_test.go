@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerUpdateEmailChannelsByEntrySuite tests
+// Controller.UpdateEmailChannelsByEntry.
+type ControllerUpdateEmailChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleEntry    uuid.UUID
+	sampleChannels []store.EmailChannel
+}
+
+func (suite *ControllerUpdateEmailChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleEntry = testutil.NewUUIDV4()
+	genChannel := func() store.EmailChannel {
+		return store.EmailChannel{
+			ID:      testutil.NewUUIDV4(),
+			Entry:   suite.sampleEntry,
+			Label:   "death",
+			Timeout: 776,
+			Email:   "death@example.com",
+		}
+	}
+	suite.sampleChannels = []store.EmailChannel{
+		genChannel(),
+		genChannel(),
+		genChannel(),
+	}
+}
+
+func (suite *ControllerUpdateEmailChannelsByEntrySuite) TestDeleteInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteEmailChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateEmailChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateEmailChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteEmailChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	suite.ctrl.Store.On("CreateEmailChannel", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateEmailChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateEmailChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeleteEmailChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	for _, channel := range suite.sampleChannels {
+		suite.ctrl.Store.On("CreateEmailChannel", timeout, suite.tx, channel).
+			Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateEmailChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateEmailChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateEmailChannelsByEntrySuite))
+}
+
+// ControllerDeleteEmailChannelsByEntrySuite tests
+// Controller.DeleteEmailChannelsByEntry.
+type ControllerDeleteEmailChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	sampleEntry uuid.UUID
+}
+
+func (suite *ControllerDeleteEmailChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.sampleEntry = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerDeleteEmailChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteEmailChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteEmailChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteEmailChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeleteEmailChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteEmailChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_DeleteEmailChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerDeleteEmailChannelsByEntrySuite))
+}
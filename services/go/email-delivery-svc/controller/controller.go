@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/mailer"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+// Controller manages all operations regarding email delivery.
+type Controller struct {
+	logger   *zap.Logger
+	db       pgutil.DBTxSupplier
+	store    Store
+	notifier Notifier
+	mailer   Mailer
+	// sendEmailRequests is a buffered channel, holding pending requests for
+	// sending emails for attempts by their id. Use scheduleSendEmail for sending to
+	// this channel. Requests are read by workers in runSendEmailScheduler.
+	sendEmailRequests chan uuid.UUID
+}
+
+// NewController creates a new Controller. Do not forget to call Controller.Run.
+func NewController(logger *zap.Logger, db pgutil.DBTxSupplier, store Store, notifier Notifier, mailer Mailer) *Controller {
+	return &Controller{
+		logger:            logger,
+		db:                db,
+		store:             store,
+		notifier:          notifier,
+		mailer:            mailer,
+		sendEmailRequests: make(chan uuid.UUID, 256),
+	}
+}
+
+// Run schedulers and periodic operations until the given context is done.
+func (c *Controller) Run(lifetime context.Context) error {
+	eg, egCtx := errgroup.WithContext(lifetime)
+	// Run scheduler.
+	eg.Go(func() error {
+		err := c.runSendEmailScheduler(egCtx)
+		if err != nil {
+			return meh.Wrap(err, "run send-email-scheduler", nil)
+		}
+		return nil
+	})
+	// Run periodic checks.
+	eg.Go(func() error {
+		err := c.runPeriodicPendingEmailChecks(egCtx)
+		if err != nil {
+			return meh.Wrap(err, "run periodic pending email checks", nil)
+		}
+		return nil
+	})
+	return eg.Wait()
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	// Send the given mailer.Mail.
+	Send(ctx context.Context, mail mailer.Mail) error
+}
+
+// Store for Controller.
+type Store interface {
+	// CreateUser creates the given store.User.
+	CreateUser(ctx context.Context, tx pgx.Tx, user store.User) error
+	// UpdateUser updates the user details of the given store.User, identified by
+	// its id.
+	UpdateUser(ctx context.Context, tx pgx.Tx, user store.User) error
+	// CreateEmailChannel creates the given store.EmailChannel in the store.
+	CreateEmailChannel(ctx context.Context, tx pgx.Tx, create store.EmailChannel) error
+	// DeleteEmailChannelsByEntry deletes the email channels in the store
+	// associated with the address book entry with the given id.
+	DeleteEmailChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error
+	// EmailChannelByID retrieves the store.EmailChannel with the given id.
+	EmailChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (store.EmailChannel, error)
+	// CreateAcceptedIntelDeliveryAttempt creates the given
+	// store.AcceptedIntelDeliveryAttempt.
+	CreateAcceptedIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, create store.AcceptedIntelDeliveryAttempt) error
+	// UpdateAcceptedIntelDeliveryAttemptStatus updates the given
+	// store.AcceptedIntelDeliveryAttemptStatus, identified by its id.
+	UpdateAcceptedIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, update store.AcceptedIntelDeliveryAttemptStatus) error
+	// CreateIntelToDeliver creates the given store.IntelToDeliver in the store.
+	CreateIntelToDeliver(ctx context.Context, tx pgx.Tx, create store.IntelToDeliver) error
+	// CreateEmailDelivery creates a pending email delivery for the attempt with
+	// the given id.
+	CreateEmailDelivery(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, to string) error
+	// PendingEmailDeliveries retrieves the attempt ids of all pending email
+	// deliveries, that are not being sent and due at the given time.
+	PendingEmailDeliveries(ctx context.Context, tx pgx.Tx, now time.Time) ([]uuid.UUID, error)
+	// PendingOutgoingEmailByAttemptAndLockOrSkip retrieves the
+	// store.OutgoingEmail for the attempt with the given id and locks it or skips
+	// it. This means, that when the returned error is meh.ErrNotFound, it might
+	// also be locked by someone else, not be pending or not be due at the given
+	// time.
+	PendingOutgoingEmailByAttemptAndLockOrSkip(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, now time.Time) (store.OutgoingEmail, error)
+	// StaleSendingEmailDeliveriesAndLockOrSkip retrieves the attempt ids of all
+	// email deliveries, that are being sent since before the given time, and
+	// locks them or skips them if locked by someone else.
+	StaleSendingEmailDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx, sendingBefore time.Time) ([]uuid.UUID, error)
+	// MarkEmailDeliveryAsSending marks the email delivery for the attempt with
+	// the given id as being sent since the given time.
+	MarkEmailDeliveryAsSending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sendingSince time.Time) error
+	// MarkEmailDeliveryForRetry marks the email delivery for the attempt with the
+	// given id as to be retried at the given time.
+	MarkEmailDeliveryForRetry(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, nextAttemptAt time.Time) error
+	// MarkEmailDeliveryAsSent marks the email delivery for the attempt with the
+	// given id as sent.
+	MarkEmailDeliveryAsSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error
+	// MarkEmailDeliveryAsFailed marks the email delivery for the attempt with the
+	// given id as failed with the given reason.
+	MarkEmailDeliveryAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error
+}
+
+// Notifier for Controller.
+type Notifier interface {
+	// NotifyEmailForIntelPending notifies that an email for an
+	// intel-delivery-attempt is pending.
+	NotifyEmailForIntelPending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, since time.Time) error
+	// NotifyEmailForIntelSent notifies that an email for an
+	// intel-delivery-attempt was sent.
+	NotifyEmailForIntelSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error
+	// NotifyEmailForIntelFailed notifies that sending an email for an
+	// intel-delivery-attempt failed.
+	NotifyEmailForIntelFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error
+}
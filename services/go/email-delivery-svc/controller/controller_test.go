@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/mailer"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"time"
+)
+
+const timeout = 5 * time.Second
+
+type ControllerMock struct {
+	Logger   *zap.Logger
+	DB       *testutil.DBTxSupplier
+	Store    *StoreMock
+	Notifier *NotifierMock
+	Mailer   *MailerMock
+	Ctrl     *Controller
+}
+
+func NewMockController() *ControllerMock {
+	ctrl := &ControllerMock{
+		Logger:   zap.NewNop(),
+		DB:       &testutil.DBTxSupplier{},
+		Store:    &StoreMock{},
+		Notifier: &NotifierMock{},
+		Mailer:   &MailerMock{},
+	}
+	ctrl.Ctrl = NewController(ctrl.Logger, ctrl.DB, ctrl.Store, ctrl.Notifier, ctrl.Mailer)
+	return ctrl
+}
+
+// StoreMock mocks Store.
+type StoreMock struct {
+	mock.Mock
+}
+
+func (m *StoreMock) CreateUser(ctx context.Context, tx pgx.Tx, user store.User) error {
+	return m.Called(ctx, tx, user).Error(0)
+}
+
+func (m *StoreMock) UpdateUser(ctx context.Context, tx pgx.Tx, user store.User) error {
+	return m.Called(ctx, tx, user).Error(0)
+}
+
+func (m *StoreMock) CreateEmailChannel(ctx context.Context, tx pgx.Tx, create store.EmailChannel) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *StoreMock) DeleteEmailChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	return m.Called(ctx, tx, entryID).Error(0)
+}
+
+func (m *StoreMock) EmailChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (store.EmailChannel, error) {
+	args := m.Called(ctx, tx, channelID)
+	return args.Get(0).(store.EmailChannel), args.Error(1)
+}
+
+func (m *StoreMock) CreateAcceptedIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, create store.AcceptedIntelDeliveryAttempt) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *StoreMock) UpdateAcceptedIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, update store.AcceptedIntelDeliveryAttemptStatus) error {
+	return m.Called(ctx, tx, update).Error(0)
+}
+
+func (m *StoreMock) CreateIntelToDeliver(ctx context.Context, tx pgx.Tx, create store.IntelToDeliver) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *StoreMock) CreateEmailDelivery(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, to string) error {
+	return m.Called(ctx, tx, attemptID, to).Error(0)
+}
+
+func (m *StoreMock) PendingEmailDeliveries(ctx context.Context, tx pgx.Tx, now time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, now)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *StoreMock) PendingOutgoingEmailByAttemptAndLockOrSkip(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, now time.Time) (store.OutgoingEmail, error) {
+	args := m.Called(ctx, tx, attemptID, now)
+	return args.Get(0).(store.OutgoingEmail), args.Error(1)
+}
+
+func (m *StoreMock) StaleSendingEmailDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx, sendingBefore time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, sendingBefore)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *StoreMock) MarkEmailDeliveryAsSending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sendingSince time.Time) error {
+	return m.Called(ctx, tx, attemptID, sendingSince).Error(0)
+}
+
+func (m *StoreMock) MarkEmailDeliveryForRetry(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, nextAttemptAt time.Time) error {
+	return m.Called(ctx, tx, attemptID, nextAttemptAt).Error(0)
+}
+
+func (m *StoreMock) MarkEmailDeliveryAsSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error {
+	return m.Called(ctx, tx, attemptID, sentAt).Error(0)
+}
+
+func (m *StoreMock) MarkEmailDeliveryAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error {
+	return m.Called(ctx, tx, attemptID, failedAt, reason).Error(0)
+}
+
+// NotifierMock mocks Notifier.
+type NotifierMock struct {
+	mock.Mock
+}
+
+func (m *NotifierMock) NotifyEmailForIntelPending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, since time.Time) error {
+	return m.Called(ctx, tx, attemptID, since).Error(0)
+}
+
+func (m *NotifierMock) NotifyEmailForIntelSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error {
+	return m.Called(ctx, tx, attemptID, sentAt).Error(0)
+}
+
+func (m *NotifierMock) NotifyEmailForIntelFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error {
+	return m.Called(ctx, tx, attemptID, failedAt, reason).Error(0)
+}
+
+// MailerMock mocks Mailer.
+type MailerMock struct {
+	mock.Mock
+}
+
+func (m *MailerMock) Send(ctx context.Context, mail mailer.Mail) error {
+	return m.Called(ctx, mail).Error(0)
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/mailer"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+// periodicPendingEmailCheckInterval is the interval in which to check for
+// pending emails in Controller.runPeriodicPendingEmailChecks.
+const periodicPendingEmailCheckInterval = 16 * time.Second
+
+// sendEmailTimeout is the timeout for sending a single email.
+const sendEmailTimeout = 32 * time.Second
+
+// recordSentEmailTimeout is the timeout for recording the result of sending an
+// email. It is not bound to the context of sending as an email, that has been
+// sent, must be recorded as such.
+const recordSentEmailTimeout = 16 * time.Second
+
+// staleSendingEmailTimeout is the duration after which an email, that is still
+// being sent, is considered stale. As it is unknown whether it has been sent,
+// it is marked as failed instead of sending it again.
+const staleSendingEmailTimeout = 4 * (sendEmailTimeout + recordSentEmailTimeout)
+
+// sendEmailMaxRetries is the maximum number of retries for sending an email
+// because of transient errors.
+const sendEmailMaxRetries = 8
+
+// sendEmailRetryBaseDelay is the delay before the first retry for sending an
+// email. It is doubled for each following retry until reaching
+// sendEmailRetryMaxDelay.
+const sendEmailRetryBaseDelay = periodicPendingEmailCheckInterval
+
+// sendEmailRetryMaxDelay is the maximum delay before retrying to send an email.
+const sendEmailRetryMaxDelay = 30 * time.Minute
+
+func (c *Controller) runPeriodicPendingEmailChecks(lifetime context.Context) error {
+	for {
+		err := c.scheduleSendAllPendingEmails(lifetime)
+		if err != nil {
+			err = meh.Wrap(err, "schedule send all pending emails in periodic check", nil)
+			mehlog.Log(c.logger, err)
+		}
+		select {
+		case <-lifetime.Done():
+			return nil
+		case <-time.After(periodicPendingEmailCheckInterval):
+		}
+	}
+}
+
+// scheduleSendAllPendingEmails calls scheduleSendEmail for all pending emails,
+// that are due. Emails, that are being sent for longer than
+// staleSendingEmailTimeout, are marked as failed.
+func (c *Controller) scheduleSendAllPendingEmails(ctx context.Context) error {
+	var attemptIDs []uuid.UUID
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.failStaleSendingEmails(ctx, tx)
+		if err != nil {
+			return meh.Wrap(err, "fail stale sending emails", nil)
+		}
+		attemptIDs, err = c.store.PendingEmailDeliveries(ctx, tx, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "pending email deliveries from store", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	for _, attemptID := range attemptIDs {
+		err = c.scheduleSendEmail(ctx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "schedule send email", meh.Details{"attempt_id": attemptID})
+		}
+	}
+	return nil
+}
+
+// failStaleSendingEmails marks all emails as failed, that are being sent for
+// longer than staleSendingEmailTimeout. This happens if the service stopped
+// while sending.
+func (c *Controller) failStaleSendingEmails(ctx context.Context, tx pgx.Tx) error {
+	attemptIDs, err := c.store.StaleSendingEmailDeliveriesAndLockOrSkip(ctx, tx, time.Now().Add(-staleSendingEmailTimeout))
+	if err != nil {
+		return meh.Wrap(err, "stale sending email deliveries from store", nil)
+	}
+	for _, attemptID := range attemptIDs {
+		err = c.markEmailAsFailed(ctx, tx, attemptID, "sending interrupted, email might not have been sent")
+		if err != nil {
+			return meh.Wrap(err, "mark email as failed", meh.Details{"attempt_id": attemptID})
+		}
+	}
+	return nil
+}
+
+// scheduleSendEmail schedules sending the email for the attempt with the given
+// id.
+func (c *Controller) scheduleSendEmail(ctx context.Context, attemptID uuid.UUID) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.sendEmailRequests <- attemptID:
+	}
+	return nil
+}
+
+const sendEmailSchedulerWorkers = 16
+
+// runSendEmailScheduler runs workers that handle requests from
+// sendEmailRequests.
+func (c *Controller) runSendEmailScheduler(lifetime context.Context) error {
+	logger := c.logger.Named("send-email-scheduler")
+	worker := func(lifetime context.Context) error {
+		for {
+			var request uuid.UUID
+			// Wait for next request.
+			select {
+			case <-lifetime.Done():
+				return nil
+			case request = <-c.sendEmailRequests:
+			}
+			// Handle.
+			err := c.sendEmail(lifetime, request)
+			if err != nil {
+				mehlog.Log(logger, meh.Wrap(err, "send email", meh.Details{"attempt_id": request}))
+				continue
+			}
+		}
+	}
+	// Launch workers.
+	eg, egCtx := errgroup.WithContext(lifetime)
+	for i := 0; i < sendEmailSchedulerWorkers; i++ {
+		eg.Go(func() error {
+			return worker(egCtx)
+		})
+	}
+	return eg.Wait()
+}
+
+// sendEmail renders and sends the email for the attempt with the given id if it
+// is still pending. If the email is locked by someone else, not pending anymore
+// or not due, nothing is done. In order to not hold locks while sending, the
+// email delivery is marked as being sent in a separate transaction beforehand.
+// Afterwards, it is marked as sent or failed and the Notifier is informed. If
+// sending failed with a mailer.ErrTransient error, it is retried later with
+// exponential backoff up to sendEmailMaxRetries times.
+//
+// Warning: You normally should not call this manually, except in
+// runSendEmailScheduler, as this is done by workers concurrently by the
+// Controller. Instead, use scheduleSendEmail.
+func (c *Controller) sendEmail(ctx context.Context, attemptID uuid.UUID) error {
+	// Claim the email.
+	var outgoing store.OutgoingEmail
+	claimed := false
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		outgoing, err = c.store.PendingOutgoingEmailByAttemptAndLockOrSkip(ctx, tx, attemptID, time.Now())
+		if err != nil {
+			if meh.ErrorCode(err) == meh.ErrNotFound {
+				// Already handled, locked by someone else or not due.
+				return nil
+			}
+			return meh.Wrap(err, "pending outgoing email by attempt and lock or skip", nil)
+		}
+		err = c.store.MarkEmailDeliveryAsSending(ctx, tx, attemptID, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "mark email delivery as sending in store", nil)
+		}
+		claimed = true
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "claim email in tx", nil)
+	}
+	if !claimed {
+		return nil
+	}
+	// Send without holding any locks.
+	sendErr := c.renderAndSendEmail(ctx, outgoing)
+	// Record the result.
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), recordSentEmailTimeout)
+	defer cancelRecord()
+	err = pgutil.RunInTx(recordCtx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		if sendErr == nil {
+			return c.markEmailAsSent(ctx, tx, attemptID)
+		}
+		mehlog.Log(c.logger.With(zap.Any("attempt_id", attemptID)), sendErr)
+		if meh.ErrorCode(sendErr) == mailer.ErrTransient && outgoing.Delivery.Retries < sendEmailMaxRetries {
+			nextAttemptAt := time.Now().Add(sendEmailRetryDelay(outgoing.Delivery.Retries))
+			err := c.store.MarkEmailDeliveryForRetry(ctx, tx, attemptID, nextAttemptAt)
+			if err != nil {
+				return meh.Wrap(err, "mark email delivery for retry in store", meh.Details{"next_attempt_at": nextAttemptAt})
+			}
+			return nil
+		}
+		return c.markEmailAsFailed(ctx, tx, attemptID, sendErr.Error())
+	})
+	if err != nil {
+		return meh.Wrap(err, "record result in tx", nil)
+	}
+	return nil
+}
+
+// renderAndSendEmail renders the given store.OutgoingEmail and sends it.
+func (c *Controller) renderAndSendEmail(ctx context.Context, outgoing store.OutgoingEmail) error {
+	mail, err := renderMail(outgoing)
+	if err != nil {
+		return meh.Wrap(err, "render mail", nil)
+	}
+	sendCtx, cancelSend := context.WithTimeout(ctx, sendEmailTimeout)
+	defer cancelSend()
+	err = c.mailer.Send(sendCtx, mail)
+	if err != nil {
+		return meh.Wrap(err, "send mail", meh.Details{"to": mail.To})
+	}
+	return nil
+}
+
+// sendEmailRetryDelay returns the delay before retrying to send an email, that
+// has already been retried the given number of times.
+func sendEmailRetryDelay(retries int) time.Duration {
+	delay := sendEmailRetryBaseDelay
+	for i := 0; i < retries && delay < sendEmailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > sendEmailRetryMaxDelay {
+		delay = sendEmailRetryMaxDelay
+	}
+	return delay
+}
+
+// markEmailAsSent marks the email delivery for the attempt with the given id as
+// sent and notifies.
+func (c *Controller) markEmailAsSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) error {
+	sentAt := time.Now()
+	err := c.store.MarkEmailDeliveryAsSent(ctx, tx, attemptID, sentAt)
+	if err != nil {
+		return meh.Wrap(err, "mark email delivery as sent in store", nil)
+	}
+	err = c.notifier.NotifyEmailForIntelSent(ctx, tx, attemptID, sentAt)
+	if err != nil {
+		return meh.Wrap(err, "notify email for intel sent", nil)
+	}
+	return nil
+}
+
+// markEmailAsFailed marks the email delivery for the attempt with the given id
+// as failed with the given reason and notifies.
+func (c *Controller) markEmailAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, reason string) error {
+	failedAt := time.Now()
+	err := c.store.MarkEmailDeliveryAsFailed(ctx, tx, attemptID, failedAt, reason)
+	if err != nil {
+		return meh.Wrap(err, "mark email delivery as failed in store", nil)
+	}
+	err = c.notifier.NotifyEmailForIntelFailed(ctx, tx, attemptID, failedAt, reason)
+	if err != nil {
+		return meh.Wrap(err, "notify email for intel failed", nil)
+	}
+	return nil
+}
@@ -0,0 +1,515 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/mailer"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// controllerScheduleSendAllPendingEmailsSuite tests
+// Controller.scheduleSendAllPendingEmails.
+type controllerScheduleSendAllPendingEmailsSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	tx               *testutil.DBTx
+	samplePendingIDs []uuid.UUID
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.samplePendingIDs = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.scheduleSendAllPendingEmails(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) TestRetrieveStaleFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("StaleSendingEmailDeliveriesAndLockOrSkip", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.scheduleSendAllPendingEmails(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) TestMarkStaleAsFailedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	staleID := testutil.NewUUIDV4()
+	suite.ctrl.Store.On("StaleSendingEmailDeliveriesAndLockOrSkip", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{staleID}, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", timeout, suite.tx, staleID, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.scheduleSendAllPendingEmails(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) TestRetrievePendingFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("StaleSendingEmailDeliveriesAndLockOrSkip", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("PendingEmailDeliveries", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.scheduleSendAllPendingEmails(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerScheduleSendAllPendingEmailsSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	staleID := testutil.NewUUIDV4()
+	suite.ctrl.Store.On("StaleSendingEmailDeliveriesAndLockOrSkip", timeout, suite.tx, mock.MatchedBy(func(sendingBefore time.Time) bool {
+		return sendingBefore.Before(time.Now().Add(-staleSendingEmailTimeout).Add(time.Second))
+	})).
+		Return([]uuid.UUID{staleID}, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", timeout, suite.tx, staleID, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("PendingEmailDeliveries", timeout, suite.tx, mock.Anything).
+		Return(suite.samplePendingIDs, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyEmailForIntelFailed", timeout, suite.tx, staleID, mock.Anything, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.scheduleSendAllPendingEmails(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		scheduled := make([]uuid.UUID, 0)
+		for range suite.samplePendingIDs {
+			select {
+			case <-timeout.Done():
+				suite.Fail("timeout", "timeout while waiting for scheduled emails")
+				return
+			case attemptID := <-suite.ctrl.Ctrl.sendEmailRequests:
+				scheduled = append(scheduled, attemptID)
+			}
+		}
+		suite.Equal(suite.samplePendingIDs, scheduled, "should schedule all pending emails")
+	}()
+
+	wait()
+}
+
+func TestController_scheduleSendAllPendingEmails(t *testing.T) {
+	suite.Run(t, new(controllerScheduleSendAllPendingEmailsSuite))
+}
+
+// controllerSendEmailSuite tests Controller.sendEmail.
+type controllerSendEmailSuite struct {
+	suite.Suite
+	ctrl            *ControllerMock
+	claimTx         *testutil.DBTx
+	recordTx        *testutil.DBTx
+	sampleAttemptID uuid.UUID
+	sampleOutgoing  store.OutgoingEmail
+	expectedMail    mailer.Mail
+}
+
+func (suite *controllerSendEmailSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.claimTx = &testutil.DBTx{}
+	suite.recordTx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.claimTx, suite.recordTx}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleOutgoing = store.OutgoingEmail{
+		Delivery: store.EmailDelivery{
+			Attempt:   suite.sampleAttemptID,
+			To:        "lady@example.com",
+			CreatedAt: time.Date(2022, 9, 8, 1, 46, 23, 0, time.UTC),
+		},
+		IntelToDeliver: store.IntelToDeliver{
+			Attempt:    suite.sampleAttemptID,
+			ID:         testutil.NewUUIDV4(),
+			CreatedAt:  time.Date(2022, 9, 8, 1, 46, 44, 0, time.UTC),
+			CreatedBy:  testutil.NewUUIDV4(),
+			Operation:  testutil.NewUUIDV4(),
+			Type:       store.IntelTypePlaintextMessage,
+			Content:    json.RawMessage(`{"text":"prison"}`),
+			Importance: 300,
+		},
+		DeliveryAttempt: store.AcceptedIntelDeliveryAttempt{
+			ID:              suite.sampleAttemptID,
+			AssignedToLabel: "tent",
+			IsActive:        true,
+		},
+		CreatorDetails: store.User{
+			Username:  "sky",
+			FirstName: "cheese",
+			LastName:  "fork",
+		},
+	}
+	var err error
+	suite.expectedMail, err = renderMail(suite.sampleOutgoing)
+	suite.Require().NoError(err, "render expected mail should not fail")
+}
+
+func (suite *controllerSendEmailSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestRetrieveOutgoingFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(store.OutgoingEmail{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestNotPending() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(store.OutgoingEmail{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestMarkAsSendingFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+		suite.False(suite.claimTx.IsCommitted, "should not commit claim tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestSendFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(errors.New("sad life"))
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.claimTx.IsCommitted, "should commit claim tx")
+		suite.True(suite.recordTx.IsCommitted, "should commit record tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestSendTransientFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(meh.NewErr(mailer.ErrTransient, "sad life", nil))
+	suite.ctrl.Store.On("MarkEmailDeliveryForRetry", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return nextAttemptAt.After(time.Now().Add(sendEmailRetryBaseDelay / 2))
+	})).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.recordTx.IsCommitted, "should commit record tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestSendTransientFailRetriesExceeded() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleOutgoing.Delivery.Retries = sendEmailMaxRetries
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(meh.NewErr(mailer.ErrTransient, "sad life", nil))
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.recordTx.IsCommitted, "should commit record tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestMarkForRetryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(meh.NewErr(mailer.ErrTransient, "sad life", nil))
+	suite.ctrl.Store.On("MarkEmailDeliveryForRetry", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+		suite.False(suite.recordTx.IsCommitted, "should not commit record tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestRenderFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleOutgoing.IntelToDeliver.Content = json.RawMessage(`"meow"`)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestMarkAsFailedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(errors.New("sad life"))
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+		suite.False(suite.recordTx.IsCommitted, "should not commit record tx")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestNotifyFailedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(errors.New("sad life"))
+	suite.ctrl.Store.On("MarkEmailDeliveryAsFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelFailed", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestMarkAsSentFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSent", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestNotifySentFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSent", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelSent", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerSendEmailSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PendingOutgoingEmailByAttemptAndLockOrSkip", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(suite.sampleOutgoing, nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSending", mock.Anything, suite.claimTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Mailer.On("Send", mock.Anything, suite.expectedMail).
+		Return(nil)
+	suite.ctrl.Store.On("MarkEmailDeliveryAsSent", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyEmailForIntelSent", mock.Anything, suite.recordTx, suite.sampleAttemptID, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Mailer.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.sendEmail(timeout, suite.sampleAttemptID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.claimTx.IsCommitted, "should commit claim tx")
+		suite.True(suite.recordTx.IsCommitted, "should commit record tx")
+	}()
+
+	wait()
+}
+
+func TestController_sendEmail(t *testing.T) {
+	suite.Run(t, new(controllerSendEmailSuite))
+}
+
+func Test_sendEmailRetryDelay(t *testing.T) {
+	assert.Equal(t, sendEmailRetryBaseDelay, sendEmailRetryDelay(0), "should return base delay for first retry")
+	assert.Equal(t, 2*sendEmailRetryBaseDelay, sendEmailRetryDelay(1), "should double delay")
+	assert.Equal(t, sendEmailRetryMaxDelay, sendEmailRetryDelay(100), "should cap at max delay")
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"time"
+)
+
+// CreateIntelDeliveryAttempt handles a new intel-delivery-attempt. It checks,
+// whether the channel is an email channel and accepts it then. Otherwise, it is
+// ignored. Accepted attempts are scheduled for sending the email.
+func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx,
+	attempt store.AcceptedIntelDeliveryAttempt, intelToDeliver store.IntelToDeliver) error {
+	channel, err := c.store.EmailChannelByID(ctx, tx, attempt.Channel)
+	if err != nil {
+		if meh.ErrorCode(err) == meh.ErrNotFound {
+			// Ignore.
+			return nil
+		}
+		return meh.Wrap(err, "email channel by id from store", meh.Details{"channel_id": attempt.Channel})
+	}
+	attempt.AcceptedAt = time.Now()
+	// Add to store.
+	err = c.store.CreateAcceptedIntelDeliveryAttempt(ctx, tx, attempt)
+	if err != nil {
+		return meh.Wrap(err, "create accepted intel delivery attempt", nil)
+	}
+	err = c.store.CreateIntelToDeliver(ctx, tx, intelToDeliver)
+	if err != nil {
+		return meh.Wrap(err, "create intel-to-deliver in store", meh.Details{"intel_to_deliver": intelToDeliver})
+	}
+	err = c.store.CreateEmailDelivery(ctx, tx, attempt.ID, channel.Email)
+	if err != nil {
+		return meh.Wrap(err, "create email delivery in store", meh.Details{
+			"attempt_id": attempt.ID,
+			"to":         channel.Email,
+		})
+	}
+	// Notify about accepted attempt.
+	err = c.notifier.NotifyEmailForIntelPending(ctx, tx, attempt.ID, attempt.AcceptedAt)
+	if err != nil {
+		return meh.Wrap(err, "notify email for intel pending", meh.Details{"attempt_id": attempt.ID})
+	}
+	// Schedule sending. As the transaction is not committed yet, the worker might
+	// not find the delivery. In this case, the periodic check will pick it up.
+	err = c.scheduleSendEmail(ctx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "schedule send email", meh.Details{"attempt_id": attempt.ID})
+	}
+	return nil
+}
+
+// UpdateIntelDeliveryAttemptStatus updates the intel-delivery-attempt-status
+// for the associated intel-delivery-attempt. The intel-delivery-attempt does
+// not need to be accepted, as we ignore meh.ErrNotFound errors.
+func (c *Controller) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, newStatus store.AcceptedIntelDeliveryAttemptStatus) error {
+	err := c.store.UpdateAcceptedIntelDeliveryAttemptStatus(ctx, tx, newStatus)
+	if err != nil && meh.ErrorCode(err) != meh.ErrNotFound {
+		return meh.Wrap(err, "update attempt status in store", meh.Details{"new_status": newStatus})
+	}
+	return nil
+}
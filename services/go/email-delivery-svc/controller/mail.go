@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/mailer"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"strings"
+	"time"
+)
+
+// mailBodyRenderer renders the content part of a mail body for raw intel
+// content.
+type mailBodyRenderer func(contentRaw json.RawMessage) (string, error)
+
+// newMailBodyRendererWithUnmarshal returns a mailBodyRenderer that unmarshals
+// the raw content and calls the given render function on it.
+func newMailBodyRendererWithUnmarshal[T any](render func(content T) string) mailBodyRenderer {
+	return func(contentRaw json.RawMessage) (string, error) {
+		var content T
+		err := json.Unmarshal(contentRaw, &content)
+		if err != nil {
+			return "", meh.NewBadInputErrFromErr(err, "parse content", meh.Details{"raw": contentRaw})
+		}
+		return render(content), nil
+	}
+}
+
+// renderMail renders the mailer.Mail for the given store.OutgoingEmail.
+func renderMail(outgoing store.OutgoingEmail) (mailer.Mail, error) {
+	var renderer mailBodyRenderer
+	switch outgoing.IntelToDeliver.Type {
+	case store.IntelTypeAnalogRadioMessage:
+		renderer = newMailBodyRendererWithUnmarshal(renderAnalogRadioMessageContent)
+	case store.IntelTypePlaintextMessage:
+		renderer = newMailBodyRendererWithUnmarshal(renderPlaintextMessageContent)
+	default:
+		renderer = renderUnknownContent
+	}
+	content, err := renderer(outgoing.IntelToDeliver.Content)
+	if err != nil {
+		return mailer.Mail{}, meh.Wrap(err, "render content", meh.Details{"intel_type": outgoing.IntelToDeliver.Type})
+	}
+	creator := outgoing.CreatorDetails
+	var body strings.Builder
+	body.WriteString(content)
+	body.WriteString("\n\n--\n")
+	_, _ = fmt.Fprintf(&body, "Type: %s\n", outgoing.IntelToDeliver.Type)
+	_, _ = fmt.Fprintf(&body, "Importance: %d\n", outgoing.IntelToDeliver.Importance)
+	_, _ = fmt.Fprintf(&body, "Created: %s\n", outgoing.IntelToDeliver.CreatedAt.UTC().Format(time.RFC1123))
+	_, _ = fmt.Fprintf(&body, "Created by: %s %s (%s)\n", creator.FirstName, creator.LastName, creator.Username)
+	_, _ = fmt.Fprintf(&body, "Assigned to: %s\n", outgoing.DeliveryAttempt.AssignedToLabel)
+	_, _ = fmt.Fprintf(&body, "Intel: %s\n", outgoing.IntelToDeliver.ID.String())
+	return mailer.Mail{
+		To:      outgoing.Delivery.To,
+		Subject: fmt.Sprintf("[MDS] New intel from %s %s", creator.FirstName, creator.LastName),
+		Body:    body.String(),
+	}, nil
+}
+
+// renderAnalogRadioMessageContent renders the content for intel with
+// store.IntelTypeAnalogRadioMessage.
+func renderAnalogRadioMessageContent(content store.IntelTypeAnalogRadioMessageContent) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "Channel: %s\n", content.Channel)
+	_, _ = fmt.Fprintf(&sb, "Callsign: %s\n", content.Callsign)
+	_, _ = fmt.Fprintf(&sb, "Head: %s\n\n", content.Head)
+	sb.WriteString(content.Content)
+	return sb.String()
+}
+
+// renderPlaintextMessageContent renders the content for intel with
+// store.IntelTypePlaintextMessage.
+func renderPlaintextMessageContent(content store.IntelTypePlaintextMessageContent) string {
+	return content.Text
+}
+
+// renderUnknownContent is a mailBodyRenderer for intel types without special
+// rendering. It renders the indented raw content.
+func renderUnknownContent(contentRaw json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	err := json.Indent(&buf, contentRaw, "", "  ")
+	if err != nil {
+		return "", meh.NewBadInputErrFromErr(err, "indent content", meh.Details{"raw": contentRaw})
+	}
+	return buf.String(), nil
+}
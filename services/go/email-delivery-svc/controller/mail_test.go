@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/mobile-directing-system/mds-server/services/go/email-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// renderMailSuite tests renderMail.
+type renderMailSuite struct {
+	suite.Suite
+	sampleOutgoing store.OutgoingEmail
+}
+
+func (suite *renderMailSuite) SetupTest() {
+	suite.sampleOutgoing = store.OutgoingEmail{
+		Delivery: store.EmailDelivery{
+			Attempt: testutil.NewUUIDV4(),
+			To:      "stiff@example.com",
+		},
+		IntelToDeliver: store.IntelToDeliver{
+			ID:         testutil.NewUUIDV4(),
+			CreatedAt:  time.Date(2022, 9, 8, 1, 46, 44, 0, time.UTC),
+			Importance: 300,
+		},
+		DeliveryAttempt: store.AcceptedIntelDeliveryAttempt{
+			AssignedToLabel: "hedge",
+		},
+		CreatorDetails: store.User{
+			Username:  "aloud",
+			FirstName: "motor",
+			LastName:  "clay",
+		},
+	}
+}
+
+func (suite *renderMailSuite) TestPlaintextMessage() {
+	suite.sampleOutgoing.IntelToDeliver.Type = store.IntelTypePlaintextMessage
+	suite.sampleOutgoing.IntelToDeliver.Content = json.RawMessage(`{"text":"nothing but prison"}`)
+	mail, err := renderMail(suite.sampleOutgoing)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(suite.sampleOutgoing.Delivery.To, mail.To, "should set correct recipient")
+	suite.Contains(mail.Subject, "motor clay", "subject should contain creator name")
+	suite.Contains(mail.Body, "nothing but prison", "body should contain text")
+	suite.Contains(mail.Body, "hedge", "body should contain assigned entry label")
+	suite.Contains(mail.Body, suite.sampleOutgoing.IntelToDeliver.ID.String(), "body should contain intel id")
+}
+
+func (suite *renderMailSuite) TestAnalogRadioMessage() {
+	suite.sampleOutgoing.IntelToDeliver.Type = store.IntelTypeAnalogRadioMessage
+	suite.sampleOutgoing.IntelToDeliver.Content = testutil.MarshalJSONMust(store.IntelTypeAnalogRadioMessageContent{
+		Channel:  "ocean",
+		Callsign: "rabbit",
+		Head:     "swing",
+		Content:  "deep silence",
+	})
+	mail, err := renderMail(suite.sampleOutgoing)
+	suite.Require().NoError(err, "should not fail")
+	suite.Contains(mail.Body, "ocean", "body should contain channel")
+	suite.Contains(mail.Body, "rabbit", "body should contain callsign")
+	suite.Contains(mail.Body, "swing", "body should contain head")
+	suite.Contains(mail.Body, "deep silence", "body should contain content")
+}
+
+func (suite *renderMailSuite) TestBadContent() {
+	suite.sampleOutgoing.IntelToDeliver.Type = store.IntelTypePlaintextMessage
+	suite.sampleOutgoing.IntelToDeliver.Content = json.RawMessage(`"meow"`)
+	_, err := renderMail(suite.sampleOutgoing)
+	suite.Error(err, "should fail")
+}
+
+func (suite *renderMailSuite) TestUnknownType() {
+	suite.sampleOutgoing.IntelToDeliver.Type = "hurry"
+	suite.sampleOutgoing.IntelToDeliver.Content = json.RawMessage(`{"hello":"world"}`)
+	mail, err := renderMail(suite.sampleOutgoing)
+	suite.Require().NoError(err, "should not fail")
+	suite.Contains(mail.Body, `"hello": "world"`, "body should contain indented raw content")
+}
+
+func Test_renderMail(t *testing.T) {
+	suite.Run(t, new(renderMailSuite))
+}
@@ -0,0 +1,21 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"go.uber.org/zap"
+)
+
+// Serve the endpoints via HTTP.
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, deadLetters kafkautil.DeadLetterAdmin) error {
+	httpendpoints.ApplyDefaultErrorHTTPMapping()
+	r := httpendpoints.NewEngine(logger)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
+	err := httpendpoints.Serve(lifetime, r, addr)
+	if err != nil {
+		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package eventport
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"time"
+)
+
+// NotifyEmailForIntelPending emits an event.TypeEmailForIntelPending event.
+func (p *Port) NotifyEmailForIntelPending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, since time.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeEmailForIntelPending,
+		Value: event.EmailForIntelPending{
+			Attempt: attemptID,
+			Since:   since,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// NotifyEmailForIntelSent emits an event.TypeEmailForIntelSent event.
+func (p *Port) NotifyEmailForIntelSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeEmailForIntelSent,
+		Value: event.EmailForIntelSent{
+			Attempt: attemptID,
+			SentAt:  sentAt,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// NotifyEmailForIntelFailed emits an event.TypeEmailForIntelFailed event.
+func (p *Port) NotifyEmailForIntelFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeEmailForIntelFailed,
+		Value: event.EmailForIntelFailed{
+			Attempt:  attemptID,
+			FailedAt: failedAt,
+			Reason:   reason,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package eventport
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// PortNotifyEmailForIntelPendingSuite tests Port.NotifyEmailForIntelPending.
+type PortNotifyEmailForIntelPendingSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleAttempt    uuid.UUID
+	sampleTS         time.Time
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyEmailForIntelPendingSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = testutil.NewUUIDV4()
+	suite.sampleTS = time.Date(2022, 9, 8, 0, 4, 19, 0, time.UTC)
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.EmailDeliveriesTopic,
+			Key:       suite.sampleAttempt.String(),
+			EventType: event.TypeEmailForIntelPending,
+			Value: event.EmailForIntelPending{
+				Attempt: suite.sampleAttempt,
+				Since:   suite.sampleTS,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyEmailForIntelPendingSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelPending(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyEmailForIntelPendingSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelPending(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyEmailForIntelPending(t *testing.T) {
+	suite.Run(t, new(PortNotifyEmailForIntelPendingSuite))
+}
+
+// PortNotifyEmailForIntelSentSuite tests Port.NotifyEmailForIntelSent.
+type PortNotifyEmailForIntelSentSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleAttempt    uuid.UUID
+	sampleTS         time.Time
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyEmailForIntelSentSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = testutil.NewUUIDV4()
+	suite.sampleTS = time.Date(2022, 9, 8, 0, 12, 19, 0, time.UTC)
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.EmailDeliveriesTopic,
+			Key:       suite.sampleAttempt.String(),
+			EventType: event.TypeEmailForIntelSent,
+			Value: event.EmailForIntelSent{
+				Attempt: suite.sampleAttempt,
+				SentAt:  suite.sampleTS,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyEmailForIntelSentSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelSent(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyEmailForIntelSentSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelSent(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyEmailForIntelSent(t *testing.T) {
+	suite.Run(t, new(PortNotifyEmailForIntelSentSuite))
+}
+
+// PortNotifyEmailForIntelFailedSuite tests Port.NotifyEmailForIntelFailed.
+type PortNotifyEmailForIntelFailedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleAttempt    uuid.UUID
+	sampleTS         time.Time
+	sampleReason     string
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyEmailForIntelFailedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = testutil.NewUUIDV4()
+	suite.sampleTS = time.Date(2022, 9, 8, 0, 31, 19, 0, time.UTC)
+	suite.sampleReason = "connection refused"
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.EmailDeliveriesTopic,
+			Key:       suite.sampleAttempt.String(),
+			EventType: event.TypeEmailForIntelFailed,
+			Value: event.EmailForIntelFailed{
+				Attempt:  suite.sampleAttempt,
+				FailedAt: suite.sampleTS,
+				Reason:   suite.sampleReason,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyEmailForIntelFailedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelFailed(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS, suite.sampleReason)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyEmailForIntelFailedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyEmailForIntelFailed(timeout, suite.tx, suite.sampleAttempt, suite.sampleTS, suite.sampleReason)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyEmailForIntelFailed(t *testing.T) {
+	suite.Run(t, new(PortNotifyEmailForIntelFailedSuite))
+}
@@ -0,0 +1,171 @@
+// Package mailer provides sending emails via SMTP.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/lefinal/meh"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// ErrTransient is used for errors, where sending might succeed when retried
+// later. This includes connection errors and transient negative replies (4xx)
+// from the SMTP server.
+const ErrTransient meh.Code = "__mds-server_email-delivery-svc_mailer_transient"
+
+// isTransientSMTPErr checks whether the given error is a connection error or a
+// transient negative reply from the SMTP server.
+func isTransientSMTPErr(err error) bool {
+	var replyErr *textproto.Error
+	if errors.As(err, &replyErr) {
+		return replyErr.Code >= 400 && replyErr.Code < 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// newSMTPErr creates a meh.Error with the given code from the given error. If
+// the error is transient according to isTransientSMTPErr, ErrTransient is used
+// instead.
+func newSMTPErr(err error, code meh.Code, message string, details meh.Details) error {
+	if isTransientSMTPErr(err) {
+		code = ErrTransient
+	}
+	return meh.NewErrFromErr(err, code, message, details)
+}
+
+// Mail is a plaintext email to send.
+type Mail struct {
+	// To is the recipient address.
+	To string
+	// Subject of the email.
+	Subject string
+	// Body is the plaintext content.
+	Body string
+}
+
+// SMTPConfig is the configuration for SMTPMailer.
+type SMTPConfig struct {
+	// Addr is the address of the SMTP server in the format host:port.
+	Addr string
+	// Username for authentication. If empty, no authentication is performed.
+	Username string
+	// Password for authentication.
+	Password string
+	// From is the sender address.
+	From string
+}
+
+// SMTPMailer sends emails via SMTP. Create one with NewSMTPMailer.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates a new SMTPMailer with the given SMTPConfig.
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		config: config,
+	}
+}
+
+// Send the given Mail. A new connection to the SMTP server is established for
+// each mail. If the server supports STARTTLS, it is used. Errors, where sending
+// might succeed when retried, have the code ErrTransient.
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	host, _, err := net.SplitHostPort(m.config.Addr)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "split host port of smtp address", meh.Details{"addr": m.config.Addr})
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.config.Addr)
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "dial smtp server", meh.Details{"addr": m.config.Addr})
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return meh.NewInternalErrFromErr(err, "set connection deadline", nil)
+		}
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "create smtp client", nil)
+	}
+	defer func() { _ = client.Close() }()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return newSMTPErr(err, meh.ErrInternal, "start tls", nil)
+		}
+	}
+	if m.config.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, host))
+		if err != nil {
+			return newSMTPErr(err, meh.ErrInternal, "auth", nil)
+		}
+	}
+	err = client.Mail(m.config.From)
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "set sender", meh.Details{"from": m.config.From})
+	}
+	err = client.Rcpt(mail.To)
+	if err != nil {
+		return newSMTPErr(err, meh.ErrBadInput, "set recipient", meh.Details{"to": mail.To})
+	}
+	w, err := client.Data()
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "start data", nil)
+	}
+	msg, err := m.message(mail, time.Now())
+	if err != nil {
+		return meh.Wrap(err, "build message", nil)
+	}
+	_, err = w.Write(msg)
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "write message", nil)
+	}
+	err = w.Close()
+	if err != nil {
+		return newSMTPErr(err, meh.ErrInternal, "close data", nil)
+	}
+	// The mail has been accepted, so we ignore errors when quitting. Otherwise, it
+	// might be sent again.
+	_ = client.Quit()
+	return nil
+}
+
+// message builds the raw message for the given Mail including headers.
+func (m *SMTPMailer) message(mail Mail, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "From: %s\r\n", m.config.From)
+	_, _ = fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	_, _ = fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	_, _ = fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+	qpWriter := quotedprintable.NewWriter(&buf)
+	_, err := qpWriter.Write([]byte(mail.Body))
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "write quoted-printable body", nil)
+	}
+	err = qpWriter.Close()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "close quoted-printable writer", nil)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,233 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const timeout = 5 * time.Second
+
+// receivedMail is a mail received by smtpStandIn.
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpStandIn is a minimal local SMTP server for testing purposes. It accepts
+// all mails without authentication and records them.
+type smtpStandIn struct {
+	listener net.Listener
+	// rejectRcpt describes whether to reject all recipients.
+	rejectRcpt bool
+	// deferRcpt describes whether to reply with a transient failure for all
+	// recipients.
+	deferRcpt bool
+	received  []receivedMail
+	m         sync.Mutex
+	wg        sync.WaitGroup
+}
+
+func newSMTPStandIn() (*smtpStandIn, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &smtpStandIn{
+		listener: listener,
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s, nil
+}
+
+func (s *smtpStandIn) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *smtpStandIn) close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+	var current receivedMail
+	reply("220 localhost stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			current = receivedMail{from: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if s.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			if s.deferRcpt {
+				reply("450 try again later")
+				continue
+			}
+			current.to = append(current.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			current.data = data.String()
+			s.m.Lock()
+			s.received = append(s.received, current)
+			s.m.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// SMTPMailerSendSuite tests SMTPMailer.Send.
+type SMTPMailerSendSuite struct {
+	suite.Suite
+	server     *smtpStandIn
+	mailer     *SMTPMailer
+	sampleMail Mail
+}
+
+func (suite *SMTPMailerSendSuite) SetupTest() {
+	var err error
+	suite.server, err = newSMTPStandIn()
+	suite.Require().NoError(err, "create smtp stand-in should not fail")
+	suite.mailer = NewSMTPMailer(SMTPConfig{
+		Addr: suite.server.addr(),
+		From: "mds@example.com",
+	})
+	suite.sampleMail = Mail{
+		To:      "rope@example.com",
+		Subject: "Stove größe",
+		Body:    "Hello there,\nthis is a fine test.",
+	}
+}
+
+func (suite *SMTPMailerSendSuite) TearDownTest() {
+	suite.server.close()
+}
+
+func (suite *SMTPMailerSendSuite) TestServerUnreachable() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.server.close()
+
+	go func() {
+		defer cancel()
+		err := suite.mailer.Send(timeout, suite.sampleMail)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(ErrTransient, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *SMTPMailerSendSuite) TestRecipientRejected() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.server.rejectRcpt = true
+
+	go func() {
+		defer cancel()
+		err := suite.mailer.Send(timeout, suite.sampleMail)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *SMTPMailerSendSuite) TestRecipientDeferred() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.server.deferRcpt = true
+
+	go func() {
+		defer cancel()
+		err := suite.mailer.Send(timeout, suite.sampleMail)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(ErrTransient, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *SMTPMailerSendSuite) TestCanceledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := suite.mailer.Send(ctx, suite.sampleMail)
+	suite.Error(err, "should fail")
+}
+
+func (suite *SMTPMailerSendSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.mailer.Send(timeout, suite.sampleMail)
+		suite.Require().NoError(err, "should not fail")
+		suite.server.m.Lock()
+		defer suite.server.m.Unlock()
+		suite.Require().Len(suite.server.received, 1, "should have received mail")
+		received := suite.server.received[0]
+		suite.Equal("mds@example.com", received.from, "should set correct sender")
+		suite.Equal([]string{suite.sampleMail.To}, received.to, "should set correct recipient")
+		suite.Contains(received.data, "To: rope@example.com\r\n", "should include to-header")
+		suite.Contains(received.data, "Subject: =?utf-8?q?Stove_gr=C3=B6=C3=9Fe?=\r\n", "should include encoded subject")
+		suite.Contains(received.data, "Content-Type: text/plain; charset=utf-8\r\n", "should include content type")
+		suite.Contains(received.data, "this is a fine test.", "should include body")
+	}()
+
+	wait()
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	suite.Run(t, new(SMTPMailerSendSuite))
+}
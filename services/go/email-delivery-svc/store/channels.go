@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"time"
+)
+
+// EmailChannel that is used for delivery-attempts.
+type EmailChannel struct {
+	// ID identifies the channel.
+	ID uuid.UUID
+	// Entry is the id of the referenced address book entry.
+	Entry uuid.UUID
+	// Label is a human-readable label for the channel.
+	Label string
+	// Timeout until delivery-attempts time out for this channel.
+	Timeout time.Duration
+	// Email is the address of the recipient.
+	Email string
+}
+
+// CreateEmailChannel creates the given EmailChannel in the database.
+func (m *Mall) CreateEmailChannel(ctx context.Context, tx pgx.Tx, create EmailChannel) error {
+	q, _, err := m.dialect.Insert(goqu.T("email_channels")).Rows(goqu.Record{
+		"id":      create.ID,
+		"entry":   create.Entry,
+		"label":   create.Label,
+		"timeout": create.Timeout,
+		"email":   create.Email,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// DeleteEmailChannelsByEntry deletes the email channels in the database
+// associated with the address book entry with the given id.
+func (m *Mall) DeleteEmailChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("email_channels")).
+		Where(goqu.C("entry").Eq(entryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// EmailChannelByID retrieves the EmailChannel from the database with the given
+// id.
+func (m *Mall) EmailChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (EmailChannel, error) {
+	q, _, err := m.dialect.From(goqu.T("email_channels")).
+		Select(goqu.C("id"),
+			goqu.C("entry"),
+			goqu.C("label"),
+			goqu.C("timeout"),
+			goqu.C("email")).
+		Where(goqu.C("id").Eq(channelID)).ToSQL()
+	if err != nil {
+		return EmailChannel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return EmailChannel{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return EmailChannel{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var channel EmailChannel
+	err = rows.Scan(&channel.ID,
+		&channel.Entry,
+		&channel.Label,
+		&channel.Timeout,
+		&channel.Email)
+	if err != nil {
+		return EmailChannel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return channel, nil
+}
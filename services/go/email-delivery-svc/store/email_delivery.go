@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// EmailDelivery holds the sending state of the email for an accepted
+// intel-delivery-attempt.
+type EmailDelivery struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID
+	// To is the recipient email address.
+	To string
+	// CreatedAt is the timestamp when the email delivery was created.
+	CreatedAt time.Time
+	// SentAt is the timestamp when the email was successfully sent.
+	SentAt nulls.Time
+	// FailedAt is the timestamp when sending the email failed.
+	FailedAt nulls.Time
+	// FailureReason holds human-readable details if FailedAt is set.
+	FailureReason nulls.String
+	// SendingSince is set while the email is being sent.
+	SendingSince nulls.Time
+	// Retries is the number of retries because of transient errors.
+	Retries int
+	// NextAttemptAt is the timestamp before which sending is not retried.
+	NextAttemptAt nulls.Time
+}
+
+// CreateEmailDelivery creates a pending EmailDelivery for the attempt with the
+// given id.
+func (m *Mall) CreateEmailDelivery(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, to string) error {
+	q, _, err := m.dialect.Insert(goqu.T("email_deliveries")).Rows(goqu.Record{
+		"attempt":    attemptID,
+		"to":         to,
+		"created_at": time.Now().UTC(),
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// pendingEmailDeliveryDueExp is the condition for email deliveries in
+// goqu.T("email_deliveries") with the given alias, that are pending, not being
+// sent and due for (re)trying at the given time.
+func pendingEmailDeliveryDueExp(alias string, now time.Time) exp.Expression {
+	return goqu.And(goqu.I(alias+".sent_at").IsNull(),
+		goqu.I(alias+".failed_at").IsNull(),
+		goqu.I(alias+".sending_since").IsNull(),
+		goqu.Or(goqu.I(alias+".next_attempt_at").IsNull(),
+			goqu.I(alias+".next_attempt_at").Lte(now.UTC())))
+}
+
+// PendingEmailDeliveries retrieves the attempt ids of all email deliveries,
+// that have neither been sent nor failed, are not being sent, are due at the
+// given time and whose attempt is still active. They are sorted ascending by
+// creation date.
+func (m *Mall) PendingEmailDeliveries(ctx context.Context, tx pgx.Tx, now time.Time) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("email_deliveries").As("deliveries")).
+		InnerJoin(goqu.T("accepted_intel_delivery_attempts").As("attempts"),
+			goqu.On(goqu.I("attempts.id").Eq(goqu.I("deliveries.attempt")))).
+		Select(goqu.I("deliveries.attempt")).
+		Where(pendingEmailDeliveryDueExp("deliveries", now),
+			goqu.I("attempts.is_active").IsTrue()).
+		Order(goqu.I("deliveries.created_at").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	attemptIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var attemptID uuid.UUID
+		err = rows.Scan(&attemptID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		attemptIDs = append(attemptIDs, attemptID)
+	}
+	rows.Close()
+	return attemptIDs, nil
+}
+
+// OutgoingEmail contains all relevant information for rendering and sending an
+// email for an intel-delivery-attempt.
+type OutgoingEmail struct {
+	// Delivery is the EmailDelivery holding the recipient.
+	Delivery EmailDelivery
+	// IntelToDeliver is the intel that needs to be delivered.
+	IntelToDeliver IntelToDeliver
+	// DeliveryAttempt contains information regarding the delivery-attempt.
+	DeliveryAttempt AcceptedIntelDeliveryAttempt
+	// CreatorDetails holds user information for the creator.
+	CreatorDetails User
+}
+
+// PendingOutgoingEmailByAttemptAndLockOrSkip retrieves the OutgoingEmail for
+// the attempt with the given id and locks the email delivery or skips it. This
+// means, that when the returned error is meh.ErrNotFound, it might also be
+// locked by someone else. It is also assured that the email is still pending,
+// not being sent, due at the given time and the attempt active.
+func (m *Mall) PendingOutgoingEmailByAttemptAndLockOrSkip(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, now time.Time) (OutgoingEmail, error) {
+	var outgoing OutgoingEmail
+	q, _, err := m.dialect.From(goqu.T("email_deliveries").As("deliveries")).
+		Select(goqu.I("deliveries.attempt"),
+			goqu.I("deliveries.to"),
+			goqu.I("deliveries.created_at"),
+			goqu.I("deliveries.sent_at"),
+			goqu.I("deliveries.failed_at"),
+			goqu.I("deliveries.failure_reason"),
+			goqu.I("deliveries.sending_since"),
+			goqu.I("deliveries.retries"),
+			goqu.I("deliveries.next_attempt_at")).
+		ForUpdate(exp.SkipLocked).
+		Where(goqu.I("deliveries.attempt").Eq(attemptID),
+			pendingEmailDeliveryDueExp("deliveries", now)).ToSQL()
+	if err != nil {
+		return OutgoingEmail{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return OutgoingEmail{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return OutgoingEmail{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	err = rows.Scan(&outgoing.Delivery.Attempt,
+		&outgoing.Delivery.To,
+		&outgoing.Delivery.CreatedAt,
+		&outgoing.Delivery.SentAt,
+		&outgoing.Delivery.FailedAt,
+		&outgoing.Delivery.FailureReason,
+		&outgoing.Delivery.SendingSince,
+		&outgoing.Delivery.Retries,
+		&outgoing.Delivery.NextAttemptAt)
+	if err != nil {
+		return OutgoingEmail{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	// Retrieve the attempt.
+	outgoing.DeliveryAttempt, err = m.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		err = meh.ApplyCode(err, meh.ErrInternal)
+		return OutgoingEmail{}, meh.Wrap(err, "accepted intel-delivery-attempt by id", meh.Details{"attempt_id": attemptID})
+	}
+	if !outgoing.DeliveryAttempt.IsActive {
+		return OutgoingEmail{}, meh.NewNotFoundErr("attempt not active anymore", meh.Details{"attempt_id": attemptID})
+	}
+	// Retrieve the intel.
+	outgoing.IntelToDeliver, err = m.IntelToDeliverByAttempt(ctx, tx, attemptID)
+	if err != nil {
+		err = meh.ApplyCode(err, meh.ErrInternal)
+		return OutgoingEmail{}, meh.Wrap(err, "intel to deliver by attempt", meh.Details{"attempt_id": attemptID})
+	}
+	// Retrieve user information.
+	outgoing.CreatorDetails, err = m.UserByID(ctx, tx, outgoing.IntelToDeliver.CreatedBy)
+	if err != nil {
+		err = meh.ApplyCode(err, meh.ErrInternal)
+		return OutgoingEmail{}, meh.Wrap(err, "retrieve user details for creator", meh.Details{"user_id": outgoing.IntelToDeliver.CreatedBy})
+	}
+	return outgoing, nil
+}
+
+// StaleSendingEmailDeliveriesAndLockOrSkip retrieves the attempt ids of all
+// email deliveries, that are being sent since before the given time, and locks
+// them or skips them if locked by someone else.
+func (m *Mall) StaleSendingEmailDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx, sendingBefore time.Time) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("email_deliveries")).
+		Select(goqu.C("attempt")).
+		ForUpdate(exp.SkipLocked).
+		Where(goqu.C("sent_at").IsNull(),
+			goqu.C("failed_at").IsNull(),
+			goqu.C("sending_since").Lt(sendingBefore.UTC())).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	attemptIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var attemptID uuid.UUID
+		err = rows.Scan(&attemptID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		attemptIDs = append(attemptIDs, attemptID)
+	}
+	rows.Close()
+	return attemptIDs, nil
+}
+
+// updatePendingEmailDelivery updates the email delivery for the attempt with
+// the given id with the given goqu.Record if it is still pending.
+func (m *Mall) updatePendingEmailDelivery(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, record goqu.Record) error {
+	q, _, err := m.dialect.Update(goqu.T("email_deliveries")).Set(record).
+		Where(goqu.C("attempt").Eq(attemptID),
+			goqu.C("sent_at").IsNull(),
+			goqu.C("failed_at").IsNull()).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	return nil
+}
+
+// MarkEmailDeliveryAsSending marks the pending email delivery for the attempt
+// with the given id as being sent since the given time.
+func (m *Mall) MarkEmailDeliveryAsSending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sendingSince time.Time) error {
+	return m.updatePendingEmailDelivery(ctx, tx, attemptID, goqu.Record{
+		"sending_since": sendingSince.UTC(),
+	})
+}
+
+// MarkEmailDeliveryForRetry marks the pending email delivery for the attempt
+// with the given id as not being sent anymore and to be retried at the given
+// time. The number of retries is incremented.
+func (m *Mall) MarkEmailDeliveryForRetry(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, nextAttemptAt time.Time) error {
+	return m.updatePendingEmailDelivery(ctx, tx, attemptID, goqu.Record{
+		"sending_since":   nil,
+		"retries":         goqu.L("retries + 1"),
+		"next_attempt_at": nextAttemptAt.UTC(),
+	})
+}
+
+// MarkEmailDeliveryAsSent marks the pending email delivery for the attempt with
+// the given id as sent.
+func (m *Mall) MarkEmailDeliveryAsSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentAt time.Time) error {
+	return m.updatePendingEmailDelivery(ctx, tx, attemptID, goqu.Record{
+		"sending_since": nil,
+		"sent_at":       sentAt.UTC(),
+	})
+}
+
+// MarkEmailDeliveryAsFailed marks the pending email delivery for the attempt
+// with the given id as failed with the given reason.
+func (m *Mall) MarkEmailDeliveryAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, failedAt time.Time, reason string) error {
+	return m.updatePendingEmailDelivery(ctx, tx, attemptID, goqu.Record{
+		"sending_since":  nil,
+		"failed_at":      failedAt.UTC(),
+		"failure_reason": reason,
+	})
+}
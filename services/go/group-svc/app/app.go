@@ -83,7 +83,7 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
@@ -96,7 +96,7 @@ func Run(ctx context.Context) error {
 	}
 	// Serve endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl)
+		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl, kafkaConnector.DeadLetterAdmin(sqlDB))
 		return meh.NilOrWrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 	})
 	// Run Kafka connector.
@@ -74,7 +74,10 @@ func Run(ctx context.Context) error {
 				event.UsersTopic,
 				event.AddressBookTopic,
 				event.IntelDeliveriesTopic,
+				event.IntelTopic,
 				event.InAppNotificationsTopic,
+				event.PermissionsTopic,
+				event.UserPresenceTopic,
 			}
 			err := kafkautil.AwaitTopics(egCtx, c.KafkaAddr, awaitTopics...)
 			return meh.NilOrWrap(err, "await topics", meh.Details{"kafka_addr": c.KafkaAddr})
@@ -91,16 +94,20 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
 	eventPort := eventport.NewPort(kafkaConnector)
 	ctrl := controller.NewController(logger.Named("controller"), sqlDB, store.NewMall(), eventPort)
-	wsHub := wsutil.NewHub(egCtx, logger.Named("ws-hub"), ws.Gatekeeper(), ws.ConnListener(logger.Named("conn-listener"), ctrl))
+	err = ctrl.RegisterPresenceReplica(ctx)
+	if err != nil {
+		return meh.Wrap(err, "register presence replica", nil)
+	}
+	wsHub := wsutil.NewHub(egCtx, logger.Named("ws-hub"), ws.Gatekeeper(), ws.ConnListener(logger.Named("conn-listener"), ctrl, ctrl, ctrl))
 	// Serve endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, wsHub)
+		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl, wsHub, kafkaConnector.DeadLetterAdmin(sqlDB))
 		return meh.NilOrWrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 	})
 	// Run Kafka connector.
@@ -111,6 +118,8 @@ func Run(ctx context.Context) error {
 				event.UsersTopic,
 				event.AddressBookTopic,
 				event.IntelDeliveriesTopic,
+				event.IntelTopic,
+				event.PermissionsTopic,
 			})
 		kafkaWriter := kafkautil.NewWriter(logger.Named("kafka"), c.KafkaAddr)
 		err := kafkautil.RunConnector(egCtx, kafkaConnector, sqlDB, kafkaWriter, kafkaReader, eventPort.HandlerFn(ctrl))
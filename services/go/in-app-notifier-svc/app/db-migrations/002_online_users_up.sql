@@ -0,0 +1,9 @@
+-- Create online users table.
+
+create table online_users
+(
+    "user" uuid      not null primary key,
+    since  timestamp not null
+);
+
+comment on table online_users is 'Users with at least one open connection. Used in order to notify users as being offline again after restarts.';
@@ -0,0 +1,15 @@
+-- Create permissions table.
+
+create table permissions
+(
+    "user"  uuid    not null,
+    name    varchar not null,
+    options jsonb
+);
+
+comment on table permissions is 'Granted permissions for users. Used in order to identify users to notify about intel-delivery-escalations.';
+comment on column permissions."user" is 'The id of the user the permission was granted to.';
+comment on column permissions.name is 'The identifier of the permission that was granted.';
+comment on column permissions.options is 'Additional options for the permission.';
+
+create index permissions_user_ix on permissions ("user");
@@ -0,0 +1,18 @@
+-- Create notification inbox table.
+
+create table notification_inbox
+(
+    id         uuid primary key not null default uuid_generate_v4(),
+    seq        bigserial        not null unique,
+    "user"     uuid             not null,
+    attempt    uuid             not null unique references accepted_intel_delivery_attempts (id)
+        on delete cascade on update cascade,
+    created_at timestamp        not null,
+    read_at    timestamp
+);
+
+comment on table notification_inbox is 'Durable per-user inbox for notifications. Entries are retained for replaying them when users reconnect.';
+comment on column notification_inbox.seq is 'Increasing sequence number, used by clients as resume cursor.';
+comment on column notification_inbox."user" is 'The id of the user, the notification is for.';
+
+create index notification_inbox_user_seq_ix on notification_inbox ("user", seq);
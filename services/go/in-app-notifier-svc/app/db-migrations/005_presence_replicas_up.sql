@@ -0,0 +1,39 @@
+-- Track connections per replica, so that presence is correct with multiple
+-- replicas.
+
+create table presence_replicas
+(
+    id             uuid      not null primary key,
+    last_heartbeat timestamp not null
+);
+
+comment on table presence_replicas is 'Running replicas with their last heartbeat. Replicas without recent heartbeat are considered dead.';
+
+create table user_connections
+(
+    "user"  uuid      not null,
+    replica uuid      not null references presence_replicas (id)
+        on delete cascade on update cascade,
+    since   timestamp not null,
+    primary key ("user", replica)
+);
+
+comment on table user_connections is 'Users with at least one open connection to the replica.';
+
+-- Online users now hold the last published presence. Users, that are currently
+-- published as being online, have no connections and are therefore published as
+-- being offline with the next heartbeat.
+
+alter table online_users
+    rename to user_presence;
+
+alter table user_presence
+    rename column since to ts;
+
+alter table user_presence
+    add column is_online bool not null default true;
+
+alter table user_presence
+    alter column is_online drop default;
+
+comment on table user_presence is 'Last published presence of users. Rows are locked for serializing presence updates of the same user across replicas.';
@@ -16,6 +16,8 @@ type Connection interface {
 	UserID() uuid.UUID
 	// Notify sends the given store.OutgoingIntelDeliveryNotification.
 	Notify(ctx context.Context, notification store.OutgoingIntelDeliveryNotification) error
+	// NotifyIntelDeliveryEscalation sends the given store.IntelDeliveryEscalation.
+	NotifyIntelDeliveryEscalation(ctx context.Context, escalation store.IntelDeliveryEscalation) error
 	// Done receives, when the connection is closed.
 	//
 	// Warning: It MUST close eventually!
@@ -35,6 +37,10 @@ func (c *Controller) AcceptNewConnection(conn Connection) {
 	}
 	conns = append(conns, conn)
 	c.connectionsByUser[conn.UserID()] = conns
+	// If this is the first connection, the user went online.
+	if len(conns) == 1 {
+		go c.updateUserPresence(conn.UserID())
+	}
 	// On connection done, remove from list.
 	go c.removeConnWhenDone(conn)
 	// Schedule notification check concurrently. We do not need to wait for this or
@@ -75,4 +81,8 @@ func (c *Controller) removeConnWhenDone(conn Connection) {
 		return
 	}
 	c.connectionsByUser[conn.UserID()] = newConns
+	// If this was the last connection, the user went offline.
+	if len(newConns) == 0 {
+		go c.updateUserPresence(conn.UserID())
+	}
 }
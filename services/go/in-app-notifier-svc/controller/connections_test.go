@@ -6,6 +6,7 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"sync"
 	"testing"
@@ -19,6 +20,7 @@ type ConnectionMock struct {
 	cancel      context.CancelFunc
 	notifyFail  bool
 	outbox      []store.OutgoingIntelDeliveryNotification
+	escalations []store.IntelDeliveryEscalation
 	outboxMutex sync.Mutex
 }
 
@@ -45,6 +47,16 @@ func (m *ConnectionMock) Notify(_ context.Context, notification store.OutgoingIn
 	return nil
 }
 
+func (m *ConnectionMock) NotifyIntelDeliveryEscalation(_ context.Context, escalation store.IntelDeliveryEscalation) error {
+	if m.notifyFail {
+		return errors.New("sad life")
+	}
+	m.outboxMutex.Lock()
+	defer m.outboxMutex.Unlock()
+	m.escalations = append(m.escalations, escalation)
+	return nil
+}
+
 func (m *ConnectionMock) Done() <-chan struct{} {
 	return m.lifetime.Done()
 }
@@ -58,11 +70,45 @@ type ControllerAcceptNewConnectionSuite struct {
 
 func (suite *ControllerAcceptNewConnectionSuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.DB.GenTx = true
 	suite.newConn = func() *ConnectionMock {
 		return NewConnectionMock()
 	}
 }
 
+// expectPresenceUpdates allows presence updates for all users and returns a
+// channel that receives the online state for each notified update. The store
+// reports the given presence changes in order. Afterwards, presence is reported
+// as unchanged.
+func (suite *ControllerAcceptNewConnectionSuite) expectPresenceUpdates(changes ...bool) <-chan bool {
+	updates := make(chan bool, 256)
+	for _, isOnline := range changes {
+		suite.ctrl.Store.On("UserPresenceAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).
+			Return(!isOnline, nil).Once()
+		suite.ctrl.Store.On("IsUserConnected", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(isOnline, nil).Once()
+	}
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	suite.ctrl.Store.On("IsUserConnected", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	suite.ctrl.Store.On("AddUserConnection", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.ctrl.Store.On("RemoveUserConnection", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.ctrl.Store.On("UpdateUserPresence", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			updates <- args.Bool(3)
+		}).Return(nil).Maybe()
+	return updates
+}
+
+func (suite *ControllerAcceptNewConnectionSuite) waitForPresenceUpdate(ctx context.Context, updates <-chan bool, expectOnline bool) {
+	select {
+	case <-ctx.Done():
+		suite.Fail("context done while waiting for presence update")
+	case isOnline := <-updates:
+		suite.Equal(expectOnline, isOnline, "should notify correct presence")
+	}
+}
+
 func (suite *ControllerAcceptNewConnectionSuite) waitForLookAfterRequest(ctx context.Context) {
 	select {
 	case <-ctx.Done():
@@ -94,6 +140,7 @@ func (suite *ControllerAcceptNewConnectionSuite) waitAllConnectionsRemoved(ctx c
 
 func (suite *ControllerAcceptNewConnectionSuite) TestFirstForUser() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	presenceUpdates := suite.expectPresenceUpdates(true)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -107,6 +154,7 @@ func (suite *ControllerAcceptNewConnectionSuite) TestFirstForUser() {
 	}()
 
 	suite.waitForLookAfterRequest(timeout)
+	suite.waitForPresenceUpdate(timeout, presenceUpdates, true)
 	cancel()
 	wg.Wait()
 	wait()
@@ -114,6 +162,7 @@ func (suite *ControllerAcceptNewConnectionSuite) TestFirstForUser() {
 
 func (suite *ControllerAcceptNewConnectionSuite) TestMultipleForUser() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	defer suite.ctrl.Notifier.AssertNotCalled(suite.T(), "NotifyUserPresenceUpdated")
 	otherConns := make([]Connection, 8)
 	newConn := suite.newConn()
 	defer newConn.cancel()
@@ -152,6 +201,7 @@ func (suite *ControllerAcceptNewConnectionSuite) TestMultipleForUser() {
 
 func (suite *ControllerAcceptNewConnectionSuite) TestConnectionDone() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	presenceUpdates := suite.expectPresenceUpdates(true, false)
 	newConn := suite.newConn()
 	defer newConn.cancel()
 	conns := make([]*ConnectionMock, 32)
@@ -171,12 +221,14 @@ func (suite *ControllerAcceptNewConnectionSuite) TestConnectionDone() {
 	for range conns {
 		suite.waitForLookAfterRequest(timeout)
 	}
+	suite.waitForPresenceUpdate(timeout, presenceUpdates, true)
 	// Close all connections.
 	for _, conn := range conns {
 		conn.cancel()
 	}
 
 	suite.waitAllConnectionsRemoved(timeout)
+	suite.waitForPresenceUpdate(timeout, presenceUpdates, false)
 	cancel()
 	wg.Wait()
 	wait()
@@ -184,6 +236,7 @@ func (suite *ControllerAcceptNewConnectionSuite) TestConnectionDone() {
 
 func (suite *ControllerAcceptNewConnectionSuite) TestConnectionDoneMessAround() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	_ = suite.expectPresenceUpdates()
 	newConn := suite.newConn()
 	defer newConn.cancel()
 	var wg sync.WaitGroup
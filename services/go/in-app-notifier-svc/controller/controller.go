@@ -6,6 +6,8 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -28,6 +30,9 @@ type Controller struct {
 	// scheduleLookAfterUserNotifications for sending to this channel. Requests are
 	// read by workers in runLookAfterUserNotificationsScheduler.
 	lookAfterUserNotificationRequests chan uuid.UUID
+	// replicaID identifies this replica for tracking connections of users across
+	// replicas.
+	replicaID uuid.UUID
 }
 
 // NewController creates a new Controller. Do not forget to call Controller.Run.
@@ -39,6 +44,7 @@ func NewController(logger *zap.Logger, db pgutil.DBTxSupplier, store Store, noti
 		notifier:                          notifier,
 		connectionsByUser:                 make(map[uuid.UUID][]Connection),
 		lookAfterUserNotificationRequests: make(chan uuid.UUID, 256),
+		replicaID:                         uuid.Must(uuid.NewV4()),
 	}
 }
 
@@ -61,6 +67,14 @@ func (c *Controller) Run(lifetime context.Context) error {
 		}
 		return nil
 	})
+	// Run presence heartbeat.
+	eg.Go(func() error {
+		err := c.runPresenceHeartbeat(egCtx)
+		if err != nil {
+			return meh.Wrap(err, "run presence heartbeat", nil)
+		}
+		return nil
+	})
 	return eg.Wait()
 }
 
@@ -100,11 +114,73 @@ type Store interface {
 	// UpdateAcceptedIntelDeliveryAttemptStatus updates the given
 	// store.AcceptedIntelDeliveryAttemptStatus, identified by its id.
 	UpdateAcceptedIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, update store.AcceptedIntelDeliveryAttemptStatus) error
+	// DeactivateAcceptedIntelDeliveryAttemptsByIntel sets all active
+	// store.AcceptedIntelDeliveryAttempt for the intel with the given id to
+	// inactive with the given note.
+	DeactivateAcceptedIntelDeliveryAttemptsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, note string) error
+	// AcceptedIntelDeliveryAttemptByID retrieves the
+	// store.AcceptedIntelDeliveryAttempt with the given id.
+	AcceptedIntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.AcceptedIntelDeliveryAttempt, error)
 	// NotificationChannelByID retrieves the store.NotificationChannel with the
 	// given id.
 	NotificationChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (store.NotificationChannel, error)
 	// CreateIntelToDeliver creates the given store.IntelToDeliver in the store.
 	CreateIntelToDeliver(ctx context.Context, tx pgx.Tx, create store.IntelToDeliver) error
+	// IntelDeliveryNotificationByAttempt retrieves the
+	// store.OutgoingIntelDeliveryNotification for the attempt with the given id
+	// without locking it or requiring it to be active.
+	IntelDeliveryNotificationByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.OutgoingIntelDeliveryNotification, error)
+	// CreateInboxEntry creates the given store.InboxEntry.
+	CreateInboxEntry(ctx context.Context, tx pgx.Tx, create store.InboxEntry) error
+	// InboxEntryByID retrieves the store.InboxEntry with the given id.
+	InboxEntryByID(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) (store.InboxEntry, error)
+	// InboxEntriesByUserAfter retrieves at most the given limit of
+	// store.InboxEntry for the user with the given id, having a sequence number
+	// greater than the given one. Entries are sorted ascending by their sequence
+	// number.
+	InboxEntriesByUserAfter(ctx context.Context, tx pgx.Tx, userID uuid.UUID, afterSeq int64, limit int) ([]store.InboxEntry, error)
+	// InboxEntriesByUser retrieves a paginated store.InboxEntry list for the user
+	// with the given id, sorted descending by sequence number.
+	InboxEntriesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, filters store.InboxEntryFilters,
+		page pagination.Params) (pagination.Paginated[store.InboxEntry], error)
+	// MarkInboxEntryAsRead marks the store.InboxEntry with the given id as read at
+	// the given timestamp.
+	MarkInboxEntryAsRead(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, readAt time.Time) error
+	// CreatePresenceReplica creates the replica with the given id and heartbeat.
+	CreatePresenceReplica(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error
+	// UpdatePresenceReplicaHeartbeat sets the last heartbeat of the replica with
+	// the given id. If the replica was not found, because it was removed for being
+	// considered dead, a meh.ErrNotFound error is returned.
+	UpdatePresenceReplicaHeartbeat(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error
+	// DeleteDeadPresenceReplicas deletes all replicas with their last heartbeat
+	// being before the given timestamp. Their connections are deleted as well.
+	DeleteDeadPresenceReplicas(ctx context.Context, tx pgx.Tx, aliveSince time.Time) error
+	// AddUserConnection marks the user with the given id as being connected to the
+	// replica with the given id since the given timestamp. If the user is already
+	// connected to the replica, nothing is changed.
+	AddUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID, since time.Time) error
+	// RemoveUserConnection removes the connection of the user with the given id to
+	// the replica with the given id.
+	RemoveUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID) error
+	// IsUserConnected checks whether the user with the given id is connected to
+	// any replica with its last heartbeat not being before the given timestamp.
+	IsUserConnected(ctx context.Context, tx pgx.Tx, userID uuid.UUID, aliveSince time.Time) (bool, error)
+	// UserPresenceAndLockOrWait locks and retrieves the last stored presence of
+	// the user with the given id. If no presence is stored, yet, the user is
+	// created as being offline.
+	UserPresenceAndLockOrWait(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error)
+	// UpdateUserPresence sets the stored presence of the user with the given id.
+	UpdateUserPresence(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, ts time.Time) error
+	// OnlineUsersWithoutConnections retrieves the ids of all users, that are
+	// stored as being online, but are not connected to any replica with its last
+	// heartbeat not being before the given timestamp.
+	OnlineUsersWithoutConnections(ctx context.Context, tx pgx.Tx, aliveSince time.Time) ([]uuid.UUID, error)
+	// PermissionsByUser retrieves the granted permission.Permission list for the
+	// user with the given id.
+	PermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]permission.Permission, error)
+	// UpdatePermissionsByUser replaces the permissions for the user with the given
+	// id.
+	UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []permission.Permission) error
 }
 
 // Notifier for Controller.
@@ -115,4 +191,15 @@ type Notifier interface {
 	// NotifyIntelDeliveryNotificationSent notifies that an in-app-notification for
 	// an intel-delivery-attempt was sent.
 	NotifyIntelDeliveryNotificationSent(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, sentTS time.Time) error
+	// NotifyIntelDeliveryNotificationReceived notifies that an
+	// in-app-notification for an intel-delivery-attempt was received by the user
+	// with the given id.
+	NotifyIntelDeliveryNotificationReceived(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID, receivedAt time.Time) error
+	// NotifyIntelDeliveryNotificationAcknowledged notifies that an
+	// in-app-notification for an intel-delivery-attempt was acknowledged by the
+	// user with the given id.
+	NotifyIntelDeliveryNotificationAcknowledged(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID, acknowledgedAt time.Time) error
+	// NotifyUserPresenceUpdated notifies that the user with the given id went
+	// online or offline.
+	NotifyUserPresenceUpdated(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, lastSeen time.Time) error
 }
@@ -5,6 +5,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -76,6 +78,44 @@ func (m *StoreMock) UpdateAcceptedIntelDeliveryAttemptStatus(ctx context.Context
 	return m.Called(ctx, tx, update).Error(0)
 }
 
+func (m *StoreMock) DeactivateAcceptedIntelDeliveryAttemptsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, note string) error {
+	return m.Called(ctx, tx, intelID, note).Error(0)
+}
+
+func (m *StoreMock) AcceptedIntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.AcceptedIntelDeliveryAttempt, error) {
+	args := m.Called(ctx, tx, attemptID)
+	return args.Get(0).(store.AcceptedIntelDeliveryAttempt), args.Error(1)
+}
+
+func (m *StoreMock) IntelDeliveryNotificationByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.OutgoingIntelDeliveryNotification, error) {
+	args := m.Called(ctx, tx, attemptID)
+	return args.Get(0).(store.OutgoingIntelDeliveryNotification), args.Error(1)
+}
+
+func (m *StoreMock) CreateInboxEntry(ctx context.Context, tx pgx.Tx, create store.InboxEntry) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *StoreMock) InboxEntryByID(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) (store.InboxEntry, error) {
+	args := m.Called(ctx, tx, entryID)
+	return args.Get(0).(store.InboxEntry), args.Error(1)
+}
+
+func (m *StoreMock) InboxEntriesByUserAfter(ctx context.Context, tx pgx.Tx, userID uuid.UUID, afterSeq int64, limit int) ([]store.InboxEntry, error) {
+	args := m.Called(ctx, tx, userID, afterSeq, limit)
+	return args.Get(0).([]store.InboxEntry), args.Error(1)
+}
+
+func (m *StoreMock) InboxEntriesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, filters store.InboxEntryFilters,
+	page pagination.Params) (pagination.Paginated[store.InboxEntry], error) {
+	args := m.Called(ctx, tx, userID, filters, page)
+	return args.Get(0).(pagination.Paginated[store.InboxEntry]), args.Error(1)
+}
+
+func (m *StoreMock) MarkInboxEntryAsRead(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, readAt time.Time) error {
+	return m.Called(ctx, tx, entryID, readAt).Error(0)
+}
+
 func (m *StoreMock) NotificationChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (store.NotificationChannel, error) {
 	args := m.Called(ctx, tx, channelID)
 	return args.Get(0).(store.NotificationChannel), args.Error(1)
@@ -85,6 +125,58 @@ func (m *StoreMock) CreateIntelToDeliver(ctx context.Context, tx pgx.Tx, create
 	return m.Called(ctx, tx, create).Error(0)
 }
 
+func (m *StoreMock) CreatePresenceReplica(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error {
+	return m.Called(ctx, tx, replicaID, heartbeat).Error(0)
+}
+
+func (m *StoreMock) UpdatePresenceReplicaHeartbeat(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error {
+	return m.Called(ctx, tx, replicaID, heartbeat).Error(0)
+}
+
+func (m *StoreMock) DeleteDeadPresenceReplicas(ctx context.Context, tx pgx.Tx, aliveSince time.Time) error {
+	return m.Called(ctx, tx, aliveSince).Error(0)
+}
+
+func (m *StoreMock) AddUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID, since time.Time) error {
+	return m.Called(ctx, tx, userID, replicaID, since).Error(0)
+}
+
+func (m *StoreMock) RemoveUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID) error {
+	return m.Called(ctx, tx, userID, replicaID).Error(0)
+}
+
+func (m *StoreMock) IsUserConnected(ctx context.Context, tx pgx.Tx, userID uuid.UUID, aliveSince time.Time) (bool, error) {
+	args := m.Called(ctx, tx, userID, aliveSince)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *StoreMock) UserPresenceAndLockOrWait(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *StoreMock) UpdateUserPresence(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, ts time.Time) error {
+	return m.Called(ctx, tx, userID, isOnline, ts).Error(0)
+}
+
+func (m *StoreMock) OnlineUsersWithoutConnections(ctx context.Context, tx pgx.Tx, aliveSince time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, aliveSince)
+	var userIDs []uuid.UUID
+	userIDs, _ = args.Get(0).([]uuid.UUID)
+	return userIDs, args.Error(1)
+}
+
+func (m *StoreMock) PermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]permission.Permission, error) {
+	args := m.Called(ctx, tx, userID)
+	var p []permission.Permission
+	p, _ = args.Get(0).([]permission.Permission)
+	return p, args.Error(1)
+}
+
+func (m *StoreMock) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []permission.Permission) error {
+	return m.Called(ctx, tx, userID, permissions).Error(0)
+}
+
 // NotifierMock mocks Notifier.
 type NotifierMock struct {
 	mock.Mock
@@ -97,3 +189,15 @@ func (m *NotifierMock) NotifyIntelDeliveryNotificationSent(ctx context.Context,
 func (m *NotifierMock) NotifyIntelDeliveryNotificationPending(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, acceptedTS time.Time) error {
 	return m.Called(ctx, tx, attemptID, acceptedTS).Error(0)
 }
+
+func (m *NotifierMock) NotifyIntelDeliveryNotificationReceived(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID, receivedAt time.Time) error {
+	return m.Called(ctx, tx, attemptID, by, receivedAt).Error(0)
+}
+
+func (m *NotifierMock) NotifyIntelDeliveryNotificationAcknowledged(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID, acknowledgedAt time.Time) error {
+	return m.Called(ctx, tx, attemptID, by, acknowledgedAt).Error(0)
+}
+
+func (m *NotifierMock) NotifyUserPresenceUpdated(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, lastSeen time.Time) error {
+	return m.Called(ctx, tx, userID, isOnline, lastSeen).Error(0)
+}
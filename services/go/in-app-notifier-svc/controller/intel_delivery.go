@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
@@ -36,8 +37,18 @@ func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx,
 	if err != nil {
 		return meh.Wrap(err, "notify intel-delivery-notification pending", meh.Details{"attempt_id": attempt.Delivery})
 	}
-	// Schedule looking after the assigned user.
 	if attempt.AssignedToUser.Valid {
+		// Add to the inbox of the assigned user, so that the notification is
+		// retained while the user is offline.
+		err = c.store.CreateInboxEntry(ctx, tx, store.InboxEntry{
+			User:      attempt.AssignedToUser.UUID,
+			Attempt:   attempt.ID,
+			CreatedAt: attempt.AcceptedAt,
+		})
+		if err != nil {
+			return meh.Wrap(err, "create inbox entry in store", meh.Details{"attempt_id": attempt.ID})
+		}
+		// Schedule looking after the assigned user.
 		err = c.scheduleLookAfterUserNotifications(ctx, attempt.AssignedToUser.UUID)
 		if err != nil {
 			return meh.Wrap(err, "schedule look after user-notifications", meh.Details{"user_id": attempt.AssignedTo})
@@ -56,3 +67,13 @@ func (c *Controller) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pg
 	}
 	return nil
 }
+
+// ExpireIntel sets all active accepted intel-delivery-attempts for the intel
+// with the given id to inactive, so that no more notifications are sent.
+func (c *Controller) ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	err := c.store.DeactivateAcceptedIntelDeliveryAttemptsByIntel(ctx, tx, intelID, "intel expired")
+	if err != nil {
+		return meh.Wrap(err, "deactivate accepted intel-delivery-attempts by intel in store", meh.Details{"intel_id": intelID})
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"sync"
+)
+
+// NotifyIntelDeliveryEscalation sends the given store.IntelDeliveryEscalation
+// to all connected users, that are allowed to manage intel-deliveries in the
+// operation of the escalated delivery. Users not being connected are not
+// notified.
+func (c *Controller) NotifyIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation) error {
+	// Copy connections in order to not block while querying permissions.
+	c.connectionsByUserMutex.RLock()
+	connsByUser := make(map[uuid.UUID][]Connection, len(c.connectionsByUser))
+	for userID, userConns := range c.connectionsByUser {
+		if len(userConns) == 0 {
+			continue
+		}
+		connsByUser[userID] = append([]Connection(nil), userConns...)
+	}
+	c.connectionsByUserMutex.RUnlock()
+	// Filter for users, that are allowed to manage intel-deliveries.
+	conns := make([]Connection, 0)
+	for userID, userConns := range connsByUser {
+		granted, err := c.store.PermissionsByUser(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "permissions by user from store", meh.Details{"user_id": userID})
+		}
+		ok, err := permission.Has(granted, permission.InOperation(escalation.Operation, permission.ManageIntelDelivery()))
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{
+				"user_id":   userID,
+				"granted":   granted,
+				"operation": escalation.Operation,
+			})
+		}
+		if !ok {
+			continue
+		}
+		conns = append(conns, userConns...)
+	}
+	c.broadcastIntelDeliveryEscalation(ctx, escalation, conns)
+	return nil
+}
+
+// broadcastIntelDeliveryEscalation broadcasts the given
+// store.IntelDeliveryEscalation concurrently to the Connection list. Errors are
+// logged to the logger.
+func (c *Controller) broadcastIntelDeliveryEscalation(ctx context.Context, escalation store.IntelDeliveryEscalation, conns []Connection) {
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		conn := conn
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := conn.NotifyIntelDeliveryEscalation(ctx, escalation)
+			if err != nil {
+				mehlog.Log(c.logger, meh.Wrap(err, "notify intel-delivery-escalation via connection", meh.Details{
+					"escalation_id": escalation.ID,
+					"delivery_id":   escalation.Delivery,
+					"user_id":       conn.UserID(),
+				}))
+				return
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerNotifyIntelDeliveryEscalationSuite tests
+// Controller.NotifyIntelDeliveryEscalation.
+type ControllerNotifyIntelDeliveryEscalationSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	tx               *testutil.DBTx
+	managerConns     []*ConnectionMock
+	otherConn        *ConnectionMock
+	sampleEscalation store.IntelDeliveryEscalation
+}
+
+func (suite *ControllerNotifyIntelDeliveryEscalationSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleEscalation = store.IntelDeliveryEscalation{
+		ID:          testutil.NewUUIDV4(),
+		Delivery:    testutil.NewUUIDV4(),
+		Intel:       testutil.NewUUIDV4(),
+		Operation:   testutil.NewUUIDV4(),
+		Trigger:     store.IntelDeliveryEscalationTriggerFailed,
+		EscalatedAt: time.Date(2022, 9, 8, 1, 52, 4, 0, time.UTC),
+		EscalatedTo: nulls.NewUUID(testutil.NewUUIDV4()),
+	}
+	// Manager with two connections.
+	manager := NewConnectionMock()
+	managerSecondConn := NewConnectionMock()
+	managerSecondConn.userID = manager.userID
+	suite.managerConns = []*ConnectionMock{manager, managerSecondConn}
+	suite.ctrl.Ctrl.connectionsByUser[manager.userID] = []Connection{manager, managerSecondConn}
+	suite.otherConn = NewConnectionMock()
+	suite.ctrl.Ctrl.connectionsByUser[suite.otherConn.userID] = []Connection{suite.otherConn}
+	suite.T().Cleanup(func() {
+		for _, conn := range suite.managerConns {
+			conn.cancel()
+		}
+		suite.otherConn.cancel()
+	})
+}
+
+func (suite *ControllerNotifyIntelDeliveryEscalationSuite) TestRetrievePermissionsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.managerConns[0].userID).
+		Return(nil, errors.New("sad life")).Maybe()
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.otherConn.userID).
+		Return(nil, errors.New("sad life")).Maybe()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.NotifyIntelDeliveryEscalation(timeout, suite.tx, suite.sampleEscalation)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerNotifyIntelDeliveryEscalationSuite) TestNotifyFailForOneConnection() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.managerConns[0].notifyFail = true
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.managerConns[0].userID).
+		Return([]permission.Permission{{Name: permission.ManageIntelDeliveryPermissionName}}, nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.otherConn.userID).
+		Return([]permission.Permission{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.NotifyIntelDeliveryEscalation(timeout, suite.tx, suite.sampleEscalation)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]store.IntelDeliveryEscalation{suite.sampleEscalation}, suite.managerConns[1].escalations,
+			"should notify other connection")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerNotifyIntelDeliveryEscalationSuite) TestOtherOperation() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.managerConns[0].userID).
+		Return([]permission.Permission{{
+			Name:    permission.ManageIntelDeliveryPermissionName,
+			Options: nulls.NewJSONRawMessage(json.RawMessage(`{"operations":["` + testutil.NewUUIDV4().String() + `"]}`)),
+		}}, nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.otherConn.userID).
+		Return([]permission.Permission{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.NotifyIntelDeliveryEscalation(timeout, suite.tx, suite.sampleEscalation)
+		suite.Require().NoError(err, "should not fail")
+		for _, conn := range suite.managerConns {
+			suite.Empty(conn.escalations, "should not notify manager of other operation")
+		}
+	}()
+
+	wait()
+}
+
+func (suite *ControllerNotifyIntelDeliveryEscalationSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.managerConns[0].userID).
+		Return([]permission.Permission{{
+			Name:    permission.ManageIntelDeliveryPermissionName,
+			Options: nulls.NewJSONRawMessage(json.RawMessage(`{"operations":["` + suite.sampleEscalation.Operation.String() + `"]}`)),
+		}}, nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.otherConn.userID).
+		Return([]permission.Permission{{Name: permission.DeliverIntelPermissionName}}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.NotifyIntelDeliveryEscalation(timeout, suite.tx, suite.sampleEscalation)
+		suite.Require().NoError(err, "should not fail")
+		for _, conn := range suite.managerConns {
+			suite.Equal([]store.IntelDeliveryEscalation{suite.sampleEscalation}, conn.escalations,
+				"should notify all manager connections")
+		}
+		suite.Empty(suite.otherConn.escalations, "should not notify user without permission")
+	}()
+
+	wait()
+}
+
+func TestController_NotifyIntelDeliveryEscalation(t *testing.T) {
+	suite.Run(t, new(ControllerNotifyIntelDeliveryEscalationSuite))
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"encoding/json"
 	"errors"
+	"github.com/gofrs/uuid"
 	"github.com/lefinal/meh"
 	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
@@ -150,6 +151,64 @@ func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestOK() {
 	wait()
 }
 
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreateInboxEntryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleAttempt.AssignedToUser = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.ctrl.Store.On("NotificationChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.NotificationChannel{}, nil)
+	suite.ctrl.Store.On("CreateIntelToDeliver", timeout, suite.tx, suite.sampleIntelToDeliver).
+		Return(nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreateInboxEntry", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationPending", timeout, suite.tx, suite.sampleAttempt.ID, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleIntelToDeliver)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestOKWithAssignedUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	assignedUser := testutil.NewUUIDV4()
+	suite.sampleAttempt.AssignedToUser = nulls.NewUUID(assignedUser)
+	suite.ctrl.Store.On("NotificationChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.NotificationChannel{}, nil)
+	suite.ctrl.Store.On("CreateIntelToDeliver", timeout, suite.tx, suite.sampleIntelToDeliver).
+		Return(nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreateInboxEntry", timeout, suite.tx, mock.MatchedBy(func(entry store.InboxEntry) bool {
+		return entry.User == assignedUser && entry.Attempt == suite.sampleAttempt.ID && !entry.ReadAt.Valid
+	})).Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationPending", timeout, suite.tx, suite.sampleAttempt.ID, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleIntelToDeliver)
+		suite.Require().NoError(err, "should not fail")
+		select {
+		case <-timeout.Done():
+			suite.Fail("timeout", "timeout while waiting for scheduled look-after-user-notifications")
+		case userID := <-suite.ctrl.Ctrl.lookAfterUserNotificationRequests:
+			suite.Equal(assignedUser, userID, "should schedule look-after-user-notifications for assigned user")
+		}
+	}()
+
+	wait()
+}
+
 func TestController_CreateIntelDeliveryAttempt(t *testing.T) {
 	suite.Run(t, new(ControllerCreateIntelDeliveryAttemptSuite))
 }
@@ -222,3 +281,51 @@ func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestOK() {
 func TestController_UpdateIntelDeliveryAttemptStatus(t *testing.T) {
 	suite.Run(t, new(ControllerUpdateIntelDeliveryAttemptStatusSuite))
 }
+
+// ControllerExpireIntelSuite tests Controller.ExpireIntel.
+type ControllerExpireIntelSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleIntelID uuid.UUID
+}
+
+func (suite *ControllerExpireIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerExpireIntelSuite) TestStoreDeactivateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeactivateAcceptedIntelDeliveryAttemptsByIntel", timeout, suite.tx, suite.sampleIntelID, "intel expired").
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeactivateAcceptedIntelDeliveryAttemptsByIntel", timeout, suite.tx, suite.sampleIntelID, "intel expired").
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_ExpireIntel(t *testing.T) {
+	suite.Run(t, new(ControllerExpireIntelSuite))
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// MarkIntelNotificationAsReceived notifies that the in-app-notification for the
+// intel-delivery-attempt with the given id was received by the user with the
+// given id. The user must be the one, assigned to the attempt. If the attempt is
+// not active anymore, nothing is notified.
+func (c *Controller) MarkIntelNotificationAsReceived(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		attempt, ok, err := c.assignedActiveIntelDeliveryAttempt(ctx, tx, attemptID, by)
+		if err != nil {
+			return meh.Wrap(err, "assigned active intel-delivery-attempt", nil)
+		}
+		if !ok {
+			return nil
+		}
+		err = c.notifier.NotifyIntelDeliveryNotificationReceived(ctx, tx, attempt.ID, by, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "notify intel-delivery-notification received", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", meh.Details{
+			"attempt_id": attemptID,
+			"by":         by,
+		})
+	}
+	return nil
+}
+
+// AcknowledgeIntelNotification notifies that the in-app-notification for the
+// intel-delivery-attempt with the given id was read and acknowledged by the user
+// with the given id. The user must be the one, assigned to the attempt. If the
+// attempt is not active anymore, nothing is notified.
+func (c *Controller) AcknowledgeIntelNotification(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		attempt, ok, err := c.assignedActiveIntelDeliveryAttempt(ctx, tx, attemptID, by)
+		if err != nil {
+			return meh.Wrap(err, "assigned active intel-delivery-attempt", nil)
+		}
+		if !ok {
+			return nil
+		}
+		err = c.notifier.NotifyIntelDeliveryNotificationAcknowledged(ctx, tx, attempt.ID, by, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "notify intel-delivery-notification acknowledged", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", meh.Details{
+			"attempt_id": attemptID,
+			"by":         by,
+		})
+	}
+	return nil
+}
+
+// assignedActiveIntelDeliveryAttempt retrieves the
+// store.AcceptedIntelDeliveryAttempt with the given id and assures that it is
+// assigned to the user with the given id. If the attempt is not active anymore,
+// false is returned.
+func (c *Controller) assignedActiveIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID,
+	userID uuid.UUID) (store.AcceptedIntelDeliveryAttempt, bool, error) {
+	attempt, err := c.store.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		return store.AcceptedIntelDeliveryAttempt{}, false, meh.Wrap(err, "accepted intel-delivery-attempt by id from store",
+			meh.Details{"attempt_id": attemptID})
+	}
+	if !attempt.AssignedToUser.Valid || attempt.AssignedToUser.UUID != userID {
+		return store.AcceptedIntelDeliveryAttempt{}, false, meh.NewForbiddenErr("attempt not assigned to user", meh.Details{
+			"assigned_to_user": attempt.AssignedToUser,
+			"user_id":          userID,
+		})
+	}
+	if !attempt.IsActive {
+		return store.AcceptedIntelDeliveryAttempt{}, false, nil
+	}
+	return attempt, true, nil
+}
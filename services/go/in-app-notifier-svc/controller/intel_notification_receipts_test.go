@@ -0,0 +1,313 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerMarkIntelNotificationAsReceivedSuite tests
+// Controller.MarkIntelNotificationAsReceived.
+type ControllerMarkIntelNotificationAsReceivedSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleUserID  uuid.UUID
+	sampleAttempt store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             testutil.NewUUIDV4(),
+		AssignedTo:     testutil.NewUUIDV4(),
+		AssignedToUser: nulls.NewUUID(suite.sampleUserID),
+		Delivery:       testutil.NewUUIDV4(),
+		Channel:        testutil.NewUUIDV4(),
+		IsActive:       true,
+	}
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestRetrieveAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestNoAssignedUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	attempt := suite.sampleAttempt
+	attempt.AssignedToUser = uuid.NullUUID{}
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(attempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestAssignedToOtherUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, testutil.NewUUIDV4())
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestAttemptInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	attempt := suite.sampleAttempt
+	attempt.IsActive = false
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(attempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationReceived", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleUserID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkIntelNotificationAsReceivedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationReceived", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleUserID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkIntelNotificationAsReceived(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_MarkIntelNotificationAsReceived(t *testing.T) {
+	suite.Run(t, new(ControllerMarkIntelNotificationAsReceivedSuite))
+}
+
+// ControllerAcknowledgeIntelNotificationSuite tests
+// Controller.AcknowledgeIntelNotification.
+type ControllerAcknowledgeIntelNotificationSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleUserID  uuid.UUID
+	sampleAttempt store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             testutil.NewUUIDV4(),
+		AssignedTo:     testutil.NewUUIDV4(),
+		AssignedToUser: nulls.NewUUID(suite.sampleUserID),
+		Delivery:       testutil.NewUUIDV4(),
+		Channel:        testutil.NewUUIDV4(),
+		IsActive:       true,
+	}
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestRetrieveAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestNoAssignedUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	attempt := suite.sampleAttempt
+	attempt.AssignedToUser = uuid.NullUUID{}
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(attempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestAssignedToOtherUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, testutil.NewUUIDV4())
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestAttemptInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	attempt := suite.sampleAttempt
+	attempt.IsActive = false
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(attempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationAcknowledged", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleUserID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelNotificationSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryNotificationAcknowledged", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleUserID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelNotification(timeout, suite.sampleAttempt.ID, suite.sampleUserID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_AcknowledgeIntelNotification(t *testing.T) {
+	suite.Run(t, new(ControllerAcknowledgeIntelNotificationSuite))
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// inboxReplayBatchSize is the maximum amount of inbox entries to retrieve at
+// once in Controller.ReplayInbox.
+const inboxReplayBatchSize = 64
+
+// ReplayInbox sends all inbox entries for the user of the given Connection in
+// order, that have a sequence number greater than the given one. This allows
+// clients to resume after reconnecting.
+func (c *Controller) ReplayInbox(ctx context.Context, conn Connection, afterSeq int64) error {
+	for {
+		var notifs []store.OutgoingIntelDeliveryNotification
+		err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+			entries, err := c.store.InboxEntriesByUserAfter(ctx, tx, conn.UserID(), afterSeq, inboxReplayBatchSize)
+			if err != nil {
+				return meh.Wrap(err, "inbox entries by user after from store", meh.Details{
+					"user_id":   conn.UserID(),
+					"after_seq": afterSeq,
+				})
+			}
+			notifs, err = c.inboxEntryNotifications(ctx, tx, entries)
+			if err != nil {
+				return meh.Wrap(err, "inbox entry notifications", nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "run in tx", nil)
+		}
+		for _, notif := range notifs {
+			err = conn.Notify(ctx, notif)
+			if err != nil {
+				return meh.Wrap(err, "notify via connection", meh.Details{"attempt_id": notif.DeliveryAttempt.ID})
+			}
+			afterSeq = notif.InboxEntry.V.Seq
+		}
+		if len(notifs) < inboxReplayBatchSize {
+			return nil
+		}
+	}
+}
+
+// InboxByUser retrieves a paginated list of notifications from the inbox of the
+// user with the given id, sorted descending by their sequence number.
+func (c *Controller) InboxByUser(ctx context.Context, userID uuid.UUID, filters store.InboxEntryFilters,
+	page pagination.Params) (pagination.Paginated[store.OutgoingIntelDeliveryNotification], error) {
+	var notifs pagination.Paginated[store.OutgoingIntelDeliveryNotification]
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		entries, err := c.store.InboxEntriesByUser(ctx, tx, userID, filters, page)
+		if err != nil {
+			return meh.Wrap(err, "inbox entries by user from store", meh.Details{
+				"user_id": userID,
+				"filters": filters,
+				"page":    page,
+			})
+		}
+		entryNotifs, err := c.inboxEntryNotifications(ctx, tx, entries.Entries)
+		if err != nil {
+			return meh.Wrap(err, "inbox entry notifications", nil)
+		}
+		notifs = pagination.PaginatedFromPaginated(entries, entryNotifs)
+		return nil
+	})
+	if err != nil {
+		return pagination.Paginated[store.OutgoingIntelDeliveryNotification]{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return notifs, nil
+}
+
+// inboxEntryNotifications retrieves the store.OutgoingIntelDeliveryNotification
+// for each of the given store.InboxEntry list while keeping the order.
+func (c *Controller) inboxEntryNotifications(ctx context.Context, tx pgx.Tx,
+	entries []store.InboxEntry) ([]store.OutgoingIntelDeliveryNotification, error) {
+	notifs := make([]store.OutgoingIntelDeliveryNotification, 0, len(entries))
+	for _, entry := range entries {
+		notif, err := c.store.IntelDeliveryNotificationByAttempt(ctx, tx, entry.Attempt)
+		if err != nil {
+			return nil, meh.Wrap(err, "intel-delivery-notification by attempt from store", meh.Details{"attempt_id": entry.Attempt})
+		}
+		notif.InboxEntry = nulls.NewJSONNullable(entry)
+		notifs = append(notifs, notif)
+	}
+	return notifs, nil
+}
+
+// MarkInboxEntryAsRead marks the inbox entry with the given id as read. The
+// entry must belong to the user with the given id.
+func (c *Controller) MarkInboxEntryAsRead(ctx context.Context, entryID uuid.UUID, by uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		entry, err := c.store.InboxEntryByID(ctx, tx, entryID)
+		if err != nil {
+			return meh.Wrap(err, "inbox entry by id from store", meh.Details{"entry_id": entryID})
+		}
+		if entry.User != by {
+			return meh.NewForbiddenErr("inbox entry belongs to other user", meh.Details{
+				"entry_user": entry.User,
+				"by":         by,
+			})
+		}
+		err = c.store.MarkInboxEntryAsRead(ctx, tx, entryID, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "mark inbox entry as read in store", meh.Details{"entry_id": entryID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerReplayInboxSuite tests Controller.ReplayInbox.
+type ControllerReplayInboxSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	conn          *ConnectionMock
+	sampleAfter   int64
+	sampleEntries []store.InboxEntry
+}
+
+func (suite *ControllerReplayInboxSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.conn = NewConnectionMock()
+	suite.T().Cleanup(suite.conn.cancel)
+	suite.sampleAfter = 17
+	suite.sampleEntries = []store.InboxEntry{
+		{
+			ID:        testutil.NewUUIDV4(),
+			Seq:       20,
+			User:      suite.conn.userID,
+			Attempt:   testutil.NewUUIDV4(),
+			CreatedAt: time.Date(2022, 9, 8, 1, 46, 23, 0, time.UTC),
+		},
+		{
+			ID:        testutil.NewUUIDV4(),
+			Seq:       24,
+			User:      suite.conn.userID,
+			Attempt:   testutil.NewUUIDV4(),
+			CreatedAt: time.Date(2022, 9, 8, 1, 47, 23, 0, time.UTC),
+			ReadAt:    nulls.NewTime(time.Date(2022, 9, 8, 1, 48, 23, 0, time.UTC)),
+		},
+	}
+}
+
+func (suite *ControllerReplayInboxSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReplayInboxSuite) TestRetrieveEntriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, suite.tx, suite.conn.userID, suite.sampleAfter, mock.Anything).
+		Return([]store.InboxEntry{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Error(err, "should fail")
+		suite.Empty(suite.conn.outbox, "should not notify")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReplayInboxSuite) TestRetrieveNotificationFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, suite.tx, suite.conn.userID, suite.sampleAfter, mock.Anything).
+		Return(suite.sampleEntries, nil)
+	suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.OutgoingIntelDeliveryNotification{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Error(err, "should fail")
+		suite.Empty(suite.conn.outbox, "should not notify")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReplayInboxSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.conn.notifyFail = true
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, suite.tx, suite.conn.userID, suite.sampleAfter, mock.Anything).
+		Return(suite.sampleEntries, nil)
+	suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.OutgoingIntelDeliveryNotification{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReplayInboxSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, suite.tx, suite.conn.userID, suite.sampleAfter, mock.Anything).
+		Return(suite.sampleEntries, nil).Once()
+	for _, entry := range suite.sampleEntries {
+		suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, entry.Attempt).
+			Return(store.OutgoingIntelDeliveryNotification{
+				DeliveryAttempt: store.AcceptedIntelDeliveryAttempt{ID: entry.Attempt},
+			}, nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Require().NoError(err, "should not fail")
+		suite.Require().Len(suite.conn.outbox, len(suite.sampleEntries), "should notify all entries")
+		for i, entry := range suite.sampleEntries {
+			suite.Equal(entry.Attempt, suite.conn.outbox[i].DeliveryAttempt.ID, "should notify in order")
+			suite.Equal(nulls.NewJSONNullable(entry), suite.conn.outbox[i].InboxEntry, "should set inbox entry")
+		}
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReplayInboxSuite) TestMultipleBatches() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx2 := &testutil.DBTx{}
+	suite.ctrl.DB.Tx = append(suite.ctrl.DB.Tx, tx2)
+	firstBatch := make([]store.InboxEntry, 0, inboxReplayBatchSize)
+	for i := 0; i < inboxReplayBatchSize; i++ {
+		firstBatch = append(firstBatch, store.InboxEntry{
+			ID:      testutil.NewUUIDV4(),
+			Seq:     int64(100 + i),
+			User:    suite.conn.userID,
+			Attempt: testutil.NewUUIDV4(),
+		})
+	}
+	lastSeq := firstBatch[len(firstBatch)-1].Seq
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, suite.tx, suite.conn.userID, suite.sampleAfter, inboxReplayBatchSize).
+		Return(firstBatch, nil).Once()
+	suite.ctrl.Store.On("InboxEntriesByUserAfter", timeout, tx2, suite.conn.userID, lastSeq, inboxReplayBatchSize).
+		Return([]store.InboxEntry{}, nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.OutgoingIntelDeliveryNotification{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReplayInbox(timeout, suite.conn, suite.sampleAfter)
+		suite.Require().NoError(err, "should not fail")
+		suite.Len(suite.conn.outbox, inboxReplayBatchSize, "should notify all entries")
+	}()
+
+	wait()
+}
+
+func TestController_ReplayInbox(t *testing.T) {
+	suite.Run(t, new(ControllerReplayInboxSuite))
+}
+
+// ControllerInboxByUserSuite tests Controller.InboxByUser.
+type ControllerInboxByUserSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleUserID  uuid.UUID
+	sampleFilters store.InboxEntryFilters
+	samplePage    pagination.Params
+	sampleEntries pagination.Paginated[store.InboxEntry]
+}
+
+func (suite *ControllerInboxByUserSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.sampleFilters = store.InboxEntryFilters{ByRead: nulls.NewBool(false)}
+	suite.samplePage = pagination.Params{Limit: 2, Offset: 4}
+	suite.sampleEntries = pagination.NewPaginated(suite.samplePage, []store.InboxEntry{
+		{
+			ID:      testutil.NewUUIDV4(),
+			Seq:     9,
+			User:    suite.sampleUserID,
+			Attempt: testutil.NewUUIDV4(),
+		},
+		{
+			ID:      testutil.NewUUIDV4(),
+			Seq:     3,
+			User:    suite.sampleUserID,
+			Attempt: testutil.NewUUIDV4(),
+		},
+	}, 12)
+}
+
+func (suite *ControllerInboxByUserSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.InboxByUser(timeout, suite.sampleUserID, suite.sampleFilters, suite.samplePage)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerInboxByUserSuite) TestRetrieveEntriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUser", timeout, suite.tx, suite.sampleUserID, suite.sampleFilters, suite.samplePage).
+		Return(pagination.Paginated[store.InboxEntry]{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.InboxByUser(timeout, suite.sampleUserID, suite.sampleFilters, suite.samplePage)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerInboxByUserSuite) TestRetrieveNotificationFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUser", timeout, suite.tx, suite.sampleUserID, suite.sampleFilters, suite.samplePage).
+		Return(suite.sampleEntries, nil)
+	suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.OutgoingIntelDeliveryNotification{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.InboxByUser(timeout, suite.sampleUserID, suite.sampleFilters, suite.samplePage)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerInboxByUserSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntriesByUser", timeout, suite.tx, suite.sampleUserID, suite.sampleFilters, suite.samplePage).
+		Return(suite.sampleEntries, nil).Once()
+	expectedNotifs := make([]store.OutgoingIntelDeliveryNotification, 0, len(suite.sampleEntries.Entries))
+	for _, entry := range suite.sampleEntries.Entries {
+		notif := store.OutgoingIntelDeliveryNotification{
+			DeliveryAttempt: store.AcceptedIntelDeliveryAttempt{ID: entry.Attempt},
+		}
+		suite.ctrl.Store.On("IntelDeliveryNotificationByAttempt", timeout, suite.tx, entry.Attempt).
+			Return(notif, nil).Once()
+		notif.InboxEntry = nulls.NewJSONNullable(entry)
+		expectedNotifs = append(expectedNotifs, notif)
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.InboxByUser(timeout, suite.sampleUserID, suite.sampleFilters, suite.samplePage)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(pagination.PaginatedFromPaginated(suite.sampleEntries, expectedNotifs), got, "should return correct result")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_InboxByUser(t *testing.T) {
+	suite.Run(t, new(ControllerInboxByUserSuite))
+}
+
+// ControllerMarkInboxEntryAsReadSuite tests Controller.MarkInboxEntryAsRead.
+type ControllerMarkInboxEntryAsReadSuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	tx          *testutil.DBTx
+	sampleEntry store.InboxEntry
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleEntry = store.InboxEntry{
+		ID:        testutil.NewUUIDV4(),
+		Seq:       92,
+		User:      testutil.NewUUIDV4(),
+		Attempt:   testutil.NewUUIDV4(),
+		CreatedAt: time.Date(2022, 9, 8, 1, 46, 23, 0, time.UTC),
+	}
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkInboxEntryAsRead(timeout, suite.sampleEntry.ID, suite.sampleEntry.User)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) TestRetrieveEntryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntryByID", timeout, suite.tx, suite.sampleEntry.ID).
+		Return(store.InboxEntry{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkInboxEntryAsRead(timeout, suite.sampleEntry.ID, suite.sampleEntry.User)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) TestOtherUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntryByID", timeout, suite.tx, suite.sampleEntry.ID).
+		Return(suite.sampleEntry, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkInboxEntryAsRead(timeout, suite.sampleEntry.ID, testutil.NewUUIDV4())
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) TestMarkFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntryByID", timeout, suite.tx, suite.sampleEntry.ID).
+		Return(suite.sampleEntry, nil)
+	suite.ctrl.Store.On("MarkInboxEntryAsRead", timeout, suite.tx, suite.sampleEntry.ID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkInboxEntryAsRead(timeout, suite.sampleEntry.ID, suite.sampleEntry.User)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerMarkInboxEntryAsReadSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("InboxEntryByID", timeout, suite.tx, suite.sampleEntry.ID).
+		Return(suite.sampleEntry, nil)
+	suite.ctrl.Store.On("MarkInboxEntryAsRead", timeout, suite.tx, suite.sampleEntry.ID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.MarkInboxEntryAsRead(timeout, suite.sampleEntry.ID, suite.sampleEntry.User)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_MarkInboxEntryAsRead(t *testing.T) {
+	suite.Run(t, new(ControllerMarkInboxEntryAsReadSuite))
+}
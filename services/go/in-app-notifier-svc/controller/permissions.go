@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+)
+
+// UpdatePermissionsByUser replaces the permissions for the user with the given
+// id in the store.
+func (c *Controller) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []permission.Permission) error {
+	err := c.store.UpdatePermissionsByUser(ctx, tx, userID, permissions)
+	if err != nil {
+		return meh.Wrap(err, "update permissions by user in store", meh.Details{
+			"user_id":     userID,
+			"permissions": permissions,
+		})
+	}
+	return nil
+}
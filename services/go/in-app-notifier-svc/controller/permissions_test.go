@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerUpdatePermissionsByUserSuite tests
+// Controller.UpdatePermissionsByUser.
+type ControllerUpdatePermissionsByUserSuite struct {
+	suite.Suite
+	ctrl              *ControllerMock
+	sampleUserID      uuid.UUID
+	samplePermissions []permission.Permission
+}
+
+func (suite *ControllerUpdatePermissionsByUserSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.samplePermissions = []permission.Permission{
+		{Name: permission.ManageIntelDeliveryPermissionName},
+	}
+}
+
+func (suite *ControllerUpdatePermissionsByUserSuite) TestUpdateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, tx, suite.sampleUserID, suite.samplePermissions).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, tx, suite.sampleUserID, suite.samplePermissions)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdatePermissionsByUserSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, tx, suite.sampleUserID, suite.samplePermissions).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, tx, suite.sampleUserID, suite.samplePermissions)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdatePermissionsByUser(t *testing.T) {
+	suite.Run(t, new(ControllerUpdatePermissionsByUserSuite))
+}
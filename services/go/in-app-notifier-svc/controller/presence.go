@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// presenceHeartbeatInterval is the interval in which
+// Controller.runPresenceHeartbeat updates the heartbeat of the replica.
+const presenceHeartbeatInterval = 8 * time.Second
+
+// presenceReplicaTTL is the duration after which a replica without heartbeat is
+// considered dead. Connections to dead replicas are not considered for
+// presence.
+const presenceReplicaTTL = 4 * presenceHeartbeatInterval
+
+// isUserConnectedLocally checks whether the user with the given id has any
+// connection to this replica.
+func (c *Controller) isUserConnectedLocally(userID uuid.UUID) bool {
+	c.connectionsByUserMutex.RLock()
+	defer c.connectionsByUserMutex.RUnlock()
+	return len(c.connectionsByUser[userID]) > 0
+}
+
+// updateUserPresence updates the presence of the user with the given id using
+// syncUserPresence. Errors are logged to the logger.
+func (c *Controller) updateUserPresence(userID uuid.UUID) {
+	timeout, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	err := pgutil.RunInTx(timeout, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		return c.syncUserPresence(ctx, tx, userID)
+	})
+	if err != nil {
+		mehlog.Log(c.logger, meh.Wrap(err, "update user presence", meh.Details{"user_id": userID}))
+		return
+	}
+}
+
+// syncUserPresence stores whether the user with the given id is connected to
+// this replica. The user is online, if connected to any live replica. If this
+// differs from the last stored presence, the new one is stored and notified. As
+// the stored presence is locked while checking the local connections, updates
+// from multiple replicas are serialized and the latest local state always wins.
+// The timestamp is used by consumers in order to discard outdated presence
+// updates, as concurrent updates might be notified out of order.
+func (c *Controller) syncUserPresence(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	wasOnline, err := c.store.UserPresenceAndLockOrWait(ctx, tx, userID)
+	if err != nil {
+		return meh.Wrap(err, "user presence from store and lock or wait", nil)
+	}
+	now := time.Now()
+	isConnectedLocally := c.isUserConnectedLocally(userID)
+	if isConnectedLocally {
+		err = c.store.AddUserConnection(ctx, tx, userID, c.replicaID, now)
+	} else {
+		err = c.store.RemoveUserConnection(ctx, tx, userID, c.replicaID)
+	}
+	if err != nil {
+		return meh.Wrap(err, "update user connection in store", meh.Details{"is_connected_locally": isConnectedLocally})
+	}
+	isOnline, err := c.store.IsUserConnected(ctx, tx, userID, now.Add(-presenceReplicaTTL))
+	if err != nil {
+		return meh.Wrap(err, "check if user connected in store", nil)
+	}
+	if isOnline == wasOnline {
+		return nil
+	}
+	err = c.store.UpdateUserPresence(ctx, tx, userID, isOnline, now)
+	if err != nil {
+		return meh.Wrap(err, "update user presence in store", meh.Details{"is_online": isOnline})
+	}
+	err = c.notifier.NotifyUserPresenceUpdated(ctx, tx, userID, isOnline, now)
+	if err != nil {
+		return meh.Wrap(err, "notify user presence updated", meh.Details{"is_online": isOnline})
+	}
+	return nil
+}
+
+// RegisterPresenceReplica registers this replica for tracking connections. This
+// must be called before accepting any connections. Connections of replicas,
+// that are gone, are considered as soon as their heartbeat expires.
+func (c *Controller) RegisterPresenceReplica(ctx context.Context) error {
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.store.CreatePresenceReplica(ctx, tx, c.replicaID, time.Now())
+		if err != nil {
+			return meh.Wrap(err, "create presence replica in store", meh.Details{"replica_id": c.replicaID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// runPresenceHeartbeat calls heartbeatPresence in an interval of
+// presenceHeartbeatInterval until the given context is done.
+func (c *Controller) runPresenceHeartbeat(lifetime context.Context) error {
+	for {
+		select {
+		case <-lifetime.Done():
+			return nil
+		case <-time.After(presenceHeartbeatInterval):
+		}
+		err := c.heartbeatPresence(lifetime)
+		if err != nil {
+			mehlog.Log(c.logger, meh.Wrap(err, "heartbeat presence", nil))
+		}
+	}
+}
+
+// heartbeatPresence updates the heartbeat of this replica and removes dead
+// replicas. Users, that are not connected to any live replica anymore, are
+// published as being offline. If this replica was considered dead, it is
+// registered again along with all of its connections.
+func (c *Controller) heartbeatPresence(ctx context.Context) error {
+	var usersToSync []uuid.UUID
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		usersToSync = nil
+		now := time.Now()
+		err := c.store.UpdatePresenceReplicaHeartbeat(ctx, tx, c.replicaID, now)
+		if err != nil {
+			if meh.ErrorCode(err) != meh.ErrNotFound {
+				return meh.Wrap(err, "update presence replica heartbeat in store", meh.Details{"replica_id": c.replicaID})
+			}
+			// Our connections were removed, so we register again and sync all locally
+			// connected users.
+			err = c.store.CreatePresenceReplica(ctx, tx, c.replicaID, now)
+			if err != nil {
+				return meh.Wrap(err, "create presence replica in store", meh.Details{"replica_id": c.replicaID})
+			}
+			c.connectionsByUserMutex.RLock()
+			for userID, conns := range c.connectionsByUser {
+				if len(conns) > 0 {
+					usersToSync = append(usersToSync, userID)
+				}
+			}
+			c.connectionsByUserMutex.RUnlock()
+		}
+		err = c.store.DeleteDeadPresenceReplicas(ctx, tx, now.Add(-presenceReplicaTTL))
+		if err != nil {
+			return meh.Wrap(err, "delete dead presence replicas in store", nil)
+		}
+		offlineUsers, err := c.store.OnlineUsersWithoutConnections(ctx, tx, now.Add(-presenceReplicaTTL))
+		if err != nil {
+			return meh.Wrap(err, "online users without connections from store", nil)
+		}
+		usersToSync = append(usersToSync, offlineUsers...)
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	// Sync each user in its own transaction, as the presence of the user is
+	// locked.
+	for _, userID := range usersToSync {
+		err = pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+			return c.syncUserPresence(ctx, tx, userID)
+		})
+		if err != nil {
+			mehlog.Log(c.logger, meh.Wrap(err, "sync user presence", meh.Details{"user_id": userID}))
+			continue
+		}
+	}
+	return nil
+}
@@ -0,0 +1,467 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerSyncUserPresenceSuite tests Controller.syncUserPresence.
+type ControllerSyncUserPresenceSuite struct {
+	suite.Suite
+	ctrl         *ControllerMock
+	tx           *testutil.DBTx
+	sampleUserID uuid.UUID
+}
+
+func (suite *ControllerSyncUserPresenceSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleUserID = testutil.NewUUIDV4()
+}
+
+// connectLocally adds a connection for the sample user to the controller.
+func (suite *ControllerSyncUserPresenceSuite) connectLocally() {
+	suite.ctrl.Ctrl.connectionsByUser[suite.sampleUserID] = []Connection{NewConnectionMock()}
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestLockFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestAddConnectionFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.connectLocally()
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(false, nil)
+	suite.ctrl.Store.On("AddUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestRemoveConnectionFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(true, nil)
+	suite.ctrl.Store.On("RemoveUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestCheckConnectedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(true, nil)
+	suite.ctrl.Store.On("RemoveUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestConnectedToOtherReplica() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(true, nil)
+	suite.ctrl.Store.On("RemoveUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Notifier.AssertNotCalled(suite.T(), "NotifyUserPresenceUpdated",
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestUpdatePresenceFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.connectLocally()
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(false, nil)
+	suite.ctrl.Store.On("AddUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(true, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.tx, suite.sampleUserID, true, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.connectLocally()
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(false, nil)
+	suite.ctrl.Store.On("AddUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(true, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.tx, suite.sampleUserID, true, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", timeout, suite.tx, suite.sampleUserID, true, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestWentOnline() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.connectLocally()
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(false, nil)
+	suite.ctrl.Store.On("AddUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(true, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.tx, suite.sampleUserID, true, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", timeout, suite.tx, suite.sampleUserID, true, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerSyncUserPresenceSuite) TestWentOffline() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.tx, suite.sampleUserID).
+		Return(true, nil)
+	suite.ctrl.Store.On("RemoveUserConnection", timeout, suite.tx, suite.sampleUserID, suite.ctrl.Ctrl.replicaID).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.tx, suite.sampleUserID, mock.Anything).
+		Return(false, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.tx, suite.sampleUserID, false, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", timeout, suite.tx, suite.sampleUserID, false, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.syncUserPresence(timeout, suite.tx, suite.sampleUserID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_syncUserPresence(t *testing.T) {
+	suite.Run(t, new(ControllerSyncUserPresenceSuite))
+}
+
+// ControllerRegisterPresenceReplicaSuite tests
+// Controller.RegisterPresenceReplica.
+type ControllerRegisterPresenceReplicaSuite struct {
+	suite.Suite
+	ctrl *ControllerMock
+	tx   *testutil.DBTx
+}
+
+func (suite *ControllerRegisterPresenceReplicaSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+}
+
+func (suite *ControllerRegisterPresenceReplicaSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RegisterPresenceReplica(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRegisterPresenceReplicaSuite) TestCreateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("CreatePresenceReplica", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RegisterPresenceReplica(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRegisterPresenceReplicaSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("CreatePresenceReplica", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.RegisterPresenceReplica(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_RegisterPresenceReplica(t *testing.T) {
+	suite.Run(t, new(ControllerRegisterPresenceReplicaSuite))
+}
+
+// ControllerHeartbeatPresenceSuite tests Controller.heartbeatPresence.
+type ControllerHeartbeatPresenceSuite struct {
+	suite.Suite
+	ctrl              *ControllerMock
+	tx                *testutil.DBTx
+	syncTx            *testutil.DBTx
+	sampleOfflineUser uuid.UUID
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.syncTx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx, suite.syncTx}
+	suite.sampleOfflineUser = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestUpdateHeartbeatFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestCreateReplicaFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(meh.NewNotFoundErr("sad life", nil))
+	suite.ctrl.Store.On("CreatePresenceReplica", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestDeleteDeadReplicasFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("DeleteDeadPresenceReplicas", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestRetrieveOfflineUsersFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("DeleteDeadPresenceReplicas", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("OnlineUsersWithoutConnections", timeout, suite.tx, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestSyncFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("DeleteDeadPresenceReplicas", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("OnlineUsersWithoutConnections", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{suite.sampleOfflineUser}, nil)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.syncTx, suite.sampleOfflineUser).
+		Return(false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.False(suite.syncTx.IsCommitted, "should not commit sync tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestReplicaRemoved() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	connectedUser := testutil.NewUUIDV4()
+	suite.ctrl.Ctrl.connectionsByUser[connectedUser] = []Connection{NewConnectionMock()}
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(meh.NewNotFoundErr("sad life", nil))
+	suite.ctrl.Store.On("CreatePresenceReplica", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("DeleteDeadPresenceReplicas", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("OnlineUsersWithoutConnections", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.syncTx, connectedUser).
+		Return(false, nil)
+	suite.ctrl.Store.On("AddUserConnection", timeout, suite.syncTx, connectedUser, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.syncTx, connectedUser, mock.Anything).
+		Return(true, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.syncTx, connectedUser, true, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", timeout, suite.syncTx, connectedUser, true, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.True(suite.syncTx.IsCommitted, "should commit sync tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerHeartbeatPresenceSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdatePresenceReplicaHeartbeat", timeout, suite.tx, suite.ctrl.Ctrl.replicaID, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("DeleteDeadPresenceReplicas", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("OnlineUsersWithoutConnections", timeout, suite.tx, mock.Anything).
+		Return([]uuid.UUID{suite.sampleOfflineUser}, nil)
+	suite.ctrl.Store.On("UserPresenceAndLockOrWait", timeout, suite.syncTx, suite.sampleOfflineUser).
+		Return(true, nil)
+	suite.ctrl.Store.On("RemoveUserConnection", timeout, suite.syncTx, suite.sampleOfflineUser, suite.ctrl.Ctrl.replicaID).
+		Return(nil)
+	suite.ctrl.Store.On("IsUserConnected", timeout, suite.syncTx, suite.sampleOfflineUser, mock.Anything).
+		Return(false, nil)
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, suite.syncTx, suite.sampleOfflineUser, false, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyUserPresenceUpdated", timeout, suite.syncTx, suite.sampleOfflineUser, false, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.heartbeatPresence(timeout)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.True(suite.syncTx.IsCommitted, "should commit sync tx")
+	}()
+
+	wait()
+}
+
+func TestController_heartbeatPresence(t *testing.T) {
+	suite.Run(t, new(ControllerHeartbeatPresenceSuite))
+}
@@ -5,15 +5,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/wsutil"
 	"go.uber.org/zap"
 )
 
+// Store are the handle dependencies.
+type Store interface {
+	handleAcknowledgeIntelNotificationStore
+	handleGetNotificationsStore
+	handleMarkNotificationAsReadStore
+}
+
 // Serve the endpoints via HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, wsHub wsutil.Hub) error {
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, s Store, wsHub wsutil.Hub,
+	deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	r := httpendpoints.NewEngine(logger)
-	populateRoutes(r, logger, authSecret, wsHub)
+	populateRoutes(r, logger, authSecret, s, wsHub)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, r, addr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
@@ -21,6 +31,9 @@ func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret
 	return nil
 }
 
-func populateRoutes(r *gin.Engine, logger *zap.Logger, secret string, wsHub wsutil.Hub) {
+func populateRoutes(r *gin.Engine, logger *zap.Logger, secret string, s Store, wsHub wsutil.Hub) {
 	r.GET("/ws", httpendpoints.GinHandlerFunc(logger, secret, wsHub.UpgradeHandler()))
+	r.GET("/notifications", httpendpoints.GinHandlerFunc(logger, secret, handleGetNotifications(s)))
+	r.POST("/notifications/:entryID/read", httpendpoints.GinHandlerFunc(logger, secret, handleMarkNotificationAsRead(s)))
+	r.POST("/:attemptID/ack", httpendpoints.GinHandlerFunc(logger, secret, handleAcknowledgeIntelNotification(s)))
 }
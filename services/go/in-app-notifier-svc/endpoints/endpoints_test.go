@@ -0,0 +1,42 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+)
+
+// StoreMock mocks Store.
+type StoreMock struct {
+	mock.Mock
+}
+
+func (m *StoreMock) AcknowledgeIntelNotification(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, attemptID, by).Error(0)
+}
+
+func (m *StoreMock) InboxByUser(ctx context.Context, userID uuid.UUID, filters store.InboxEntryFilters,
+	page pagination.Params) (pagination.Paginated[store.OutgoingIntelDeliveryNotification], error) {
+	args := m.Called(ctx, userID, filters, page)
+	return args.Get(0).(pagination.Paginated[store.OutgoingIntelDeliveryNotification]), args.Error(1)
+}
+
+func (m *StoreMock) MarkInboxEntryAsRead(ctx context.Context, entryID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, entryID, by).Error(0)
+}
+
+type wsHubStub struct {
+}
+
+func (m *wsHubStub) UpgradeHandler() httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		c.Status(http.StatusTeapot)
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"net/http"
+)
+
+// handleAcknowledgeIntelNotificationStore are the dependencies needed for
+// handleAcknowledgeIntelNotification.
+type handleAcknowledgeIntelNotificationStore interface {
+	AcknowledgeIntelNotification(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error
+}
+
+// handleAcknowledgeIntelNotification acknowledges the intel-notification for the
+// given attempt for the requesting user. This is the fallback for clients, not
+// being able to send acknowledgements via WebSocket.
+func handleAcknowledgeIntelNotification(s handleAcknowledgeIntelNotificationStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract attempt id.
+		attemptIDStr := c.Param("attemptID")
+		attemptID, err := uuid.FromString(attemptIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
+		}
+		// Acknowledge.
+		err = s.AcknowledgeIntelNotification(c.Request.Context(), attemptID, token.UserID)
+		if err != nil {
+			return meh.Wrap(err, "acknowledge intel-notification", meh.Details{
+				"attempt_id": attemptID,
+				"by":         token.UserID,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+)
+
+// handleAcknowledgeIntelNotificationSuite tests
+// handleAcknowledgeIntelNotification.
+type handleAcknowledgeIntelNotificationSuite struct {
+	suite.Suite
+	s               *StoreMock
+	r               *gin.Engine
+	sampleToken     auth.Token
+	sampleAttemptID uuid.UUID
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "summer",
+		IsAuthenticated: true,
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/ack", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/ack", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/ack",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestNotAssigned() {
+	suite.s.On("AcknowledgeIntelNotification", mock.Anything, suite.sampleAttemptID, suite.sampleToken.UserID).
+		Return(meh.NewForbiddenErr("sad life", nil)).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/ack", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestAcknowledgeFail() {
+	suite.s.On("AcknowledgeIntelNotification", mock.Anything, suite.sampleAttemptID, suite.sampleToken.UserID).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/ack", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleAcknowledgeIntelNotificationSuite) TestOK() {
+	suite.s.On("AcknowledgeIntelNotification", mock.Anything, suite.sampleAttemptID, suite.sampleToken.UserID).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/ack", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleAcknowledgeIntelNotification(t *testing.T) {
+	suite.Run(t, new(handleAcknowledgeIntelNotificationSuite))
+}
@@ -0,0 +1,251 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// publicIntelToDeliver is the public representation of store.IntelToDeliver.
+type publicIntelToDeliver struct {
+	Attempt    uuid.UUID       `json:"attempt"`
+	ID         uuid.UUID       `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	CreatedBy  uuid.UUID       `json:"created_by"`
+	Operation  uuid.UUID       `json:"operation"`
+	Type       string          `json:"type"`
+	Content    json.RawMessage `json:"content"`
+	Importance int             `json:"importance"`
+}
+
+// publicIntelToDeliverFromStore converts store.IntelToDeliver to
+// publicIntelToDeliver.
+func publicIntelToDeliverFromStore(s store.IntelToDeliver) publicIntelToDeliver {
+	return publicIntelToDeliver{
+		Attempt:    s.Attempt,
+		ID:         s.ID,
+		CreatedAt:  s.CreatedAt,
+		CreatedBy:  s.CreatedBy,
+		Operation:  s.Operation,
+		Type:       string(s.Type),
+		Content:    s.Content,
+		Importance: s.Importance,
+	}
+}
+
+// publicIntelDeliveryAttempt is the public representation of
+// store.AcceptedIntelDeliveryAttempt.
+type publicIntelDeliveryAttempt struct {
+	ID              uuid.UUID     `json:"id"`
+	AssignedTo      uuid.UUID     `json:"assigned_to"`
+	AssignedToLabel string        `json:"assigned_to_label"`
+	AssignedToUser  uuid.NullUUID `json:"assigned_to_user"`
+	Delivery        uuid.UUID     `json:"delivery"`
+	Channel         uuid.UUID     `json:"channel"`
+	CreatedAt       time.Time     `json:"created_at"`
+	IsActive        bool          `json:"is_active"`
+	StatusTS        time.Time     `json:"status_ts"`
+	Note            nulls.String  `json:"note"`
+	AcceptedAt      time.Time     `json:"accepted_at"`
+}
+
+// publicIntelDeliveryAttemptFromStore converts store.AcceptedIntelDeliveryAttempt
+// to publicIntelDeliveryAttempt.
+func publicIntelDeliveryAttemptFromStore(s store.AcceptedIntelDeliveryAttempt) publicIntelDeliveryAttempt {
+	return publicIntelDeliveryAttempt{
+		ID:              s.ID,
+		AssignedTo:      s.AssignedTo,
+		AssignedToLabel: s.AssignedToLabel,
+		AssignedToUser:  s.AssignedToUser,
+		Delivery:        s.Delivery,
+		Channel:         s.Channel,
+		CreatedAt:       s.CreatedAt,
+		IsActive:        s.IsActive,
+		StatusTS:        s.StatusTS,
+		Note:            s.Note,
+		AcceptedAt:      s.AcceptedAt,
+	}
+}
+
+// publicNotificationChannel is the public representation of
+// store.NotificationChannel.
+type publicNotificationChannel struct {
+	ID      uuid.UUID     `json:"id"`
+	Entry   uuid.UUID     `json:"entry"`
+	Label   string        `json:"label"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+// publicNotificationChannelFromStore converts store.NotificationChannel to
+// publicNotificationChannel.
+func publicNotificationChannelFromStore(s store.NotificationChannel) publicNotificationChannel {
+	return publicNotificationChannel{
+		ID:      s.ID,
+		Entry:   s.Entry,
+		Label:   s.Label,
+		Timeout: s.Timeout,
+	}
+}
+
+// publicUser is the public representation of store.User.
+type publicUser struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	IsActive  bool      `json:"is_active"`
+}
+
+// publicUserFromStore converts store.User to publicUser.
+func publicUserFromStore(s store.User) publicUser {
+	return publicUser{
+		ID:        s.ID,
+		Username:  s.Username,
+		FirstName: s.FirstName,
+		LastName:  s.LastName,
+		IsActive:  s.IsActive,
+	}
+}
+
+// publicInboxEntry is the public representation of store.InboxEntry.
+type publicInboxEntry struct {
+	ID        uuid.UUID  `json:"id"`
+	Seq       int64      `json:"seq"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    nulls.Time `json:"read_at"`
+}
+
+// publicInboxEntryFromStore converts store.InboxEntry to publicInboxEntry.
+func publicInboxEntryFromStore(s store.InboxEntry) publicInboxEntry {
+	return publicInboxEntry{
+		ID:        s.ID,
+		Seq:       s.Seq,
+		CreatedAt: s.CreatedAt,
+		ReadAt:    s.ReadAt,
+	}
+}
+
+// publicIntelDeliveryNotification is the public representation of
+// store.OutgoingIntelDeliveryNotification.
+type publicIntelDeliveryNotification struct {
+	IntelToDeliver   publicIntelToDeliver                 `json:"intel_to_deliver"`
+	DeliveryAttempt  publicIntelDeliveryAttempt           `json:"delivery_attempt"`
+	Channel          publicNotificationChannel            `json:"channel"`
+	CreatorDetails   publicUser                           `json:"creator_details"`
+	RecipientDetails nulls.JSONNullable[publicUser]       `json:"recipient_details"`
+	InboxEntry       nulls.JSONNullable[publicInboxEntry] `json:"inbox_entry"`
+}
+
+// publicIntelDeliveryNotificationFromStore converts
+// store.OutgoingIntelDeliveryNotification to publicIntelDeliveryNotification.
+func publicIntelDeliveryNotificationFromStore(s store.OutgoingIntelDeliveryNotification) publicIntelDeliveryNotification {
+	n := publicIntelDeliveryNotification{
+		IntelToDeliver:  publicIntelToDeliverFromStore(s.IntelToDeliver),
+		DeliveryAttempt: publicIntelDeliveryAttemptFromStore(s.DeliveryAttempt),
+		Channel:         publicNotificationChannelFromStore(s.Channel),
+		CreatorDetails:  publicUserFromStore(s.CreatorDetails),
+	}
+	if s.RecipientDetails.Valid {
+		n.RecipientDetails = nulls.NewJSONNullable(publicUserFromStore(s.RecipientDetails.V))
+	}
+	if s.InboxEntry.Valid {
+		n.InboxEntry = nulls.NewJSONNullable(publicInboxEntryFromStore(s.InboxEntry.V))
+	}
+	return n
+}
+
+// inboxEntryFiltersFromRequest extracts store.InboxEntryFilters from the given
+// query.
+func inboxEntryFiltersFromRequest(q url.Values) (store.InboxEntryFilters, error) {
+	var filters store.InboxEntryFilters
+	// By read.
+	if v := q.Get("by_read"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return store.InboxEntryFilters{}, meh.NewBadInputErrFromErr(err, "parse by-read", meh.Details{"was": v})
+		}
+		filters.ByRead = nulls.NewBool(b)
+	}
+	return filters, nil
+}
+
+// handleGetNotificationsStore are the dependencies needed for
+// handleGetNotifications.
+type handleGetNotificationsStore interface {
+	InboxByUser(ctx context.Context, userID uuid.UUID, filters store.InboxEntryFilters,
+		page pagination.Params) (pagination.Paginated[store.OutgoingIntelDeliveryNotification], error)
+}
+
+// handleGetNotifications retrieves a paginated list of notifications from the
+// inbox of the requesting user with optional filtering.
+func handleGetNotifications(s handleGetNotificationsStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Parse filters.
+		filters, err := inboxEntryFiltersFromRequest(c.Request.URL.Query())
+		if err != nil {
+			return meh.Wrap(err, "inbox entry filters from query", nil)
+		}
+		// Parse pagination params.
+		paginationParams, err := pagination.ParamsFromRequest(c)
+		if err != nil {
+			return meh.Wrap(err, "pagination params from request", nil)
+		}
+		// Retrieve.
+		sNotifs, err := s.InboxByUser(c.Request.Context(), token.UserID, filters, paginationParams)
+		if err != nil {
+			return meh.Wrap(err, "inbox by user", meh.Details{
+				"user_id": token.UserID,
+				"filters": filters,
+				"params":  paginationParams,
+			})
+		}
+		c.JSON(http.StatusOK, pagination.MapPaginated(sNotifs, publicIntelDeliveryNotificationFromStore))
+		return nil
+	}
+}
+
+// handleMarkNotificationAsReadStore are the dependencies needed for
+// handleMarkNotificationAsRead.
+type handleMarkNotificationAsReadStore interface {
+	MarkInboxEntryAsRead(ctx context.Context, entryID uuid.UUID, by uuid.UUID) error
+}
+
+// handleMarkNotificationAsRead marks the inbox entry with the given id of the
+// requesting user as read.
+func handleMarkNotificationAsRead(s handleMarkNotificationAsReadStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract entry id.
+		entryIDStr := c.Param("entryID")
+		entryID, err := uuid.FromString(entryIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse entry id", meh.Details{"was": entryIDStr})
+		}
+		// Mark as read.
+		err = s.MarkInboxEntryAsRead(c.Request.Context(), entryID, token.UserID)
+		if err != nil {
+			return meh.Wrap(err, "mark inbox entry as read", meh.Details{
+				"entry_id": entryID,
+				"by":       token.UserID,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
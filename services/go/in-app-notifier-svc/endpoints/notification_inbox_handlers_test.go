@@ -0,0 +1,332 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// handleGetNotificationsSuite tests handleGetNotifications.
+type handleGetNotificationsSuite struct {
+	suite.Suite
+	s                      *StoreMock
+	r                      *gin.Engine
+	sampleToken            auth.Token
+	sampleFilters          store.InboxEntryFilters
+	samplePaginationParams pagination.Params
+	sampleStoreNotifs      pagination.Paginated[store.OutgoingIntelDeliveryNotification]
+	samplePublicNotifs     pagination.Paginated[publicIntelDeliveryNotification]
+}
+
+func (suite *handleGetNotificationsSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "notice",
+		IsAuthenticated: true,
+	}
+	suite.sampleFilters = store.InboxEntryFilters{
+		ByRead: nulls.NewBool(false),
+	}
+	suite.samplePaginationParams = pagination.Params{
+		Limit:          12,
+		Offset:         3,
+		OrderDirection: pagination.OrderDirAsc,
+	}
+	attemptID := testutil.NewUUIDV4()
+	sampleStoreNotif := store.OutgoingIntelDeliveryNotification{
+		IntelToDeliver: store.IntelToDeliver{
+			Attempt:    attemptID,
+			ID:         testutil.NewUUIDV4(),
+			CreatedAt:  time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC),
+			CreatedBy:  testutil.NewUUIDV4(),
+			Operation:  testutil.NewUUIDV4(),
+			Type:       "plaintext-message",
+			Content:    json.RawMessage(`{"text":"hello"}`),
+			Importance: 200,
+		},
+		DeliveryAttempt: store.AcceptedIntelDeliveryAttempt{
+			ID:              attemptID,
+			AssignedTo:      testutil.NewUUIDV4(),
+			AssignedToLabel: "cross",
+			AssignedToUser:  nulls.NewUUID(suite.sampleToken.UserID),
+			Delivery:        testutil.NewUUIDV4(),
+			Channel:         testutil.NewUUIDV4(),
+			CreatedAt:       time.Date(2022, 9, 1, 10, 0, 1, 0, time.UTC),
+			IsActive:        true,
+			StatusTS:        time.Date(2022, 9, 1, 10, 0, 2, 0, time.UTC),
+			Note:            nulls.NewString("weak"),
+			AcceptedAt:      time.Date(2022, 9, 1, 10, 0, 3, 0, time.UTC),
+		},
+		Channel: store.NotificationChannel{
+			ID:      testutil.NewUUIDV4(),
+			Entry:   testutil.NewUUIDV4(),
+			Label:   "heat",
+			Timeout: 5 * time.Minute,
+		},
+		CreatorDetails: store.User{
+			ID:        testutil.NewUUIDV4(),
+			Username:  "sail",
+			FirstName: "Pipe",
+			LastName:  "Stone",
+			IsActive:  true,
+		},
+		InboxEntry: nulls.NewJSONNullable(store.InboxEntry{
+			ID:        testutil.NewUUIDV4(),
+			Seq:       42,
+			User:      suite.sampleToken.UserID,
+			Attempt:   attemptID,
+			CreatedAt: time.Date(2022, 9, 1, 10, 0, 3, 0, time.UTC),
+		}),
+	}
+	suite.sampleStoreNotifs = pagination.NewPaginated(suite.samplePaginationParams,
+		[]store.OutgoingIntelDeliveryNotification{sampleStoreNotif}, 4)
+	suite.samplePublicNotifs = pagination.NewPaginated(suite.samplePaginationParams,
+		[]publicIntelDeliveryNotification{
+			{
+				IntelToDeliver: publicIntelToDeliver{
+					Attempt:    sampleStoreNotif.IntelToDeliver.Attempt,
+					ID:         sampleStoreNotif.IntelToDeliver.ID,
+					CreatedAt:  sampleStoreNotif.IntelToDeliver.CreatedAt,
+					CreatedBy:  sampleStoreNotif.IntelToDeliver.CreatedBy,
+					Operation:  sampleStoreNotif.IntelToDeliver.Operation,
+					Type:       "plaintext-message",
+					Content:    json.RawMessage(`{"text":"hello"}`),
+					Importance: 200,
+				},
+				DeliveryAttempt: publicIntelDeliveryAttempt{
+					ID:              attemptID,
+					AssignedTo:      sampleStoreNotif.DeliveryAttempt.AssignedTo,
+					AssignedToLabel: "cross",
+					AssignedToUser:  nulls.NewUUID(suite.sampleToken.UserID),
+					Delivery:        sampleStoreNotif.DeliveryAttempt.Delivery,
+					Channel:         sampleStoreNotif.DeliveryAttempt.Channel,
+					CreatedAt:       sampleStoreNotif.DeliveryAttempt.CreatedAt,
+					IsActive:        true,
+					StatusTS:        sampleStoreNotif.DeliveryAttempt.StatusTS,
+					Note:            nulls.NewString("weak"),
+					AcceptedAt:      sampleStoreNotif.DeliveryAttempt.AcceptedAt,
+				},
+				Channel: publicNotificationChannel{
+					ID:      sampleStoreNotif.Channel.ID,
+					Entry:   sampleStoreNotif.Channel.Entry,
+					Label:   "heat",
+					Timeout: 5 * time.Minute,
+				},
+				CreatorDetails: publicUser{
+					ID:        sampleStoreNotif.CreatorDetails.ID,
+					Username:  "sail",
+					FirstName: "Pipe",
+					LastName:  "Stone",
+					IsActive:  true,
+				},
+				InboxEntry: nulls.NewJSONNullable(publicInboxEntry{
+					ID:        sampleStoreNotif.InboxEntry.V.ID,
+					Seq:       42,
+					CreatedAt: sampleStoreNotif.InboxEntry.V.CreatedAt,
+				}),
+			},
+		}, 4)
+}
+
+func (suite *handleGetNotificationsSuite) url() string {
+	return fmt.Sprintf("/notifications?by_read=%t&%s", suite.sampleFilters.ByRead.Bool,
+		pagination.ParamsToQueryString(suite.samplePaginationParams))
+}
+
+func (suite *handleGetNotificationsSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    suite.url(),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNotificationsSuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    suite.url(),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNotificationsSuite) TestInvalidByReadFilter() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/notifications?by_read=abc",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNotificationsSuite) TestInvalidPaginationParams() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/notifications?limit=abc",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNotificationsSuite) TestRetrieveFail() {
+	suite.s.On("InboxByUser", mock.Anything, suite.sampleToken.UserID, suite.sampleFilters, suite.samplePaginationParams).
+		Return(pagination.Paginated[store.OutgoingIntelDeliveryNotification]{}, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    suite.url(),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNotificationsSuite) TestOK() {
+	suite.s.On("InboxByUser", mock.Anything, suite.sampleToken.UserID, suite.sampleFilters, suite.samplePaginationParams).
+		Return(suite.sampleStoreNotifs, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    suite.url(),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got pagination.Paginated[publicIntelDeliveryNotification]
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicNotifs, got, "should return correct body")
+}
+
+func Test_handleGetNotifications(t *testing.T) {
+	suite.Run(t, new(handleGetNotificationsSuite))
+}
+
+// handleMarkNotificationAsReadSuite tests handleMarkNotificationAsRead.
+type handleMarkNotificationAsReadSuite struct {
+	suite.Suite
+	s             *StoreMock
+	r             *gin.Engine
+	sampleToken   auth.Token
+	sampleEntryID uuid.UUID
+}
+
+func (suite *handleMarkNotificationAsReadSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "mention",
+		IsAuthenticated: true,
+	}
+	suite.sampleEntryID = testutil.NewUUIDV4()
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/notifications/%s/read", suite.sampleEntryID),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/notifications/%s/read", suite.sampleEntryID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestInvalidEntryID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/notifications/abc/read",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestForeignEntry() {
+	suite.s.On("MarkInboxEntryAsRead", mock.Anything, suite.sampleEntryID, suite.sampleToken.UserID).
+		Return(meh.NewForbiddenErr("sad life", nil)).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/notifications/%s/read", suite.sampleEntryID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestMarkFail() {
+	suite.s.On("MarkInboxEntryAsRead", mock.Anything, suite.sampleEntryID, suite.sampleToken.UserID).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/notifications/%s/read", suite.sampleEntryID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleMarkNotificationAsReadSuite) TestOK() {
+	suite.s.On("MarkInboxEntryAsRead", mock.Anything, suite.sampleEntryID, suite.sampleToken.UserID).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/notifications/%s/read", suite.sampleEntryID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleMarkNotificationAsRead(t *testing.T) {
+	suite.Run(t, new(handleMarkNotificationAsReadSuite))
+}
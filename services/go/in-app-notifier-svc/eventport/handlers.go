@@ -9,6 +9,7 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"time"
 )
 
@@ -33,6 +34,15 @@ type Handler interface {
 	// for the associated intel-delivery-attempt. The attempt does not need to be
 	// accepted.
 	UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, newStatus store.AcceptedIntelDeliveryAttemptStatus) error
+	// NotifyIntelDeliveryEscalation notifies connected users, that are allowed to
+	// manage intel-deliveries, about the given store.IntelDeliveryEscalation.
+	NotifyIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation) error
+	// UpdatePermissionsByUser updates the permissions for the user with the given
+	// id.
+	UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []permission.Permission) error
+	// ExpireIntel sets all active accepted intel-delivery-attempts for the intel
+	// with the given id to inactive.
+	ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error
 }
 
 // HandlerFn for handling messages.
@@ -43,6 +53,10 @@ func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 			return meh.NilOrWrap(p.handleAddressBookTopic(ctx, tx, handler, message), "handle address book topic", nil)
 		case event.IntelDeliveriesTopic:
 			return meh.NilOrWrap(p.handleIntelDeliveriesTopic(ctx, tx, handler, message), "handle intel-deliveries topic", nil)
+		case event.IntelTopic:
+			return meh.NilOrWrap(p.handleIntelTopic(ctx, tx, handler, message), "handle intel topic", nil)
+		case event.PermissionsTopic:
+			return meh.NilOrWrap(p.handlePermissionsTopic(ctx, tx, handler, message), "handle permissions topic", nil)
 		case event.UsersTopic:
 			return meh.NilOrWrap(p.handleUsersTopic(ctx, tx, handler, message), "handle users topic", nil)
 		}
@@ -50,6 +64,32 @@ func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 	}
 }
 
+// handlePermissionsTopic handles the event.PermissionsTopic.
+func (p *Port) handlePermissionsTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypePermissionsUpdated:
+		return meh.NilOrWrap(p.handlePermissionsUpdated(ctx, tx, handler, message), "handle permissions updated", nil)
+	}
+	return nil
+}
+
+// handlePermissionsUpdated handles an event.TypePermissionsUpdated event.
+func (p *Port) handlePermissionsUpdated(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var permissionsUpdatedEvent event.PermissionsUpdated
+	err := json.Unmarshal(message.RawValue, &permissionsUpdatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdatePermissionsByUser(ctx, tx, permissionsUpdatedEvent.User, permissionsUpdatedEvent.Permissions)
+	if err != nil {
+		return meh.Wrap(err, "update permissions by user", meh.Details{
+			"user_id":     permissionsUpdatedEvent.User,
+			"permissions": permissionsUpdatedEvent.Permissions,
+		})
+	}
+	return nil
+}
+
 // handleUsersTopic handles the event.UsersTopic.
 func (p *Port) handleUsersTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
 	switch message.EventType {
@@ -173,6 +213,8 @@ func (p *Port) handleIntelDeliveriesTopic(ctx context.Context, tx pgx.Tx, handle
 		return meh.NilOrWrap(p.handleIntelDeliveryAttemptCreated(ctx, tx, handler, message), "handle intel-delivery-attempt created", nil)
 	case event.TypeIntelDeliveryAttemptStatusUpdated:
 		return meh.NilOrWrap(p.handleIntelDeliveryAttemptStatusUpdated(ctx, tx, handler, message), "handle intel-delivery-attempt-status updated", nil)
+	case event.TypeIntelDeliveryEscalated:
+		return meh.NilOrWrap(p.handleIntelDeliveryEscalated(ctx, tx, handler, message), "handle intel-delivery escalated", nil)
 	}
 	return nil
 }
@@ -232,3 +274,70 @@ func (p *Port) handleIntelDeliveryAttemptStatusUpdated(ctx context.Context, tx p
 	}
 	return nil
 }
+
+// handleIntelDeliveryEscalated handles an event.TypeIntelDeliveryEscalated.
+// Escalations without managers to notify are ignored.
+func (p *Port) handleIntelDeliveryEscalated(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var escalatedEvent event.IntelDeliveryEscalated
+	err := json.Unmarshal(message.RawValue, &escalatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	if !escalatedEvent.NotifyManagers {
+		return nil
+	}
+	trigger, err := mapEventIntelDeliveryEscalationTriggerToStore(escalatedEvent.Trigger)
+	if err != nil {
+		return meh.Wrap(err, "map intel-delivery-escalation-trigger", meh.Details{"trigger": escalatedEvent.Trigger})
+	}
+	escalation := store.IntelDeliveryEscalation{
+		ID:               escalatedEvent.ID,
+		Delivery:         escalatedEvent.Delivery,
+		Intel:            escalatedEvent.Intel,
+		Operation:        escalatedEvent.Operation,
+		Trigger:          trigger,
+		EscalatedAt:      escalatedEvent.EscalatedAt,
+		EscalatedTo:      escalatedEvent.EscalatedTo,
+		EscalatedToEntry: escalatedEvent.EscalatedToEntry,
+	}
+	err = handler.NotifyIntelDeliveryEscalation(ctx, tx, escalation)
+	if err != nil {
+		return meh.Wrap(err, "notify intel-delivery-escalation", meh.Details{"escalation": escalation})
+	}
+	return nil
+}
+
+// mapEventIntelDeliveryEscalationTriggerToStore maps
+// event.IntelDeliveryEscalationTrigger to store.IntelDeliveryEscalationTrigger.
+func mapEventIntelDeliveryEscalationTriggerToStore(e event.IntelDeliveryEscalationTrigger) (store.IntelDeliveryEscalationTrigger, error) {
+	switch e {
+	case event.IntelDeliveryEscalationTriggerFailed:
+		return store.IntelDeliveryEscalationTriggerFailed, nil
+	case event.IntelDeliveryEscalationTriggerOpenTimeout:
+		return store.IntelDeliveryEscalationTriggerOpenTimeout, nil
+	}
+	return "", meh.NewInternalErr("unsupported trigger", meh.Details{"trigger": e})
+}
+
+// handleIntelTopic handles the event.IntelTopic.
+func (p *Port) handleIntelTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeIntelExpired:
+		return meh.NilOrWrap(p.handleIntelExpired(ctx, tx, handler, message), "handle intel expired", nil)
+	}
+	return nil
+}
+
+// handleIntelExpired handles an event.TypeIntelExpired event.
+func (p *Port) handleIntelExpired(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var intelExpiredEvent event.IntelExpired
+	err := json.Unmarshal(message.RawValue, &intelExpiredEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.ExpireIntel(ctx, tx, intelExpiredEvent.ID)
+	if err != nil {
+		return meh.Wrap(err, "expire intel", meh.Details{"intel_id": intelExpiredEvent.ID})
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -49,6 +50,18 @@ func (m *HandlerMock) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx p
 	return m.Called(ctx, tx, newStatus).Error(0)
 }
 
+func (m *HandlerMock) ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	return m.Called(ctx, tx, intelID).Error(0)
+}
+
+func (m *HandlerMock) NotifyIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation) error {
+	return m.Called(ctx, tx, escalation).Error(0)
+}
+
+func (m *HandlerMock) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []permission.Permission) error {
+	return m.Called(ctx, tx, userID, permissions).Error(0)
+}
+
 // portHandleUserCreatedSuite tests Port.handleUserCreated.
 type portHandleUserCreatedSuite struct {
 	suite.Suite
@@ -628,3 +641,272 @@ func (suite *portHandleIntelDeliveryAttemptCreatedSuite) TestOK() {
 func TestPort_handleIntelDeliveryAttemptCreated(t *testing.T) {
 	suite.Run(t, new(portHandleIntelDeliveryAttemptCreatedSuite))
 }
+
+// portHandlePermissionsUpdatedSuite tests Port.handlePermissionsUpdated.
+type portHandlePermissionsUpdatedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.PermissionsUpdated
+}
+
+func (suite *portHandlePermissionsUpdatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.PermissionsUpdated{
+		User: testutil.NewUUIDV4(),
+		Permissions: []permission.Permission{
+			{Name: permission.ManageIntelDeliveryPermissionName},
+			{Name: permission.DeliverIntelPermissionName},
+		},
+	}
+}
+
+func (suite *portHandlePermissionsUpdatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.PermissionsTopic,
+		EventType: event.TypePermissionsUpdated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandlePermissionsUpdatedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePermissionsUpdatedSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdatePermissionsByUser", timeout, tx, suite.sampleEvent.User, suite.sampleEvent.Permissions).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePermissionsUpdatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdatePermissionsByUser", timeout, tx, suite.sampleEvent.User, suite.sampleEvent.Permissions).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handlePermissionsUpdated(t *testing.T) {
+	suite.Run(t, new(portHandlePermissionsUpdatedSuite))
+}
+
+// portHandleIntelDeliveryEscalatedSuite tests Port.handleIntelDeliveryEscalated.
+type portHandleIntelDeliveryEscalatedSuite struct {
+	suite.Suite
+	handler          *HandlerMock
+	port             *PortMock
+	sampleEvent      event.IntelDeliveryEscalated
+	sampleEscalation store.IntelDeliveryEscalation
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.IntelDeliveryEscalated{
+		ID:               testutil.NewUUIDV4(),
+		Delivery:         testutil.NewUUIDV4(),
+		Intel:            testutil.NewUUIDV4(),
+		Operation:        testutil.NewUUIDV4(),
+		Trigger:          event.IntelDeliveryEscalationTriggerOpenTimeout,
+		EscalatedAt:      time.Date(2022, 9, 8, 1, 40, 12, 0, time.UTC),
+		EscalatedTo:      nulls.NewUUID(testutil.NewUUIDV4()),
+		EscalatedToEntry: nulls.NewUUID(testutil.NewUUIDV4()),
+		NotifyManagers:   true,
+	}
+	suite.sampleEscalation = store.IntelDeliveryEscalation{
+		ID:               suite.sampleEvent.ID,
+		Delivery:         suite.sampleEvent.Delivery,
+		Intel:            suite.sampleEvent.Intel,
+		Operation:        suite.sampleEvent.Operation,
+		Trigger:          store.IntelDeliveryEscalationTriggerOpenTimeout,
+		EscalatedAt:      suite.sampleEvent.EscalatedAt,
+		EscalatedTo:      suite.sampleEvent.EscalatedTo,
+		EscalatedToEntry: suite.sampleEvent.EscalatedToEntry,
+	}
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.IntelDeliveriesTopic,
+		EventType: event.TypeIntelDeliveryEscalated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) TestNoManagersToNotify() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.sampleEvent.NotifyManagers = false
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) TestUnknownTrigger() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.sampleEvent.Trigger = "unknown"
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("NotifyIntelDeliveryEscalation", timeout, tx, suite.sampleEscalation).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelDeliveryEscalatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("NotifyIntelDeliveryEscalation", timeout, tx, suite.sampleEscalation).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleIntelDeliveryEscalated(t *testing.T) {
+	suite.Run(t, new(portHandleIntelDeliveryEscalatedSuite))
+}
+
+// portHandleIntelExpiredSuite tests Port.handleIntelExpired.
+type portHandleIntelExpiredSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.IntelExpired
+}
+
+func (suite *portHandleIntelExpiredSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.IntelExpired{
+		ID: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *portHandleIntelExpiredSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.IntelTopic,
+		EventType: event.TypeIntelExpired,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleIntelExpiredSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelExpiredSuite) TestExpireFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("ExpireIntel", timeout, tx, suite.sampleEvent.ID).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelExpiredSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("ExpireIntel", timeout, tx, suite.sampleEvent.ID).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleIntelExpired(t *testing.T) {
+	suite.Run(t, new(portHandleIntelExpiredSuite))
+}
@@ -47,3 +47,64 @@ func (p *Port) NotifyIntelDeliveryNotificationPending(ctx context.Context, tx pg
 	}
 	return nil
 }
+
+// NotifyIntelDeliveryNotificationReceived emits an
+// event.TypeInAppNotificationForIntelReceived event.
+func (p *Port) NotifyIntelDeliveryNotificationReceived(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID,
+	receivedAt time.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.InAppNotificationsTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeInAppNotificationForIntelReceived,
+		Value: event.InAppNotificationForIntelReceived{
+			Attempt:    attemptID,
+			ReceivedBy: by,
+			ReceivedAt: receivedAt,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// NotifyIntelDeliveryNotificationAcknowledged emits an
+// event.TypeInAppNotificationForIntelAcknowledged event.
+func (p *Port) NotifyIntelDeliveryNotificationAcknowledged(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID,
+	acknowledgedAt time.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.InAppNotificationsTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypeInAppNotificationForIntelAcknowledged,
+		Value: event.InAppNotificationForIntelAcknowledged{
+			Attempt:        attemptID,
+			AcknowledgedBy: by,
+			AcknowledgedAt: acknowledgedAt,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// NotifyUserPresenceUpdated emits an event.TypeUserPresenceUpdated event.
+func (p *Port) NotifyUserPresenceUpdated(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, lastSeen time.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.UserPresenceTopic,
+		Key:       userID.String(),
+		EventType: event.TypeUserPresenceUpdated,
+		Value: event.UserPresenceUpdated{
+			User:     userID,
+			IsOnline: isOnline,
+			LastSeen: lastSeen,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
@@ -129,3 +129,189 @@ func (suite *PortNotifyIntelDeliveryNotificationPendingSuite) TestOK() {
 func TestPort_NotifyIntelDeliveryNotificationPending(t *testing.T) {
 	suite.Run(t, new(PortNotifyIntelDeliveryNotificationPendingSuite))
 }
+
+// PortNotifyIntelDeliveryNotificationReceivedSuite tests
+// Port.NotifyIntelDeliveryNotificationReceived.
+type PortNotifyIntelDeliveryNotificationReceivedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleAttempt    uuid.UUID
+	sampleBy         uuid.UUID
+	sampleReceivedAt time.Time
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationReceivedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = testutil.NewUUIDV4()
+	suite.sampleBy = testutil.NewUUIDV4()
+	suite.sampleReceivedAt = time.Date(2022, 9, 8, 0, 12, 19, 0, time.UTC)
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.InAppNotificationsTopic,
+			Key:       suite.sampleAttempt.String(),
+			EventType: event.TypeInAppNotificationForIntelReceived,
+			Value: event.InAppNotificationForIntelReceived{
+				Attempt:    suite.sampleAttempt,
+				ReceivedBy: suite.sampleBy,
+				ReceivedAt: suite.sampleReceivedAt,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationReceivedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryNotificationReceived(timeout, suite.tx, suite.sampleAttempt, suite.sampleBy, suite.sampleReceivedAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationReceivedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryNotificationReceived(timeout, suite.tx, suite.sampleAttempt, suite.sampleBy, suite.sampleReceivedAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelDeliveryNotificationReceived(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelDeliveryNotificationReceivedSuite))
+}
+
+// PortNotifyIntelDeliveryNotificationAcknowledgedSuite tests
+// Port.NotifyIntelDeliveryNotificationAcknowledged.
+type PortNotifyIntelDeliveryNotificationAcknowledgedSuite struct {
+	suite.Suite
+	port                 *PortMock
+	tx                   *testutil.DBTx
+	sampleAttempt        uuid.UUID
+	sampleBy             uuid.UUID
+	sampleAcknowledgedAt time.Time
+	expectedMessages     []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationAcknowledgedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = testutil.NewUUIDV4()
+	suite.sampleBy = testutil.NewUUIDV4()
+	suite.sampleAcknowledgedAt = time.Date(2022, 9, 8, 0, 14, 19, 0, time.UTC)
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.InAppNotificationsTopic,
+			Key:       suite.sampleAttempt.String(),
+			EventType: event.TypeInAppNotificationForIntelAcknowledged,
+			Value: event.InAppNotificationForIntelAcknowledged{
+				Attempt:        suite.sampleAttempt,
+				AcknowledgedBy: suite.sampleBy,
+				AcknowledgedAt: suite.sampleAcknowledgedAt,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationAcknowledgedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryNotificationAcknowledged(timeout, suite.tx, suite.sampleAttempt, suite.sampleBy, suite.sampleAcknowledgedAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelDeliveryNotificationAcknowledgedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryNotificationAcknowledged(timeout, suite.tx, suite.sampleAttempt, suite.sampleBy, suite.sampleAcknowledgedAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelDeliveryNotificationAcknowledged(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelDeliveryNotificationAcknowledgedSuite))
+}
+
+// PortNotifyUserPresenceUpdatedSuite tests Port.NotifyUserPresenceUpdated.
+type PortNotifyUserPresenceUpdatedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleUser       uuid.UUID
+	sampleLastSeen   time.Time
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyUserPresenceUpdatedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleUser = testutil.NewUUIDV4()
+	suite.sampleLastSeen = time.Date(2022, 9, 8, 0, 4, 19, 0, time.UTC)
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.UserPresenceTopic,
+			Key:       suite.sampleUser.String(),
+			EventType: event.TypeUserPresenceUpdated,
+			Value: event.UserPresenceUpdated{
+				User:     suite.sampleUser,
+				IsOnline: true,
+				LastSeen: suite.sampleLastSeen,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyUserPresenceUpdatedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyUserPresenceUpdated(timeout, suite.tx, suite.sampleUser, true, suite.sampleLastSeen)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyUserPresenceUpdatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyUserPresenceUpdated(timeout, suite.tx, suite.sampleUser, true, suite.sampleLastSeen)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyUserPresenceUpdated(t *testing.T) {
+	suite.Run(t, new(PortNotifyUserPresenceUpdatedSuite))
+}
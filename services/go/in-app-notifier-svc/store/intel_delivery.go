@@ -101,6 +101,74 @@ func (m *Mall) UpdateAcceptedIntelDeliveryAttemptStatus(ctx context.Context, tx
 	return nil
 }
 
+// AcceptedIntelDeliveryAttemptByID retrieves the AcceptedIntelDeliveryAttempt
+// with the given id.
+func (m *Mall) AcceptedIntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (AcceptedIntelDeliveryAttempt, error) {
+	q, _, err := m.dialect.From(goqu.T("accepted_intel_delivery_attempts")).
+		Select(goqu.C("id"),
+			goqu.C("assigned_to"),
+			goqu.C("assigned_to_label"),
+			goqu.C("assigned_to_user"),
+			goqu.C("delivery"),
+			goqu.C("channel"),
+			goqu.C("created_at"),
+			goqu.C("is_active"),
+			goqu.C("status_ts"),
+			goqu.C("note"),
+			goqu.C("accepted_at")).
+		Where(goqu.C("id").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return AcceptedIntelDeliveryAttempt{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return AcceptedIntelDeliveryAttempt{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return AcceptedIntelDeliveryAttempt{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var attempt AcceptedIntelDeliveryAttempt
+	err = rows.Scan(&attempt.ID,
+		&attempt.AssignedTo,
+		&attempt.AssignedToLabel,
+		&attempt.AssignedToUser,
+		&attempt.Delivery,
+		&attempt.Channel,
+		&attempt.CreatedAt,
+		&attempt.IsActive,
+		&attempt.StatusTS,
+		&attempt.Note,
+		&attempt.AcceptedAt)
+	if err != nil {
+		return AcceptedIntelDeliveryAttempt{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return attempt, nil
+}
+
+// DeactivateAcceptedIntelDeliveryAttemptsByIntel sets all active
+// AcceptedIntelDeliveryAttempt for the intel with the given id to inactive with
+// the given note.
+func (m *Mall) DeactivateAcceptedIntelDeliveryAttemptsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, note string) error {
+	q, _, err := m.dialect.Update(goqu.T("accepted_intel_delivery_attempts")).Set(goqu.Record{
+		"is_active": false,
+		"status_ts": time.Now().UTC(),
+		"note":      note,
+	}).Where(goqu.C("is_active").IsTrue(),
+		goqu.C("id").In(m.dialect.From(goqu.T("intel_to_deliver")).
+			Select(goqu.C("attempt")).
+			Where(goqu.C("id").Eq(intelID)))).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
 // CreateIntelNotificationHistoryEntry creates an entry in the history-table for
 // keeping log of sent notifications for attempts.
 func (m *Mall) CreateIntelNotificationHistoryEntry(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, ts time.Time) error {
@@ -133,6 +201,9 @@ type OutgoingIntelDeliveryNotification struct {
 	// RecipientDetails holds the user information for the optionally assigned
 	// recipient (from the address book entry).
 	RecipientDetails nulls.JSONNullable[User]
+	// InboxEntry is the optional InboxEntry for the recipient. It is not set for
+	// attempts without an assigned user.
+	InboxEntry nulls.JSONNullable[InboxEntry]
 }
 
 // OldestPendingAttemptToNotifyByUser retrieves the id of the oldest attempt
@@ -228,35 +299,87 @@ func (m *Mall) OutgoingNotificationByAttemptWithoutTriesAndLockOrSkip(ctx contex
 		return OutgoingIntelDeliveryNotification{}, mehpg.NewScanRowsErr(err, "scan row", attemptQuery)
 	}
 	rows.Close()
-	// Retrieve the intel.
-	notif.IntelToDeliver, err = m.IntelToDeliverByAttempt(ctx, tx, attemptID)
+	// Retrieve the channel.
+	notif.Channel, err = m.NotificationChannelByID(ctx, tx, notif.DeliveryAttempt.Channel)
 	if err != nil {
 		err = meh.ApplyCode(err, meh.ErrInternal)
-		err = meh.Wrap(err, "intel to deliver by attempt", meh.Details{"attempt_id": attemptID})
+		err = meh.Wrap(err, "notification channel by id", meh.Details{"channel_id": notif.DeliveryAttempt.Channel})
 		return OutgoingIntelDeliveryNotification{}, err
 	}
-	// Retrieve the channel.
+	err = m.completeOutgoingIntelDeliveryNotification(ctx, tx, &notif)
+	if err != nil {
+		return OutgoingIntelDeliveryNotification{}, meh.Wrap(err, "complete outgoing intel-delivery-notification", nil)
+	}
+	return notif, nil
+}
+
+// IntelDeliveryNotificationByAttempt retrieves the
+// OutgoingIntelDeliveryNotification for the attempt with the given id. In
+// contrast to OutgoingNotificationByAttemptWithoutTriesAndLockOrSkip, the
+// attempt is neither locked nor required to be active.
+func (m *Mall) IntelDeliveryNotificationByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (OutgoingIntelDeliveryNotification, error) {
+	var notif OutgoingIntelDeliveryNotification
+	var err error
+	notif.DeliveryAttempt, err = m.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		return OutgoingIntelDeliveryNotification{}, meh.Wrap(err, "accepted intel-delivery-attempt by id", meh.Details{"attempt_id": attemptID})
+	}
+	// Retrieve the channel. As the notification might be an old one, the channel
+	// might have been removed in the meantime.
 	notif.Channel, err = m.NotificationChannelByID(ctx, tx, notif.DeliveryAttempt.Channel)
+	if err != nil {
+		if meh.ErrorCode(err) != meh.ErrNotFound {
+			return OutgoingIntelDeliveryNotification{}, meh.Wrap(err, "notification channel by id",
+				meh.Details{"channel_id": notif.DeliveryAttempt.Channel})
+		}
+		notif.Channel = NotificationChannel{
+			ID:    notif.DeliveryAttempt.Channel,
+			Entry: notif.DeliveryAttempt.AssignedTo,
+		}
+	}
+	err = m.completeOutgoingIntelDeliveryNotification(ctx, tx, &notif)
+	if err != nil {
+		return OutgoingIntelDeliveryNotification{}, meh.Wrap(err, "complete outgoing intel-delivery-notification", nil)
+	}
+	return notif, nil
+}
+
+// completeOutgoingIntelDeliveryNotification retrieves all remaining details
+// for the given OutgoingIntelDeliveryNotification with its DeliveryAttempt and
+// Channel being already set.
+func (m *Mall) completeOutgoingIntelDeliveryNotification(ctx context.Context, tx pgx.Tx, notif *OutgoingIntelDeliveryNotification) error {
+	attemptID := notif.DeliveryAttempt.ID
+	var err error
+	// Retrieve the intel.
+	notif.IntelToDeliver, err = m.IntelToDeliverByAttempt(ctx, tx, attemptID)
 	if err != nil {
 		err = meh.ApplyCode(err, meh.ErrInternal)
-		err = meh.Wrap(err, "notification channel by id", meh.Details{"channel_id": notif.DeliveryAttempt.Channel})
-		return OutgoingIntelDeliveryNotification{}, err
+		err = meh.Wrap(err, "intel to deliver by attempt", meh.Details{"attempt_id": attemptID})
+		return err
 	}
 	// Retrieve user information.
 	notif.CreatorDetails, err = m.UserByID(ctx, tx, notif.IntelToDeliver.CreatedBy)
 	if err != nil {
 		err = meh.ApplyCode(err, meh.ErrInternal)
 		err = meh.Wrap(err, "retrieve user details for creator", meh.Details{"user_id": notif.IntelToDeliver.CreatedBy})
-		return OutgoingIntelDeliveryNotification{}, err
+		return err
 	}
 	if notif.DeliveryAttempt.AssignedToUser.Valid {
 		recipientDetails, err := m.UserByID(ctx, tx, notif.DeliveryAttempt.AssignedToUser.UUID)
 		if err != nil {
 			err = meh.ApplyCode(err, meh.ErrInternal)
 			err = meh.Wrap(err, "retrieve user details for recipient", meh.Details{"user_id": notif.DeliveryAttempt.AssignedTo})
-			return OutgoingIntelDeliveryNotification{}, err
+			return err
 		}
 		notif.RecipientDetails = nulls.NewJSONNullable(recipientDetails)
 	}
-	return notif, nil
+	// Retrieve the inbox entry.
+	inboxEntry, err := m.inboxEntryByAttempt(ctx, tx, attemptID)
+	if err != nil && meh.ErrorCode(err) != meh.ErrNotFound {
+		return meh.Wrap(err, "inbox entry by attempt", meh.Details{"attempt_id": attemptID})
+	}
+	if err == nil {
+		notif.InboxEntry = nulls.NewJSONNullable(inboxEntry)
+	}
+	return nil
 }
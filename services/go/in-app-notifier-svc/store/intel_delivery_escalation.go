@@ -0,0 +1,42 @@
+package store
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// IntelDeliveryEscalationTrigger is the reason for an intel-delivery being
+// escalated.
+type IntelDeliveryEscalationTrigger string
+
+const (
+	// IntelDeliveryEscalationTriggerFailed for deliveries that failed because of no
+	// more channels to try.
+	IntelDeliveryEscalationTriggerFailed IntelDeliveryEscalationTrigger = "failed"
+	// IntelDeliveryEscalationTriggerOpenTimeout for deliveries that are still open
+	// after the timeout of the escalation rule.
+	IntelDeliveryEscalationTriggerOpenTimeout IntelDeliveryEscalationTrigger = "open-timeout"
+)
+
+// IntelDeliveryEscalation is an escalation of an intel-delivery, that users
+// managing intel-deliveries are notified about. It is not persisted as it is
+// only sent to connected users.
+type IntelDeliveryEscalation struct {
+	// ID identifies the escalation.
+	ID uuid.UUID
+	// Delivery is the id of the escalated delivery.
+	Delivery uuid.UUID
+	// Intel is the id of the intel being delivered.
+	Intel uuid.UUID
+	// Operation is the id of the operation the intel is assigned to.
+	Operation uuid.UUID
+	// Trigger is the reason for the escalation.
+	Trigger IntelDeliveryEscalationTrigger
+	// EscalatedAt is the timestamp when the delivery was escalated.
+	EscalatedAt time.Time
+	// EscalatedTo is the id of the optional delivery that was created to the
+	// fallback address book entry.
+	EscalatedTo uuid.NullUUID
+	// EscalatedToEntry is the id of the optional fallback address book entry.
+	EscalatedToEntry uuid.NullUUID
+}
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"time"
+)
+
+// InboxEntry is an entry in the durable notification inbox of a user. It is
+// retained, even if the user is offline, so that notifications can be replayed
+// when the user reconnects.
+type InboxEntry struct {
+	// ID identifies the entry.
+	ID uuid.UUID
+	// Seq is the increasing sequence number of the entry, used as resume cursor.
+	Seq int64
+	// User is the id of the user, the entry is for.
+	User uuid.UUID
+	// Attempt is the id of the associated AcceptedIntelDeliveryAttempt.
+	Attempt uuid.UUID
+	// CreatedAt is the timestamp when the entry was created.
+	CreatedAt time.Time
+	// ReadAt is the optional timestamp when the entry was marked as read.
+	ReadAt nulls.Time
+}
+
+// CreateInboxEntry creates the given InboxEntry. InboxEntry.ID and
+// InboxEntry.Seq are assigned by the database and therefore ignored.
+func (m *Mall) CreateInboxEntry(ctx context.Context, tx pgx.Tx, create InboxEntry) error {
+	q, _, err := m.dialect.Insert(goqu.T("notification_inbox")).Rows(goqu.Record{
+		"user":       create.User,
+		"attempt":    create.Attempt,
+		"created_at": create.CreatedAt.UTC(),
+		"read_at":    create.ReadAt,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// inboxEntrySelect is the select-query for InboxEntry. Use scanInboxEntry for
+// scanning.
+func (m *Mall) inboxEntrySelect() *goqu.SelectDataset {
+	return m.dialect.From(goqu.T("notification_inbox")).
+		Select(goqu.C("id"),
+			goqu.C("seq"),
+			goqu.C("user"),
+			goqu.C("attempt"),
+			goqu.C("created_at"),
+			goqu.C("read_at"))
+}
+
+// scanInboxEntry scans an InboxEntry, selected via inboxEntrySelect, with
+// optional additional destinations.
+func scanInboxEntry(rows pgx.Rows, entry *InboxEntry, additionalDest ...any) error {
+	dest := []any{
+		&entry.ID,
+		&entry.Seq,
+		&entry.User,
+		&entry.Attempt,
+		&entry.CreatedAt,
+		&entry.ReadAt,
+	}
+	return rows.Scan(append(dest, additionalDest...)...)
+}
+
+// inboxEntryBy retrieves the InboxEntry, matching the given expression.
+func (m *Mall) inboxEntryBy(ctx context.Context, tx pgx.Tx, where goqu.Expression) (InboxEntry, error) {
+	q, _, err := m.inboxEntrySelect().Where(where).ToSQL()
+	if err != nil {
+		return InboxEntry{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return InboxEntry{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return InboxEntry{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var entry InboxEntry
+	err = scanInboxEntry(rows, &entry)
+	if err != nil {
+		return InboxEntry{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return entry, nil
+}
+
+// InboxEntryByID retrieves the InboxEntry with the given id.
+func (m *Mall) InboxEntryByID(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) (InboxEntry, error) {
+	entry, err := m.inboxEntryBy(ctx, tx, goqu.C("id").Eq(entryID))
+	if err != nil {
+		return InboxEntry{}, meh.Wrap(err, "inbox entry by id", meh.Details{"entry_id": entryID})
+	}
+	return entry, nil
+}
+
+// inboxEntryByAttempt retrieves the InboxEntry for the attempt with the given
+// id.
+func (m *Mall) inboxEntryByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (InboxEntry, error) {
+	entry, err := m.inboxEntryBy(ctx, tx, goqu.C("attempt").Eq(attemptID))
+	if err != nil {
+		return InboxEntry{}, meh.Wrap(err, "inbox entry by attempt", meh.Details{"attempt_id": attemptID})
+	}
+	return entry, nil
+}
+
+// InboxEntriesByUserAfter retrieves at most the given limit of InboxEntry for
+// the user with the given id, having a sequence number greater than the given
+// one. Entries are sorted ascending by their sequence number.
+func (m *Mall) InboxEntriesByUserAfter(ctx context.Context, tx pgx.Tx, userID uuid.UUID, afterSeq int64, limit int) ([]InboxEntry, error) {
+	q, _, err := m.inboxEntrySelect().
+		Where(goqu.C("user").Eq(userID),
+			goqu.C("seq").Gt(afterSeq)).
+		Order(goqu.C("seq").Asc()).
+		Limit(uint(limit)).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	entries := make([]InboxEntry, 0, limit)
+	for rows.Next() {
+		var entry InboxEntry
+		err = scanInboxEntry(rows, &entry)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	return entries, nil
+}
+
+// InboxEntryFilters are filters for InboxEntry retrieval.
+type InboxEntryFilters struct {
+	// ByRead only includes entries being (un)read.
+	ByRead nulls.Bool
+}
+
+// InboxEntriesByUser retrieves a paginated InboxEntry list for the user with
+// the given id using the given InboxEntryFilters and pagination.Params, sorted
+// descending by sequence number.
+//
+// Warning: Sorting via pagination.Params is discarded!
+func (m *Mall) InboxEntriesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, filters InboxEntryFilters,
+	page pagination.Params) (pagination.Paginated[InboxEntry], error) {
+	qb := m.inboxEntrySelect().
+		Where(goqu.C("user").Eq(userID)).
+		Order(goqu.C("seq").Desc())
+	if filters.ByRead.Valid {
+		if filters.ByRead.Bool {
+			qb = qb.Where(goqu.C("read_at").IsNotNull())
+		} else {
+			qb = qb.Where(goqu.C("read_at").IsNull())
+		}
+	}
+	page.OrderBy = nulls.String{}
+	q, _, err := pagination.QueryToSQLWithPagination(qb, page, pagination.FieldMap{})
+	if err != nil {
+		return pagination.Paginated[InboxEntry]{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return pagination.Paginated[InboxEntry]{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	entries := make([]InboxEntry, 0)
+	var total int
+	for rows.Next() {
+		var entry InboxEntry
+		err = scanInboxEntry(rows, &entry, &total)
+		if err != nil {
+			return pagination.Paginated[InboxEntry]{}, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	return pagination.NewPaginated(page, entries, total), nil
+}
+
+// MarkInboxEntryAsRead marks the InboxEntry with the given id as read at the
+// given timestamp. If it is already marked as read, the original timestamp is
+// kept.
+func (m *Mall) MarkInboxEntryAsRead(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, readAt time.Time) error {
+	q, _, err := m.dialect.Update(goqu.T("notification_inbox")).Set(goqu.Record{
+		"read_at": goqu.Func("coalesce", goqu.C("read_at"), readAt.UTC()),
+	}).Where(goqu.C("id").Eq(entryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	return nil
+}
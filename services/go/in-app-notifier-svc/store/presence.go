@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"time"
+)
+
+// CreatePresenceReplica creates the replica with the given id and heartbeat.
+func (m *Mall) CreatePresenceReplica(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error {
+	q, _, err := m.dialect.Insert(goqu.T("presence_replicas")).Rows(goqu.Record{
+		"id":             replicaID,
+		"last_heartbeat": heartbeat,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// UpdatePresenceReplicaHeartbeat sets the last heartbeat of the replica with
+// the given id. If the replica was not found, because it was removed for being
+// considered dead, a meh.ErrNotFound error is returned.
+func (m *Mall) UpdatePresenceReplicaHeartbeat(ctx context.Context, tx pgx.Tx, replicaID uuid.UUID, heartbeat time.Time) error {
+	q, _, err := m.dialect.Update(goqu.T("presence_replicas")).Set(goqu.Record{
+		"last_heartbeat": heartbeat,
+	}).Where(goqu.C("id").Eq(replicaID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("replica not found", meh.Details{"query": q})
+	}
+	return nil
+}
+
+// DeleteDeadPresenceReplicas deletes all replicas with their last heartbeat
+// being before the given timestamp. Their connections are deleted as well.
+func (m *Mall) DeleteDeadPresenceReplicas(ctx context.Context, tx pgx.Tx, aliveSince time.Time) error {
+	q, _, err := m.dialect.Delete(goqu.T("presence_replicas")).
+		Where(goqu.C("last_heartbeat").Lt(aliveSince)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// AddUserConnection marks the user with the given id as being connected to the
+// replica with the given id since the given timestamp. If the user is already
+// connected to the replica, nothing is changed.
+func (m *Mall) AddUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID, since time.Time) error {
+	q, _, err := m.dialect.Insert(goqu.T("user_connections")).Rows(goqu.Record{
+		"user":    userID,
+		"replica": replicaID,
+		"since":   since,
+	}).OnConflict(goqu.DoNothing()).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// RemoveUserConnection removes the connection of the user with the given id to
+// the replica with the given id.
+func (m *Mall) RemoveUserConnection(ctx context.Context, tx pgx.Tx, userID uuid.UUID, replicaID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("user_connections")).
+		Where(goqu.C("user").Eq(userID),
+			goqu.C("replica").Eq(replicaID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// IsUserConnected checks whether the user with the given id is connected to any
+// replica with its last heartbeat not being before the given timestamp.
+func (m *Mall) IsUserConnected(ctx context.Context, tx pgx.Tx, userID uuid.UUID, aliveSince time.Time) (bool, error) {
+	q, _, err := m.dialect.Select(goqu.L("exists ?", m.dialect.From(goqu.T("user_connections")).
+		InnerJoin(goqu.T("presence_replicas"),
+			goqu.On(goqu.I("presence_replicas.id").Eq(goqu.I("user_connections.replica")))).
+		Select(goqu.L("1")).
+		Where(goqu.I("user_connections.user").Eq(userID),
+			goqu.I("presence_replicas.last_heartbeat").Gte(aliveSince)))).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return false, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
+	}
+	var isConnected bool
+	err = rows.Scan(&isConnected)
+	if err != nil {
+		return false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return isConnected, nil
+}
+
+// UserPresenceAndLockOrWait locks and retrieves the last stored presence of the
+// user with the given id. If no presence is stored, yet, the user is created as
+// being offline.
+func (m *Mall) UserPresenceAndLockOrWait(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
+	// Assure existing row for being able to lock it.
+	q, _, err := m.dialect.Insert(goqu.T("user_presence")).Rows(goqu.Record{
+		"user":      userID,
+		"ts":        time.Time{},
+		"is_online": false,
+	}).OnConflict(goqu.DoNothing()).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "insert-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "exec insert-query", q)
+	}
+	// Lock.
+	q, _, err = m.dialect.From(goqu.T("user_presence")).
+		Select(goqu.C("is_online")).
+		Where(goqu.C("user").Eq(userID)).
+		ForUpdate(exp.Wait).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "select-query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return false, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var isOnline bool
+	err = rows.Scan(&isOnline)
+	if err != nil {
+		return false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return isOnline, nil
+}
+
+// UpdateUserPresence sets the stored presence of the user with the given id.
+func (m *Mall) UpdateUserPresence(ctx context.Context, tx pgx.Tx, userID uuid.UUID, isOnline bool, ts time.Time) error {
+	q, _, err := m.dialect.Update(goqu.T("user_presence")).Set(goqu.Record{
+		"is_online": isOnline,
+		"ts":        ts,
+	}).Where(goqu.C("user").Eq(userID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	return nil
+}
+
+// OnlineUsersWithoutConnections retrieves the ids of all users, that are stored
+// as being online, but are not connected to any replica with its last heartbeat
+// not being before the given timestamp.
+func (m *Mall) OnlineUsersWithoutConnections(ctx context.Context, tx pgx.Tx, aliveSince time.Time) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("user_presence")).
+		Select(goqu.C("user")).
+		Where(goqu.C("is_online").IsTrue(),
+			goqu.L("not exists ?", m.dialect.From(goqu.T("user_connections")).
+				InnerJoin(goqu.T("presence_replicas"),
+					goqu.On(goqu.I("presence_replicas.id").Eq(goqu.I("user_connections.replica")))).
+				Select(goqu.L("1")).
+				Where(goqu.I("user_connections.user").Eq(goqu.I("user_presence.user")),
+					goqu.I("presence_replicas.last_heartbeat").Gte(aliveSince)))).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		err = rows.Scan(&userID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
@@ -15,8 +15,41 @@ const (
 	// messageTypeIntelNotification is used in connection.Notify for notifying about
 	// an intel.
 	messageTypeIntelNotification wsutil.MessageType = "intel-notification"
+	// messageTypeIntelDeliveryEscalation is used in
+	// connection.NotifyIntelDeliveryEscalation for notifying about an escalated
+	// intel-delivery.
+	messageTypeIntelDeliveryEscalation wsutil.MessageType = "intel-delivery-escalation"
+	// messageTypeIntelNotificationReceived is sent by the client in order to
+	// confirm that an intel-notification was received.
+	messageTypeIntelNotificationReceived wsutil.MessageType = "intel-notification-received"
+	// messageTypeIntelNotificationAcknowledged is sent by the client in order to
+	// confirm that an intel-notification was read and acknowledged.
+	messageTypeIntelNotificationAcknowledged wsutil.MessageType = "intel-notification-acknowledged"
+	// messageTypeReplayNotifications is sent by the client in order to request
+	// replaying notifications from the inbox after a resume cursor.
+	messageTypeReplayNotifications wsutil.MessageType = "replay-notifications"
 )
 
+// messageIntelNotificationReceived is the payload for messages with type
+// messageTypeIntelNotificationReceived.
+type messageIntelNotificationReceived struct {
+	Attempt uuid.UUID `json:"attempt"`
+}
+
+// messageIntelNotificationAcknowledged is the payload for messages with type
+// messageTypeIntelNotificationAcknowledged.
+type messageIntelNotificationAcknowledged struct {
+	Attempt uuid.UUID `json:"attempt"`
+}
+
+// messageReplayNotifications is the payload for messages with type
+// messageTypeReplayNotifications.
+type messageReplayNotifications struct {
+	// After is the sequence number of the last received inbox entry. Only entries
+	// with a greater sequence number are replayed.
+	After int64 `json:"after"`
+}
+
 // publicIntelToDeliver is the public representation of store.IntelToDeliver.
 type publicIntelToDeliver struct {
 	Attempt    uuid.UUID       `json:"attempt"`
@@ -118,14 +151,33 @@ func mapStoreUserToPublic(s store.User) publicUser {
 	}
 }
 
+// publicInboxEntry is the public representation of store.InboxEntry.
+type publicInboxEntry struct {
+	ID        uuid.UUID  `json:"id"`
+	Seq       int64      `json:"seq"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    nulls.Time `json:"read_at"`
+}
+
+// mapStoreInboxEntryToPublic maps store.InboxEntry to publicInboxEntry.
+func mapStoreInboxEntryToPublic(s store.InboxEntry) publicInboxEntry {
+	return publicInboxEntry{
+		ID:        s.ID,
+		Seq:       s.Seq,
+		CreatedAt: s.CreatedAt,
+		ReadAt:    s.ReadAt,
+	}
+}
+
 // publicIntelDeliveryNotification is the public representation of
 // store.OutgoingIntelDeliveryNotification.
 type publicIntelDeliveryNotification struct {
-	IntelToDeliver   publicIntelToDeliver           `json:"intel_to_deliver"`
-	DeliveryAttempt  publicIntelDeliveryAttempt     `json:"delivery_attempt"`
-	Channel          publicNotificationChannel      `json:"channel"`
-	CreatorDetails   publicUser                     `json:"creator_details"`
-	RecipientDetails nulls.JSONNullable[publicUser] `json:"recipient_details"`
+	IntelToDeliver   publicIntelToDeliver                 `json:"intel_to_deliver"`
+	DeliveryAttempt  publicIntelDeliveryAttempt           `json:"delivery_attempt"`
+	Channel          publicNotificationChannel            `json:"channel"`
+	CreatorDetails   publicUser                           `json:"creator_details"`
+	RecipientDetails nulls.JSONNullable[publicUser]       `json:"recipient_details"`
+	InboxEntry       nulls.JSONNullable[publicInboxEntry] `json:"inbox_entry"`
 }
 
 // mapStoreOutgoingIntelDeliveryNotificationToPublic maps
@@ -140,9 +192,40 @@ func mapStoreOutgoingIntelDeliveryNotificationToPublic(s store.OutgoingIntelDeli
 	if s.RecipientDetails.Valid {
 		n.RecipientDetails = nulls.NewJSONNullable(mapStoreUserToPublic(s.RecipientDetails.V))
 	}
+	if s.InboxEntry.Valid {
+		n.InboxEntry = nulls.NewJSONNullable(mapStoreInboxEntryToPublic(s.InboxEntry.V))
+	}
 	return n
 }
 
+// publicIntelDeliveryEscalation is the public representation of
+// store.IntelDeliveryEscalation.
+type publicIntelDeliveryEscalation struct {
+	ID               uuid.UUID     `json:"id"`
+	Delivery         uuid.UUID     `json:"delivery"`
+	Intel            uuid.UUID     `json:"intel"`
+	Operation        uuid.UUID     `json:"operation"`
+	Trigger          string        `json:"trigger"`
+	EscalatedAt      time.Time     `json:"escalated_at"`
+	EscalatedTo      uuid.NullUUID `json:"escalated_to"`
+	EscalatedToEntry uuid.NullUUID `json:"escalated_to_entry"`
+}
+
+// mapStoreIntelDeliveryEscalationToPublic maps store.IntelDeliveryEscalation to
+// publicIntelDeliveryEscalation.
+func mapStoreIntelDeliveryEscalationToPublic(s store.IntelDeliveryEscalation) publicIntelDeliveryEscalation {
+	return publicIntelDeliveryEscalation{
+		ID:               s.ID,
+		Delivery:         s.Delivery,
+		Intel:            s.Intel,
+		Operation:        s.Operation,
+		Trigger:          string(s.Trigger),
+		EscalatedAt:      s.EscalatedAt,
+		EscalatedTo:      s.EscalatedTo,
+		EscalatedToEntry: s.EscalatedToEntry,
+	}
+}
+
 // connection implements controller.Connection and maps notifications from the
 // store-representation to the public one.
 type connection struct {
@@ -170,6 +253,17 @@ func (conn *connection) Notify(ctx context.Context, notification store.OutgoingI
 	return nil
 }
 
+// NotifyIntelDeliveryEscalation maps the given store.IntelDeliveryEscalation to
+// its public representation and sends it over the connection.
+func (conn *connection) NotifyIntelDeliveryEscalation(ctx context.Context, escalation store.IntelDeliveryEscalation) error {
+	publicEscalation := mapStoreIntelDeliveryEscalationToPublic(escalation)
+	err := conn.conn.Send(ctx, messageTypeIntelDeliveryEscalation, publicEscalation)
+	if err != nil {
+		return meh.Wrap(err, "send over connection", meh.Details{"payload": publicEscalation})
+	}
+	return nil
+}
+
 // Done returns the done-channel from the connection.
 func (conn *connection) Done() <-chan struct{} {
 	return conn.conn.Lifetime().Done()
@@ -85,6 +85,14 @@ func (suite *connectionNotifySuite) SetupTest() {
 			LastName:  "earth",
 			IsActive:  true,
 		}),
+		InboxEntry: nulls.NewJSONNullable(store.InboxEntry{
+			ID:        testutil.NewUUIDV4(),
+			Seq:       561,
+			User:      assignedToUser,
+			Attempt:   attemptID,
+			CreatedAt: time.Date(2022, 9, 8, 1, 27, 11, 0, time.UTC),
+			ReadAt:    nulls.NewTime(time.Date(2022, 9, 8, 1, 32, 4, 0, time.UTC)),
+		}),
 	}
 	suite.samplePublicNotification = publicIntelDeliveryNotification{
 		IntelToDeliver: publicIntelToDeliver{
@@ -130,6 +138,12 @@ func (suite *connectionNotifySuite) SetupTest() {
 			LastName:  suite.sampleNotification.RecipientDetails.V.LastName,
 			IsActive:  suite.sampleNotification.RecipientDetails.V.IsActive,
 		}),
+		InboxEntry: nulls.NewJSONNullable(publicInboxEntry{
+			ID:        suite.sampleNotification.InboxEntry.V.ID,
+			Seq:       suite.sampleNotification.InboxEntry.V.Seq,
+			CreatedAt: suite.sampleNotification.InboxEntry.V.CreatedAt,
+			ReadAt:    suite.sampleNotification.InboxEntry.V.ReadAt,
+		}),
 	}
 }
 
@@ -154,3 +168,64 @@ func (suite *connectionNotifySuite) TestOK() {
 func TestConnection_Notify(t *testing.T) {
 	suite.Run(t, new(connectionNotifySuite))
 }
+
+// connectionNotifyIntelDeliveryEscalationSuite tests
+// connection.NotifyIntelDeliveryEscalation.
+type connectionNotifyIntelDeliveryEscalationSuite struct {
+	suite.Suite
+	wsConn                 *wstest.RawConnection
+	conn                   *connection
+	sampleEscalation       store.IntelDeliveryEscalation
+	samplePublicEscalation publicIntelDeliveryEscalation
+}
+
+func (suite *connectionNotifyIntelDeliveryEscalationSuite) SetupTest() {
+	connLifetime, shutdownConn := context.WithCancel(context.Background())
+	suite.T().Cleanup(func() {
+		shutdownConn()
+	})
+	suite.wsConn = wstest.NewConnectionMock(connLifetime, auth.Token{})
+	suite.conn = newConnection(wsutil.NewAutoParserConnection(suite.wsConn))
+	suite.sampleEscalation = store.IntelDeliveryEscalation{
+		ID:               testutil.NewUUIDV4(),
+		Delivery:         testutil.NewUUIDV4(),
+		Intel:            testutil.NewUUIDV4(),
+		Operation:        testutil.NewUUIDV4(),
+		Trigger:          store.IntelDeliveryEscalationTriggerFailed,
+		EscalatedAt:      time.Date(2022, 9, 8, 1, 31, 2, 0, time.UTC),
+		EscalatedTo:      nulls.NewUUID(testutil.NewUUIDV4()),
+		EscalatedToEntry: nulls.NewUUID(testutil.NewUUIDV4()),
+	}
+	suite.samplePublicEscalation = publicIntelDeliveryEscalation{
+		ID:               suite.sampleEscalation.ID,
+		Delivery:         suite.sampleEscalation.Delivery,
+		Intel:            suite.sampleEscalation.Intel,
+		Operation:        suite.sampleEscalation.Operation,
+		Trigger:          "failed",
+		EscalatedAt:      suite.sampleEscalation.EscalatedAt,
+		EscalatedTo:      suite.sampleEscalation.EscalatedTo,
+		EscalatedToEntry: suite.sampleEscalation.EscalatedToEntry,
+	}
+}
+
+func (suite *connectionNotifyIntelDeliveryEscalationSuite) TestSendFail() {
+	suite.wsConn.SendFail = true
+
+	err := suite.conn.NotifyIntelDeliveryEscalation(context.Background(), suite.sampleEscalation)
+	suite.Error(err, "should fail")
+}
+
+func (suite *connectionNotifyIntelDeliveryEscalationSuite) TestOK() {
+	err := suite.conn.NotifyIntelDeliveryEscalation(context.Background(), suite.sampleEscalation)
+	suite.Require().NoError(err, "should not fail")
+	outbox := suite.wsConn.Outbox()
+	suite.NotEmpty(outbox, "should have send message")
+	suite.Equal(wsutil.Message{
+		Type:    messageTypeIntelDeliveryEscalation,
+		Payload: testutil.MarshalJSONMust(suite.samplePublicEscalation),
+	}, outbox[0], "should have send correct message")
+}
+
+func TestConnection_NotifyIntelDeliveryEscalation(t *testing.T) {
+	suite.Run(t, new(connectionNotifyIntelDeliveryEscalationSuite))
+}
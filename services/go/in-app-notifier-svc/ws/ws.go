@@ -1,6 +1,8 @@
 package ws
 
 import (
+	"context"
+	"github.com/gofrs/uuid"
 	"github.com/lefinal/meh"
 	"github.com/lefinal/meh/mehlog"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/controller"
@@ -15,6 +17,23 @@ type ForwardListener interface {
 	AcceptNewConnection(connection controller.Connection)
 }
 
+// ReceiptHandler handles receipts for intel-notifications, sent by the client.
+type ReceiptHandler interface {
+	// MarkIntelNotificationAsReceived marks the intel-notification for the attempt
+	// with the given id as received by the user with the given id.
+	MarkIntelNotificationAsReceived(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error
+	// AcknowledgeIntelNotification marks the intel-notification for the attempt
+	// with the given id as read and acknowledged by the user with the given id.
+	AcknowledgeIntelNotification(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error
+}
+
+// InboxReplayer replays notifications from the inbox of a user.
+type InboxReplayer interface {
+	// ReplayInbox sends all inbox entries for the user of the given
+	// controller.Connection, having a sequence number greater than the given one.
+	ReplayInbox(ctx context.Context, conn controller.Connection, afterSeq int64) error
+}
+
 // Gatekeeper is a ws.Gatekeeper, assuring that the auth.Token is authenticated.
 func Gatekeeper() wsutil.Gatekeeper {
 	return func(token auth.Token) error {
@@ -26,13 +45,71 @@ func Gatekeeper() wsutil.Gatekeeper {
 }
 
 // ConnListener is the listener for ws.ConnListener that forwards created and
-// mapped connections to the given ForwardListener.
-func ConnListener(logger *zap.Logger, forwardListener ForwardListener) wsutil.ConnListener {
+// mapped connections to the given ForwardListener. Received messages are
+// handled until the connection is closed.
+func ConnListener(logger *zap.Logger, forwardListener ForwardListener, receiptHandler ReceiptHandler,
+	inboxReplayer InboxReplayer) wsutil.ConnListener {
 	return func(conn wsutil.RawConnection) {
 		if !conn.AuthToken().IsAuthenticated {
 			mehlog.Log(logger, meh.NewInternalErr("websocket connection listener received unauthenticated connection", nil))
 			return
 		}
-		forwardListener.AcceptNewConnection(newConnection(wsutil.NewAutoParserConnection(conn)))
+		wsConn := wsutil.NewAutoParserConnection(conn)
+		mappedConn := newConnection(wsConn)
+		forwardListener.AcceptNewConnection(mappedConn)
+		for receivedMessage := range wsConn.Receive() {
+			err := handleReceivedMessage(wsConn.Lifetime(), receiptHandler, inboxReplayer, mappedConn, receivedMessage)
+			if err != nil {
+				err = meh.Wrap(err, "handle received message", meh.Details{"message": receivedMessage})
+				mehlog.Log(logger, err)
+				wsConn.SendErr(context.Background(), err)
+				continue
+			}
+		}
+	}
+}
+
+// handleReceivedMessage handles the given wsutil.Message, received from the
+// client of the given connection.
+func handleReceivedMessage(ctx context.Context, receiptHandler ReceiptHandler, inboxReplayer InboxReplayer, conn *connection,
+	receivedMessage wsutil.Message) error {
+	token := conn.conn.AuthToken()
+	switch receivedMessage.Type {
+	case messageTypeIntelNotificationReceived:
+		err := wsutil.ParseAndHandle(receivedMessage, func(message messageIntelNotificationReceived) error {
+			err := receiptHandler.MarkIntelNotificationAsReceived(ctx, message.Attempt, token.UserID)
+			if err != nil {
+				return meh.Wrap(err, "mark intel-notification as received", meh.Details{"attempt": message.Attempt})
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "handle intel-notification received message", nil)
+		}
+	case messageTypeIntelNotificationAcknowledged:
+		err := wsutil.ParseAndHandle(receivedMessage, func(message messageIntelNotificationAcknowledged) error {
+			err := receiptHandler.AcknowledgeIntelNotification(ctx, message.Attempt, token.UserID)
+			if err != nil {
+				return meh.Wrap(err, "acknowledge intel-notification", meh.Details{"attempt": message.Attempt})
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "handle intel-notification acknowledged message", nil)
+		}
+	case messageTypeReplayNotifications:
+		err := wsutil.ParseAndHandle(receivedMessage, func(message messageReplayNotifications) error {
+			err := inboxReplayer.ReplayInbox(ctx, conn, message.After)
+			if err != nil {
+				return meh.Wrap(err, "replay inbox", meh.Details{"after": message.After})
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "handle replay notifications message", nil)
+		}
+	default:
+		return meh.NewBadInputErr("unsupported message type", meh.Details{"message_type": receivedMessage.Type})
 	}
+	return nil
 }
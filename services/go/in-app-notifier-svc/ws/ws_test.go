@@ -2,9 +2,14 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
 	"github.com/lefinal/zaprec"
 	"github.com/mobile-directing-system/mds-server/services/go/in-app-notifier-svc/controller"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/wstest"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/wsutil"
 	"github.com/stretchr/testify/mock"
@@ -25,41 +30,241 @@ func (m *ForwardListenerMock) AcceptNewConnection(connection controller.Connecti
 	m.Called(connection)
 }
 
+// ReceiptHandlerMock mocks ReceiptHandler.
+type ReceiptHandlerMock struct {
+	mock.Mock
+}
+
+func (m *ReceiptHandlerMock) MarkIntelNotificationAsReceived(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, attemptID, by).Error(0)
+}
+
+func (m *ReceiptHandlerMock) AcknowledgeIntelNotification(ctx context.Context, attemptID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, attemptID, by).Error(0)
+}
+
+// InboxReplayerMock mocks InboxReplayer.
+type InboxReplayerMock struct {
+	mock.Mock
+}
+
+func (m *InboxReplayerMock) ReplayInbox(ctx context.Context, conn controller.Connection, afterSeq int64) error {
+	return m.Called(ctx, conn, afterSeq).Error(0)
+}
+
 // ConnListenerSuite tests ConnListener.
 type ConnListenerSuite struct {
 	suite.Suite
 	forwardListener *ForwardListenerMock
-	sampleConn      wsutil.RawConnection
+	receiptHandler  *ReceiptHandlerMock
+	inboxReplayer   *InboxReplayerMock
+	sampleToken     auth.Token
+	sampleConn      *wstest.RawConnection
 	listener        wsutil.ConnListener
 }
 
 func (suite *ConnListenerSuite) SetupTest() {
 	suite.forwardListener = &ForwardListenerMock{}
-	suite.sampleConn = wstest.NewConnectionMock(context.Background(), auth.Token{IsAuthenticated: true})
-	suite.listener = ConnListener(zap.NewNop(), suite.forwardListener)
+	suite.receiptHandler = &ReceiptHandlerMock{}
+	suite.inboxReplayer = &InboxReplayerMock{}
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+	}
+	suite.sampleConn = wstest.NewConnectionMock(context.Background(), suite.sampleToken)
+	suite.listener = ConnListener(zap.NewNop(), suite.forwardListener, suite.receiptHandler, suite.inboxReplayer)
 }
 
 func (suite *ConnListenerSuite) TestNotAuthenticated() {
 	conn := wstest.NewConnectionMock(context.Background(), auth.Token{IsAuthenticated: false})
 	logger, recorder := zaprec.NewRecorder(zap.ErrorLevel)
-	listener := ConnListener(logger, suite.forwardListener)
+	listener := ConnListener(logger, suite.forwardListener, suite.receiptHandler, suite.inboxReplayer)
 
 	listener(conn)
 
 	suite.NotEmpty(recorder.Records(), "should have logged error")
 }
 
+func (suite *ConnListenerSuite) TestHandleFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	defer cancel()
+	suite.forwardListener.On("AcceptNewConnection", mock.Anything).Once()
+	defer suite.forwardListener.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		suite.sampleConn.NextReceive(timeout, "unknown", nil)
+		select {
+		case <-timeout.Done():
+			suite.Fail("timeout", "timeout while waiting for error message")
+		case message := <-suite.sampleConn.OutboxChan():
+			suite.Equal(wsutil.TypeError, message.Type, "should send error message")
+		}
+		suite.sampleConn.Disconnect()
+	}()
+
+	suite.listener(suite.sampleConn)
+
+	wait()
+}
+
 func (suite *ConnListenerSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	defer cancel()
+	keepConnectionAlive, disconnect := context.WithCancel(timeout)
+	defer disconnect()
+	attemptID := testutil.NewUUIDV4()
 	suite.forwardListener.On("AcceptNewConnection", mock.Anything).Once()
 	defer suite.forwardListener.AssertExpectations(suite.T())
+	suite.receiptHandler.On("AcknowledgeIntelNotification", mock.Anything, attemptID, suite.sampleToken.UserID).
+		Run(func(_ mock.Arguments) {
+			disconnect()
+		}).Return(nil).Once()
+	defer suite.receiptHandler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		suite.sampleConn.NextReceive(timeout, messageTypeIntelNotificationAcknowledged, messageIntelNotificationAcknowledged{
+			Attempt: attemptID,
+		})
+		<-keepConnectionAlive.Done()
+		suite.Require().NotEqual(context.DeadlineExceeded, keepConnectionAlive.Err(), "should not time out while waiting for disconnect-call")
+		suite.sampleConn.Disconnect()
+	}()
 
 	suite.listener(suite.sampleConn)
+
+	wait()
 }
 
 func TestConnListener(t *testing.T) {
 	suite.Run(t, new(ConnListenerSuite))
 }
 
+// handleReceivedMessageSuite tests handleReceivedMessage.
+type handleReceivedMessageSuite struct {
+	suite.Suite
+	receiptHandler *ReceiptHandlerMock
+	inboxReplayer  *InboxReplayerMock
+	sampleToken    auth.Token
+	conn           *connection
+	sampleAttempt  uuid.UUID
+}
+
+func (suite *handleReceivedMessageSuite) SetupTest() {
+	suite.receiptHandler = &ReceiptHandlerMock{}
+	suite.inboxReplayer = &InboxReplayerMock{}
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+	}
+	connLifetime, cancelConn := context.WithCancel(context.Background())
+	suite.T().Cleanup(cancelConn)
+	suite.conn = newConnection(wsutil.NewAutoParserConnection(wstest.NewConnectionMock(connLifetime, suite.sampleToken)))
+	suite.sampleAttempt = testutil.NewUUIDV4()
+}
+
+func (suite *handleReceivedMessageSuite) handle(messageType wsutil.MessageType, payload json.RawMessage) error {
+	return handleReceivedMessage(context.Background(), suite.receiptHandler, suite.inboxReplayer, suite.conn, wsutil.Message{
+		Type:    messageType,
+		Payload: payload,
+	})
+}
+
+func (suite *handleReceivedMessageSuite) TestUnsupportedMessageType() {
+	err := suite.handle("unknown", json.RawMessage(`{}`))
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *handleReceivedMessageSuite) TestReceivedInvalidContent() {
+	err := suite.handle(messageTypeIntelNotificationReceived, json.RawMessage(`{invalid`))
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *handleReceivedMessageSuite) TestReceivedFail() {
+	suite.receiptHandler.On("MarkIntelNotificationAsReceived", mock.Anything, suite.sampleAttempt, suite.sampleToken.UserID).
+		Return(errors.New("sad life"))
+	defer suite.receiptHandler.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeIntelNotificationReceived, testutil.MarshalJSONMust(messageIntelNotificationReceived{
+		Attempt: suite.sampleAttempt,
+	}))
+	suite.Error(err, "should fail")
+}
+
+func (suite *handleReceivedMessageSuite) TestReceivedOK() {
+	suite.receiptHandler.On("MarkIntelNotificationAsReceived", mock.Anything, suite.sampleAttempt, suite.sampleToken.UserID).
+		Return(nil).Once()
+	defer suite.receiptHandler.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeIntelNotificationReceived, testutil.MarshalJSONMust(messageIntelNotificationReceived{
+		Attempt: suite.sampleAttempt,
+	}))
+	suite.NoError(err, "should not fail")
+}
+
+func (suite *handleReceivedMessageSuite) TestAcknowledgedInvalidContent() {
+	err := suite.handle(messageTypeIntelNotificationAcknowledged, json.RawMessage(`{invalid`))
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *handleReceivedMessageSuite) TestAcknowledgedFail() {
+	suite.receiptHandler.On("AcknowledgeIntelNotification", mock.Anything, suite.sampleAttempt, suite.sampleToken.UserID).
+		Return(errors.New("sad life"))
+	defer suite.receiptHandler.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeIntelNotificationAcknowledged, testutil.MarshalJSONMust(messageIntelNotificationAcknowledged{
+		Attempt: suite.sampleAttempt,
+	}))
+	suite.Error(err, "should fail")
+}
+
+func (suite *handleReceivedMessageSuite) TestAcknowledgedOK() {
+	suite.receiptHandler.On("AcknowledgeIntelNotification", mock.Anything, suite.sampleAttempt, suite.sampleToken.UserID).
+		Return(nil).Once()
+	defer suite.receiptHandler.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeIntelNotificationAcknowledged, testutil.MarshalJSONMust(messageIntelNotificationAcknowledged{
+		Attempt: suite.sampleAttempt,
+	}))
+	suite.NoError(err, "should not fail")
+}
+
+func (suite *handleReceivedMessageSuite) TestReplayInvalidContent() {
+	err := suite.handle(messageTypeReplayNotifications, json.RawMessage(`{invalid`))
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *handleReceivedMessageSuite) TestReplayFail() {
+	suite.inboxReplayer.On("ReplayInbox", mock.Anything, suite.conn, int64(42)).
+		Return(errors.New("sad life"))
+	defer suite.inboxReplayer.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeReplayNotifications, testutil.MarshalJSONMust(messageReplayNotifications{
+		After: 42,
+	}))
+	suite.Error(err, "should fail")
+}
+
+func (suite *handleReceivedMessageSuite) TestReplayOK() {
+	suite.inboxReplayer.On("ReplayInbox", mock.Anything, suite.conn, int64(42)).
+		Return(nil).Once()
+	defer suite.inboxReplayer.AssertExpectations(suite.T())
+
+	err := suite.handle(messageTypeReplayNotifications, testutil.MarshalJSONMust(messageReplayNotifications{
+		After: 42,
+	}))
+	suite.NoError(err, "should not fail")
+}
+
+func Test_handleReceivedMessage(t *testing.T) {
+	suite.Run(t, new(handleReceivedMessageSuite))
+}
+
 // GatekeeperSuite tests Gatekeeper.
 type GatekeeperSuite struct {
 	suite.Suite
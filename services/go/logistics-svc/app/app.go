@@ -76,7 +76,11 @@ func Run(ctx context.Context) error {
 				event.IntelTopic,
 				event.IntelDeliveriesTopic,
 				event.InAppNotificationsTopic,
+				event.UserPresenceTopic,
 				event.RadioDeliveriesTopic,
+				event.EmailDeliveriesTopic,
+				event.PhoneCallDeliveriesTopic,
+				event.DirectDeliveriesTopic,
 			}
 			err := kafkautil.AwaitTopics(egCtx, c.KafkaAddr, awaitTopics...)
 			return meh.NilOrWrap(err, "await topics", meh.Details{"kafka_addr": c.KafkaAddr})
@@ -93,7 +97,7 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
@@ -110,7 +114,7 @@ func Run(ctx context.Context) error {
 	}
 	// Serve endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl)
+		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl, kafkaConnector.DeadLetterAdmin(sqlDB))
 		return meh.NilOrWrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 	})
 	// Run Kafka connector.
@@ -123,7 +127,11 @@ func Run(ctx context.Context) error {
 				event.GroupsTopic,
 				event.AddressBookTopic,
 				event.InAppNotificationsTopic,
+				event.UserPresenceTopic,
 				event.RadioDeliveriesTopic,
+				event.EmailDeliveriesTopic,
+				event.PhoneCallDeliveriesTopic,
+				event.DirectDeliveriesTopic,
 			})
 		kafkaWriter := kafkautil.NewWriter(logger.Named("kafka"), c.KafkaAddr)
 		err := kafkautil.RunConnector(egCtx, kafkaConnector, sqlDB, kafkaWriter, kafkaReader, eventPort.HandlerFn(ctrl))
@@ -0,0 +1,24 @@
+-- Create table for quorums of forward-channels.
+
+create table forward_channel_quorums
+(
+    channel uuid primary key not null references channels (id)
+        on delete cascade on update cascade,
+    quorum  int              not null
+);
+
+comment on table forward_channel_quorums is 'Number of forwarded deliveries that need to succeed for forward-to-user and forward-to-group channels.';
+
+-- Create table for linking forwarded deliveries to the attempt they were created by.
+
+create table forwarded_intel_deliveries
+(
+    delivery        uuid primary key not null references intel_deliveries (id)
+        on delete cascade on update cascade,
+    forwarded_by    uuid             not null references intel_delivery_attempts (id)
+        on delete cascade on update cascade
+);
+
+comment on column forwarded_intel_deliveries.forwarded_by is 'The intel-delivery-attempt over a forward-channel that created the delivery.';
+
+create index forwarded_intel_deliveries_forwarded_by_ix on forwarded_intel_deliveries (forwarded_by);
@@ -0,0 +1,14 @@
+-- Add versioning for intel being amended.
+
+alter table intel
+    add column previous_version uuid unique references intel (id)
+        on delete restrict on update restrict,
+    add column first_version    uuid references intel (id)
+        on delete restrict on update restrict,
+    add column version          int not null default 1;
+
+comment on column intel.previous_version is 'The intel, this one is an amended version of.';
+comment on column intel.first_version is 'The original intel of the version chain. Null for the original intel itself.';
+comment on column intel.version is 'Version number in the version chain, starting with 1 for the original intel.';
+
+create index intel_first_version_ix on intel (first_version);
@@ -0,0 +1,12 @@
+-- Add retry policies for channels.
+
+alter table channels
+    add column retry_max_attempts int     not null default 1,
+    add column retry_backoff      bigint  not null default 0,
+    add column retry_on_timeout   boolean not null default false,
+    add column retry_on_failure   boolean not null default false;
+
+comment on column channels.retry_max_attempts is 'Maximum number of delivery attempts over the channel for a single delivery.';
+comment on column channels.retry_backoff is 'Duration in nanoseconds to wait after the last attempt ended before retrying.';
+comment on column channels.retry_on_timeout is 'Whether to retry after an attempt timed out.';
+comment on column channels.retry_on_failure is 'Whether to retry after an attempt explicitly failed.';
@@ -0,0 +1,18 @@
+-- Create user presence table.
+
+create table user_presence
+(
+    "user"    uuid      not null primary key,
+    is_online boolean   not null,
+    last_seen timestamp not null
+);
+
+comment on table user_presence is 'Presence of users as notified by the in-app-notifier.';
+comment on column user_presence.last_seen is 'Timestamp of the last presence change. Used in order to discard outdated updates.';
+
+-- Add presence policies for channels.
+
+alter table channels
+    add column presence_policy varchar not null default 'ignore';
+
+comment on column channels.presence_policy is 'How to respect the presence of the user, associated with the entry, when choosing channels for delivery.';
@@ -0,0 +1,6 @@
+-- Add availability schedules for channels.
+
+alter table channels
+    add column availability jsonb not null default '{}';
+
+comment on column channels.availability is 'Weekly recurring availability windows and one-off exceptions. Without windows, the channel is available all the time.';
@@ -0,0 +1,6 @@
+-- Add fan-out delivery for address book entries.
+
+alter table address_book_entries
+    add column fan_out_min_importance int;
+
+comment on column address_book_entries.fan_out_min_importance is 'Minimum importance of intel for delivering over all usable channels in parallel. If null, delivery is always sequential.';
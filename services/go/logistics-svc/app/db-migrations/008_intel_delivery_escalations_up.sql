@@ -0,0 +1,46 @@
+-- Add creation timestamps to intel-deliveries. Like all other timestamps, they
+-- are stored in UTC.
+
+alter table intel_deliveries
+    add column created_at timestamp not null default (now() at time zone 'utc');
+
+-- Create table for escalation rules of intel-deliveries.
+
+create table intel_delivery_escalation_rules
+(
+    id              uuid primary key not null default uuid_generate_v4(),
+    operation       uuid             not null references operations (id)
+        on delete cascade on update cascade,
+    trigger         varchar          not null,
+    open_timeout    bigint           not null default 0,
+    escalate_to     uuid references address_book_entries (id)
+        on delete cascade on update cascade,
+    notify_managers bool             not null
+);
+
+comment on column intel_delivery_escalation_rules.trigger is 'Either failed or open-timeout.';
+comment on column intel_delivery_escalation_rules.open_timeout is 'Duration in nanoseconds after which still active deliveries are escalated. Only used with open-timeout trigger.';
+comment on column intel_delivery_escalation_rules.escalate_to is 'The fallback address book entry to create a delivery to.';
+
+create index intel_delivery_escalation_rules_operation_ix on intel_delivery_escalation_rules (operation);
+
+-- Create table for escalations of intel-deliveries.
+
+create table intel_delivery_escalations
+(
+    id                uuid primary key not null default uuid_generate_v4(),
+    delivery          uuid             not null references intel_deliveries (id)
+        on delete cascade on update cascade,
+    rule              uuid references intel_delivery_escalation_rules (id)
+        on delete set null on update cascade,
+    trigger           varchar          not null,
+    escalated_at      timestamp        not null,
+    escalated_to      uuid references intel_deliveries (id)
+        on delete set null on update cascade,
+    notified_managers bool             not null
+);
+
+comment on column intel_delivery_escalations.escalated_to is 'The delivery created to the fallback address book entry.';
+
+create index intel_delivery_escalations_delivery_ix on intel_delivery_escalations (delivery);
+create index intel_delivery_escalations_escalated_to_ix on intel_delivery_escalations (escalated_to);
@@ -0,0 +1,15 @@
+-- Add due-times for checking intel-deliveries.
+
+alter table intel_deliveries
+    add column next_check_at timestamp;
+
+comment on column intel_deliveries.next_check_at is 'Timestamp when the active delivery needs to be looked after the next time. Null if no check is scheduled.';
+
+create index intel_deliveries_next_check_at_ix on intel_deliveries (next_check_at)
+    where is_active = true;
+
+-- Check all active deliveries once in order to schedule further checks.
+
+update intel_deliveries
+set next_check_at = (now() at time zone 'utc')
+where is_active = true;
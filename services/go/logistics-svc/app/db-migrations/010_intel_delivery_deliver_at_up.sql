@@ -0,0 +1,6 @@
+-- Add scheduled intel-deliveries.
+
+alter table intel_deliveries
+    add column deliver_at timestamp;
+
+comment on column intel_deliveries.deliver_at is 'Optional timestamp before which no delivery attempts are created.';
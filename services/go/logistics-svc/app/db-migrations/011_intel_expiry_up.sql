@@ -0,0 +1,14 @@
+-- Add optional expiry for intel.
+
+alter table intel
+    add column valid_until timestamp;
+
+comment on column intel.valid_until is 'Optional timestamp after which the intel is stale and active deliveries are canceled.';
+
+alter table intel
+    add column is_expired bool not null default false;
+
+comment on column intel.is_expired is 'Whether the intel expired because of valid_until being reached.';
+
+create index intel_valid_until_ix on intel (valid_until)
+    where is_valid = true and is_expired = false;
@@ -0,0 +1,7 @@
+-- Add acknowledgement of intel-delivery-attempts.
+
+alter table intel_delivery_attempts
+    add column acknowledged_by uuid references users (id)
+        on delete restrict on update restrict;
+
+comment on column intel_delivery_attempts.acknowledged_by is 'The user that acknowledged the delivered intel, if reported by the channel.';
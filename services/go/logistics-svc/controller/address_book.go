@@ -255,6 +255,12 @@ func (c *Controller) SetAutoIntelDeliveryEnabledForAddressBookEntry(ctx context.
 				"enabled":  enabled,
 			})
 		}
+		if enabled {
+			err = c.scheduleDeliveryChecksByEntry(ctx, tx, entryID)
+			if err != nil {
+				return meh.Wrap(err, "schedule delivery checks by entry", meh.Details{"entry_id": entryID})
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -277,6 +283,10 @@ func (c *Controller) SetAddressBookEntriesWithAutoDeliveryEnabled(ctx context.Co
 			if err != nil {
 				return meh.Wrap(err, "notify address book entry auto delivery enabled", meh.Details{"entry_id": enabledEntryID})
 			}
+			err = c.scheduleDeliveryChecksByEntry(ctx, tx, enabledEntryID)
+			if err != nil {
+				return meh.Wrap(err, "schedule delivery checks by entry", meh.Details{"entry_id": enabledEntryID})
+			}
 		}
 		for _, disabledEntryID := range disabled {
 			err = c.Notifier.NotifyAddressBookEntryAutoDeliveryUpdated(ctx, tx, disabledEntryID, false)
@@ -948,6 +948,8 @@ func (suite *ControllerSetAddressBookEntriesWithAutoDeliveryEnabledSuite) SetupT
 	for _, entryID := range suite.entryIDs {
 		suite.ctrl.Notifier.On("NotifyAddressBookEntryAutoDeliveryUpdated", mock.Anything, suite.tx, entryID, true).
 			Return(nil).Maybe()
+		suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByEntry", mock.Anything, suite.tx, entryID, mock.Anything).
+			Return(nil).Maybe()
 	}
 }
 
@@ -987,6 +989,14 @@ func (suite *ControllerSetAddressBookEntriesWithAutoDeliveryEnabledSuite) TestNo
 	suite.NoError(err, "should not fail")
 }
 
+func (suite *ControllerSetAddressBookEntriesWithAutoDeliveryEnabledSuite) TestScheduleChecksFail() {
+	testutil.UnsetAndOn(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryChecksByEntry", mock.Anything, suite.tx, suite.entryIDs[1], mock.Anything).
+		Return(errors.New("sad life"))
+
+	err := suite.ctrl.Ctrl.SetAddressBookEntriesWithAutoDeliveryEnabled(context.Background(), suite.entryIDs)
+	suite.Error(err, "should fail")
+}
+
 func (suite *ControllerSetAddressBookEntriesWithAutoDeliveryEnabledSuite) TestOK() {
 	err := suite.ctrl.Ctrl.SetAddressBookEntriesWithAutoDeliveryEnabled(context.Background(), suite.entryIDs)
 	suite.NoError(err, "should not fail")
@@ -1101,6 +1111,23 @@ func (suite *ControllerSetAutoIntelDeliveryEnabledForAddressBookEntrySuite) Noti
 	suite.Error(err, "should fail")
 }
 
+func (suite *ControllerSetAutoIntelDeliveryEnabledForAddressBookEntrySuite) TestScheduleChecksFail() {
+	suite.enabled = true
+	suite.ctrl.Store.On("SetAutoDeliveryEnabledForAddressBookEntry",
+		mock.Anything, suite.tx, suite.entryID, suite.enabled).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyAddressBookEntryAutoDeliveryUpdated",
+		mock.Anything, suite.tx, suite.entryID, suite.enabled).
+		Return(nil)
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByEntry", mock.Anything, suite.tx, suite.entryID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	err := suite.ctrl.Ctrl.SetAutoIntelDeliveryEnabledForAddressBookEntry(context.Background(), suite.entryID, suite.enabled)
+	suite.Error(err, "should fail")
+}
+
 func (suite *ControllerSetAutoIntelDeliveryEnabledForAddressBookEntrySuite) TestOKEnabled() {
 	suite.enabled = true
 	suite.ctrl.Store.On("SetAutoDeliveryEnabledForAddressBookEntry",
@@ -1109,6 +1136,8 @@ func (suite *ControllerSetAutoIntelDeliveryEnabledForAddressBookEntrySuite) Test
 	suite.ctrl.Notifier.On("NotifyAddressBookEntryAutoDeliveryUpdated",
 		mock.Anything, suite.tx, suite.entryID, suite.enabled).
 		Return(nil)
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByEntry", mock.Anything, suite.tx, suite.entryID, mock.Anything).
+		Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
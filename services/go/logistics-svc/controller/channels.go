@@ -93,6 +93,11 @@ func (c *Controller) UpdateChannelsByAddressBookEntry(ctx context.Context, entry
 				return meh.Wrap(err, "look after affected delivery", meh.Details{"affected_delivery": affectedDelivery})
 			}
 		}
+		// New channels might be usable for other active deliveries as well.
+		err = c.scheduleDeliveryChecksByEntry(ctx, tx, entryID)
+		if err != nil {
+			return meh.Wrap(err, "schedule delivery checks by entry", meh.Details{"entry_id": entryID})
+		}
 		return nil
 	})
 	if err != nil {
@@ -192,6 +192,14 @@ func (suite *ControllerUpdateChannelsByAddressBookEntrySuite) genChannel(id uuid
 
 func (suite *ControllerUpdateChannelsByAddressBookEntrySuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.sampleEntryID = testutil.NewUUIDV4()
 	suite.entry = store.AddressBookEntryDetailed{
 		AddressBookEntry: store.AddressBookEntry{
@@ -555,6 +563,8 @@ func (suite *ControllerUpdateChannelsByAddressBookEntrySuite) TestOK() {
 		suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.ctrl.DB.Tx[0], affectedDelivery).
 			Return(store.IntelDelivery{IsActive: false}, nil).Once()
 	}
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByEntry", timeout, suite.ctrl.DB.Tx[0], suite.sampleEntryID, mock.Anything).
+		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
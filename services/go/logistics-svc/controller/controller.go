@@ -12,6 +12,8 @@ import (
 	"github.com/mobile-directing-system/mds-server/services/go/shared/search"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"sync"
+	"time"
 )
 
 // Controller manages all operations regarding logistics.
@@ -20,13 +22,24 @@ type Controller struct {
 	DB       pgutil.DBTxSupplier
 	Store    Store
 	Notifier Notifier
+	// deliveryChecksWakeUpChan is used for waking up runDeliveryChecks. Use
+	// deliveryChecksWakeUp for access.
+	deliveryChecksWakeUpChan chan struct{}
+	deliveryChecksWakeUpOnce sync.Once
+	// intelExpiriesWakeUpChan is used for waking up runIntelExpiries. Use
+	// intelExpiriesWakeUp for access.
+	intelExpiriesWakeUpChan chan struct{}
+	intelExpiriesWakeUpOnce sync.Once
 }
 
 // Run the controller for periodic checks, etc.
 func (c *Controller) Run(lifetime context.Context) error {
 	eg, egCtx := errgroup.WithContext(lifetime)
 	eg.Go(func() error {
-		return meh.NilOrWrap(c.runPeriodicDeliveryChecks(egCtx), "run periodic delivery checks", nil)
+		return meh.NilOrWrap(c.runDeliveryChecks(egCtx), "run delivery checks", nil)
+	})
+	eg.Go(func() error {
+		return meh.NilOrWrap(c.runIntelExpiries(egCtx), "run intel expiries", nil)
 	})
 	return eg.Wait()
 }
@@ -75,6 +88,9 @@ type Store interface {
 	CreateUser(ctx context.Context, tx pgx.Tx, create store.User) error
 	// UpdateUser updates the given store.User, identified by its id.
 	UpdateUser(ctx context.Context, tx pgx.Tx, update store.User) error
+	// UpdateUserPresence updates the given store.UserPresence, if it is not
+	// outdated.
+	UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence store.UserPresence) error
 	// CreateOperation creates the given store.Operation.
 	CreateOperation(ctx context.Context, tx pgx.Tx, create store.Operation) error
 	// UpdateOperation updates the given store.Operation.
@@ -108,17 +124,33 @@ type Store interface {
 	// attempt with the given id.
 	UpdateIntelDeliveryAttemptStatusByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, newIsActive bool,
 		newStatus store.IntelDeliveryStatus, newNote nulls.String) error
+	// SetIntelDeliveryAttemptAcknowledgedBy sets
+	// store.IntelDeliveryAttempt.AcknowledgedBy for the attempt with the given id.
+	SetIntelDeliveryAttemptAcknowledgedBy(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error
 	// IntelDeliveryAttemptByID retrieves the store.IntelDeliveryAttempt with the
 	// given id.
 	IntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.IntelDeliveryAttempt, error)
 	// NextChannelForDeliveryAttempt retrieves the next channel to try with for the
-	// delivery with the given id. If none was found, the second return value will
-	// be false.
-	NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.Channel, bool, error)
+	// delivery with the given id, respecting the retry policies of channels. The
+	// returned time is the one from which on the attempt should be created. If
+	// none was found, the third return value will be false.
+	NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.Channel, time.Time, bool, error)
+	// ChannelsForFanOutDeliveryAttempts retrieves all channels to use for parallel
+	// delivery attempts right now for the delivery with the given id. The returned
+	// time is the earliest one, another channel becomes usable at, or zero if
+	// there is none. If no more attempts are possible at all, the third return
+	// value will be false.
+	ChannelsForFanOutDeliveryAttempts(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]store.Channel, time.Time, bool, error)
+	// IsFanOutEnabledForIntelDelivery checks whether the delivery with the given id
+	// should be delivered over all usable channels in parallel.
+	IsFanOutEnabledForIntelDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (bool, error)
 	// UpdateIntelDeliveryStatusByDelivery updates the status of the intel delivery
 	// with the given id.
 	UpdateIntelDeliveryStatusByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, newIsActive bool,
 		newSuccess bool, newNote nulls.String) error
+	// UpdateIntelDeliveryDeliverAt updates the store.IntelDelivery.DeliverAt for
+	// the delivery with the given id.
+	UpdateIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error
 	// ActiveIntelDeliveryAttemptsByDelivery retrieves a store.IntelDeliveryAttempt
 	// list with active delivery attempts.
 	ActiveIntelDeliveryAttemptsByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]store.IntelDeliveryAttempt, error)
@@ -142,14 +174,21 @@ type Store interface {
 	// DeleteInactiveIntelDeliveriesFor deletes all inactive intel deliveries for a given address book entry
 	DeleteInactiveIntelDeliveriesFor(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error
 	// LockIntelDeliveryByIDOrWait locks the intel-delivery in the database with the
-	// given id or waits until it is available.
+	// given id or waits until it is available. Deliveries, it was forwarded by, are
+	// locked before.
 	LockIntelDeliveryByIDOrWait(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error
-	// ActiveIntelDeliveriesAndLockOrSkip retrieves all active intel-deliveries and
-	// locks or skips them.
-	ActiveIntelDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx) ([]store.IntelDelivery, error)
 	// InvalidateIntelByID sets the valid-field of the intel with the given id to
 	// false.
 	InvalidateIntelByID(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error
+	// AmendIntel creates a new version of the intel from store.AmendIntel and
+	// marks the amended one as invalid.
+	AmendIntel(ctx context.Context, tx pgx.Tx, amend store.AmendIntel) (store.Intel, error)
+	// IntelVersionsByIntel retrieves all versions of the intel with the given id,
+	// sorted ascending by version.
+	IntelVersionsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]store.Intel, error)
+	// IntelDeliveriesByIntel retrieves the store.IntelDelivery list for the intel
+	// with the given id.
+	IntelDeliveriesByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]store.IntelDelivery, error)
 	// SearchAddressBookEntries with the given AddressBookEntryFilters and
 	// search.Params.
 	SearchAddressBookEntries(ctx context.Context, tx pgx.Tx, filters store.AddressBookEntryFilters,
@@ -157,7 +196,8 @@ type Store interface {
 	// RebuildAddressBookEntrySearch rebuilds the address-book-entry-search.
 	RebuildAddressBookEntrySearch(ctx context.Context, tx pgx.Tx) error
 	// IntelDeliveryByIDAndLockOrWait retrieves the store.IntelDelivery with the
-	// given id and locks it or waits until it is available.
+	// given id and locks it or waits until it is available. Deliveries, it was
+	// forwarded by, are locked before.
 	IntelDeliveryByIDAndLockOrWait(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.IntelDelivery, error)
 	// SearchIntel using the given store.IntelFilters and search.Params.
 	SearchIntel(ctx context.Context, tx pgx.Tx, filters store.IntelFilters, searchParams search.Params) (search.Result[store.Intel], error)
@@ -195,6 +235,84 @@ type Store interface {
 	// SetAutoDeliveryEnabledForAddressBookEntry sets auto intel delivery enabled for
 	// the address book entry with the given id.
 	SetAutoDeliveryEnabledForAddressBookEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, enabled bool) error
+	// ForwardTargetAddressBookEntriesByChannel retrieves the ids of all address
+	// book entries, intel-deliveries over the forward-channel with the given id
+	// should be forwarded to.
+	ForwardTargetAddressBookEntriesByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) ([]uuid.UUID, error)
+	// ForwardChannelQuorumByChannel retrieves the quorum for the forward-channel
+	// with the given id. If none is set, zero is returned.
+	ForwardChannelQuorumByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (int32, error)
+	// LinkIntelDeliveryToForwardingAttempt marks the intel-delivery with the given
+	// id as being forwarded by the intel-delivery-attempt with the given id.
+	LinkIntelDeliveryToForwardingAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, attemptID uuid.UUID) error
+	// ForwardedIntelDeliveriesByAttempt retrieves the store.IntelDelivery list of
+	// deliveries, that were forwarded by the intel-delivery-attempt with the given
+	// id.
+	ForwardedIntelDeliveriesByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) ([]store.IntelDelivery, error)
+	// ForwardingAttemptByDelivery retrieves the store.IntelDeliveryAttempt, the
+	// intel-delivery with the given id was forwarded by. If the delivery was not
+	// forwarded, the second return value will be false.
+	ForwardingAttemptByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.IntelDeliveryAttempt, bool, error)
+	// IntelDeliveryEscalationRulesByOperation retrieves the
+	// store.IntelDeliveryEscalationRule list for the operation with the given id.
+	IntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID) ([]store.IntelDeliveryEscalationRule, error)
+	// UpdateIntelDeliveryEscalationRulesByOperation clears and recreates the
+	// escalation rules for the operation with the given id.
+	UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID,
+		rules []store.IntelDeliveryEscalationRule) error
+	// DueIntelDeliveryEscalationRulesByDelivery retrieves all
+	// store.IntelDeliveryEscalationRule entries with the given trigger, that are
+	// due for the delivery with the given id.
+	DueIntelDeliveryEscalationRulesByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID,
+		trigger store.IntelDeliveryEscalationTrigger) ([]store.IntelDeliveryEscalationRule, error)
+	// CreateIntelDeliveryEscalation creates the given
+	// store.IntelDeliveryEscalation and returns it with its assigned id.
+	CreateIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, create store.IntelDeliveryEscalation) (store.IntelDeliveryEscalation, error)
+	// IntelDeliveryEscalationChainByDelivery retrieves the
+	// store.IntelDeliveryEscalation list, that led to the delivery with the given
+	// id, followed by the escalations of the delivery itself.
+	IntelDeliveryEscalationChainByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]store.IntelDeliveryEscalation, error)
+	// ScheduleIntelDeliveryCheck schedules a check for the active delivery with the
+	// given id at the given time. If a check is already scheduled earlier, it is
+	// kept.
+	ScheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, at time.Time) error
+	// ScheduleIntelDeliveryChecksByEntry schedules checks like
+	// ScheduleIntelDeliveryCheck for all active deliveries to the address book
+	// entry with the given id.
+	ScheduleIntelDeliveryChecksByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, at time.Time) error
+	// ScheduleIntelDeliveryChecksByUser schedules checks like
+	// ScheduleIntelDeliveryCheck for all active deliveries to address book entries,
+	// that are associated with the user with the given id.
+	ScheduleIntelDeliveryChecksByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, at time.Time) error
+	// ScheduleIntelDeliveryChecksByOperation schedules checks like
+	// ScheduleIntelDeliveryCheck for all active deliveries of intel for the
+	// operation with the given id.
+	ScheduleIntelDeliveryChecksByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID, at time.Time) error
+	// ScheduleIntelDeliveryTimeoutCheck schedules a check like
+	// ScheduleIntelDeliveryCheck for the delivery with the given id at the time,
+	// when the next of its active attempts times out or the next open-timeout
+	// escalation rule becomes due.
+	ScheduleIntelDeliveryTimeoutCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error
+	// UnscheduleIntelDeliveryCheck removes the scheduled check for the delivery with
+	// the given id.
+	UnscheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error
+	// ClaimDueIntelDeliveries retrieves the ids of at most the given limit of
+	// active deliveries with checks being due and reschedules their checks after
+	// the given lease.
+	ClaimDueIntelDeliveries(ctx context.Context, tx pgx.Tx, limit int, lease time.Duration) ([]uuid.UUID, error)
+	// NextIntelDeliveryCheck retrieves the earliest time, a check for any active
+	// delivery is scheduled for. If no checks are scheduled, false is returned.
+	NextIntelDeliveryCheck(ctx context.Context, tx pgx.Tx) (time.Time, bool, error)
+	// DueIntelExpiries retrieves the ids of at most the given limit of valid intel,
+	// that is not marked as expired, yet, although store.Intel.ValidUntil was
+	// reached.
+	DueIntelExpiries(ctx context.Context, tx pgx.Tx, limit int) ([]uuid.UUID, error)
+	// MarkIntelAsExpiredIfDue marks the intel with the given id as expired, if it
+	// is due. If the intel was not marked, false is returned.
+	MarkIntelAsExpiredIfDue(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) (bool, error)
+	// NextIntelExpiry retrieves the earliest store.Intel.ValidUntil of all valid
+	// intel, that is not expired, yet. If no intel expires, false is returned.
+	NextIntelExpiry(ctx context.Context, tx pgx.Tx) (time.Time, bool, error)
 }
 
 // Notifier sends event messages.
@@ -212,6 +330,11 @@ type Notifier interface {
 	NotifyIntelCreated(ctx context.Context, tx pgx.Tx, created store.Intel) error
 	// NotifyIntelInvalidated notifies about existing intel being invalidated.
 	NotifyIntelInvalidated(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, by uuid.UUID) error
+	// NotifyIntelAmended notifies about intel being amended by the given new
+	// version.
+	NotifyIntelAmended(ctx context.Context, tx pgx.Tx, newVersion store.Intel) error
+	// NotifyIntelExpired notifies about intel being expired.
+	NotifyIntelExpired(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error
 	// NotifyIntelDeliveryCreated notifies about a created intel-delivery.
 	NotifyIntelDeliveryCreated(ctx context.Context, tx pgx.Tx, created store.IntelDelivery) error
 	// NotifyIntelDeliveryAttemptCreated notifies about a created
@@ -225,7 +348,15 @@ type Notifier interface {
 	// intel-delivery.
 	NotifyIntelDeliveryStatusUpdated(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, newIsActive bool,
 		newSuccess bool, newNote nulls.String) error
+	// NotifyIntelDeliveryDeliverAtUpdated notifies about an updated time to
+	// deliver a scheduled intel-delivery at.
+	NotifyIntelDeliveryDeliverAtUpdated(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error
 	// NotifyAddressBookEntryAutoDeliveryUpdated emits an
 	// event.TypeAddressBookEntryAutoDeliveryUpdated event.
 	NotifyAddressBookEntryAutoDeliveryUpdated(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, isAutoDeliveryEnabled bool) error
+	// NotifyIntelDeliveryEscalated notifies about an escalated intel-delivery for
+	// the given intel. If a delivery to a fallback address book entry was created,
+	// escalatedToEntry is its id.
+	NotifyIntelDeliveryEscalated(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation, intel store.Intel,
+		escalatedToEntry uuid.NullUUID) error
 }
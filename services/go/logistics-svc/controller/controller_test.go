@@ -99,6 +99,10 @@ func (m *StoreMock) UpdateUser(ctx context.Context, tx pgx.Tx, update store.User
 	return m.Called(ctx, tx, update).Error(0)
 }
 
+func (m *StoreMock) UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence store.UserPresence) error {
+	return m.Called(ctx, tx, presence).Error(0)
+}
+
 func (m *StoreMock) DeleteUserByID(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
 	return m.Called(ctx, tx, userID).Error(0)
 }
@@ -170,6 +174,10 @@ func (m *StoreMock) TimedOutIntelDeliveryAttemptsByDelivery(ctx context.Context,
 	return attempts, args.Error(1)
 }
 
+func (m *StoreMock) SetIntelDeliveryAttemptAcknowledgedBy(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, tx, attemptID, by).Error(0)
+}
+
 func (m *StoreMock) UpdateIntelDeliveryAttemptStatusByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID,
 	newIsActive bool, newStatus store.IntelDeliveryStatus, newNote nulls.String) error {
 	return m.Called(ctx, tx, attemptID, newIsActive, newStatus, newNote).Error(0)
@@ -180,9 +188,134 @@ func (m *StoreMock) IntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, att
 	return args.Get(0).(store.IntelDeliveryAttempt), args.Error(1)
 }
 
-func (m *StoreMock) NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.Channel, bool, error) {
+func (m *StoreMock) ForwardTargetAddressBookEntriesByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, channelID)
+	var entries []uuid.UUID
+	entries, _ = args.Get(0).([]uuid.UUID)
+	return entries, args.Error(1)
+}
+
+func (m *StoreMock) ForwardChannelQuorumByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (int32, error) {
+	args := m.Called(ctx, tx, channelID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *StoreMock) LinkIntelDeliveryToForwardingAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, attemptID uuid.UUID) error {
+	return m.Called(ctx, tx, deliveryID, attemptID).Error(0)
+}
+
+func (m *StoreMock) ForwardedIntelDeliveriesByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) ([]store.IntelDelivery, error) {
+	args := m.Called(ctx, tx, attemptID)
+	var deliveries []store.IntelDelivery
+	deliveries, _ = args.Get(0).([]store.IntelDelivery)
+	return deliveries, args.Error(1)
+}
+
+func (m *StoreMock) ForwardingAttemptByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.IntelDeliveryAttempt, bool, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	return args.Get(0).(store.IntelDeliveryAttempt), args.Bool(1), args.Error(2)
+}
+
+func (m *StoreMock) IntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID) ([]store.IntelDeliveryEscalationRule, error) {
+	args := m.Called(ctx, tx, operationID)
+	var rules []store.IntelDeliveryEscalationRule
+	rules, _ = args.Get(0).([]store.IntelDeliveryEscalationRule)
+	return rules, args.Error(1)
+}
+
+func (m *StoreMock) UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID,
+	rules []store.IntelDeliveryEscalationRule) error {
+	return m.Called(ctx, tx, operationID, rules).Error(0)
+}
+
+func (m *StoreMock) DueIntelDeliveryEscalationRulesByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID,
+	trigger store.IntelDeliveryEscalationTrigger) ([]store.IntelDeliveryEscalationRule, error) {
+	args := m.Called(ctx, tx, deliveryID, trigger)
+	var rules []store.IntelDeliveryEscalationRule
+	rules, _ = args.Get(0).([]store.IntelDeliveryEscalationRule)
+	return rules, args.Error(1)
+}
+
+func (m *StoreMock) CreateIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, create store.IntelDeliveryEscalation) (store.IntelDeliveryEscalation, error) {
+	args := m.Called(ctx, tx, create)
+	return args.Get(0).(store.IntelDeliveryEscalation), args.Error(1)
+}
+
+func (m *StoreMock) IntelDeliveryEscalationChainByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]store.IntelDeliveryEscalation, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	var chain []store.IntelDeliveryEscalation
+	chain, _ = args.Get(0).([]store.IntelDeliveryEscalation)
+	return chain, args.Error(1)
+}
+
+func (m *StoreMock) ScheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, at time.Time) error {
+	return m.Called(ctx, tx, deliveryID, at).Error(0)
+}
+
+func (m *StoreMock) ScheduleIntelDeliveryChecksByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, at time.Time) error {
+	return m.Called(ctx, tx, entryID, at).Error(0)
+}
+
+func (m *StoreMock) ScheduleIntelDeliveryChecksByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, at time.Time) error {
+	return m.Called(ctx, tx, userID, at).Error(0)
+}
+
+func (m *StoreMock) ScheduleIntelDeliveryChecksByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID, at time.Time) error {
+	return m.Called(ctx, tx, operationID, at).Error(0)
+}
+
+func (m *StoreMock) ScheduleIntelDeliveryTimeoutCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	return m.Called(ctx, tx, deliveryID).Error(0)
+}
+
+func (m *StoreMock) UnscheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	return m.Called(ctx, tx, deliveryID).Error(0)
+}
+
+func (m *StoreMock) ClaimDueIntelDeliveries(ctx context.Context, tx pgx.Tx, limit int, lease time.Duration) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, limit, lease)
+	var deliveryIDs []uuid.UUID
+	deliveryIDs, _ = args.Get(0).([]uuid.UUID)
+	return deliveryIDs, args.Error(1)
+}
+
+func (m *StoreMock) NextIntelDeliveryCheck(ctx context.Context, tx pgx.Tx) (time.Time, bool, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
+func (m *StoreMock) DueIntelExpiries(ctx context.Context, tx pgx.Tx, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, limit)
+	var intelIDs []uuid.UUID
+	intelIDs, _ = args.Get(0).([]uuid.UUID)
+	return intelIDs, args.Error(1)
+}
+
+func (m *StoreMock) MarkIntelAsExpiredIfDue(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tx, intelID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *StoreMock) NextIntelExpiry(ctx context.Context, tx pgx.Tx) (time.Time, bool, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
+func (m *StoreMock) NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (store.Channel, time.Time, bool, error) {
 	args := m.Called(ctx, tx, deliveryID)
-	return args.Get(0).(store.Channel), args.Bool(1), args.Error(2)
+	return args.Get(0).(store.Channel), args.Get(1).(time.Time), args.Bool(2), args.Error(3)
+}
+
+func (m *StoreMock) ChannelsForFanOutDeliveryAttempts(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]store.Channel, time.Time, bool, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	var channels []store.Channel
+	channels, _ = args.Get(0).([]store.Channel)
+	return channels, args.Get(1).(time.Time), args.Bool(2), args.Error(3)
+}
+
+func (m *StoreMock) IsFanOutEnabledForIntelDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *StoreMock) UpdateIntelDeliveryStatusByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID,
@@ -190,6 +323,10 @@ func (m *StoreMock) UpdateIntelDeliveryStatusByDelivery(ctx context.Context, tx
 	return m.Called(ctx, tx, deliveryID, newIsActive, newSuccess, newNote).Error(0)
 }
 
+func (m *StoreMock) UpdateIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	return m.Called(ctx, tx, deliveryID, deliverAt).Error(0)
+}
+
 func (m *StoreMock) ActiveIntelDeliveryAttemptsByDelivery(ctx context.Context, tx pgx.Tx,
 	deliveryID uuid.UUID) ([]store.IntelDeliveryAttempt, error) {
 	args := m.Called(ctx, tx, deliveryID)
@@ -237,8 +374,26 @@ func (m *StoreMock) LockIntelDeliveryByIDOrWait(ctx context.Context, tx pgx.Tx,
 	return m.Called(ctx, tx, deliveryID).Error(0)
 }
 
-func (m *StoreMock) ActiveIntelDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx) ([]store.IntelDelivery, error) {
-	args := m.Called(ctx, tx)
+func (m *StoreMock) InvalidateIntelByID(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	return m.Called(ctx, tx, intelID).Error(0)
+}
+
+func (m *StoreMock) AmendIntel(ctx context.Context, tx pgx.Tx, amend store.AmendIntel) (store.Intel, error) {
+	args := m.Called(ctx, tx, amend)
+	return args.Get(0).(store.Intel), args.Error(1)
+}
+
+func (m *StoreMock) IntelVersionsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]store.Intel, error) {
+	args := m.Called(ctx, tx, intelID)
+	var versions []store.Intel
+	if a := args.Get(0); a != nil {
+		versions = a.([]store.Intel)
+	}
+	return versions, args.Error(1)
+}
+
+func (m *StoreMock) IntelDeliveriesByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]store.IntelDelivery, error) {
+	args := m.Called(ctx, tx, intelID)
 	var deliveries []store.IntelDelivery
 	if a := args.Get(0); a != nil {
 		deliveries = a.([]store.IntelDelivery)
@@ -246,10 +401,6 @@ func (m *StoreMock) ActiveIntelDeliveriesAndLockOrSkip(ctx context.Context, tx p
 	return deliveries, args.Error(1)
 }
 
-func (m *StoreMock) InvalidateIntelByID(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
-	return m.Called(ctx, tx, intelID).Error(0)
-}
-
 func (m *StoreMock) SearchAddressBookEntries(ctx context.Context, tx pgx.Tx, filters store.AddressBookEntryFilters,
 	searchParams search.Params) (search.Result[store.AddressBookEntryDetailed], error) {
 	args := m.Called(ctx, tx, filters, searchParams)
@@ -372,6 +523,10 @@ func (m *NotifierMock) NotifyIntelDeliveryStatusUpdated(ctx context.Context, tx
 	return m.Called(ctx, tx, deliveryID, newIsActive, newSuccess, newNote).Error(0)
 }
 
+func (m *NotifierMock) NotifyIntelDeliveryDeliverAtUpdated(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	return m.Called(ctx, tx, deliveryID, deliverAt).Error(0)
+}
+
 func (m *NotifierMock) NotifyIntelCreated(ctx context.Context, tx pgx.Tx, created store.Intel) error {
 	return m.Called(ctx, tx, created).Error(0)
 }
@@ -380,6 +535,19 @@ func (m *NotifierMock) NotifyIntelInvalidated(ctx context.Context, tx pgx.Tx, in
 	return m.Called(ctx, tx, intelID, by).Error(0)
 }
 
+func (m *NotifierMock) NotifyIntelExpired(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	return m.Called(ctx, tx, intelID).Error(0)
+}
+
+func (m *NotifierMock) NotifyIntelAmended(ctx context.Context, tx pgx.Tx, newVersion store.Intel) error {
+	return m.Called(ctx, tx, newVersion).Error(0)
+}
+
 func (m *NotifierMock) NotifyAddressBookEntryAutoDeliveryUpdated(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, isAutoDeliveryEnabled bool) error {
 	return m.Called(ctx, tx, entryID, isAutoDeliveryEnabled).Error(0)
 }
+
+func (m *NotifierMock) NotifyIntelDeliveryEscalated(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation,
+	intel store.Intel, escalatedToEntry uuid.NullUUID) error {
+	return m.Called(ctx, tx, escalation, intel, escalatedToEntry).Error(0)
+}
@@ -41,7 +41,14 @@ func (c *Controller) CreateIntel(ctx context.Context, create store.CreateIntel)
 			return meh.Wrap(err, "create in store", meh.Details{"create": create})
 		}
 		// Schedule initial deliveries.
-		err = c.scheduleDeliveriesForIntel(ctx, tx, created.ID, create.InitialDeliverTo)
+		recipients := make([]intelDeliveryRecipient, 0, len(create.InitialDeliverTo))
+		for _, entryID := range create.InitialDeliverTo {
+			recipients = append(recipients, intelDeliveryRecipient{
+				entry:     entryID,
+				deliverAt: create.InitialDeliverAt,
+			})
+		}
+		err = c.scheduleDeliveriesForIntel(ctx, tx, created.ID, recipients)
 		if err != nil {
 			return meh.Wrap(err, "schedule intel delivery", meh.Details{"intel_id": created.ID})
 		}
@@ -55,6 +62,9 @@ func (c *Controller) CreateIntel(ctx context.Context, create store.CreateIntel)
 	if err != nil {
 		return store.Intel{}, meh.Wrap(err, "run in tx", nil)
 	}
+	if created.ValidUntil.Valid {
+		c.wakeUpIntelExpiries()
+	}
 	return created, nil
 
 }
@@ -193,6 +203,23 @@ func (c *Controller) RebuildIntelSearch(ctx context.Context) {
 	c.Logger.Debug("intel-search rebuilt", zap.Duration("took", time.Since(start)))
 }
 
+// assureIntelVisibleForUser checks all deliveries for the intel with the given
+// id for target-address-book-entries being associated with the user with the
+// given id. If none is found, a meh.ErrForbidden is returned.
+func (c *Controller) assureIntelVisibleForUser(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, userID uuid.UUID) error {
+	associatedUserIDs, err := c.Store.UsersWithDeliveriesByIntel(ctx, tx, intelID)
+	if err != nil {
+		return meh.Wrap(err, "users with deliveries by intel from store", meh.Details{"intel_id": intelID})
+	}
+	for _, associatedUser := range associatedUserIDs {
+		if userID == associatedUser {
+			return nil
+		}
+	}
+	return meh.NewForbiddenErr("user has no associated target-delivery-address-book-entries",
+		meh.Details{"user_id": userID})
+}
+
 // IntelByID retrieves the store.Intel with the given id. If limit-to-user is
 // set, all deliveries for the intel are checked for target-address-book-entries
 // being associated with the user with the given id.
@@ -202,21 +229,12 @@ func (c *Controller) IntelByID(ctx context.Context, intelID uuid.UUID, limitToUs
 		var err error
 		// Assure allowed to retrieve.
 		if limitToUser.Valid {
-			associatedUserIDs, err := c.Store.UsersWithDeliveriesByIntel(ctx, tx, intelID)
+			err = c.assureIntelVisibleForUser(ctx, tx, intelID, limitToUser.UUID)
 			if err != nil {
-				return meh.Wrap(err, "users with deliveries by intel from store", meh.Details{"intel_id": intelID})
-			}
-			ok := false
-			for _, associatedUser := range associatedUserIDs {
-				if limitToUser.UUID != associatedUser {
-					continue
-				}
-				ok = true
-				break
-			}
-			if !ok {
-				return meh.NewForbiddenErr("user has no associated target-delivery-address-book-entries",
-					meh.Details{"user_id": limitToUser.UUID})
+				return meh.Wrap(err, "assure intel visible for user", meh.Details{
+					"intel_id": intelID,
+					"user_id":  limitToUser.UUID,
+				})
 			}
 		}
 		// Retrieve intel.
@@ -274,3 +292,21 @@ func (c *Controller) Intel(ctx context.Context, intelFilters store.IntelFilters,
 	}
 	return result, nil
 }
+
+// OperationByIntel retrieves the id of the operation, the intel with the given
+// id is assigned to.
+func (c *Controller) OperationByIntel(ctx context.Context, intelID uuid.UUID) (uuid.UUID, error) {
+	var operationID uuid.UUID
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		intel, err := c.Store.IntelByID(ctx, tx, intelID)
+		if err != nil {
+			return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": intelID})
+		}
+		operationID = intel.Operation
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return operationID, nil
+}
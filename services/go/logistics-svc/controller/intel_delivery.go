@@ -6,7 +6,6 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
-	"github.com/lefinal/meh/mehlog"
 	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
@@ -15,79 +14,28 @@ import (
 	"time"
 )
 
-const periodicDeliveryChecksInterval = 30 * time.Second
-const periodicDeliveryChecksDurationWarnThreshold = 1 * time.Second
-
-func (c *Controller) runPeriodicDeliveryChecks(lifetime context.Context) error {
-	runCheck := func() error {
-		err := pgutil.RunInTx(lifetime, c.DB, func(ctx context.Context, tx pgx.Tx) error {
-			activeLockedDeliveries, err := c.Store.ActiveIntelDeliveriesAndLockOrSkip(ctx, tx)
-			if err != nil {
-				return meh.Wrap(err, "active intel-deliveries from store and lock or skip", nil)
-			}
-			// Concurrency not possible because of locking and limited connections. If this
-			// reveals to be a bottleneck, we need to change this behavior.
-			for _, delivery := range activeLockedDeliveries {
-				err = c.lookAfterDelivery(ctx, tx, delivery.ID)
-				if err != nil {
-					return meh.Wrap(err, "look after delivery", meh.Details{"delivery_id": delivery.ID})
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			return meh.Wrap(err, "run in tx", nil)
-		}
-		return nil
-	}
-	for {
-		start := time.Now()
-		err := runCheck()
-		if err != nil {
-			mehlog.Log(c.Logger, meh.Wrap(err, "run periodic delivery checks", nil))
-		} else if took := time.Since(start); took > periodicDeliveryChecksDurationWarnThreshold {
-			c.Logger.Warn("periodic delivery checks took longer than expected",
-				zap.Duration("took", took),
-				zap.Duration("warn_threshold", periodicDeliveryChecksDurationWarnThreshold))
-		}
-		// Wait.
-		select {
-		case <-lifetime.Done():
-			return nil
-		case <-time.After(periodicDeliveryChecksInterval):
-		}
-	}
+// intelDeliveryRecipient is an address book entry to create an intel-delivery
+// for in scheduleDeliveriesForIntel.
+type intelDeliveryRecipient struct {
+	// entry is the id of the recipient address book entry.
+	entry uuid.UUID
+	// deliverAt is the optional store.IntelDelivery.DeliverAt.
+	deliverAt nulls.Time
 }
 
 // scheduleDeliveriesForIntel schedules intel-deliveries for the intel with the
 // given id.
-func (c *Controller) scheduleDeliveriesForIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, recipientEntries []uuid.UUID) error {
+func (c *Controller) scheduleDeliveriesForIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, recipients []intelDeliveryRecipient) error {
 	// Assure intel exists.
 	_, err := c.Store.IntelByID(ctx, tx, intelID)
 	if err != nil {
 		return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": intelID})
 	}
 	// Create deliveries.
-	for _, entryID := range recipientEntries {
-		// Create.
-		deliveryToCreate := store.IntelDelivery{
-			Intel:    intelID,
-			To:       entryID,
-			IsActive: true,
-			Success:  false,
-		}
-		createdDelivery, err := c.Store.CreateIntelDelivery(ctx, tx, deliveryToCreate)
+	for _, recipient := range recipients {
+		createdDelivery, err := c.createIntelDelivery(ctx, tx, intelID, recipient.entry, recipient.deliverAt)
 		if err != nil {
-			return meh.Wrap(err, "create intel-delivery in store", meh.Details{"create": deliveryToCreate})
-		}
-		err = c.Notifier.NotifyIntelDeliveryCreated(ctx, tx, createdDelivery)
-		if err != nil {
-			return meh.Wrap(err, "notify intel-delivery created", meh.Details{"created": createdDelivery})
-		}
-		// Lock and look after.
-		err = c.Store.LockIntelDeliveryByIDOrSkip(ctx, tx, createdDelivery.ID)
-		if err != nil {
-			return meh.Wrap(err, "lock created intel-delivery in store", meh.Details{"delivery_id": deliveryToCreate.ID})
+			return meh.Wrap(err, "create intel-delivery", meh.Details{"entry_id": recipient.entry})
 		}
 		err = c.lookAfterDelivery(ctx, tx, createdDelivery.ID)
 		if err != nil {
@@ -97,6 +45,34 @@ func (c *Controller) scheduleDeliveriesForIntel(ctx context.Context, tx pgx.Tx,
 	return nil
 }
 
+// createIntelDelivery creates and notifies about an active intel-delivery for
+// the intel with the given id to the given address book entry. If deliverAt is
+// set, no attempts are created before. The created delivery is locked, but
+// lookAfterDelivery is NOT called.
+func (c *Controller) createIntelDelivery(ctx context.Context, tx pgx.Tx, intelID uuid.UUID, entryID uuid.UUID,
+	deliverAt nulls.Time) (store.IntelDelivery, error) {
+	deliveryToCreate := store.IntelDelivery{
+		Intel:     intelID,
+		To:        entryID,
+		IsActive:  true,
+		Success:   false,
+		DeliverAt: deliverAt,
+	}
+	createdDelivery, err := c.Store.CreateIntelDelivery(ctx, tx, deliveryToCreate)
+	if err != nil {
+		return store.IntelDelivery{}, meh.Wrap(err, "create intel-delivery in store", meh.Details{"create": deliveryToCreate})
+	}
+	err = c.Notifier.NotifyIntelDeliveryCreated(ctx, tx, createdDelivery)
+	if err != nil {
+		return store.IntelDelivery{}, meh.Wrap(err, "notify intel-delivery created", meh.Details{"created": createdDelivery})
+	}
+	err = c.Store.LockIntelDeliveryByIDOrSkip(ctx, tx, createdDelivery.ID)
+	if err != nil {
+		return store.IntelDelivery{}, meh.Wrap(err, "lock created intel-delivery in store", meh.Details{"delivery_id": createdDelivery.ID})
+	}
+	return createdDelivery, nil
+}
+
 // lookAfterDelivery checks the intel-delivery with the given id. It creates and
 // notifies about new attempts as required, timeouts and all other stuff that is
 // relevant for the delivery.
@@ -113,18 +89,50 @@ func (c *Controller) lookAfterDelivery(ctx context.Context, tx pgx.Tx, deliveryI
 			zap.Any("delivery_id", deliveryID))
 		return nil
 	}
+	intel, err := c.Store.IntelByID(ctx, tx, delivery.Intel)
+	if err != nil {
+		return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": delivery.Intel})
+	}
+	if !intel.IsValid || intel.IsExpired {
+		// Deliveries for invalidated or expired intel are canceled, so we must not
+		// create any further attempts for them.
+		return nil
+	}
+	if isIntelDeliveryScheduled(delivery) {
+		// Look after the delivery again, when it is due. Escalation rules for being
+		// open too long only apply afterwards, so we do not need to check for them.
+		err = c.scheduleDeliveryCheck(ctx, tx, deliveryID, delivery.DeliverAt.Time)
+		if err != nil {
+			return meh.Wrap(err, "schedule delivery check for deliver-at", meh.Details{
+				"delivery_id": deliveryID,
+				"deliver_at":  delivery.DeliverAt.Time,
+			})
+		}
+		return nil
+	}
 	// First, we check for timed out attempts.
 	err = c.handleTimedOutDeliveryAttempts(ctx, tx, deliveryID)
 	if err != nil {
 		return meh.Wrap(err, "handle timed out delivery attempts for delivery", meh.Details{"delivery_id": deliveryID})
 	}
+	// Check again when the next attempt times out or the delivery is open for too
+	// long.
+	err = c.scheduleDeliveryTimeoutCheck(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "schedule delivery timeout check", meh.Details{"delivery_id": deliveryID})
+	}
 	// Second, we check if there are still attempts ongoing, as then, we can skip
-	// further processing.
+	// further processing. However, with fan-out, attempts are made in parallel, so
+	// we still need to look for further channels.
 	activeAttempts, err := c.Store.ActiveIntelDeliveryAttemptsByDelivery(ctx, tx, deliveryID)
 	if err != nil {
 		return meh.Wrap(err, "active delivery attempts by delivery from store", meh.Details{"delivery_id": deliveryID})
 	}
-	if len(activeAttempts) > 0 {
+	isFanOutEnabled, err := c.Store.IsFanOutEnabledForIntelDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "check if fan-out enabled for intel delivery in store", meh.Details{"delivery_id": deliveryID})
+	}
+	if len(activeAttempts) > 0 && !isFanOutEnabled {
 		return nil
 	}
 	// No attempts are active anymore, so we check if auto-delivery is enabled for
@@ -137,8 +145,15 @@ func (c *Controller) lookAfterDelivery(ctx context.Context, tx pgx.Tx, deliveryI
 		// Nothing to do.
 		return nil
 	}
+	if isFanOutEnabled {
+		err = c.lookAfterFanOutDelivery(ctx, tx, deliveryID, len(activeAttempts) > 0)
+		if err != nil {
+			return meh.Wrap(err, "look after fan-out delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	}
 	// Check for the next channel, that could be used for the next delivery attempt.
-	nextChannel, ok, err := c.Store.NextChannelForDeliveryAttempt(ctx, tx, deliveryID)
+	nextChannel, notBefore, ok, err := c.Store.NextChannelForDeliveryAttempt(ctx, tx, deliveryID)
 	if err != nil {
 		return meh.Wrap(err, "next channel for delivery attempt from store", meh.Details{"delivery_id": deliveryID})
 	}
@@ -151,25 +166,107 @@ func (c *Controller) lookAfterDelivery(ctx context.Context, tx pgx.Tx, deliveryI
 		}
 		return nil
 	}
+	if notBefore.After(time.Now()) {
+		// The channel is retried, but its backoff did not elapse, yet, or it is not
+		// available, yet. We wait instead of falling through to lower-priority
+		// channels or failing and look after the delivery again, when the channel
+		// becomes usable.
+		err = c.scheduleDeliveryCheck(ctx, tx, deliveryID, notBefore)
+		if err != nil {
+			return meh.Wrap(err, "schedule delivery check for when channel becomes usable", meh.Details{
+				"delivery_id": deliveryID,
+				"not_before":  notBefore,
+			})
+		}
+		return nil
+	}
 	// Create attempt with this channel.
-	_, err = c.createIntelDeliveryAttempt(ctx, tx, delivery.ID, nextChannel.ID)
+	createdAttempt, err := c.createIntelDeliveryAttempt(ctx, tx, delivery.ID, nextChannel)
 	if err != nil {
 		return meh.Wrap(err, "create intel delivery attempt", meh.Details{
 			"delivery_id":     deliveryID,
 			"next_channel_id": nextChannel.ID,
 		})
 	}
+	if !createdAttempt.IsActive {
+		// The attempt failed right away, so we look for the next channel.
+		err = c.lookAfterDelivery(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "look after delivery after attempt failed immediately", meh.Details{"delivery_id": deliveryID})
+		}
+	}
+	return nil
+}
+
+// lookAfterFanOutDelivery creates attempts for all channels that can be used
+// for the delivery with the given id right now. Remaining active attempts are
+// canceled as soon as one attempt is delivered using
+// MarkIntelDeliveryAndAttemptAsDelivered. It is only meant to be used in
+// lookAfterDelivery and kept separate for better readability.
+func (c *Controller) lookAfterFanOutDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, hasActiveAttempts bool) error {
+	channels, nextUsableLater, ok, err := c.Store.ChannelsForFanOutDeliveryAttempts(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "channels for fan-out delivery attempts from store", meh.Details{"delivery_id": deliveryID})
+	}
+	if !ok {
+		if hasActiveAttempts {
+			// Wait for the active attempts to finish.
+			return nil
+		}
+		// No more attempts possible. We mark delivery as failed.
+		err = c.markDeliveryAsFailed(ctx, tx, deliveryID, "no more channels to try")
+		if err != nil {
+			return meh.Wrap(err, "mark delivery as failed because of no more attempts possible",
+				meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	}
+	// Channels, that are not usable, yet, are tried when they become usable.
+	if !nextUsableLater.IsZero() {
+		err = c.scheduleDeliveryCheck(ctx, tx, deliveryID, nextUsableLater)
+		if err != nil {
+			return meh.Wrap(err, "schedule delivery check for when next channel becomes usable", meh.Details{
+				"delivery_id":       deliveryID,
+				"next_usable_later": nextUsableLater,
+			})
+		}
+	}
+	hasFailedAttempts := false
+	for _, channel := range channels {
+		createdAttempt, err := c.createIntelDeliveryAttempt(ctx, tx, deliveryID, channel)
+		if err != nil {
+			return meh.Wrap(err, "create intel delivery attempt", meh.Details{
+				"delivery_id": deliveryID,
+				"channel_id":  channel.ID,
+			})
+		}
+		if !createdAttempt.IsActive {
+			hasFailedAttempts = true
+		}
+	}
+	// Attempts, that failed right away, are only handled after all attempts have
+	// been created. Otherwise, looking after the delivery would compute the
+	// channels to use while we are still creating attempts for them.
+	if hasFailedAttempts {
+		err = c.lookAfterDelivery(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "look after delivery after attempts failed immediately", meh.Details{"delivery_id": deliveryID})
+		}
+	}
 	return nil
 }
 
 // createIntelDeliveryAttempt creates and notifies about the given
-// store.IntelDeliveryAttempt. If the delivery is inactive, a meh.ErrBadInput
-// will be returned. Keep in mind, that we will not check, whether other attempts
-// are ongoing/active.
-func (c *Controller) createIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, channelID uuid.UUID) (store.IntelDeliveryAttempt, error) {
+// store.IntelDeliveryAttempt. If the delivery is inactive or the intel is
+// invalid or expired, a meh.ErrBadInput will be returned. Keep in mind, that we
+// will not check, whether other attempts are ongoing/active. If the channel is a
+// forward-channel, the attempt is forwarded using forwardIntelDeliveryAttempt.
+// As forwarding may fail right away, the caller is responsible for looking after
+// the delivery, if the returned attempt is not active anymore.
+func (c *Controller) createIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, channel store.Channel) (store.IntelDeliveryAttempt, error) {
 	attemptToCreate := store.IntelDeliveryAttempt{
 		Delivery:  deliveryID,
-		Channel:   channelID,
+		Channel:   channel.ID,
 		CreatedAt: time.Now(),
 		IsActive:  true,
 		Status:    store.IntelDeliveryStatusOpen,
@@ -183,13 +280,24 @@ func (c *Controller) createIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx,
 	if !delivery.IsActive {
 		return store.IntelDeliveryAttempt{}, meh.NewBadInputErr("delivery inactive", meh.Details{"delivery": delivery})
 	}
+	intel, err := c.Store.IntelByID(ctx, tx, delivery.Intel)
+	if err != nil {
+		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": delivery.Intel})
+	}
+	if !intel.IsValid || intel.IsExpired {
+		return store.IntelDeliveryAttempt{}, meh.NewBadInputErr("intel invalid or expired", meh.Details{
+			"intel_id":   intel.ID,
+			"is_valid":   intel.IsValid,
+			"is_expired": intel.IsExpired,
+		})
+	}
 	createdAttempt, err := c.Store.CreateIntelDeliveryAttempt(ctx, tx, attemptToCreate)
 	if err != nil {
 		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "create intel delivery attempt", meh.Details{"to_create": attemptToCreate})
 	}
-	intel, err := c.Store.IntelByID(ctx, tx, delivery.Intel)
+	err = c.scheduleDeliveryTimeoutCheck(ctx, tx, deliveryID)
 	if err != nil {
-		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": delivery.Intel})
+		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "schedule delivery timeout check", meh.Details{"delivery_id": deliveryID})
 	}
 	assignedEntry, err := c.Store.AddressBookEntryByID(ctx, tx, delivery.To, uuid.NullUUID{})
 	if err != nil {
@@ -199,7 +307,20 @@ func (c *Controller) createIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx,
 	if err != nil {
 		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "notify intel delivery attempt created", meh.Details{"created": createdAttempt})
 	}
-	return createdAttempt, nil
+	if !isForwardChannelType(channel.Type) {
+		return createdAttempt, nil
+	}
+	// Forward.
+	err = c.forwardIntelDeliveryAttempt(ctx, tx, createdAttempt, delivery)
+	if err != nil {
+		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "forward intel delivery attempt", meh.Details{"attempt": createdAttempt})
+	}
+	forwardedAttempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, createdAttempt.ID)
+	if err != nil {
+		return store.IntelDeliveryAttempt{}, meh.Wrap(err, "forwarded intel delivery attempt by id from store",
+			meh.Details{"attempt_id": createdAttempt.ID})
+	}
+	return forwardedAttempt, nil
 }
 
 // CreateIntelDeliveryAttempt schedules a delivery attempt for the delivery with
@@ -221,13 +342,23 @@ func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, deliveryID
 				meh.Details{"active_attempts": len(activeAttempts)})
 		}
 		// Create.
-		createdAttempt, err = c.createIntelDeliveryAttempt(ctx, tx, deliveryID, channelID)
+		channel, err := c.Store.ChannelMetadataByID(ctx, tx, channelID)
+		if err != nil {
+			return meh.Wrap(err, "channel metadata by id from store", meh.Details{"channel_id": channelID})
+		}
+		createdAttempt, err = c.createIntelDeliveryAttempt(ctx, tx, deliveryID, channel)
 		if err != nil {
 			return meh.Wrap(err, "create intel delivery attempt", meh.Details{
 				"delivery_id": deliveryID,
 				"channel_id":  channelID,
 			})
 		}
+		if !createdAttempt.IsActive {
+			err = c.lookAfterDelivery(ctx, tx, deliveryID)
+			if err != nil {
+				return meh.Wrap(err, "look after delivery after attempt failed immediately", meh.Details{"delivery_id": deliveryID})
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -237,7 +368,8 @@ func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, deliveryID
 }
 
 // markDeliveryAsFailed marks the delivery with the given id as failed and
-// notifies about the updated status.
+// notifies about the updated status. Afterwards, the delivery is escalated
+// using escalateIntelDelivery.
 //
 // Warning: Only call this when there are no more active delivery-attempts as
 // this will NOT be checked by markDeliveryAsFailed!
@@ -262,6 +394,14 @@ func (c *Controller) markDeliveryAsFailed(ctx context.Context, tx pgx.Tx, delive
 			"new_success":   newSuccess,
 		})
 	}
+	err = c.lookAfterForwardingAttempt(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "look after forwarding attempt", meh.Details{"delivery_id": deliveryID})
+	}
+	err = c.escalateIntelDelivery(ctx, tx, deliveryID, store.IntelDeliveryEscalationTriggerFailed)
+	if err != nil {
+		return meh.Wrap(err, "escalate failed intel-delivery", meh.Details{"delivery_id": deliveryID})
+	}
 	return nil
 }
 
@@ -297,6 +437,10 @@ func (c *Controller) handleTimedOutDeliveryAttempts(ctx context.Context, tx pgx.
 		if err != nil {
 			return meh.Wrap(err, "notify intel delivery attempt status updated", meh.Details{"updated_attempt": updatedAttempt})
 		}
+		err = c.cancelForwardedDeliveries(ctx, tx, timedOutAttempt.ID, "canceled because of forwarding attempt timing out")
+		if err != nil {
+			return meh.Wrap(err, "cancel forwarded deliveries for timed out attempt", meh.Details{"attempt_id": timedOutAttempt.ID})
+		}
 		// TODO: notify for manual delviery
 	}
 	return nil
@@ -327,6 +471,10 @@ func (c *Controller) handleDeletedChannelsForDeliveryAttempts(ctx context.Contex
 		if err != nil {
 			return nil, meh.Wrap(err, "notify intel delivery attempt status updated", meh.Details{"attempt": activeAttempt})
 		}
+		err = c.cancelForwardedDeliveries(ctx, tx, activeAttempt.ID, "canceled because of forwarding channel deletion")
+		if err != nil {
+			return nil, meh.Wrap(err, "cancel forwarded deliveries", meh.Details{"attempt_id": activeAttempt.ID})
+		}
 		affectedDeliveries[activeAttempt.Delivery] = struct{}{}
 	}
 	// Delete for all channels.
@@ -431,6 +579,48 @@ func (c *Controller) MarkIntelDeliveryAttemptAsDeliveredTx(ctx context.Context,
 	return nil
 }
 
+// AcknowledgeIntelDeliveryAttempt records the user with the given id as having
+// acknowledged the intel-delivery-attempt and marks it as delivered. The user is
+// expected to be verified by the channel. If the attempt is not active anymore,
+// nothing is done.
+func (c *Controller) AcknowledgeIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error {
+	attempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		return meh.Wrap(err, "intel-delivery-attempt by id from store", meh.Details{"attempt_id": attemptID})
+	}
+	err = c.Store.LockIntelDeliveryByIDOrWait(ctx, tx, attempt.Delivery)
+	if err != nil {
+		return meh.Wrap(err, "lock intel-delivery by id or wait in store", meh.Details{"delivery_id": attempt.Delivery})
+	}
+	// Assure still active.
+	attempt, err = c.Store.IntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		return meh.Wrap(err, "intel-delivery-attempt by id from store (after locked delivery)",
+			meh.Details{"attempt_id": attemptID})
+	}
+	if !attempt.IsActive {
+		c.Logger.Debug("skipping acknowledgement of intel-delivery-attempt due to not being active anymore",
+			zap.Any("attempt_id", attemptID),
+			zap.Any("by", by))
+		return nil
+	}
+	err = c.Store.SetIntelDeliveryAttemptAcknowledgedBy(ctx, tx, attemptID, by)
+	if err != nil {
+		return meh.Wrap(err, "set intel-delivery-attempt acknowledged by in store", meh.Details{
+			"attempt_id": attemptID,
+			"by":         by,
+		})
+	}
+	err = c.MarkIntelDeliveryAndAttemptAsDelivered(ctx, tx, attempt.Delivery, nulls.NewUUID(attemptID), uuid.NullUUID{})
+	if err != nil {
+		return meh.Wrap(err, "mark intel delivery and attempt as delivered", meh.Details{
+			"delivery_id": attempt.Delivery,
+			"attempt_id":  attemptID,
+		})
+	}
+	return nil
+}
+
 // MarkIntelDeliveryAsDelivered is a shortcut for
 // MarkIntelDeliveryAndAttemptAsDelivered.
 func (c *Controller) MarkIntelDeliveryAsDelivered(ctx context.Context, deliveryID uuid.UUID, by uuid.NullUUID) error {
@@ -474,14 +664,30 @@ func (c *Controller) MarkIntelDeliveryAttemptAsFailed(ctx context.Context, tx pg
 		return nil
 	}
 	// Mark as failed.
-	newStatus := store.IntelDeliveryStatusFailed
-	newNote := note
-	err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attemptID, false, newStatus, newNote)
+	err = c.markIntelDeliveryAttemptAsFailed(ctx, tx, attemptID, note)
+	if err != nil {
+		return meh.Wrap(err, "mark intel-delivery-attempt as failed", meh.Details{"attempt_id": attemptID})
+	}
+	err = c.lookAfterDelivery(ctx, tx, attempt.Delivery)
+	if err != nil {
+		return meh.Wrap(err, "look after delivery", meh.Details{"delivery_id": attempt.Delivery})
+	}
+	return nil
+}
+
+// markIntelDeliveryAttemptAsFailed marks the intel-delivery-attempt with the
+// given id with store.IntelDeliveryStatusFailed and notifies about the updated
+// status. In contrast to MarkIntelDeliveryAttemptAsFailed, the delivery is not
+// looked after, so this is safe to use while creating attempts.
+//
+// Warning: The delivery of the attempt is expected to be LOCKED in the store!
+func (c *Controller) markIntelDeliveryAttemptAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, note nulls.String) error {
+	err := c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attemptID, false, store.IntelDeliveryStatusFailed, note)
 	if err != nil {
 		return meh.Wrap(err, "update intel-delivery-attempt-status by id in store", meh.Details{"attempt_id": attemptID})
 	}
 	// Retrieve updated.
-	attempt, err = c.Store.IntelDeliveryAttemptByID(ctx, tx, attemptID)
+	attempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attemptID)
 	if err != nil {
 		return meh.Wrap(err, "retrieve updated intel-delivery-attempt from store", meh.Details{"attempt_id": attemptID})
 	}
@@ -490,10 +696,6 @@ func (c *Controller) MarkIntelDeliveryAttemptAsFailed(ctx context.Context, tx pg
 	if err != nil {
 		return meh.Wrap(err, "notify intel-deliver-attempt-status updated", meh.Details{"updated_attempt": attempt})
 	}
-	err = c.lookAfterDelivery(ctx, tx, attempt.Delivery)
-	if err != nil {
-		return meh.Wrap(err, "look after delivery", meh.Details{"delivery_id": attempt.Delivery})
-	}
 	return nil
 }
 
@@ -516,7 +718,8 @@ func (c *Controller) MarkIntelDeliveryAndAttemptAsDelivered(ctx context.Context,
 			})
 		}
 	}
-	// Cancel all ongoing attempts, except for the the one with the given id.
+	// Cancel all ongoing attempts, except for the the one with the given id. With
+	// fan-out, these are the attempts over other channels.
 	activeAttempts, err := c.Store.ActiveIntelDeliveryAttemptsByDelivery(ctx, tx, deliveryID)
 	if err != nil {
 		return meh.Wrap(err, "active intel-delivery-attempts by delivery from store", meh.Details{"delivery_id": deliveryID})
@@ -524,9 +727,12 @@ func (c *Controller) MarkIntelDeliveryAndAttemptAsDelivered(ctx context.Context,
 	for _, attempt := range activeAttempts {
 		newStatus := store.IntelDeliveryStatusCanceled
 		newNote := nulls.NewString("canceled due to manual delivery-confirmation")
-		if attemptID.Valid && attempt.ID == attemptID.UUID {
-			newStatus = store.IntelDeliveryStatusDelivered
-			newNote = nulls.String{}
+		if attemptID.Valid {
+			newNote = nulls.NewString("canceled due to delivery over other attempt")
+			if attempt.ID == attemptID.UUID {
+				newStatus = store.IntelDeliveryStatusDelivered
+				newNote = nulls.String{}
+			}
 		}
 		err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attempt.ID, false, newStatus, newNote)
 		if err != nil {
@@ -540,6 +746,10 @@ func (c *Controller) MarkIntelDeliveryAndAttemptAsDelivered(ctx context.Context,
 		if err != nil {
 			return meh.Wrap(err, "notify about updated intel-delivery-attempt", meh.Details{"updated": updatedAttempt})
 		}
+		err = c.cancelForwardedDeliveries(ctx, tx, attempt.ID, "canceled because of forwarding delivery being delivered")
+		if err != nil {
+			return meh.Wrap(err, "cancel forwarded deliveries", meh.Details{"attempt_id": attempt.ID})
+		}
 	}
 	// Mark delivery as delivered and notify.
 	const newDeliveryIsActive = false
@@ -553,6 +763,10 @@ func (c *Controller) MarkIntelDeliveryAndAttemptAsDelivered(ctx context.Context,
 	if err != nil {
 		return meh.Wrap(err, "notify intel-delivery-status updated", meh.Details{"delivery_id": deliveryID})
 	}
+	err = c.lookAfterForwardingAttempt(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "look after forwarding attempt", meh.Details{"delivery_id": deliveryID})
+	}
 	return nil
 }
 
@@ -573,40 +787,67 @@ func (c *Controller) CancelIntelDeliveryByID(ctx context.Context, deliveryID uui
 				"delivery_note":      delivery.Note,
 			})
 		}
-		// Cancel all ongoing attempts.
-		activeAttempts, err := c.Store.ActiveIntelDeliveryAttemptsByDelivery(ctx, tx, deliveryID)
-		if err != nil {
-			return meh.Wrap(err, "active intel-delivery-attempts by delivery from store", meh.Details{"delivery_id": deliveryID})
-		}
-		for _, attempt := range activeAttempts {
-			newStatus := store.IntelDeliveryStatusCanceled
-			newNote := nulls.NewString("canceled due to delivery being manually cancelled")
-			err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attempt.ID, false, newStatus, newNote)
-			if err != nil {
-				return meh.Wrap(err, "update intel-delivery-attempt status by id", meh.Details{"attempt_id": attempt.ID})
-			}
-			updatedAttempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attempt.ID)
-			if err != nil {
-				return meh.Wrap(err, "updated intel-delivery-attemt by id", meh.Details{"attempt_id": attempt.ID})
-			}
-			err = c.Notifier.NotifyIntelDeliveryAttemptStatusUpdated(ctx, tx, updatedAttempt)
-			if err != nil {
-				return meh.Wrap(err, "notify about updated intel-delivery-attempt", meh.Details{"updated": updatedAttempt})
-			}
-		}
-		// Mark delivery as delivered and notify.
-		const newDeliveryIsActive = false
 		newNote := nulls.NewString("manually cancelled")
 		if note.Valid {
 			newNote = note
 		}
-		err = c.Store.UpdateIntelDeliveryStatusByDelivery(ctx, tx, deliveryID, newDeliveryIsActive, success, newNote)
+		err = c.cancelActiveIntelDelivery(ctx, tx, deliveryID, success, newNote,
+			"canceled due to delivery being manually cancelled")
+		if err != nil {
+			return meh.Wrap(err, "cancel active intel-delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// isIntelDeliveryScheduled checks whether the given delivery is scheduled for
+// the future via store.IntelDelivery.DeliverAt.
+func isIntelDeliveryScheduled(delivery store.IntelDelivery) bool {
+	return delivery.DeliverAt.Valid && delivery.DeliverAt.Time.After(time.Now())
+}
+
+// UpdateIntelDeliveryDeliverAt updates the time to deliver the intel-delivery
+// with the given id at. If the delivery is inactive or not scheduled anymore,
+// an error with meh.ErrBadInput is returned. Setting deliverAt to null or a
+// time in the past, starts the delivery immediately.
+func (c *Controller) UpdateIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		// Lock the delivery.
+		delivery, err := c.Store.IntelDeliveryByIDAndLockOrWait(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery by id from store", meh.Details{"delivery_id": deliveryID})
+		}
+		if !delivery.IsActive {
+			return meh.NewBadInputErr("delivery inactive", meh.Details{
+				"delivery_is_active": delivery.IsActive,
+				"delivery_success":   delivery.Success,
+				"delivery_note":      delivery.Note,
+			})
+		}
+		if !isIntelDeliveryScheduled(delivery) {
+			return meh.NewBadInputErr("delivery not scheduled anymore", meh.Details{"delivery_deliver_at": delivery.DeliverAt})
+		}
+		err = c.Store.UpdateIntelDeliveryDeliverAt(ctx, tx, deliveryID, deliverAt)
+		if err != nil {
+			return meh.Wrap(err, "update intel-delivery deliver-at in store", meh.Details{
+				"delivery_id": deliveryID,
+				"deliver_at":  deliverAt,
+			})
+		}
+		err = c.Notifier.NotifyIntelDeliveryDeliverAtUpdated(ctx, tx, deliveryID, deliverAt)
 		if err != nil {
-			return meh.Wrap(err, "update intel-delivery-status in store", meh.Details{"delivery_id": deliveryID})
+			return meh.Wrap(err, "notify intel-delivery deliver-at updated", meh.Details{
+				"delivery_id": deliveryID,
+				"deliver_at":  deliverAt,
+			})
 		}
-		err = c.Notifier.NotifyIntelDeliveryStatusUpdated(ctx, tx, deliveryID, newDeliveryIsActive, success, newNote)
+		err = c.lookAfterDelivery(ctx, tx, deliveryID)
 		if err != nil {
-			return meh.Wrap(err, "notify intel-delivery-status updated", meh.Details{"delivery_id": deliveryID})
+			return meh.Wrap(err, "look after delivery", meh.Details{"delivery_id": deliveryID})
 		}
 		return nil
 	})
@@ -616,6 +857,77 @@ func (c *Controller) CancelIntelDeliveryByID(ctx context.Context, deliveryID uui
 	return nil
 }
 
+// cancelActiveIntelDelivery cancels the active intel-delivery with the given id
+// as well as all of its ongoing attempts. The given note is set for the
+// delivery and the attempt-note for all canceled attempts.
+//
+// Warning: The delivery with the given id is expected to be LOCKED in the store!
+func (c *Controller) cancelActiveIntelDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, success bool,
+	note nulls.String, attemptNote string) error {
+	// Cancel all ongoing attempts.
+	activeAttempts, err := c.Store.ActiveIntelDeliveryAttemptsByDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "active intel-delivery-attempts by delivery from store", meh.Details{"delivery_id": deliveryID})
+	}
+	for _, attempt := range activeAttempts {
+		newStatus := store.IntelDeliveryStatusCanceled
+		newNote := nulls.NewString(attemptNote)
+		err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attempt.ID, false, newStatus, newNote)
+		if err != nil {
+			return meh.Wrap(err, "update intel-delivery-attempt status by id", meh.Details{"attempt_id": attempt.ID})
+		}
+		updatedAttempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attempt.ID)
+		if err != nil {
+			return meh.Wrap(err, "updated intel-delivery-attemt by id", meh.Details{"attempt_id": attempt.ID})
+		}
+		err = c.Notifier.NotifyIntelDeliveryAttemptStatusUpdated(ctx, tx, updatedAttempt)
+		if err != nil {
+			return meh.Wrap(err, "notify about updated intel-delivery-attempt", meh.Details{"updated": updatedAttempt})
+		}
+		err = c.cancelForwardedDeliveries(ctx, tx, attempt.ID, "canceled because of forwarding delivery being cancelled")
+		if err != nil {
+			return meh.Wrap(err, "cancel forwarded deliveries", meh.Details{"attempt_id": attempt.ID})
+		}
+	}
+	// Mark delivery as finished and notify.
+	const newDeliveryIsActive = false
+	err = c.Store.UpdateIntelDeliveryStatusByDelivery(ctx, tx, deliveryID, newDeliveryIsActive, success, note)
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-status in store", meh.Details{"delivery_id": deliveryID})
+	}
+	err = c.Notifier.NotifyIntelDeliveryStatusUpdated(ctx, tx, deliveryID, newDeliveryIsActive, success, note)
+	if err != nil {
+		return meh.Wrap(err, "notify intel-delivery-status updated", meh.Details{"delivery_id": deliveryID})
+	}
+	err = c.lookAfterForwardingAttempt(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "look after forwarding attempt", meh.Details{"delivery_id": deliveryID})
+	}
+	return nil
+}
+
+// OperationByIntelDelivery retrieves the id of the operation, the intel of the
+// intel-delivery with the given id is assigned to.
+func (c *Controller) OperationByIntelDelivery(ctx context.Context, deliveryID uuid.UUID) (uuid.UUID, error) {
+	var operationID uuid.UUID
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		delivery, err := c.Store.IntelDeliveryByID(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery by id from store", meh.Details{"delivery_id": deliveryID})
+		}
+		intel, err := c.Store.IntelByID(ctx, tx, delivery.Intel)
+		if err != nil {
+			return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": delivery.Intel})
+		}
+		operationID = intel.Operation
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return operationID, nil
+}
+
 // IntelDeliveryAttemptsByDelivery retrieves a store.IntelDeliveryAttempt list
 // with attempts for the delivery with the given id.
 func (c *Controller) IntelDeliveryAttemptsByDelivery(ctx context.Context, deliveryID uuid.UUID) ([]store.IntelDeliveryAttempt, error) {
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+// deliveryCheckWorkers is the number of workers that process due delivery
+// checks concurrently.
+const deliveryCheckWorkers = 4
+
+// deliveryCheckBatchSize is the maximum number of due deliveries a worker
+// claims at once.
+const deliveryCheckBatchSize = 16
+
+// deliveryCheckLease is the duration after which a claimed delivery is checked
+// again, if processing failed or the instance died.
+const deliveryCheckLease = 30 * time.Second
+
+// deliveryCheckMaxIdle is the maximum duration to wait until looking for due
+// checks again. This limits the delay for checks, scheduled by other instances.
+const deliveryCheckMaxIdle = 30 * time.Second
+
+const deliveryChecksDurationWarnThreshold = 1 * time.Second
+
+// runDeliveryChecks looks after deliveries with due checks until the given
+// lifetime is done. Due deliveries are claimed in small batches and processed
+// by multiple workers, so that multiple instances can share the work. After
+// all due checks are processed, it waits until the next check is due or it is
+// woken up because of a newly scheduled one.
+func (c *Controller) runDeliveryChecks(lifetime context.Context) error {
+	for {
+		start := time.Now()
+		err := c.runDueDeliveryChecks(lifetime)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "run due delivery checks", nil))
+		} else if took := time.Since(start); took > deliveryChecksDurationWarnThreshold {
+			c.Logger.Warn("due delivery checks took longer than expected",
+				zap.Duration("took", took),
+				zap.Duration("warn_threshold", deliveryChecksDurationWarnThreshold))
+		}
+		wait, err := c.durationUntilNextDeliveryCheck(lifetime)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "duration until next delivery check", nil))
+			wait = deliveryCheckMaxIdle
+		}
+		// Wait.
+		timer := time.NewTimer(wait)
+		select {
+		case <-lifetime.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		case <-c.deliveryChecksWakeUp():
+			timer.Stop()
+		}
+	}
+}
+
+// runDueDeliveryChecks processes all due delivery checks using
+// deliveryCheckWorkers workers. Errors for single deliveries are logged and do
+// not stop processing the remaining ones.
+func (c *Controller) runDueDeliveryChecks(ctx context.Context) error {
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i := 0; i < deliveryCheckWorkers; i++ {
+		eg.Go(func() error {
+			for {
+				deliveryIDs, err := c.claimDueDeliveries(egCtx)
+				if err != nil {
+					return meh.Wrap(err, "claim due deliveries", nil)
+				}
+				if len(deliveryIDs) == 0 {
+					return nil
+				}
+				for _, deliveryID := range deliveryIDs {
+					err = c.checkDelivery(egCtx, deliveryID)
+					if err != nil {
+						mehlog.Log(c.Logger, meh.Wrap(err, "check delivery", meh.Details{"delivery_id": deliveryID}))
+					}
+				}
+			}
+		})
+	}
+	return eg.Wait()
+}
+
+// claimDueDeliveries claims at most deliveryCheckBatchSize due deliveries for
+// deliveryCheckLease.
+func (c *Controller) claimDueDeliveries(ctx context.Context) ([]uuid.UUID, error) {
+	var deliveryIDs []uuid.UUID
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		deliveryIDs, err = c.Store.ClaimDueIntelDeliveries(ctx, tx, deliveryCheckBatchSize, deliveryCheckLease)
+		if err != nil {
+			return meh.Wrap(err, "claim due intel-deliveries in store", meh.Details{
+				"limit": deliveryCheckBatchSize,
+				"lease": deliveryCheckLease,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return deliveryIDs, nil
+}
+
+// checkDelivery looks after the delivery with the given id and escalates it, if
+// it is open for too long. Further checks are scheduled by lookAfterDelivery.
+func (c *Controller) checkDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		delivery, err := c.Store.IntelDeliveryByIDAndLockOrWait(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery by id from store and lock or wait", meh.Details{"delivery_id": deliveryID})
+		}
+		err = c.Store.UnscheduleIntelDeliveryCheck(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "unschedule intel-delivery check in store", meh.Details{"delivery_id": deliveryID})
+		}
+		if !delivery.IsActive {
+			return nil
+		}
+		err = c.lookAfterDelivery(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "look after delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		err = c.escalateIntelDelivery(ctx, tx, deliveryID, store.IntelDeliveryEscalationTriggerOpenTimeout)
+		if err != nil {
+			return meh.Wrap(err, "escalate intel-delivery if open for too long", meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// durationUntilNextDeliveryCheck returns the duration until the next scheduled
+// delivery check is due. It is at most deliveryCheckMaxIdle.
+func (c *Controller) durationUntilNextDeliveryCheck(ctx context.Context) (time.Duration, error) {
+	var nextCheck time.Time
+	var ok bool
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		nextCheck, ok, err = c.Store.NextIntelDeliveryCheck(ctx, tx)
+		if err != nil {
+			return meh.Wrap(err, "next intel-delivery check from store", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, meh.Wrap(err, "run in tx", nil)
+	}
+	if !ok {
+		return deliveryCheckMaxIdle, nil
+	}
+	wait := time.Until(nextCheck)
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > deliveryCheckMaxIdle {
+		wait = deliveryCheckMaxIdle
+	}
+	return wait, nil
+}
+
+// deliveryChecksWakeUp returns the channel for waking up runDeliveryChecks.
+func (c *Controller) deliveryChecksWakeUp() chan struct{} {
+	c.deliveryChecksWakeUpOnce.Do(func() {
+		c.deliveryChecksWakeUpChan = make(chan struct{}, 1)
+	})
+	return c.deliveryChecksWakeUpChan
+}
+
+// wakeUpDeliveryChecks wakes up runDeliveryChecks, so that newly scheduled
+// checks are respected. It does not block.
+func (c *Controller) wakeUpDeliveryChecks() {
+	select {
+	case c.deliveryChecksWakeUp() <- struct{}{}:
+	default:
+	}
+}
+
+// wakeUpDeliveryChecksAfterCommit calls wakeUpDeliveryChecks after the given
+// transaction was committed, so that newly scheduled checks are visible when
+// looking for due ones.
+func (c *Controller) wakeUpDeliveryChecksAfterCommit(ctx context.Context, tx pgx.Tx) {
+	pgutil.AfterCommit(ctx, tx, func(_ context.Context) {
+		c.wakeUpDeliveryChecks()
+	})
+}
+
+// scheduleDeliveryCheck schedules a check for the delivery with the given id at
+// the given time.
+func (c *Controller) scheduleDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, at time.Time) error {
+	err := c.Store.ScheduleIntelDeliveryCheck(ctx, tx, deliveryID, at)
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery check in store", meh.Details{
+			"delivery_id": deliveryID,
+			"at":          at,
+		})
+	}
+	c.wakeUpDeliveryChecksAfterCommit(ctx, tx)
+	return nil
+}
+
+// scheduleDeliveryTimeoutCheck schedules a check for the delivery with the
+// given id for when the next active attempt times out or the next open-timeout
+// escalation rule becomes due.
+func (c *Controller) scheduleDeliveryTimeoutCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	err := c.Store.ScheduleIntelDeliveryTimeoutCheck(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery timeout check in store", meh.Details{"delivery_id": deliveryID})
+	}
+	c.wakeUpDeliveryChecksAfterCommit(ctx, tx)
+	return nil
+}
+
+// scheduleDeliveryChecksByEntry schedules immediate checks for all active
+// deliveries to the address book entry with the given id.
+func (c *Controller) scheduleDeliveryChecksByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	err := c.Store.ScheduleIntelDeliveryChecksByEntry(ctx, tx, entryID, time.Now())
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks by entry in store", meh.Details{"entry_id": entryID})
+	}
+	c.wakeUpDeliveryChecksAfterCommit(ctx, tx)
+	return nil
+}
+
+// scheduleDeliveryChecksByUser schedules immediate checks for all active
+// deliveries to address book entries, associated with the user with the given
+// id.
+func (c *Controller) scheduleDeliveryChecksByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	err := c.Store.ScheduleIntelDeliveryChecksByUser(ctx, tx, userID, time.Now())
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks by user in store", meh.Details{"user_id": userID})
+	}
+	c.wakeUpDeliveryChecksAfterCommit(ctx, tx)
+	return nil
+}
+
+// scheduleDeliveryChecksByOperation schedules immediate checks for all active
+// deliveries of intel for the operation with the given id.
+func (c *Controller) scheduleDeliveryChecksByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID) error {
+	err := c.Store.ScheduleIntelDeliveryChecksByOperation(ctx, tx, operationID, time.Now())
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks by operation in store", meh.Details{"operation_id": operationID})
+	}
+	c.wakeUpDeliveryChecksAfterCommit(ctx, tx)
+	return nil
+}
@@ -0,0 +1,415 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerCheckDeliverySuite tests Controller.checkDelivery.
+type ControllerCheckDeliverySuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleDelivery store.IntelDelivery
+}
+
+func (suite *ControllerCheckDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    testutil.NewUUIDV4(),
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+	}
+
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil).Maybe()
+	suite.ctrl.Store.On("UnscheduleIntelDeliveryCheck", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil).Maybe()
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleDelivery.Intel).
+		Return(store.Intel{ID: suite.sampleDelivery.Intel, IsValid: true}, nil).Maybe()
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(nil).Maybe()
+	// Report an active attempt for not having to mock the whole delivery process
+	// in lookAfterDelivery.
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return([]store.IntelDeliveryAttempt{{ID: testutil.NewUUIDV4()}}, nil).Maybe()
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID,
+		store.IntelDeliveryEscalationTriggerOpenTimeout).Return(nil, nil).Maybe()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestLockFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByIDAndLockOrWait")
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDelivery{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestUnscheduleFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UnscheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("UnscheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleDelivery.IsActive = false
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByIDAndLockOrWait")
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UnscheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("UnscheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleDelivery.ID).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByID")
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestLookAfterDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "TimedOutIntelDeliveryAttemptsByDelivery")
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestEscalateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCheckDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UnscheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("UnscheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleDelivery.ID).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryTimeoutCheck")
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", timeout, suite.tx, suite.sampleDelivery.ID).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", timeout, suite.tx, suite.sampleDelivery.ID,
+		store.IntelDeliveryEscalationTriggerOpenTimeout).Return(nil, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.checkDelivery(timeout, suite.sampleDelivery.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_checkDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerCheckDeliverySuite))
+}
+
+// ControllerRunDueDeliveryChecksSuite tests Controller.runDueDeliveryChecks.
+type ControllerRunDueDeliveryChecksSuite struct {
+	suite.Suite
+	ctrl              *ControllerMock
+	sampleDeliveryIDs []uuid.UUID
+}
+
+func (suite *ControllerRunDueDeliveryChecksSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.GenTx = true
+	suite.sampleDeliveryIDs = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerRunDueDeliveryChecksSuite) TestClaimFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ClaimDueIntelDeliveries", mock.Anything, mock.Anything, deliveryCheckBatchSize, deliveryCheckLease).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueDeliveryChecks(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRunDueDeliveryChecksSuite) TestNoneDue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ClaimDueIntelDeliveries", mock.Anything, mock.Anything, deliveryCheckBatchSize, deliveryCheckLease).
+		Return([]uuid.UUID{}, nil).Times(deliveryCheckWorkers)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueDeliveryChecks(timeout)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRunDueDeliveryChecksSuite) TestCheckFailDoesNotStopOthers() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ClaimDueIntelDeliveries", mock.Anything, mock.Anything, deliveryCheckBatchSize, deliveryCheckLease).
+		Return(suite.sampleDeliveryIDs, nil).Once()
+	suite.ctrl.Store.On("ClaimDueIntelDeliveries", mock.Anything, mock.Anything, deliveryCheckBatchSize, deliveryCheckLease).
+		Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, mock.Anything, suite.sampleDeliveryIDs[0]).
+		Return(store.IntelDelivery{}, errors.New("sad life")).Once()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, mock.Anything, suite.sampleDeliveryIDs[1]).
+		Return(store.IntelDelivery{ID: suite.sampleDeliveryIDs[1], IsActive: false}, nil).Once()
+	suite.ctrl.Store.On("UnscheduleIntelDeliveryCheck", mock.Anything, mock.Anything, suite.sampleDeliveryIDs[1]).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueDeliveryChecks(timeout)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_runDueDeliveryChecks(t *testing.T) {
+	suite.Run(t, new(ControllerRunDueDeliveryChecksSuite))
+}
+
+// ControllerDurationUntilNextDeliveryCheckSuite tests
+// Controller.durationUntilNextDeliveryCheck.
+type ControllerDurationUntilNextDeliveryCheckSuite struct {
+	suite.Suite
+	ctrl *ControllerMock
+	tx   *testutil.DBTx
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelDeliveryCheck", timeout, suite.tx).
+		Return(time.Time{}, false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.durationUntilNextDeliveryCheck(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) TestNoneScheduled() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelDeliveryCheck", timeout, suite.tx).
+		Return(time.Time{}, false, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextDeliveryCheck(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(deliveryCheckMaxIdle, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) TestDue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelDeliveryCheck", timeout, suite.tx).
+		Return(time.Now().Add(-time.Minute), true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextDeliveryCheck(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(time.Duration(0), got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) TestUpcoming() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelDeliveryCheck", timeout, suite.tx).
+		Return(time.Now().Add(deliveryCheckMaxIdle/2), true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextDeliveryCheck(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Greater(got, time.Duration(0), "should wait")
+		suite.LessOrEqual(got, deliveryCheckMaxIdle/2, "should not wait longer than until next check")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextDeliveryCheckSuite) TestLimitToMaxIdle() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelDeliveryCheck", timeout, suite.tx).
+		Return(time.Now().Add(24*time.Hour), true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextDeliveryCheck(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(deliveryCheckMaxIdle, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_durationUntilNextDeliveryCheck(t *testing.T) {
+	suite.Run(t, new(ControllerDurationUntilNextDeliveryCheckSuite))
+}
+
+// TestController_wakeUpDeliveryChecks tests Controller.wakeUpDeliveryChecks.
+func TestController_wakeUpDeliveryChecks(t *testing.T) {
+	ctrl := NewMockController()
+	// Multiple wake-ups must not block.
+	ctrl.Ctrl.wakeUpDeliveryChecks()
+	ctrl.Ctrl.wakeUpDeliveryChecks()
+	select {
+	case <-ctrl.Ctrl.deliveryChecksWakeUp():
+	default:
+		t.Fatal("should have been woken up")
+	}
+}
+
+// TestController_scheduleDeliveryCheckWakeUpAfterCommit tests that
+// Controller.scheduleDeliveryCheck wakes up runDeliveryChecks only after the
+// transaction was committed.
+func TestController_scheduleDeliveryCheckWakeUpAfterCommit(t *testing.T) {
+	ctrl := NewMockController()
+	tx := &testutil.DBTx{}
+	rolledBackTx := &testutil.DBTx{}
+	ctrl.DB.Tx = []*testutil.DBTx{rolledBackTx, tx}
+	deliveryID := testutil.NewUUIDV4()
+	at := time.Date(2022, 10, 3, 8, 12, 0, 0, time.UTC)
+	ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, deliveryID, at).Return(nil)
+	defer ctrl.Store.AssertExpectations(t)
+
+	// Rolled back.
+	err := pgutil.RunInTx(context.Background(), ctrl.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := ctrl.Ctrl.scheduleDeliveryCheck(ctx, tx, deliveryID, at)
+		require.NoError(t, err, "schedule should not fail")
+		return errors.New("sad life")
+	})
+	require.Error(t, err, "should fail")
+	select {
+	case <-ctrl.Ctrl.deliveryChecksWakeUp():
+		t.Fatal("should not have been woken up after rollback")
+	default:
+	}
+	// Committed.
+	err = pgutil.RunInTx(context.Background(), ctrl.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := ctrl.Ctrl.scheduleDeliveryCheck(ctx, tx, deliveryID, at)
+		require.NoError(t, err, "schedule should not fail")
+		select {
+		case <-ctrl.Ctrl.deliveryChecksWakeUp():
+			t.Fatal("should not have been woken up before commit")
+		default:
+		}
+		return nil
+	})
+	require.NoError(t, err, "should not fail")
+	select {
+	case <-ctrl.Ctrl.deliveryChecksWakeUp():
+	default:
+		t.Fatal("should have been woken up after commit")
+	}
+}
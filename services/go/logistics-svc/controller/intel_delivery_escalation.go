@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// IntelDeliveryEscalationRulesByOperation retrieves the
+// store.IntelDeliveryEscalationRule list for the operation with the given id.
+func (c *Controller) IntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID) ([]store.IntelDeliveryEscalationRule, error) {
+	var rules []store.IntelDeliveryEscalationRule
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		rules, err = c.Store.IntelDeliveryEscalationRulesByOperation(ctx, tx, operationID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery-escalation-rules by operation from store", meh.Details{"operation_id": operationID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return rules, nil
+}
+
+// UpdateIntelDeliveryEscalationRulesByOperation replaces the escalation rules
+// for the operation with the given id. Fallback address book entries must
+// either be global or belong to the operation.
+func (c *Controller) UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID,
+	rules []store.IntelDeliveryEscalationRule) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		for _, rule := range rules {
+			if !rule.EscalateTo.Valid {
+				continue
+			}
+			entry, err := c.Store.AddressBookEntryByID(ctx, tx, rule.EscalateTo.UUID, uuid.NullUUID{})
+			if err != nil {
+				return meh.Wrap(err, "address book entry to escalate to from store", meh.Details{"entry_id": rule.EscalateTo.UUID})
+			}
+			if entry.Operation.Valid && entry.Operation.UUID != operationID {
+				return meh.NewBadInputErr("address book entry to escalate to belongs to other operation", meh.Details{
+					"entry_id":        entry.ID,
+					"entry_operation": entry.Operation.UUID,
+					"operation_id":    operationID,
+				})
+			}
+		}
+		err := c.Store.UpdateIntelDeliveryEscalationRulesByOperation(ctx, tx, operationID, rules)
+		if err != nil {
+			return meh.Wrap(err, "update intel-delivery-escalation-rules by operation in store", meh.Details{
+				"operation_id": operationID,
+				"rules":        rules,
+			})
+		}
+		// Open-timeout escalations might be due earlier now.
+		err = c.scheduleDeliveryChecksByOperation(ctx, tx, operationID)
+		if err != nil {
+			return meh.Wrap(err, "schedule delivery checks by operation", meh.Details{"operation_id": operationID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// IntelDeliveryEscalationChainByDelivery retrieves the
+// store.IntelDeliveryEscalation list, that led to the delivery with the given
+// id, followed by the escalations of the delivery itself.
+func (c *Controller) IntelDeliveryEscalationChainByDelivery(ctx context.Context, deliveryID uuid.UUID) ([]store.IntelDeliveryEscalation, error) {
+	var chain []store.IntelDeliveryEscalation
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		// Assure delivery exists.
+		_, err := c.Store.IntelDeliveryByID(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery by id from store", meh.Details{"delivery_id": deliveryID})
+		}
+		chain, err = c.Store.IntelDeliveryEscalationChainByDelivery(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery-escalation-chain by delivery from store", meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return chain, nil
+}
+
+// escalateIntelDelivery applies all due escalation rules with the given
+// trigger for the delivery with the given id. Each rule is applied at most once
+// in an escalation chain, so escalated deliveries do not bounce between
+// fallback entries. If a rule has a fallback address book entry, that is not
+// the recipient of the delivery itself, a new delivery to it is created.
+//
+// Warning: The delivery with the given id is expected to be LOCKED in the store!
+func (c *Controller) escalateIntelDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID,
+	trigger store.IntelDeliveryEscalationTrigger) error {
+	rules, err := c.Store.DueIntelDeliveryEscalationRulesByDelivery(ctx, tx, deliveryID, trigger)
+	if err != nil {
+		return meh.Wrap(err, "due intel-delivery-escalation-rules by delivery from store", meh.Details{
+			"delivery_id": deliveryID,
+			"trigger":     trigger,
+		})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	delivery, err := c.Store.IntelDeliveryByID(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "intel-delivery by id from store", meh.Details{"delivery_id": deliveryID})
+	}
+	intel, err := c.Store.IntelByID(ctx, tx, delivery.Intel)
+	if err != nil {
+		return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": delivery.Intel})
+	}
+	if !intel.IsValid || intel.IsExpired {
+		// No need to escalate deliveries for invalidated or expired intel.
+		return nil
+	}
+	chain, err := c.Store.IntelDeliveryEscalationChainByDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "intel-delivery-escalation-chain by delivery from store", meh.Details{"delivery_id": deliveryID})
+	}
+	appliedRules := make(map[uuid.UUID]struct{}, len(chain))
+	for _, escalation := range chain {
+		if escalation.Rule.Valid {
+			appliedRules[escalation.Rule.UUID] = struct{}{}
+		}
+	}
+	for _, rule := range rules {
+		if _, ok := appliedRules[rule.ID]; ok {
+			continue
+		}
+		appliedRules[rule.ID] = struct{}{}
+		escalation := store.IntelDeliveryEscalation{
+			Delivery:         deliveryID,
+			Rule:             nulls.NewUUID(rule.ID),
+			Trigger:          trigger,
+			EscalatedAt:      time.Now(),
+			NotifiedManagers: rule.NotifyManagers,
+		}
+		var escalatedDelivery uuid.NullUUID
+		escalateTo := rule.EscalateTo
+		if escalateTo.Valid && escalateTo.UUID == delivery.To {
+			// Escalating to the same recipient would not help.
+			escalateTo = uuid.NullUUID{}
+		}
+		if escalateTo.Valid {
+			createdDelivery, err := c.createIntelDelivery(ctx, tx, delivery.Intel, escalateTo.UUID, nulls.Time{})
+			if err != nil {
+				return meh.Wrap(err, "create intel-delivery to fallback entry", meh.Details{"entry_id": escalateTo.UUID})
+			}
+			escalatedDelivery = nulls.NewUUID(createdDelivery.ID)
+			escalation.EscalatedTo = escalatedDelivery
+		}
+		// Record the escalation before looking after the created delivery, so that a
+		// failing one already includes it in its escalation chain.
+		createdEscalation, err := c.Store.CreateIntelDeliveryEscalation(ctx, tx, escalation)
+		if err != nil {
+			return meh.Wrap(err, "create intel-delivery-escalation in store", meh.Details{"escalation": escalation})
+		}
+		err = c.Notifier.NotifyIntelDeliveryEscalated(ctx, tx, createdEscalation, intel, escalateTo)
+		if err != nil {
+			return meh.Wrap(err, "notify intel-delivery escalated", meh.Details{"escalation": createdEscalation})
+		}
+		if escalatedDelivery.Valid {
+			err = c.lookAfterDelivery(ctx, tx, escalatedDelivery.UUID)
+			if err != nil {
+				return meh.Wrap(err, "look after escalated delivery", meh.Details{"delivery_id": escalatedDelivery.UUID})
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerEscalateIntelDeliverySuite tests Controller.escalateIntelDelivery.
+type ControllerEscalateIntelDeliverySuite struct {
+	suite.Suite
+	ctrl              *ControllerMock
+	tx                *testutil.DBTx
+	sampleDelivery    store.IntelDelivery
+	sampleIntel       store.Intel
+	sampleRule        store.IntelDeliveryEscalationRule
+	sampleEscalated   store.IntelDelivery
+	createdEscalation store.IntelDeliveryEscalation
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleIntel = store.Intel{
+		ID:        testutil.NewUUIDV4(),
+		Operation: testutil.NewUUIDV4(),
+		IsValid:   true,
+	}
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleIntel.ID,
+		To:       testutil.NewUUIDV4(),
+		IsActive: false,
+		Success:  false,
+		Note:     nulls.NewString("no more channels to try"),
+	}
+	suite.sampleRule = store.IntelDeliveryEscalationRule{
+		ID:             testutil.NewUUIDV4(),
+		Operation:      suite.sampleIntel.Operation,
+		Trigger:        store.IntelDeliveryEscalationTriggerFailed,
+		EscalateTo:     nulls.NewUUID(testutil.NewUUIDV4()),
+		NotifyManagers: true,
+	}
+	suite.sampleEscalated = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleIntel.ID,
+		To:       suite.sampleRule.EscalateTo.UUID,
+		IsActive: true,
+		Success:  false,
+	}
+	suite.createdEscalation = store.IntelDeliveryEscalation{
+		ID:               testutil.NewUUIDV4(),
+		Delivery:         suite.sampleDelivery.ID,
+		Rule:             nulls.NewUUID(suite.sampleRule.ID),
+		Trigger:          store.IntelDeliveryEscalationTriggerFailed,
+		EscalatedTo:      nulls.NewUUID(suite.sampleEscalated.ID),
+		NotifiedManagers: true,
+	}
+
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID,
+		store.IntelDeliveryEscalationTriggerFailed).Return([]store.IntelDeliveryEscalationRule{suite.sampleRule}, nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil).Maybe()
+	// Return inactive deliveries for not having to mock the whole delivery process
+	// in lookAfterDelivery.
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleEscalated.ID).
+		Return(store.IntelDelivery{IsActive: false}, nil).Maybe()
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryEscalationChainByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return(suite.sampleEscalated, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryCreated", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrSkip", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("CreateIntelDeliveryEscalation", mock.Anything, suite.tx, mock.Anything).
+		Return(suite.createdEscalation, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryEscalated", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.T().Cleanup(func() {
+		suite.ctrl.Store.AssertExpectations(suite.T())
+		suite.ctrl.Notifier.AssertExpectations(suite.T())
+	})
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) escalate() error {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	var err error
+	go func() {
+		defer cancel()
+		err = suite.ctrl.Ctrl.escalateIntelDelivery(timeout, suite.tx, suite.sampleDelivery.ID, store.IntelDeliveryEscalationTriggerFailed)
+	}()
+	wait()
+	return err
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestRetrieveRulesFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+
+	suite.Error(suite.escalate(), "should fail")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestNoRules() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]store.IntelDeliveryEscalationRule{}, nil)
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryEscalation")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestRetrieveChainFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryEscalationChainByDelivery")
+	suite.ctrl.Store.On("IntelDeliveryEscalationChainByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+
+	suite.Error(suite.escalate(), "should fail")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestInvalidIntel() {
+	suite.sampleIntel.IsValid = false
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil)
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryEscalation")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestExpiredIntel() {
+	suite.sampleIntel.IsExpired = true
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil)
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryEscalation")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestRuleAlreadyAppliedInChain() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryEscalationChainByDelivery")
+	suite.ctrl.Store.On("IntelDeliveryEscalationChainByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return([]store.IntelDeliveryEscalation{
+			{
+				ID:          testutil.NewUUIDV4(),
+				Delivery:    testutil.NewUUIDV4(),
+				Rule:        nulls.NewUUID(suite.sampleRule.ID),
+				Trigger:     store.IntelDeliveryEscalationTriggerFailed,
+				EscalatedTo: nulls.NewUUID(suite.sampleDelivery.ID),
+			},
+		}, nil)
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDelivery")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryEscalation")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestCreateDeliveryFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDelivery")
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDelivery{}, errors.New("sad life"))
+
+	suite.Error(suite.escalate(), "should fail")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestCreateEscalationFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDeliveryEscalation")
+	suite.ctrl.Store.On("CreateIntelDeliveryEscalation", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryEscalation{}, errors.New("sad life"))
+
+	suite.Error(suite.escalate(), "should fail")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestNotifyFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryEscalated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryEscalated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+
+	suite.Error(suite.escalate(), "should fail")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestNotifyManagersOnly() {
+	suite.sampleRule.EscalateTo = uuid.NullUUID{}
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]store.IntelDeliveryEscalationRule{suite.sampleRule}, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDeliveryEscalation")
+	suite.ctrl.Store.On("CreateIntelDeliveryEscalation", mock.Anything, suite.tx, mock.MatchedBy(func(e store.IntelDeliveryEscalation) bool {
+		return e.Delivery == suite.sampleDelivery.ID && !e.EscalatedTo.Valid && e.NotifiedManagers
+	})).Return(suite.createdEscalation, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryEscalated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryEscalated", mock.Anything, suite.tx, suite.createdEscalation, suite.sampleIntel,
+		uuid.NullUUID{}).Return(nil).Once()
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDelivery")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestEscalateToSameRecipient() {
+	suite.sampleRule.EscalateTo = nulls.NewUUID(suite.sampleDelivery.To)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "DueIntelDeliveryEscalationRulesByDelivery")
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]store.IntelDeliveryEscalationRule{suite.sampleRule}, nil)
+
+	suite.NoError(suite.escalate(), "should not fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDelivery")
+}
+
+func (suite *ControllerEscalateIntelDeliverySuite) TestOK() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDelivery")
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, suite.tx, store.IntelDelivery{
+		Intel:    suite.sampleIntel.ID,
+		To:       suite.sampleRule.EscalateTo.UUID,
+		IsActive: true,
+		Success:  false,
+	}).Return(suite.sampleEscalated, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDeliveryEscalation")
+	suite.ctrl.Store.On("CreateIntelDeliveryEscalation", mock.Anything, suite.tx, mock.MatchedBy(func(e store.IntelDeliveryEscalation) bool {
+		return e.Delivery == suite.sampleDelivery.ID &&
+			e.Rule == nulls.NewUUID(suite.sampleRule.ID) &&
+			e.Trigger == store.IntelDeliveryEscalationTriggerFailed &&
+			e.EscalatedTo == nulls.NewUUID(suite.sampleEscalated.ID) &&
+			e.NotifiedManagers &&
+			time.Since(e.EscalatedAt) < time.Minute
+	})).Return(suite.createdEscalation, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryEscalated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryEscalated", mock.Anything, suite.tx, suite.createdEscalation, suite.sampleIntel,
+		suite.sampleRule.EscalateTo).Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByID")
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleEscalated.ID).
+		Return(store.IntelDelivery{IsActive: false}, nil).Once()
+
+	suite.NoError(suite.escalate(), "should not fail")
+}
+
+func TestController_escalateIntelDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerEscalateIntelDeliverySuite))
+}
+
+// ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite tests
+// Controller.UpdateIntelDeliveryEscalationRulesByOperation.
+type ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite struct {
+	suite.Suite
+	ctrl              *ControllerMock
+	tx                *testutil.DBTx
+	sampleOperationID uuid.UUID
+	sampleEntry       store.AddressBookEntryDetailed
+	sampleRules       []store.IntelDeliveryEscalationRule
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleOperationID = testutil.NewUUIDV4()
+	suite.sampleEntry = store.AddressBookEntryDetailed{
+		AddressBookEntry: store.AddressBookEntry{
+			ID:        testutil.NewUUIDV4(),
+			Label:     "lead",
+			Operation: nulls.NewUUID(suite.sampleOperationID),
+		},
+	}
+	suite.sampleRules = []store.IntelDeliveryEscalationRule{
+		{
+			Trigger:        store.IntelDeliveryEscalationTriggerFailed,
+			EscalateTo:     nulls.NewUUID(suite.sampleEntry.ID),
+			NotifyManagers: true,
+		},
+		{
+			Trigger:        store.IntelDeliveryEscalationTriggerOpenTimeout,
+			OpenTimeout:    10 * time.Minute,
+			NotifyManagers: true,
+		},
+	}
+	suite.ctrl.Store.On("AddressBookEntryByID", mock.Anything, suite.tx, suite.sampleEntry.ID, uuid.NullUUID{}).
+		Return(suite.sampleEntry, nil).Maybe()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestRetrieveEntryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "AddressBookEntryByID")
+	suite.ctrl.Store.On("AddressBookEntryByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(store.AddressBookEntryDetailed{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestEntryOfOtherOperation() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleEntry.Operation = nulls.NewUUID(testutil.NewUUIDV4())
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "AddressBookEntryByID")
+	suite.ctrl.Store.On("AddressBookEntryByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(suite.sampleEntry, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestScheduleChecksFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestOKWithGlobalEntry() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleEntry.Operation = uuid.NullUUID{}
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "AddressBookEntryByID")
+	suite.ctrl.Store.On("AddressBookEntryByID", mock.Anything, suite.tx, suite.sampleEntry.ID, uuid.NullUUID{}).
+		Return(suite.sampleEntry, nil)
+	suite.ctrl.Store.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, suite.tx, suite.sampleOperationID, suite.sampleRules).
+		Return(nil).Once()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByOperation", mock.Anything, suite.tx, suite.sampleOperationID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, suite.tx, suite.sampleOperationID, suite.sampleRules).
+		Return(nil).Once()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByOperation", mock.Anything, suite.tx, suite.sampleOperationID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryEscalationRulesByOperation(timeout, suite.sampleOperationID, suite.sampleRules)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateIntelDeliveryEscalationRulesByOperation(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateIntelDeliveryEscalationRulesByOperationSuite))
+}
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+)
+
+// isForwardChannelType checks whether the given store.ChannelType is handled by
+// forwarding to other address book entries.
+func isForwardChannelType(channelType store.ChannelType) bool {
+	return channelType == store.ChannelTypeForwardToUser || channelType == store.ChannelTypeForwardToGroup
+}
+
+// forwardQuorum returns the number of forwarded deliveries that need to succeed
+// based on the configured quorum and the number of forwarded deliveries. The
+// result is always at least one and never exceeds the number of forwarded
+// deliveries, as the attempt could never be successful otherwise.
+func forwardQuorum(configuredQuorum int32, forwardedDeliveries int) int {
+	required := int(configuredQuorum)
+	if required > forwardedDeliveries {
+		required = forwardedDeliveries
+	}
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// forwardIntelDeliveryAttempt handles the given intel-delivery-attempt over a
+// forward-channel. It creates intel-deliveries for all target address book
+// entries of the channel and links them to the attempt. Entries, that are
+// already part of the forward chain of the delivery, are skipped in order to
+// avoid forwarding loops. If no entries are left, the attempt is marked as
+// failed without looking after the delivery.
+//
+// Warning: The delivery of the attempt is expected to be LOCKED in the store!
+func (c *Controller) forwardIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attempt store.IntelDeliveryAttempt,
+	delivery store.IntelDelivery) error {
+	targetEntries, err := c.Store.ForwardTargetAddressBookEntriesByChannel(ctx, tx, attempt.Channel)
+	if err != nil {
+		return meh.Wrap(err, "forward target address book entries by channel from store",
+			meh.Details{"channel_id": attempt.Channel})
+	}
+	forwardChainEntries, err := c.forwardChainEntriesByDelivery(ctx, tx, delivery)
+	if err != nil {
+		return meh.Wrap(err, "forward chain entries by delivery", meh.Details{"delivery_id": delivery.ID})
+	}
+	entriesToForwardTo := make([]uuid.UUID, 0, len(targetEntries))
+	for _, entryID := range targetEntries {
+		if _, ok := forwardChainEntries[entryID]; ok {
+			// Already part of the chain, so we would forward in a loop.
+			continue
+		}
+		entriesToForwardTo = append(entriesToForwardTo, entryID)
+	}
+	if len(entriesToForwardTo) == 0 {
+		err = c.markIntelDeliveryAttemptAsFailed(ctx, tx, attempt.ID, nulls.NewString("no entries to forward to"))
+		if err != nil {
+			return meh.Wrap(err, "mark intel-delivery-attempt as failed because of no entries to forward to",
+				meh.Details{"attempt_id": attempt.ID})
+		}
+		return nil
+	}
+	// Create all deliveries first, so that the attempt is not resolved before all
+	// forwarded deliveries exist.
+	forwardedDeliveries := make([]store.IntelDelivery, 0, len(entriesToForwardTo))
+	for _, entryID := range entriesToForwardTo {
+		deliveryToCreate := store.IntelDelivery{
+			Intel:    delivery.Intel,
+			To:       entryID,
+			IsActive: true,
+			Success:  false,
+		}
+		createdDelivery, err := c.Store.CreateIntelDelivery(ctx, tx, deliveryToCreate)
+		if err != nil {
+			return meh.Wrap(err, "create forwarded intel-delivery in store", meh.Details{"create": deliveryToCreate})
+		}
+		err = c.Store.LinkIntelDeliveryToForwardingAttempt(ctx, tx, createdDelivery.ID, attempt.ID)
+		if err != nil {
+			return meh.Wrap(err, "link intel-delivery to forwarding attempt in store", meh.Details{
+				"delivery_id": createdDelivery.ID,
+				"attempt_id":  attempt.ID,
+			})
+		}
+		err = c.Notifier.NotifyIntelDeliveryCreated(ctx, tx, createdDelivery)
+		if err != nil {
+			return meh.Wrap(err, "notify intel-delivery created", meh.Details{"created": createdDelivery})
+		}
+		forwardedDeliveries = append(forwardedDeliveries, createdDelivery)
+	}
+	// Update attempt status.
+	newNote := nulls.NewString(fmt.Sprintf("forwarded to %d entries", len(forwardedDeliveries)))
+	err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attempt.ID, true, store.IntelDeliveryStatusDelivering, newNote)
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt status by id in store", meh.Details{"attempt_id": attempt.ID})
+	}
+	updatedAttempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "retrieve updated intel-delivery-attempt from store", meh.Details{"attempt_id": attempt.ID})
+	}
+	err = c.Notifier.NotifyIntelDeliveryAttemptStatusUpdated(ctx, tx, updatedAttempt)
+	if err != nil {
+		return meh.Wrap(err, "notify intel-delivery-attempt-status updated", meh.Details{"updated": updatedAttempt})
+	}
+	// Lock and look after.
+	for _, forwardedDelivery := range forwardedDeliveries {
+		err = c.Store.LockIntelDeliveryByIDOrSkip(ctx, tx, forwardedDelivery.ID)
+		if err != nil {
+			return meh.Wrap(err, "lock forwarded intel-delivery in store", meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+		err = c.lookAfterDelivery(ctx, tx, forwardedDelivery.ID)
+		if err != nil {
+			return meh.Wrap(err, "look after forwarded delivery", meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+	}
+	return nil
+}
+
+// forwardChainEntriesByDelivery returns the ids of all address book entries,
+// the given delivery as well as all deliveries it was forwarded by, are
+// addressed to.
+func (c *Controller) forwardChainEntriesByDelivery(ctx context.Context, tx pgx.Tx, delivery store.IntelDelivery) (map[uuid.UUID]struct{}, error) {
+	chainEntries := map[uuid.UUID]struct{}{
+		delivery.To: {},
+	}
+	current := delivery
+	for {
+		forwardingAttempt, ok, err := c.Store.ForwardingAttemptByDelivery(ctx, tx, current.ID)
+		if err != nil {
+			return nil, meh.Wrap(err, "forwarding attempt by delivery from store", meh.Details{"delivery_id": current.ID})
+		}
+		if !ok {
+			return chainEntries, nil
+		}
+		current, err = c.Store.IntelDeliveryByID(ctx, tx, forwardingAttempt.Delivery)
+		if err != nil {
+			return nil, meh.Wrap(err, "forwarding intel-delivery by id from store",
+				meh.Details{"delivery_id": forwardingAttempt.Delivery})
+		}
+		if _, ok := chainEntries[current.To]; ok {
+			// Should not happen because of loop protection, but we want to be safe.
+			return chainEntries, nil
+		}
+		chainEntries[current.To] = struct{}{}
+	}
+}
+
+// lookAfterForwardingAttempt checks whether the intel-delivery with the given
+// id was forwarded by an intel-delivery-attempt. If so, the attempt is marked
+// as delivered, if the quorum of successful forwarded deliveries is reached, or
+// as failed, if all forwarded deliveries are done without reaching it.
+func (c *Controller) lookAfterForwardingAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	forwardingAttempt, ok, err := c.Store.ForwardingAttemptByDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return meh.Wrap(err, "forwarding attempt by delivery from store", meh.Details{"delivery_id": deliveryID})
+	}
+	if !ok {
+		return nil
+	}
+	// Lock the forwarding delivery and retrieve the attempt again in order to
+	// find its updated information. As forwarding deliveries are always locked
+	// before forwarded ones, it is usually already locked by us. Otherwise, we
+	// would risk a deadlock with cancelForwardedDeliveries.
+	err = c.Store.LockIntelDeliveryByIDOrWait(ctx, tx, forwardingAttempt.Delivery)
+	if err != nil {
+		return meh.Wrap(err, "lock forwarding intel-delivery by id or wait in store",
+			meh.Details{"delivery_id": forwardingAttempt.Delivery})
+	}
+	forwardingAttemptID := forwardingAttempt.ID
+	forwardingAttempt, err = c.Store.IntelDeliveryAttemptByID(ctx, tx, forwardingAttemptID)
+	if err != nil {
+		return meh.Wrap(err, "forwarding intel-delivery-attempt by id from store (after locked delivery)",
+			meh.Details{"attempt_id": forwardingAttemptID})
+	}
+	if !forwardingAttempt.IsActive {
+		// Already resolved.
+		return nil
+	}
+	forwardedDeliveries, err := c.Store.ForwardedIntelDeliveriesByAttempt(ctx, tx, forwardingAttempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "forwarded intel-deliveries by attempt from store", meh.Details{"attempt_id": forwardingAttempt.ID})
+	}
+	configuredQuorum, err := c.Store.ForwardChannelQuorumByChannel(ctx, tx, forwardingAttempt.Channel)
+	if err != nil {
+		return meh.Wrap(err, "forward-channel-quorum by channel from store", meh.Details{"channel_id": forwardingAttempt.Channel})
+	}
+	requiredSuccessful := forwardQuorum(configuredQuorum, len(forwardedDeliveries))
+	successful := 0
+	active := 0
+	for _, forwardedDelivery := range forwardedDeliveries {
+		if forwardedDelivery.IsActive {
+			active++
+		} else if forwardedDelivery.Success {
+			successful++
+		}
+	}
+	if successful >= requiredSuccessful {
+		err = c.MarkIntelDeliveryAttemptAsDeliveredTx(ctx, tx, forwardingAttempt.ID, uuid.NullUUID{})
+		if err != nil {
+			return meh.Wrap(err, "mark forwarding intel-delivery-attempt as delivered",
+				meh.Details{"attempt_id": forwardingAttempt.ID})
+		}
+		return nil
+	}
+	if active > 0 {
+		// Still waiting.
+		return nil
+	}
+	err = c.MarkIntelDeliveryAttemptAsFailed(ctx, tx, forwardingAttempt.ID,
+		nulls.NewString(fmt.Sprintf("%d of %d required forwarded deliveries succeeded", successful, requiredSuccessful)))
+	if err != nil {
+		return meh.Wrap(err, "mark forwarding intel-delivery-attempt as failed", meh.Details{"attempt_id": forwardingAttempt.ID})
+	}
+	return nil
+}
+
+// cancelForwardedDeliveries cancels all active intel-deliveries, that were
+// forwarded by the intel-delivery-attempt with the given id. This includes
+// their active attempts and deliveries forwarded by them.
+func (c *Controller) cancelForwardedDeliveries(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, reason string) error {
+	forwardedDeliveries, err := c.Store.ForwardedIntelDeliveriesByAttempt(ctx, tx, attemptID)
+	if err != nil {
+		return meh.Wrap(err, "forwarded intel-deliveries by attempt from store", meh.Details{"attempt_id": attemptID})
+	}
+	newNote := nulls.NewString(reason)
+	for _, forwardedDelivery := range forwardedDeliveries {
+		if !forwardedDelivery.IsActive {
+			continue
+		}
+		lockedDelivery, err := c.Store.IntelDeliveryByIDAndLockOrWait(ctx, tx, forwardedDelivery.ID)
+		if err != nil {
+			return meh.Wrap(err, "forwarded intel-delivery by id from store", meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+		if !lockedDelivery.IsActive {
+			continue
+		}
+		// Cancel all ongoing attempts.
+		activeAttempts, err := c.Store.ActiveIntelDeliveryAttemptsByDelivery(ctx, tx, forwardedDelivery.ID)
+		if err != nil {
+			return meh.Wrap(err, "active intel-delivery-attempts by delivery from store",
+				meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+		for _, attempt := range activeAttempts {
+			err = c.Store.UpdateIntelDeliveryAttemptStatusByID(ctx, tx, attempt.ID, false, store.IntelDeliveryStatusCanceled, newNote)
+			if err != nil {
+				return meh.Wrap(err, "update intel-delivery-attempt status by id", meh.Details{"attempt_id": attempt.ID})
+			}
+			updatedAttempt, err := c.Store.IntelDeliveryAttemptByID(ctx, tx, attempt.ID)
+			if err != nil {
+				return meh.Wrap(err, "updated intel-delivery-attempt by id", meh.Details{"attempt_id": attempt.ID})
+			}
+			err = c.Notifier.NotifyIntelDeliveryAttemptStatusUpdated(ctx, tx, updatedAttempt)
+			if err != nil {
+				return meh.Wrap(err, "notify about updated intel-delivery-attempt", meh.Details{"updated": updatedAttempt})
+			}
+			err = c.cancelForwardedDeliveries(ctx, tx, attempt.ID, reason)
+			if err != nil {
+				return meh.Wrap(err, "cancel forwarded deliveries", meh.Details{"attempt_id": attempt.ID})
+			}
+		}
+		// Mark delivery as canceled and notify.
+		const newDeliveryIsActive = false
+		const newDeliverySuccess = false
+		err = c.Store.UpdateIntelDeliveryStatusByDelivery(ctx, tx, forwardedDelivery.ID, newDeliveryIsActive, newDeliverySuccess, newNote)
+		if err != nil {
+			return meh.Wrap(err, "update intel-delivery-status in store", meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+		err = c.Notifier.NotifyIntelDeliveryStatusUpdated(ctx, tx, forwardedDelivery.ID, newDeliveryIsActive, newDeliverySuccess, newNote)
+		if err != nil {
+			return meh.Wrap(err, "notify intel-delivery-status updated", meh.Details{"delivery_id": forwardedDelivery.ID})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,810 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+func Test_forwardQuorum(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		assert.Equal(t, 1, forwardQuorum(0, 4), "should require at least one")
+	})
+	t.Run("negative", func(t *testing.T) {
+		assert.Equal(t, 1, forwardQuorum(-2, 4), "should require at least one")
+	})
+	t.Run("within forwarded", func(t *testing.T) {
+		assert.Equal(t, 3, forwardQuorum(3, 4), "should use configured quorum")
+	})
+	t.Run("exceeds forwarded", func(t *testing.T) {
+		assert.Equal(t, 2, forwardQuorum(5, 2), "should limit to forwarded deliveries")
+	})
+	t.Run("no forwarded", func(t *testing.T) {
+		assert.Equal(t, 1, forwardQuorum(2, 0), "should require at least one")
+	})
+}
+
+// ControllerForwardIntelDeliveryAttemptSuite tests
+// Controller.forwardIntelDeliveryAttempt.
+type ControllerForwardIntelDeliveryAttemptSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	tx               *testutil.DBTx
+	sampleDelivery   store.IntelDelivery
+	sampleAttempt    store.IntelDeliveryAttempt
+	sampleTargets    []uuid.UUID
+	sampleForwarded  store.IntelDelivery
+	forwardingParent store.IntelDelivery
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    testutil.NewUUIDV4(),
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+		Success:  false,
+	}
+	suite.sampleAttempt = store.IntelDeliveryAttempt{
+		ID:        testutil.NewUUIDV4(),
+		Delivery:  suite.sampleDelivery.ID,
+		Channel:   testutil.NewUUIDV4(),
+		CreatedAt: time.Date(2022, 10, 3, 8, 12, 0, 0, time.UTC),
+		IsActive:  true,
+		Status:    store.IntelDeliveryStatusOpen,
+		StatusTS:  time.Date(2022, 10, 3, 8, 12, 0, 0, time.UTC),
+	}
+	suite.sampleTargets = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+	suite.sampleForwarded = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleDelivery.Intel,
+		To:       suite.sampleTargets[0],
+		IsActive: true,
+		Success:  false,
+	}
+	suite.forwardingParent = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleDelivery.Intel,
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+		Success:  false,
+	}
+
+	suite.ctrl.Store.On("ForwardTargetAddressBookEntriesByChannel", mock.Anything, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.sampleTargets, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return(suite.sampleForwarded, nil).Maybe()
+	suite.ctrl.Store.On("LinkIntelDeliveryToForwardingAttempt", mock.Anything, suite.tx, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryCreated", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrSkip", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil).Maybe()
+	// Return inactive deliveries for not having to mock the whole delivery process
+	// in lookAfterDelivery.
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.forwardingParent.ID).
+		Return(suite.forwardingParent, nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, mock.Anything).
+		Return(store.IntelDelivery{IsActive: false}, nil).Maybe()
+	suite.T().Cleanup(func() {
+		suite.ctrl.Store.AssertExpectations(suite.T())
+		suite.ctrl.Notifier.AssertExpectations(suite.T())
+	})
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestRetrieveTargetsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardTargetAddressBookEntriesByChannel")
+	suite.ctrl.Store.On("ForwardTargetAddressBookEntriesByChannel", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestRetrieveForwardChainFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardingAttemptByDelivery")
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestNoEntriesToForwardTo() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardTargetAddressBookEntriesByChannel")
+	suite.ctrl.Store.On("ForwardTargetAddressBookEntriesByChannel", mock.Anything, mock.Anything, mock.Anything).
+		Return([]uuid.UUID{}, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleAttempt.ID, false,
+		store.IntelDeliveryStatusFailed, mock.Anything).
+		Return(nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDelivery", mock.Anything, mock.Anything, mock.Anything)
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "IntelDeliveryByID", mock.Anything, mock.Anything, suite.sampleDelivery.ID)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestLoopProtection() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardTargetAddressBookEntriesByChannel")
+	suite.ctrl.Store.On("ForwardTargetAddressBookEntriesByChannel", mock.Anything, mock.Anything, mock.Anything).
+		Return([]uuid.UUID{suite.sampleDelivery.To, suite.forwardingParent.To, suite.sampleTargets[0]}, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardingAttemptByDelivery")
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(store.IntelDeliveryAttempt{Delivery: suite.forwardingParent.ID}, true, nil).Once()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDelivery")
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, suite.tx, store.IntelDelivery{
+		Intel:    suite.sampleDelivery.Intel,
+		To:       suite.sampleTargets[0],
+		IsActive: true,
+		Success:  false,
+	}).Return(suite.sampleForwarded, nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestCreateDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDelivery")
+	suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDelivery{}, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestLinkDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "LinkIntelDeliveryToForwardingAttempt")
+	suite.ctrl.Store.On("LinkIntelDeliveryToForwardingAttempt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestNotifyDeliveryCreatedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryCreated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryCreated", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestUpdateAttemptStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestNotifyAttemptStatusUpdatedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryAttemptStatusUpdated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestLockForwardedDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "LockIntelDeliveryByIDOrSkip")
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrSkip", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerForwardIntelDeliveryAttemptSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDelivery")
+	for _, target := range suite.sampleTargets {
+		suite.ctrl.Store.On("CreateIntelDelivery", mock.Anything, suite.tx, store.IntelDelivery{
+			Intel:    suite.sampleDelivery.Intel,
+			To:       target,
+			IsActive: true,
+			Success:  false,
+		}).Return(suite.sampleForwarded, nil).Once()
+	}
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "LinkIntelDeliveryToForwardingAttempt")
+	suite.ctrl.Store.On("LinkIntelDeliveryToForwardingAttempt", mock.Anything, suite.tx, suite.sampleForwarded.ID, suite.sampleAttempt.ID).
+		Return(nil).Twice()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleAttempt.ID, true,
+		store.IntelDeliveryStatusDelivering, nulls.NewString("forwarded to 2 entries")).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "LockIntelDeliveryByIDOrSkip")
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrSkip", mock.Anything, suite.tx, suite.sampleForwarded.ID).
+		Return(nil).Twice()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.forwardIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt, suite.sampleDelivery)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_forwardIntelDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerForwardIntelDeliveryAttemptSuite))
+}
+
+// ControllerLookAfterForwardingAttemptSuite tests
+// Controller.lookAfterForwardingAttempt.
+type ControllerLookAfterForwardingAttemptSuite struct {
+	suite.Suite
+	ctrl                      *ControllerMock
+	tx                        *testutil.DBTx
+	sampleDeliveryID          uuid.UUID
+	sampleForwardingDelivery  store.IntelDelivery
+	sampleForwardingAttempt   store.IntelDeliveryAttempt
+	sampleSuccessfulForwarded store.IntelDelivery
+	sampleFailedForwarded     store.IntelDelivery
+	sampleActiveForwarded     store.IntelDelivery
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleDeliveryID = testutil.NewUUIDV4()
+	suite.sampleForwardingDelivery = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    testutil.NewUUIDV4(),
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+		Success:  false,
+	}
+	suite.sampleForwardingAttempt = store.IntelDeliveryAttempt{
+		ID:        testutil.NewUUIDV4(),
+		Delivery:  suite.sampleForwardingDelivery.ID,
+		Channel:   testutil.NewUUIDV4(),
+		CreatedAt: time.Date(2022, 10, 3, 9, 1, 0, 0, time.UTC),
+		IsActive:  true,
+		Status:    store.IntelDeliveryStatusDelivering,
+		StatusTS:  time.Date(2022, 10, 3, 9, 1, 0, 0, time.UTC),
+		Note:      nulls.NewString("forwarded to 3 entries"),
+	}
+	suite.sampleSuccessfulForwarded = store.IntelDelivery{
+		ID:       suite.sampleDeliveryID,
+		Intel:    suite.sampleForwardingDelivery.Intel,
+		To:       testutil.NewUUIDV4(),
+		IsActive: false,
+		Success:  true,
+	}
+	suite.sampleFailedForwarded = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleForwardingDelivery.Intel,
+		To:       testutil.NewUUIDV4(),
+		IsActive: false,
+		Success:  false,
+	}
+	suite.sampleActiveForwarded = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleForwardingDelivery.Intel,
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+		Success:  false,
+	}
+
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleForwardingAttempt, true, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", mock.Anything, suite.tx, suite.sampleForwardingDelivery.ID).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID).
+		Return(suite.sampleForwardingAttempt, nil).Maybe()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID).
+		Return([]store.IntelDelivery{suite.sampleSuccessfulForwarded, suite.sampleActiveForwarded}, nil).Maybe()
+	suite.ctrl.Store.On("ForwardChannelQuorumByChannel", mock.Anything, suite.tx, suite.sampleForwardingAttempt.Channel).
+		Return(int32(2), nil).Maybe()
+	// For resolving the attempt.
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleForwardingDelivery.ID).
+		Return(suite.sampleForwardingDelivery, nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleActiveForwarded.ID).
+		Return(suite.sampleActiveForwarded, nil).Maybe()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleForwardingDelivery.ID).
+		Return([]store.IntelDeliveryAttempt{suite.sampleForwardingAttempt}, nil).Maybe()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, mock.Anything).
+		Return([]store.IntelDeliveryAttempt{}, nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	// Return inactive delivery for not having to mock the whole delivery process
+	// in lookAfterDelivery.
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, mock.Anything).
+		Return(store.IntelDelivery{IsActive: false}, nil).Maybe()
+	suite.T().Cleanup(func() {
+		suite.ctrl.Store.AssertExpectations(suite.T())
+		suite.ctrl.Notifier.AssertExpectations(suite.T())
+	})
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestRetrieveForwardingAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardingAttemptByDelivery")
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestNotForwarded() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardingAttemptByDelivery")
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, suite.sampleDeliveryID).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "LockIntelDeliveryByIDOrWait", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestLockForwardingDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "LockIntelDeliveryByIDOrWait")
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestRetrieveLockedAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryAttemptByID")
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestAttemptInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	inactiveAttempt := suite.sampleForwardingAttempt
+	inactiveAttempt.IsActive = false
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryAttemptByID")
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID).
+		Return(inactiveAttempt, nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestRetrieveForwardedDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestRetrieveQuorumFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardChannelQuorumByChannel")
+	suite.ctrl.Store.On("ForwardChannelQuorumByChannel", mock.Anything, mock.Anything, mock.Anything).
+		Return(int32(0), errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestQuorumNotReachedYet() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "UpdateIntelDeliveryAttemptStatusByID",
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "UpdateIntelDeliveryStatusByDelivery",
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestQuorumReached() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardChannelQuorumByChannel")
+	suite.ctrl.Store.On("ForwardChannelQuorumByChannel", mock.Anything, suite.tx, suite.sampleForwardingAttempt.Channel).
+		Return(int32(1), nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID,
+		false, store.IntelDeliveryStatusDelivered, nulls.String{}).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryStatusByDelivery")
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, suite.sampleActiveForwarded.ID,
+		false, false, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, suite.sampleForwardingDelivery.ID,
+		false, true, mock.Anything).
+		Return(nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestQuorumLimitedToForwarded() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID).
+		Return([]store.IntelDelivery{suite.sampleSuccessfulForwarded}, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID,
+		false, store.IntelDeliveryStatusDelivered, nulls.String{}).
+		Return(nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerLookAfterForwardingAttemptSuite) TestQuorumNotReached() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID).
+		Return([]store.IntelDelivery{suite.sampleSuccessfulForwarded, suite.sampleFailedForwarded}, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleForwardingAttempt.ID,
+		false, store.IntelDeliveryStatusFailed, nulls.NewString("1 of 2 required forwarded deliveries succeeded")).
+		Return(nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterForwardingAttempt(timeout, suite.tx, suite.sampleDeliveryID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "UpdateIntelDeliveryStatusByDelivery",
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func TestController_lookAfterForwardingAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerLookAfterForwardingAttemptSuite))
+}
+
+// ControllerCancelForwardedDeliveriesSuite tests
+// Controller.cancelForwardedDeliveries.
+type ControllerCancelForwardedDeliveriesSuite struct {
+	suite.Suite
+	ctrl                  *ControllerMock
+	tx                    *testutil.DBTx
+	sampleAttemptID       uuid.UUID
+	sampleForwarded       store.IntelDelivery
+	sampleForwardedActive store.IntelDeliveryAttempt
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleForwarded = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    testutil.NewUUIDV4(),
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+		Success:  false,
+	}
+	suite.sampleForwardedActive = store.IntelDeliveryAttempt{
+		ID:        testutil.NewUUIDV4(),
+		Delivery:  suite.sampleForwarded.ID,
+		Channel:   testutil.NewUUIDV4(),
+		CreatedAt: time.Date(2022, 10, 3, 9, 24, 0, 0, time.UTC),
+		IsActive:  true,
+		Status:    store.IntelDeliveryStatusAwaitingAck,
+		StatusTS:  time.Date(2022, 10, 3, 9, 24, 0, 0, time.UTC),
+	}
+
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleAttemptID).
+		Return([]store.IntelDelivery{suite.sampleForwarded}, nil).Maybe()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, mock.Anything).
+		Return([]store.IntelDelivery{}, nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleForwarded.ID).
+		Return(suite.sampleForwarded, nil).Maybe()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleForwarded.ID).
+		Return([]store.IntelDeliveryAttempt{suite.sampleForwardedActive}, nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, suite.tx, suite.sampleForwardedActive.ID).
+		Return(suite.sampleForwardedActive, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", mock.Anything, suite.tx, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", mock.Anything, suite.tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.T().Cleanup(func() {
+		suite.ctrl.Store.AssertExpectations(suite.T())
+		suite.ctrl.Notifier.AssertExpectations(suite.T())
+	})
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestRetrieveForwardedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestSkipInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	inactive := suite.sampleForwarded
+	inactive.IsActive = false
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleAttemptID).
+		Return([]store.IntelDelivery{inactive}, nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "IntelDeliveryByIDAndLockOrWait", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestLockFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByIDAndLockOrWait")
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDelivery{}, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestInactiveAfterLock() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	inactive := suite.sampleForwarded
+	inactive.IsActive = false
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveryByIDAndLockOrWait")
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, suite.sampleForwarded.ID).
+		Return(inactive, nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "UpdateIntelDeliveryStatusByDelivery",
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestRetrieveActiveAttemptsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ActiveIntelDeliveryAttemptsByDelivery")
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestCancelAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestUpdateDeliveryStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryStatusByDelivery")
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelForwardedDeliveriesSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryAttemptStatusByID")
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, suite.sampleForwardedActive.ID,
+		false, store.IntelDeliveryStatusCanceled, nulls.NewString("meow")).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardedIntelDeliveriesByAttempt")
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleAttemptID).
+		Return([]store.IntelDelivery{suite.sampleForwarded}, nil).Once()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, suite.sampleForwardedActive.ID).
+		Return([]store.IntelDelivery{}, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryStatusByDelivery")
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, suite.sampleForwarded.ID,
+		false, false, nulls.NewString("meow")).
+		Return(nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelDeliveryStatusUpdated")
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", mock.Anything, suite.tx, suite.sampleForwarded.ID,
+		false, false, nulls.NewString("meow")).
+		Return(nil).Once()
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.cancelForwardedDeliveries(timeout, suite.tx, suite.sampleAttemptID, "meow")
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_cancelForwardedDeliveries(t *testing.T) {
+	suite.Run(t, new(ControllerCancelForwardedDeliveriesSuite))
+}
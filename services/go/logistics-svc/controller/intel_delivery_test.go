@@ -30,6 +30,16 @@ type controllerLookAfterDeliverySuite struct {
 
 func (suite *controllerLookAfterDeliverySuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.tx = &testutil.DBTx{}
 	suite.sampleID = testutil.NewUUIDV4()
 	userID := testutil.NewUUIDV4()
@@ -102,6 +112,10 @@ func (suite *controllerLookAfterDeliverySuite) SetupTest() {
 	}
 	suite.ctrl.Store.On("IsAutoDeliveryEnabledForAddressBookEntry", mock.Anything, mock.Anything, mock.Anything).
 		Return(true, nil).Maybe()
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(false, nil).Maybe()
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil).Maybe()
 }
 
 func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelFail() {
@@ -119,6 +133,44 @@ func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelFail() {
 	wait()
 }
 
+func (suite *controllerLookAfterDeliverySuite) TestScheduledScheduleCheckFail() {
+	suite.sampleDelivery.DeliverAt = nulls.NewTime(time.Now().Add(time.Hour))
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleID, suite.sampleDelivery.DeliverAt.Time).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestScheduled() {
+	suite.sampleDelivery.DeliverAt = nulls.NewTime(time.Now().Add(time.Hour))
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleID, suite.sampleDelivery.DeliverAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.Require().NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
 func (suite *controllerLookAfterDeliverySuite) TestRetrieveTimedOutDeliveryAttemptsFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
@@ -323,7 +375,7 @@ func (suite *controllerLookAfterDeliverySuite) TestNextChannelForDeliveryAttempt
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(store.Channel{}, false, errors.New("sad life"))
+		Return(store.Channel{}, time.Time{}, false, errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
@@ -345,7 +397,7 @@ func (suite *controllerLookAfterDeliverySuite) TestUpdateDeliveryStatusBecauseOf
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(store.Channel{}, false, nil)
+		Return(store.Channel{}, time.Time{}, false, nil)
 	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
 		Return(errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -369,7 +421,7 @@ func (suite *controllerLookAfterDeliverySuite) TestNotifyDeliveryStatusUpdatedBe
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(store.Channel{}, false, nil)
+		Return(store.Channel{}, time.Time{}, false, nil)
 	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
 		Return(nil)
 	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
@@ -395,7 +447,7 @@ func (suite *controllerLookAfterDeliverySuite) TestOKNoMoreChannels() {
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(store.Channel{}, false, nil)
+		Return(store.Channel{}, time.Time{}, false, nil)
 	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
 		Return(nil)
 	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
@@ -412,6 +464,32 @@ func (suite *controllerLookAfterDeliverySuite) TestOKNoMoreChannels() {
 	wait()
 }
 
+func (suite *controllerLookAfterDeliverySuite) TestRetryBackoffNotElapsed() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	notBefore := time.Now().Add(time.Hour)
+	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleChannel, notBefore, true, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleID, notBefore).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
 func (suite *controllerLookAfterDeliverySuite) TestCreateNewAttemptFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
@@ -421,7 +499,7 @@ func (suite *controllerLookAfterDeliverySuite) TestCreateNewAttemptFail() {
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(suite.sampleChannel, true, nil)
+		Return(suite.sampleChannel, time.Time{}, true, nil)
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
 		Return(store.IntelDeliveryAttempt{}, errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -436,7 +514,65 @@ func (suite *controllerLookAfterDeliverySuite) TestCreateNewAttemptFail() {
 	wait()
 }
 
-func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelForNotifyFail() {
+func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelOfDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
+		Return(store.Intel{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestIntelExpired() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleIntel.IsExpired = true
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
+		Return(suite.sampleIntel, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestIntelInvalid() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleIntel.IsValid = false
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
+		Return(suite.sampleIntel, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelForNewAttemptFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
 		Return(suite.sampleDelivery, nil)
@@ -445,9 +581,10 @@ func (suite *controllerLookAfterDeliverySuite) TestRetrieveIntelForNotifyFail()
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(suite.sampleChannel, true, nil)
-	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
-		Return(suite.sampleDeliveryAttempts[0], nil)
+		Return(suite.sampleChannel, time.Time{}, true, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
+		Return(suite.sampleIntel, nil).Once()
 	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
 		Return(store.Intel{}, errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -471,11 +608,9 @@ func (suite *controllerLookAfterDeliverySuite) TestRetrieveAssignedEntryFail() {
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(suite.sampleChannel, true, nil)
+		Return(suite.sampleChannel, time.Time{}, true, nil)
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
-		Return(suite.sampleDeliveryAttempts[0], nil)
-	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
-		Return(suite.sampleIntel, nil).Once()
+		Return(suite.sampleDeliveryAttempts[1], nil)
 	suite.ctrl.Store.On("AddressBookEntryByID", timeout, suite.tx, suite.sampleDelivery.To, uuid.NullUUID{}).
 		Return(store.AddressBookEntryDetailed{}, errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -499,14 +634,12 @@ func (suite *controllerLookAfterDeliverySuite) TestNotifyAttemptCreatedFail() {
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(suite.sampleChannel, true, nil)
+		Return(suite.sampleChannel, time.Time{}, true, nil)
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
-		Return(suite.sampleDeliveryAttempts[0], nil)
-	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
-		Return(suite.sampleIntel, nil).Once()
+		Return(suite.sampleDeliveryAttempts[1], nil)
 	suite.ctrl.Store.On("AddressBookEntryByID", timeout, suite.tx, suite.sampleDelivery.To, uuid.NullUUID{}).
 		Return(suite.sampleAssignedEntry, nil)
-	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, suite.sampleDeliveryAttempts[0],
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, suite.sampleDeliveryAttempts[1],
 		suite.sampleDelivery, suite.sampleAssignedEntry, suite.sampleIntel).
 		Return(errors.New("sad life"))
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -530,7 +663,7 @@ func (suite *controllerLookAfterDeliverySuite) TestOKWithNewAttempt() {
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
 		Return(nil, nil)
 	suite.ctrl.Store.On("NextChannelForDeliveryAttempt", timeout, suite.tx, suite.sampleID).
-		Return(suite.sampleChannel, true, nil)
+		Return(suite.sampleChannel, time.Time{}, true, nil)
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.MatchedBy(func(v any) bool {
 		expect := store.IntelDeliveryAttempt{
 			Delivery:  suite.sampleID,
@@ -549,12 +682,10 @@ func (suite *controllerLookAfterDeliverySuite) TestOKWithNewAttempt() {
 		vv.StatusTS = time.Time{}
 		return vv == expect
 	})).
-		Return(suite.sampleDeliveryAttempts[0], nil)
-	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
-		Return(suite.sampleIntel, nil).Once()
+		Return(suite.sampleDeliveryAttempts[1], nil)
 	suite.ctrl.Store.On("AddressBookEntryByID", timeout, suite.tx, suite.sampleDelivery.To, uuid.NullUUID{}).
 		Return(suite.sampleAssignedEntry, nil)
-	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, suite.sampleDeliveryAttempts[0],
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, suite.sampleDeliveryAttempts[1],
 		suite.sampleDelivery, suite.sampleAssignedEntry, suite.sampleIntel).
 		Return(nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
@@ -569,6 +700,241 @@ func (suite *controllerLookAfterDeliverySuite) TestOKWithNewAttempt() {
 	wait()
 }
 
+func (suite *controllerLookAfterDeliverySuite) TestCheckFanOutFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(false, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestFanOutChannelsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDeliveryAttempts, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Once()
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return(nil, time.Time{}, false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestFanOutNoMoreChannelsWithActiveAttempts() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDeliveryAttempts, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Once()
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return(nil, time.Time{}, false, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestFanOutNoMoreChannels() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Once()
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return(nil, time.Time{}, false, nil)
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleID, false, false, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestOKWithFanOutAttempts() {
+	otherChannel := suite.sampleChannel
+	otherChannel.ID = testutil.NewUUIDV4()
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDeliveryAttempts, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Once()
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return([]store.Channel{suite.sampleChannel, otherChannel}, time.Time{}, true, nil)
+	for _, channel := range []store.Channel{suite.sampleChannel, otherChannel} {
+		channelID := channel.ID
+		suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.MatchedBy(func(v store.IntelDeliveryAttempt) bool {
+			return v.Delivery == suite.sampleID && v.Channel == channelID && v.IsActive
+		})).
+			Return(suite.sampleDeliveryAttempts[1], nil).Once()
+	}
+	suite.ctrl.Store.On("AddressBookEntryByID", timeout, suite.tx, suite.sampleDelivery.To, uuid.NullUUID{}).
+		Return(suite.sampleAssignedEntry, nil).Twice()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, suite.sampleDeliveryAttempts[1],
+		suite.sampleDelivery, suite.sampleAssignedEntry, suite.sampleIntel).
+		Return(nil).Twice()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestFanOutForwardWithoutTargets() {
+	forwardChannel := suite.sampleChannel
+	forwardChannel.ID = testutil.NewUUIDV4()
+	forwardChannel.Type = store.ChannelTypeForwardToGroup
+	forwardAttempt := store.IntelDeliveryAttempt{
+		ID:       testutil.NewUUIDV4(),
+		Delivery: suite.sampleID,
+		Channel:  forwardChannel.ID,
+		IsActive: true,
+		Status:   store.IntelDeliveryStatusOpen,
+	}
+	failedForwardAttempt := forwardAttempt
+	failedForwardAttempt.IsActive = false
+	failedForwardAttempt.Status = store.IntelDeliveryStatusFailed
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return([]store.IntelDeliveryAttempt{suite.sampleDeliveryAttempts[1]}, nil).Once()
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Twice()
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return([]store.Channel{forwardChannel, suite.sampleChannel}, time.Time{}, true, nil).Once()
+	createForwardAttempt := suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.MatchedBy(func(v store.IntelDeliveryAttempt) bool {
+		return v.Channel == forwardChannel.ID
+	})).
+		Return(forwardAttempt, nil).Once()
+	createOtherAttempt := suite.ctrl.Store.On("CreateIntelDeliveryAttempt", timeout, suite.tx, mock.MatchedBy(func(v store.IntelDeliveryAttempt) bool {
+		return v.Channel == suite.sampleChannel.ID
+	})).
+		Return(suite.sampleDeliveryAttempts[1], nil).Once()
+	// Channels must only be recomputed after all attempts have been created.
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return(nil, time.Time{}, false, nil).Once().NotBefore(createForwardAttempt, createOtherAttempt)
+	suite.ctrl.Store.On("AddressBookEntryByID", timeout, suite.tx, suite.sampleDelivery.To, uuid.NullUUID{}).
+		Return(suite.sampleAssignedEntry, nil).Twice()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated", timeout, suite.tx, mock.Anything,
+		suite.sampleDelivery, suite.sampleAssignedEntry, suite.sampleIntel).
+		Return(nil).Twice()
+	suite.ctrl.Store.On("ForwardTargetAddressBookEntriesByChannel", timeout, suite.tx, forwardChannel.ID).
+		Return([]uuid.UUID{}, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, forwardAttempt.ID, false,
+		store.IntelDeliveryStatusFailed, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, forwardAttempt.ID).
+		Return(failedForwardAttempt, nil)
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", timeout, suite.tx, failedForwardAttempt).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *controllerLookAfterDeliverySuite) TestFanOutChannelsNotUsableYet() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("TimedOutIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleID).
+		Return(nil, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IsFanOutEnabledForIntelDelivery")
+	suite.ctrl.Store.On("IsFanOutEnabledForIntelDelivery", timeout, suite.tx, suite.sampleID).
+		Return(true, nil).Once()
+	nextUsableLater := time.Now().Add(time.Hour)
+	suite.ctrl.Store.On("ChannelsForFanOutDeliveryAttempts", timeout, suite.tx, suite.sampleID).
+		Return([]store.Channel{}, nextUsableLater, true, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ScheduleIntelDeliveryCheck")
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleID, nextUsableLater).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.lookAfterDelivery(timeout, suite.tx, suite.sampleID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
 func TestController_lookAfterDelivery(t *testing.T) {
 	suite.Run(t, new(controllerLookAfterDeliverySuite))
 }
@@ -813,6 +1179,16 @@ type ControllerMarkIntelDeliveryAndAttemptAsDeliveredSuite struct {
 func (suite *ControllerMarkIntelDeliveryAndAttemptAsDeliveredSuite) SetupTest() {
 	suite.tx = &testutil.DBTx{}
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.sampleDeliveryID = testutil.NewUUIDV4()
 	suite.sampleAttemptID = testutil.NewUUIDV4()
 	suite.sampleDelivery = store.IntelDelivery{
@@ -1069,6 +1445,16 @@ type ControllerCancelIntelDeliveryByIDSuite struct {
 func (suite *ControllerCancelIntelDeliveryByIDSuite) SetupTest() {
 	suite.tx = &testutil.DBTx{}
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
 	suite.sampleDeliveryID = testutil.NewUUIDV4()
 	suite.sampleDelivery = store.IntelDelivery{
@@ -1118,185 +1504,383 @@ func (suite *ControllerCancelIntelDeliveryByIDSuite) TestRetrieveDeliveryFail()
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestDeliveryInactive() {
-	suite.sampleDelivery.IsActive = false
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestDeliveryInactive() {
+	suite.sampleDelivery.IsActive = false
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestRetrieveActiveDeliveryAttemptsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestUpdateIntelDeliveryAttemptStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleActiveAttempts, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestRetrieveUpdatedIntelDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleActiveAttempts, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestNotifyAboutUpdatedDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleActiveAttempts, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleActiveAttempts[0], nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", timeout, suite.tx, suite.sampleActiveAttempts[0]).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestUpdateDeliveryStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(nil, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestNotifyAboutUpdatedDeliveryStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(nil, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) testOK(success bool, note nulls.String) {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(suite.sampleActiveAttempts, nil).Once()
+	// Expect attempt updates for active ones.
+	for _, sampleActiveAttempt := range suite.sampleActiveAttempts {
+		newAttempt := sampleActiveAttempt
+		newAttempt.IsActive = false
+		suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, sampleActiveAttempt.ID,
+			false, store.IntelDeliveryStatusCanceled, mock.Anything).
+			Return(nil).Once()
+		suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, sampleActiveAttempt.ID).
+			Return(newAttempt, nil).Once()
+		suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", timeout, suite.tx, newAttempt).
+			Return(nil).Once()
+	}
+	// Expect intel delivery updates.
+	matchNote := mock.MatchedBy(func(n nulls.String) bool {
+		suite.True(n.Valid, "should set note")
+		suite.NotEmpty(n.Valid, "should set note")
+		if note.Valid {
+			suite.Equal(note.String, n.String, "should set passed note")
+		}
+		return true
+	})
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, false, success, matchNote).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleDeliveryID, false, success, matchNote).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, success, note)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithSuccessWithoutNote() {
+	suite.testOK(true, nulls.String{})
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithoutSuccessWithoutNote() {
+	suite.testOK(false, nulls.String{})
+}
+
+func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithSuccessWithNote() {
+	suite.testOK(true, nulls.NewString("wait"))
+}
+
+func TestController_CancelIntelDeliveryByID(t *testing.T) {
+	suite.Run(t, new(ControllerCancelIntelDeliveryByIDSuite))
+}
+
+// ControllerUpdateIntelDeliveryDeliverAtSuite tests
+// Controller.UpdateIntelDeliveryDeliverAt.
+type ControllerUpdateIntelDeliveryDeliverAtSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	tx               *testutil.DBTx
+	sampleDeliveryID uuid.UUID
+	sampleDelivery   store.IntelDelivery
+	sampleDeliverAt  nulls.Time
+}
+
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) SetupTest() {
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleDeliveryID = testutil.NewUUIDV4()
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:        suite.sampleDeliveryID,
+		Intel:     testutil.NewUUIDV4(),
+		To:        testutil.NewUUIDV4(),
+		IsActive:  true,
+		Success:   false,
+		Note:      nulls.NewString("shelf"),
+		DeliverAt: nulls.NewTime(time.Now().Add(time.Hour)),
+	}
+	suite.sampleDeliverAt = nulls.NewTime(time.Now().Add(2 * time.Hour))
+}
+
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestRetrieveDeliveryFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(suite.sampleDelivery, nil).Once()
+		Return(store.IntelDelivery{}, errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestRetrieveActiveDeliveryAttemptsFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestDeliveryInactive() {
+	suite.sampleDelivery.IsActive = false
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(nil, errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestUpdateIntelDeliveryAttemptStatusFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestDeliveryNotScheduled() {
+	suite.sampleDelivery.DeliverAt = nulls.Time{}
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(suite.sampleActiveAttempts, nil).Once()
-	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
-		mock.Anything, mock.Anything, mock.Anything).
-		Return(errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestRetrieveUpdatedIntelDeliveryAttemptFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestDeliveryAlreadyFired() {
+	suite.sampleDelivery.DeliverAt = nulls.NewTime(time.Now().Add(-time.Minute))
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(suite.sampleActiveAttempts, nil).Once()
-	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
-		mock.Anything, mock.Anything, mock.Anything).
-		Return(nil).Once()
-	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
-		Return(store.IntelDeliveryAttempt{}, errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestNotifyAboutUpdatedDeliveryAttemptFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestUpdateFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(suite.sampleActiveAttempts, nil).Once()
-	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, mock.Anything,
-		mock.Anything, mock.Anything, mock.Anything).
-		Return(nil).Once()
-	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
-		Return(suite.sampleActiveAttempts[0], nil).Once()
-	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", timeout, suite.tx, suite.sampleActiveAttempts[0]).
+	suite.ctrl.Store.On("UpdateIntelDeliveryDeliverAt", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
 		Return(errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
-	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestUpdateDeliveryStatusFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestNotifyFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(nil, nil).Once()
-	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
+	suite.ctrl.Store.On("UpdateIntelDeliveryDeliverAt", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryDeliverAtUpdated", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
 		Return(errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestNotifyAboutUpdatedDeliveryStatusFail() {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestLookAfterDeliveryFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(nil, nil).Once()
-	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
+	suite.ctrl.Store.On("UpdateIntelDeliveryDeliverAt", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
 		Return(nil).Once()
-	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleDeliveryID, mock.Anything, mock.Anything, mock.Anything).
-		Return(errors.New("sad life")).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryDeliverAtUpdated", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(store.IntelDelivery{}, errors.New("sad life")).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, false, nulls.String{})
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Error(err, "should fail")
 	}()
 
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) testOK(success bool, note nulls.String) {
+func (suite *ControllerUpdateIntelDeliveryDeliverAtSuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	updatedDelivery := suite.sampleDelivery
+	updatedDelivery.DeliverAt = suite.sampleDeliverAt
 	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDeliveryID).
 		Return(suite.sampleDelivery, nil).Once()
-	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, suite.sampleDeliveryID).
-		Return(suite.sampleActiveAttempts, nil).Once()
-	// Expect attempt updates for active ones.
-	for _, sampleActiveAttempt := range suite.sampleActiveAttempts {
-		newAttempt := sampleActiveAttempt
-		newAttempt.IsActive = false
-		suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", timeout, suite.tx, sampleActiveAttempt.ID,
-			false, store.IntelDeliveryStatusCanceled, mock.Anything).
-			Return(nil).Once()
-		suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, sampleActiveAttempt.ID).
-			Return(newAttempt, nil).Once()
-		suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", timeout, suite.tx, newAttempt).
-			Return(nil).Once()
-	}
-	// Expect intel delivery updates.
-	matchNote := mock.MatchedBy(func(n nulls.String) bool {
-		suite.True(n.Valid, "should set note")
-		suite.NotEmpty(n.Valid, "should set note")
-		if note.Valid {
-			suite.Equal(note.String, n.String, "should set passed note")
-		}
-		return true
-	})
-	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, suite.sampleDeliveryID, false, success, matchNote).
+	suite.ctrl.Store.On("UpdateIntelDeliveryDeliverAt", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
 		Return(nil).Once()
-	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, suite.sampleDeliveryID, false, success, matchNote).
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryDeliverAtUpdated", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, suite.sampleDeliveryID).
+		Return(updatedDelivery, nil).Once()
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleDelivery.Intel).
+		Return(store.Intel{ID: suite.sampleDelivery.Intel, IsValid: true}, nil).Once()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", timeout, suite.tx, suite.sampleDeliveryID, suite.sampleDeliverAt.Time).
 		Return(nil).Once()
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
 		defer cancel()
-		err := suite.ctrl.Ctrl.CancelIntelDeliveryByID(timeout, suite.sampleDeliveryID, success, note)
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryDeliverAt(timeout, suite.sampleDeliveryID, suite.sampleDeliverAt)
 		suite.Require().NoError(err, "should not fail")
 		suite.True(suite.tx.IsCommitted, "should commit tx")
 	}()
@@ -1304,20 +1888,8 @@ func (suite *ControllerCancelIntelDeliveryByIDSuite) testOK(success bool, note n
 	wait()
 }
 
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithSuccessWithoutNote() {
-	suite.testOK(true, nulls.String{})
-}
-
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithoutSuccessWithoutNote() {
-	suite.testOK(false, nulls.String{})
-}
-
-func (suite *ControllerCancelIntelDeliveryByIDSuite) TestOKWithSuccessWithNote() {
-	suite.testOK(true, nulls.NewString("wait"))
-}
-
-func TestController_CancelIntelDeliveryByID(t *testing.T) {
-	suite.Run(t, new(ControllerCancelIntelDeliveryByIDSuite))
+func TestController_UpdateIntelDeliveryDeliverAt(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateIntelDeliveryDeliverAtSuite))
 }
 
 // ControllerMarkIntelDeliveryAttemptAsDeliveredSuite tests
@@ -1334,6 +1906,16 @@ type ControllerMarkIntelDeliveryAttemptAsDeliveredSuite struct {
 
 func (suite *ControllerMarkIntelDeliveryAttemptAsDeliveredSuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.tx = &testutil.DBTx{}
 	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
 	suite.sampleAttemptID = testutil.NewUUIDV4()
@@ -1422,6 +2004,135 @@ func TestController_MarkIntelDeliveryAttemptAsDelivered(t *testing.T) {
 	suite.Run(t, new(ControllerMarkIntelDeliveryAttemptAsDeliveredSuite))
 }
 
+// ControllerAcknowledgeIntelDeliveryAttemptSuite tests
+// Controller.AcknowledgeIntelDeliveryAttempt.
+type ControllerAcknowledgeIntelDeliveryAttemptSuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleBy       uuid.UUID
+	sampleAttempt  store.IntelDeliveryAttempt
+	sampleDelivery store.IntelDelivery
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleBy = testutil.NewUUIDV4()
+	suite.sampleAttempt = store.IntelDeliveryAttempt{
+		ID:       testutil.NewUUIDV4(),
+		Delivery: testutil.NewUUIDV4(),
+		Channel:  testutil.NewUUIDV4(),
+		IsActive: true,
+		Status:   store.IntelDeliveryStatusAwaitingAck,
+	}
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:       suite.sampleAttempt.Delivery,
+		Intel:    testutil.NewUUIDV4(),
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+	}
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) TestRetrieveAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.IntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) TestLockDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", timeout, suite.tx, suite.sampleAttempt.Delivery).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) TestAttemptInactive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	inactiveAttempt := suite.sampleAttempt
+	inactiveAttempt.IsActive = false
+	inactiveAttempt.Status = store.IntelDeliveryStatusCanceled
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", timeout, suite.tx, suite.sampleAttempt.Delivery).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(inactiveAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) TestSetAcknowledgedByFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Twice()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", timeout, suite.tx, suite.sampleAttempt.Delivery).
+		Return(nil).Once()
+	suite.ctrl.Store.On("SetIntelDeliveryAttemptAcknowledgedBy", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAcknowledgeIntelDeliveryAttemptSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Twice()
+	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", timeout, suite.tx, suite.sampleAttempt.Delivery).
+		Return(nil).Once()
+	suite.ctrl.Store.On("SetIntelDeliveryAttemptAcknowledgedBy", timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy).
+		Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, meh.NewErr("done", "", nil)).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.AcknowledgeIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt.ID, suite.sampleBy)
+		suite.Require().Error(err, "should fail because of marking as delivered being called")
+		suite.EqualValues("done", meh.ErrorCode(err), "should mark as delivered")
+	}()
+
+	wait()
+}
+
+func TestController_AcknowledgeIntelDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerAcknowledgeIntelDeliveryAttemptSuite))
+}
+
 // ControllerMarkIntelDeliveryAsDeliveredSuite tests
 // Controller.MarkIntelDeliveryAsDelivered.
 type ControllerMarkIntelDeliveryAsDeliveredSuite struct {
@@ -1435,6 +2146,16 @@ type ControllerMarkIntelDeliveryAsDeliveredSuite struct {
 
 func (suite *ControllerMarkIntelDeliveryAsDeliveredSuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.tx = &testutil.DBTx{}
 	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
 	suite.sampleDeliveryID = testutil.NewUUIDV4()
@@ -1511,6 +2232,16 @@ type ControllerMarkIntelDeliveryAttemptAsFailedSuite struct {
 
 func (suite *ControllerMarkIntelDeliveryAttemptAsFailedSuite) SetupTest() {
 	suite.ctrl = NewMockController()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	suite.ctrl.Store.On("DueIntelDeliveryEscalationRulesByDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Maybe()
 	suite.tx = &testutil.DBTx{}
 	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
 	suite.sampleAttemptID = testutil.NewUUIDV4()
@@ -1750,6 +2481,7 @@ type ControllerCreateIntelDeliveryAttemptSuite struct {
 	tx         *testutil.DBTx
 	deliveryID uuid.UUID
 	channelID  uuid.UUID
+	channel    store.Channel
 	created    store.IntelDeliveryAttempt
 }
 
@@ -1769,10 +2501,18 @@ func (suite *ControllerCreateIntelDeliveryAttemptSuite) SetupTest() {
 		StatusTS:  time.Now(),
 		Note:      nulls.String{},
 	}
+	suite.channel = store.Channel{
+		ID:   suite.channelID,
+		Type: store.ChannelTypeInAppNotification,
+	}
 	delivery := store.IntelDelivery{IsActive: true}
 
 	suite.ctrl.Store.On("LockIntelDeliveryByIDOrWait", mock.Anything, suite.tx, suite.deliveryID).
 		Return(nil).Maybe()
+	suite.ctrl.Store.On("ChannelMetadataByID", mock.Anything, suite.tx, suite.channelID).
+		Return(suite.channel, nil).Maybe()
+	suite.ctrl.Store.On("ScheduleIntelDeliveryTimeoutCheck", mock.Anything, suite.tx, suite.deliveryID).
+		Return(nil).Maybe()
 	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.deliveryID).
 		Return([]store.IntelDeliveryAttempt{}, nil).Maybe()
 	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.deliveryID).
@@ -1780,7 +2520,7 @@ func (suite *ControllerCreateIntelDeliveryAttemptSuite) SetupTest() {
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", mock.Anything, suite.tx, mock.Anything).
 		Return(suite.created, nil).Maybe()
 	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, mock.Anything).
-		Return(store.Intel{}, nil).Maybe()
+		Return(store.Intel{IsValid: true}, nil).Maybe()
 	suite.ctrl.Store.On("AddressBookEntryByID", mock.Anything, suite.tx, mock.Anything, mock.Anything).
 		Return(store.AddressBookEntryDetailed{}, nil).Maybe()
 	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptCreated",
@@ -1817,6 +2557,47 @@ func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestRetrieveActiveAttemp
 	suite.Error(err, "should fail")
 }
 
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestRetrieveChannelFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ChannelMetadataByID")
+	suite.ctrl.Store.On("ChannelMetadataByID", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.Channel{}, errors.New("sad life")).Once()
+
+	_, err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(context.Background(), suite.deliveryID, suite.channelID)
+	suite.Error(err, "should fail")
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestRetrieveIntelFail() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.Intel{}, errors.New("sad life")).Once()
+
+	_, err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(context.Background(), suite.deliveryID, suite.channelID)
+	suite.Error(err, "should fail")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestIntelInvalid() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.Intel{IsValid: false}, nil).Once()
+
+	_, err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(context.Background(), suite.deliveryID, suite.channelID)
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestIntelExpired() {
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelByID")
+	suite.ctrl.Store.On("IntelByID", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.Intel{IsValid: true, IsExpired: true}, nil).Once()
+
+	_, err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(context.Background(), suite.deliveryID, suite.channelID)
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreateFail() {
 	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "CreateIntelDeliveryAttempt")
 	suite.ctrl.Store.On("CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything).
@@ -1853,6 +2634,76 @@ func TestController_CreateIntelDeliveryAttempt(t *testing.T) {
 	suite.Run(t, new(ControllerCreateIntelDeliveryAttemptSuite))
 }
 
+// ControllerOperationByIntelDeliverySuite tests
+// Controller.OperationByIntelDelivery.
+type ControllerOperationByIntelDeliverySuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleDelivery store.IntelDelivery
+	sampleIntel    store.Intel
+}
+
+func (suite *ControllerOperationByIntelDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleIntel = store.Intel{
+		ID:        testutil.NewUUIDV4(),
+		Operation: testutil.NewUUIDV4(),
+	}
+	suite.sampleDelivery = store.IntelDelivery{
+		ID:    testutil.NewUUIDV4(),
+		Intel: suite.sampleIntel.ID,
+	}
+}
+
+func (suite *ControllerOperationByIntelDeliverySuite) TestBeginTxFail() {
+	suite.ctrl.DB.BeginFail = true
+
+	_, err := suite.ctrl.Ctrl.OperationByIntelDelivery(context.Background(), suite.sampleDelivery.ID)
+	suite.Error(err, "should fail")
+}
+
+func (suite *ControllerOperationByIntelDeliverySuite) TestRetrieveDeliveryFail() {
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(store.IntelDelivery{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	_, err := suite.ctrl.Ctrl.OperationByIntelDelivery(context.Background(), suite.sampleDelivery.ID)
+	suite.Error(err, "should fail")
+	suite.False(suite.tx.IsCommitted, "should not commit tx")
+}
+
+func (suite *ControllerOperationByIntelDeliverySuite) TestRetrieveIntelFail() {
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(store.Intel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	_, err := suite.ctrl.Ctrl.OperationByIntelDelivery(context.Background(), suite.sampleDelivery.ID)
+	suite.Error(err, "should fail")
+	suite.False(suite.tx.IsCommitted, "should not commit tx")
+}
+
+func (suite *ControllerOperationByIntelDeliverySuite) TestOK() {
+	suite.ctrl.Store.On("IntelDeliveryByID", mock.Anything, suite.tx, suite.sampleDelivery.ID).
+		Return(suite.sampleDelivery, nil)
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	got, err := suite.ctrl.Ctrl.OperationByIntelDelivery(context.Background(), suite.sampleDelivery.ID)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(suite.sampleIntel.Operation, got, "should return correct value")
+	suite.True(suite.tx.IsCommitted, "should commit tx")
+}
+
+func TestController_OperationByIntelDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerOperationByIntelDeliverySuite))
+}
+
 // ControllerIntelDeliveryAttemptsByDeliverySuite tests
 // Controller.IntelDeliveryAttemptsByDelivery.
 type ControllerIntelDeliveryAttemptsByDeliverySuite struct {
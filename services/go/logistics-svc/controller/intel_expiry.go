@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// intelExpiryBatchSize is the maximum number of due intel expiries to retrieve
+// at once.
+const intelExpiryBatchSize = 16
+
+// intelExpiryMaxIdle is the maximum duration to wait until looking for due
+// intel expiries again. This limits the delay for intel, created by other
+// instances.
+const intelExpiryMaxIdle = 30 * time.Second
+
+// runIntelExpiries expires intel with store.Intel.ValidUntil being reached until
+// the given lifetime is done. After all due intel is expired, it waits until the
+// next intel expires or it is woken up because of newly created intel.
+func (c *Controller) runIntelExpiries(lifetime context.Context) error {
+	for {
+		wait := intelExpiryMaxIdle
+		err := c.runDueIntelExpiries(lifetime)
+		if err != nil {
+			mehlog.Log(c.Logger, meh.Wrap(err, "run due intel expiries", nil))
+		} else {
+			wait, err = c.durationUntilNextIntelExpiry(lifetime)
+			if err != nil {
+				mehlog.Log(c.Logger, meh.Wrap(err, "duration until next intel expiry", nil))
+				wait = intelExpiryMaxIdle
+			}
+		}
+		// Wait.
+		timer := time.NewTimer(wait)
+		select {
+		case <-lifetime.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		case <-c.intelExpiriesWakeUp():
+			timer.Stop()
+		}
+	}
+}
+
+// runDueIntelExpiries expires all due intel. Errors for single intel are logged
+// and do not stop processing the remaining ones. However, an error is returned
+// afterwards in order to not retry immediately.
+func (c *Controller) runDueIntelExpiries(ctx context.Context) error {
+	for {
+		var intelIDs []uuid.UUID
+		err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+			var err error
+			intelIDs, err = c.Store.DueIntelExpiries(ctx, tx, intelExpiryBatchSize)
+			if err != nil {
+				return meh.Wrap(err, "due intel expiries from store", meh.Details{"limit": intelExpiryBatchSize})
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "run in tx", nil)
+		}
+		failed := 0
+		for _, intelID := range intelIDs {
+			err = c.expireIntel(ctx, intelID)
+			if err != nil {
+				mehlog.Log(c.Logger, meh.Wrap(err, "expire intel", meh.Details{"intel_id": intelID}))
+				failed++
+			}
+		}
+		if failed > 0 {
+			return meh.NewInternalErr("expiring intel failed", meh.Details{"failed": failed})
+		}
+		if len(intelIDs) < intelExpiryBatchSize {
+			return nil
+		}
+	}
+}
+
+// expireIntel marks the intel with the given id as expired, if it is due, and
+// cancels all of its active deliveries. Only deliveries, that were not forwarded,
+// are canceled directly. Forwarded ones are canceled along with their
+// forwarding delivery, so that forwarding deliveries are always locked before
+// forwarded ones.
+func (c *Controller) expireIntel(ctx context.Context, intelID uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		ok, err := c.Store.MarkIntelAsExpiredIfDue(ctx, tx, intelID)
+		if err != nil {
+			return meh.Wrap(err, "mark intel as expired if due in store", meh.Details{"intel_id": intelID})
+		}
+		if !ok {
+			// Already expired by someone else.
+			return nil
+		}
+		err = c.Notifier.NotifyIntelExpired(ctx, tx, intelID)
+		if err != nil {
+			return meh.Wrap(err, "notify intel expired", meh.Details{"intel_id": intelID})
+		}
+		// Cancel active deliveries.
+		deliveries, err := c.Store.IntelDeliveriesByIntel(ctx, tx, intelID)
+		if err != nil {
+			return meh.Wrap(err, "intel-deliveries by intel from store", meh.Details{"intel_id": intelID})
+		}
+		for _, delivery := range deliveries {
+			_, isForwarded, err := c.Store.ForwardingAttemptByDelivery(ctx, tx, delivery.ID)
+			if err != nil {
+				return meh.Wrap(err, "forwarding attempt by delivery from store", meh.Details{"delivery_id": delivery.ID})
+			}
+			if isForwarded {
+				continue
+			}
+			current, err := c.Store.IntelDeliveryByIDAndLockOrWait(ctx, tx, delivery.ID)
+			if err != nil {
+				return meh.Wrap(err, "intel-delivery by id and lock from store", meh.Details{"delivery_id": delivery.ID})
+			}
+			if !current.IsActive {
+				continue
+			}
+			err = c.cancelActiveIntelDelivery(ctx, tx, current.ID, false, nulls.NewString("intel expired"),
+				"canceled due to intel being expired")
+			if err != nil {
+				return meh.Wrap(err, "cancel active intel-delivery", meh.Details{"delivery_id": current.ID})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// durationUntilNextIntelExpiry returns the duration until the next intel
+// expires. It is at most intelExpiryMaxIdle.
+func (c *Controller) durationUntilNextIntelExpiry(ctx context.Context) (time.Duration, error) {
+	var nextExpiry time.Time
+	var ok bool
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		nextExpiry, ok, err = c.Store.NextIntelExpiry(ctx, tx)
+		if err != nil {
+			return meh.Wrap(err, "next intel expiry from store", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, meh.Wrap(err, "run in tx", nil)
+	}
+	if !ok {
+		return intelExpiryMaxIdle, nil
+	}
+	wait := time.Until(nextExpiry)
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > intelExpiryMaxIdle {
+		wait = intelExpiryMaxIdle
+	}
+	return wait, nil
+}
+
+// intelExpiriesWakeUp returns the channel for waking up runIntelExpiries.
+func (c *Controller) intelExpiriesWakeUp() chan struct{} {
+	c.intelExpiriesWakeUpOnce.Do(func() {
+		c.intelExpiriesWakeUpChan = make(chan struct{}, 1)
+	})
+	return c.intelExpiriesWakeUpChan
+}
+
+// wakeUpIntelExpiries wakes up runIntelExpiries, so that newly created intel
+// with store.Intel.ValidUntil is respected. It does not block. Only call this
+// after the creating transaction was committed.
+func (c *Controller) wakeUpIntelExpiries() {
+	select {
+	case c.intelExpiriesWakeUp() <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,439 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerExpireIntelSuite tests Controller.expireIntel.
+type ControllerExpireIntelSuite struct {
+	suite.Suite
+	ctrl             *ControllerMock
+	tx               *testutil.DBTx
+	sampleIntelID    uuid.UUID
+	sampleActive     store.IntelDelivery
+	sampleInactive   store.IntelDelivery
+	sampleDeliveries []store.IntelDelivery
+}
+
+func (suite *ControllerExpireIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+	suite.sampleActive = store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleIntelID,
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+	}
+	suite.sampleInactive = store.IntelDelivery{
+		ID:      testutil.NewUUIDV4(),
+		Intel:   suite.sampleIntelID,
+		To:      testutil.NewUUIDV4(),
+		Success: true,
+	}
+	suite.sampleDeliveries = []store.IntelDelivery{suite.sampleActive, suite.sampleInactive}
+
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, suite.tx, suite.sampleIntelID).
+		Return(true, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelExpired", mock.Anything, suite.tx, suite.sampleIntelID).
+		Return(nil).Maybe()
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", mock.Anything, suite.tx, suite.sampleIntelID).
+		Return(suite.sampleDeliveries, nil).Maybe()
+	for _, delivery := range suite.sampleDeliveries {
+		suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, delivery.ID).
+			Return(delivery, nil).Maybe()
+	}
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleActive.ID).
+		Return([]store.IntelDeliveryAttempt{}, nil).Maybe()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, suite.sampleActive.ID,
+		false, false, nulls.NewString("intel expired")).Return(nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", mock.Anything, suite.tx, suite.sampleActive.ID,
+		false, false, nulls.NewString("intel expired")).Return(nil).Maybe()
+	for _, delivery := range suite.sampleDeliveries {
+		suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, delivery.ID).
+			Return(store.IntelDeliveryAttempt{}, false, nil).Maybe()
+	}
+}
+
+func (suite *ControllerExpireIntelSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestMarkFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "MarkIntelAsExpiredIfDue")
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, mock.Anything, mock.Anything).
+		Return(false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestNotDue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "MarkIntelAsExpiredIfDue")
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, mock.Anything, mock.Anything).
+		Return(false, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertNotCalled(suite.T(), "NotifyIntelExpired", mock.Anything, mock.Anything, mock.Anything)
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyIntelExpired")
+	suite.ctrl.Notifier.On("NotifyIntelExpired", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestRetrieveDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveriesByIntel")
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestRetrieveForwardingAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ForwardingAttemptByDelivery")
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(store.IntelDeliveryAttempt{}, false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestCancelFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateIntelDeliveryStatusByDelivery")
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.ctrl.Notifier.AssertCalled(suite.T(), "NotifyIntelExpired", mock.Anything, suite.tx, suite.sampleIntelID)
+		suite.ctrl.Store.AssertCalled(suite.T(), "UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx,
+			suite.sampleActive.ID, false, false, nulls.NewString("intel expired"))
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "ActiveIntelDeliveryAttemptsByDelivery",
+			mock.Anything, mock.Anything, suite.sampleInactive.ID)
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestForwardedDeliveryChain() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	forwardingAttempt := store.IntelDeliveryAttempt{
+		ID:       testutil.NewUUIDV4(),
+		Delivery: suite.sampleActive.ID,
+		Channel:  testutil.NewUUIDV4(),
+		IsActive: true,
+		Status:   store.IntelDeliveryStatusAwaitingAck,
+	}
+	forwardedDelivery := store.IntelDelivery{
+		ID:       testutil.NewUUIDV4(),
+		Intel:    suite.sampleIntelID,
+		To:       testutil.NewUUIDV4(),
+		IsActive: true,
+	}
+	canceledForwardingAttempt := forwardingAttempt
+	canceledForwardingAttempt.IsActive = false
+	canceledForwardingAttempt.Status = store.IntelDeliveryStatusCanceled
+	// The forwarded delivery is listed first in order to assert that it is not
+	// locked before the forwarding one.
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "IntelDeliveriesByIntel")
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", mock.Anything, suite.tx, suite.sampleIntelID).
+		Return([]store.IntelDelivery{forwardedDelivery, suite.sampleActive}, nil).Once()
+	suite.ctrl.Store.On("ForwardingAttemptByDelivery", mock.Anything, suite.tx, forwardedDelivery.ID).
+		Return(forwardingAttempt, true, nil)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ActiveIntelDeliveryAttemptsByDelivery")
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, suite.sampleActive.ID).
+		Return([]store.IntelDeliveryAttempt{forwardingAttempt}, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryAttemptStatusByID", mock.Anything, suite.tx, forwardingAttempt.ID,
+		false, store.IntelDeliveryStatusCanceled, mock.Anything).Return(nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryAttemptByID", mock.Anything, suite.tx, forwardingAttempt.ID).
+		Return(canceledForwardingAttempt, nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryAttemptStatusUpdated", mock.Anything, suite.tx, canceledForwardingAttempt).
+		Return(nil).Once()
+	suite.ctrl.Store.On("ForwardedIntelDeliveriesByAttempt", mock.Anything, suite.tx, forwardingAttempt.ID).
+		Return([]store.IntelDelivery{forwardedDelivery}, nil).Once()
+	suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", mock.Anything, suite.tx, forwardedDelivery.ID).
+		Return(forwardedDelivery, nil).Once()
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", mock.Anything, suite.tx, forwardedDelivery.ID).
+		Return([]store.IntelDeliveryAttempt{}, nil).Once()
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx, forwardedDelivery.ID,
+		false, false, mock.Anything).Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", mock.Anything, suite.tx, forwardedDelivery.ID,
+		false, false, mock.Anything).Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.expireIntel(timeout, suite.sampleIntelID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.ctrl.Store.AssertCalled(suite.T(), "UpdateIntelDeliveryStatusByDelivery", mock.Anything, suite.tx,
+			suite.sampleActive.ID, false, false, nulls.NewString("intel expired"))
+		suite.ctrl.Store.AssertNumberOfCalls(suite.T(), "IntelDeliveryByIDAndLockOrWait", 2)
+		suite.ctrl.Store.AssertNotCalled(suite.T(), "CreateIntelDeliveryAttempt", mock.Anything, mock.Anything, mock.Anything)
+	}()
+
+	wait()
+}
+
+func TestController_expireIntel(t *testing.T) {
+	suite.Run(t, new(ControllerExpireIntelSuite))
+}
+
+// ControllerRunDueIntelExpiriesSuite tests Controller.runDueIntelExpiries.
+type ControllerRunDueIntelExpiriesSuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	sampleIntelIDs []uuid.UUID
+}
+
+func (suite *ControllerRunDueIntelExpiriesSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.ctrl.DB.GenTx = true
+	suite.sampleIntelIDs = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerRunDueIntelExpiriesSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DueIntelExpiries", mock.Anything, mock.Anything, intelExpiryBatchSize).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueIntelExpiries(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRunDueIntelExpiriesSuite) TestNoneDue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DueIntelExpiries", mock.Anything, mock.Anything, intelExpiryBatchSize).
+		Return([]uuid.UUID{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueIntelExpiries(timeout)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRunDueIntelExpiriesSuite) TestExpireFailDoesNotStopOthers() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DueIntelExpiries", mock.Anything, mock.Anything, intelExpiryBatchSize).
+		Return(suite.sampleIntelIDs, nil).Once()
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, mock.Anything, suite.sampleIntelIDs[0]).
+		Return(false, errors.New("sad life")).Once()
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, mock.Anything, suite.sampleIntelIDs[1]).
+		Return(false, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueIntelExpiries(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRunDueIntelExpiriesSuite) TestFullBatch() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	fullBatch := make([]uuid.UUID, 0, intelExpiryBatchSize)
+	for i := 0; i < intelExpiryBatchSize; i++ {
+		fullBatch = append(fullBatch, testutil.NewUUIDV4())
+	}
+	suite.ctrl.Store.On("DueIntelExpiries", mock.Anything, mock.Anything, intelExpiryBatchSize).
+		Return(fullBatch, nil).Once()
+	suite.ctrl.Store.On("DueIntelExpiries", mock.Anything, mock.Anything, intelExpiryBatchSize).
+		Return([]uuid.UUID{}, nil).Once()
+	suite.ctrl.Store.On("MarkIntelAsExpiredIfDue", mock.Anything, mock.Anything, mock.Anything).
+		Return(false, nil).Times(intelExpiryBatchSize)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.runDueIntelExpiries(timeout)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_runDueIntelExpiries(t *testing.T) {
+	suite.Run(t, new(ControllerRunDueIntelExpiriesSuite))
+}
+
+// ControllerDurationUntilNextIntelExpirySuite tests
+// Controller.durationUntilNextIntelExpiry.
+type ControllerDurationUntilNextIntelExpirySuite struct {
+	suite.Suite
+	ctrl *ControllerMock
+	tx   *testutil.DBTx
+}
+
+func (suite *ControllerDurationUntilNextIntelExpirySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+}
+
+func (suite *ControllerDurationUntilNextIntelExpirySuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelExpiry", timeout, suite.tx).
+		Return(time.Time{}, false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.durationUntilNextIntelExpiry(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextIntelExpirySuite) TestNoneExpiring() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelExpiry", timeout, suite.tx).
+		Return(time.Time{}, false, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextIntelExpiry(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(intelExpiryMaxIdle, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextIntelExpirySuite) TestDue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelExpiry", timeout, suite.tx).
+		Return(time.Now().Add(-time.Minute), true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextIntelExpiry(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(time.Duration(0), got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDurationUntilNextIntelExpirySuite) TestLimitToMaxIdle() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("NextIntelExpiry", timeout, suite.tx).
+		Return(time.Now().Add(24*time.Hour), true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.durationUntilNextIntelExpiry(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(intelExpiryMaxIdle, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_durationUntilNextIntelExpiry(t *testing.T) {
+	suite.Run(t, new(ControllerDurationUntilNextIntelExpirySuite))
+}
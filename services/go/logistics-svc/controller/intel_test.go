@@ -1066,3 +1066,71 @@ func (suite *controllerLimitIntelFiltersToUserSuite) TestNoneLeftAfterRemoval()
 func TestController_limitIntelFiltersToUser(t *testing.T) {
 	suite.Run(t, new(controllerLimitIntelFiltersToUserSuite))
 }
+
+// ControllerOperationByIntelSuite tests Controller.OperationByIntel.
+type ControllerOperationByIntelSuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	tx          *testutil.DBTx
+	sampleIntel store.Intel
+}
+
+func (suite *ControllerOperationByIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleIntel = store.Intel{
+		ID:        testutil.NewUUIDV4(),
+		Operation: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerOperationByIntelSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.OperationByIntel(timeout, suite.sampleIntel.ID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerOperationByIntelSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntel.ID).
+		Return(store.Intel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.OperationByIntel(timeout, suite.sampleIntel.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerOperationByIntelSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntel.ID).
+		Return(suite.sampleIntel, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.OperationByIntel(timeout, suite.sampleIntel.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.sampleIntel.Operation, got, "should return correct value")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_OperationByIntel(t *testing.T) {
+	suite.Run(t, new(ControllerOperationByIntelSuite))
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+)
+
+// AmendIntel amends the intel with the given id by creating a new version of
+// it, after assuring that the user is part of the same operation. The amended
+// intel is marked as invalid. Active deliveries for the amended intel are
+// canceled and scheduled for the new version. If
+// store.AmendIntel.RedeliverToDelivered is set, the new version is also
+// delivered to all address book entries, the amended intel was already
+// delivered to.
+func (c *Controller) AmendIntel(ctx context.Context, amend store.AmendIntel) (store.Intel, error) {
+	var created store.Intel
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		intelToAmend, err := c.Store.IntelByID(ctx, tx, amend.Intel)
+		if err != nil {
+			return meh.Wrap(err, "intel by id from store", meh.Details{"intel_id": amend.Intel})
+		}
+		// Assure amending user part of operation.
+		ok, err := c.Store.IsUserOperationMember(ctx, tx, amend.CreatedBy, intelToAmend.Operation)
+		if err != nil {
+			return meh.Wrap(err, "is user operation member", meh.Details{
+				"user_id":      amend.CreatedBy,
+				"operation_id": intelToAmend.Operation,
+			})
+		}
+		if !ok {
+			return meh.NewForbiddenErr("user is not operation member", meh.Details{
+				"amending_user": amend.CreatedBy,
+				"operation":     intelToAmend.Operation,
+			})
+		}
+		if !intelToAmend.IsValid {
+			return meh.NewBadInputErr("intel is invalid", meh.Details{"intel_id": amend.Intel})
+		}
+		// Search text.
+		amend.SearchText, err = c.genSearchText(store.CreateIntel{Type: amend.Type, Content: amend.Content})
+		if err != nil {
+			return meh.Wrap(err, "gen search text", nil)
+		}
+		// Create new version in store.
+		created, err = c.Store.AmendIntel(ctx, tx, amend)
+		if err != nil {
+			return meh.Wrap(err, "amend intel in store", meh.Details{"amend": amend})
+		}
+		err = c.Notifier.NotifyIntelCreated(ctx, tx, created)
+		if err != nil {
+			return meh.Wrap(err, "notify intel created", meh.Details{"created": created})
+		}
+		err = c.Notifier.NotifyIntelInvalidated(ctx, tx, amend.Intel, amend.CreatedBy)
+		if err != nil {
+			return meh.Wrap(err, "notify intel invalidated", meh.Details{"intel_id": amend.Intel})
+		}
+		err = c.Notifier.NotifyIntelAmended(ctx, tx, created)
+		if err != nil {
+			return meh.Wrap(err, "notify intel amended", meh.Details{"new_version": created})
+		}
+		// Carry over deliveries.
+		recipients, err := c.carryOverDeliveriesForAmendedIntel(ctx, tx, amend.Intel, amend.RedeliverToDelivered)
+		if err != nil {
+			return meh.Wrap(err, "carry over deliveries for amended intel", meh.Details{"intel_id": amend.Intel})
+		}
+		err = c.scheduleDeliveriesForIntel(ctx, tx, created.ID, recipients)
+		if err != nil {
+			return meh.Wrap(err, "schedule intel deliveries", meh.Details{
+				"intel_id":   created.ID,
+				"recipients": recipients,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return store.Intel{}, meh.Wrap(err, "run in tx", nil)
+	}
+	if created.ValidUntil.Valid {
+		c.wakeUpIntelExpiries()
+	}
+	return created, nil
+}
+
+// carryOverDeliveriesForAmendedIntel cancels all active deliveries for the
+// amended intel with the given id and returns the address book entries, the new
+// version should be delivered to. These are the recipients of the canceled
+// deliveries and, if redeliverToDelivered is set, the ones the amended intel
+// was already delivered to. Forwarded deliveries are skipped as they are
+// created again when forwarding the new version. Scheduled deliveries keep
+// their time to deliver at.
+func (c *Controller) carryOverDeliveriesForAmendedIntel(ctx context.Context, tx pgx.Tx, amendedIntelID uuid.UUID,
+	redeliverToDelivered bool) ([]intelDeliveryRecipient, error) {
+	deliveries, err := c.Store.IntelDeliveriesByIntel(ctx, tx, amendedIntelID)
+	if err != nil {
+		return nil, meh.Wrap(err, "intel-deliveries by intel from store", meh.Details{"intel_id": amendedIntelID})
+	}
+	recipients := make([]intelDeliveryRecipient, 0)
+	recipientsSet := make(map[uuid.UUID]struct{})
+	for _, delivery := range deliveries {
+		// Lock and retrieve current state as canceling forwarding deliveries also
+		// cancels forwarded ones.
+		current, err := c.Store.IntelDeliveryByIDAndLockOrWait(ctx, tx, delivery.ID)
+		if err != nil {
+			return nil, meh.Wrap(err, "intel-delivery by id and lock from store", meh.Details{"delivery_id": delivery.ID})
+		}
+		_, isForwarded, err := c.Store.ForwardingAttemptByDelivery(ctx, tx, current.ID)
+		if err != nil {
+			return nil, meh.Wrap(err, "forwarding attempt by delivery from store", meh.Details{"delivery_id": current.ID})
+		}
+		var deliverAt nulls.Time
+		if current.IsActive {
+			if isIntelDeliveryScheduled(current) {
+				deliverAt = current.DeliverAt
+			}
+			err = c.cancelActiveIntelDelivery(ctx, tx, current.ID, false, nulls.NewString("intel amended"),
+				"canceled due to intel being amended")
+			if err != nil {
+				return nil, meh.Wrap(err, "cancel active intel-delivery", meh.Details{"delivery_id": current.ID})
+			}
+		} else if !current.Success || !redeliverToDelivered {
+			continue
+		}
+		if isForwarded {
+			continue
+		}
+		if _, ok := recipientsSet[current.To]; ok {
+			continue
+		}
+		recipientsSet[current.To] = struct{}{}
+		recipients = append(recipients, intelDeliveryRecipient{
+			entry:     current.To,
+			deliverAt: deliverAt,
+		})
+	}
+	return recipients, nil
+}
+
+// IntelVersionsByIntel retrieves all versions of the intel with the given id,
+// sorted ascending by version. If limit-to-user is set, the user needs to have
+// associated deliveries for the intel with the given id.
+func (c *Controller) IntelVersionsByIntel(ctx context.Context, intelID uuid.UUID, limitToUser uuid.NullUUID) ([]store.Intel, error) {
+	var versions []store.Intel
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		// Assure allowed to retrieve.
+		if limitToUser.Valid {
+			err = c.assureIntelVisibleForUser(ctx, tx, intelID, limitToUser.UUID)
+			if err != nil {
+				return meh.Wrap(err, "assure intel visible for user", meh.Details{
+					"intel_id": intelID,
+					"user_id":  limitToUser.UUID,
+				})
+			}
+		}
+		versions, err = c.Store.IntelVersionsByIntel(ctx, tx, intelID)
+		if err != nil {
+			return meh.Wrap(err, "intel versions by intel from store", meh.Details{"intel_id": intelID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return versions, nil
+}
@@ -0,0 +1,479 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ControllerAmendIntelSuite tests Controller.AmendIntel.
+type ControllerAmendIntelSuite struct {
+	suite.Suite
+	ctrl               *ControllerMock
+	tx                 *testutil.DBTx
+	sampleIntelToAmend store.Intel
+	sampleAmend        store.AmendIntel
+	sampleStoreAmend   store.AmendIntel
+	sampleCreated      store.Intel
+}
+
+func (suite *ControllerAmendIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleIntelToAmend = store.Intel{
+		ID:         testutil.NewUUIDV4(),
+		CreatedAt:  time.Date(2022, 9, 1, 11, 12, 57, 0, time.UTC),
+		CreatedBy:  testutil.NewUUIDV4(),
+		Operation:  testutil.NewUUIDV4(),
+		Type:       store.IntelTypePlaintextMessage,
+		Content:    json.RawMessage(`{"text":"hello"}`),
+		SearchText: nulls.NewString("hello"),
+		Importance: 234,
+		IsValid:    true,
+		Version:    1,
+	}
+	suite.sampleAmend = store.AmendIntel{
+		Intel:      suite.sampleIntelToAmend.ID,
+		CreatedBy:  testutil.NewUUIDV4(),
+		Type:       store.IntelTypePlaintextMessage,
+		Content:    json.RawMessage(`{"text":"world"}`),
+		Importance: 500,
+	}
+	suite.sampleStoreAmend = suite.sampleAmend
+	suite.sampleStoreAmend.SearchText = nulls.NewString("world")
+	suite.sampleCreated = store.Intel{
+		ID:              testutil.NewUUIDV4(),
+		CreatedAt:       time.Date(2022, 9, 1, 11, 20, 3, 0, time.UTC),
+		CreatedBy:       suite.sampleAmend.CreatedBy,
+		Operation:       suite.sampleIntelToAmend.Operation,
+		Type:            suite.sampleAmend.Type,
+		Content:         suite.sampleAmend.Content,
+		SearchText:      nulls.NewString("world"),
+		Importance:      suite.sampleAmend.Importance,
+		IsValid:         true,
+		PreviousVersion: nulls.NewUUID(suite.sampleIntelToAmend.ID),
+		Version:         2,
+	}
+}
+
+// mockUntilCarryOver mocks all calls until carrying over deliveries.
+func (suite *ControllerAmendIntelSuite) mockUntilCarryOver() {
+	suite.ctrl.Store.On("IntelByID", mock.Anything, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil).Once()
+	suite.ctrl.Store.On("IsUserOperationMember", mock.Anything, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(true, nil).Once()
+	suite.ctrl.Store.On("AmendIntel", mock.Anything, suite.tx, suite.sampleStoreAmend).
+		Return(suite.sampleCreated, nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelCreated", mock.Anything, suite.tx, suite.sampleCreated).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelInvalidated", mock.Anything, suite.tx, suite.sampleIntelToAmend.ID, suite.sampleAmend.CreatedBy).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyIntelAmended", mock.Anything, suite.tx, suite.sampleCreated).
+		Return(nil).Once()
+}
+
+func (suite *ControllerAmendIntelSuite) TestTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestRetrieveIntelFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(store.Intel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestOperationMemberCheckFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil)
+	suite.ctrl.Store.On("IsUserOperationMember", timeout, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(false, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestNoOperationMember() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil)
+	suite.ctrl.Store.On("IsUserOperationMember", timeout, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(false, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestInvalidIntel() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleIntelToAmend.IsValid = false
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil)
+	suite.ctrl.Store.On("IsUserOperationMember", timeout, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(true, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestAmendInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil)
+	suite.ctrl.Store.On("IsUserOperationMember", timeout, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(true, nil)
+	suite.ctrl.Store.On("AmendIntel", timeout, suite.tx, suite.sampleStoreAmend).
+		Return(store.Intel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestNotifyAmendedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(suite.sampleIntelToAmend, nil)
+	suite.ctrl.Store.On("IsUserOperationMember", timeout, suite.tx, suite.sampleAmend.CreatedBy, suite.sampleIntelToAmend.Operation).
+		Return(true, nil)
+	suite.ctrl.Store.On("AmendIntel", timeout, suite.tx, suite.sampleStoreAmend).
+		Return(suite.sampleCreated, nil)
+	suite.ctrl.Notifier.On("NotifyIntelCreated", timeout, suite.tx, suite.sampleCreated).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyIntelInvalidated", timeout, suite.tx, suite.sampleIntelToAmend.ID, suite.sampleAmend.CreatedBy).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyIntelAmended", timeout, suite.tx, suite.sampleCreated).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestRetrieveDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.mockUntilCarryOver()
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestOKWithoutDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.mockUntilCarryOver()
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return([]store.IntelDelivery{}, nil)
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleCreated.ID).
+		Return(suite.sampleCreated, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleCreated, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) testOKWithDeliveries(redeliverToDelivered bool) {
+	suite.sampleAmend.RedeliverToDelivered = redeliverToDelivered
+	suite.sampleStoreAmend.RedeliverToDelivered = redeliverToDelivered
+	activeDelivery := store.IntelDelivery{
+		ID:        testutil.NewUUIDV4(),
+		Intel:     suite.sampleIntelToAmend.ID,
+		To:        testutil.NewUUIDV4(),
+		IsActive:  true,
+		DeliverAt: nulls.NewTime(time.Now().Add(time.Hour)),
+	}
+	deliveredDelivery := store.IntelDelivery{
+		ID:      testutil.NewUUIDV4(),
+		Intel:   suite.sampleIntelToAmend.ID,
+		To:      testutil.NewUUIDV4(),
+		Success: true,
+	}
+	failedDelivery := store.IntelDelivery{
+		ID:    testutil.NewUUIDV4(),
+		Intel: suite.sampleIntelToAmend.ID,
+		To:    testutil.NewUUIDV4(),
+	}
+	forwardedDelivery := store.IntelDelivery{
+		ID:      testutil.NewUUIDV4(),
+		Intel:   suite.sampleIntelToAmend.ID,
+		To:      testutil.NewUUIDV4(),
+		Success: true,
+	}
+	deliveries := []store.IntelDelivery{activeDelivery, deliveredDelivery, failedDelivery, forwardedDelivery}
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.mockUntilCarryOver()
+	suite.ctrl.Store.On("IntelDeliveriesByIntel", timeout, suite.tx, suite.sampleIntelToAmend.ID).
+		Return(deliveries, nil)
+	for _, delivery := range deliveries {
+		suite.ctrl.Store.On("IntelDeliveryByIDAndLockOrWait", timeout, suite.tx, delivery.ID).
+			Return(delivery, nil)
+		suite.ctrl.Store.On("ForwardingAttemptByDelivery", timeout, suite.tx, delivery.ID).
+			Return(store.IntelDeliveryAttempt{}, delivery.ID == forwardedDelivery.ID, nil)
+	}
+	// Cancel active delivery.
+	suite.ctrl.Store.On("ActiveIntelDeliveryAttemptsByDelivery", timeout, suite.tx, activeDelivery.ID).
+		Return([]store.IntelDeliveryAttempt{}, nil)
+	suite.ctrl.Store.On("UpdateIntelDeliveryStatusByDelivery", timeout, suite.tx, activeDelivery.ID, false, false, nulls.NewString("intel amended")).
+		Return(nil)
+	suite.ctrl.Notifier.On("NotifyIntelDeliveryStatusUpdated", timeout, suite.tx, activeDelivery.ID, false, false, nulls.NewString("intel amended")).
+		Return(nil)
+	// Schedule deliveries for new version.
+	expectedRecipients := []uuid.UUID{activeDelivery.To}
+	if redeliverToDelivered {
+		expectedRecipients = append(expectedRecipients, deliveredDelivery.To)
+	}
+	suite.ctrl.Store.On("IntelByID", timeout, suite.tx, suite.sampleCreated.ID).
+		Return(suite.sampleCreated, nil)
+	for _, recipient := range expectedRecipients {
+		toCreate := store.IntelDelivery{
+			Intel:    suite.sampleCreated.ID,
+			To:       recipient,
+			IsActive: true,
+		}
+		if recipient == activeDelivery.To {
+			// Scheduled deliveries should be carried over.
+			toCreate.DeliverAt = activeDelivery.DeliverAt
+		}
+		created := toCreate
+		created.ID = testutil.NewUUIDV4()
+		created.IsActive = false // Skip looking after delivery.
+		suite.ctrl.Store.On("CreateIntelDelivery", timeout, suite.tx, toCreate).
+			Return(created, nil).Once()
+		suite.ctrl.Notifier.On("NotifyIntelDeliveryCreated", timeout, suite.tx, created).
+			Return(nil).Once()
+		suite.ctrl.Store.On("LockIntelDeliveryByIDOrSkip", timeout, suite.tx, created.ID).
+			Return(nil).Once()
+		suite.ctrl.Store.On("IntelDeliveryByID", timeout, suite.tx, created.ID).
+			Return(created, nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.AmendIntel(timeout, suite.sampleAmend)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleCreated, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerAmendIntelSuite) TestOKWithDeliveries() {
+	suite.testOKWithDeliveries(false)
+}
+
+func (suite *ControllerAmendIntelSuite) TestOKWithDeliveriesAndRedeliverToDelivered() {
+	suite.testOKWithDeliveries(true)
+}
+
+func TestController_AmendIntel(t *testing.T) {
+	suite.Run(t, new(ControllerAmendIntelSuite))
+}
+
+// ControllerIntelVersionsByIntelSuite tests Controller.IntelVersionsByIntel.
+type ControllerIntelVersionsByIntelSuite struct {
+	suite.Suite
+	ctrl                      *ControllerMock
+	tx                        *testutil.DBTx
+	sampleID                  uuid.UUID
+	sampleUsersWithDeliveries []uuid.UUID
+	sampleVersions            []store.Intel
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleID = testutil.NewUUIDV4()
+	suite.sampleUsersWithDeliveries = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+	suite.sampleVersions = []store.Intel{
+		{
+			ID:         suite.sampleID,
+			Type:       "everyone",
+			Content:    json.RawMessage(`null`),
+			SearchText: nulls.NewString("gold"),
+			Version:    1,
+		},
+		{
+			ID:              testutil.NewUUIDV4(),
+			Type:            "everyone",
+			Content:         json.RawMessage(`null`),
+			SearchText:      nulls.NewString("silver"),
+			IsValid:         true,
+			PreviousVersion: nulls.NewUUID(suite.sampleID),
+			Version:         2,
+		},
+	}
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) TestTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.IntelVersionsByIntel(timeout, suite.sampleID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) TestNonAssociatedUser() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UsersWithDeliveriesByIntel", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleUsersWithDeliveries, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.IntelVersionsByIntel(timeout, suite.sampleID, nulls.NewUUID(testutil.NewUUIDV4()))
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelVersionsByIntel", timeout, suite.tx, suite.sampleID).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.IntelVersionsByIntel(timeout, suite.sampleID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) TestOKWithoutUserLimit() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("IntelVersionsByIntel", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleVersions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.IntelVersionsByIntel(timeout, suite.sampleID, uuid.NullUUID{})
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleVersions, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerIntelVersionsByIntelSuite) TestOKWithUserLimit() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UsersWithDeliveriesByIntel", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleUsersWithDeliveries, nil)
+	suite.ctrl.Store.On("IntelVersionsByIntel", timeout, suite.tx, suite.sampleID).
+		Return(suite.sampleVersions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.IntelVersionsByIntel(timeout, suite.sampleID, nulls.NewUUID(suite.sampleUsersWithDeliveries[1]))
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleVersions, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_IntelVersionsByIntel(t *testing.T) {
+	suite.Run(t, new(ControllerIntelVersionsByIntelSuite))
+}
@@ -24,3 +24,18 @@ func (c *Controller) UpdateUser(ctx context.Context, tx pgx.Tx, update store.Use
 	}
 	return nil
 }
+
+// UpdateUserPresence updates the given store.UserPresence. Outdated updates are
+// discarded by the store.
+func (c *Controller) UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence store.UserPresence) error {
+	err := c.Store.UpdateUserPresence(ctx, tx, presence)
+	if err != nil {
+		return meh.Wrap(err, "update user presence in store", meh.Details{"presence": presence})
+	}
+	// Channels of the user might have become usable.
+	err = c.scheduleDeliveryChecksByUser(ctx, tx, presence.User)
+	if err != nil {
+		return meh.Wrap(err, "schedule delivery checks by user", meh.Details{"user_id": presence.User})
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // ControllerCreateUserSuite tests Controller.CreateUser.
@@ -113,3 +115,75 @@ func (suite *ControllerUpdateUserSuite) TestOK() {
 func TestController_UpdateUser(t *testing.T) {
 	suite.Run(t, new(ControllerUpdateUserSuite))
 }
+
+// ControllerUpdateUserPresenceSuite tests Controller.UpdateUserPresence.
+type ControllerUpdateUserPresenceSuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	samplePresence store.UserPresence
+}
+
+func (suite *ControllerUpdateUserPresenceSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.samplePresence = store.UserPresence{
+		User:     testutil.NewUUIDV4(),
+		IsOnline: true,
+		LastSeen: time.Date(2022, 9, 8, 0, 4, 19, 0, time.UTC),
+	}
+}
+
+func (suite *ControllerUpdateUserPresenceSuite) TestUpdateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, tx, suite.samplePresence).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateUserPresence(timeout, tx, suite.samplePresence)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateUserPresenceSuite) TestScheduleChecksFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, tx, suite.samplePresence).
+		Return(nil)
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByUser", timeout, tx, suite.samplePresence.User, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateUserPresence(timeout, tx, suite.samplePresence)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateUserPresenceSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("UpdateUserPresence", timeout, tx, suite.samplePresence).
+		Return(nil)
+	suite.ctrl.Store.On("ScheduleIntelDeliveryChecksByUser", timeout, tx, suite.samplePresence.User, mock.Anything).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateUserPresence(timeout, tx, suite.samplePresence)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateUserPresence(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateUserPresenceSuite))
+}
@@ -61,22 +61,24 @@ func publicUserFromStore(s store.User) publicUser {
 // publicAddressBookEntry is the public representation of
 // store.AddressBookEntry.
 type publicAddressBookEntry struct {
-	ID          uuid.UUID     `json:"id"`
-	Label       string        `json:"label"`
-	Description string        `json:"description"`
-	Operation   uuid.NullUUID `json:"operation"`
-	User        uuid.NullUUID `json:"user"`
+	ID                  uuid.UUID     `json:"id"`
+	Label               string        `json:"label"`
+	Description         string        `json:"description"`
+	Operation           uuid.NullUUID `json:"operation"`
+	User                uuid.NullUUID `json:"user"`
+	FanOutMinImportance nulls.Int     `json:"fan_out_min_importance"`
 }
 
 // publicAddressBookEntryFromStore converts a store.AddressBookEntry to
 // publicAddressBookEntry.
 func publicAddressBookEntryFromStore(s store.AddressBookEntry) publicAddressBookEntry {
 	return publicAddressBookEntry{
-		ID:          s.ID,
-		Label:       s.Label,
-		Description: s.Description,
-		Operation:   s.Operation,
-		User:        s.User,
+		ID:                  s.ID,
+		Label:               s.Label,
+		Description:         s.Description,
+		Operation:           s.Operation,
+		User:                s.User,
+		FanOutMinImportance: s.FanOutMinImportance,
 	}
 }
 
@@ -84,11 +86,12 @@ func publicAddressBookEntryFromStore(s store.AddressBookEntry) publicAddressBook
 // store.AddressBookEntry.
 func storeAddressBookEntryFromPublic(p publicAddressBookEntry) store.AddressBookEntry {
 	return store.AddressBookEntry{
-		ID:          p.ID,
-		Label:       p.Label,
-		Description: p.Description,
-		Operation:   p.Operation,
-		User:        p.User,
+		ID:                  p.ID,
+		Label:               p.Label,
+		Description:         p.Description,
+		Operation:           p.Operation,
+		User:                p.User,
+		FanOutMinImportance: p.FanOutMinImportance,
 	}
 }
 
@@ -528,10 +528,11 @@ func (suite *handleCreateAddressBookEntrySuite) SetupTest() {
 		RandomSalt:      nil,
 	}
 	suite.sampleStoreCreate = store.AddressBookEntry{
-		Label:       "insure",
-		Description: "radio",
-		Operation:   nulls.NewUUID(testutil.NewUUIDV4()),
-		User:        nulls.NewUUID(testutil.NewUUIDV4()),
+		Label:               "insure",
+		Description:         "radio",
+		Operation:           nulls.NewUUID(testutil.NewUUIDV4()),
+		User:                nulls.NewUUID(testutil.NewUUIDV4()),
+		FanOutMinImportance: nulls.NewInt(500),
 	}
 	suite.samplePublicCreate = publicAddressBookEntryFromStore(suite.sampleStoreCreate)
 }
@@ -674,11 +675,12 @@ func (suite *handleUpdateAddressBookEntrySuite) SetupTest() {
 	}
 	suite.sampleEntryID = testutil.NewUUIDV4()
 	suite.sampleStoreUpdate = store.AddressBookEntry{
-		ID:          suite.sampleEntryID,
-		Label:       "insure",
-		Description: "radio",
-		Operation:   nulls.NewUUID(testutil.NewUUIDV4()),
-		User:        nulls.NewUUID(testutil.NewUUIDV4()),
+		ID:                  suite.sampleEntryID,
+		Label:               "insure",
+		Description:         "radio",
+		Operation:           nulls.NewUUID(testutil.NewUUIDV4()),
+		User:                nulls.NewUUID(testutil.NewUUIDV4()),
+		FanOutMinImportance: nulls.NewInt(500),
 	}
 	suite.samplePublicUpdate = publicAddressBookEntryFromStore(suite.sampleStoreUpdate)
 }
@@ -27,20 +27,168 @@ type publicChannel struct {
 	MinImportance float64         `json:"min_importance"`
 	Details       json.RawMessage `json:"details"`
 	Timeout       time.Duration   `json:"timeout"`
+	// RetryPolicy for the channel. If not set, store.DefaultChannelRetryPolicy is
+	// used.
+	RetryPolicy nulls.JSONNullable[publicChannelRetryPolicy] `json:"retry_policy"`
+	// PresencePolicy for the channel. If not set,
+	// store.ChannelPresencePolicyIgnore is used.
+	PresencePolicy string `json:"presence_policy"`
+	// Availability of the channel. If not set, the channel is available all the
+	// time.
+	Availability nulls.JSONNullable[publicChannelAvailability] `json:"availability"`
+}
+
+// publicChannelRetryPolicy is the public representation of
+// store.ChannelRetryPolicy.
+type publicChannelRetryPolicy struct {
+	MaxAttempts    int32         `json:"max_attempts"`
+	Backoff        time.Duration `json:"backoff"`
+	RetryOnTimeout bool          `json:"retry_on_timeout"`
+	RetryOnFailure bool          `json:"retry_on_failure"`
+}
+
+// publicChannelRetryPolicyFromStore converts store.ChannelRetryPolicy to
+// publicChannelRetryPolicy.
+func publicChannelRetryPolicyFromStore(s store.ChannelRetryPolicy) publicChannelRetryPolicy {
+	return publicChannelRetryPolicy{
+		MaxAttempts:    s.MaxAttempts,
+		Backoff:        s.Backoff,
+		RetryOnTimeout: s.RetryOnTimeout,
+		RetryOnFailure: s.RetryOnFailure,
+	}
+}
+
+// storeChannelRetryPolicyFromPublic converts publicChannelRetryPolicy to
+// store.ChannelRetryPolicy.
+func storeChannelRetryPolicyFromPublic(p publicChannelRetryPolicy) store.ChannelRetryPolicy {
+	return store.ChannelRetryPolicy{
+		MaxAttempts:    p.MaxAttempts,
+		Backoff:        p.Backoff,
+		RetryOnTimeout: p.RetryOnTimeout,
+		RetryOnFailure: p.RetryOnFailure,
+	}
+}
+
+// publicChannelAvailability is the public representation of
+// store.ChannelAvailability.
+type publicChannelAvailability struct {
+	Timezone   string                               `json:"timezone"`
+	Windows    []publicChannelAvailabilityWindow    `json:"windows"`
+	Exceptions []publicChannelAvailabilityException `json:"exceptions"`
+}
+
+// publicChannelAvailabilityWindow is the public representation of
+// store.ChannelAvailabilityWindow. From and To are times of the day in the
+// format of publicTimeOfDayLayout.
+type publicChannelAvailabilityWindow struct {
+	Weekday time.Weekday `json:"weekday"`
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+}
+
+// publicChannelAvailabilityException is the public representation of
+// store.ChannelAvailabilityException.
+type publicChannelAvailabilityException struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	IsAvailable bool      `json:"is_available"`
+}
+
+// publicTimeOfDayLayout is the layout for times of the day in
+// publicChannelAvailabilityWindow.
+const publicTimeOfDayLayout = "15:04"
+
+// publicTimeOfDayEndOfDay is used in publicChannelAvailabilityWindow.To for
+// windows ending at midnight.
+const publicTimeOfDayEndOfDay = "24:00"
+
+// publicTimeOfDayFromStore formats the given offset since midnight using
+// publicTimeOfDayLayout.
+func publicTimeOfDayFromStore(s time.Duration) string {
+	if s >= 24*time.Hour {
+		return publicTimeOfDayEndOfDay
+	}
+	return time.Time{}.Add(s).Format(publicTimeOfDayLayout)
+}
+
+// storeTimeOfDayFromPublic parses the given time of the day in the format of
+// publicTimeOfDayLayout and returns the offset since midnight.
+func storeTimeOfDayFromPublic(p string) (time.Duration, error) {
+	if p == publicTimeOfDayEndOfDay {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse(publicTimeOfDayLayout, p)
+	if err != nil {
+		return 0, meh.NewBadInputErrFromErr(err, "parse time of day", meh.Details{"was": p})
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// publicChannelAvailabilityFromStore converts store.ChannelAvailability to
+// publicChannelAvailability.
+func publicChannelAvailabilityFromStore(s store.ChannelAvailability) publicChannelAvailability {
+	p := publicChannelAvailability{
+		Timezone:   s.Timezone,
+		Windows:    make([]publicChannelAvailabilityWindow, 0, len(s.Windows)),
+		Exceptions: make([]publicChannelAvailabilityException, 0, len(s.Exceptions)),
+	}
+	for _, window := range s.Windows {
+		p.Windows = append(p.Windows, publicChannelAvailabilityWindow{
+			Weekday: window.Weekday,
+			From:    publicTimeOfDayFromStore(window.From),
+			To:      publicTimeOfDayFromStore(window.To),
+		})
+	}
+	for _, exception := range s.Exceptions {
+		p.Exceptions = append(p.Exceptions, publicChannelAvailabilityException(exception))
+	}
+	return p
+}
+
+// storeChannelAvailabilityFromPublic converts publicChannelAvailability to
+// store.ChannelAvailability.
+func storeChannelAvailabilityFromPublic(p publicChannelAvailability) (store.ChannelAvailability, error) {
+	s := store.ChannelAvailability{
+		Timezone:   p.Timezone,
+		Windows:    make([]store.ChannelAvailabilityWindow, 0, len(p.Windows)),
+		Exceptions: make([]store.ChannelAvailabilityException, 0, len(p.Exceptions)),
+	}
+	for _, window := range p.Windows {
+		from, err := storeTimeOfDayFromPublic(window.From)
+		if err != nil {
+			return store.ChannelAvailability{}, meh.Wrap(err, "window start from public", nil)
+		}
+		to, err := storeTimeOfDayFromPublic(window.To)
+		if err != nil {
+			return store.ChannelAvailability{}, meh.Wrap(err, "window end from public", nil)
+		}
+		s.Windows = append(s.Windows, store.ChannelAvailabilityWindow{
+			Weekday: window.Weekday,
+			From:    from,
+			To:      to,
+		})
+	}
+	for _, exception := range p.Exceptions {
+		s.Exceptions = append(s.Exceptions, store.ChannelAvailabilityException(exception))
+	}
+	return s, nil
 }
 
 // publicChannelFromStore converts store.Channel to publicChannel.
 func publicChannelFromStore(s store.Channel) (publicChannel, error) {
 	p := publicChannel{
-		ID:            s.ID,
-		Entry:         s.Entry,
-		IsActive:      s.IsActive,
-		Label:         s.Label,
-		Type:          string(s.Type),
-		Priority:      s.Priority,
-		MinImportance: s.MinImportance,
-		Details:       nil,
-		Timeout:       s.Timeout,
+		ID:             s.ID,
+		Entry:          s.Entry,
+		IsActive:       s.IsActive,
+		Label:          s.Label,
+		Type:           string(s.Type),
+		Priority:       s.Priority,
+		MinImportance:  s.MinImportance,
+		Details:        nil,
+		Timeout:        s.Timeout,
+		RetryPolicy:    nulls.NewJSONNullable(publicChannelRetryPolicyFromStore(s.RetryPolicy)),
+		PresencePolicy: string(s.PresencePolicy),
+		Availability:   nulls.NewJSONNullable(publicChannelAvailabilityFromStore(s.Availability)),
 	}
 	// Convert details.
 	var marshalErr error
@@ -84,14 +232,29 @@ func storeChannelDetailsFromPublic[T any](details json.RawMessage, mapFn func(T)
 // storeChannelFromPublic converts publicChannel to store.Channel.
 func storeChannelFromPublic(p publicChannel) (store.Channel, error) {
 	s := store.Channel{
-		ID:            p.ID,
-		Entry:         p.Entry,
-		IsActive:      p.IsActive,
-		Label:         p.Label,
-		Type:          store.ChannelType(p.Type),
-		Priority:      p.Priority,
-		MinImportance: p.MinImportance,
-		Timeout:       p.Timeout,
+		ID:             p.ID,
+		Entry:          p.Entry,
+		IsActive:       p.IsActive,
+		Label:          p.Label,
+		Type:           store.ChannelType(p.Type),
+		Priority:       p.Priority,
+		MinImportance:  p.MinImportance,
+		Timeout:        p.Timeout,
+		RetryPolicy:    store.DefaultChannelRetryPolicy,
+		PresencePolicy: store.ChannelPresencePolicy(p.PresencePolicy),
+	}
+	if p.RetryPolicy.Valid {
+		s.RetryPolicy = storeChannelRetryPolicyFromPublic(p.RetryPolicy.V)
+	}
+	if s.PresencePolicy == "" {
+		s.PresencePolicy = store.ChannelPresencePolicyIgnore
+	}
+	if p.Availability.Valid {
+		availability, err := storeChannelAvailabilityFromPublic(p.Availability.V)
+		if err != nil {
+			return store.Channel{}, meh.Wrap(err, "channel availability from public", nil)
+		}
+		s.Availability = availability
 	}
 	// Parse details based on channel type.
 	var err error
@@ -170,6 +333,7 @@ func storeEmailChannelDetailsFromPublic(p publicEmailChannelDetails) store.Chann
 // store.ForwardToGroupChannelDetails.
 type publicForwardToGroupChannelDetails struct {
 	ForwardToGroup []uuid.UUID `json:"forward_to_group"`
+	Quorum         int32       `json:"quorum"`
 }
 
 // publicForwardToGroupChannelDetailsFromStore converts
@@ -177,6 +341,7 @@ type publicForwardToGroupChannelDetails struct {
 func publicForwardToGroupChannelDetailsFromStore(s store.ForwardToGroupChannelDetails) publicForwardToGroupChannelDetails {
 	return publicForwardToGroupChannelDetails{
 		ForwardToGroup: s.ForwardToGroup,
+		Quorum:         s.Quorum,
 	}
 }
 
@@ -185,6 +350,7 @@ func publicForwardToGroupChannelDetailsFromStore(s store.ForwardToGroupChannelDe
 func storeForwardToGroupChannelDetailsFromPublic(p publicForwardToGroupChannelDetails) store.ChannelDetails {
 	return store.ForwardToGroupChannelDetails{
 		ForwardToGroup: p.ForwardToGroup,
+		Quorum:         p.Quorum,
 	}
 }
 
@@ -192,6 +358,7 @@ func storeForwardToGroupChannelDetailsFromPublic(p publicForwardToGroupChannelDe
 // store.ForwardToUserChannelDetails.
 type publicForwardToUserChannelDetails struct {
 	ForwardToUser []uuid.UUID `json:"forward_to_user"`
+	Quorum        int32       `json:"quorum"`
 }
 
 // publicForwardToUserChannelDetailsFromStore converts
@@ -199,6 +366,7 @@ type publicForwardToUserChannelDetails struct {
 func publicForwardToUserChannelDetailsFromStore(s store.ForwardToUserChannelDetails) publicForwardToUserChannelDetails {
 	return publicForwardToUserChannelDetails{
 		ForwardToUser: s.ForwardToUser,
+		Quorum:        s.Quorum,
 	}
 }
 
@@ -207,6 +375,7 @@ func publicForwardToUserChannelDetailsFromStore(s store.ForwardToUserChannelDeta
 func storeForwardToUserChannelDetailsFromPublic(p publicForwardToUserChannelDetails) store.ChannelDetails {
 	return store.ForwardToUserChannelDetails{
 		ForwardToUser: p.ForwardToUser,
+		Quorum:        p.Quorum,
 	}
 }
 
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // storeChannelFromPublicSuite tests storeChannelFromPublic.
@@ -42,6 +43,30 @@ func (suite *storeChannelFromPublicSuite) SetupTest() {
 		MinImportance: 210,
 		Details:       testutil.MarshalJSONMust(details),
 		Timeout:       851,
+		RetryPolicy: nulls.NewJSONNullable(publicChannelRetryPolicy{
+			MaxAttempts:    3,
+			Backoff:        90 * time.Second,
+			RetryOnTimeout: true,
+			RetryOnFailure: false,
+		}),
+		PresencePolicy: string(store.ChannelPresencePolicySkipOffline),
+		Availability: nulls.NewJSONNullable(publicChannelAvailability{
+			Timezone: "Europe/Berlin",
+			Windows: []publicChannelAvailabilityWindow{
+				{
+					Weekday: time.Saturday,
+					From:    "00:00",
+					To:      "24:00",
+				},
+			},
+			Exceptions: []publicChannelAvailabilityException{
+				{
+					From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+					To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+					IsAvailable: true,
+				},
+			},
+		}),
 	}
 	suite.sampleStoreChannel = store.Channel{
 		ID:            suite.samplePublicChannel.ID,
@@ -53,6 +78,30 @@ func (suite *storeChannelFromPublicSuite) SetupTest() {
 		MinImportance: suite.samplePublicChannel.MinImportance,
 		Details:       storeInAppNotificationChannelDetailsFromPublic(details),
 		Timeout:       suite.samplePublicChannel.Timeout,
+		RetryPolicy: store.ChannelRetryPolicy{
+			MaxAttempts:    3,
+			Backoff:        90 * time.Second,
+			RetryOnTimeout: true,
+			RetryOnFailure: false,
+		},
+		PresencePolicy: store.ChannelPresencePolicySkipOffline,
+		Availability: store.ChannelAvailability{
+			Timezone: "Europe/Berlin",
+			Windows: []store.ChannelAvailabilityWindow{
+				{
+					Weekday: time.Saturday,
+					From:    0,
+					To:      24 * time.Hour,
+				},
+			},
+			Exceptions: []store.ChannelAvailabilityException{
+				{
+					From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+					To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+					IsAvailable: true,
+				},
+			},
+		},
 	}
 }
 
@@ -70,6 +119,44 @@ func (suite *storeChannelFromPublicSuite) TestDetailsConversionFail() {
 	suite.Error(err, "should fail")
 }
 
+func (suite *storeChannelFromPublicSuite) TestDefaultRetryPolicy() {
+	pChan := suite.samplePublicChannel
+	pChan.RetryPolicy = nulls.JSONNullable[publicChannelRetryPolicy]{}
+	s, err := storeChannelFromPublic(pChan)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(store.DefaultChannelRetryPolicy, s.RetryPolicy, "should use default retry policy")
+}
+
+func (suite *storeChannelFromPublicSuite) TestDefaultPresencePolicy() {
+	pChan := suite.samplePublicChannel
+	pChan.PresencePolicy = ""
+	s, err := storeChannelFromPublic(pChan)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(store.ChannelPresencePolicyIgnore, s.PresencePolicy, "should use default presence policy")
+}
+
+func (suite *storeChannelFromPublicSuite) TestInvalidAvailabilityWindowTime() {
+	pChan := suite.samplePublicChannel
+	pChan.Availability.V.Windows = []publicChannelAvailabilityWindow{
+		{
+			Weekday: time.Monday,
+			From:    "6 o'clock",
+			To:      "18:00",
+		},
+	}
+	_, err := storeChannelFromPublic(pChan)
+	suite.Error(err, "should fail")
+}
+
+func (suite *storeChannelFromPublicSuite) TestDefaultAvailability() {
+	pChan := suite.samplePublicChannel
+	pChan.Availability = nulls.JSONNullable[publicChannelAvailability]{}
+	s, err := storeChannelFromPublic(pChan)
+	suite.Require().NoError(err, "should not fail")
+	suite.Empty(s.Availability.Windows, "should not set windows")
+	suite.Empty(s.Availability.Exceptions, "should not set exceptions")
+}
+
 func (suite *storeChannelFromPublicSuite) TestOK() {
 	s, err := storeChannelFromPublic(suite.samplePublicChannel)
 	suite.Require().NoError(err, "should not fail")
@@ -97,15 +184,17 @@ func testStoreChannelDetailsFromPublic[From any, To store.ChannelDetails](t *tes
 	sChan, err := storeChannelFromPublic(pChan)
 	require.NoError(t, err, "store channel conversion from public should not fail")
 	assert.Equal(t, store.Channel{
-		ID:            pChan.ID,
-		Entry:         pChan.Entry,
-		IsActive:      pChan.IsActive,
-		Label:         pChan.Label,
-		Type:          chanType,
-		Priority:      pChan.Priority,
-		MinImportance: pChan.MinImportance,
-		Details:       to,
-		Timeout:       pChan.Timeout,
+		ID:             pChan.ID,
+		Entry:          pChan.Entry,
+		IsActive:       pChan.IsActive,
+		Label:          pChan.Label,
+		Type:           chanType,
+		Priority:       pChan.Priority,
+		MinImportance:  pChan.MinImportance,
+		Details:        to,
+		Timeout:        pChan.Timeout,
+		RetryPolicy:    store.DefaultChannelRetryPolicy,
+		PresencePolicy: store.ChannelPresencePolicyIgnore,
 	}, sChan, "conversion should return correct value")
 }
 
@@ -202,6 +291,35 @@ func (suite *publicChannelFromStoreSuite) SetupTest() {
 		MinImportance: 210,
 		Details:       details,
 		Timeout:       851,
+		RetryPolicy: store.ChannelRetryPolicy{
+			MaxAttempts:    2,
+			Backoff:        time.Minute,
+			RetryOnTimeout: false,
+			RetryOnFailure: true,
+		},
+		PresencePolicy: store.ChannelPresencePolicyPreferOnline,
+		Availability: store.ChannelAvailability{
+			Timezone: "Europe/Berlin",
+			Windows: []store.ChannelAvailabilityWindow{
+				{
+					Weekday: time.Monday,
+					From:    6 * time.Hour,
+					To:      18*time.Hour + 30*time.Minute,
+				},
+				{
+					Weekday: time.Tuesday,
+					From:    22 * time.Hour,
+					To:      24 * time.Hour,
+				},
+			},
+			Exceptions: []store.ChannelAvailabilityException{
+				{
+					From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+					To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+					IsAvailable: false,
+				},
+			},
+		},
 	}
 	suite.samplePublicChannel = publicChannel{
 		ID:            suite.sampleStoreChannel.ID,
@@ -213,6 +331,35 @@ func (suite *publicChannelFromStoreSuite) SetupTest() {
 		MinImportance: suite.sampleStoreChannel.MinImportance,
 		Details:       testutil.MarshalJSONMust(suite.sampleStoreChannel.Details),
 		Timeout:       suite.sampleStoreChannel.Timeout,
+		RetryPolicy: nulls.NewJSONNullable(publicChannelRetryPolicy{
+			MaxAttempts:    2,
+			Backoff:        time.Minute,
+			RetryOnTimeout: false,
+			RetryOnFailure: true,
+		}),
+		PresencePolicy: string(store.ChannelPresencePolicyPreferOnline),
+		Availability: nulls.NewJSONNullable(publicChannelAvailability{
+			Timezone: "Europe/Berlin",
+			Windows: []publicChannelAvailabilityWindow{
+				{
+					Weekday: time.Monday,
+					From:    "06:00",
+					To:      "18:30",
+				},
+				{
+					Weekday: time.Tuesday,
+					From:    "22:00",
+					To:      "24:00",
+				},
+			},
+			Exceptions: []publicChannelAvailabilityException{
+				{
+					From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+					To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+					IsAvailable: false,
+				},
+			},
+		}),
 	}
 }
 
@@ -269,6 +416,11 @@ func testPublicChannelDetailsFromStore[From store.ChannelDetails, To any](t *tes
 		MinImportance: sChan.MinImportance,
 		Details:       toRaw,
 		Timeout:       sChan.Timeout,
+		RetryPolicy:   nulls.NewJSONNullable(publicChannelRetryPolicy{}),
+		Availability: nulls.NewJSONNullable(publicChannelAvailability{
+			Windows:    []publicChannelAvailabilityWindow{},
+			Exceptions: []publicChannelAvailabilityException{},
+		}),
 	}, pChan, "conversion should return correct value")
 }
 
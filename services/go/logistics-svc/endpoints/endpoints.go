@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"go.uber.org/zap"
 )
 
@@ -25,23 +26,31 @@ type Store interface {
 	handleCreateIntelStore
 	handleGetIntelByIDStore
 	handleInvalidateIntelByIDStore
+	handleAmendIntelStore
+	handleGetIntelVersionsByIntelStore
 	handleRebuildIntelSearchStore
 	handleGetAllIntelStore
 	handleCreateIntelDeliveryAttemptForDeliveryStore
 	handleGetIntelDeliveryAttemptsByDeliveryStore
 	handleSetAddressBookEntriesWithAutoDeliveryEnabledStore
 	handleCancelIntelDeliveryByIDStore
+	handleUpdateIntelDeliveryDeliverAtStore
 	handleGetIntelDeliveryAttemptsStore
 	handleEnableAutoIntelDeliveryForAddressBookEntryStore
 	handleDisableAutoIntelDeliveryForAddressBookEntryStore
 	handleGetAutoIntelDeliveryEnabledForAddressBookEntryStore
+	handleGetIntelDeliveryEscalationRulesByOperationStore
+	handleUpdateIntelDeliveryEscalationRulesByOperationStore
+	handleGetIntelDeliveryEscalationChainByDeliveryStore
 }
 
 // Serve the endpoints via HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, s Store) error {
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, s Store,
+	deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	r := httpendpoints.NewEngine(logger)
 	populateRoutes(r, logger, authSecret, s)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, r, addr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
@@ -69,10 +78,16 @@ func populateRoutes(r *gin.Engine, logger *zap.Logger, secret string, s Store) {
 	r.POST("/intel/search/rebuild", httpendpoints.GinHandlerFunc(logger, secret, handleRebuildIntelSearch(s)))
 	r.GET("/intel/:intelID", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelByID(s)))
 	r.POST("/intel/:intelID/invalidate", httpendpoints.GinHandlerFunc(logger, secret, handleInvalidateIntelByID(s)))
+	r.POST("/intel/:intelID/amend", httpendpoints.GinHandlerFunc(logger, secret, handleAmendIntel(s)))
+	r.GET("/intel/:intelID/versions", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelVersionsByIntel(s)))
 	r.GET("/intel-deliveries/:deliveryID/attempts", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelDeliveryAttemptsByDelivery(s)))
 	r.POST("/intel-deliveries/:deliveryID/cancel", httpendpoints.GinHandlerFunc(logger, secret, handleCancelIntelDeliveryByID(s)))
+	r.PUT("/intel-deliveries/:deliveryID/deliver-at", httpendpoints.GinHandlerFunc(logger, secret, handleUpdateIntelDeliveryDeliverAt(s)))
 	r.POST("/intel-deliveries/:deliveryID/delivered", httpendpoints.GinHandlerFunc(logger, secret, handleMarkIntelDeliveryAsDelivered(s)))
 	r.POST("/intel-deliveries/:deliveryID/deliver/channel/:channelID", httpendpoints.GinHandlerFunc(logger, secret, handleCreateIntelDeliveryAttemptForDelivery(s)))
+	r.GET("/intel-deliveries/:deliveryID/escalations", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelDeliveryEscalationChainByDelivery(s)))
+	r.GET("/intel-delivery-escalation-rules/:operationID", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelDeliveryEscalationRulesByOperation(s)))
+	r.PUT("/intel-delivery-escalation-rules/:operationID", httpendpoints.GinHandlerFunc(logger, secret, handleUpdateIntelDeliveryEscalationRulesByOperation(s)))
 	r.GET("/intel-delivery-attempts", httpendpoints.GinHandlerFunc(logger, secret, handleGetIntelDeliveryAttempts(s)))
 	r.POST("/intel-delivery-attempts/:attemptID/delivered", httpendpoints.GinHandlerFunc(logger, secret, handleMarkIntelDeliveryAttemptAsDelivered(s)))
 }
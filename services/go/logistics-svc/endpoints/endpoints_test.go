@@ -87,6 +87,20 @@ func (m *StoreMock) InvalidateIntelByID(ctx context.Context, intelID uuid.UUID,
 	return m.Called(ctx, intelID, by).Error(0)
 }
 
+func (m *StoreMock) AmendIntel(ctx context.Context, amend store.AmendIntel) (store.Intel, error) {
+	args := m.Called(ctx, amend)
+	return args.Get(0).(store.Intel), args.Error(1)
+}
+
+func (m *StoreMock) IntelVersionsByIntel(ctx context.Context, intelID uuid.UUID, limitToUser uuid.NullUUID) ([]store.Intel, error) {
+	args := m.Called(ctx, intelID, limitToUser)
+	var versions []store.Intel
+	if a := args.Get(0); a != nil {
+		versions = a.([]store.Intel)
+	}
+	return versions, args.Error(1)
+}
+
 func (m *StoreMock) RebuildIntelSearch(ctx context.Context) {
 	m.Called(ctx)
 }
@@ -96,6 +110,16 @@ func (m *StoreMock) IntelByID(ctx context.Context, intelID uuid.UUID, limitToUse
 	return args.Get(0).(store.Intel), args.Error(1)
 }
 
+func (m *StoreMock) OperationByIntel(ctx context.Context, intelID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, intelID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *StoreMock) OperationByIntelDelivery(ctx context.Context, deliveryID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func (m *StoreMock) Intel(ctx context.Context, filters store.IntelFilters, paginationParams pagination.Params,
 	limitToUser uuid.NullUUID) (pagination.Paginated[store.Intel], error) {
 	args := m.Called(ctx, filters, paginationParams, limitToUser)
@@ -124,6 +148,10 @@ func (m *StoreMock) CancelIntelDeliveryByID(ctx context.Context, deliveryID uuid
 	return m.Called(ctx, deliveryID, success, note).Error(0)
 }
 
+func (m *StoreMock) UpdateIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	return m.Called(ctx, deliveryID, deliverAt).Error(0)
+}
+
 func (m *StoreMock) IntelDeliveryAttempts(ctx context.Context, filters store.IntelDeliveryAttemptFilters,
 	page pagination.Params) (pagination.Paginated[store.IntelDeliveryAttempt], error) {
 	args := m.Called(ctx, filters, page)
@@ -138,3 +166,21 @@ func (m *StoreMock) IsAutoIntelDeliveryEnabledForAddressBookEntry(ctx context.Co
 	args := m.Called(ctx, entryID)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *StoreMock) IntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID) ([]store.IntelDeliveryEscalationRule, error) {
+	args := m.Called(ctx, operationID)
+	var rules []store.IntelDeliveryEscalationRule
+	rules, _ = args.Get(0).([]store.IntelDeliveryEscalationRule)
+	return rules, args.Error(1)
+}
+
+func (m *StoreMock) UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID, rules []store.IntelDeliveryEscalationRule) error {
+	return m.Called(ctx, operationID, rules).Error(0)
+}
+
+func (m *StoreMock) IntelDeliveryEscalationChainByDelivery(ctx context.Context, deliveryID uuid.UUID) ([]store.IntelDeliveryEscalation, error) {
+	args := m.Called(ctx, deliveryID)
+	var chain []store.IntelDeliveryEscalation
+	chain, _ = args.Get(0).([]store.IntelDeliveryEscalation)
+	return chain, args.Error(1)
+}
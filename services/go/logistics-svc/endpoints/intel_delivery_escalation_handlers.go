@@ -0,0 +1,285 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/entityvalidation"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"net/http"
+	"time"
+)
+
+// publicIntelDeliveryEscalationTrigger is the public representation of
+// store.IntelDeliveryEscalationTrigger.
+type publicIntelDeliveryEscalationTrigger string
+
+const (
+	publicIntelDeliveryEscalationTriggerFailed      publicIntelDeliveryEscalationTrigger = "failed"
+	publicIntelDeliveryEscalationTriggerOpenTimeout publicIntelDeliveryEscalationTrigger = "open-timeout"
+)
+
+// publicIntelDeliveryEscalationTriggerFromStore maps
+// store.IntelDeliveryEscalationTrigger to publicIntelDeliveryEscalationTrigger.
+func publicIntelDeliveryEscalationTriggerFromStore(s store.IntelDeliveryEscalationTrigger) (publicIntelDeliveryEscalationTrigger, error) {
+	switch s {
+	case store.IntelDeliveryEscalationTriggerFailed:
+		return publicIntelDeliveryEscalationTriggerFailed, nil
+	case store.IntelDeliveryEscalationTriggerOpenTimeout:
+		return publicIntelDeliveryEscalationTriggerOpenTimeout, nil
+	default:
+		return "", meh.NewInternalErr(fmt.Sprintf("unknown trigger: %v", s), nil)
+	}
+}
+
+// storeIntelDeliveryEscalationTriggerFromPublic maps
+// publicIntelDeliveryEscalationTrigger to store.IntelDeliveryEscalationTrigger.
+func storeIntelDeliveryEscalationTriggerFromPublic(p publicIntelDeliveryEscalationTrigger) (store.IntelDeliveryEscalationTrigger, error) {
+	switch p {
+	case publicIntelDeliveryEscalationTriggerFailed:
+		return store.IntelDeliveryEscalationTriggerFailed, nil
+	case publicIntelDeliveryEscalationTriggerOpenTimeout:
+		return store.IntelDeliveryEscalationTriggerOpenTimeout, nil
+	default:
+		return "", meh.NewBadInputErr(fmt.Sprintf("unknown trigger: %v", p), nil)
+	}
+}
+
+// publicIntelDeliveryEscalationRule is the public representation of
+// store.IntelDeliveryEscalationRule.
+type publicIntelDeliveryEscalationRule struct {
+	ID             uuid.UUID                            `json:"id"`
+	Operation      uuid.UUID                            `json:"operation"`
+	Trigger        publicIntelDeliveryEscalationTrigger `json:"trigger"`
+	OpenTimeout    time.Duration                        `json:"open_timeout"`
+	EscalateTo     uuid.NullUUID                        `json:"escalate_to"`
+	NotifyManagers bool                                 `json:"notify_managers"`
+}
+
+// publicIntelDeliveryEscalationRuleFromStore maps
+// store.IntelDeliveryEscalationRule to publicIntelDeliveryEscalationRule.
+func publicIntelDeliveryEscalationRuleFromStore(s store.IntelDeliveryEscalationRule) (publicIntelDeliveryEscalationRule, error) {
+	trigger, err := publicIntelDeliveryEscalationTriggerFromStore(s.Trigger)
+	if err != nil {
+		return publicIntelDeliveryEscalationRule{}, meh.Wrap(err, "map trigger", meh.Details{"trigger": s.Trigger})
+	}
+	return publicIntelDeliveryEscalationRule{
+		ID:             s.ID,
+		Operation:      s.Operation,
+		Trigger:        trigger,
+		OpenTimeout:    s.OpenTimeout,
+		EscalateTo:     s.EscalateTo,
+		NotifyManagers: s.NotifyManagers,
+	}, nil
+}
+
+// storeIntelDeliveryEscalationRuleFromPublic maps
+// publicIntelDeliveryEscalationRule to store.IntelDeliveryEscalationRule.
+func storeIntelDeliveryEscalationRuleFromPublic(p publicIntelDeliveryEscalationRule) (store.IntelDeliveryEscalationRule, error) {
+	trigger, err := storeIntelDeliveryEscalationTriggerFromPublic(p.Trigger)
+	if err != nil {
+		return store.IntelDeliveryEscalationRule{}, meh.Wrap(err, "map trigger", meh.Details{"trigger": p.Trigger})
+	}
+	return store.IntelDeliveryEscalationRule{
+		ID:             p.ID,
+		Operation:      p.Operation,
+		Trigger:        trigger,
+		OpenTimeout:    p.OpenTimeout,
+		EscalateTo:     p.EscalateTo,
+		NotifyManagers: p.NotifyManagers,
+	}, nil
+}
+
+// publicIntelDeliveryEscalation is the public representation of
+// store.IntelDeliveryEscalation.
+type publicIntelDeliveryEscalation struct {
+	ID               uuid.UUID                            `json:"id"`
+	Delivery         uuid.UUID                            `json:"delivery"`
+	Rule             uuid.NullUUID                        `json:"rule"`
+	Trigger          publicIntelDeliveryEscalationTrigger `json:"trigger"`
+	EscalatedAt      time.Time                            `json:"escalated_at"`
+	EscalatedTo      uuid.NullUUID                        `json:"escalated_to"`
+	NotifiedManagers bool                                 `json:"notified_managers"`
+}
+
+// publicIntelDeliveryEscalationFromStore maps store.IntelDeliveryEscalation to
+// publicIntelDeliveryEscalation.
+func publicIntelDeliveryEscalationFromStore(s store.IntelDeliveryEscalation) (publicIntelDeliveryEscalation, error) {
+	trigger, err := publicIntelDeliveryEscalationTriggerFromStore(s.Trigger)
+	if err != nil {
+		return publicIntelDeliveryEscalation{}, meh.Wrap(err, "map trigger", meh.Details{"trigger": s.Trigger})
+	}
+	return publicIntelDeliveryEscalation{
+		ID:               s.ID,
+		Delivery:         s.Delivery,
+		Rule:             s.Rule,
+		Trigger:          trigger,
+		EscalatedAt:      s.EscalatedAt,
+		EscalatedTo:      s.EscalatedTo,
+		NotifiedManagers: s.NotifiedManagers,
+	}, nil
+}
+
+// assureManageIntelDeliveryForOperation assures, that
+// permission.ManageIntelDelivery is granted for the operation with the given
+// id.
+func assureManageIntelDeliveryForOperation(token auth.Token, operationID uuid.UUID) error {
+	scope, err := auth.GrantedOperations(token, permission.ManageIntelDelivery())
+	if err != nil {
+		return meh.Wrap(err, "granted operations", nil)
+	}
+	return assureOperationScope(token, scope, func() (uuid.UUID, error) {
+		return operationID, nil
+	})
+}
+
+// handleGetIntelDeliveryEscalationRulesByOperationStore are the dependencies
+// needed for handleGetIntelDeliveryEscalationRulesByOperation.
+type handleGetIntelDeliveryEscalationRulesByOperationStore interface {
+	IntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID) ([]store.IntelDeliveryEscalationRule, error)
+}
+
+// handleGetIntelDeliveryEscalationRulesByOperation retrieves the escalation
+// rules for the operation with the given id.
+func handleGetIntelDeliveryEscalationRulesByOperation(s handleGetIntelDeliveryEscalationRulesByOperationStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract operation id.
+		operationIDStr := c.Param("operationID")
+		operationID, err := uuid.FromString(operationIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse operation id", meh.Details{"was": operationIDStr})
+		}
+		// Check permissions.
+		err = assureManageIntelDeliveryForOperation(token, operationID)
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"operation_id": operationID})
+		}
+		// Retrieve.
+		sRules, err := s.IntelDeliveryEscalationRulesByOperation(c.Request.Context(), operationID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery-escalation-rules by operation", meh.Details{"operation_id": operationID})
+		}
+		pRules := make([]publicIntelDeliveryEscalationRule, 0, len(sRules))
+		for _, sRule := range sRules {
+			pRule, err := publicIntelDeliveryEscalationRuleFromStore(sRule)
+			if err != nil {
+				return meh.Wrap(err, "convert to public", meh.Details{"store_rule": sRule})
+			}
+			pRules = append(pRules, pRule)
+		}
+		c.JSON(http.StatusOK, pRules)
+		return nil
+	}
+}
+
+// handleUpdateIntelDeliveryEscalationRulesByOperationStore are the
+// dependencies needed for handleUpdateIntelDeliveryEscalationRulesByOperation.
+type handleUpdateIntelDeliveryEscalationRulesByOperationStore interface {
+	UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, operationID uuid.UUID, rules []store.IntelDeliveryEscalationRule) error
+}
+
+// handleUpdateIntelDeliveryEscalationRulesByOperation replaces the escalation
+// rules for the operation with the given id.
+func handleUpdateIntelDeliveryEscalationRulesByOperation(s handleUpdateIntelDeliveryEscalationRulesByOperationStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract operation id.
+		operationIDStr := c.Param("operationID")
+		operationID, err := uuid.FromString(operationIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse operation id", meh.Details{"was": operationIDStr})
+		}
+		// Check permissions.
+		err = assureManageIntelDeliveryForOperation(token, operationID)
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"operation_id": operationID})
+		}
+		// Parse body.
+		var pRules []publicIntelDeliveryEscalationRule
+		err = json.NewDecoder(c.Request.Body).Decode(&pRules)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		sRules := make([]store.IntelDeliveryEscalationRule, 0, len(pRules))
+		for _, pRule := range pRules {
+			sRule, err := storeIntelDeliveryEscalationRuleFromPublic(pRule)
+			if err != nil {
+				return meh.Wrap(err, "store intel-delivery-escalation-rule from public", nil)
+			}
+			sRule.Operation = operationID
+			// Validate.
+			if ok, err := entityvalidation.ValidateInRequest(c, sRule); err != nil {
+				return meh.Wrap(err, "validate in request", meh.Details{"rule": sRule})
+			} else if !ok {
+				// Handled.
+				return nil
+			}
+			sRules = append(sRules, sRule)
+		}
+		// Update.
+		err = s.UpdateIntelDeliveryEscalationRulesByOperation(c.Request.Context(), operationID, sRules)
+		if err != nil {
+			return meh.Wrap(err, "update intel-delivery-escalation-rules by operation", meh.Details{
+				"operation_id": operationID,
+				"rules":        sRules,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// handleGetIntelDeliveryEscalationChainByDeliveryStore are the dependencies
+// needed for handleGetIntelDeliveryEscalationChainByDelivery.
+type handleGetIntelDeliveryEscalationChainByDeliveryStore interface {
+	operationByIntelDeliveryStore
+	IntelDeliveryEscalationChainByDelivery(ctx context.Context, deliveryID uuid.UUID) ([]store.IntelDeliveryEscalation, error)
+}
+
+// handleGetIntelDeliveryEscalationChainByDelivery retrieves the escalations,
+// that led to the intel-delivery with the given id, followed by the
+// escalations of the delivery itself.
+func handleGetIntelDeliveryEscalationChainByDelivery(s handleGetIntelDeliveryEscalationChainByDeliveryStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract intel delivery id.
+		deliveryIDStr := c.Param("deliveryID")
+		deliveryID, err := uuid.FromString(deliveryIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse delivery id", meh.Details{"was": deliveryIDStr})
+		}
+		// Check permissions.
+		err = assurePermissionForIntelDelivery(c.Request.Context(), s, token, deliveryID, permission.ManageIntelDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"delivery_id": deliveryID})
+		}
+		// Retrieve.
+		sChain, err := s.IntelDeliveryEscalationChainByDelivery(c.Request.Context(), deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel-delivery-escalation-chain by delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		pChain := make([]publicIntelDeliveryEscalation, 0, len(sChain))
+		for _, sEscalation := range sChain {
+			pEscalation, err := publicIntelDeliveryEscalationFromStore(sEscalation)
+			if err != nil {
+				return meh.Wrap(err, "convert to public", meh.Details{"store_escalation": sEscalation})
+			}
+			pChain = append(pChain, pEscalation)
+		}
+		c.JSON(http.StatusOK, pChain)
+		return nil
+	}
+}
@@ -0,0 +1,415 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// handleGetIntelDeliveryEscalationRulesByOperationSuite tests
+// handleGetIntelDeliveryEscalationRulesByOperation.
+type handleGetIntelDeliveryEscalationRulesByOperationSuite struct {
+	suite.Suite
+	s                 *StoreMock
+	r                 *gin.Engine
+	tokenOK           auth.Token
+	sampleOperationID uuid.UUID
+	sRules            []store.IntelDeliveryEscalationRule
+	pRules            []publicIntelDeliveryEscalationRule
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.sampleOperationID = testutil.NewUUIDV4()
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "stamp",
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{operationScopedPermission(permission.ManageIntelDeliveryPermissionName, suite.sampleOperationID)},
+	}
+	suite.sRules = []store.IntelDeliveryEscalationRule{
+		{
+			ID:             testutil.NewUUIDV4(),
+			Operation:      suite.sampleOperationID,
+			Trigger:        store.IntelDeliveryEscalationTriggerFailed,
+			EscalateTo:     nulls.NewUUID(testutil.NewUUIDV4()),
+			NotifyManagers: true,
+		},
+		{
+			ID:             testutil.NewUUIDV4(),
+			Operation:      suite.sampleOperationID,
+			Trigger:        store.IntelDeliveryEscalationTriggerOpenTimeout,
+			OpenTimeout:    10 * time.Minute,
+			NotifyManagers: true,
+		},
+	}
+	suite.pRules = make([]publicIntelDeliveryEscalationRule, 0, len(suite.sRules))
+	for _, sRule := range suite.sRules {
+		pRule, err := publicIntelDeliveryEscalationRuleFromStore(sRule)
+		suite.Require().NoError(err, "converting rule to public should not fail")
+		suite.pRules = append(suite.pRules, pRule)
+	}
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) TestInvalidOperationID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/intel-delivery-escalation-rules/abc",
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) TestMissingPermissionForOperation() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{operationScopedPermission(permission.ManageIntelDeliveryPermissionName, testutil.NewUUIDV4())}
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) TestRetrieveFail() {
+	suite.s.On("IntelDeliveryEscalationRulesByOperation", mock.Anything, suite.sampleOperationID).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationRulesByOperationSuite) TestOK() {
+	suite.s.On("IntelDeliveryEscalationRulesByOperation", mock.Anything, suite.sampleOperationID).
+		Return(suite.sRules, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicIntelDeliveryEscalationRule
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.pRules, got, "should return correct body")
+}
+
+func Test_handleGetIntelDeliveryEscalationRulesByOperation(t *testing.T) {
+	suite.Run(t, new(handleGetIntelDeliveryEscalationRulesByOperationSuite))
+}
+
+// handleUpdateIntelDeliveryEscalationRulesByOperationSuite tests
+// handleUpdateIntelDeliveryEscalationRulesByOperation.
+type handleUpdateIntelDeliveryEscalationRulesByOperationSuite struct {
+	suite.Suite
+	s                 *StoreMock
+	r                 *gin.Engine
+	tokenOK           auth.Token
+	sampleOperationID uuid.UUID
+	pRules            []publicIntelDeliveryEscalationRule
+	sRules            []store.IntelDeliveryEscalationRule
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.sampleOperationID = testutil.NewUUIDV4()
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "wheel",
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.ManageIntelDeliveryPermissionName}},
+	}
+	fallbackEntry := testutil.NewUUIDV4()
+	suite.pRules = []publicIntelDeliveryEscalationRule{
+		{
+			Trigger:        publicIntelDeliveryEscalationTriggerFailed,
+			EscalateTo:     nulls.NewUUID(fallbackEntry),
+			NotifyManagers: false,
+		},
+		{
+			Trigger:        publicIntelDeliveryEscalationTriggerOpenTimeout,
+			OpenTimeout:    5 * time.Minute,
+			NotifyManagers: true,
+		},
+	}
+	suite.sRules = []store.IntelDeliveryEscalationRule{
+		{
+			Operation:      suite.sampleOperationID,
+			Trigger:        store.IntelDeliveryEscalationTriggerFailed,
+			EscalateTo:     nulls.NewUUID(fallbackEntry),
+			NotifyManagers: false,
+		},
+		{
+			Operation:      suite.sampleOperationID,
+			Trigger:        store.IntelDeliveryEscalationTriggerOpenTimeout,
+			OpenTimeout:    5 * time.Minute,
+			NotifyManagers: true,
+		},
+	}
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  token,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  token,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+		Body:   bytes.NewReader([]byte(`{invalid`)),
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestUnknownTrigger() {
+	suite.pRules[0].Trigger = "meow"
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestInvalidRule() {
+	suite.pRules[1].OpenTimeout = 0
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestUpdateFail() {
+	suite.s.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, suite.sampleOperationID, suite.sRules).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryEscalationRulesByOperationSuite) TestOK() {
+	suite.s.On("UpdateIntelDeliveryEscalationRulesByOperation", mock.Anything, suite.sampleOperationID, suite.sRules).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-delivery-escalation-rules/%s", suite.sampleOperationID.String()),
+		Token:  suite.tokenOK,
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.pRules)),
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUpdateIntelDeliveryEscalationRulesByOperation(t *testing.T) {
+	suite.Run(t, new(handleUpdateIntelDeliveryEscalationRulesByOperationSuite))
+}
+
+// handleGetIntelDeliveryEscalationChainByDeliverySuite tests
+// handleGetIntelDeliveryEscalationChainByDelivery.
+type handleGetIntelDeliveryEscalationChainByDeliverySuite struct {
+	suite.Suite
+	s                *StoreMock
+	r                *gin.Engine
+	tokenOK          auth.Token
+	sampleDeliveryID uuid.UUID
+	sChain           []store.IntelDeliveryEscalation
+	pChain           []publicIntelDeliveryEscalation
+}
+
+func (suite *handleGetIntelDeliveryEscalationChainByDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "fever",
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.ManageIntelDeliveryPermissionName}},
+	}
+	suite.sampleDeliveryID = testutil.NewUUIDV4()
+	suite.sChain = []store.IntelDeliveryEscalation{
+		{
+			ID:               testutil.NewUUIDV4(),
+			Delivery:         testutil.NewUUIDV4(),
+			Rule:             nulls.NewUUID(testutil.NewUUIDV4()),
+			Trigger:          store.IntelDeliveryEscalationTriggerFailed,
+			EscalatedAt:      testutil.NewRandomTime(),
+			EscalatedTo:      nulls.NewUUID(suite.sampleDeliveryID),
+			NotifiedManagers: true,
+		},
+		{
+			ID:          testutil.NewUUIDV4(),
+			Delivery:    suite.sampleDeliveryID,
+			Trigger:     store.IntelDeliveryEscalationTriggerOpenTimeout,
+			EscalatedAt: testutil.NewRandomTime(),
+		},
+	}
+	suite.pChain = make([]publicIntelDeliveryEscalation, 0, len(suite.sChain))
+	for _, sEscalation := range suite.sChain {
+		pEscalation, err := publicIntelDeliveryEscalationFromStore(sEscalation)
+		suite.Require().NoError(err, "converting escalation to public should not fail")
+		suite.pChain = append(suite.pChain, pEscalation)
+	}
+}
+
+func (suite *handleGetIntelDeliveryEscalationChainByDeliverySuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/escalations", suite.sampleDeliveryID.String()),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationChainByDeliverySuite) TestMissingPermissions() {
+	token := suite.tokenOK
+	token.Permissions = nil
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/escalations", suite.sampleDeliveryID.String()),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationChainByDeliverySuite) TestRetrieveFail() {
+	suite.s.On("IntelDeliveryEscalationChainByDelivery", mock.Anything, suite.sampleDeliveryID).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/escalations", suite.sampleDeliveryID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelDeliveryEscalationChainByDeliverySuite) TestOK() {
+	suite.s.On("IntelDeliveryEscalationChainByDelivery", mock.Anything, suite.sampleDeliveryID).
+		Return(suite.sChain, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/escalations", suite.sampleDeliveryID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicIntelDeliveryEscalation
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.pChain, got, "should return correct body")
+}
+
+func Test_handleGetIntelDeliveryEscalationChainByDelivery(t *testing.T) {
+	suite.Run(t, new(handleGetIntelDeliveryEscalationChainByDeliverySuite))
+}
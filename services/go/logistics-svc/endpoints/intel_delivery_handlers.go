@@ -21,14 +21,15 @@ import (
 // publicIntelDeliveryAttempt is the public representation of
 // store.IntelDeliveryAttempt.
 type publicIntelDeliveryAttempt struct {
-	ID        uuid.UUID                 `json:"id"`
-	Delivery  uuid.UUID                 `json:"delivery"`
-	Channel   uuid.UUID                 `json:"channel"`
-	CreatedAt time.Time                 `json:"created_at"`
-	IsActive  bool                      `json:"is_active"`
-	Status    publicIntelDeliveryStatus `json:"status"`
-	StatusTS  time.Time                 `json:"status_ts"`
-	Note      nulls.String              `json:"note"`
+	ID             uuid.UUID                 `json:"id"`
+	Delivery       uuid.UUID                 `json:"delivery"`
+	Channel        uuid.UUID                 `json:"channel"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	IsActive       bool                      `json:"is_active"`
+	Status         publicIntelDeliveryStatus `json:"status"`
+	StatusTS       time.Time                 `json:"status_ts"`
+	Note           nulls.String              `json:"note"`
+	AcknowledgedBy uuid.NullUUID             `json:"acknowledged_by"`
 }
 
 // publicIntelDeliveryAttemptFromStore maps store.IntelDeliveryAttempt to
@@ -39,14 +40,15 @@ func publicIntelDeliveryAttemptFromStore(s store.IntelDeliveryAttempt) (publicIn
 		return publicIntelDeliveryAttempt{}, meh.Wrap(err, "map status", meh.Details{"status": s.Status})
 	}
 	return publicIntelDeliveryAttempt{
-		ID:        s.ID,
-		Delivery:  s.Delivery,
-		Channel:   s.Channel,
-		CreatedAt: s.CreatedAt,
-		IsActive:  s.IsActive,
-		Status:    status,
-		StatusTS:  s.StatusTS,
-		Note:      s.Note,
+		ID:             s.ID,
+		Delivery:       s.Delivery,
+		Channel:        s.Channel,
+		CreatedAt:      s.CreatedAt,
+		IsActive:       s.IsActive,
+		Status:         status,
+		StatusTS:       s.StatusTS,
+		Note:           s.Note,
+		AcknowledgedBy: s.AcknowledgedBy,
 	}, nil
 }
 
@@ -161,6 +163,7 @@ func handleMarkIntelDeliveryAsDelivered(s handleMarkIntelDeliveryAsDeliveredStor
 // handleCreateIntelDeliveryAttemptForDeliveryStore are the dependencies needed
 // for handleCreateIntelDeliveryAttemptForDelivery.
 type handleCreateIntelDeliveryAttemptForDeliveryStore interface {
+	operationByIntelDeliveryStore
 	CreateIntelDeliveryAttempt(ctx context.Context, deliveryID uuid.UUID, channelID uuid.UUID) (store.IntelDeliveryAttempt, error)
 }
 
@@ -171,17 +174,17 @@ func handleCreateIntelDeliveryAttemptForDelivery(s handleCreateIntelDeliveryAtte
 		if !token.IsAuthenticated {
 			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
-		// Check permissions.
-		err := auth.AssurePermission(token, permission.ManageIntelDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
-		}
 		// Extract ids.
 		deliveryIDStr := c.Param("deliveryID")
 		deliveryID, err := uuid.FromString(deliveryIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse delivery id", meh.Details{"was": deliveryIDStr})
 		}
+		// Check permissions.
+		err = assurePermissionForIntelDelivery(c.Request.Context(), s, token, deliveryID, permission.ManageIntelDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"delivery_id": deliveryID})
+		}
 		channelIDStr := c.Param("channelID")
 		channelID, err := uuid.FromString(channelIDStr)
 		if err != nil {
@@ -207,6 +210,7 @@ func handleCreateIntelDeliveryAttemptForDelivery(s handleCreateIntelDeliveryAtte
 // handleGetIntelDeliveryAttemptsByDeliveryStore are the dependencies needed for
 // handleGetIntelDeliveryAttemptsByDelivery.
 type handleGetIntelDeliveryAttemptsByDeliveryStore interface {
+	operationByIntelDeliveryStore
 	IntelDeliveryAttemptsByDelivery(ctx context.Context, deliveryID uuid.UUID) ([]store.IntelDeliveryAttempt, error)
 }
 
@@ -217,17 +221,17 @@ func handleGetIntelDeliveryAttemptsByDelivery(s handleGetIntelDeliveryAttemptsBy
 		if !token.IsAuthenticated {
 			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
-		// Check permissions.
-		err := auth.AssurePermission(token, permission.ManageIntelDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
-		}
 		// Extract intel delivery id.
 		deliveryIDStr := c.Param("deliveryID")
 		deliveryID, err := uuid.FromString(deliveryIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse delivery id", meh.Details{"was": deliveryIDStr})
 		}
+		// Check permissions.
+		err = assurePermissionForIntelDelivery(c.Request.Context(), s, token, deliveryID, permission.ManageIntelDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"delivery_id": deliveryID})
+		}
 		// Retrieve.
 		sAttempts, err := s.IntelDeliveryAttemptsByDelivery(c.Request.Context(), deliveryID)
 		if err != nil {
@@ -256,6 +260,7 @@ type publicIntelDeliveryCancellation struct {
 // handleCancelIntelDeliveryByIDStore are the dependencies needed for
 // handleCancelIntelDeliveryByID.
 type handleCancelIntelDeliveryByIDStore interface {
+	operationByIntelDeliveryStore
 	CancelIntelDeliveryByID(ctx context.Context, deliveryID uuid.UUID, success bool, note nulls.String) error
 }
 
@@ -265,17 +270,17 @@ func handleCancelIntelDeliveryByID(s handleCancelIntelDeliveryByIDStore) httpend
 		if !token.IsAuthenticated {
 			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
-		// Check permissions.
-		err := auth.AssurePermission(token, permission.ManageIntelDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
-		}
 		// Extract intel delivery id.
 		deliveryIDStr := c.Param("deliveryID")
 		deliveryID, err := uuid.FromString(deliveryIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse delivery id", meh.Details{"was": deliveryIDStr})
 		}
+		// Check permissions.
+		err = assurePermissionForIntelDelivery(c.Request.Context(), s, token, deliveryID, permission.ManageIntelDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"delivery_id": deliveryID})
+		}
 		// Parse cancellation-details.
 		var cancellation publicIntelDeliveryCancellation
 		err = c.BindJSON(&cancellation)
@@ -296,6 +301,56 @@ func handleCancelIntelDeliveryByID(s handleCancelIntelDeliveryByIDStore) httpend
 	}
 }
 
+// publicIntelDeliveryDeliverAtUpdate contains details for calling
+// handleUpdateIntelDeliveryDeliverAtStore.UpdateIntelDeliveryDeliverAt.
+type publicIntelDeliveryDeliverAtUpdate struct {
+	DeliverAt nulls.Time `json:"deliver_at"`
+}
+
+// handleUpdateIntelDeliveryDeliverAtStore are the dependencies needed for
+// handleUpdateIntelDeliveryDeliverAt.
+type handleUpdateIntelDeliveryDeliverAtStore interface {
+	operationByIntelDeliveryStore
+	UpdateIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error
+}
+
+// handleUpdateIntelDeliveryDeliverAt updates the time to deliver the scheduled
+// intel delivery with the given id at.
+func handleUpdateIntelDeliveryDeliverAt(s handleUpdateIntelDeliveryDeliverAtStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract intel delivery id.
+		deliveryIDStr := c.Param("deliveryID")
+		deliveryID, err := uuid.FromString(deliveryIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse delivery id", meh.Details{"was": deliveryIDStr})
+		}
+		// Check permissions.
+		err = assurePermissionForIntelDelivery(c.Request.Context(), s, token, deliveryID, permission.ManageIntelDelivery())
+		if err != nil {
+			return meh.Wrap(err, "check permissions", meh.Details{"delivery_id": deliveryID})
+		}
+		// Parse update.
+		var update publicIntelDeliveryDeliverAtUpdate
+		err = c.BindJSON(&update)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		// Update.
+		err = s.UpdateIntelDeliveryDeliverAt(c.Request.Context(), deliveryID, update.DeliverAt)
+		if err != nil {
+			return meh.Wrap(err, "update intel delivery deliver-at", meh.Details{
+				"delivery_id": deliveryID,
+				"deliver_at":  update.DeliverAt,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
 // intelDeliveryAttemptFiltersFromRequest parses
 // store.IntelDeliveryAttemptFilters from the given query url.Values.
 func intelDeliveryAttemptFiltersFromRequest(q url.Values) (store.IntelDeliveryAttemptFilters, error) {
@@ -343,7 +398,8 @@ type handleGetIntelDeliveryAttemptsStore interface {
 }
 
 // handleGetIntelDeliveryAttempts retrieves a paginated list of intel delivery
-// attempts with optional filtering.
+// attempts with optional filtering. If permission.DeliverIntel is limited to
+// operations, filtering by one of them is required.
 func handleGetIntelDeliveryAttempts(s handleGetIntelDeliveryAttemptsStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
 		if !token.IsAuthenticated {
@@ -360,9 +416,15 @@ func handleGetIntelDeliveryAttempts(s handleGetIntelDeliveryAttemptsStore) httpe
 			return meh.Wrap(err, "pagination params from request", nil)
 		}
 		// Check permisions.
-		err = auth.AssurePermission(token, permission.DeliverIntel())
+		scope, err := auth.GrantedOperations(token, permission.DeliverIntel())
 		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
+			return meh.Wrap(err, "granted operations", nil)
+		}
+		if !scope.All && (!filters.ByOperation.Valid || !scope.Includes(filters.ByOperation.UUID)) {
+			return meh.NewForbiddenErr("permission not granted for all or filtered operation", meh.Details{
+				"by_operation":       filters.ByOperation,
+				"granted_operations": scope.Operations,
+			})
 		}
 		// Retrieve.
 		sResult, err := s.IntelDeliveryAttempts(c.Request.Context(), filters, paginationParams)
@@ -18,6 +18,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test_publicIntelTypeFromStore reads all constants of store.IntelType and
@@ -519,3 +520,118 @@ func (suite *handleCancelIntelDeliveryByIDSuite) TestOK() {
 func Test_handleCancelIntelDeliveryByID(t *testing.T) {
 	suite.Run(t, new(handleCancelIntelDeliveryByIDSuite))
 }
+
+// handleUpdateIntelDeliveryDeliverAtSuite tests
+// handleUpdateIntelDeliveryDeliverAt.
+type handleUpdateIntelDeliveryDeliverAtSuite struct {
+	suite.Suite
+	s                *StoreMock
+	r                *gin.Engine
+	tokenOK          auth.Token
+	sampleDeliveryID uuid.UUID
+	sampleUpdate     publicIntelDeliveryDeliverAtUpdate
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "postpone",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.ManageIntelDeliveryPermissionName}},
+	}
+	suite.sampleDeliveryID = testutil.NewUUIDV4()
+	suite.sampleUpdate = publicIntelDeliveryDeliverAtUpdate{
+		DeliverAt: nulls.NewTime(time.Date(2022, 9, 1, 12, 30, 0, 0, time.UTC)),
+	}
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/deliver-at", suite.sampleDeliveryID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleUpdate)),
+		Token:  suite.tokenOK,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/deliver-at", suite.sampleDeliveryID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleUpdate)),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestInvalidDeliveryID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    "/intel-deliveries/abc/deliver-at",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleUpdate)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/deliver-at", suite.sampleDeliveryID.String()),
+		Body:   strings.NewReader(`{invalid`),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestUpdateFail() {
+	suite.s.On("UpdateIntelDeliveryDeliverAt", mock.Anything, suite.sampleDeliveryID, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/deliver-at", suite.sampleDeliveryID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleUpdate)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateIntelDeliveryDeliverAtSuite) TestOK() {
+	suite.s.On("UpdateIntelDeliveryDeliverAt", mock.Anything, suite.sampleDeliveryID, suite.sampleUpdate.DeliverAt).
+		Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/intel-deliveries/%s/deliver-at", suite.sampleDeliveryID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleUpdate)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUpdateIntelDeliveryDeliverAt(t *testing.T) {
+	suite.Run(t, new(handleUpdateIntelDeliveryDeliverAtSuite))
+}
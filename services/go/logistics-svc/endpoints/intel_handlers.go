@@ -176,6 +176,8 @@ type publicCreateIntel struct {
 	Content          json.RawMessage `json:"content"`
 	Importance       int             `json:"importance"`
 	InitialDeliverTo []uuid.UUID     `json:"initial_deliver_to"`
+	InitialDeliverAt nulls.Time      `json:"initial_deliver_at"`
+	ValidUntil       nulls.Time      `json:"valid_until"`
 }
 
 // storeCreateIntelFromPublic maps publicCreateIntel to store.CreateIntel.
@@ -198,20 +200,26 @@ func storeCreateIntelFromPublic(createdBy uuid.UUID, p publicCreateIntel) (store
 		Content:          intelContent,
 		Importance:       p.Importance,
 		InitialDeliverTo: p.InitialDeliverTo,
+		InitialDeliverAt: p.InitialDeliverAt,
+		ValidUntil:       p.ValidUntil,
 	}, nil
 }
 
 // publicIntel is the public representation of store.Intel.
 type publicIntel struct {
-	ID         uuid.UUID       `json:"id"`
-	CreatedAt  time.Time       `json:"created_at"`
-	CreatedBy  uuid.UUID       `json:"created_by"`
-	Operation  uuid.UUID       `json:"operation"`
-	Type       publicIntelType `json:"type"`
-	Content    json.RawMessage `json:"content"`
-	SearchText nulls.String    `json:"search_text"`
-	Importance int             `json:"importance"`
-	IsValid    bool            `json:"is_valid"`
+	ID              uuid.UUID       `json:"id"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       uuid.UUID       `json:"created_by"`
+	Operation       uuid.UUID       `json:"operation"`
+	Type            publicIntelType `json:"type"`
+	Content         json.RawMessage `json:"content"`
+	SearchText      nulls.String    `json:"search_text"`
+	Importance      int             `json:"importance"`
+	IsValid         bool            `json:"is_valid"`
+	PreviousVersion uuid.NullUUID   `json:"previous_version"`
+	Version         int             `json:"version"`
+	ValidUntil      nulls.Time      `json:"valid_until"`
+	IsExpired       bool            `json:"is_expired"`
 }
 
 // publicIntelFromStore converts a store.Intel list to publicIntel list.
@@ -228,15 +236,19 @@ func publicIntelFromStore(s store.Intel) (publicIntel, error) {
 		})
 	}
 	return publicIntel{
-		ID:         s.ID,
-		CreatedAt:  s.CreatedAt,
-		CreatedBy:  s.CreatedBy,
-		Operation:  s.Operation,
-		Type:       intelType,
-		Content:    intelContent,
-		SearchText: s.SearchText,
-		Importance: s.Importance,
-		IsValid:    s.IsValid,
+		ID:              s.ID,
+		CreatedAt:       s.CreatedAt,
+		CreatedBy:       s.CreatedBy,
+		Operation:       s.Operation,
+		Type:            intelType,
+		Content:         intelContent,
+		SearchText:      s.SearchText,
+		Importance:      s.Importance,
+		IsValid:         s.IsValid,
+		PreviousVersion: s.PreviousVersion,
+		Version:         s.Version,
+		ValidUntil:      s.ValidUntil,
+		IsExpired:       s.IsExpired,
 	}, nil
 }
 
@@ -248,9 +260,15 @@ type handleCreateIntelStore interface {
 // handleCreateIntel creates the given intel.
 func handleCreateIntel(s handleCreateIntelStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
-		err := auth.AssurePermission(token, permission.CreateIntel())
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		scope, err := auth.GrantedOperations(token, permission.CreateIntel())
 		if err != nil {
-			return meh.Wrap(err, "assure permission", nil)
+			return meh.Wrap(err, "granted operations", nil)
+		}
+		if scope.Empty() {
+			return meh.NewForbiddenErr("permission not granted", nil)
 		}
 		// Parse body.
 		var pCreateIntel publicCreateIntel
@@ -262,6 +280,9 @@ func handleCreateIntel(s handleCreateIntelStore) httpendpoints.HandlerFunc {
 		if err != nil {
 			return meh.Wrap(err, "store create intel type from public", meh.Details{"public": pCreateIntel})
 		}
+		if !scope.Includes(sCreateIntel.Operation) {
+			return meh.NewForbiddenErr("permission not granted for operation", meh.Details{"operation_id": sCreateIntel.Operation})
+		}
 		// Validate.
 		if ok, err := entityvalidation.ValidateInRequest(c, sCreateIntel); err != nil {
 			return meh.Wrap(err, "validate in request", meh.Details{"intel": sCreateIntel})
@@ -287,22 +308,23 @@ func handleCreateIntel(s handleCreateIntelStore) httpendpoints.HandlerFunc {
 // handleInvalidateIntelByIDStore are the dependencies needed for
 // handleInvalidateIntelByID.
 type handleInvalidateIntelByIDStore interface {
+	operationByIntelStore
 	InvalidateIntelByID(ctx context.Context, intelID uuid.UUID, by uuid.UUID) error
 }
 
 // handleInvalidateIntelByID invalidates the given intel.
 func handleInvalidateIntelByID(s handleInvalidateIntelByIDStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
-		err := auth.AssurePermission(token, permission.InvalidateIntel())
-		if err != nil {
-			return meh.Wrap(err, "assure permission", nil)
-		}
 		// Extract intel id.
 		intelIDStr := c.Param("intelID")
 		intelID, err := uuid.FromString(intelIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse intel id", meh.Details{"was": intelIDStr})
 		}
+		err = assurePermissionForIntel(c.Request.Context(), s, token, intelID, permission.InvalidateIntel())
+		if err != nil {
+			return meh.Wrap(err, "assure permission for intel", meh.Details{"intel_id": intelID})
+		}
 		// Invalidate.
 		err = s.InvalidateIntelByID(c.Request.Context(), intelID, token.UserID)
 		if err != nil {
@@ -313,6 +335,136 @@ func handleInvalidateIntelByID(s handleInvalidateIntelByIDStore) httpendpoints.H
 	}
 }
 
+// publicAmendIntel is the public representation of store.AmendIntel.
+type publicAmendIntel struct {
+	Type                 publicIntelType `json:"type"`
+	Content              json.RawMessage `json:"content"`
+	Importance           int             `json:"importance"`
+	RedeliverToDelivered bool            `json:"redeliver_to_delivered"`
+	ValidUntil           nulls.Time      `json:"valid_until"`
+}
+
+// storeAmendIntelFromPublic maps publicAmendIntel to store.AmendIntel.
+func storeAmendIntelFromPublic(intelID uuid.UUID, createdBy uuid.UUID, p publicAmendIntel) (store.AmendIntel, error) {
+	intelType, err := storeIntelTypeFromPublic(p.Type)
+	if err != nil {
+		return store.AmendIntel{}, meh.Wrap(err, "store intel type from public", meh.Details{"type": p.Type})
+	}
+	intelContent, err := storeIntelContentFromPublic(p.Type, p.Content)
+	if err != nil {
+		return store.AmendIntel{}, meh.Wrap(err, "map intel-content", meh.Details{
+			"intel_type":    p.Type,
+			"intel_content": string(p.Content),
+		})
+	}
+	return store.AmendIntel{
+		Intel:                intelID,
+		CreatedBy:            createdBy,
+		Type:                 intelType,
+		Content:              intelContent,
+		Importance:           p.Importance,
+		RedeliverToDelivered: p.RedeliverToDelivered,
+		ValidUntil:           p.ValidUntil,
+	}, nil
+}
+
+// handleAmendIntelStore are the dependencies needed for handleAmendIntel.
+type handleAmendIntelStore interface {
+	operationByIntelStore
+	AmendIntel(ctx context.Context, amend store.AmendIntel) (store.Intel, error)
+}
+
+// handleAmendIntel amends the given intel by creating a new version of it.
+func handleAmendIntel(s handleAmendIntelStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Extract intel id.
+		intelIDStr := c.Param("intelID")
+		intelID, err := uuid.FromString(intelIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse intel id", meh.Details{"was": intelIDStr})
+		}
+		err = assurePermissionForIntel(c.Request.Context(), s, token, intelID, permission.AmendIntel())
+		if err != nil {
+			return meh.Wrap(err, "assure permission for intel", meh.Details{"intel_id": intelID})
+		}
+		// Parse body.
+		var pAmendIntel publicAmendIntel
+		err = json.NewDecoder(c.Request.Body).Decode(&pAmendIntel)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		sAmendIntel, err := storeAmendIntelFromPublic(intelID, token.UserID, pAmendIntel)
+		if err != nil {
+			return meh.Wrap(err, "store amend intel from public", meh.Details{"public": pAmendIntel})
+		}
+		// Validate.
+		if ok, err := entityvalidation.ValidateInRequest(c, sAmendIntel); err != nil {
+			return meh.Wrap(err, "validate in request", meh.Details{"amend": sAmendIntel})
+		} else if !ok {
+			// Handled.
+			return nil
+		}
+		// Amend.
+		sCreated, err := s.AmendIntel(c.Request.Context(), sAmendIntel)
+		if err != nil {
+			return meh.Wrap(err, "amend intel", meh.Details{"intel_id": intelID})
+		}
+		pCreated, err := publicIntelFromStore(sCreated)
+		if err != nil {
+			c.Status(http.StatusOK)
+			return httpendpoints.NoResponse(meh.Wrap(err, "public intel from store", nil))
+		}
+		c.JSON(http.StatusCreated, pCreated)
+		return nil
+	}
+}
+
+// handleGetIntelVersionsByIntelStore are the dependencies needed for
+// handleGetIntelVersionsByIntel.
+type handleGetIntelVersionsByIntelStore interface {
+	operationByIntelStore
+	IntelVersionsByIntel(ctx context.Context, intelID uuid.UUID, limitToUser uuid.NullUUID) ([]store.Intel, error)
+}
+
+// handleGetIntelVersionsByIntel retrieves all versions of the intel with the
+// given id.
+func handleGetIntelVersionsByIntel(s handleGetIntelVersionsByIntelStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
+		}
+		// Extract intel id.
+		intelIDStr := c.Param("intelID")
+		intelID, err := uuid.FromString(intelIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse intel id", meh.Details{"was": intelIDStr})
+		}
+		// Check permissions for viewing any intel.
+		limitToUser, err := limitIntelToUserForIntel(c.Request.Context(), s, token, intelID)
+		if err != nil {
+			return meh.Wrap(err, "limit intel to user for intel", meh.Details{"intel_id": intelID})
+		}
+		// Retrieve.
+		sVersions, err := s.IntelVersionsByIntel(c.Request.Context(), intelID, limitToUser)
+		if err != nil {
+			return meh.Wrap(err, "intel versions by intel", meh.Details{
+				"intel_id":      intelID,
+				"limit_to_user": limitToUser,
+			})
+		}
+		pVersions := make([]publicIntel, 0, len(sVersions))
+		for _, sIntel := range sVersions {
+			pIntel, err := publicIntelFromStore(sIntel)
+			if err != nil {
+				return meh.Wrap(err, "public intel from store", meh.Details{"store_intel": sIntel})
+			}
+			pVersions = append(pVersions, pIntel)
+		}
+		c.JSON(http.StatusOK, pVersions)
+		return nil
+	}
+}
+
 // intelFiltersFromRequest parses store.IntelFilters from the given query
 // url.Values.
 func intelFiltersFromRequest(q url.Values) (store.IntelFilters, error) {
@@ -394,13 +546,9 @@ func handleSearchIntel(s handleSearchIntelStore) httpendpoints.HandlerFunc {
 			return meh.Wrap(err, "intel filters from request", nil)
 		}
 		// Check permissions.
-		limitToUser := nulls.NewUUID(token.UserID)
-		ok, err := auth.HasPermission(token, permission.ViewAnyIntel())
+		limitToUser, err := limitIntelToUserForFilters(token, IntelFilters)
 		if err != nil {
-			return meh.Wrap(err, "check permission", nil)
-		}
-		if ok {
-			limitToUser = uuid.NullUUID{}
+			return meh.Wrap(err, "limit intel to user for filters", nil)
 		}
 		// Extract params.
 		searchParams, err := search.ParamsFromRequest(c)
@@ -446,6 +594,7 @@ func handleRebuildIntelSearch(s handleRebuildIntelSearchStore) httpendpoints.Han
 
 // handleGetIntelByIDStore are the dependencies needed for handleGetIntelByID.
 type handleGetIntelByIDStore interface {
+	operationByIntelStore
 	IntelByID(ctx context.Context, intelID uuid.UUID, limitToUser uuid.NullUUID) (store.Intel, error)
 }
 
@@ -462,13 +611,9 @@ func handleGetIntelByID(s handleGetIntelByIDStore) httpendpoints.HandlerFunc {
 			return meh.NewBadInputErrFromErr(err, "parse intel id", meh.Details{"was": intelIDStr})
 		}
 		// Check permissions for viewing any intel.
-		limitToUser := nulls.NewUUID(token.UserID)
-		ok, err := auth.HasPermission(token, permission.ViewAnyIntel())
+		limitToUser, err := limitIntelToUserForIntel(c.Request.Context(), s, token, intelID)
 		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
-		}
-		if ok {
-			limitToUser = uuid.NullUUID{}
+			return meh.Wrap(err, "limit intel to user for intel", meh.Details{"intel_id": intelID})
 		}
 		// Retrieve.
 		sIntel, err := s.IntelByID(c.Request.Context(), intelID, limitToUser)
@@ -494,8 +639,8 @@ type handleGetAllIntelStore interface {
 }
 
 // handleGetAllIntel retrieves a paginated intel list. Without the
-// permission.ViewAnyIntel, the filter for deliveries for entries will be set
-// automatically.
+// permission.ViewAnyIntel for all operations or the filtered one, the filter for
+// deliveries for entries will be set automatically.
 func handleGetAllIntel(s handleGetAllIntelStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
 		if !token.IsAuthenticated {
@@ -512,13 +657,9 @@ func handleGetAllIntel(s handleGetAllIntelStore) httpendpoints.HandlerFunc {
 			return meh.Wrap(err, "pagination params from request", nil)
 		}
 		// Check permisions.
-		limitToUser := nulls.NewUUID(token.UserID)
-		viewAnyGranted, err := auth.HasPermission(token, permission.ViewAnyIntel())
+		limitToUser, err := limitIntelToUserForFilters(token, filters)
 		if err != nil {
-			return meh.Wrap(err, "check permission", nil)
-		}
-		if viewAnyGranted {
-			limitToUser = uuid.NullUUID{}
+			return meh.Wrap(err, "limit intel to user for filters", nil)
 		}
 		// Retrieve.
 		sResult, err := s.Intel(c.Request.Context(), filters, paginationParams, limitToUser)
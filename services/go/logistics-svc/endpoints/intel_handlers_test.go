@@ -70,6 +70,8 @@ func (suite *handleCreateIntelSuite) SetupTest() {
 			testutil.NewUUIDV4(),
 			testutil.NewUUIDV4(),
 		},
+		InitialDeliverAt: nulls.NewTime(time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)),
+		ValidUntil:       nulls.NewTime(time.Date(2099, 9, 1, 10, 0, 0, 0, time.UTC)),
 	}
 	suite.sampleStoreCreate = store.CreateIntel{
 		CreatedBy:        suite.tokenOK.UserID,
@@ -77,6 +79,8 @@ func (suite *handleCreateIntelSuite) SetupTest() {
 		Type:             store.IntelTypePlaintextMessage,
 		Content:          json.RawMessage(`{"text":"hello"}`),
 		InitialDeliverTo: suite.samplePublicCreate.InitialDeliverTo,
+		InitialDeliverAt: suite.samplePublicCreate.InitialDeliverAt,
+		ValidUntil:       suite.samplePublicCreate.ValidUntil,
 	}
 	suite.sampleStoreCreated = store.Intel{
 		ID:         testutil.NewUUIDV4(),
@@ -87,6 +91,7 @@ func (suite *handleCreateIntelSuite) SetupTest() {
 		Content:    suite.sampleStoreCreate.Content,
 		SearchText: suite.sampleStoreCreate.SearchText,
 		IsValid:    true,
+		ValidUntil: suite.sampleStoreCreate.ValidUntil,
 	}
 	suite.samplePublicCreated = publicIntel{
 		ID:         suite.sampleStoreCreated.ID,
@@ -97,6 +102,7 @@ func (suite *handleCreateIntelSuite) SetupTest() {
 		Content:    suite.sampleStoreCreated.Content,
 		SearchText: suite.sampleStoreCreated.SearchText,
 		IsValid:    true,
+		ValidUntil: suite.sampleStoreCreated.ValidUntil,
 	}
 }
 
@@ -127,6 +133,57 @@ func (suite *handleCreateIntelSuite) TestNotAuthenticated() {
 	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
 }
 
+func (suite *handleCreateIntelSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{}
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/intel",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicCreate)),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleCreateIntelSuite) TestPermissionForOtherOperation() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{
+		operationScopedPermission(permission.CreateIntelPermissionName, testutil.NewUUIDV4()),
+	}
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/intel",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicCreate)),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleCreateIntelSuite) TestPermissionForOperationOK() {
+	suite.tokenOK.Permissions = []permission.Permission{
+		operationScopedPermission(permission.CreateIntelPermissionName, suite.samplePublicCreate.Operation),
+	}
+	suite.s.On("CreateIntel", mock.Anything, suite.sampleStoreCreate).
+		Return(suite.sampleStoreCreated, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/intel",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicCreate)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusCreated, rr.Code, "should return correct code")
+}
+
 func (suite *handleCreateIntelSuite) TestInvalidBody() {
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -670,7 +727,9 @@ func (suite *handleGetAllIntelSuite) SetupTest() {
 			Content:    testutil.MarshalJSONMust(store.IntelTypeAnalogRadioMessageContent{}),
 			SearchText: nulls.NewString("pool"),
 			Importance: 648,
-			IsValid:    false,
+			IsValid:    true,
+			ValidUntil: nulls.NewTime(time.Date(2022, 9, 20, 22, 0, 0, 0, time.UTC)),
+			IsExpired:  true,
 		},
 	}, 14)
 	suite.samplePublicIntel = pagination.MapPaginated(suite.sampleStoreIntel, func(from store.Intel) publicIntel {
@@ -851,3 +910,368 @@ func (suite *handleGetAllIntelSuite) TestOKWithViewAnyPermission() {
 func Test_handleGetAllIntel(t *testing.T) {
 	suite.Run(t, new(handleGetAllIntelSuite))
 }
+
+// handleAmendIntelSuite tests handleAmendIntel.
+type handleAmendIntelSuite struct {
+	suite.Suite
+	s                   *StoreMock
+	r                   *gin.Engine
+	tokenOK             auth.Token
+	sampleIntelID       uuid.UUID
+	samplePublicAmend   publicAmendIntel
+	sampleStoreAmend    store.AmendIntel
+	sampleStoreCreated  store.Intel
+	samplePublicCreated publicIntel
+}
+
+func (suite *handleAmendIntelSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "rough",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.AmendIntelPermissionName}},
+		RandomSalt:      nil,
+	}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+	suite.samplePublicAmend = publicAmendIntel{
+		Type:                 intelTypePlaintextMessage,
+		Content:              json.RawMessage(`{"text":"hello"}`),
+		Importance:           78,
+		RedeliverToDelivered: true,
+		ValidUntil:           nulls.NewTime(time.Date(2099, 9, 1, 10, 0, 0, 0, time.UTC)),
+	}
+	suite.sampleStoreAmend = store.AmendIntel{
+		Intel:                suite.sampleIntelID,
+		CreatedBy:            suite.tokenOK.UserID,
+		Type:                 store.IntelTypePlaintextMessage,
+		Content:              json.RawMessage(`{"text":"hello"}`),
+		Importance:           78,
+		RedeliverToDelivered: true,
+		ValidUntil:           suite.samplePublicAmend.ValidUntil,
+	}
+	suite.sampleStoreCreated = store.Intel{
+		ID:              testutil.NewUUIDV4(),
+		CreatedAt:       time.Now().UTC(),
+		CreatedBy:       suite.sampleStoreAmend.CreatedBy,
+		Operation:       testutil.NewUUIDV4(),
+		Type:            suite.sampleStoreAmend.Type,
+		Content:         suite.sampleStoreAmend.Content,
+		SearchText:      nulls.NewString("hello"),
+		Importance:      suite.sampleStoreAmend.Importance,
+		IsValid:         true,
+		PreviousVersion: nulls.NewUUID(suite.sampleIntelID),
+		Version:         2,
+	}
+	suite.samplePublicCreated = publicIntel{
+		ID:              suite.sampleStoreCreated.ID,
+		CreatedAt:       suite.sampleStoreCreated.CreatedAt,
+		CreatedBy:       suite.sampleStoreCreated.CreatedBy,
+		Operation:       suite.sampleStoreCreated.Operation,
+		Type:            intelTypePlaintextMessage,
+		Content:         suite.sampleStoreCreated.Content,
+		SearchText:      suite.sampleStoreCreated.SearchText,
+		Importance:      suite.sampleStoreCreated.Importance,
+		IsValid:         true,
+		PreviousVersion: suite.sampleStoreCreated.PreviousVersion,
+		Version:         2,
+	}
+}
+
+func (suite *handleAmendIntelSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{}
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestInvalidID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/intel/abc/amend",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   strings.NewReader(`{invalid`),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestUnsupportedType() {
+	suite.samplePublicAmend.Type = "meow"
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestInvalidContent() {
+	suite.samplePublicAmend.Content = json.RawMessage(`{"text":""}`)
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestAmendFail() {
+	suite.s.On("AmendIntel", mock.Anything, suite.sampleStoreAmend).
+		Return(store.Intel{}, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleAmendIntelSuite) TestOK() {
+	suite.s.On("AmendIntel", mock.Anything, suite.sampleStoreAmend).
+		Return(suite.sampleStoreCreated, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/intel/%s/amend", suite.sampleIntelID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicAmend)),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Require().Equal(http.StatusCreated, rr.Code, "should return correct code")
+	var got publicIntel
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicCreated, got, "should return correct body")
+}
+
+func Test_handleAmendIntel(t *testing.T) {
+	suite.Run(t, new(handleAmendIntelSuite))
+}
+
+// handleGetIntelVersionsByIntelSuite tests handleGetIntelVersionsByIntel.
+type handleGetIntelVersionsByIntelSuite struct {
+	suite.Suite
+	s                    *StoreMock
+	r                    *gin.Engine
+	tokenOK              auth.Token
+	sampleID             uuid.UUID
+	sampleStoreVersions  []store.Intel
+	samplePublicVersions []publicIntel
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s)
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "split",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		RandomSalt:      nil,
+	}
+	suite.sampleID = testutil.NewUUIDV4()
+	operation := testutil.NewUUIDV4()
+	suite.sampleStoreVersions = []store.Intel{
+		{
+			ID:         suite.sampleID,
+			CreatedAt:  time.Now().UTC(),
+			CreatedBy:  testutil.NewUUIDV4(),
+			Operation:  operation,
+			Type:       store.IntelTypePlaintextMessage,
+			Content:    json.RawMessage(`{"text":"hello"}`),
+			SearchText: nulls.NewString("hello"),
+			Importance: 12,
+			IsValid:    false,
+			Version:    1,
+		},
+		{
+			ID:              testutil.NewUUIDV4(),
+			CreatedAt:       time.Now().UTC(),
+			CreatedBy:       testutil.NewUUIDV4(),
+			Operation:       operation,
+			Type:            store.IntelTypePlaintextMessage,
+			Content:         json.RawMessage(`{"text":"hello world"}`),
+			SearchText:      nulls.NewString("hello world"),
+			Importance:      14,
+			IsValid:         true,
+			PreviousVersion: nulls.NewUUID(suite.sampleID),
+			Version:         2,
+		},
+	}
+	suite.samplePublicVersions = make([]publicIntel, 0, len(suite.sampleStoreVersions))
+	for _, sIntel := range suite.sampleStoreVersions {
+		suite.samplePublicVersions = append(suite.samplePublicVersions, publicIntel{
+			ID:              sIntel.ID,
+			CreatedAt:       sIntel.CreatedAt,
+			CreatedBy:       sIntel.CreatedBy,
+			Operation:       sIntel.Operation,
+			Type:            intelTypePlaintextMessage,
+			Content:         sIntel.Content,
+			SearchText:      sIntel.SearchText,
+			Importance:      sIntel.Importance,
+			IsValid:         sIntel.IsValid,
+			PreviousVersion: sIntel.PreviousVersion,
+			Version:         sIntel.Version,
+		})
+	}
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel/%s/versions", suite.sampleID.String()),
+		Token:  suite.tokenOK,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestNotAuthenticated() {
+	token := suite.tokenOK
+	token.IsAuthenticated = false
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel/%s/versions", suite.sampleID.String()),
+		Token:  token,
+	})
+
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestInvalidID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/intel/abc/versions",
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestRetrieveFail() {
+	suite.s.On("IntelVersionsByIntel", mock.Anything, suite.sampleID, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel/%s/versions", suite.sampleID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestOKLimitedToUser() {
+	suite.s.On("IntelVersionsByIntel", mock.Anything, suite.sampleID, nulls.NewUUID(suite.tokenOK.UserID)).
+		Return(suite.sampleStoreVersions, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel/%s/versions", suite.sampleID.String()),
+		Token:  suite.tokenOK,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicIntel
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicVersions, got, "should return correct body")
+}
+
+func (suite *handleGetIntelVersionsByIntelSuite) TestOKViewAny() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{{Name: permission.ViewAnyIntelPermissionName}}
+	suite.s.On("IntelVersionsByIntel", mock.Anything, suite.sampleID, uuid.NullUUID{}).
+		Return(suite.sampleStoreVersions, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/intel/%s/versions", suite.sampleID.String()),
+		Token:  token,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicIntel
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicVersions, got, "should return correct body")
+}
+
+func Test_handleGetIntelVersionsByIntel(t *testing.T) {
+	suite.Run(t, new(handleGetIntelVersionsByIntelSuite))
+}
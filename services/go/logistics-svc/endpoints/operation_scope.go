@@ -0,0 +1,125 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+)
+
+// operationByIntelStore are the dependencies needed for looking up the
+// operation of intel.
+type operationByIntelStore interface {
+	OperationByIntel(ctx context.Context, intelID uuid.UUID) (uuid.UUID, error)
+}
+
+// operationByIntelDeliveryStore are the dependencies needed for looking up the
+// operation of intel-deliveries.
+type operationByIntelDeliveryStore interface {
+	OperationByIntelDelivery(ctx context.Context, deliveryID uuid.UUID) (uuid.UUID, error)
+}
+
+// assureOperationScope assures, that the given permission.OperationScope
+// includes the operation, that is retrieved via the given function. The
+// operation is only looked up if the permission is not granted for all
+// operations. If the scope is empty, a meh.ErrForbidden is returned without
+// looking up the operation.
+func assureOperationScope(token auth.Token, scope permission.OperationScope, operation func() (uuid.UUID, error)) error {
+	if !token.IsAuthenticated {
+		return meh.NewUnauthorizedErr("not authenticated", nil)
+	}
+	if scope.All {
+		return nil
+	}
+	if scope.Empty() {
+		return meh.NewForbiddenErr("permission not granted", nil)
+	}
+	operationID, err := operation()
+	if err != nil {
+		return meh.Wrap(err, "retrieve operation", nil)
+	}
+	if !scope.Includes(operationID) {
+		return meh.NewForbiddenErr("permission not granted for operation", meh.Details{
+			"operation_id":       operationID,
+			"granted_operations": scope.Operations,
+		})
+	}
+	return nil
+}
+
+// assurePermissionForIntel assures, that the given permission.Matcher is
+// granted for the operation of the intel with the given id.
+func assurePermissionForIntel(ctx context.Context, s operationByIntelStore, token auth.Token, intelID uuid.UUID,
+	matcher permission.Matcher) error {
+	scope, err := auth.GrantedOperations(token, matcher)
+	if err != nil {
+		return meh.Wrap(err, "granted operations", nil)
+	}
+	return assureOperationScope(token, scope, func() (uuid.UUID, error) {
+		operationID, err := s.OperationByIntel(ctx, intelID)
+		if err != nil {
+			return uuid.Nil, meh.Wrap(err, "operation by intel", meh.Details{"intel_id": intelID})
+		}
+		return operationID, nil
+	})
+}
+
+// assurePermissionForIntelDelivery assures, that the given permission.Matcher
+// is granted for the operation of the intel-delivery with the given id.
+func assurePermissionForIntelDelivery(ctx context.Context, s operationByIntelDeliveryStore, token auth.Token,
+	deliveryID uuid.UUID, matcher permission.Matcher) error {
+	scope, err := auth.GrantedOperations(token, matcher)
+	if err != nil {
+		return meh.Wrap(err, "granted operations", nil)
+	}
+	return assureOperationScope(token, scope, func() (uuid.UUID, error) {
+		operationID, err := s.OperationByIntelDelivery(ctx, deliveryID)
+		if err != nil {
+			return uuid.Nil, meh.Wrap(err, "operation by intel-delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		return operationID, nil
+	})
+}
+
+// limitIntelToUserForIntel returns the user, visibility of the intel with the
+// given id needs to be limited to. If permission.ViewAnyIntel is granted for
+// the operation of the intel, no limitation is returned.
+func limitIntelToUserForIntel(ctx context.Context, s operationByIntelStore, token auth.Token,
+	intelID uuid.UUID) (uuid.NullUUID, error) {
+	scope, err := auth.GrantedOperations(token, permission.ViewAnyIntel())
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "granted operations", nil)
+	}
+	if scope.All {
+		return uuid.NullUUID{}, nil
+	}
+	if scope.Empty() {
+		return nulls.NewUUID(token.UserID), nil
+	}
+	operationID, err := s.OperationByIntel(ctx, intelID)
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "operation by intel", meh.Details{"intel_id": intelID})
+	}
+	if scope.Includes(operationID) {
+		return uuid.NullUUID{}, nil
+	}
+	return nulls.NewUUID(token.UserID), nil
+}
+
+// limitIntelToUserForFilters returns the user, visibility of intel retrieved
+// with the given store.IntelFilters needs to be limited to. If
+// permission.ViewAnyIntel is granted for all operations or for the one in
+// store.IntelFilters.Operation, no limitation is returned.
+func limitIntelToUserForFilters(token auth.Token, filters store.IntelFilters) (uuid.NullUUID, error) {
+	scope, err := auth.GrantedOperations(token, permission.ViewAnyIntel())
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "granted operations", nil)
+	}
+	if scope.All || (filters.Operation.Valid && scope.Includes(filters.Operation.UUID)) {
+		return uuid.NullUUID{}, nil
+	}
+	return nulls.NewUUID(token.UserID), nil
+}
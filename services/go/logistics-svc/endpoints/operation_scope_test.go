@@ -0,0 +1,290 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/logistics-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// operationScopedPermission creates a permission.Permission with the given
+// name, that is limited to the operations with the given ids.
+func operationScopedPermission(name permission.Name, operations ...uuid.UUID) permission.Permission {
+	if operations == nil {
+		operations = make([]uuid.UUID, 0)
+	}
+	raw, err := json.Marshal(permission.OperationScopeOptions{Operations: operations})
+	if err != nil {
+		panic(err)
+	}
+	return permission.Permission{
+		Name:    name,
+		Options: nulls.NewJSONRawMessage(raw),
+	}
+}
+
+// assurePermissionForIntelSuite tests assurePermissionForIntel.
+type assurePermissionForIntelSuite struct {
+	suite.Suite
+	s               *StoreMock
+	sampleIntelID   uuid.UUID
+	sampleOperation uuid.UUID
+	sampleToken     auth.Token
+}
+
+func (suite *assurePermissionForIntelSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+	suite.sampleOperation = testutil.NewUUIDV4()
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions: []permission.Permission{
+			operationScopedPermission(permission.AmendIntelPermissionName, testutil.NewUUIDV4(), suite.sampleOperation),
+		},
+	}
+}
+
+func (suite *assurePermissionForIntelSuite) TestNotAuthenticated() {
+	token := suite.sampleToken
+	token.IsAuthenticated = false
+	err := assurePermissionForIntel(context.Background(), suite.s, token, suite.sampleIntelID, permission.AmendIntel())
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrUnauthorized, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *assurePermissionForIntelSuite) TestGrantedForAll() {
+	token := suite.sampleToken
+	token.Permissions = []permission.Permission{{Name: permission.AmendIntelPermissionName}}
+	err := assurePermissionForIntel(context.Background(), suite.s, token, suite.sampleIntelID, permission.AmendIntel())
+	suite.NoError(err, "should not fail")
+}
+
+func (suite *assurePermissionForIntelSuite) TestNotGranted() {
+	token := suite.sampleToken
+	token.Permissions = []permission.Permission{{Name: permission.InvalidateIntelPermissionName}}
+	err := assurePermissionForIntel(context.Background(), suite.s, token, suite.sampleIntelID, permission.AmendIntel())
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *assurePermissionForIntelSuite) TestOperationByIntelFail() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(uuid.Nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID, permission.AmendIntel())
+	suite.Error(err, "should fail")
+}
+
+func (suite *assurePermissionForIntelSuite) TestOtherOperation() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(testutil.NewUUIDV4(), nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID, permission.AmendIntel())
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *assurePermissionForIntelSuite) TestOK() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(suite.sampleOperation, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID, permission.AmendIntel())
+	suite.NoError(err, "should not fail")
+}
+
+func Test_assurePermissionForIntel(t *testing.T) {
+	suite.Run(t, new(assurePermissionForIntelSuite))
+}
+
+// assurePermissionForIntelDeliverySuite tests
+// assurePermissionForIntelDelivery.
+type assurePermissionForIntelDeliverySuite struct {
+	suite.Suite
+	s                *StoreMock
+	sampleDeliveryID uuid.UUID
+	sampleOperation  uuid.UUID
+	sampleToken      auth.Token
+}
+
+func (suite *assurePermissionForIntelDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.sampleDeliveryID = testutil.NewUUIDV4()
+	suite.sampleOperation = testutil.NewUUIDV4()
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions: []permission.Permission{
+			operationScopedPermission(permission.ManageIntelDeliveryPermissionName, suite.sampleOperation),
+		},
+	}
+}
+
+func (suite *assurePermissionForIntelDeliverySuite) TestOperationByIntelDeliveryFail() {
+	suite.s.On("OperationByIntelDelivery", context.Background(), suite.sampleDeliveryID).
+		Return(uuid.Nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntelDelivery(context.Background(), suite.s, suite.sampleToken, suite.sampleDeliveryID,
+		permission.ManageIntelDelivery())
+	suite.Error(err, "should fail")
+}
+
+func (suite *assurePermissionForIntelDeliverySuite) TestOtherOperation() {
+	suite.s.On("OperationByIntelDelivery", context.Background(), suite.sampleDeliveryID).
+		Return(testutil.NewUUIDV4(), nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntelDelivery(context.Background(), suite.s, suite.sampleToken, suite.sampleDeliveryID,
+		permission.ManageIntelDelivery())
+	suite.Require().Error(err, "should fail")
+	suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+}
+
+func (suite *assurePermissionForIntelDeliverySuite) TestOK() {
+	suite.s.On("OperationByIntelDelivery", context.Background(), suite.sampleDeliveryID).
+		Return(suite.sampleOperation, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	err := assurePermissionForIntelDelivery(context.Background(), suite.s, suite.sampleToken, suite.sampleDeliveryID,
+		permission.ManageIntelDelivery())
+	suite.NoError(err, "should not fail")
+}
+
+func Test_assurePermissionForIntelDelivery(t *testing.T) {
+	suite.Run(t, new(assurePermissionForIntelDeliverySuite))
+}
+
+// limitIntelToUserForIntelSuite tests limitIntelToUserForIntel.
+type limitIntelToUserForIntelSuite struct {
+	suite.Suite
+	s               *StoreMock
+	sampleIntelID   uuid.UUID
+	sampleOperation uuid.UUID
+	sampleToken     auth.Token
+}
+
+func (suite *limitIntelToUserForIntelSuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+	suite.sampleOperation = testutil.NewUUIDV4()
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions: []permission.Permission{
+			operationScopedPermission(permission.ViewAnyIntelPermissionName, suite.sampleOperation),
+		},
+	}
+}
+
+func (suite *limitIntelToUserForIntelSuite) TestGrantedForAll() {
+	token := suite.sampleToken
+	token.Permissions = []permission.Permission{{Name: permission.ViewAnyIntelPermissionName}}
+	limitToUser, err := limitIntelToUserForIntel(context.Background(), suite.s, token, suite.sampleIntelID)
+	suite.Require().NoError(err, "should not fail")
+	suite.False(limitToUser.Valid, "should not limit")
+}
+
+func (suite *limitIntelToUserForIntelSuite) TestNotGranted() {
+	token := suite.sampleToken
+	token.Permissions = []permission.Permission{}
+	limitToUser, err := limitIntelToUserForIntel(context.Background(), suite.s, token, suite.sampleIntelID)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(nulls.NewUUID(token.UserID), limitToUser, "should limit to user")
+}
+
+func (suite *limitIntelToUserForIntelSuite) TestOperationByIntelFail() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(uuid.Nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	_, err := limitIntelToUserForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID)
+	suite.Error(err, "should fail")
+}
+
+func (suite *limitIntelToUserForIntelSuite) TestOtherOperation() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(testutil.NewUUIDV4(), nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	limitToUser, err := limitIntelToUserForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(nulls.NewUUID(suite.sampleToken.UserID), limitToUser, "should limit to user")
+}
+
+func (suite *limitIntelToUserForIntelSuite) TestGrantedForOperation() {
+	suite.s.On("OperationByIntel", context.Background(), suite.sampleIntelID).
+		Return(suite.sampleOperation, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	limitToUser, err := limitIntelToUserForIntel(context.Background(), suite.s, suite.sampleToken, suite.sampleIntelID)
+	suite.Require().NoError(err, "should not fail")
+	suite.False(limitToUser.Valid, "should not limit")
+}
+
+func Test_limitIntelToUserForIntel(t *testing.T) {
+	suite.Run(t, new(limitIntelToUserForIntelSuite))
+}
+
+// limitIntelToUserForFiltersSuite tests limitIntelToUserForFilters.
+type limitIntelToUserForFiltersSuite struct {
+	suite.Suite
+	sampleOperation uuid.UUID
+	sampleToken     auth.Token
+}
+
+func (suite *limitIntelToUserForFiltersSuite) SetupTest() {
+	suite.sampleOperation = testutil.NewUUIDV4()
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions: []permission.Permission{
+			operationScopedPermission(permission.ViewAnyIntelPermissionName, suite.sampleOperation),
+		},
+	}
+}
+
+func (suite *limitIntelToUserForFiltersSuite) TestGrantedForAll() {
+	token := suite.sampleToken
+	token.Permissions = []permission.Permission{{Name: permission.ViewAnyIntelPermissionName}}
+	limitToUser, err := limitIntelToUserForFilters(token, store.IntelFilters{})
+	suite.Require().NoError(err, "should not fail")
+	suite.False(limitToUser.Valid, "should not limit")
+}
+
+func (suite *limitIntelToUserForFiltersSuite) TestNoOperationFilter() {
+	limitToUser, err := limitIntelToUserForFilters(suite.sampleToken, store.IntelFilters{})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(nulls.NewUUID(suite.sampleToken.UserID), limitToUser, "should limit to user")
+}
+
+func (suite *limitIntelToUserForFiltersSuite) TestOtherOperationFilter() {
+	limitToUser, err := limitIntelToUserForFilters(suite.sampleToken, store.IntelFilters{
+		Operation: nulls.NewUUID(testutil.NewUUIDV4()),
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(nulls.NewUUID(suite.sampleToken.UserID), limitToUser, "should limit to user")
+}
+
+func (suite *limitIntelToUserForFiltersSuite) TestGrantedForFilteredOperation() {
+	limitToUser, err := limitIntelToUserForFilters(suite.sampleToken, store.IntelFilters{
+		Operation: nulls.NewUUID(suite.sampleOperation),
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.False(limitToUser.Valid, "should not limit")
+}
+
+func Test_limitIntelToUserForFilters(t *testing.T) {
+	suite.Run(t, new(limitIntelToUserForFiltersSuite))
+}
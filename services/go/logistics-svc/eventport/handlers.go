@@ -18,6 +18,8 @@ type Handler interface {
 	CreateUser(ctx context.Context, tx pgx.Tx, userID store.User) error
 	// UpdateUser updates the given store.user, identified by its id.
 	UpdateUser(ctx context.Context, tx pgx.Tx, user store.User) error
+	// UpdateUserPresence updates the given store.UserPresence.
+	UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence store.UserPresence) error
 	// CreateGroup creates the given store.Group.
 	CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error
 	// UpdateGroup updates the given store.Group, identified by its id.
@@ -43,6 +45,9 @@ type Handler interface {
 	// MarkIntelDeliveryAttemptAsFailed marks the intel-delivery-attempt with the
 	// given id with store.IntelDeliveryStatusFailed, if still being active.
 	MarkIntelDeliveryAttemptAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, note nulls.String) error
+	// AcknowledgeIntelDeliveryAttempt records the user with the given id as having
+	// acknowledged the intel-delivery-attempt and marks it as delivered.
+	AcknowledgeIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error
 }
 
 // HandlerFn for handling messages.
@@ -57,8 +62,16 @@ func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 			return meh.NilOrWrap(p.handleUsersTopic(ctx, tx, handler, message), "handle users topic", nil)
 		case event.InAppNotificationsTopic:
 			return meh.NilOrWrap(p.handleInAppNotificationsTopic(ctx, tx, handler, message), "handle in-app-notifications topic", nil)
+		case event.UserPresenceTopic:
+			return meh.NilOrWrap(p.handleUserPresenceTopic(ctx, tx, handler, message), "handle user-presence topic", nil)
 		case event.RadioDeliveriesTopic:
 			return meh.NilOrWrap(p.handleRadioDeliveriesTopic(ctx, tx, handler, message), "handle radio-deliveries topic", nil)
+		case event.EmailDeliveriesTopic:
+			return meh.NilOrWrap(p.handleEmailDeliveriesTopic(ctx, tx, handler, message), "handle email-deliveries topic", nil)
+		case event.PhoneCallDeliveriesTopic:
+			return meh.NilOrWrap(p.handlePhoneCallDeliveriesTopic(ctx, tx, handler, message), "handle phone-call-deliveries topic", nil)
+		case event.DirectDeliveriesTopic:
+			return meh.NilOrWrap(p.handleDirectDeliveriesTopic(ctx, tx, handler, message), "handle direct-deliveries topic", nil)
 		}
 		return nil
 	}
@@ -268,6 +281,10 @@ func (p *Port) handleInAppNotificationsTopic(ctx context.Context, tx pgx.Tx, han
 		return meh.NilOrWrap(p.handleInAppNotificationForIntelPending(ctx, tx, handler, message), "handle in-app-notification pending", nil)
 	case event.TypeInAppNotificationForIntelSent:
 		return meh.NilOrWrap(p.handleInAppNotificationForIntelSent(ctx, tx, handler, message), "handle in-app-notification sent", nil)
+	case event.TypeInAppNotificationForIntelReceived:
+		return meh.NilOrWrap(p.handleInAppNotificationForIntelReceived(ctx, tx, handler, message), "handle in-app-notification received", nil)
+	case event.TypeInAppNotificationForIntelAcknowledged:
+		return meh.NilOrWrap(p.handleInAppNotificationForIntelAcknowledged(ctx, tx, handler, message), "handle in-app-notification acknowledged", nil)
 	}
 	return nil
 }
@@ -304,6 +321,68 @@ func (p *Port) handleInAppNotificationForIntelSent(ctx context.Context, tx pgx.T
 	return nil
 }
 
+// handleInAppNotificationForIntelReceived handles an
+// event.TypeInAppNotificationForIntelReceived event.
+func (p *Port) handleInAppNotificationForIntelReceived(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var notifReceivedEvent event.InAppNotificationForIntelReceived
+	err := json.Unmarshal(message.RawValue, &notifReceivedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, notifReceivedEvent.Attempt, store.IntelDeliveryStatusAwaitingAck,
+		nulls.NewString("in-app-notification received"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": notifReceivedEvent.Attempt})
+	}
+	return nil
+}
+
+// handleInAppNotificationForIntelAcknowledged handles an
+// event.TypeInAppNotificationForIntelAcknowledged event.
+func (p *Port) handleInAppNotificationForIntelAcknowledged(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var notifAcknowledgedEvent event.InAppNotificationForIntelAcknowledged
+	err := json.Unmarshal(message.RawValue, &notifAcknowledgedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.AcknowledgeIntelDeliveryAttempt(ctx, tx, notifAcknowledgedEvent.Attempt, notifAcknowledgedEvent.AcknowledgedBy)
+	if err != nil {
+		return meh.Wrap(err, "acknowledge intel-delivery-attempt", meh.Details{
+			"attempt_id": notifAcknowledgedEvent.Attempt,
+			"by":         notifAcknowledgedEvent.AcknowledgedBy,
+		})
+	}
+	return nil
+}
+
+// handleUserPresenceTopic handles the event.UserPresenceTopic.
+func (p *Port) handleUserPresenceTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeUserPresenceUpdated:
+		return meh.NilOrWrap(p.handleUserPresenceUpdated(ctx, tx, handler, message), "handle user presence updated", nil)
+	}
+	return nil
+}
+
+// handleUserPresenceUpdated handles an event.TypeUserPresenceUpdated event.
+func (p *Port) handleUserPresenceUpdated(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var presenceUpdatedEvent event.UserPresenceUpdated
+	err := json.Unmarshal(message.RawValue, &presenceUpdatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	presence := store.UserPresence{
+		User:     presenceUpdatedEvent.User,
+		IsOnline: presenceUpdatedEvent.IsOnline,
+		LastSeen: presenceUpdatedEvent.LastSeen,
+	}
+	err = handler.UpdateUserPresence(ctx, tx, presence)
+	if err != nil {
+		return meh.Wrap(err, "update user presence", meh.Details{"presence": presence})
+	}
+	return nil
+}
+
 // handleRadioDeliveriesTopic handles the event.RadioDeliveriesTopic.
 func (p *Port) handleRadioDeliveriesTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
 	switch message.EventType {
@@ -390,3 +469,234 @@ func (p *Port) handleRadioDeliveryFinished(ctx context.Context, tx pgx.Tx, handl
 	}
 	return nil
 }
+
+// handlePhoneCallDeliveriesTopic handles the event.PhoneCallDeliveriesTopic.
+func (p *Port) handlePhoneCallDeliveriesTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypePhoneCallDeliveryReadyForPickup:
+		return meh.NilOrWrap(p.handlePhoneCallDeliveryReadyForPickup(ctx, tx, handler, message), "handle phone call delivery ready for pickup", nil)
+	case event.TypePhoneCallDeliveryPickedUp:
+		return meh.NilOrWrap(p.handlePhoneCallDeliveryPickedUp(ctx, tx, handler, message), "handle phone call delivery picked up", nil)
+	case event.TypePhoneCallDeliveryReleased:
+		return meh.NilOrWrap(p.handlePhoneCallDeliveryReleased(ctx, tx, handler, message), "handle phone call delivery released", nil)
+	case event.TypePhoneCallDeliveryFinished:
+		return meh.NilOrWrap(p.handlePhoneCallDeliveryFinished(ctx, tx, handler, message), "handle phone call delivery finished", nil)
+	}
+	return nil
+}
+
+// handlePhoneCallDeliveryReadyForPickup handles an
+// event.TypePhoneCallDeliveryReadyForPickup event.
+func (p *Port) handlePhoneCallDeliveryReadyForPickup(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var readyEvent event.PhoneCallDeliveryReadyForPickup
+	err := json.Unmarshal(message.RawValue, &readyEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, readyEvent.Attempt, store.IntelDeliveryStatusAwaitingDelivery,
+		nulls.NewString("delivery ready for pickup"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": readyEvent.Attempt})
+	}
+	return nil
+}
+
+// handlePhoneCallDeliveryPickedUp handles an event.handlePhoneCallDeliveryPickedUp
+// event.
+func (p *Port) handlePhoneCallDeliveryPickedUp(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var pickedUpEvent event.PhoneCallDeliveryPickedUp
+	err := json.Unmarshal(message.RawValue, &pickedUpEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, pickedUpEvent.Attempt, store.IntelDeliveryStatusDelivering,
+		nulls.NewString("delivery picked up"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": pickedUpEvent.Attempt})
+	}
+	return nil
+}
+
+// handlePhoneCallDeliveryReleased handles an event.handlePhoneCallDeliveryReleased
+// event.
+func (p *Port) handlePhoneCallDeliveryReleased(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var releasedEvent event.PhoneCallDeliveryReleased
+	err := json.Unmarshal(message.RawValue, &releasedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, releasedEvent.Attempt, store.IntelDeliveryStatusAwaitingDelivery,
+		nulls.NewString("delivery ready for pickup (released)"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": releasedEvent.Attempt})
+	}
+	return nil
+}
+
+// handlePhoneCallDeliveryFinished handles an event.handlePhoneCallDeliveryFinished
+// event.
+func (p *Port) handlePhoneCallDeliveryFinished(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var finishedEvent event.PhoneCallDeliveryFinished
+	err := json.Unmarshal(message.RawValue, &finishedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	if finishedEvent.Success {
+		// Mark as delivered.
+		err = handler.MarkIntelDeliveryAttemptAsDeliveredTx(ctx, tx, finishedEvent.Attempt, uuid.NullUUID{})
+		if err != nil {
+			return meh.Wrap(err, "mark intel-delivery-attempt as delivered", meh.Details{"attempt_id": finishedEvent.Attempt})
+		}
+	} else {
+		// Mark as failed.
+		err = handler.MarkIntelDeliveryAttemptAsFailed(ctx, tx, finishedEvent.Attempt, nulls.NewString(finishedEvent.Note))
+		if err != nil {
+			return meh.Wrap(err, "mark intel-delivery-attempt as failed", meh.Details{"attempt_id": finishedEvent.Attempt})
+		}
+	}
+	return nil
+}
+
+// handleDirectDeliveriesTopic handles the event.DirectDeliveriesTopic.
+func (p *Port) handleDirectDeliveriesTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeDirectDeliveryReadyForPickup:
+		return meh.NilOrWrap(p.handleDirectDeliveryReadyForPickup(ctx, tx, handler, message), "handle direct delivery ready for pickup", nil)
+	case event.TypeDirectDeliveryPickedUp:
+		return meh.NilOrWrap(p.handleDirectDeliveryPickedUp(ctx, tx, handler, message), "handle direct delivery picked up", nil)
+	case event.TypeDirectDeliveryReleased:
+		return meh.NilOrWrap(p.handleDirectDeliveryReleased(ctx, tx, handler, message), "handle direct delivery released", nil)
+	case event.TypeDirectDeliveryFinished:
+		return meh.NilOrWrap(p.handleDirectDeliveryFinished(ctx, tx, handler, message), "handle direct delivery finished", nil)
+	}
+	return nil
+}
+
+// handleDirectDeliveryReadyForPickup handles an
+// event.TypeDirectDeliveryReadyForPickup event.
+func (p *Port) handleDirectDeliveryReadyForPickup(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var readyEvent event.DirectDeliveryReadyForPickup
+	err := json.Unmarshal(message.RawValue, &readyEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, readyEvent.Attempt, store.IntelDeliveryStatusAwaitingDelivery,
+		nulls.NewString("delivery ready for pickup"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": readyEvent.Attempt})
+	}
+	return nil
+}
+
+// handleDirectDeliveryPickedUp handles an event.handleDirectDeliveryPickedUp
+// event.
+func (p *Port) handleDirectDeliveryPickedUp(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var pickedUpEvent event.DirectDeliveryPickedUp
+	err := json.Unmarshal(message.RawValue, &pickedUpEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, pickedUpEvent.Attempt, store.IntelDeliveryStatusDelivering,
+		nulls.NewString("delivery picked up"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": pickedUpEvent.Attempt})
+	}
+	return nil
+}
+
+// handleDirectDeliveryReleased handles an event.handleDirectDeliveryReleased
+// event.
+func (p *Port) handleDirectDeliveryReleased(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var releasedEvent event.DirectDeliveryReleased
+	err := json.Unmarshal(message.RawValue, &releasedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, releasedEvent.Attempt, store.IntelDeliveryStatusAwaitingDelivery,
+		nulls.NewString("delivery ready for pickup (released)"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": releasedEvent.Attempt})
+	}
+	return nil
+}
+
+// handleDirectDeliveryFinished handles an event.handleDirectDeliveryFinished
+// event.
+func (p *Port) handleDirectDeliveryFinished(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var finishedEvent event.DirectDeliveryFinished
+	err := json.Unmarshal(message.RawValue, &finishedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	if finishedEvent.Success {
+		// Mark as delivered.
+		err = handler.MarkIntelDeliveryAttemptAsDeliveredTx(ctx, tx, finishedEvent.Attempt, uuid.NullUUID{})
+		if err != nil {
+			return meh.Wrap(err, "mark intel-delivery-attempt as delivered", meh.Details{"attempt_id": finishedEvent.Attempt})
+		}
+	} else {
+		// Mark as failed.
+		err = handler.MarkIntelDeliveryAttemptAsFailed(ctx, tx, finishedEvent.Attempt, nulls.NewString(finishedEvent.Note))
+		if err != nil {
+			return meh.Wrap(err, "mark intel-delivery-attempt as failed", meh.Details{"attempt_id": finishedEvent.Attempt})
+		}
+	}
+	return nil
+}
+
+// handleEmailDeliveriesTopic handles the event.EmailDeliveriesTopic.
+func (p *Port) handleEmailDeliveriesTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeEmailForIntelPending:
+		return meh.NilOrWrap(p.handleEmailForIntelPending(ctx, tx, handler, message), "handle email for intel pending", nil)
+	case event.TypeEmailForIntelSent:
+		return meh.NilOrWrap(p.handleEmailForIntelSent(ctx, tx, handler, message), "handle email for intel sent", nil)
+	case event.TypeEmailForIntelFailed:
+		return meh.NilOrWrap(p.handleEmailForIntelFailed(ctx, tx, handler, message), "handle email for intel failed", nil)
+	}
+	return nil
+}
+
+// handleEmailForIntelPending handles an event.TypeEmailForIntelPending event.
+func (p *Port) handleEmailForIntelPending(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var pendingEvent event.EmailForIntelPending
+	err := json.Unmarshal(message.RawValue, &pendingEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, pendingEvent.Attempt, store.IntelDeliveryStatusDelivering,
+		nulls.NewString("email pending"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": pendingEvent.Attempt})
+	}
+	return nil
+}
+
+// handleEmailForIntelSent handles an event.TypeEmailForIntelSent event.
+func (p *Port) handleEmailForIntelSent(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var sentEvent event.EmailForIntelSent
+	err := json.Unmarshal(message.RawValue, &sentEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.UpdateIntelDeliveryAttemptStatusForActive(ctx, tx, sentEvent.Attempt, store.IntelDeliveryStatusAwaitingAck,
+		nulls.NewString("email sent"))
+	if err != nil {
+		return meh.Wrap(err, "update intel-delivery-attempt-status for active", meh.Details{"attempt_id": sentEvent.Attempt})
+	}
+	return nil
+}
+
+// handleEmailForIntelFailed handles an event.TypeEmailForIntelFailed event.
+func (p *Port) handleEmailForIntelFailed(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var failedEvent event.EmailForIntelFailed
+	err := json.Unmarshal(message.RawValue, &failedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.MarkIntelDeliveryAttemptAsFailed(ctx, tx, failedEvent.Attempt, nulls.NewString(failedEvent.Reason))
+	if err != nil {
+		return meh.Wrap(err, "mark intel-delivery-attempt as failed", meh.Details{"attempt_id": failedEvent.Attempt})
+	}
+	return nil
+}
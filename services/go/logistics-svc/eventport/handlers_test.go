@@ -30,6 +30,10 @@ func (m *HandlerMock) UpdateUser(ctx context.Context, tx pgx.Tx, user store.User
 	return m.Called(ctx, tx, user).Error(0)
 }
 
+func (m *HandlerMock) UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence store.UserPresence) error {
+	return m.Called(ctx, tx, presence).Error(0)
+}
+
 func (m *HandlerMock) CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error {
 	return m.Called(ctx, tx, create).Error(0)
 }
@@ -71,6 +75,10 @@ func (m *HandlerMock) MarkIntelDeliveryAttemptAsDeliveredTx(ctx context.Context,
 	return m.Called(ctx, tx, attemptID, by).Error(0)
 }
 
+func (m *HandlerMock) AcknowledgeIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error {
+	return m.Called(ctx, tx, attemptID, by).Error(0)
+}
+
 func (m *HandlerMock) MarkIntelDeliveryAttemptAsFailed(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, note nulls.String) error {
 	return m.Called(ctx, tx, attemptID, note).Error(0)
 }
@@ -244,6 +252,86 @@ func TestPort_handleUserUpdated(t *testing.T) {
 	suite.Run(t, new(portHandleUserUpdatedSuite))
 }
 
+// portHandleUserPresenceUpdatedSuite tests Port.handleUserPresenceUpdated.
+type portHandleUserPresenceUpdatedSuite struct {
+	suite.Suite
+	handler        *HandlerMock
+	port           *PortMock
+	sampleEvent    event.UserPresenceUpdated
+	samplePresence store.UserPresence
+}
+
+func (suite *portHandleUserPresenceUpdatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.UserPresenceUpdated{
+		User:     testutil.NewUUIDV4(),
+		IsOnline: true,
+		LastSeen: time.Date(2022, 9, 8, 0, 4, 19, 0, time.UTC),
+	}
+	suite.samplePresence = store.UserPresence{
+		User:     suite.sampleEvent.User,
+		IsOnline: suite.sampleEvent.IsOnline,
+		LastSeen: suite.sampleEvent.LastSeen,
+	}
+}
+
+func (suite *portHandleUserPresenceUpdatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.UserPresenceTopic,
+		EventType: event.TypeUserPresenceUpdated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleUserPresenceUpdatedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleUserPresenceUpdatedSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateUserPresence", timeout, tx, suite.samplePresence).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleUserPresenceUpdatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateUserPresence", timeout, tx, suite.samplePresence).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleUserPresenceUpdated(t *testing.T) {
+	suite.Run(t, new(portHandleUserPresenceUpdatedSuite))
+}
+
 // portHandleGroupCreatedSuite tests Port.handleGroupCreated.
 type portHandleGroupCreatedSuite struct {
 	suite.Suite
@@ -900,6 +988,160 @@ func TestPort_handleInAppNotificationForIntelSentSuite(t *testing.T) {
 	suite.Run(t, new(portHandleInAppNotificationForIntelSentSuite))
 }
 
+// portHandleInAppNotificationForIntelReceivedSuite tests
+// Port.handleInAppNotificationForIntelReceived.
+type portHandleInAppNotificationForIntelReceivedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.InAppNotificationForIntelReceived
+}
+
+func (suite *portHandleInAppNotificationForIntelReceivedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.InAppNotificationForIntelReceived{
+		Attempt:    testutil.NewUUIDV4(),
+		ReceivedBy: testutil.NewUUIDV4(),
+		ReceivedAt: time.Date(2022, 9, 8, 14, 1, 12, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleInAppNotificationForIntelReceivedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.InAppNotificationsTopic,
+		EventType: event.TypeInAppNotificationForIntelReceived,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleInAppNotificationForIntelReceivedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleInAppNotificationForIntelReceivedSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingAck, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleInAppNotificationForIntelReceivedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingAck, nulls.NewString("in-app-notification received")).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleInAppNotificationForIntelReceived(t *testing.T) {
+	suite.Run(t, new(portHandleInAppNotificationForIntelReceivedSuite))
+}
+
+// portHandleInAppNotificationForIntelAcknowledgedSuite tests
+// Port.handleInAppNotificationForIntelAcknowledged.
+type portHandleInAppNotificationForIntelAcknowledgedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.InAppNotificationForIntelAcknowledged
+}
+
+func (suite *portHandleInAppNotificationForIntelAcknowledgedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.InAppNotificationForIntelAcknowledged{
+		Attempt:        testutil.NewUUIDV4(),
+		AcknowledgedBy: testutil.NewUUIDV4(),
+		AcknowledgedAt: time.Date(2022, 9, 8, 14, 2, 40, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleInAppNotificationForIntelAcknowledgedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.InAppNotificationsTopic,
+		EventType: event.TypeInAppNotificationForIntelAcknowledged,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleInAppNotificationForIntelAcknowledgedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleInAppNotificationForIntelAcknowledgedSuite) TestAcknowledgeFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("AcknowledgeIntelDeliveryAttempt", timeout, tx, suite.sampleEvent.Attempt, suite.sampleEvent.AcknowledgedBy).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleInAppNotificationForIntelAcknowledgedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("AcknowledgeIntelDeliveryAttempt", timeout, tx, suite.sampleEvent.Attempt, suite.sampleEvent.AcknowledgedBy).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleInAppNotificationForIntelAcknowledged(t *testing.T) {
+	suite.Run(t, new(portHandleInAppNotificationForIntelAcknowledgedSuite))
+}
+
 // portHandleRadioDeliveryReadyForPickupSuite tests
 // Port.handleRadioDeliveryReadyForPickup.
 type portHandleRadioDeliveryReadyForPickupSuite struct {
@@ -1251,3 +1493,934 @@ func (suite *portHandleRadioDeliveryFinishedSuite) TestOKFailed() {
 func TestPort_handleRadioDeliveryFinishedSuite(t *testing.T) {
 	suite.Run(t, new(portHandleRadioDeliveryFinishedSuite))
 }
+
+// portHandleEmailForIntelPendingSuite tests Port.handleEmailForIntelPending.
+type portHandleEmailForIntelPendingSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.EmailForIntelPending
+}
+
+func (suite *portHandleEmailForIntelPendingSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.EmailForIntelPending{
+		Attempt: testutil.NewUUIDV4(),
+		Since:   time.Date(2022, 9, 8, 13, 59, 39, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleEmailForIntelPendingSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		EventType: event.TypeEmailForIntelPending,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleEmailForIntelPendingSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelPendingSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelPendingSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleEmailForIntelPendingSuite(t *testing.T) {
+	suite.Run(t, new(portHandleEmailForIntelPendingSuite))
+}
+
+// portHandleEmailForIntelSentSuite tests Port.handleEmailForIntelSent.
+type portHandleEmailForIntelSentSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.EmailForIntelSent
+}
+
+func (suite *portHandleEmailForIntelSentSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.EmailForIntelSent{
+		Attempt: testutil.NewUUIDV4(),
+		SentAt:  time.Date(2022, 9, 8, 13, 59, 39, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleEmailForIntelSentSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		EventType: event.TypeEmailForIntelSent,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleEmailForIntelSentSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelSentSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingAck, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelSentSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingAck, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleEmailForIntelSentSuite(t *testing.T) {
+	suite.Run(t, new(portHandleEmailForIntelSentSuite))
+}
+
+// portHandleEmailForIntelFailedSuite tests Port.handleEmailForIntelFailed.
+type portHandleEmailForIntelFailedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.EmailForIntelFailed
+}
+
+func (suite *portHandleEmailForIntelFailedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.EmailForIntelFailed{
+		Attempt:  testutil.NewUUIDV4(),
+		FailedAt: time.Date(2022, 9, 8, 13, 59, 39, 0, time.UTC),
+		Reason:   "mailbox full",
+	}
+}
+
+func (suite *portHandleEmailForIntelFailedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.EmailDeliveriesTopic,
+		EventType: event.TypeEmailForIntelFailed,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleEmailForIntelFailedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelFailedSuite) TestMarkAsFailedFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Reason)).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleEmailForIntelFailedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Reason)).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleEmailForIntelFailedSuite(t *testing.T) {
+	suite.Run(t, new(portHandleEmailForIntelFailedSuite))
+}
+
+// portHandlePhoneCallDeliveryReadyForPickupSuite tests
+// Port.handlePhoneCallDeliveryReadyForPickup.
+type portHandlePhoneCallDeliveryReadyForPickupSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.PhoneCallDeliveryReadyForPickup
+}
+
+func (suite *portHandlePhoneCallDeliveryReadyForPickupSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.PhoneCallDeliveryReadyForPickup{
+		Attempt:                testutil.NewUUIDV4(),
+		Intel:                  testutil.NewUUIDV4(),
+		IntelOperation:         testutil.NewUUIDV4(),
+		IntelImportance:        979,
+		AttemptAssignedTo:      testutil.NewUUIDV4(),
+		AttemptAssignedToLabel: "appear",
+		Delivery:               testutil.NewUUIDV4(),
+		Channel:                testutil.NewUUIDV4(),
+		Note:                   "jaw",
+		AttemptAcceptedAt:      time.Date(2022, 10, 13, 14, 11, 40, 0, time.UTC),
+	}
+}
+
+func (suite *portHandlePhoneCallDeliveryReadyForPickupSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		EventType: event.TypePhoneCallDeliveryReadyForPickup,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandlePhoneCallDeliveryReadyForPickupSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryReadyForPickupSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryReadyForPickupSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handlePhoneCallDeliveryReadyForPickupSuite(t *testing.T) {
+	suite.Run(t, new(portHandlePhoneCallDeliveryReadyForPickupSuite))
+}
+
+// portHandlePhoneCallDeliveryPickedUpSuite tests Port.handlePhoneCallDeliveryPickedUp.
+type portHandlePhoneCallDeliveryPickedUpSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.PhoneCallDeliveryPickedUp
+}
+
+func (suite *portHandlePhoneCallDeliveryPickedUpSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.PhoneCallDeliveryPickedUp{
+		Attempt:    testutil.NewUUIDV4(),
+		PickedUpBy: testutil.NewUUIDV4(),
+		PickedUpAt: time.Date(2022, 10, 13, 14, 12, 55, 0, time.UTC),
+	}
+}
+
+func (suite *portHandlePhoneCallDeliveryPickedUpSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		EventType: event.TypePhoneCallDeliveryPickedUp,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandlePhoneCallDeliveryPickedUpSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryPickedUpSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryPickedUpSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handlePhoneCallDeliveryPickedUpSuite(t *testing.T) {
+	suite.Run(t, new(portHandlePhoneCallDeliveryPickedUpSuite))
+}
+
+// portHandlePhoneCallDeliveryReleasedSuite tests Port.handlePhoneCallDeliveryReleased.
+type portHandlePhoneCallDeliveryReleasedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.PhoneCallDeliveryReleased
+}
+
+func (suite *portHandlePhoneCallDeliveryReleasedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.PhoneCallDeliveryReleased{
+		Attempt:    testutil.NewUUIDV4(),
+		ReleasedAt: time.Date(2022, 10, 13, 14, 12, 55, 0, time.UTC),
+	}
+}
+
+func (suite *portHandlePhoneCallDeliveryReleasedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		EventType: event.TypePhoneCallDeliveryReleased,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandlePhoneCallDeliveryReleasedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryReleasedSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryReleasedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handlePhoneCallDeliveryReleasedSuite(t *testing.T) {
+	suite.Run(t, new(portHandlePhoneCallDeliveryReleasedSuite))
+}
+
+// portHandlePhoneCallDeliveryFinishedSuite tests Port.handlePhoneCallDeliveryFinished.
+type portHandlePhoneCallDeliveryFinishedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.PhoneCallDeliveryFinished
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.PhoneCallDeliveryFinished{
+		Attempt:    testutil.NewUUIDV4(),
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(time.Date(2022, 10, 13, 14, 15, 23, 0, time.UTC)),
+		Success:    true,
+		FinishedAt: time.Date(2022, 10, 13, 14, 15, 55, 0, time.UTC),
+		Note:       "instead",
+	}
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		EventType: event.TypePhoneCallDeliveryFinished,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) TestMarkAsDeliveredFail() {
+	suite.sampleEvent.Success = true
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsDeliveredTx", timeout, tx, suite.sampleEvent.Attempt, uuid.NullUUID{}).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) TestOKDelivered() {
+	suite.sampleEvent.Success = true
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsDeliveredTx", timeout, tx, suite.sampleEvent.Attempt, uuid.NullUUID{}).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) TestMarkAsFailedFail() {
+	suite.sampleEvent.Success = false
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Note)).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandlePhoneCallDeliveryFinishedSuite) TestOKFailed() {
+	suite.sampleEvent.Success = false
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Note)).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handlePhoneCallDeliveryFinishedSuite(t *testing.T) {
+	suite.Run(t, new(portHandlePhoneCallDeliveryFinishedSuite))
+}
+
+// portHandleDirectDeliveryReadyForPickupSuite tests
+// Port.handleDirectDeliveryReadyForPickup.
+type portHandleDirectDeliveryReadyForPickupSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.DirectDeliveryReadyForPickup
+}
+
+func (suite *portHandleDirectDeliveryReadyForPickupSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.DirectDeliveryReadyForPickup{
+		Attempt:                testutil.NewUUIDV4(),
+		Intel:                  testutil.NewUUIDV4(),
+		IntelOperation:         testutil.NewUUIDV4(),
+		IntelImportance:        979,
+		AttemptAssignedTo:      testutil.NewUUIDV4(),
+		AttemptAssignedToLabel: "appear",
+		Delivery:               testutil.NewUUIDV4(),
+		Channel:                testutil.NewUUIDV4(),
+		Note:                   "jaw",
+		AttemptAcceptedAt:      time.Date(2022, 10, 13, 14, 11, 40, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleDirectDeliveryReadyForPickupSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		EventType: event.TypeDirectDeliveryReadyForPickup,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleDirectDeliveryReadyForPickupSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryReadyForPickupSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryReadyForPickupSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleDirectDeliveryReadyForPickupSuite(t *testing.T) {
+	suite.Run(t, new(portHandleDirectDeliveryReadyForPickupSuite))
+}
+
+// portHandleDirectDeliveryPickedUpSuite tests Port.handleDirectDeliveryPickedUp.
+type portHandleDirectDeliveryPickedUpSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.DirectDeliveryPickedUp
+}
+
+func (suite *portHandleDirectDeliveryPickedUpSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.DirectDeliveryPickedUp{
+		Attempt:    testutil.NewUUIDV4(),
+		PickedUpBy: testutil.NewUUIDV4(),
+		PickedUpAt: time.Date(2022, 10, 13, 14, 12, 55, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleDirectDeliveryPickedUpSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		EventType: event.TypeDirectDeliveryPickedUp,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleDirectDeliveryPickedUpSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryPickedUpSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryPickedUpSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusDelivering, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleDirectDeliveryPickedUpSuite(t *testing.T) {
+	suite.Run(t, new(portHandleDirectDeliveryPickedUpSuite))
+}
+
+// portHandleDirectDeliveryReleasedSuite tests Port.handleDirectDeliveryReleased.
+type portHandleDirectDeliveryReleasedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.DirectDeliveryReleased
+}
+
+func (suite *portHandleDirectDeliveryReleasedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.DirectDeliveryReleased{
+		Attempt:    testutil.NewUUIDV4(),
+		ReleasedAt: time.Date(2022, 10, 13, 14, 12, 55, 0, time.UTC),
+	}
+}
+
+func (suite *portHandleDirectDeliveryReleasedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		EventType: event.TypeDirectDeliveryReleased,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleDirectDeliveryReleasedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryReleasedSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryReleasedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateIntelDeliveryAttemptStatusForActive", timeout, tx, suite.sampleEvent.Attempt,
+		store.IntelDeliveryStatusAwaitingDelivery, mock.Anything).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleDirectDeliveryReleasedSuite(t *testing.T) {
+	suite.Run(t, new(portHandleDirectDeliveryReleasedSuite))
+}
+
+// portHandleDirectDeliveryFinishedSuite tests Port.handleDirectDeliveryFinished.
+type portHandleDirectDeliveryFinishedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.DirectDeliveryFinished
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.DirectDeliveryFinished{
+		Attempt:    testutil.NewUUIDV4(),
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(time.Date(2022, 10, 13, 14, 15, 23, 0, time.UTC)),
+		Success:    true,
+		FinishedAt: time.Date(2022, 10, 13, 14, 15, 55, 0, time.UTC),
+		Note:       "instead",
+	}
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.DirectDeliveriesTopic,
+		EventType: event.TypeDirectDeliveryFinished,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) TestMarkAsDeliveredFail() {
+	suite.sampleEvent.Success = true
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsDeliveredTx", timeout, tx, suite.sampleEvent.Attempt, uuid.NullUUID{}).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) TestOKDelivered() {
+	suite.sampleEvent.Success = true
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsDeliveredTx", timeout, tx, suite.sampleEvent.Attempt, uuid.NullUUID{}).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) TestMarkAsFailedFail() {
+	suite.sampleEvent.Success = false
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Note)).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleDirectDeliveryFinishedSuite) TestOKFailed() {
+	suite.sampleEvent.Success = false
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("MarkIntelDeliveryAttemptAsFailed", timeout, tx, suite.sampleEvent.Attempt, nulls.NewString(suite.sampleEvent.Note)).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleDirectDeliveryFinishedSuite(t *testing.T) {
+	suite.Run(t, new(portHandleDirectDeliveryFinishedSuite))
+}
@@ -185,6 +185,7 @@ func mapForwardToGroupChannelDetails(detailsRaw store.ChannelDetails) (json.RawM
 	}
 	mappedDetails := event.AddressBookEntryForwardToGroupChannelDetails{
 		ForwardToGroup: details.ForwardToGroup,
+		Quorum:         details.Quorum,
 	}
 	mappedDetailsRaw, err := json.Marshal(mappedDetails)
 	if err != nil {
@@ -202,6 +203,7 @@ func mapForwardToUserChannelDetails(detailsRaw store.ChannelDetails) (json.RawMe
 	}
 	mappedDetails := event.AddressBookEntryForwardToUserChannelDetails{
 		ForwardToUser: details.ForwardToUser,
+		Quorum:        details.Quorum,
 	}
 	mappedDetailsRaw, err := json.Marshal(mappedDetails)
 	if err != nil {
@@ -397,15 +399,18 @@ func (p *Port) NotifyIntelCreated(ctx context.Context, tx pgx.Tx, created store.
 		Key:       created.ID.String(),
 		EventType: event.TypeIntelCreated,
 		Value: event.IntelCreated{
-			ID:         created.ID,
-			CreatedAt:  created.CreatedAt,
-			CreatedBy:  created.CreatedBy,
-			Operation:  created.Operation,
-			Type:       mappedType,
-			Content:    mappedContent,
-			SearchText: created.SearchText,
-			Importance: created.Importance,
-			IsValid:    created.IsValid,
+			ID:              created.ID,
+			CreatedAt:       created.CreatedAt,
+			CreatedBy:       created.CreatedBy,
+			Operation:       created.Operation,
+			Type:            mappedType,
+			Content:         mappedContent,
+			SearchText:      created.SearchText,
+			Importance:      created.Importance,
+			IsValid:         created.IsValid,
+			PreviousVersion: created.PreviousVersion,
+			Version:         created.Version,
+			ValidUntil:      created.ValidUntil,
 		},
 	}
 	err = p.writer.AddOutboxMessages(ctx, tx, intelCreatedMessage)
@@ -433,6 +438,47 @@ func (p *Port) NotifyIntelInvalidated(ctx context.Context, tx pgx.Tx, intelID uu
 	return nil
 }
 
+// NotifyIntelExpired notifies about intel being expired.
+func (p *Port) NotifyIntelExpired(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	intelExpiredMessage := kafkautil.OutboundMessage{
+		Topic:     event.IntelTopic,
+		Key:       intelID.String(),
+		EventType: event.TypeIntelExpired,
+		Value: event.IntelExpired{
+			ID: intelID,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, intelExpiredMessage)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": intelExpiredMessage})
+	}
+	return nil
+}
+
+// NotifyIntelAmended notifies about intel being amended by the given new
+// version.
+func (p *Port) NotifyIntelAmended(ctx context.Context, tx pgx.Tx, newVersion store.Intel) error {
+	if !newVersion.PreviousVersion.Valid {
+		return meh.NewInternalErr("new version has no previous version", meh.Details{"new_version": newVersion.ID})
+	}
+	intelAmendedMessage := kafkautil.OutboundMessage{
+		Topic:     event.IntelTopic,
+		Key:       newVersion.PreviousVersion.UUID.String(),
+		EventType: event.TypeIntelAmended,
+		Value: event.IntelAmended{
+			ID:         newVersion.PreviousVersion.UUID,
+			NewVersion: newVersion.ID,
+			Version:    newVersion.Version,
+			By:         newVersion.CreatedBy,
+		},
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, intelAmendedMessage)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": intelAmendedMessage})
+	}
+	return nil
+}
+
 // NotifyIntelDeliveryCreated emits an event.TypeIntelDeliveryCreated event.
 func (p *Port) NotifyIntelDeliveryCreated(ctx context.Context, tx pgx.Tx, created store.IntelDelivery) error {
 	message := kafkautil.OutboundMessage{
@@ -440,12 +486,13 @@ func (p *Port) NotifyIntelDeliveryCreated(ctx context.Context, tx pgx.Tx, create
 		Key:       created.ID.String(),
 		EventType: event.TypeIntelDeliveryCreated,
 		Value: event.IntelDeliveryCreated{
-			ID:       created.ID,
-			Intel:    created.Intel,
-			To:       created.To,
-			IsActive: created.IsActive,
-			Success:  created.Success,
-			Note:     created.Note,
+			ID:        created.ID,
+			Intel:     created.Intel,
+			To:        created.To,
+			IsActive:  created.IsActive,
+			Success:   created.Success,
+			Note:      created.Note,
+			DeliverAt: created.DeliverAt,
 		},
 		Headers: nil,
 	}
@@ -479,6 +526,72 @@ func (p *Port) NotifyIntelDeliveryStatusUpdated(ctx context.Context, tx pgx.Tx,
 	return nil
 }
 
+// NotifyIntelDeliveryDeliverAtUpdated emits an
+// event.TypeIntelDeliveryDeliverAtUpdated event.
+func (p *Port) NotifyIntelDeliveryDeliverAtUpdated(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	message := kafkautil.OutboundMessage{
+		Topic:     event.IntelDeliveriesTopic,
+		Key:       deliveryID.String(),
+		EventType: event.TypeIntelDeliveryDeliverAtUpdated,
+		Value: event.IntelDeliveryDeliverAtUpdated{
+			ID:        deliveryID,
+			DeliverAt: deliverAt,
+		},
+		Headers: nil,
+	}
+	err := p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// NotifyIntelDeliveryEscalated emits an event.TypeIntelDeliveryEscalated
+// event.
+func (p *Port) NotifyIntelDeliveryEscalated(ctx context.Context, tx pgx.Tx, escalation store.IntelDeliveryEscalation,
+	intel store.Intel, escalatedToEntry uuid.NullUUID) error {
+	trigger, err := eventIntelDeliveryEscalationTriggerFromStore(escalation.Trigger)
+	if err != nil {
+		return meh.Wrap(err, "event intel-delivery-escalation-trigger from store", meh.Details{"trigger": escalation.Trigger})
+	}
+	message := kafkautil.OutboundMessage{
+		Topic:     event.IntelDeliveriesTopic,
+		Key:       escalation.Delivery.String(),
+		EventType: event.TypeIntelDeliveryEscalated,
+		Value: event.IntelDeliveryEscalated{
+			ID:               escalation.ID,
+			Delivery:         escalation.Delivery,
+			Intel:            intel.ID,
+			Operation:        intel.Operation,
+			Trigger:          trigger,
+			EscalatedAt:      escalation.EscalatedAt,
+			EscalatedTo:      escalation.EscalatedTo,
+			EscalatedToEntry: escalatedToEntry,
+			NotifyManagers:   escalation.NotifiedManagers,
+		},
+		Headers: nil,
+	}
+	err = p.writer.AddOutboxMessages(ctx, tx, message)
+	if err != nil {
+		return meh.Wrap(err, "add outbox messages", meh.Details{"message": message})
+	}
+	return nil
+}
+
+// eventIntelDeliveryEscalationTriggerFromStore maps
+// store.IntelDeliveryEscalationTrigger to event.IntelDeliveryEscalationTrigger
+// and returns a meh.ErrInternal when no mapping was found.
+func eventIntelDeliveryEscalationTriggerFromStore(s store.IntelDeliveryEscalationTrigger) (event.IntelDeliveryEscalationTrigger, error) {
+	switch s {
+	case store.IntelDeliveryEscalationTriggerFailed:
+		return event.IntelDeliveryEscalationTriggerFailed, nil
+	case store.IntelDeliveryEscalationTriggerOpenTimeout:
+		return event.IntelDeliveryEscalationTriggerOpenTimeout, nil
+	default:
+		return "", meh.NewInternalErr(fmt.Sprintf("unsupported trigger: %v", s), nil)
+	}
+}
+
 // eventIntelDeliveryStatusFromStore maps store.IntelDeliveryStatus to
 // event.IntelDeliveryStaus and returns a meh.ErrInternal when no mapping was
 // found.
@@ -585,11 +698,12 @@ func (p *Port) NotifyIntelDeliveryAttemptStatusUpdated(ctx context.Context, tx p
 		Key:       attempt.Delivery.String(),
 		EventType: event.TypeIntelDeliveryAttemptStatusUpdated,
 		Value: event.IntelDeliveryAttemptStatusUpdated{
-			ID:       attempt.ID,
-			IsActive: attempt.IsActive,
-			Status:   mappedStatus,
-			StatusTS: attempt.StatusTS,
-			Note:     attempt.Note,
+			ID:             attempt.ID,
+			IsActive:       attempt.IsActive,
+			Status:         mappedStatus,
+			StatusTS:       attempt.StatusTS,
+			Note:           attempt.Note,
+			AcknowledgedBy: attempt.AcknowledgedBy,
 		},
 		Headers: nil,
 	}
@@ -655,7 +655,10 @@ func (suite *PortNotifyIntelCreatedSuite) SetupTest() {
 		Content: testutil.MarshalJSONMust(store.IntelTypePlaintextMessageContent{
 			Text: "Hello World!",
 		}),
-		SearchText: nulls.NewString("gold"),
+		SearchText:      nulls.NewString("gold"),
+		PreviousVersion: nulls.NewUUID(testutil.NewUUIDV4()),
+		Version:         2,
+		ValidUntil:      nulls.NewTime(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)),
 	}
 	suite.expectedMessages = []kafkautil.OutboundMessage{
 		{
@@ -671,8 +674,11 @@ func (suite *PortNotifyIntelCreatedSuite) SetupTest() {
 				Content: testutil.MarshalJSONMust(event.IntelTypePlaintextMessageContent{
 					Text: "Hello World!",
 				}),
-				SearchText: suite.sampleCreated.SearchText,
-				IsValid:    suite.sampleCreated.IsValid,
+				SearchText:      suite.sampleCreated.SearchText,
+				IsValid:         suite.sampleCreated.IsValid,
+				PreviousVersion: suite.sampleCreated.PreviousVersion,
+				Version:         suite.sampleCreated.Version,
+				ValidUntil:      suite.sampleCreated.ValidUntil,
 			},
 			Headers: nil,
 		},
@@ -780,6 +786,144 @@ func TestPort_NotifyIntelInvalidated(t *testing.T) {
 	suite.Run(t, new(PortNotifyIntelInvalidatedSuite))
 }
 
+// PortNotifyIntelExpiredSuite tests Port.NotifyIntelExpired.
+type PortNotifyIntelExpiredSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleID         uuid.UUID
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelExpiredSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleID = testutil.NewUUIDV4()
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.IntelTopic,
+			Key:       suite.sampleID.String(),
+			EventType: event.TypeIntelExpired,
+			Value: event.IntelExpired{
+				ID: suite.sampleID,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelExpiredSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelExpired(timeout, suite.tx, suite.sampleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelExpiredSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelExpired(timeout, suite.tx, suite.sampleID)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelExpired(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelExpiredSuite))
+}
+
+// PortNotifyIntelAmendedSuite tests Port.NotifyIntelAmended.
+type PortNotifyIntelAmendedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleNewVersion store.Intel
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelAmendedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleNewVersion = store.Intel{
+		ID:              testutil.NewUUIDV4(),
+		CreatedBy:       testutil.NewUUIDV4(),
+		Operation:       testutil.NewUUIDV4(),
+		Type:            store.IntelTypePlaintextMessage,
+		Content:         testutil.MarshalJSONMust(store.IntelTypePlaintextMessageContent{Text: "Hello World!"}),
+		IsValid:         true,
+		PreviousVersion: nulls.NewUUID(testutil.NewUUIDV4()),
+		Version:         3,
+	}
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.IntelTopic,
+			Key:       suite.sampleNewVersion.PreviousVersion.UUID.String(),
+			EventType: event.TypeIntelAmended,
+			Value: event.IntelAmended{
+				ID:         suite.sampleNewVersion.PreviousVersion.UUID,
+				NewVersion: suite.sampleNewVersion.ID,
+				Version:    suite.sampleNewVersion.Version,
+				By:         suite.sampleNewVersion.CreatedBy,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelAmendedSuite) TestNoPreviousVersion() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleNewVersion.PreviousVersion = uuid.NullUUID{}
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelAmended(timeout, suite.tx, suite.sampleNewVersion)
+		suite.Error(err, "should fail")
+		suite.Empty(suite.port.recorder.Recorded, "should not write any messages")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelAmendedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelAmended(timeout, suite.tx, suite.sampleNewVersion)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelAmendedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelAmended(timeout, suite.tx, suite.sampleNewVersion)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelAmended(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelAmendedSuite))
+}
+
 func Test_mapIntelContentFromStore(t *testing.T) {
 	testutil.TestMapperWithConstExtraction(t, func(from store.IntelType) (string, error) {
 		// Assure that the type is known.
@@ -998,14 +1142,15 @@ func (suite *PortNotifyIntelDeliveryAttemptStatusUpdatedSuite) SetupTest() {
 	suite.port = newMockPort()
 	suite.tx = &testutil.DBTx{}
 	suite.sampleUpdated = store.IntelDeliveryAttempt{
-		ID:        testutil.NewUUIDV4(),
-		Delivery:  testutil.NewUUIDV4(),
-		Channel:   testutil.NewUUIDV4(),
-		CreatedAt: time.Date(2022, 9, 1, 12, 18, 37, 0, time.UTC),
-		IsActive:  true,
-		Status:    store.IntelDeliveryStatusAwaitingAck,
-		StatusTS:  time.Date(2022, 9, 1, 12, 18, 55, 0, time.UTC),
-		Note:      nulls.NewString("variety"),
+		ID:             testutil.NewUUIDV4(),
+		Delivery:       testutil.NewUUIDV4(),
+		Channel:        testutil.NewUUIDV4(),
+		CreatedAt:      time.Date(2022, 9, 1, 12, 18, 37, 0, time.UTC),
+		IsActive:       true,
+		Status:         store.IntelDeliveryStatusAwaitingAck,
+		StatusTS:       time.Date(2022, 9, 1, 12, 18, 55, 0, time.UTC),
+		Note:           nulls.NewString("variety"),
+		AcknowledgedBy: nulls.NewUUID(testutil.NewUUIDV4()),
 	}
 	suite.expectedMessages = []kafkautil.OutboundMessage{
 		{
@@ -1013,11 +1158,12 @@ func (suite *PortNotifyIntelDeliveryAttemptStatusUpdatedSuite) SetupTest() {
 			Key:       suite.sampleUpdated.Delivery.String(),
 			EventType: event.TypeIntelDeliveryAttemptStatusUpdated,
 			Value: event.IntelDeliveryAttemptStatusUpdated{
-				ID:       suite.sampleUpdated.ID,
-				IsActive: suite.sampleUpdated.IsActive,
-				Status:   event.IntelDeliveryStatusAwaitingAck,
-				StatusTS: suite.sampleUpdated.StatusTS,
-				Note:     suite.sampleUpdated.Note,
+				ID:             suite.sampleUpdated.ID,
+				IsActive:       suite.sampleUpdated.IsActive,
+				Status:         event.IntelDeliveryStatusAwaitingAck,
+				StatusTS:       suite.sampleUpdated.StatusTS,
+				Note:           suite.sampleUpdated.Note,
+				AcknowledgedBy: suite.sampleUpdated.AcknowledgedBy,
 			},
 			Headers: nil,
 		},
@@ -1081,12 +1227,13 @@ func (suite *PortNotifyIntelDeliveryCreatedSuite) SetupTest() {
 	suite.port = newMockPort()
 	suite.tx = &testutil.DBTx{}
 	suite.sampleCreated = store.IntelDelivery{
-		ID:       testutil.NewUUIDV4(),
-		Intel:    testutil.NewUUIDV4(),
-		To:       testutil.NewUUIDV4(),
-		IsActive: false,
-		Success:  true,
-		Note:     nulls.NewString("variety"),
+		ID:        testutil.NewUUIDV4(),
+		Intel:     testutil.NewUUIDV4(),
+		To:        testutil.NewUUIDV4(),
+		IsActive:  false,
+		Success:   true,
+		Note:      nulls.NewString("variety"),
+		DeliverAt: nulls.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)),
 	}
 	suite.expectedMessages = []kafkautil.OutboundMessage{
 		{
@@ -1094,12 +1241,13 @@ func (suite *PortNotifyIntelDeliveryCreatedSuite) SetupTest() {
 			Key:       suite.sampleCreated.ID.String(),
 			EventType: event.TypeIntelDeliveryCreated,
 			Value: event.IntelDeliveryCreated{
-				ID:       suite.sampleCreated.ID,
-				Intel:    suite.sampleCreated.Intel,
-				To:       suite.sampleCreated.To,
-				IsActive: suite.sampleCreated.IsActive,
-				Success:  suite.sampleCreated.Success,
-				Note:     suite.sampleCreated.Note,
+				ID:        suite.sampleCreated.ID,
+				Intel:     suite.sampleCreated.Intel,
+				To:        suite.sampleCreated.To,
+				IsActive:  suite.sampleCreated.IsActive,
+				Success:   suite.sampleCreated.Success,
+				Note:      suite.sampleCreated.Note,
+				DeliverAt: suite.sampleCreated.DeliverAt,
 			},
 			Headers: nil,
 		},
@@ -1204,6 +1352,66 @@ func TestPort_NotifyIntelDeliveryStatusUpdated(t *testing.T) {
 	suite.Run(t, new(PortNotifyIntelDeliveryStatusUpdatedSuite))
 }
 
+// PortNotifyIntelDeliveryDeliverAtUpdatedSuite tests
+// Port.NotifyIntelDeliveryDeliverAtUpdated.
+type PortNotifyIntelDeliveryDeliverAtUpdatedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleID         uuid.UUID
+	sampleDeliverAt  nulls.Time
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelDeliveryDeliverAtUpdatedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleID = testutil.NewUUIDV4()
+	suite.sampleDeliverAt = nulls.NewTime(time.Date(2022, 9, 1, 14, 0, 0, 0, time.UTC))
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.IntelDeliveriesTopic,
+			Key:       suite.sampleID.String(),
+			EventType: event.TypeIntelDeliveryDeliverAtUpdated,
+			Value: event.IntelDeliveryDeliverAtUpdated{
+				ID:        suite.sampleID,
+				DeliverAt: suite.sampleDeliverAt,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelDeliveryDeliverAtUpdatedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryDeliverAtUpdated(timeout, suite.tx, suite.sampleID, suite.sampleDeliverAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelDeliveryDeliverAtUpdatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryDeliverAtUpdated(timeout, suite.tx, suite.sampleID, suite.sampleDeliverAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelDeliveryDeliverAtUpdated(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelDeliveryDeliverAtUpdatedSuite))
+}
+
 // PortNotifyAddressBookEntryAutoDeliveryUpdatedSuite tests
 // Port.NotifyAddressBookEntryAutoDeliveryUpdated.
 type PortNotifyAddressBookEntryAutoDeliveryUpdatedSuite struct {
@@ -1247,3 +1455,96 @@ func (suite *PortNotifyAddressBookEntryAutoDeliveryUpdatedSuite) TestOK() {
 func TestPort_NotifyAddressBookEntryAutoDeliveryUpdated(t *testing.T) {
 	suite.Run(t, new(PortNotifyAddressBookEntryAutoDeliveryUpdatedSuite))
 }
+
+// PortNotifyIntelDeliveryEscalatedSuite tests
+// Port.NotifyIntelDeliveryEscalated.
+type PortNotifyIntelDeliveryEscalatedSuite struct {
+	suite.Suite
+	port             *PortMock
+	tx               *testutil.DBTx
+	sampleEscalation store.IntelDeliveryEscalation
+	sampleIntel      store.Intel
+	sampleEntry      uuid.NullUUID
+	expectedMessages []kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyIntelDeliveryEscalatedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleEscalation = store.IntelDeliveryEscalation{
+		ID:               testutil.NewUUIDV4(),
+		Delivery:         testutil.NewUUIDV4(),
+		Rule:             nulls.NewUUID(testutil.NewUUIDV4()),
+		Trigger:          store.IntelDeliveryEscalationTriggerOpenTimeout,
+		EscalatedAt:      testutil.NewRandomTime(),
+		EscalatedTo:      nulls.NewUUID(testutil.NewUUIDV4()),
+		NotifiedManagers: true,
+	}
+	suite.sampleIntel = store.Intel{
+		ID:        testutil.NewUUIDV4(),
+		Operation: testutil.NewUUIDV4(),
+	}
+	suite.sampleEntry = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.expectedMessages = []kafkautil.OutboundMessage{
+		{
+			Topic:     event.IntelDeliveriesTopic,
+			Key:       suite.sampleEscalation.Delivery.String(),
+			EventType: event.TypeIntelDeliveryEscalated,
+			Value: event.IntelDeliveryEscalated{
+				ID:               suite.sampleEscalation.ID,
+				Delivery:         suite.sampleEscalation.Delivery,
+				Intel:            suite.sampleIntel.ID,
+				Operation:        suite.sampleIntel.Operation,
+				Trigger:          event.IntelDeliveryEscalationTriggerOpenTimeout,
+				EscalatedAt:      suite.sampleEscalation.EscalatedAt,
+				EscalatedTo:      suite.sampleEscalation.EscalatedTo,
+				EscalatedToEntry: suite.sampleEntry,
+				NotifyManagers:   true,
+			},
+			Headers: nil,
+		},
+	}
+}
+
+func (suite *PortNotifyIntelDeliveryEscalatedSuite) TestUnknownTrigger() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleEscalation.Trigger = "meow"
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryEscalated(timeout, suite.tx, suite.sampleEscalation, suite.sampleIntel, suite.sampleEntry)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelDeliveryEscalatedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryEscalated(timeout, suite.tx, suite.sampleEscalation, suite.sampleIntel, suite.sampleEntry)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyIntelDeliveryEscalatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyIntelDeliveryEscalated(timeout, suite.tx, suite.sampleEscalation, suite.sampleIntel, suite.sampleEntry)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.expectedMessages, suite.port.recorder.Recorded, "should write correct messages")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyIntelDeliveryEscalated(t *testing.T) {
+	suite.Run(t, new(PortNotifyIntelDeliveryEscalatedSuite))
+}
@@ -74,6 +74,10 @@ type AddressBookEntry struct {
 	Operation uuid.NullUUID
 	// User is the id of an optionally assigned user.
 	User uuid.NullUUID
+	// FanOutMinImportance is the minimum importance of intel for which delivery
+	// attempts are made over all usable channels in parallel instead of one after
+	// another. If not set, delivery is always sequential.
+	FanOutMinImportance nulls.Int
 }
 
 // Validate that Label is not empty.
@@ -230,7 +234,8 @@ func (m *Mall) AddressBookEntries(ctx context.Context, tx pgx.Tx, filters Addres
 			goqu.I("address_book_entries.label"),
 			goqu.I("address_book_entries.description"),
 			goqu.I("address_book_entries.operation"),
-			goqu.I("address_book_entries.user"))
+			goqu.I("address_book_entries.user"),
+			goqu.I("address_book_entries.fan_out_min_importance"))
 	whereAnd := make([]exp.Expression, 0)
 	// Apply filters.
 	if filters.ByUser.Valid {
@@ -286,6 +291,7 @@ func (m *Mall) AddressBookEntries(ctx context.Context, tx pgx.Tx, filters Addres
 			&entry.Description,
 			&entry.Operation,
 			&entry.User,
+			&entry.FanOutMinImportance,
 			&total)
 		if err != nil {
 			return pagination.Paginated[AddressBookEntryDetailed]{}, mehpg.NewScanRowsErr(err, "scan row", q)
@@ -366,7 +372,8 @@ func (m *Mall) addressBookEntryMetadataByID(ctx context.Context, tx pgx.Tx, entr
 			goqu.C("label"),
 			goqu.C("description"),
 			goqu.C("operation"),
-			goqu.C("user")).
+			goqu.C("user"),
+			goqu.C("fan_out_min_importance")).
 		Where(goqu.C("id").Eq(entryID)).ToSQL()
 	if err != nil {
 		return AddressBookEntry{}, meh.NewInternalErrFromErr(err, "entry-query to sql", nil)
@@ -384,7 +391,8 @@ func (m *Mall) addressBookEntryMetadataByID(ctx context.Context, tx pgx.Tx, entr
 		&entry.Label,
 		&entry.Description,
 		&entry.Operation,
-		&entry.User)
+		&entry.User,
+		&entry.FanOutMinImportance)
 	if err != nil {
 		return AddressBookEntry{}, mehpg.NewScanRowsErr(err, "scan entry-row", entryQuery)
 	}
@@ -424,10 +432,11 @@ func (m *Mall) isUserVisibleBy(ctx context.Context, tx pgx.Tx, userID uuid.UUID,
 func (m *Mall) CreateAddressBookEntry(ctx context.Context, tx pgx.Tx, entry AddressBookEntry) (AddressBookEntryDetailed, error) {
 	// Create.
 	q, _, err := m.dialect.Insert(goqu.T("address_book_entries")).Rows(goqu.Record{
-		"label":       entry.Label,
-		"description": entry.Description,
-		"operation":   entry.Operation,
-		"user":        entry.User,
+		"label":                  entry.Label,
+		"description":            entry.Description,
+		"operation":              entry.Operation,
+		"user":                   entry.User,
+		"fan_out_min_importance": entry.FanOutMinImportance,
 	}).Returning(goqu.C("id")).ToSQL()
 	if err != nil {
 		return AddressBookEntryDetailed{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -473,10 +482,11 @@ func (m *Mall) CreateAddressBookEntry(ctx context.Context, tx pgx.Tx, entry Addr
 // id.
 func (m *Mall) UpdateAddressBookEntry(ctx context.Context, tx pgx.Tx, entry AddressBookEntry) error {
 	q, _, err := m.dialect.Update(goqu.T("address_book_entries")).Set(goqu.Record{
-		"label":       entry.Label,
-		"description": entry.Description,
-		"operation":   entry.Operation,
-		"user":        entry.User,
+		"label":                  entry.Label,
+		"description":            entry.Description,
+		"operation":              entry.Operation,
+		"user":                   entry.User,
+		"fan_out_min_importance": entry.FanOutMinImportance,
 	}).Where(goqu.C("id").Eq(entry.ID)).ToSQL()
 	if err != nil {
 		return meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -574,6 +584,7 @@ func (m *Mall) SearchAddressBookEntries(ctx context.Context, tx pgx.Tx, filters
 			goqu.I("entries.description"),
 			goqu.I("entries.operation"),
 			goqu.I("entries.user"),
+			goqu.I("entries.fan_out_min_importance"),
 			goqu.I("users.username"),
 			goqu.I("users.first_name"),
 			goqu.I("users.last_name"),
@@ -598,6 +609,7 @@ func (m *Mall) SearchAddressBookEntries(ctx context.Context, tx pgx.Tx, filters
 			&entry.Description,
 			&entry.Operation,
 			&entry.User,
+			&entry.FanOutMinImportance,
 			&userUsername,
 			&userFirstName,
 			&userLastName,
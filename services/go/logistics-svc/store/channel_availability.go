@@ -0,0 +1,242 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/entityvalidation"
+	"sort"
+	"time"
+	// Embed timezone database as containers might not provide one.
+	_ "time/tzdata"
+)
+
+// ChannelAvailability describes when a Channel is available for delivery. If no
+// Windows are set, the channel is available all the time. Exceptions take
+// precedence over Windows.
+type ChannelAvailability struct {
+	// Timezone is the IANA timezone name, Windows are evaluated in. Usually, this
+	// is the local timezone of the operation. If empty, UTC is used.
+	Timezone string
+	// Windows are weekly recurring time ranges, the channel is available in.
+	Windows []ChannelAvailabilityWindow
+	// Exceptions are one-off time ranges that override Windows.
+	Exceptions []ChannelAvailabilityException
+}
+
+// ChannelAvailabilityWindow is a weekly recurring time range in
+// ChannelAvailability.Windows.
+type ChannelAvailabilityWindow struct {
+	// Weekday the window applies to.
+	Weekday time.Weekday
+	// From is the inclusive start of the window as wall clock time of the day.
+	From time.Duration
+	// To is the exclusive end of the window as wall clock time of the day. Windows
+	// spanning midnight must be split into two ones.
+	To time.Duration
+}
+
+// boundsOn returns the start and end of the window on the date of the given
+// time in the given location. The boundaries are built from the wall clock, so
+// that windows are correct on days with daylight saving time transitions.
+func (w ChannelAvailabilityWindow) boundsOn(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	wallClock := func(offset time.Duration) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute),
+			int(offset%time.Minute/time.Second), int(offset%time.Second), loc)
+	}
+	return wallClock(w.From), wallClock(w.To)
+}
+
+// ChannelAvailabilityException is a one-off time range in
+// ChannelAvailability.Exceptions.
+type ChannelAvailabilityException struct {
+	// From is the inclusive start of the exception.
+	From time.Time
+	// To is the exclusive end of the exception.
+	To time.Time
+	// IsAvailable describes whether the channel is available during the exception
+	// or not.
+	IsAvailable bool
+}
+
+// channelAvailabilityLookahead is the duration after which
+// ChannelAvailability.NextAvailableFrom gives up looking for the next
+// availability. As windows recur weekly, one week is sufficient for them.
+const channelAvailabilityLookahead = 8 * 24 * time.Hour
+
+// Validate the timezone as well as Windows and Exceptions.
+func (a ChannelAvailability) Validate() (entityvalidation.Report, error) {
+	report := entityvalidation.NewReport()
+	if _, err := time.LoadLocation(a.Timezone); err != nil {
+		report.AddError(fmt.Sprintf("unknown timezone: %v", a.Timezone))
+	}
+	for i, window := range a.Windows {
+		if window.Weekday < time.Sunday || window.Weekday > time.Saturday {
+			report.AddError(fmt.Sprintf("window %d has invalid weekday: %d", i, window.Weekday))
+		}
+		if window.From < 0 || window.To > 24*time.Hour {
+			report.AddError(fmt.Sprintf("window %d must be within the day", i))
+		}
+		if window.From >= window.To {
+			report.AddError(fmt.Sprintf("window %d must start before it ends", i))
+		}
+	}
+	for i, exception := range a.Exceptions {
+		if !exception.From.Before(exception.To) {
+			report.AddError(fmt.Sprintf("exception %d must start before it ends", i))
+		}
+	}
+	return report, nil
+}
+
+// location returns the time.Location for ChannelAvailability.Timezone.
+func (a ChannelAvailability) location() (*time.Location, error) {
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "load location", meh.Details{"timezone": a.Timezone})
+	}
+	return loc, nil
+}
+
+// IsAvailableAt checks whether the channel is available at the given time.
+// Unavailable exceptions take precedence over available ones.
+func (a ChannelAvailability) IsAvailableAt(t time.Time) (bool, error) {
+	isExceptionallyAvailable := false
+	for _, exception := range a.Exceptions {
+		if t.Before(exception.From) || !t.Before(exception.To) {
+			continue
+		}
+		if !exception.IsAvailable {
+			return false, nil
+		}
+		isExceptionallyAvailable = true
+	}
+	if isExceptionallyAvailable || len(a.Windows) == 0 {
+		return true, nil
+	}
+	loc, err := a.location()
+	if err != nil {
+		return false, meh.Wrap(err, "location", nil)
+	}
+	local := t.In(loc)
+	for _, window := range a.Windows {
+		if window.Weekday != local.Weekday() {
+			continue
+		}
+		from, to := window.boundsOn(local, loc)
+		if !local.Before(from) && local.Before(to) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NextAvailableFrom returns the earliest time, not before the given one, at
+// which the channel is available. If the channel does not become available
+// within channelAvailabilityLookahead, false is returned.
+func (a ChannelAvailability) NextAvailableFrom(t time.Time) (time.Time, bool, error) {
+	loc, err := a.location()
+	if err != nil {
+		return time.Time{}, false, meh.Wrap(err, "location", nil)
+	}
+	// Availability can only change at the start of windows or at the boundaries of
+	// exceptions, so we only need to check these.
+	candidates := []time.Time{t}
+	local := t.In(loc)
+	for day := 0; day <= int(channelAvailabilityLookahead/(24*time.Hour)); day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, loc)
+		for _, window := range a.Windows {
+			if window.Weekday == date.Weekday() {
+				from, _ := window.boundsOn(date, loc)
+				candidates = append(candidates, from)
+			}
+		}
+	}
+	for _, exception := range a.Exceptions {
+		candidates = append(candidates, exception.From, exception.To)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+	until := t.Add(channelAvailabilityLookahead)
+	for _, candidate := range candidates {
+		if candidate.Before(t) || candidate.After(until) {
+			continue
+		}
+		isAvailable, err := a.IsAvailableAt(candidate)
+		if err != nil {
+			return time.Time{}, false, meh.Wrap(err, "is available at", meh.Details{"at": candidate})
+		}
+		if isAvailable {
+			return candidate, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// channelAvailabilityRecord is the representation of ChannelAvailability, being
+// persisted as JSON.
+type channelAvailabilityRecord struct {
+	Timezone   string                               `json:"timezone"`
+	Windows    []channelAvailabilityWindowRecord    `json:"windows"`
+	Exceptions []channelAvailabilityExceptionRecord `json:"exceptions"`
+}
+
+// channelAvailabilityWindowRecord is the record for ChannelAvailabilityWindow.
+type channelAvailabilityWindowRecord struct {
+	Weekday time.Weekday  `json:"weekday"`
+	From    time.Duration `json:"from"`
+	To      time.Duration `json:"to"`
+}
+
+// channelAvailabilityExceptionRecord is the record for
+// ChannelAvailabilityException.
+type channelAvailabilityExceptionRecord struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	IsAvailable bool      `json:"is_available"`
+}
+
+// marshalChannelAvailability marshals the given ChannelAvailability for
+// persisting.
+func marshalChannelAvailability(a ChannelAvailability) ([]byte, error) {
+	record := channelAvailabilityRecord{
+		Timezone:   a.Timezone,
+		Windows:    make([]channelAvailabilityWindowRecord, 0, len(a.Windows)),
+		Exceptions: make([]channelAvailabilityExceptionRecord, 0, len(a.Exceptions)),
+	}
+	for _, window := range a.Windows {
+		record.Windows = append(record.Windows, channelAvailabilityWindowRecord(window))
+	}
+	for _, exception := range a.Exceptions {
+		record.Exceptions = append(record.Exceptions, channelAvailabilityExceptionRecord(exception))
+	}
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "marshal channel availability record", nil)
+	}
+	return raw, nil
+}
+
+// unmarshalChannelAvailability unmarshals the given raw ChannelAvailability,
+// being persisted using marshalChannelAvailability.
+func unmarshalChannelAvailability(raw []byte) (ChannelAvailability, error) {
+	var record channelAvailabilityRecord
+	err := json.Unmarshal(raw, &record)
+	if err != nil {
+		return ChannelAvailability{}, meh.NewInternalErrFromErr(err, "unmarshal channel availability record",
+			meh.Details{"raw": string(raw)})
+	}
+	a := ChannelAvailability{
+		Timezone:   record.Timezone,
+		Windows:    make([]ChannelAvailabilityWindow, 0, len(record.Windows)),
+		Exceptions: make([]ChannelAvailabilityException, 0, len(record.Exceptions)),
+	}
+	for _, window := range record.Windows {
+		a.Windows = append(a.Windows, ChannelAvailabilityWindow(window))
+	}
+	for _, exception := range record.Exceptions {
+		a.Exceptions = append(a.Exceptions, ChannelAvailabilityException(exception))
+	}
+	return a, nil
+}
@@ -0,0 +1,261 @@
+package store
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// ChannelAvailabilityValidateSuite tests ChannelAvailability.Validate.
+type ChannelAvailabilityValidateSuite struct {
+	suite.Suite
+	ok ChannelAvailability
+}
+
+func (suite *ChannelAvailabilityValidateSuite) SetupTest() {
+	suite.ok = ChannelAvailability{
+		Timezone: "Europe/Berlin",
+		Windows: []ChannelAvailabilityWindow{
+			{
+				Weekday: time.Monday,
+				From:    6 * time.Hour,
+				To:      18 * time.Hour,
+			},
+		},
+		Exceptions: []ChannelAvailabilityException{
+			{
+				From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+				To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+				IsAvailable: false,
+			},
+		},
+	}
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestUnknownTimezone() {
+	suite.ok.Timezone = "Middle/Earth"
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestInvalidWeekday() {
+	suite.ok.Windows[0].Weekday = 7
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestWindowExceedsDay() {
+	suite.ok.Windows[0].To = 25 * time.Hour
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestWindowEndsBeforeStart() {
+	suite.ok.Windows[0].From = 18 * time.Hour
+	suite.ok.Windows[0].To = 6 * time.Hour
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestExceptionEndsBeforeStart() {
+	suite.ok.Exceptions[0].To = suite.ok.Exceptions[0].From
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestEmpty() {
+	report, err := ChannelAvailability{}.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.True(report.IsOK(), "report should be ok")
+}
+
+func (suite *ChannelAvailabilityValidateSuite) TestOK() {
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.True(report.IsOK(), "report should be ok")
+}
+
+func TestChannelAvailability_Validate(t *testing.T) {
+	suite.Run(t, new(ChannelAvailabilityValidateSuite))
+}
+
+// ChannelAvailabilityIsAvailableAtSuite tests
+// ChannelAvailability.IsAvailableAt and ChannelAvailability.NextAvailableFrom.
+type ChannelAvailabilityIsAvailableAtSuite struct {
+	suite.Suite
+	loc          *time.Location
+	availability ChannelAvailability
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) SetupTest() {
+	var err error
+	suite.loc, err = time.LoadLocation("Europe/Berlin")
+	suite.Require().NoError(err, "load location should not fail")
+	// Available on weekdays from 06:00 to 18:00, except for Christmas.
+	suite.availability = ChannelAvailability{
+		Timezone: "Europe/Berlin",
+		Exceptions: []ChannelAvailabilityException{
+			{
+				From:        time.Date(2022, 12, 24, 0, 0, 0, 0, suite.loc),
+				To:          time.Date(2022, 12, 27, 0, 0, 0, 0, suite.loc),
+				IsAvailable: false,
+			},
+		},
+	}
+	for weekday := time.Monday; weekday <= time.Friday; weekday++ {
+		suite.availability.Windows = append(suite.availability.Windows, ChannelAvailabilityWindow{
+			Weekday: weekday,
+			From:    6 * time.Hour,
+			To:      18 * time.Hour,
+		})
+	}
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) isAvailableAt(t time.Time) bool {
+	isAvailable, err := suite.availability.IsAvailableAt(t)
+	suite.Require().NoError(err, "should not fail")
+	return isAvailable
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestWithoutWindows() {
+	suite.availability.Windows = nil
+	suite.True(suite.isAvailableAt(time.Date(2022, 12, 20, 3, 0, 0, 0, suite.loc)), "should be available")
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 25, 3, 0, 0, 0, suite.loc)), "should respect exceptions")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestWithinWindow() {
+	suite.True(suite.isAvailableAt(time.Date(2022, 12, 20, 6, 0, 0, 0, suite.loc)), "should be available at start")
+	suite.True(suite.isAvailableAt(time.Date(2022, 12, 20, 17, 59, 0, 0, suite.loc)), "should be available before end")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestWindowInOtherTimezone() {
+	// 05:30 UTC is 06:30 in Berlin during winter.
+	suite.True(suite.isAvailableAt(time.Date(2022, 12, 20, 5, 30, 0, 0, time.UTC)), "should respect timezone")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestOutsideWindow() {
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 20, 5, 59, 0, 0, suite.loc)), "should not be available before start")
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 20, 18, 0, 0, 0, suite.loc)), "should not be available at end")
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 18, 12, 0, 0, 0, suite.loc)), "should not be available on sunday")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestWindowOnDaylightSavingTimeTransitions() {
+	suite.availability.Windows = append(suite.availability.Windows, ChannelAvailabilityWindow{
+		Weekday: time.Sunday,
+		From:    6 * time.Hour,
+		To:      18 * time.Hour,
+	})
+	// Clocks are set forward on 2023-03-26 and back on 2022-10-30.
+	for _, day := range []time.Time{
+		time.Date(2023, 3, 26, 0, 0, 0, 0, suite.loc),
+		time.Date(2022, 10, 30, 0, 0, 0, 0, suite.loc),
+	} {
+		y, m, d := day.Date()
+		suite.False(suite.isAvailableAt(time.Date(y, m, d, 5, 59, 0, 0, suite.loc)), "should not be available before start on %v", day)
+		suite.True(suite.isAvailableAt(time.Date(y, m, d, 6, 0, 0, 0, suite.loc)), "should be available at start on %v", day)
+		suite.True(suite.isAvailableAt(time.Date(y, m, d, 17, 59, 0, 0, suite.loc)), "should be available before end on %v", day)
+		suite.False(suite.isAvailableAt(time.Date(y, m, d, 18, 0, 0, 0, suite.loc)), "should not be available at end on %v", day)
+		next, ok, err := suite.availability.NextAvailableFrom(day)
+		suite.Require().NoError(err, "should not fail")
+		suite.Require().True(ok, "should be available")
+		suite.True(time.Date(y, m, d, 6, 0, 0, 0, suite.loc).Equal(next), "should be available at start on %v", day)
+	}
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestUnavailableException() {
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 26, 12, 0, 0, 0, suite.loc)), "should not be available")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestAvailableException() {
+	suite.availability.Exceptions = append(suite.availability.Exceptions, ChannelAvailabilityException{
+		From:        time.Date(2022, 12, 17, 8, 0, 0, 0, suite.loc),
+		To:          time.Date(2022, 12, 17, 12, 0, 0, 0, suite.loc),
+		IsAvailable: true,
+	})
+	suite.True(suite.isAvailableAt(time.Date(2022, 12, 17, 10, 0, 0, 0, suite.loc)), "should be available")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestUnavailableExceptionPrecedence() {
+	suite.availability.Exceptions = append(suite.availability.Exceptions, ChannelAvailabilityException{
+		From:        time.Date(2022, 12, 26, 8, 0, 0, 0, suite.loc),
+		To:          time.Date(2022, 12, 26, 12, 0, 0, 0, suite.loc),
+		IsAvailable: true,
+	})
+	suite.False(suite.isAvailableAt(time.Date(2022, 12, 26, 10, 0, 0, 0, suite.loc)), "should not be available")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestNextAvailableFromNow() {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, suite.loc)
+	next, ok, err := suite.availability.NextAvailableFrom(now)
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should be available")
+	suite.Equal(now, next, "should be available now")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestNextAvailableFromNextWindow() {
+	next, ok, err := suite.availability.NextAvailableFrom(time.Date(2022, 12, 16, 20, 0, 0, 0, suite.loc))
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should be available")
+	suite.True(time.Date(2022, 12, 19, 6, 0, 0, 0, suite.loc).Equal(next), "should be available on monday morning")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestNextAvailableFromAfterException() {
+	next, ok, err := suite.availability.NextAvailableFrom(time.Date(2022, 12, 24, 0, 0, 0, 0, suite.loc))
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should be available")
+	suite.True(time.Date(2022, 12, 27, 6, 0, 0, 0, suite.loc).Equal(next), "should be available after exception")
+}
+
+func (suite *ChannelAvailabilityIsAvailableAtSuite) TestNextAvailableFromNever() {
+	suite.availability.Exceptions = []ChannelAvailabilityException{
+		{
+			From:        time.Date(2022, 1, 1, 0, 0, 0, 0, suite.loc),
+			To:          time.Date(2023, 1, 1, 0, 0, 0, 0, suite.loc),
+			IsAvailable: false,
+		},
+	}
+	_, ok, err := suite.availability.NextAvailableFrom(time.Date(2022, 12, 1, 0, 0, 0, 0, suite.loc))
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not be available within lookahead")
+}
+
+func TestChannelAvailability_IsAvailableAt(t *testing.T) {
+	suite.Run(t, new(ChannelAvailabilityIsAvailableAtSuite))
+}
+
+func TestChannelAvailabilityMarshalRoundTrip(t *testing.T) {
+	availability := ChannelAvailability{
+		Timezone: "Europe/Berlin",
+		Windows: []ChannelAvailabilityWindow{
+			{
+				Weekday: time.Monday,
+				From:    6 * time.Hour,
+				To:      18 * time.Hour,
+			},
+		},
+		Exceptions: []ChannelAvailabilityException{
+			{
+				From:        time.Date(2022, 12, 24, 0, 0, 0, 0, time.UTC),
+				To:          time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC),
+				IsAvailable: true,
+			},
+		},
+	}
+	raw, err := marshalChannelAvailability(availability)
+	require.NoError(t, err, "marshal should not fail")
+	got, err := unmarshalChannelAvailability(raw)
+	require.NoError(t, err, "unmarshal should not fail")
+	assert.Equal(t, availability, got, "should be equal after round trip")
+}
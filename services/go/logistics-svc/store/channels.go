@@ -44,6 +44,59 @@ type Channel struct {
 	Details ChannelDetails
 	// Timeout is the timeout when delivery over this channel timed out.
 	Timeout time.Duration
+	// RetryPolicy describes whether and how delivery over this channel is retried.
+	RetryPolicy ChannelRetryPolicy
+	// PresencePolicy describes how the presence of the user, associated with the
+	// entry, is respected when choosing channels for delivery.
+	PresencePolicy ChannelPresencePolicy
+	// Availability describes when the channel is available for delivery.
+	Availability ChannelAvailability
+}
+
+// ChannelPresencePolicy describes how the presence of the user, associated with
+// the address book entry of a Channel, is respected when choosing channels for
+// delivery. Only in-app-notification channels support policies other than
+// ChannelPresencePolicyIgnore.
+type ChannelPresencePolicy string
+
+const (
+	// ChannelPresencePolicyIgnore uses the channel by its priority, regardless of
+	// the user being online or not.
+	ChannelPresencePolicyIgnore ChannelPresencePolicy = "ignore"
+	// ChannelPresencePolicyPreferOnline uses the channel before all others, if the
+	// user is online. Otherwise, it is used by its priority.
+	ChannelPresencePolicyPreferOnline ChannelPresencePolicy = "prefer-online"
+	// ChannelPresencePolicyDeprioritizeOffline uses the channel before all others,
+	// if the user is online. Otherwise, it is used after all others.
+	ChannelPresencePolicyDeprioritizeOffline ChannelPresencePolicy = "deprioritize-offline"
+	// ChannelPresencePolicySkipOffline uses the channel before all others, if the
+	// user is online. Otherwise, it is not used at all.
+	ChannelPresencePolicySkipOffline ChannelPresencePolicy = "skip-offline"
+)
+
+// ChannelRetryPolicy describes whether and how delivery attempts over a Channel
+// are retried, before lower-priority channels are used.
+type ChannelRetryPolicy struct {
+	// MaxAttempts is the maximum number of delivery attempts over the channel for
+	// a single delivery. A value of 1 disables retries.
+	MaxAttempts int32
+	// Backoff is the duration to wait after the last attempt ended, before
+	// retrying.
+	Backoff time.Duration
+	// RetryOnTimeout describes whether to retry after an attempt timed out.
+	RetryOnTimeout bool
+	// RetryOnFailure describes whether to retry after an attempt explicitly
+	// failed.
+	RetryOnFailure bool
+}
+
+// DefaultChannelRetryPolicy is the ChannelRetryPolicy used, if none is
+// specified. It does not retry at all.
+var DefaultChannelRetryPolicy = ChannelRetryPolicy{
+	MaxAttempts:    1,
+	Backoff:        0,
+	RetryOnTimeout: false,
+	RetryOnFailure: false,
 }
 
 // Validate that Type is known as well as Details.
@@ -63,6 +116,29 @@ func (c Channel) Validate() (entityvalidation.Report, error) {
 	if c.Timeout <= 0 {
 		report.AddError("timeout must be greater zero")
 	}
+	// Validate retry policy.
+	if c.RetryPolicy.MaxAttempts < 1 {
+		report.AddError("retry policy max attempts must be at least 1")
+	}
+	if c.RetryPolicy.Backoff < 0 {
+		report.AddError("retry policy backoff must not be negative")
+	}
+	// Validate presence policy.
+	switch c.PresencePolicy {
+	case ChannelPresencePolicyIgnore:
+	case ChannelPresencePolicyPreferOnline, ChannelPresencePolicyDeprioritizeOffline, ChannelPresencePolicySkipOffline:
+		if c.Type != ChannelTypeInAppNotification {
+			report.AddError(fmt.Sprintf("presence policy %v is only supported for in-app-notification channels", c.PresencePolicy))
+		}
+	default:
+		report.AddError(fmt.Sprintf("unknown presence policy: %v", c.PresencePolicy))
+	}
+	// Validate availability.
+	availabilityReport, err := c.Availability.Validate()
+	if err != nil {
+		return entityvalidation.Report{}, meh.Wrap(err, "validate availability", nil)
+	}
+	report.Include(availabilityReport)
 	return report, nil
 }
 
@@ -144,7 +220,13 @@ func (m *Mall) channelMetadataByAddressBookEntry(ctx context.Context, tx pgx.Tx,
 			goqu.C("type"),
 			goqu.C("priority"),
 			goqu.C("min_importance"),
-			goqu.C("timeout")).
+			goqu.C("timeout"),
+			goqu.C("retry_max_attempts"),
+			goqu.C("retry_backoff"),
+			goqu.C("retry_on_timeout"),
+			goqu.C("retry_on_failure"),
+			goqu.C("presence_policy"),
+			goqu.C("availability")).
 		Where(goqu.C("entry").Eq(entryID)).ToSQL()
 	if err != nil {
 		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -157,6 +239,7 @@ func (m *Mall) channelMetadataByAddressBookEntry(ctx context.Context, tx pgx.Tx,
 	channels := make([]Channel, 0)
 	for rows.Next() {
 		var channel Channel
+		var availabilityRaw []byte
 		err = rows.Scan(&channel.ID,
 			&channel.Entry,
 			&channel.IsActive,
@@ -164,10 +247,20 @@ func (m *Mall) channelMetadataByAddressBookEntry(ctx context.Context, tx pgx.Tx,
 			&channel.Type,
 			&channel.Priority,
 			&channel.MinImportance,
-			&channel.Timeout)
+			&channel.Timeout,
+			&channel.RetryPolicy.MaxAttempts,
+			&channel.RetryPolicy.Backoff,
+			&channel.RetryPolicy.RetryOnTimeout,
+			&channel.RetryPolicy.RetryOnFailure,
+			&channel.PresencePolicy,
+			&availabilityRaw)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
+		channel.Availability, err = unmarshalChannelAvailability(availabilityRaw)
+		if err != nil {
+			return nil, meh.Wrap(err, "unmarshal channel availability", meh.Details{"channel_id": channel.ID})
+		}
 		channels = append(channels, channel)
 	}
 	return channels, nil
@@ -236,15 +329,25 @@ func (m *Mall) DeleteChannelWithDetailsByID(ctx context.Context, tx pgx.Tx, chan
 //
 // Warning: No entry existence checks are performed!
 func (m *Mall) CreateChannelWithDetails(ctx context.Context, tx pgx.Tx, channel Channel) error {
+	availabilityRaw, err := marshalChannelAvailability(channel.Availability)
+	if err != nil {
+		return meh.Wrap(err, "marshal channel availability", nil)
+	}
 	// Create channel itself.
 	q, _, err := m.dialect.Insert(goqu.T("channels")).Rows(goqu.Record{
-		"entry":          channel.Entry,
-		"is_active":      channel.IsActive,
-		"label":          channel.Label,
-		"type":           channel.Type,
-		"priority":       channel.Priority,
-		"min_importance": channel.MinImportance,
-		"timeout":        channel.Timeout,
+		"entry":              channel.Entry,
+		"is_active":          channel.IsActive,
+		"label":              channel.Label,
+		"type":               channel.Type,
+		"priority":           channel.Priority,
+		"min_importance":     channel.MinImportance,
+		"timeout":            channel.Timeout,
+		"retry_max_attempts": channel.RetryPolicy.MaxAttempts,
+		"retry_backoff":      channel.RetryPolicy.Backoff,
+		"retry_on_timeout":   channel.RetryPolicy.RetryOnTimeout,
+		"retry_on_failure":   channel.RetryPolicy.RetryOnFailure,
+		"presence_policy":    channel.PresencePolicy,
+		"availability":       availabilityRaw,
 	}).Returning(goqu.C("id")).ToSQL()
 	if err != nil {
 		return meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -314,7 +417,13 @@ func (m *Mall) ChannelMetadataByID(ctx context.Context, tx pgx.Tx, channelID uui
 			goqu.C("type"),
 			goqu.C("priority"),
 			goqu.C("min_importance"),
-			goqu.C("timeout")).
+			goqu.C("timeout"),
+			goqu.C("retry_max_attempts"),
+			goqu.C("retry_backoff"),
+			goqu.C("retry_on_timeout"),
+			goqu.C("retry_on_failure"),
+			goqu.C("presence_policy"),
+			goqu.C("availability")).
 		Where(goqu.C("id").Eq(channelID)).ToSQL()
 	if err != nil {
 		return Channel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -325,6 +434,7 @@ func (m *Mall) ChannelMetadataByID(ctx context.Context, tx pgx.Tx, channelID uui
 	}
 	defer rows.Close()
 	var channel Channel
+	var availabilityRaw []byte
 	if !rows.Next() {
 		return Channel{}, meh.NewNotFoundErr("not found", nil)
 	}
@@ -335,9 +445,19 @@ func (m *Mall) ChannelMetadataByID(ctx context.Context, tx pgx.Tx, channelID uui
 		&channel.Type,
 		&channel.Priority,
 		&channel.MinImportance,
-		&channel.Timeout)
+		&channel.Timeout,
+		&channel.RetryPolicy.MaxAttempts,
+		&channel.RetryPolicy.Backoff,
+		&channel.RetryPolicy.RetryOnTimeout,
+		&channel.RetryPolicy.RetryOnFailure,
+		&channel.PresencePolicy,
+		&availabilityRaw)
 	if err != nil {
 		return Channel{}, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
+	channel.Availability, err = unmarshalChannelAvailability(availabilityRaw)
+	if err != nil {
+		return Channel{}, meh.Wrap(err, "unmarshal channel availability", meh.Details{"channel_id": channel.ID})
+	}
 	return channel, nil
 }
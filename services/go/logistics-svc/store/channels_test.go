@@ -30,14 +30,16 @@ type ChannelValidateSuite struct {
 func (suite *ChannelValidateSuite) SetupTest() {
 	suite.details = &channelDetailsMock{}
 	suite.ok = Channel{
-		ID:            testutil.NewUUIDV4(),
-		Entry:         testutil.NewUUIDV4(),
-		Label:         "", // Can be empty.
-		Type:          ChannelTypeDirect,
-		Priority:      12,
-		MinImportance: 24,
-		Details:       suite.details,
-		Timeout:       10 * time.Minute,
+		ID:             testutil.NewUUIDV4(),
+		Entry:          testutil.NewUUIDV4(),
+		Label:          "", // Can be empty.
+		Type:           ChannelTypeDirect,
+		Priority:       12,
+		MinImportance:  24,
+		Details:        suite.details,
+		Timeout:        10 * time.Minute,
+		RetryPolicy:    DefaultChannelRetryPolicy,
+		PresencePolicy: ChannelPresencePolicyIgnore,
 	}
 }
 
@@ -90,6 +92,67 @@ func (suite *ChannelValidateSuite) TestNegativeTimeout() {
 	suite.False(report.IsOK(), "report should not be ok")
 }
 
+func (suite *ChannelValidateSuite) TestMissingRetryMaxAttempts() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.RetryPolicy.MaxAttempts = 0
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelValidateSuite) TestNegativeRetryBackoff() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.RetryPolicy.Backoff = -time.Second
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelValidateSuite) TestUnknownPresencePolicy() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.PresencePolicy = "bm2qT8wL"
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelValidateSuite) TestPresencePolicyForUnsupportedType() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.PresencePolicy = ChannelPresencePolicySkipOffline
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *ChannelValidateSuite) TestPresencePolicyForInAppNotification() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.Type = ChannelTypeInAppNotification
+	suite.ok.PresencePolicy = ChannelPresencePolicySkipOffline
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.True(report.IsOK(), "report should be ok")
+}
+
+func (suite *ChannelValidateSuite) TestInvalidAvailability() {
+	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
+	defer suite.details.AssertExpectations(suite.T())
+	suite.ok.Availability.Timezone = "Middle/Earth"
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
 func (suite *ChannelValidateSuite) TestOK() {
 	suite.details.On("Validate").Return(entityvalidation.NewReport(), nil)
 	defer suite.details.AssertExpectations(suite.T())
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+)
+
+// setForwardChannelQuorumByChannel sets the quorum for the forward-channel with
+// the given id. Any existing one is replaced.
+func (m *Mall) setForwardChannelQuorumByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID, quorum int32) error {
+	err := m.deleteForwardChannelQuorumByChannel(ctx, tx, channelID)
+	if err != nil {
+		return meh.Wrap(err, "delete forward-channel-quorum by channel", meh.Details{"channel_id": channelID})
+	}
+	q, _, err := m.dialect.Insert(goqu.T("forward_channel_quorums")).Rows(goqu.Record{
+		"channel": channelID,
+		"quorum":  quorum,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// deleteForwardChannelQuorumByChannel deletes the quorum for the forward-channel
+// with the given id if one is set.
+func (m *Mall) deleteForwardChannelQuorumByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("forward_channel_quorums")).
+		Where(goqu.C("channel").Eq(channelID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// ForwardChannelQuorumByChannel retrieves the quorum for the forward-channel
+// with the given id. If none is set, zero is returned.
+func (m *Mall) ForwardChannelQuorumByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (int32, error) {
+	q, _, err := m.dialect.From(goqu.T("forward_channel_quorums")).
+		Select(goqu.C("quorum")).
+		Where(goqu.C("channel").Eq(channelID)).ToSQL()
+	if err != nil {
+		return 0, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return 0, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, nil
+	}
+	var quorum int32
+	err = rows.Scan(&quorum)
+	if err != nil {
+		return 0, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	return quorum, nil
+}
+
+// ForwardTargetAddressBookEntriesByChannel retrieves the ids of all address book
+// entries, intel-deliveries over the forward-channel with the given id should
+// be forwarded to. These are the entries of the users from
+// ChannelTypeForwardToUser as well as the ones of members of groups from
+// ChannelTypeForwardToGroup. Invalidated entries are skipped.
+func (m *Mall) ForwardTargetAddressBookEntriesByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) ([]uuid.UUID, error) {
+	forwardToUsers := m.dialect.From(goqu.T("forward_to_user_channel_entries")).
+		Select(goqu.C("forward_to_user").As("user")).
+		Where(goqu.C("channel").Eq(channelID))
+	forwardToGroupMembers := m.dialect.From(goqu.T("forward_to_group_channel_entries")).
+		InnerJoin(goqu.T("group_members"),
+			goqu.On(goqu.I("group_members.group").Eq(goqu.I("forward_to_group_channel_entries.forward_to_group")))).
+		Select(goqu.I("group_members.user").As("user")).
+		Where(goqu.I("forward_to_group_channel_entries.channel").Eq(channelID))
+	q, _, err := m.dialect.From(goqu.T("address_book_entries")).
+		Select(goqu.DISTINCT(goqu.C("id"))).
+		Where(goqu.C("user").In(forwardToUsers.Union(forwardToGroupMembers)),
+			goqu.C("is_invalidated").IsFalse()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	entries := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var entryID uuid.UUID
+		err = rows.Scan(&entryID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		entries = append(entries, entryID)
+	}
+	rows.Close()
+	return entries, nil
+}
@@ -19,9 +19,14 @@ const ChannelTypeForwardToGroup ChannelType = "forward-to-group"
 type ForwardToGroupChannelDetails struct {
 	// ForwardToGroup is the id of the group that should be forwarded to.
 	ForwardToGroup []uuid.UUID
+	// Quorum is the number of forwarded deliveries that need to succeed in order
+	// for the delivery attempt to be considered delivered. Values below one are
+	// treated as one.
+	Quorum int32
 }
 
-// Validate assures no duplicate group ids in ForwardToGroup.
+// Validate assures no duplicate group ids in ForwardToGroup and a non-negative
+// Quorum.
 func (d ForwardToGroupChannelDetails) Validate() (entityvalidation.Report, error) {
 	report := entityvalidation.NewReport()
 	forwardToGroupMap := map[uuid.UUID]struct{}{}
@@ -32,6 +37,9 @@ func (d ForwardToGroupChannelDetails) Validate() (entityvalidation.Report, error
 		}
 		forwardToGroupMap[groupID] = struct{}{}
 	}
+	if d.Quorum < 0 {
+		report.AddError("quorum must not be negative")
+	}
 	return report, nil
 }
 
@@ -42,6 +50,10 @@ type forwardToGroupChannelOperator struct {
 }
 
 func (op *forwardToGroupChannelOperator) deleteDetailsByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) error {
+	err := op.m.deleteForwardChannelQuorumByChannel(ctx, tx, channelID)
+	if err != nil {
+		return meh.Wrap(err, "delete forward-channel-quorum by channel", meh.Details{"channel_id": channelID})
+	}
 	q, _, err := op.m.dialect.Delete(goqu.T("forward_to_group_channel_entries")).
 		Where(goqu.C("channel").Eq(channelID)).ToSQL()
 	if err != nil {
@@ -69,6 +81,10 @@ func (op *forwardToGroupChannelOperator) setChannelDetailsByChannel(ctx context.
 		return meh.Wrap(err, "delete details by channel", nil)
 	}
 	// Insert.
+	err = op.m.setForwardChannelQuorumByChannel(ctx, tx, channelID, details.Quorum)
+	if err != nil {
+		return meh.Wrap(err, "set forward-channel-quorum by channel", meh.Details{"quorum": details.Quorum})
+	}
 	records := make([]any, 0, len(details.ForwardToGroup))
 	for _, groupID := range details.ForwardToGroup {
 		records = append(records, goqu.Record{
@@ -94,6 +110,7 @@ func (op *forwardToGroupChannelOperator) setChannelDetailsByChannel(ctx context.
 func (op *forwardToGroupChannelOperator) getChannelDetailsByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (ChannelDetails, error) {
 	// Build query.
 	q, _, err := op.m.dialect.From(goqu.T("forward_to_group_channel_entries")).
+		Select(goqu.C("forward_to_group")).
 		Where(goqu.C("channel").Eq(channelID)).ToSQL()
 	if err != nil {
 		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -116,6 +133,11 @@ func (op *forwardToGroupChannelOperator) getChannelDetailsByChannel(ctx context.
 		}
 		details.ForwardToGroup = append(details.ForwardToGroup, groupID)
 	}
+	rows.Close()
+	details.Quorum, err = op.m.ForwardChannelQuorumByChannel(ctx, tx, channelID)
+	if err != nil {
+		return nil, meh.Wrap(err, "forward-channel-quorum by channel", nil)
+	}
 	return details, nil
 }
 
@@ -21,9 +21,14 @@ const ChannelTypeForwardToUser ChannelType = "forward-to-user"
 type ForwardToUserChannelDetails struct {
 	// ForwardToUser is the id of the user that should be forwarded to.
 	ForwardToUser []uuid.UUID
+	// Quorum is the number of forwarded deliveries that need to succeed in order
+	// for the delivery attempt to be considered delivered. Values below one are
+	// treated as one.
+	Quorum int32
 }
 
-// Validate assures no duplicate user ids in ForwardToUser.
+// Validate assures no duplicate user ids in ForwardToUser and a non-negative
+// Quorum.
 func (d ForwardToUserChannelDetails) Validate() (entityvalidation.Report, error) {
 	report := entityvalidation.NewReport()
 	forwardToUserMap := map[uuid.UUID]struct{}{}
@@ -34,6 +39,9 @@ func (d ForwardToUserChannelDetails) Validate() (entityvalidation.Report, error)
 		}
 		forwardToUserMap[userID] = struct{}{}
 	}
+	if d.Quorum < 0 {
+		report.AddError("quorum must not be negative")
+	}
 	return report, nil
 }
 
@@ -44,6 +52,10 @@ type forwardToUserChannelOperator struct {
 }
 
 func (op *forwardToUserChannelOperator) deleteDetailsByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) error {
+	err := op.m.deleteForwardChannelQuorumByChannel(ctx, tx, channelID)
+	if err != nil {
+		return meh.Wrap(err, "delete forward-channel-quorum by channel", meh.Details{"channel_id": channelID})
+	}
 	q, _, err := op.m.dialect.Delete(goqu.T("forward_to_user_channel_entries")).
 		Where(goqu.C("channel").Eq(channelID)).ToSQL()
 	if err != nil {
@@ -71,6 +83,10 @@ func (op *forwardToUserChannelOperator) setChannelDetailsByChannel(ctx context.C
 		return meh.Wrap(err, "delete details by channel", nil)
 	}
 	// Insert.
+	err = op.m.setForwardChannelQuorumByChannel(ctx, tx, channelID, details.Quorum)
+	if err != nil {
+		return meh.Wrap(err, "set forward-channel-quorum by channel", meh.Details{"quorum": details.Quorum})
+	}
 	records := make([]any, 0, len(details.ForwardToUser))
 	for _, userID := range details.ForwardToUser {
 		records = append(records, goqu.Record{
@@ -96,6 +112,7 @@ func (op *forwardToUserChannelOperator) setChannelDetailsByChannel(ctx context.C
 func (op *forwardToUserChannelOperator) getChannelDetailsByChannel(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (ChannelDetails, error) {
 	// Build query.
 	q, _, err := op.m.dialect.From(goqu.T("forward_to_user_channel_entries")).
+		Select(goqu.C("forward_to_user")).
 		Where(goqu.C("channel").Eq(channelID)).ToSQL()
 	if err != nil {
 		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -118,6 +135,11 @@ func (op *forwardToUserChannelOperator) getChannelDetailsByChannel(ctx context.C
 		}
 		details.ForwardToUser = append(details.ForwardToUser, userID)
 	}
+	rows.Close()
+	details.Quorum, err = op.m.ForwardChannelQuorumByChannel(ctx, tx, channelID)
+	if err != nil {
+		return nil, meh.Wrap(err, "forward-channel-quorum by channel", nil)
+	}
 	return details, nil
 }
 
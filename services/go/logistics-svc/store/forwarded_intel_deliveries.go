@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+)
+
+// LinkIntelDeliveryToForwardingAttempt marks the intel-delivery with the given
+// id as being forwarded by the intel-delivery-attempt with the given id.
+func (m *Mall) LinkIntelDeliveryToForwardingAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, attemptID uuid.UUID) error {
+	q, _, err := m.dialect.Insert(goqu.T("forwarded_intel_deliveries")).Rows(goqu.Record{
+		"delivery":     deliveryID,
+		"forwarded_by": attemptID,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// ForwardedIntelDeliveriesByAttempt retrieves the IntelDelivery list of
+// deliveries, that were forwarded by the intel-delivery-attempt with the given
+// id.
+func (m *Mall) ForwardedIntelDeliveriesByAttempt(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) ([]IntelDelivery, error) {
+	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
+		InnerJoin(goqu.T("forwarded_intel_deliveries"),
+			goqu.On(goqu.I("forwarded_intel_deliveries.delivery").Eq(goqu.I("intel_deliveries.id")))).
+		Select(goqu.I("intel_deliveries.id"),
+			goqu.I("intel_deliveries.intel"),
+			goqu.I("intel_deliveries.to"),
+			goqu.I("intel_deliveries.is_active"),
+			goqu.I("intel_deliveries.success"),
+			goqu.I("intel_deliveries.note"),
+			goqu.I("intel_deliveries.deliver_at")).
+		Where(goqu.I("forwarded_intel_deliveries.forwarded_by").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	deliveries := make([]IntelDelivery, 0)
+	for rows.Next() {
+		var delivery IntelDelivery
+		err = rows.Scan(&delivery.ID,
+			&delivery.Intel,
+			&delivery.To,
+			&delivery.IsActive,
+			&delivery.Success,
+			&delivery.Note,
+			&delivery.DeliverAt)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+	return deliveries, nil
+}
+
+// ForwardingAttemptByDelivery retrieves the IntelDeliveryAttempt, the
+// intel-delivery with the given id was forwarded by. If the delivery was not
+// forwarded, the second return value will be false.
+func (m *Mall) ForwardingAttemptByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (IntelDeliveryAttempt, bool, error) {
+	q, _, err := m.dialect.From(goqu.T("forwarded_intel_deliveries")).
+		Select(goqu.C("forwarded_by")).
+		Where(goqu.C("delivery").Eq(deliveryID)).ToSQL()
+	if err != nil {
+		return IntelDeliveryAttempt{}, false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return IntelDeliveryAttempt{}, false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return IntelDeliveryAttempt{}, false, nil
+	}
+	var attemptID uuid.UUID
+	err = rows.Scan(&attemptID)
+	if err != nil {
+		return IntelDeliveryAttempt{}, false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	attempt, err := m.IntelDeliveryAttemptByID(ctx, tx, attemptID)
+	if err != nil {
+		return IntelDeliveryAttempt{}, false, meh.Wrap(err, "intel-delivery-attempt by id", meh.Details{"attempt_id": attemptID})
+	}
+	return attempt, true, nil
+}
+
+// lockIntelDeliveriesWithForwardChainsOrWait locks the intel-deliveries,
+// matching the given filter, as well as all deliveries they were forwarded by,
+// or waits until they are available. Deliveries are locked in the order of the
+// forward chain, starting with the one that forwarded first. As any delivery is
+// locked after the one it was forwarded by, this avoids deadlocks between
+// handling forwarded deliveries and the forwarding ones.
+func (m *Mall) lockIntelDeliveriesWithForwardChainsOrWait(ctx context.Context, tx pgx.Tx, filter exp.Expression) error {
+	// The depth is the distance to the deliveries, matching the filter. As an
+	// ancestor always has a greater maximum depth than its descendants, ordering
+	// by it descending locks ancestors first.
+	forwardChain := m.dialect.From(goqu.T("intel_deliveries")).
+		Select(goqu.C("id"), goqu.L("0")).
+		Where(filter).
+		UnionAll(m.dialect.From(goqu.T("forward_chain")).
+			InnerJoin(goqu.T("forwarded_intel_deliveries"),
+				goqu.On(goqu.I("forwarded_intel_deliveries.delivery").Eq(goqu.I("forward_chain.id")))).
+			InnerJoin(goqu.T("intel_delivery_attempts"),
+				goqu.On(goqu.I("intel_delivery_attempts.id").Eq(goqu.I("forwarded_intel_deliveries.forwarded_by")))).
+			Select(goqu.I("intel_delivery_attempts.delivery"), goqu.L("forward_chain.depth + 1")))
+	forwardChainDepths := m.dialect.From(goqu.T("forward_chain")).
+		Select(goqu.C("id"), goqu.MAX(goqu.C("depth")).As("depth")).
+		GroupBy(goqu.C("id"))
+	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
+		WithRecursive("forward_chain(id, depth)", forwardChain).
+		With("forward_chain_depths", forwardChainDepths).
+		InnerJoin(goqu.T("forward_chain_depths"),
+			goqu.On(goqu.I("forward_chain_depths.id").Eq(goqu.I("intel_deliveries.id")))).
+		Select(goqu.I("intel_deliveries.id")).
+		Order(goqu.I("forward_chain_depths.depth").Desc(), goqu.I("intel_deliveries.id").Asc()).
+		ForUpdate(exp.Wait, goqu.T("intel_deliveries")).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
@@ -94,6 +94,11 @@ type CreateIntel struct {
 	// InitialDeliverTo contains the recipient address book entries to initially
 	// deliver the intel to.
 	InitialDeliverTo []uuid.UUID
+	// InitialDeliverAt is the optional IntelDelivery.DeliverAt for deliveries to
+	// InitialDeliverTo.
+	InitialDeliverAt nulls.Time
+	// ValidUntil is the optional timestamp after which the intel expires.
+	ValidUntil nulls.Time
 }
 
 // Validate the CreateIntel for Type, Content and Assignments.
@@ -105,6 +110,10 @@ func (i CreateIntel) Validate() (entityvalidation.Report, error) {
 		return entityvalidation.Report{}, meh.Wrap(err, "validate create-intel-type and content", nil)
 	}
 	report.Include(subReport)
+	// Assure expiry in the future.
+	if i.ValidUntil.Valid && !i.ValidUntil.Time.After(time.Now()) {
+		report.AddError("valid-until must be in the future")
+	}
 	// Assure no duplicate delivery-entries.
 	assignedTo := make(map[uuid.UUID]struct{}, len(i.InitialDeliverTo))
 	for _, to := range i.InitialDeliverTo {
@@ -138,12 +147,23 @@ type Intel struct {
 	// IsValid describes whether the intel is still valid or marked as invalid
 	// (equals deletion).
 	IsValid bool
+	// PreviousVersion is the id of the intel, this one is an amended version of.
+	PreviousVersion uuid.NullUUID
+	// Version is the number in the version chain, starting with 1 for the
+	// original intel.
+	Version int
+	// ValidUntil is the optional timestamp after which the intel expires. Active
+	// deliveries are then canceled.
+	ValidUntil nulls.Time
+	// IsExpired describes whether the intel expired because of ValidUntil being
+	// reached.
+	IsExpired bool
 }
 
 // CreateIntel creates the given intel with its assignments.
 func (m *Mall) CreateIntel(ctx context.Context, tx pgx.Tx, create CreateIntel) (Intel, error) {
 	// Create intel.
-	q, _, err := m.dialect.Insert(goqu.T("intel")).Rows(goqu.Record{
+	intelID, err := m.insertIntel(ctx, tx, goqu.Record{
 		"created_at":  time.Now().UTC(),
 		"created_by":  create.CreatedBy,
 		"operation":   create.Operation,
@@ -152,37 +172,48 @@ func (m *Mall) CreateIntel(ctx context.Context, tx pgx.Tx, create CreateIntel) (
 		"search_text": create.SearchText,
 		"importance":  create.Importance,
 		"is_valid":    true,
-	}).Returning(goqu.C("id")).ToSQL()
+		"valid_until": utcNullTime(create.ValidUntil),
+	})
 	if err != nil {
-		return Intel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+		return Intel{}, meh.Wrap(err, "insert intel", nil)
+	}
+	created, err := m.IntelByID(ctx, tx, intelID)
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "created intel by id", meh.Details{"intel_id": intelID})
+	}
+	// Create in search.
+	err = m.addOrUpdateIntelInSearch(ctx, tx, intelID)
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "add or update intel in search", meh.Details{"intel_id": intelID})
+	}
+	return created, nil
+}
+
+// insertIntel inserts the given record into the intel-table and returns the id
+// of the created intel.
+func (m *Mall) insertIntel(ctx context.Context, tx pgx.Tx, record goqu.Record) (uuid.UUID, error) {
+	q, _, err := m.dialect.Insert(goqu.T("intel")).Rows(record).Returning(goqu.C("id")).ToSQL()
+	if err != nil {
+		return uuid.Nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
 	rows, err := tx.Query(ctx, q)
 	if err != nil {
-		return Intel{}, mehpg.NewQueryDBErr(err, "exec query", q)
+		return uuid.Nil, mehpg.NewQueryDBErr(err, "exec query", q)
 	}
 	defer rows.Close()
 	if !rows.Next() {
 		if err = rows.Err(); err != nil {
-			return Intel{}, mehpg.NewQueryDBErr(err, "exec query", q)
+			return uuid.Nil, mehpg.NewQueryDBErr(err, "exec query", q)
 		}
-		return Intel{}, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
+		return uuid.Nil, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
 	}
 	var intelID uuid.UUID
 	err = rows.Scan(&intelID)
 	if err != nil {
-		return Intel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+		return uuid.Nil, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
 	rows.Close()
-	created, err := m.IntelByID(ctx, tx, intelID)
-	if err != nil {
-		return Intel{}, meh.Wrap(err, "created intel by id", meh.Details{"intel_id": intelID})
-	}
-	// Create in search.
-	err = m.addOrUpdateIntelInSearch(ctx, tx, intelID)
-	if err != nil {
-		return Intel{}, meh.Wrap(err, "add or update intel in search", meh.Details{"intel_id": intelID})
-	}
-	return created, nil
+	return intelID, nil
 }
 
 // InvalidateIntelByID sets the valid-field of the intel with the given id to
@@ -221,7 +252,11 @@ func (m *Mall) IntelByID(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) (Int
 			goqu.C("content"),
 			goqu.C("search_text"),
 			goqu.C("importance"),
-			goqu.C("is_valid")).
+			goqu.C("is_valid"),
+			goqu.C("previous_version"),
+			goqu.C("version"),
+			goqu.C("valid_until"),
+			goqu.C("is_expired")).
 		Where(goqu.C("id").Eq(intelID)).ToSQL()
 	if err != nil {
 		return Intel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -243,7 +278,11 @@ func (m *Mall) IntelByID(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) (Int
 		&intel.Content,
 		&intel.SearchText,
 		&intel.Importance,
-		&intel.IsValid)
+		&intel.IsValid,
+		&intel.PreviousVersion,
+		&intel.Version,
+		&intel.ValidUntil,
+		&intel.IsExpired)
 	if err != nil {
 		return Intel{}, mehpg.NewScanRowsErr(err, "scan rows", q)
 	}
@@ -337,7 +376,11 @@ func (m *Mall) SearchIntel(ctx context.Context, tx pgx.Tx, filters IntelFilters,
 			goqu.C("content"),
 			goqu.C("search_text"),
 			goqu.C("importance"),
-			goqu.C("is_valid"))
+			goqu.C("is_valid"),
+			goqu.C("previous_version"),
+			goqu.C("version"),
+			goqu.C("valid_until"),
+			goqu.C("is_expired"))
 	// For safety in order to hide intel not having deliveries for the optionally
 	// set user.
 	if len(filters.OneOfDeliveryForEntries) > 0 {
@@ -365,7 +408,11 @@ func (m *Mall) SearchIntel(ctx context.Context, tx pgx.Tx, filters IntelFilters,
 			&intel.Content,
 			&intel.SearchText,
 			&intel.Importance,
-			&intel.IsValid)
+			&intel.IsValid,
+			&intel.PreviousVersion,
+			&intel.Version,
+			&intel.ValidUntil,
+			&intel.IsExpired)
 		if err != nil {
 			return search.Result[Intel]{}, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
@@ -447,7 +494,11 @@ func (m *Mall) Intel(ctx context.Context, tx pgx.Tx, filters IntelFilters,
 			goqu.I("intel.content"),
 			goqu.I("intel.search_text"),
 			goqu.I("intel.importance"),
-			goqu.I("intel.is_valid")).
+			goqu.I("intel.is_valid"),
+			goqu.I("intel.previous_version"),
+			goqu.I("intel.version"),
+			goqu.I("intel.valid_until"),
+			goqu.I("intel.is_expired")).
 		Order(goqu.I("intel.created_at").Desc())
 	if filters.CreatedBy.Valid {
 		qb = qb.Where(goqu.I("intel.created_by").Eq(filters.CreatedBy.UUID))
@@ -500,6 +551,10 @@ func (m *Mall) Intel(ctx context.Context, tx pgx.Tx, filters IntelFilters,
 			&intel.SearchText,
 			&intel.Importance,
 			&intel.IsValid,
+			&intel.PreviousVersion,
+			&intel.Version,
+			&intel.ValidUntil,
+			&intel.IsExpired,
 			&total)
 		if err != nil {
 			return pagination.Paginated[Intel]{}, mehpg.NewScanRowsErr(err, "scan row", q)
@@ -542,7 +597,8 @@ func (m *Mall) IntelDeliveryAttempts(ctx context.Context, tx pgx.Tx, filters Int
 			goqu.I("intel_delivery_attempts.is_active"),
 			goqu.I("intel_delivery_attempts.status"),
 			goqu.I("intel_delivery_attempts.status_ts"),
-			goqu.I("intel_delivery_attempts.note")).
+			goqu.I("intel_delivery_attempts.note"),
+			goqu.I("intel_delivery_attempts.acknowledged_by")).
 		Order(goqu.I("intel_delivery_attempts.created_at").Desc())
 	if filters.ByOperation.Valid {
 		qb = qb.Where(goqu.I("intel.operation").Eq(filters.ByOperation.UUID))
@@ -580,6 +636,7 @@ func (m *Mall) IntelDeliveryAttempts(ctx context.Context, tx pgx.Tx, filters Int
 			&attempt.Status,
 			&attempt.StatusTS,
 			&attempt.Note,
+			&attempt.AcknowledgedBy,
 			&total)
 		if err != nil {
 			return pagination.Paginated[IntelDeliveryAttempt]{}, mehpg.NewScanRowsErr(err, "scan row", q)
@@ -55,6 +55,9 @@ type IntelDelivery struct {
 	Success bool
 	// Note contains optional human-readable information regarding the delivery.
 	Note nulls.String `json:"note"`
+	// DeliverAt is the optional timestamp, before which no delivery attempts are
+	// created. Until then, the delivery is considered scheduled.
+	DeliverAt nulls.Time
 }
 
 // IntelDeliveryAttempt is an attempt for IntelDelivery for a specific channel.
@@ -75,17 +78,21 @@ type IntelDeliveryAttempt struct {
 	StatusTS time.Time
 	// Note contains optional human-readable information regarding the attempt.
 	Note nulls.String
+	// AcknowledgedBy is the id of the user that acknowledged the delivered intel,
+	// if reported by the channel.
+	AcknowledgedBy uuid.NullUUID
 }
 
 // CreateIntelDelivery creates the given IntelDelivery and returns the one with
 // assigned id.
 func (m *Mall) CreateIntelDelivery(ctx context.Context, tx pgx.Tx, create IntelDelivery) (IntelDelivery, error) {
 	q, _, err := m.dialect.Insert(goqu.T("intel_deliveries")).Rows(goqu.Record{
-		"intel":     create.Intel,
-		"to":        create.To,
-		"is_active": create.IsActive,
-		"success":   create.Success,
-		"note":      create.Note,
+		"intel":      create.Intel,
+		"to":         create.To,
+		"is_active":  create.IsActive,
+		"success":    create.Success,
+		"note":       create.Note,
+		"deliver_at": utcNullTime(create.DeliverAt),
 	}).Returning(goqu.C("id")).ToSQL()
 	if err != nil {
 		return IntelDelivery{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -127,7 +134,8 @@ func (m *Mall) IntelDeliveryByID(ctx context.Context, tx pgx.Tx, deliveryID uuid
 			goqu.C("to"),
 			goqu.C("is_active"),
 			goqu.C("success"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("deliver_at")).
 		Where(goqu.C("id").Eq(deliveryID)).ToSQL()
 	if err != nil {
 		return IntelDelivery{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -146,7 +154,8 @@ func (m *Mall) IntelDeliveryByID(ctx context.Context, tx pgx.Tx, deliveryID uuid
 		&delivery.To,
 		&delivery.IsActive,
 		&delivery.Success,
-		&delivery.Note)
+		&delivery.Note,
+		&delivery.DeliverAt)
 	if err != nil {
 		return IntelDelivery{}, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
@@ -161,7 +170,8 @@ func (m *Mall) IntelDeliveriesTo(ctx context.Context, tx pgx.Tx, entryID uuid.UU
 			goqu.C("to"),
 			goqu.C("is_active"),
 			goqu.C("success"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("deliver_at")).
 		Where(goqu.C("to").Eq(entryID)).ToSQL()
 
 	if err != nil {
@@ -181,6 +191,7 @@ func (m *Mall) IntelDeliveriesTo(ctx context.Context, tx pgx.Tx, entryID uuid.UU
 			&delivery.IsActive,
 			&delivery.Success,
 			&delivery.Note,
+			&delivery.DeliverAt,
 		)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
@@ -200,7 +211,8 @@ func (m *Mall) IntelDeliveriesByIntel(ctx context.Context, tx pgx.Tx, intelID uu
 			goqu.C("to"),
 			goqu.C("is_active"),
 			goqu.C("success"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("deliver_at")).
 		Where(goqu.C("intel").Eq(intelID)).ToSQL()
 	if err != nil {
 		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -218,7 +230,8 @@ func (m *Mall) IntelDeliveriesByIntel(ctx context.Context, tx pgx.Tx, intelID uu
 			&delivery.To,
 			&delivery.IsActive,
 			&delivery.Success,
-			&delivery.Note)
+			&delivery.Note,
+			&delivery.DeliverAt)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
@@ -288,6 +301,25 @@ func (m *Mall) UpdateIntelDeliveryAttemptStatusByID(ctx context.Context, tx pgx.
 	return nil
 }
 
+// SetIntelDeliveryAttemptAcknowledgedBy sets IntelDeliveryAttempt.AcknowledgedBy
+// for the attempt with the given id.
+func (m *Mall) SetIntelDeliveryAttemptAcknowledgedBy(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, by uuid.UUID) error {
+	q, _, err := m.dialect.Update(goqu.T("intel_delivery_attempts")).Set(goqu.Record{
+		"acknowledged_by": by,
+	}).Where(goqu.C("id").Eq(attemptID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
 // TimedOutIntelDeliveryAttemptsByDelivery retrieves an IntelDeliveryAttempt
 // list with all attempts that have timed out.
 func (m *Mall) TimedOutIntelDeliveryAttemptsByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]IntelDeliveryAttempt, error) {
@@ -300,7 +332,8 @@ func (m *Mall) TimedOutIntelDeliveryAttemptsByDelivery(ctx context.Context, tx p
 			goqu.I("intel_delivery_attempts.is_active"),
 			goqu.I("intel_delivery_attempts.status"),
 			goqu.I("intel_delivery_attempts.status_ts"),
-			goqu.I("intel_delivery_attempts.note")).
+			goqu.I("intel_delivery_attempts.note"),
+			goqu.I("intel_delivery_attempts.acknowledged_by")).
 		Where(goqu.I("intel_delivery_attempts.delivery").Eq(deliveryID),
 			goqu.I("intel_delivery_attempts.created_at").Lt(goqu.L("now() - interval '1 ms' * channels.timeout / 1000000"))).ToSQL()
 	if err != nil {
@@ -321,7 +354,8 @@ func (m *Mall) TimedOutIntelDeliveryAttemptsByDelivery(ctx context.Context, tx p
 			&attempt.IsActive,
 			&attempt.Status,
 			&attempt.StatusTS,
-			&attempt.Note)
+			&attempt.Note,
+			&attempt.AcknowledgedBy)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
@@ -341,7 +375,8 @@ func (m *Mall) IntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptI
 			goqu.C("is_active"),
 			goqu.C("status"),
 			goqu.C("status_ts"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("acknowledged_by")).
 		Where(goqu.C("id").Eq(attemptID)).ToSQL()
 	if err != nil {
 		return IntelDeliveryAttempt{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -362,7 +397,8 @@ func (m *Mall) IntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptI
 		&attempt.IsActive,
 		&attempt.Status,
 		&attempt.StatusTS,
-		&attempt.Note)
+		&attempt.Note,
+		&attempt.AcknowledgedBy)
 	if err != nil {
 		return IntelDeliveryAttempt{}, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
@@ -371,17 +407,84 @@ func (m *Mall) IntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptI
 }
 
 // NextChannelForDeliveryAttempt retrieves the next channel to use for a
-// delivery attempt. Choice is based on available ones, priority and past
-// attempts.
-func (m *Mall) NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (Channel, bool, error) {
+// delivery attempt. Choice is based on available ones, priority, past attempts
+// and the ChannelRetryPolicy of each channel. The ChannelPresencePolicy of each
+// channel is respected based on the presence of the user, associated with the
+// recipient entry. Channels outside their ChannelAvailability are skipped. The
+// returned time is the one from which on the attempt should be created,
+// respecting ChannelRetryPolicy.Backoff. If no more attempts are possible, false
+// is returned.
+func (m *Mall) NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (Channel, time.Time, bool, error) {
+	candidates, pastAttempts, err := m.deliveryAttemptCandidates(ctx, tx, deliveryID)
+	if err != nil {
+		return Channel{}, time.Time{}, false, meh.Wrap(err, "delivery attempt candidates", meh.Details{"delivery_id": deliveryID})
+	}
+	channel, notBefore, ok, err := nextAvailableChannelForDeliveryAttempt(candidates, pastAttempts, time.Now())
+	if err != nil {
+		return Channel{}, time.Time{}, false, meh.Wrap(err, "next available channel for delivery attempt", nil)
+	}
+	return channel, notBefore, ok, nil
+}
+
+// ChannelsForFanOutDeliveryAttempts retrieves all channels to use for parallel
+// delivery attempts right now. Choice is made like in
+// NextChannelForDeliveryAttempt, but channels with active attempts are skipped
+// instead of blocking others. The returned time is the earliest one, from which
+// on another channel can be used later on. It is zero, if no channel becomes
+// usable later. If no channel can be used right now, but later on, an empty
+// list is returned. If no more attempts are possible at all, false is returned.
+func (m *Mall) ChannelsForFanOutDeliveryAttempts(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]Channel, time.Time, bool, error) {
+	candidates, pastAttempts, err := m.deliveryAttemptCandidates(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, time.Time{}, false, meh.Wrap(err, "delivery attempt candidates", meh.Details{"delivery_id": deliveryID})
+	}
+	channels, nextUsableLater, ok, err := fanOutChannelsForDeliveryAttempts(candidates, pastAttempts, time.Now())
+	if err != nil {
+		return nil, time.Time{}, false, meh.Wrap(err, "fan-out channels for delivery attempts", nil)
+	}
+	return channels, nextUsableLater, ok, nil
+}
+
+// IsFanOutEnabledForIntelDelivery checks whether the delivery with the given id
+// should be delivered over all usable channels in parallel. This is the case if
+// the importance of the intel reaches AddressBookEntry.FanOutMinImportance of
+// the recipient entry.
+func (m *Mall) IsFanOutEnabledForIntelDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (bool, error) {
+	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
+		InnerJoin(goqu.T("intel"),
+			goqu.On(goqu.I("intel.id").Eq(goqu.I("intel_deliveries.intel")))).
+		InnerJoin(goqu.T("address_book_entries"),
+			goqu.On(goqu.I("address_book_entries.id").Eq(goqu.I("intel_deliveries.to")))).
+		Select(goqu.I("intel_deliveries.id")).
+		Where(goqu.I("intel_deliveries.id").Eq(deliveryID),
+			goqu.I("address_book_entries.fan_out_min_importance").IsNotNull(),
+			goqu.I("intel.importance").Gte(goqu.I("address_book_entries.fan_out_min_importance"))).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	enabled := rows.Next()
+	return enabled, nil
+}
+
+// deliveryAttemptCandidates retrieves all active channels for the delivery with
+// the given id that match the importance of the intel, ordered by priority
+// descending and respecting the ChannelPresencePolicy. Past attempts for the
+// delivery are returned as well.
+func (m *Mall) deliveryAttemptCandidates(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]Channel, []IntelDeliveryAttempt, error) {
 	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
 		InnerJoin(goqu.T("intel"),
 			goqu.On(goqu.I("intel.id").Eq(goqu.I("intel_deliveries.intel")))).
 		InnerJoin(goqu.T("channels"),
 			goqu.On(goqu.I("channels.entry").Eq(goqu.I("intel_deliveries.to")))).
-		LeftJoin(goqu.T("intel_delivery_attempts"),
-			goqu.On(goqu.I("intel_delivery_attempts.delivery").Eq(deliveryID),
-				goqu.I("intel_delivery_attempts.channel").Eq(goqu.I("channels.id")))).
+		InnerJoin(goqu.T("address_book_entries"),
+			goqu.On(goqu.I("address_book_entries.id").Eq(goqu.I("intel_deliveries.to")))).
+		LeftJoin(goqu.T("user_presence"),
+			goqu.On(goqu.I("user_presence.user").Eq(goqu.I("address_book_entries.user")))).
 		Select(goqu.I("channels.id"),
 			goqu.I("channels.entry"),
 			goqu.I("channels.is_active"),
@@ -389,37 +492,241 @@ func (m *Mall) NextChannelForDeliveryAttempt(ctx context.Context, tx pgx.Tx, del
 			goqu.I("channels.type"),
 			goqu.I("channels.priority"),
 			goqu.I("channels.min_importance"),
-			goqu.I("channels.timeout")).
+			goqu.I("channels.timeout"),
+			goqu.I("channels.retry_max_attempts"),
+			goqu.I("channels.retry_backoff"),
+			goqu.I("channels.retry_on_timeout"),
+			goqu.I("channels.retry_on_failure"),
+			goqu.I("channels.presence_policy"),
+			goqu.I("channels.availability"),
+			goqu.I("address_book_entries.user"),
+			goqu.I("user_presence.is_online")).
 		Where(goqu.I("intel_deliveries.id").Eq(deliveryID),
-			goqu.I("intel_delivery_attempts.id").IsNull(),
 			goqu.I("channels.is_active").IsTrue(),
 			goqu.I("intel.importance").Gte(goqu.I("channels.min_importance"))).
-		Order(goqu.I("channels.priority").Desc()).
-		Limit(1).ToSQL()
+		Order(goqu.I("channels.priority").Desc()).ToSQL()
 	if err != nil {
-		return Channel{}, false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+		return nil, nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
 	rows, err := tx.Query(ctx, q)
 	if err != nil {
-		return Channel{}, false, mehpg.NewQueryDBErr(err, "query db", q)
+		return nil, nil, mehpg.NewQueryDBErr(err, "query db", q)
 	}
 	defer rows.Close()
-	if !rows.Next() {
-		return Channel{}, false, nil
+	candidates := make([]Channel, 0)
+	var recipientUser uuid.NullUUID
+	var recipientIsOnline nulls.Bool
+	for rows.Next() {
+		var channel Channel
+		var availabilityRaw []byte
+		err = rows.Scan(&channel.ID,
+			&channel.Entry,
+			&channel.IsActive,
+			&channel.Label,
+			&channel.Type,
+			&channel.Priority,
+			&channel.MinImportance,
+			&channel.Timeout,
+			&channel.RetryPolicy.MaxAttempts,
+			&channel.RetryPolicy.Backoff,
+			&channel.RetryPolicy.RetryOnTimeout,
+			&channel.RetryPolicy.RetryOnFailure,
+			&channel.PresencePolicy,
+			&availabilityRaw,
+			&recipientUser,
+			&recipientIsOnline)
+		if err != nil {
+			return nil, nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		channel.Availability, err = unmarshalChannelAvailability(availabilityRaw)
+		if err != nil {
+			return nil, nil, meh.Wrap(err, "unmarshal channel availability", meh.Details{"channel_id": channel.ID})
+		}
+		candidates = append(candidates, channel)
+	}
+	rows.Close()
+	pastAttempts, err := m.IntelDeliveryAttemptsByDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, nil, meh.Wrap(err, "intel delivery attempts by delivery", meh.Details{"delivery_id": deliveryID})
+	}
+	// Respect presence only for entries associated with a user. Users without
+	// known presence are considered offline.
+	if recipientUser.Valid {
+		candidates = orderChannelsByPresence(candidates, recipientIsOnline.Valid && recipientIsOnline.Bool)
+	}
+	return candidates, pastAttempts, nil
+}
+
+// nextAvailableChannelForDeliveryAttempt chooses the next channel to use like
+// nextChannelForDeliveryAttempt, but respects the ChannelAvailability of each
+// candidate. Channels, being available at the given time, are preferred. If
+// none of them can be used, channels that become available later are
+// considered. In this case, the returned time is the one when the chosen channel
+// becomes available, so that we wait instead of failing the delivery.
+func nextAvailableChannelForDeliveryAttempt(candidates []Channel, pastAttempts []IntelDeliveryAttempt, now time.Time) (Channel, time.Time, bool, error) {
+	available := make([]Channel, 0, len(candidates))
+	later := make([]Channel, 0)
+	availableFromByChannel := make(map[uuid.UUID]time.Time)
+	for _, channel := range candidates {
+		isAvailable, err := channel.Availability.IsAvailableAt(now)
+		if err != nil {
+			return Channel{}, time.Time{}, false, meh.Wrap(err, "check channel availability", meh.Details{"channel_id": channel.ID})
+		}
+		if isAvailable {
+			available = append(available, channel)
+			continue
+		}
+		availableFrom, ok, err := channel.Availability.NextAvailableFrom(now)
+		if err != nil {
+			return Channel{}, time.Time{}, false, meh.Wrap(err, "next channel availability", meh.Details{"channel_id": channel.ID})
+		}
+		if ok {
+			later = append(later, channel)
+			availableFromByChannel[channel.ID] = availableFrom
+		}
+	}
+	channel, notBefore, ok := nextChannelForDeliveryAttempt(available, pastAttempts)
+	if ok {
+		return channel, notBefore, true, nil
+	}
+	channel, notBefore, ok = nextChannelForDeliveryAttempt(later, pastAttempts)
+	if !ok {
+		return Channel{}, time.Time{}, false, nil
+	}
+	if availableFrom := availableFromByChannel[channel.ID]; availableFrom.After(notBefore) {
+		notBefore = availableFrom
+	}
+	return channel, notBefore, true, nil
+}
+
+// fanOutChannelsForDeliveryAttempts chooses all channels from the given
+// candidates that can be used for delivery attempts at the given time. Each
+// candidate is checked like in nextAvailableChannelForDeliveryAttempt. The
+// returned time is the earliest one, from which on a channel, that cannot be
+// used right now, becomes usable. It is zero, if there is no such channel. If no
+// channel can be used right now, but later on, an empty list is returned. If no
+// channel can be used at all, false is returned.
+func fanOutChannelsForDeliveryAttempts(candidates []Channel, pastAttempts []IntelDeliveryAttempt, now time.Time) ([]Channel, time.Time, bool, error) {
+	channels := make([]Channel, 0)
+	var nextUsableLater time.Time
+	for _, candidate := range candidates {
+		_, notBefore, ok, err := nextAvailableChannelForDeliveryAttempt([]Channel{candidate}, pastAttempts, now)
+		if err != nil {
+			return nil, time.Time{}, false, meh.Wrap(err, "next available channel for delivery attempt", meh.Details{"channel_id": candidate.ID})
+		}
+		if !ok {
+			continue
+		}
+		if notBefore.After(now) {
+			if nextUsableLater.IsZero() || notBefore.Before(nextUsableLater) {
+				nextUsableLater = notBefore
+			}
+			continue
+		}
+		channels = append(channels, candidate)
+	}
+	return channels, nextUsableLater, len(channels) > 0 || !nextUsableLater.IsZero(), nil
+}
+
+// orderChannelsByPresence reorders the given candidates, ordered by priority
+// descending, according to their ChannelPresencePolicy and whether the recipient
+// user is online. Channels are kept in priority order within being preferred,
+// regular or deprioritized. Skipped channels are removed.
+func orderChannelsByPresence(candidates []Channel, recipientIsOnline bool) []Channel {
+	preferred := make([]Channel, 0)
+	regular := make([]Channel, 0, len(candidates))
+	deprioritized := make([]Channel, 0)
+	for _, channel := range candidates {
+		if channel.PresencePolicy == ChannelPresencePolicyIgnore {
+			regular = append(regular, channel)
+			continue
+		}
+		if recipientIsOnline {
+			preferred = append(preferred, channel)
+			continue
+		}
+		switch channel.PresencePolicy {
+		case ChannelPresencePolicyDeprioritizeOffline:
+			deprioritized = append(deprioritized, channel)
+		case ChannelPresencePolicySkipOffline:
+		default:
+			regular = append(regular, channel)
+		}
+	}
+	ordered := make([]Channel, 0, len(preferred)+len(regular)+len(deprioritized))
+	ordered = append(ordered, preferred...)
+	ordered = append(ordered, regular...)
+	ordered = append(ordered, deprioritized...)
+	return ordered
+}
+
+// nextChannelForDeliveryAttempt chooses the next channel to use from the given
+// candidates, ordered by priority descending. Channels without any past attempt
+// are used right away. Channels with past attempts are retried according to
+// their ChannelRetryPolicy, based on the status of the last attempt. A channel,
+// that is still allowed to be retried, is preferred over lower-priority ones,
+// even if its backoff has not elapsed yet. In this case, the returned time is
+// the one when the backoff elapses.
+func nextChannelForDeliveryAttempt(candidates []Channel, pastAttempts []IntelDeliveryAttempt) (Channel, time.Time, bool) {
+	attemptCountByChannel := make(map[uuid.UUID]int32)
+	lastAttemptByChannel := make(map[uuid.UUID]IntelDeliveryAttempt)
+	for _, attempt := range pastAttempts {
+		attemptCountByChannel[attempt.Channel]++
+		if last, ok := lastAttemptByChannel[attempt.Channel]; !ok || attempt.CreatedAt.After(last.CreatedAt) {
+			lastAttemptByChannel[attempt.Channel] = attempt
+		}
 	}
-	var channel Channel
-	err = rows.Scan(&channel.ID,
-		&channel.Entry,
-		&channel.IsActive,
-		&channel.Label,
-		&channel.Type,
-		&channel.Priority,
-		&channel.MinImportance,
-		&channel.Timeout)
+	for _, channel := range candidates {
+		lastAttempt, ok := lastAttemptByChannel[channel.ID]
+		if !ok {
+			return channel, time.Time{}, true
+		}
+		if lastAttempt.IsActive || attemptCountByChannel[channel.ID] >= channel.RetryPolicy.MaxAttempts {
+			continue
+		}
+		switch lastAttempt.Status {
+		case IntelDeliveryStatusTimeout:
+			if !channel.RetryPolicy.RetryOnTimeout {
+				continue
+			}
+		case IntelDeliveryStatusFailed:
+			if !channel.RetryPolicy.RetryOnFailure {
+				continue
+			}
+		default:
+			continue
+		}
+		return channel, lastAttempt.StatusTS.Add(channel.RetryPolicy.Backoff), true
+	}
+	return Channel{}, time.Time{}, false
+}
+
+// UpdateIntelDeliveryDeliverAt updates the IntelDelivery.DeliverAt for the
+// delivery with the given id.
+func (m *Mall) UpdateIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	q, _, err := m.dialect.Update(goqu.T("intel_deliveries")).Set(goqu.Record{
+		"deliver_at": utcNullTime(deliverAt),
+	}).Where(goqu.C("id").Eq(deliveryID)).ToSQL()
 	if err != nil {
-		return Channel{}, false, mehpg.NewScanRowsErr(err, "scan row", q)
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
-	return channel, true, nil
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// utcNullTime converts the given nulls.Time to UTC, if set, as timestamps are
+// stored without time zone.
+func utcNullTime(t nulls.Time) nulls.Time {
+	if !t.Valid {
+		return t
+	}
+	return nulls.NewTime(t.Time.UTC())
 }
 
 // UpdateIntelDeliveryStatusByDelivery updates the status for the delivery with
@@ -480,7 +787,8 @@ func (m *Mall) IntelDeliveryAttemptsByDelivery(ctx context.Context, tx pgx.Tx, d
 			goqu.C("is_active"),
 			goqu.C("status"),
 			goqu.C("status_ts"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("acknowledged_by")).
 		Where(goqu.C("delivery").Eq(deliveryID)).
 		Order(goqu.C("created_at").Asc()).ToSQL()
 	if err != nil {
@@ -501,7 +809,8 @@ func (m *Mall) IntelDeliveryAttemptsByDelivery(ctx context.Context, tx pgx.Tx, d
 			&attempt.IsActive,
 			&attempt.Status,
 			&attempt.StatusTS,
-			&attempt.Note)
+			&attempt.Note,
+			&attempt.AcknowledgedBy)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
@@ -534,15 +843,22 @@ func (m *Mall) LockIntelDeliveryByIDOrSkip(ctx context.Context, tx pgx.Tx, deliv
 }
 
 // IntelDeliveryByIDAndLockOrWait retrieves the IntelDelivery with the given id
-// and locks it or waits until it is available.
+// and locks it or waits until it is available. Deliveries, it was forwarded by,
+// are locked before, so that forwarding deliveries are always locked before
+// forwarded ones.
 func (m *Mall) IntelDeliveryByIDAndLockOrWait(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) (IntelDelivery, error) {
+	err := m.lockIntelDeliveriesWithForwardChainsOrWait(ctx, tx, goqu.C("id").Eq(deliveryID))
+	if err != nil {
+		return IntelDelivery{}, meh.Wrap(err, "lock intel-delivery with forward chain", meh.Details{"delivery_id": deliveryID})
+	}
 	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
 		Select(goqu.C("id"),
 			goqu.C("intel"),
 			goqu.C("to"),
 			goqu.C("is_active"),
 			goqu.C("success"),
-			goqu.C("note")).
+			goqu.C("note"),
+			goqu.C("deliver_at")).
 		ForUpdate(exp.Wait).
 		Where(goqu.C("id").Eq(deliveryID)).ToSQL()
 	if err != nil {
@@ -562,7 +878,8 @@ func (m *Mall) IntelDeliveryByIDAndLockOrWait(ctx context.Context, tx pgx.Tx, de
 		&delivery.To,
 		&delivery.IsActive,
 		&delivery.Success,
-		&delivery.Note)
+		&delivery.Note,
+		&delivery.DeliverAt)
 	if err != nil {
 		return IntelDelivery{}, mehpg.NewScanRowsErr(err, "scan row", q)
 	}
@@ -570,7 +887,8 @@ func (m *Mall) IntelDeliveryByIDAndLockOrWait(ctx context.Context, tx pgx.Tx, de
 }
 
 // LockIntelDeliveryByIDOrWait locks the intel-delivery in the database with the
-// given id or waits until it is available.
+// given id or waits until it is available. Like IntelDeliveryByIDAndLockOrWait,
+// deliveries, it was forwarded by, are locked before.
 func (m *Mall) LockIntelDeliveryByIDOrWait(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
 	_, err := m.IntelDeliveryByIDAndLockOrWait(ctx, tx, deliveryID)
 	return err
@@ -579,12 +897,21 @@ func (m *Mall) LockIntelDeliveryByIDOrWait(ctx context.Context, tx pgx.Tx, deliv
 // ActiveIntelDeliveryAttemptsByChannelsAndLockOrWait retrieves an
 // IntelDeliveryAttempt list where each one is active and uses one of the given
 // channels. It locks the associated deliveries as well as the attempts or waits
-// until locked.
+// until locked. Like with IntelDeliveryByIDAndLockOrWait, deliveries, the
+// associated ones were forwarded by, are locked before.
 func (m *Mall) ActiveIntelDeliveryAttemptsByChannelsAndLockOrWait(ctx context.Context, tx pgx.Tx,
 	channelIDs []uuid.UUID) ([]IntelDeliveryAttempt, error) {
 	if len(channelIDs) == 0 {
 		return make([]IntelDeliveryAttempt, 0), nil
 	}
+	affectedDeliveries := m.dialect.From(goqu.T("intel_delivery_attempts")).
+		Select(goqu.C("delivery")).
+		Where(goqu.C("is_active").IsTrue(),
+			goqu.C("channel").In(channelIDs))
+	err := m.lockIntelDeliveriesWithForwardChainsOrWait(ctx, tx, goqu.C("id").In(affectedDeliveries))
+	if err != nil {
+		return nil, meh.Wrap(err, "lock affected intel-deliveries with forward chains", meh.Details{"channel_ids": channelIDs})
+	}
 	q, _, err := m.dialect.From(goqu.T("intel_delivery_attempts")).
 		InnerJoin(goqu.T("intel_deliveries"),
 			goqu.On(goqu.I("intel_deliveries.id").Eq(goqu.I("intel_delivery_attempts.delivery")))).
@@ -595,7 +922,8 @@ func (m *Mall) ActiveIntelDeliveryAttemptsByChannelsAndLockOrWait(ctx context.Co
 			goqu.I("intel_delivery_attempts.is_active"),
 			goqu.I("intel_delivery_attempts.status"),
 			goqu.I("intel_delivery_attempts.status_ts"),
-			goqu.I("intel_delivery_attempts.note")).
+			goqu.I("intel_delivery_attempts.note"),
+			goqu.I("intel_delivery_attempts.acknowledged_by")).
 		ForUpdate(exp.Wait).
 		Where(goqu.I("intel_delivery_attempts.is_active").IsTrue(),
 			goqu.I("intel_delivery_attempts.channel").In(channelIDs)).ToSQL()
@@ -617,7 +945,8 @@ func (m *Mall) ActiveIntelDeliveryAttemptsByChannelsAndLockOrWait(ctx context.Co
 			&attempt.IsActive,
 			&attempt.Status,
 			&attempt.StatusTS,
-			&attempt.Note)
+			&attempt.Note,
+			&attempt.AcknowledgedBy)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
@@ -656,44 +985,6 @@ func (m *Mall) DeleteInactiveIntelDeliveriesFor(ctx context.Context, tx pgx.Tx,
 	return nil
 }
 
-// ActiveIntelDeliveriesAndLockOrSkip retrieves all active intel-deliveries and
-// locks or skips them.
-func (m *Mall) ActiveIntelDeliveriesAndLockOrSkip(ctx context.Context, tx pgx.Tx) ([]IntelDelivery, error) {
-	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
-		Select(goqu.C("id"),
-			goqu.C("intel"),
-			goqu.C("to"),
-			goqu.C("is_active"),
-			goqu.C("success"),
-			goqu.C("note")).
-		ForUpdate(exp.SkipLocked).
-		Where(goqu.C("is_active").IsTrue()).ToSQL()
-	if err != nil {
-		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
-	}
-	rows, err := tx.Query(ctx, q)
-	if err != nil {
-		return nil, mehpg.NewQueryDBErr(err, "query db", q)
-	}
-	defer rows.Close()
-	deliveries := make([]IntelDelivery, 0)
-	for rows.Next() {
-		var delivery IntelDelivery
-		err = rows.Scan(&delivery.ID,
-			&delivery.Intel,
-			&delivery.To,
-			&delivery.IsActive,
-			&delivery.Success,
-			&delivery.Note)
-		if err != nil {
-			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
-		}
-		deliveries = append(deliveries, delivery)
-	}
-	rows.Close()
-	return deliveries, nil
-}
-
 // ActiveIntelDeliveriesForOperation retrieves the IntelDelivery list for active
 // deliveries for intel for the operation with the given id.
 func (m *Mall) ActiveIntelDeliveriesForOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID) ([]IntelDelivery, error) {
@@ -704,7 +995,8 @@ func (m *Mall) ActiveIntelDeliveriesForOperation(ctx context.Context, tx pgx.Tx,
 			goqu.I("intel_deliveries.to"),
 			goqu.I("intel_deliveries.is_active"),
 			goqu.I("intel_deliveries.success"),
-			goqu.I("intel_deliveries.note")).
+			goqu.I("intel_deliveries.note"),
+			goqu.I("intel_deliveries.deliver_at")).
 		Where(goqu.I("intel_deliveries.is_active").IsTrue(),
 			goqu.I("intel.operation").Eq(operationID)).ToSQL()
 	if err != nil {
@@ -723,7 +1015,8 @@ func (m *Mall) ActiveIntelDeliveriesForOperation(ctx context.Context, tx pgx.Tx,
 			&delivery.To,
 			&delivery.IsActive,
 			&delivery.Success,
-			&delivery.Note)
+			&delivery.Note,
+			&delivery.DeliverAt)
 		if err != nil {
 			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
 		}
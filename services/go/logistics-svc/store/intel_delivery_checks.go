@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// ScheduleIntelDeliveryCheck schedules a check for the active delivery with the
+// given id at the given time. If a check is already scheduled earlier, it is
+// kept.
+func (m *Mall) ScheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, at time.Time) error {
+	err := m.scheduleIntelDeliveryChecks(ctx, tx, goqu.L("?", at.UTC()), goqu.C("id").Eq(deliveryID))
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks", meh.Details{"delivery_id": deliveryID})
+	}
+	return nil
+}
+
+// ScheduleIntelDeliveryChecksByEntry schedules checks like
+// ScheduleIntelDeliveryCheck for all active deliveries to the address book
+// entry with the given id.
+func (m *Mall) ScheduleIntelDeliveryChecksByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, at time.Time) error {
+	err := m.scheduleIntelDeliveryChecks(ctx, tx, goqu.L("?", at.UTC()), goqu.C("to").Eq(entryID))
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks", meh.Details{"entry_id": entryID})
+	}
+	return nil
+}
+
+// ScheduleIntelDeliveryChecksByUser schedules checks like
+// ScheduleIntelDeliveryCheck for all active deliveries to address book entries,
+// that are associated with the user with the given id.
+func (m *Mall) ScheduleIntelDeliveryChecksByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, at time.Time) error {
+	entriesOfUser := m.dialect.From(goqu.T("address_book_entries")).
+		Select(goqu.C("id")).
+		Where(goqu.C("user").Eq(userID))
+	err := m.scheduleIntelDeliveryChecks(ctx, tx, goqu.L("?", at.UTC()), goqu.C("to").In(entriesOfUser))
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks", meh.Details{"user_id": userID})
+	}
+	return nil
+}
+
+// ScheduleIntelDeliveryChecksByOperation schedules checks like
+// ScheduleIntelDeliveryCheck for all active deliveries of intel for the
+// operation with the given id.
+func (m *Mall) ScheduleIntelDeliveryChecksByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID, at time.Time) error {
+	intelOfOperation := m.dialect.From(goqu.T("intel")).
+		Select(goqu.C("id")).
+		Where(goqu.C("operation").Eq(operationID))
+	err := m.scheduleIntelDeliveryChecks(ctx, tx, goqu.L("?", at.UTC()), goqu.C("intel").In(intelOfOperation))
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks", meh.Details{"operation_id": operationID})
+	}
+	return nil
+}
+
+// ScheduleIntelDeliveryTimeoutCheck schedules a check like
+// ScheduleIntelDeliveryCheck for the delivery with the given id at the time,
+// when the next of its active attempts times out or the next open-timeout
+// escalation rule becomes due. If none of them are upcoming, no check is
+// scheduled.
+func (m *Mall) ScheduleIntelDeliveryTimeoutCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	nextAttemptTimeout := m.dialect.From(goqu.T("intel_delivery_attempts")).
+		InnerJoin(goqu.T("channels"),
+			goqu.On(goqu.I("channels.id").Eq(goqu.I("intel_delivery_attempts.channel")))).
+		Select(goqu.MIN(goqu.L("intel_delivery_attempts.created_at + interval '1 ms' * channels.timeout / 1000000"))).
+		Where(goqu.I("intel_delivery_attempts.delivery").Eq(goqu.I("intel_deliveries.id")),
+			goqu.I("intel_delivery_attempts.is_active").IsTrue())
+	escalationDueAt := goqu.L("greatest(intel_deliveries.created_at, intel_deliveries.deliver_at) + interval '1 ms' * intel_delivery_escalation_rules.open_timeout / 1000000")
+	nextOpenTimeoutEscalation := m.dialect.From(goqu.T("intel_delivery_escalation_rules")).
+		InnerJoin(goqu.T("intel"),
+			goqu.On(goqu.I("intel.operation").Eq(goqu.I("intel_delivery_escalation_rules.operation")))).
+		Select(goqu.MIN(escalationDueAt)).
+		Where(goqu.I("intel.id").Eq(goqu.I("intel_deliveries.intel")),
+			goqu.I("intel_delivery_escalation_rules.trigger").Eq(IntelDeliveryEscalationTriggerOpenTimeout),
+			goqu.L("? > (now() at time zone 'utc')", escalationDueAt))
+	err := m.scheduleIntelDeliveryChecks(ctx, tx, goqu.L("least(?, ?)", nextAttemptTimeout, nextOpenTimeoutEscalation),
+		goqu.C("id").Eq(deliveryID))
+	if err != nil {
+		return meh.Wrap(err, "schedule intel-delivery checks", meh.Details{"delivery_id": deliveryID})
+	}
+	return nil
+}
+
+// scheduleIntelDeliveryChecks sets the next check for all active deliveries,
+// matching the given filter, to the given timestamp expression, if no earlier
+// one is scheduled. As least ignores null values, the expression may evaluate
+// to null for not scheduling any check.
+func (m *Mall) scheduleIntelDeliveryChecks(ctx context.Context, tx pgx.Tx, at exp.Expression, filter exp.Expression) error {
+	q, _, err := m.dialect.Update(goqu.T("intel_deliveries")).Set(goqu.Record{
+		"next_check_at": goqu.L("least(next_check_at, ?)", at),
+	}).Where(filter,
+		goqu.C("is_active").IsTrue()).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// UnscheduleIntelDeliveryCheck removes the scheduled check for the delivery with
+// the given id.
+func (m *Mall) UnscheduleIntelDeliveryCheck(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error {
+	q, _, err := m.dialect.Update(goqu.T("intel_deliveries")).Set(goqu.Record{
+		"next_check_at": nil,
+	}).Where(goqu.C("id").Eq(deliveryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// ClaimDueIntelDeliveries retrieves the ids of at most the given limit of
+// active deliveries with checks being due, ordered by due-time. Already locked
+// ones are skipped. The checks for claimed deliveries are rescheduled after the
+// given lease. This allows processing each of them in a separate transaction
+// without others claiming them as well. If processing fails, the delivery is
+// checked again after the lease.
+func (m *Mall) ClaimDueIntelDeliveries(ctx context.Context, tx pgx.Tx, limit int, lease time.Duration) ([]uuid.UUID, error) {
+	dueDeliveries := m.dialect.From(goqu.T("intel_deliveries")).
+		Select(goqu.C("id")).
+		Where(goqu.C("is_active").IsTrue(),
+			goqu.C("next_check_at").Lte(goqu.L("(now() at time zone 'utc')"))).
+		Order(goqu.C("next_check_at").Asc()).
+		Limit(uint(limit)).
+		ForUpdate(exp.SkipLocked)
+	q, _, err := m.dialect.Update(goqu.T("intel_deliveries")).Set(goqu.Record{
+		"next_check_at": goqu.L("(now() at time zone 'utc') + interval '1 ms' * ? / 1000000", lease.Nanoseconds()),
+	}).Where(goqu.C("id").In(dueDeliveries)).
+		Returning(goqu.C("id")).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	deliveryIDs := make([]uuid.UUID, 0, limit)
+	for rows.Next() {
+		var deliveryID uuid.UUID
+		err = rows.Scan(&deliveryID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		deliveryIDs = append(deliveryIDs, deliveryID)
+	}
+	rows.Close()
+	return deliveryIDs, nil
+}
+
+// NextIntelDeliveryCheck retrieves the earliest time, a check for any active
+// delivery is scheduled for. If no checks are scheduled, false is returned.
+func (m *Mall) NextIntelDeliveryCheck(ctx context.Context, tx pgx.Tx) (time.Time, bool, error) {
+	q, _, err := m.dialect.From(goqu.T("intel_deliveries")).
+		Select(goqu.MIN(goqu.C("next_check_at"))).
+		Where(goqu.C("is_active").IsTrue()).ToSQL()
+	if err != nil {
+		return time.Time{}, false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return time.Time{}, false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return time.Time{}, false, nil
+	}
+	var nextCheck nulls.Time
+	err = rows.Scan(&nextCheck)
+	if err != nil {
+		return time.Time{}, false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return nextCheck.Time, nextCheck.Valid, nil
+}
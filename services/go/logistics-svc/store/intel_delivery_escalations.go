@@ -0,0 +1,334 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/entityvalidation"
+	"time"
+)
+
+// IntelDeliveryEscalationTrigger is the reason for an IntelDelivery being
+// escalated.
+type IntelDeliveryEscalationTrigger string
+
+const (
+	// IntelDeliveryEscalationTriggerFailed for deliveries that failed because of no
+	// more channels to try.
+	IntelDeliveryEscalationTriggerFailed IntelDeliveryEscalationTrigger = "failed"
+	// IntelDeliveryEscalationTriggerOpenTimeout for deliveries that are still
+	// active after IntelDeliveryEscalationRule.OpenTimeout.
+	IntelDeliveryEscalationTriggerOpenTimeout IntelDeliveryEscalationTrigger = "open-timeout"
+)
+
+// IntelDeliveryEscalationRule describes what to do, when an IntelDelivery in an
+// operation failed or is still open for too long.
+type IntelDeliveryEscalationRule struct {
+	// ID identifies the rule.
+	ID uuid.UUID
+	// Operation is the id of the operation the rule applies to.
+	Operation uuid.UUID
+	// Trigger for the escalation.
+	Trigger IntelDeliveryEscalationTrigger
+	// OpenTimeout is the duration after which still active deliveries are
+	// escalated. Only used with IntelDeliveryEscalationTriggerOpenTimeout.
+	OpenTimeout time.Duration
+	// EscalateTo is the optional id of the fallback address book entry to create a
+	// delivery to.
+	EscalateTo uuid.NullUUID
+	// NotifyManagers describes whether users, that are allowed to manage
+	// intel-deliveries in the operation, should be notified.
+	NotifyManagers bool
+}
+
+// Validate the Trigger, OpenTimeout and that the rule actually does something.
+func (r IntelDeliveryEscalationRule) Validate() (entityvalidation.Report, error) {
+	report := entityvalidation.NewReport()
+	switch r.Trigger {
+	case IntelDeliveryEscalationTriggerFailed:
+		if r.OpenTimeout != 0 {
+			report.AddError("open timeout must only be set for open-timeout trigger")
+		}
+	case IntelDeliveryEscalationTriggerOpenTimeout:
+		if r.OpenTimeout <= 0 {
+			report.AddError("open timeout must be greater zero")
+		}
+	default:
+		report.AddError(fmt.Sprintf("unknown trigger: %v", r.Trigger))
+	}
+	if !r.EscalateTo.Valid && !r.NotifyManagers {
+		report.AddError("rule must either escalate to an address book entry or notify managers")
+	}
+	return report, nil
+}
+
+// IntelDeliveryEscalation is an applied IntelDeliveryEscalationRule for an
+// IntelDelivery.
+type IntelDeliveryEscalation struct {
+	// ID identifies the escalation.
+	ID uuid.UUID
+	// Delivery is the id of the escalated delivery.
+	Delivery uuid.UUID
+	// Rule is the id of the applied IntelDeliveryEscalationRule. It is not set
+	// anymore, if the rule was deleted in the meantime.
+	Rule uuid.NullUUID
+	// Trigger is the reason for the escalation.
+	Trigger IntelDeliveryEscalationTrigger
+	// EscalatedAt is the timestamp when the delivery was escalated.
+	EscalatedAt time.Time
+	// EscalatedTo is the id of the delivery that was created to the fallback
+	// address book entry.
+	EscalatedTo uuid.NullUUID
+	// NotifiedManagers describes whether managers were notified.
+	NotifiedManagers bool
+}
+
+// IntelDeliveryEscalationRulesByOperation retrieves the
+// IntelDeliveryEscalationRule list for the operation with the given id.
+func (m *Mall) IntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID) ([]IntelDeliveryEscalationRule, error) {
+	q, _, err := m.dialect.From(goqu.T("intel_delivery_escalation_rules")).
+		Select(goqu.C("id"),
+			goqu.C("operation"),
+			goqu.C("trigger"),
+			goqu.C("open_timeout"),
+			goqu.C("escalate_to"),
+			goqu.C("notify_managers")).
+		Where(goqu.C("operation").Eq(operationID)).
+		Order(goqu.C("trigger").Asc(), goqu.C("open_timeout").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	return m.queryIntelDeliveryEscalationRules(ctx, tx, q)
+}
+
+// DueIntelDeliveryEscalationRulesByDelivery retrieves all
+// IntelDeliveryEscalationRule entries with the given trigger, that are due for
+// the delivery with the given id. Rules with
+// IntelDeliveryEscalationTriggerOpenTimeout are only due, if the delivery is
+// still active and their timeout elapsed. Deliveries, that were created by a
+// forward-channel, are never escalated as the forwarding delivery is.
+func (m *Mall) DueIntelDeliveryEscalationRulesByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID,
+	trigger IntelDeliveryEscalationTrigger) ([]IntelDeliveryEscalationRule, error) {
+	qb := m.dialect.From(goqu.T("intel_delivery_escalation_rules")).
+		InnerJoin(goqu.T("intel"),
+			goqu.On(goqu.I("intel.operation").Eq(goqu.I("intel_delivery_escalation_rules.operation")))).
+		InnerJoin(goqu.T("intel_deliveries"),
+			goqu.On(goqu.I("intel_deliveries.intel").Eq(goqu.I("intel.id")))).
+		LeftJoin(goqu.T("forwarded_intel_deliveries"),
+			goqu.On(goqu.I("forwarded_intel_deliveries.delivery").Eq(goqu.I("intel_deliveries.id")))).
+		Select(goqu.I("intel_delivery_escalation_rules.id"),
+			goqu.I("intel_delivery_escalation_rules.operation"),
+			goqu.I("intel_delivery_escalation_rules.trigger"),
+			goqu.I("intel_delivery_escalation_rules.open_timeout"),
+			goqu.I("intel_delivery_escalation_rules.escalate_to"),
+			goqu.I("intel_delivery_escalation_rules.notify_managers")).
+		Where(goqu.I("intel_deliveries.id").Eq(deliveryID),
+			goqu.I("intel_delivery_escalation_rules.trigger").Eq(string(trigger)),
+			goqu.I("forwarded_intel_deliveries.delivery").IsNull())
+	if trigger == IntelDeliveryEscalationTriggerOpenTimeout {
+		qb = qb.Where(goqu.I("intel_deliveries.is_active").IsTrue(),
+			goqu.L("greatest(intel_deliveries.created_at, intel_deliveries.deliver_at)").
+				Lte(goqu.L("(now() at time zone 'utc') - interval '1 ms' * intel_delivery_escalation_rules.open_timeout / 1000000")))
+	}
+	q, _, err := qb.Order(goqu.I("intel_delivery_escalation_rules.open_timeout").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	return m.queryIntelDeliveryEscalationRules(ctx, tx, q)
+}
+
+// queryIntelDeliveryEscalationRules runs the given query and scans the
+// resulting IntelDeliveryEscalationRule list. Selected columns are expected to
+// be in the order of the fields of IntelDeliveryEscalationRule.
+func (m *Mall) queryIntelDeliveryEscalationRules(ctx context.Context, tx pgx.Tx, q string) ([]IntelDeliveryEscalationRule, error) {
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	rules := make([]IntelDeliveryEscalationRule, 0)
+	for rows.Next() {
+		var rule IntelDeliveryEscalationRule
+		err = rows.Scan(&rule.ID,
+			&rule.Operation,
+			&rule.Trigger,
+			&rule.OpenTimeout,
+			&rule.EscalateTo,
+			&rule.NotifyManagers)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		rules = append(rules, rule)
+	}
+	rows.Close()
+	return rules, nil
+}
+
+// UpdateIntelDeliveryEscalationRulesByOperation clears and recreates the
+// escalation rules for the operation with the given id. Escalations of
+// replaced rules are kept but lose their reference to the rule.
+//
+// Warning: No operation existence checks are performed!
+func (m *Mall) UpdateIntelDeliveryEscalationRulesByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID,
+	rules []IntelDeliveryEscalationRule) error {
+	// Delete old rules.
+	deleteQuery, _, err := m.dialect.Delete(goqu.T("intel_delivery_escalation_rules")).
+		Where(goqu.C("operation").Eq(operationID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "delete query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, deleteQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec delete query", deleteQuery)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	// Create new rules.
+	records := make([]any, 0, len(rules))
+	for _, rule := range rules {
+		records = append(records, goqu.Record{
+			"operation":       operationID,
+			"trigger":         rule.Trigger,
+			"open_timeout":    rule.OpenTimeout,
+			"escalate_to":     rule.EscalateTo,
+			"notify_managers": rule.NotifyManagers,
+		})
+	}
+	insertQuery, _, err := m.dialect.Insert(goqu.T("intel_delivery_escalation_rules")).
+		Rows(records...).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "insert query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, insertQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec insert query", insertQuery)
+	}
+	return nil
+}
+
+// CreateIntelDeliveryEscalation creates the given IntelDeliveryEscalation and
+// returns it with its assigned id.
+func (m *Mall) CreateIntelDeliveryEscalation(ctx context.Context, tx pgx.Tx, create IntelDeliveryEscalation) (IntelDeliveryEscalation, error) {
+	q, _, err := m.dialect.Insert(goqu.T("intel_delivery_escalations")).Rows(goqu.Record{
+		"delivery":          create.Delivery,
+		"rule":              create.Rule,
+		"trigger":           create.Trigger,
+		"escalated_at":      create.EscalatedAt.UTC(),
+		"escalated_to":      create.EscalatedTo,
+		"notified_managers": create.NotifiedManagers,
+	}).Returning(goqu.C("id")).ToSQL()
+	if err != nil {
+		return IntelDeliveryEscalation{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return IntelDeliveryEscalation{}, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return IntelDeliveryEscalation{}, mehpg.NewQueryDBErr(err, "exec query", q)
+		}
+		return IntelDeliveryEscalation{}, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
+	}
+	err = rows.Scan(&create.ID)
+	if err != nil {
+		return IntelDeliveryEscalation{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return create, nil
+}
+
+// IntelDeliveryEscalationChainByDelivery retrieves the IntelDeliveryEscalation
+// list for the delivery with the given id. It starts with the escalations, that
+// led to the creation of the delivery (beginning with the original one), and
+// ends with the escalations of the delivery itself.
+func (m *Mall) IntelDeliveryEscalationChainByDelivery(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) ([]IntelDeliveryEscalation, error) {
+	chain := make([]IntelDeliveryEscalation, 0)
+	// Walk up the escalations that led to the delivery. Each delivery is created by
+	// at most one escalation.
+	current := deliveryID
+	visited := map[uuid.UUID]struct{}{current: {}}
+	for {
+		q, _, err := m.dialect.From(goqu.T("intel_delivery_escalations")).
+			Select(intelDeliveryEscalationColumns()...).
+			Where(goqu.C("escalated_to").Eq(current)).ToSQL()
+		if err != nil {
+			return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+		}
+		escalations, err := m.queryIntelDeliveryEscalations(ctx, tx, q)
+		if err != nil {
+			return nil, meh.Wrap(err, "query escalations to delivery", meh.Details{"delivery_id": current})
+		}
+		if len(escalations) == 0 {
+			break
+		}
+		chain = append([]IntelDeliveryEscalation{escalations[0]}, chain...)
+		current = escalations[0].Delivery
+		if _, ok := visited[current]; ok {
+			break
+		}
+		visited[current] = struct{}{}
+	}
+	// Add escalations of the delivery itself.
+	q, _, err := m.dialect.From(goqu.T("intel_delivery_escalations")).
+		Select(intelDeliveryEscalationColumns()...).
+		Where(goqu.C("delivery").Eq(deliveryID)).
+		Order(goqu.C("escalated_at").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	escalations, err := m.queryIntelDeliveryEscalations(ctx, tx, q)
+	if err != nil {
+		return nil, meh.Wrap(err, "query escalations of delivery", meh.Details{"delivery_id": deliveryID})
+	}
+	chain = append(chain, escalations...)
+	return chain, nil
+}
+
+// intelDeliveryEscalationColumns returns the columns to select for scanning
+// with queryIntelDeliveryEscalations.
+func intelDeliveryEscalationColumns() []any {
+	return []any{
+		goqu.C("id"),
+		goqu.C("delivery"),
+		goqu.C("rule"),
+		goqu.C("trigger"),
+		goqu.C("escalated_at"),
+		goqu.C("escalated_to"),
+		goqu.C("notified_managers"),
+	}
+}
+
+// queryIntelDeliveryEscalations runs the given query and scans the resulting
+// IntelDeliveryEscalation list. Selected columns are expected to be the ones
+// from intelDeliveryEscalationColumns.
+func (m *Mall) queryIntelDeliveryEscalations(ctx context.Context, tx pgx.Tx, q string) ([]IntelDeliveryEscalation, error) {
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	escalations := make([]IntelDeliveryEscalation, 0)
+	for rows.Next() {
+		var escalation IntelDeliveryEscalation
+		err = rows.Scan(&escalation.ID,
+			&escalation.Delivery,
+			&escalation.Rule,
+			&escalation.Trigger,
+			&escalation.EscalatedAt,
+			&escalation.EscalatedTo,
+			&escalation.NotifiedManagers)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		escalations = append(escalations, escalation)
+	}
+	rows.Close()
+	return escalations, nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// IntelDeliveryEscalationRuleValidateSuite tests
+// IntelDeliveryEscalationRule.Validate.
+type IntelDeliveryEscalationRuleValidateSuite struct {
+	suite.Suite
+	ok IntelDeliveryEscalationRule
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) SetupTest() {
+	suite.ok = IntelDeliveryEscalationRule{
+		ID:             testutil.NewUUIDV4(),
+		Operation:      testutil.NewUUIDV4(),
+		Trigger:        IntelDeliveryEscalationTriggerOpenTimeout,
+		OpenTimeout:    15 * time.Minute,
+		EscalateTo:     nulls.NewUUID(testutil.NewUUIDV4()),
+		NotifyManagers: true,
+	}
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestUnknownTrigger() {
+	suite.ok.Trigger = "meow"
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestOpenTimeoutWithoutTimeout() {
+	suite.ok.OpenTimeout = 0
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestFailedWithTimeout() {
+	suite.ok.Trigger = IntelDeliveryEscalationTriggerFailed
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestNoAction() {
+	suite.ok.EscalateTo = uuid.NullUUID{}
+	suite.ok.NotifyManagers = false
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestOKFailed() {
+	suite.ok.Trigger = IntelDeliveryEscalationTriggerFailed
+	suite.ok.OpenTimeout = 0
+
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.True(report.IsOK(), "report should be ok")
+}
+
+func (suite *IntelDeliveryEscalationRuleValidateSuite) TestOK() {
+	report, err := suite.ok.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.True(report.IsOK(), "report should be ok")
+}
+
+func TestIntelDeliveryEscalationRule_Validate(t *testing.T) {
+	suite.Run(t, new(IntelDeliveryEscalationRuleValidateSuite))
+}
@@ -0,0 +1,391 @@
+package store
+
+import (
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// nextChannelForDeliveryAttemptSuite tests nextChannelForDeliveryAttempt.
+type nextChannelForDeliveryAttemptSuite struct {
+	suite.Suite
+	high       Channel
+	low        Channel
+	deliveryTS time.Time
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) SetupTest() {
+	suite.high = Channel{
+		ID:       testutil.NewUUIDV4(),
+		Priority: 20,
+		RetryPolicy: ChannelRetryPolicy{
+			MaxAttempts:    3,
+			Backoff:        2 * time.Minute,
+			RetryOnTimeout: true,
+			RetryOnFailure: false,
+		},
+	}
+	suite.low = Channel{
+		ID:          testutil.NewUUIDV4(),
+		Priority:    10,
+		RetryPolicy: DefaultChannelRetryPolicy,
+	}
+	suite.deliveryTS = time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+}
+
+// attempt creates an inactive IntelDeliveryAttempt for the given channel with
+// the given status, ending the given number of minutes after deliveryTS.
+func (suite *nextChannelForDeliveryAttemptSuite) attempt(channel Channel, status IntelDeliveryStatus, minutes int) IntelDeliveryAttempt {
+	return IntelDeliveryAttempt{
+		ID:        testutil.NewUUIDV4(),
+		Channel:   channel.ID,
+		CreatedAt: suite.deliveryTS.Add(time.Duration(minutes-1) * time.Minute),
+		IsActive:  false,
+		Status:    status,
+		StatusTS:  suite.deliveryTS.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestNoCandidates() {
+	_, _, ok := nextChannelForDeliveryAttempt(nil, nil)
+	suite.False(ok, "should not return channel")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestNoPastAttempts() {
+	channel, notBefore, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, nil)
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should return highest priority channel")
+	suite.True(notBefore.IsZero(), "should not wait")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestRetryAfterTimeout() {
+	channel, notBefore, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusTimeout, 5),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should retry high priority channel")
+	suite.Equal(suite.deliveryTS.Add(7*time.Minute), notBefore, "should respect backoff")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestNoRetryAfterFailure() {
+	channel, notBefore, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusFailed, 5),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.low, channel, "should fall through to low priority channel")
+	suite.True(notBefore.IsZero(), "should not wait")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestRetryAfterFailure() {
+	suite.high.RetryPolicy.RetryOnFailure = true
+	channel, _, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusFailed, 5),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should retry high priority channel")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestNoRetryAfterCancel() {
+	channel, _, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusCanceled, 5),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.low, channel, "should fall through to low priority channel")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestLastAttemptDecides() {
+	suite.high.RetryPolicy.MaxAttempts = 5
+	channel, notBefore, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusTimeout, 9),
+		suite.attempt(suite.high, IntelDeliveryStatusFailed, 5),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should retry high priority channel")
+	suite.Equal(suite.deliveryTS.Add(11*time.Minute), notBefore, "should respect backoff from last attempt")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestMaxAttemptsReached() {
+	channel, _, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusTimeout, 5),
+		suite.attempt(suite.high, IntelDeliveryStatusTimeout, 10),
+		suite.attempt(suite.high, IntelDeliveryStatusTimeout, 15),
+	})
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.low, channel, "should fall through to low priority channel")
+}
+
+func (suite *nextChannelForDeliveryAttemptSuite) TestAllExhausted() {
+	_, _, ok := nextChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, []IntelDeliveryAttempt{
+		suite.attempt(suite.high, IntelDeliveryStatusFailed, 5),
+		suite.attempt(suite.low, IntelDeliveryStatusTimeout, 10),
+	})
+	suite.False(ok, "should not return channel")
+}
+
+func Test_nextChannelForDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(nextChannelForDeliveryAttemptSuite))
+}
+
+// orderChannelsByPresenceSuite tests orderChannelsByPresence.
+type orderChannelsByPresenceSuite struct {
+	suite.Suite
+	high   Channel
+	inApp  Channel
+	low    Channel
+	lowest Channel
+}
+
+func (suite *orderChannelsByPresenceSuite) SetupTest() {
+	suite.high = Channel{
+		ID:             testutil.NewUUIDV4(),
+		Type:           ChannelTypePhoneCall,
+		Priority:       30,
+		PresencePolicy: ChannelPresencePolicyIgnore,
+	}
+	suite.inApp = Channel{
+		ID:             testutil.NewUUIDV4(),
+		Type:           ChannelTypeInAppNotification,
+		Priority:       20,
+		PresencePolicy: ChannelPresencePolicyIgnore,
+	}
+	suite.low = Channel{
+		ID:             testutil.NewUUIDV4(),
+		Type:           ChannelTypeRadio,
+		Priority:       10,
+		PresencePolicy: ChannelPresencePolicyIgnore,
+	}
+	suite.lowest = Channel{
+		ID:             testutil.NewUUIDV4(),
+		Type:           ChannelTypeEmail,
+		Priority:       0,
+		PresencePolicy: ChannelPresencePolicyIgnore,
+	}
+}
+
+func (suite *orderChannelsByPresenceSuite) candidates() []Channel {
+	return []Channel{suite.high, suite.inApp, suite.low, suite.lowest}
+}
+
+func (suite *orderChannelsByPresenceSuite) TestIgnore() {
+	suite.Equal(suite.candidates(), orderChannelsByPresence(suite.candidates(), true), "should keep order if online")
+	suite.Equal(suite.candidates(), orderChannelsByPresence(suite.candidates(), false), "should keep order if offline")
+}
+
+func (suite *orderChannelsByPresenceSuite) TestPreferOnline() {
+	suite.inApp.PresencePolicy = ChannelPresencePolicyPreferOnline
+	suite.Equal([]Channel{suite.inApp, suite.high, suite.low, suite.lowest}, orderChannelsByPresence(suite.candidates(), true),
+		"should prefer if online")
+	suite.Equal(suite.candidates(), orderChannelsByPresence(suite.candidates(), false), "should keep order if offline")
+}
+
+func (suite *orderChannelsByPresenceSuite) TestDeprioritizeOffline() {
+	suite.inApp.PresencePolicy = ChannelPresencePolicyDeprioritizeOffline
+	suite.Equal([]Channel{suite.inApp, suite.high, suite.low, suite.lowest}, orderChannelsByPresence(suite.candidates(), true),
+		"should prefer if online")
+	suite.Equal([]Channel{suite.high, suite.low, suite.lowest, suite.inApp}, orderChannelsByPresence(suite.candidates(), false),
+		"should deprioritize if offline")
+}
+
+func (suite *orderChannelsByPresenceSuite) TestSkipOffline() {
+	suite.inApp.PresencePolicy = ChannelPresencePolicySkipOffline
+	suite.Equal([]Channel{suite.inApp, suite.high, suite.low, suite.lowest}, orderChannelsByPresence(suite.candidates(), true),
+		"should prefer if online")
+	suite.Equal([]Channel{suite.high, suite.low, suite.lowest}, orderChannelsByPresence(suite.candidates(), false),
+		"should skip if offline")
+}
+
+func (suite *orderChannelsByPresenceSuite) TestKeepPriorityWithinPreferred() {
+	suite.inApp.PresencePolicy = ChannelPresencePolicyPreferOnline
+	suite.lowest.Type = ChannelTypeInAppNotification
+	suite.lowest.PresencePolicy = ChannelPresencePolicySkipOffline
+	suite.Equal([]Channel{suite.inApp, suite.lowest, suite.high, suite.low}, orderChannelsByPresence(suite.candidates(), true),
+		"should keep priority order within preferred channels")
+}
+
+func Test_orderChannelsByPresence(t *testing.T) {
+	suite.Run(t, new(orderChannelsByPresenceSuite))
+}
+
+// nextAvailableChannelForDeliveryAttemptSuite tests
+// nextAvailableChannelForDeliveryAttempt.
+type nextAvailableChannelForDeliveryAttemptSuite struct {
+	suite.Suite
+	high  Channel
+	low   Channel
+	now   time.Time
+	later time.Time
+}
+
+func (suite *nextAvailableChannelForDeliveryAttemptSuite) SetupTest() {
+	suite.now = time.Date(2022, 5, 2, 4, 0, 0, 0, time.UTC)
+	suite.later = time.Date(2022, 5, 2, 6, 0, 0, 0, time.UTC)
+	suite.high = Channel{
+		ID:          testutil.NewUUIDV4(),
+		Priority:    20,
+		RetryPolicy: DefaultChannelRetryPolicy,
+		Availability: ChannelAvailability{
+			Windows: []ChannelAvailabilityWindow{
+				{
+					Weekday: suite.now.Weekday(),
+					From:    6 * time.Hour,
+					To:      18 * time.Hour,
+				},
+			},
+		},
+	}
+	suite.low = Channel{
+		ID:          testutil.NewUUIDV4(),
+		Priority:    10,
+		RetryPolicy: DefaultChannelRetryPolicy,
+	}
+}
+
+func (suite *nextAvailableChannelForDeliveryAttemptSuite) TestSkipUnavailable() {
+	channel, notBefore, ok, err := nextAvailableChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, nil, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.low, channel, "should skip unavailable channel")
+	suite.True(notBefore.IsZero(), "should not wait")
+}
+
+func (suite *nextAvailableChannelForDeliveryAttemptSuite) TestAvailable() {
+	channel, _, ok, err := nextAvailableChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, nil, suite.later)
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should use available channel")
+}
+
+func (suite *nextAvailableChannelForDeliveryAttemptSuite) TestWaitForAvailability() {
+	pastAttempts := []IntelDeliveryAttempt{
+		{
+			ID:        testutil.NewUUIDV4(),
+			Channel:   suite.low.ID,
+			CreatedAt: suite.now.Add(-2 * time.Minute),
+			IsActive:  false,
+			Status:    IntelDeliveryStatusTimeout,
+			StatusTS:  suite.now.Add(-time.Minute),
+		},
+	}
+	channel, notBefore, ok, err := nextAvailableChannelForDeliveryAttempt([]Channel{suite.high, suite.low}, pastAttempts, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.Require().True(ok, "should return channel")
+	suite.Equal(suite.high, channel, "should return channel that becomes available")
+	suite.True(suite.later.Equal(notBefore), "should wait until channel becomes available")
+}
+
+func (suite *nextAvailableChannelForDeliveryAttemptSuite) TestNeverAvailable() {
+	suite.high.Availability.Exceptions = []ChannelAvailabilityException{
+		{
+			From:        suite.now.Add(-time.Hour),
+			To:          suite.now.Add(365 * 24 * time.Hour),
+			IsAvailable: false,
+		},
+	}
+	_, _, ok, err := nextAvailableChannelForDeliveryAttempt([]Channel{suite.high}, nil, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not return channel")
+}
+
+func Test_nextAvailableChannelForDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(nextAvailableChannelForDeliveryAttemptSuite))
+}
+
+// fanOutChannelsForDeliveryAttemptsSuite tests
+// fanOutChannelsForDeliveryAttempts.
+type fanOutChannelsForDeliveryAttemptsSuite struct {
+	suite.Suite
+	first  Channel
+	second Channel
+	now    time.Time
+}
+
+func (suite *fanOutChannelsForDeliveryAttemptsSuite) SetupTest() {
+	suite.now = time.Date(2022, 5, 2, 4, 0, 0, 0, time.UTC)
+	suite.first = Channel{
+		ID:          testutil.NewUUIDV4(),
+		Priority:    20,
+		RetryPolicy: DefaultChannelRetryPolicy,
+	}
+	suite.second = Channel{
+		ID:       testutil.NewUUIDV4(),
+		Priority: 10,
+		RetryPolicy: ChannelRetryPolicy{
+			MaxAttempts:    2,
+			Backoff:        time.Hour,
+			RetryOnTimeout: true,
+		},
+	}
+}
+
+func (suite *fanOutChannelsForDeliveryAttemptsSuite) TestAllWithoutPastAttempts() {
+	channels, nextUsableLater, ok, err := fanOutChannelsForDeliveryAttempts([]Channel{suite.first, suite.second}, nil, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should return channels")
+	suite.Equal([]Channel{suite.first, suite.second}, channels, "should return all channels")
+	suite.True(nextUsableLater.IsZero(), "should not return time for channels being usable later")
+}
+
+func (suite *fanOutChannelsForDeliveryAttemptsSuite) TestSkipActive() {
+	pastAttempts := []IntelDeliveryAttempt{
+		{
+			ID:        testutil.NewUUIDV4(),
+			Channel:   suite.first.ID,
+			CreatedAt: suite.now.Add(-time.Minute),
+			IsActive:  true,
+			Status:    IntelDeliveryStatusAwaitingAck,
+			StatusTS:  suite.now.Add(-time.Minute),
+		},
+	}
+	channels, _, ok, err := fanOutChannelsForDeliveryAttempts([]Channel{suite.first, suite.second}, pastAttempts, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should return channels")
+	suite.Equal([]Channel{suite.second}, channels, "should skip channel with active attempt")
+}
+
+func (suite *fanOutChannelsForDeliveryAttemptsSuite) TestWaitForBackoff() {
+	pastAttempts := []IntelDeliveryAttempt{
+		{
+			ID:        testutil.NewUUIDV4(),
+			Channel:   suite.first.ID,
+			CreatedAt: suite.now.Add(-time.Hour),
+			IsActive:  false,
+			Status:    IntelDeliveryStatusTimeout,
+			StatusTS:  suite.now.Add(-time.Minute),
+		},
+		{
+			ID:        testutil.NewUUIDV4(),
+			Channel:   suite.second.ID,
+			CreatedAt: suite.now.Add(-time.Hour),
+			IsActive:  false,
+			Status:    IntelDeliveryStatusTimeout,
+			StatusTS:  suite.now.Add(-time.Minute),
+		},
+	}
+	channels, nextUsableLater, ok, err := fanOutChannelsForDeliveryAttempts([]Channel{suite.first, suite.second}, pastAttempts, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should report channel being usable later")
+	suite.Empty(channels, "should not return channels")
+	suite.Equal(suite.now.Add(-time.Minute).Add(suite.second.RetryPolicy.Backoff), nextUsableLater,
+		"should return time when backoff elapses")
+}
+
+func (suite *fanOutChannelsForDeliveryAttemptsSuite) TestNoMoreChannels() {
+	pastAttempts := []IntelDeliveryAttempt{
+		{
+			ID:        testutil.NewUUIDV4(),
+			Channel:   suite.first.ID,
+			CreatedAt: suite.now.Add(-time.Hour),
+			IsActive:  false,
+			Status:    IntelDeliveryStatusFailed,
+			StatusTS:  suite.now.Add(-time.Minute),
+		},
+	}
+	_, _, ok, err := fanOutChannelsForDeliveryAttempts([]Channel{suite.first}, pastAttempts, suite.now)
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not return channels")
+}
+
+func Test_fanOutChannelsForDeliveryAttempts(t *testing.T) {
+	suite.Run(t, new(fanOutChannelsForDeliveryAttemptsSuite))
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// intelExpiryPending is the condition for intel, that is not expired, yet, but
+// will expire at Intel.ValidUntil.
+func intelExpiryPending() exp.Expression {
+	return goqu.And(goqu.C("is_valid").IsTrue(),
+		goqu.C("is_expired").IsFalse(),
+		goqu.C("valid_until").IsNotNull())
+}
+
+// DueIntelExpiries retrieves the ids of at most the given limit of valid intel,
+// that is not marked as expired, yet, although Intel.ValidUntil was reached.
+func (m *Mall) DueIntelExpiries(ctx context.Context, tx pgx.Tx, limit int) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("intel")).
+		Select(goqu.C("id")).
+		Where(intelExpiryPending(),
+			goqu.C("valid_until").Lte(goqu.L("(now() at time zone 'utc')"))).
+		Order(goqu.C("valid_until").Asc()).
+		Limit(uint(limit)).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	intelIDs := make([]uuid.UUID, 0, limit)
+	for rows.Next() {
+		var intelID uuid.UUID
+		err = rows.Scan(&intelID)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		intelIDs = append(intelIDs, intelID)
+	}
+	rows.Close()
+	return intelIDs, nil
+}
+
+// MarkIntelAsExpiredIfDue marks the intel with the given id as expired, if it
+// is valid, not expired, yet, and Intel.ValidUntil was reached. If the intel
+// was not marked, false is returned. As the intel is locked while updating,
+// concurrent calls for the same intel return true only once.
+func (m *Mall) MarkIntelAsExpiredIfDue(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) (bool, error) {
+	q, _, err := m.dialect.Update(goqu.T("intel")).Set(goqu.Record{
+		"is_expired": true,
+	}).Where(goqu.C("id").Eq(intelID),
+		intelExpiryPending(),
+		goqu.C("valid_until").Lte(goqu.L("(now() at time zone 'utc')"))).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+	// Update in search.
+	err = m.addOrUpdateIntelInSearch(ctx, tx, intelID)
+	if err != nil {
+		return false, meh.Wrap(err, "update intel in search", meh.Details{"intel_id": intelID})
+	}
+	return true, nil
+}
+
+// NextIntelExpiry retrieves the earliest Intel.ValidUntil of all valid intel,
+// that is not expired, yet. If no intel expires, false is returned.
+func (m *Mall) NextIntelExpiry(ctx context.Context, tx pgx.Tx) (time.Time, bool, error) {
+	q, _, err := m.dialect.From(goqu.T("intel")).
+		Select(goqu.MIN(goqu.C("valid_until"))).
+		Where(intelExpiryPending()).ToSQL()
+	if err != nil {
+		return time.Time{}, false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return time.Time{}, false, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return time.Time{}, false, nil
+	}
+	var nextExpiry nulls.Time
+	err = rows.Scan(&nextExpiry)
+	if err != nil {
+		return time.Time{}, false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return nextExpiry.Time, nextExpiry.Valid, nil
+}
@@ -3,9 +3,11 @@ package store
 import (
 	"encoding/json"
 	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // CreateIntelValidateSuite tests CreateIntel.Validate.
@@ -37,7 +39,17 @@ func (suite *CreateIntelValidateSuite) TestDuplicateInitialDeliverToEntries() {
 	suite.False(report.IsOK(), "report should not be ok")
 }
 
+func (suite *CreateIntelValidateSuite) TestValidUntilInPast() {
+	suite.sampleCreateIntel.ValidUntil = nulls.NewTime(time.Now().Add(-time.Minute))
+
+	report, err := suite.sampleCreateIntel.Validate()
+	suite.Require().NoError(err, "should not fail")
+	suite.False(report.IsOK(), "report should not be ok")
+}
+
 func (suite *CreateIntelValidateSuite) TestOK() {
+	suite.sampleCreateIntel.ValidUntil = nulls.NewTime(time.Now().Add(time.Hour))
+
 	report, err := suite.sampleCreateIntel.Validate()
 	suite.Require().NoError(err, "should not fail")
 	suite.True(report.IsOK(), "report should be ok")
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/entityvalidation"
+	"time"
+)
+
+// AmendIntel for amending existing intel by creating a new version of it.
+type AmendIntel struct {
+	// Intel is the id of the intel to amend.
+	Intel uuid.UUID
+	// CreatedBy is the id of the user, who amends the intel.
+	CreatedBy uuid.UUID
+	// Type of the new version.
+	Type IntelType
+	// Content of the new version.
+	Content json.RawMessage
+	// SearchText for better searching. Used with higher priority than Content.
+	SearchText nulls.String
+	// Importance of the new version.
+	Importance int
+	// ValidUntil is the optional timestamp after which the new version expires.
+	ValidUntil nulls.Time
+	// RedeliverToDelivered schedules deliveries of the new version to all address
+	// book entries, the amended intel was already delivered to.
+	RedeliverToDelivered bool
+}
+
+// Validate the AmendIntel for Type and Content.
+func (i AmendIntel) Validate() (entityvalidation.Report, error) {
+	report, err := validateCreateIntelTypeAndContent(i.Type, i.Content)
+	if err != nil {
+		return entityvalidation.Report{}, meh.Wrap(err, "validate intel-type and content", nil)
+	}
+	if i.ValidUntil.Valid && !i.ValidUntil.Time.After(time.Now()) {
+		report.AddError("valid-until must be in the future")
+	}
+	return report, nil
+}
+
+// AmendIntel creates a new version of the intel from AmendIntel.Intel and marks
+// the amended one as invalid. The new version is assigned to the same
+// operation. If the intel was already amended, a meh.ErrBadInput is returned.
+func (m *Mall) AmendIntel(ctx context.Context, tx pgx.Tx, amend AmendIntel) (Intel, error) {
+	// Retrieve version information of the intel to amend.
+	q, _, err := m.dialect.From(goqu.T("intel")).
+		Select(goqu.C("operation"),
+			goqu.COALESCE(goqu.C("first_version"), goqu.C("id")),
+			goqu.C("version")).
+		Where(goqu.C("id").Eq(amend.Intel)).ToSQL()
+	if err != nil {
+		return Intel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return Intel{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Intel{}, meh.NewNotFoundErr("not found", nil)
+	}
+	var operationID uuid.UUID
+	var firstVersion uuid.UUID
+	var previousVersionNum int
+	err = rows.Scan(&operationID, &firstVersion, &previousVersionNum)
+	if err != nil {
+		return Intel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	// Assure not already amended.
+	q, _, err = m.dialect.From(goqu.T("intel")).
+		Select(goqu.C("id")).
+		Where(goqu.C("previous_version").Eq(amend.Intel)).ToSQL()
+	if err != nil {
+		return Intel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err = tx.Query(ctx, q)
+	if err != nil {
+		return Intel{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var amendedBy uuid.UUID
+		err = rows.Scan(&amendedBy)
+		if err != nil {
+			return Intel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		return Intel{}, meh.NewBadInputErr("intel already amended", meh.Details{"amended_by": amendedBy})
+	}
+	rows.Close()
+	// Create new version.
+	intelID, err := m.insertIntel(ctx, tx, goqu.Record{
+		"created_at":       time.Now().UTC(),
+		"created_by":       amend.CreatedBy,
+		"operation":        operationID,
+		"type":             amend.Type,
+		"content":          []byte(amend.Content),
+		"search_text":      amend.SearchText,
+		"importance":       amend.Importance,
+		"is_valid":         true,
+		"previous_version": amend.Intel,
+		"first_version":    firstVersion,
+		"version":          previousVersionNum + 1,
+		"valid_until":      utcNullTime(amend.ValidUntil),
+	})
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "insert intel", nil)
+	}
+	// Invalidate the amended one. This also updates the search.
+	err = m.InvalidateIntelByID(ctx, tx, amend.Intel)
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "invalidate amended intel", meh.Details{"intel_id": amend.Intel})
+	}
+	created, err := m.IntelByID(ctx, tx, intelID)
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "created intel by id", meh.Details{"intel_id": intelID})
+	}
+	// Create in search.
+	err = m.addOrUpdateIntelInSearch(ctx, tx, intelID)
+	if err != nil {
+		return Intel{}, meh.Wrap(err, "add or update intel in search", meh.Details{"intel_id": intelID})
+	}
+	return created, nil
+}
+
+// IntelVersionsByIntel retrieves all versions of the intel with the given id,
+// sorted ascending by version. This includes the intel itself as well as
+// previous and following versions. If the intel was not found, a
+// meh.ErrNotFound is returned.
+func (m *Mall) IntelVersionsByIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]Intel, error) {
+	firstVersion := m.dialect.From(goqu.T("intel").As("requested")).
+		Select(goqu.COALESCE(goqu.I("requested.first_version"), goqu.I("requested.id"))).
+		Where(goqu.I("requested.id").Eq(intelID))
+	q, _, err := m.dialect.From(goqu.T("intel")).
+		Select(goqu.C("id"),
+			goqu.C("created_at"),
+			goqu.C("created_by"),
+			goqu.C("operation"),
+			goqu.C("type"),
+			goqu.C("content"),
+			goqu.C("search_text"),
+			goqu.C("importance"),
+			goqu.C("is_valid"),
+			goqu.C("previous_version"),
+			goqu.C("version"),
+			goqu.C("valid_until"),
+			goqu.C("is_expired")).
+		Where(goqu.Or(
+			goqu.C("id").Eq(firstVersion),
+			goqu.C("first_version").Eq(firstVersion))).
+		Order(goqu.C("version").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	versions := make([]Intel, 0)
+	for rows.Next() {
+		var intel Intel
+		err = rows.Scan(&intel.ID,
+			&intel.CreatedAt,
+			&intel.CreatedBy,
+			&intel.Operation,
+			&intel.Type,
+			&intel.Content,
+			&intel.SearchText,
+			&intel.Importance,
+			&intel.IsValid,
+			&intel.PreviousVersion,
+			&intel.Version,
+			&intel.ValidUntil,
+			&intel.IsExpired)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		versions = append(versions, intel)
+	}
+	rows.Close()
+	if len(versions) == 0 {
+		return nil, meh.NewNotFoundErr("not found", nil)
+	}
+	return versions, nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"time"
+)
+
+// UserPresence describes whether a user is currently connected.
+type UserPresence struct {
+	// User is the id of the user.
+	User uuid.UUID
+	// IsOnline describes whether the user is currently online.
+	IsOnline bool
+	// LastSeen is the timestamp of the last presence change.
+	LastSeen time.Time
+}
+
+// UpdateUserPresence sets the given UserPresence. If the stored presence is
+// newer, based on UserPresence.LastSeen, the update is discarded, as presence
+// updates might be received out of order.
+func (m *Mall) UpdateUserPresence(ctx context.Context, tx pgx.Tx, presence UserPresence) error {
+	q, _, err := m.dialect.Insert(goqu.T("user_presence")).Rows(goqu.Record{
+		"user":      presence.User,
+		"is_online": presence.IsOnline,
+		"last_seen": presence.LastSeen,
+	}).OnConflict(goqu.DoUpdate(`"user"`, goqu.Record{
+		"is_online": goqu.I("excluded.is_online"),
+		"last_seen": goqu.I("excluded.last_seen"),
+	}).Where(goqu.I("user_presence.last_seen").Lte(goqu.I("excluded.last_seen")))).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
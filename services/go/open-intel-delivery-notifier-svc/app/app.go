@@ -85,7 +85,7 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
@@ -94,7 +94,7 @@ func Run(ctx context.Context) error {
 	wsHub := wsutil.NewHub(egCtx, logger.Named("ws-hub"), ws.Gatekeeper(), ws.ConnListener(logger.Named("conn-listener"), ctrl))
 	// Serve endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, wsHub)
+		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, wsHub, kafkaConnector.DeadLetterAdmin(sqlDB))
 		return meh.NilOrWrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 	})
 	// Run Kafka connector.
@@ -0,0 +1,4 @@
+-- Add time to deliver scheduled intel deliveries at.
+
+alter table active_intel_deliveries
+    add column deliver_at timestamp;
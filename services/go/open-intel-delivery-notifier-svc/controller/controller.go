@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/open-intel-delivery-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"go.uber.org/zap"
@@ -47,6 +48,9 @@ type Store interface {
 	// DeleteActiveIntelDeliveryByID deletes the ActiveIntelDelivery with the given
 	// id.
 	DeleteActiveIntelDeliveryByID(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID) error
+	// UpdateActiveIntelDeliveryDeliverAt updates the
+	// store.ActiveIntelDelivery.DeliverAt for the delivery with the given id.
+	UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error
 	// CreateActiveIntelDeliveryAttempt creates the given ActiveIntelDeliveryAttempt.
 	CreateActiveIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, create store.ActiveIntelDeliveryAttempt) error
 	// DeleteActiveIntelDeliveryAttemptByID deletes the ActiveIntelDeliveryAttempt
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/open-intel-delivery-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/mock"
@@ -63,6 +64,10 @@ func (m *StoreMock) DeleteActiveIntelDeliveryByID(ctx context.Context, tx pgx.Tx
 	return m.Called(ctx, tx, deliveryID).Error(0)
 }
 
+func (m *StoreMock) UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	return m.Called(ctx, tx, deliveryID, deliverAt).Error(0)
+}
+
 func (m *StoreMock) CreateActiveIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, create store.ActiveIntelDeliveryAttempt) error {
 	return m.Called(ctx, tx, create).Error(0)
 }
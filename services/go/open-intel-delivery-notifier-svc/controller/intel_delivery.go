@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/open-intel-delivery-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"go.uber.org/atomic"
@@ -53,10 +54,11 @@ func copyOpenDeliveries(openDeliveries []store.OpenIntelDeliverySummary) []store
 	for _, openDelivery := range openDeliveries {
 		copied = append(copied, store.OpenIntelDeliverySummary{
 			Delivery: store.ActiveIntelDelivery{
-				ID:    openDelivery.Delivery.ID,
-				Intel: openDelivery.Delivery.Intel,
-				To:    openDelivery.Delivery.To,
-				Note:  openDelivery.Delivery.Note,
+				ID:        openDelivery.Delivery.ID,
+				Intel:     openDelivery.Delivery.Intel,
+				To:        openDelivery.Delivery.To,
+				Note:      openDelivery.Delivery.Note,
+				DeliverAt: openDelivery.Delivery.DeliverAt,
 			},
 			Intel: store.Intel{
 				ID:         openDelivery.Intel.ID,
@@ -313,6 +315,31 @@ func (c *Controller) DeleteActiveIntelDeliveryByID(ctx context.Context, delivery
 	return nil
 }
 
+// UpdateActiveIntelDeliveryDeliverAt updates the time to deliver the active
+// intel delivery with the given id at.
+func (c *Controller) UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	var operationID uuid.UUID
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.store.UpdateActiveIntelDeliveryDeliverAt(ctx, tx, deliveryID, deliverAt)
+		if err != nil {
+			return meh.Wrap(err, "update active intel delivery deliver-at in store", meh.Details{
+				"delivery_id": deliveryID,
+				"deliver_at":  deliverAt,
+			})
+		}
+		operationID, err = c.store.IntelOperationByDelivery(ctx, tx, deliveryID)
+		if err != nil {
+			return meh.Wrap(err, "intel operation by delivery", meh.Details{"delivery_id": deliveryID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	c.notifyIntelDeliveryChanged(operationID)
+	return nil
+}
+
 // CreateActiveIntelDeliveryAttempt creates the given
 // store.ActiveIntelDeliveryAttempt.
 func (c *Controller) CreateActiveIntelDeliveryAttempt(ctx context.Context, create store.ActiveIntelDeliveryAttempt) error {
@@ -575,6 +575,72 @@ func TestController_DeleteActiveIntelDeliveryByID(t *testing.T) {
 	suite.Run(t, new(ControllerDeleteActiveIntelDeliveryByIDSuite))
 }
 
+// ControllerUpdateActiveIntelDeliveryDeliverAtSuite tests
+// Controller.UpdateActiveIntelDeliveryDeliverAt.
+type ControllerUpdateActiveIntelDeliveryDeliverAtSuite struct {
+	suite.Suite
+	c           *ControllerMock
+	deliveryID  uuid.UUID
+	deliverAt   nulls.Time
+	operationID uuid.UUID
+}
+
+func (suite *ControllerUpdateActiveIntelDeliveryDeliverAtSuite) SetupTest() {
+	suite.c = NewMockController()
+	suite.c.DB.GenTx = true
+	suite.deliveryID = testutil.NewUUIDV4()
+	suite.deliverAt = nulls.NewTime(time.Date(2022, 9, 1, 14, 0, 0, 0, time.UTC))
+	suite.operationID = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerUpdateActiveIntelDeliveryDeliverAtSuite) TestBeginTxFail() {
+	suite.c.DB.BeginFail = true
+
+	err := suite.c.Ctrl.UpdateActiveIntelDeliveryDeliverAt(context.Background(), suite.deliveryID, suite.deliverAt)
+	suite.Error(err, "should fail")
+}
+
+func (suite *ControllerUpdateActiveIntelDeliveryDeliverAtSuite) TestUpdateFail() {
+	suite.c.Store.On("UpdateActiveIntelDeliveryDeliverAt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.c.Store.AssertExpectations(suite.T())
+
+	err := suite.c.Ctrl.UpdateActiveIntelDeliveryDeliverAt(context.Background(), suite.deliveryID, suite.deliverAt)
+	suite.Error(err, "should fail")
+}
+
+func (suite *ControllerUpdateActiveIntelDeliveryDeliverAtSuite) TestRetrieveOperationIDFail() {
+	suite.c.Store.On("UpdateActiveIntelDeliveryDeliverAt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	suite.c.Store.On("IntelOperationByDelivery", mock.Anything, mock.Anything, mock.Anything).
+		Return(uuid.UUID{}, errors.New("sad life"))
+	defer suite.c.Store.AssertExpectations(suite.T())
+
+	err := suite.c.Ctrl.UpdateActiveIntelDeliveryDeliverAt(context.Background(), suite.deliveryID, suite.deliverAt)
+	suite.Error(err, "should fail")
+}
+
+func (suite *ControllerUpdateActiveIntelDeliveryDeliverAtSuite) TestOK() {
+	suite.c.Store.On("UpdateActiveIntelDeliveryDeliverAt", mock.Anything, mock.Anything, suite.deliveryID, suite.deliverAt).
+		Return(nil)
+	suite.c.Store.On("IntelOperationByDelivery", mock.Anything, mock.Anything, suite.deliveryID).
+		Return(suite.operationID, nil)
+	defer suite.c.Store.AssertExpectations(suite.T())
+	w := newWatcher(false)
+	otherW := newWatcher(false)
+	suite.c.Ctrl.openIntelDeliveryWatchersByOperation[suite.operationID] = w
+	suite.c.Ctrl.openIntelDeliveryWatchersByOperation[testutil.NewUUIDV4()] = otherW
+
+	err := suite.c.Ctrl.UpdateActiveIntelDeliveryDeliverAt(context.Background(), suite.deliveryID, suite.deliverAt)
+	suite.NoError(err, "should not fail")
+	suite.True(w.doNotify, "should notify correct watcher")
+	suite.False(otherW.doNotify, "should not notify watchers for other operations")
+}
+
+func TestController_UpdateActiveIntelDeliveryDeliverAt(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateActiveIntelDeliveryDeliverAtSuite))
+}
+
 // ControllerCreateActiveIntelDeliveryAttemptSuite tests
 // Controller.CreateActiveIntelDeliveryAttempt.
 type ControllerCreateActiveIntelDeliveryAttemptSuite struct {
@@ -5,15 +5,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/wsutil"
 	"go.uber.org/zap"
 )
 
 // Serve the endpoints via HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, wsHub wsutil.Hub) error {
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, wsHub wsutil.Hub,
+	deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	r := httpendpoints.NewEngine(logger)
 	populateRoutes(r, logger, authSecret, wsHub)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, r, addr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
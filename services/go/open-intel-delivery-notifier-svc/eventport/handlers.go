@@ -6,6 +6,7 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/open-intel-delivery-notifier-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
@@ -34,6 +35,9 @@ type Handler interface {
 	// DeleteActiveIntelDeliveryByID deletes the intel delivery with the given id
 	// from the store.
 	DeleteActiveIntelDeliveryByID(ctx context.Context, deliveryID uuid.UUID) error
+	// UpdateActiveIntelDeliveryDeliverAt updates the time to deliver the active
+	// intel delivery with the given id at.
+	UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error
 	// CreateActiveIntelDeliveryAttempt creates the given
 	// store.ActiveIntelDeliveryAttempt.
 	CreateActiveIntelDeliveryAttempt(ctx context.Context, create store.ActiveIntelDeliveryAttempt) error
@@ -247,6 +251,8 @@ func (p *Port) handleIntelDeliveriesTopic(ctx context.Context, tx pgx.Tx, handle
 		return meh.NilOrWrap(p.handleIntelDeliveryAttemptStatusUpdated(ctx, tx, handler, message), "handle intel delivery attempt status updated", nil)
 	case event.TypeIntelDeliveryStatusUpdated:
 		return meh.NilOrWrap(p.handleIntelDeliveryStatusUpdated(ctx, tx, handler, message), "handle intel delivery status updated", nil)
+	case event.TypeIntelDeliveryDeliverAtUpdated:
+		return meh.NilOrWrap(p.handleIntelDeliveryDeliverAtUpdated(ctx, tx, handler, message), "handle intel delivery deliver-at updated", nil)
 	case event.TypeAddressBookEntryAutoDeliveryUpdated:
 		return meh.NilOrWrap(p.handleAddressBookEntryAutoDeliveryUpdated(ctx, tx, handler, message), "handle address book entry auto delivery updated", nil)
 	}
@@ -264,10 +270,11 @@ func (p *Port) handleIntelDeliveryCreated(ctx context.Context, _ pgx.Tx, handler
 		return nil
 	}
 	create := store.ActiveIntelDelivery{
-		ID:    createdEvent.ID,
-		Intel: createdEvent.Intel,
-		To:    createdEvent.To,
-		Note:  createdEvent.Note,
+		ID:        createdEvent.ID,
+		Intel:     createdEvent.Intel,
+		To:        createdEvent.To,
+		Note:      createdEvent.Note,
+		DeliverAt: createdEvent.DeliverAt,
 	}
 	err = handler.CreateActiveIntelDelivery(ctx, create)
 	if err != nil {
@@ -340,6 +347,24 @@ func (p *Port) handleIntelDeliveryStatusUpdated(ctx context.Context, _ pgx.Tx, h
 	return nil
 }
 
+// handleIntelDeliveryDeliverAtUpdated handles an
+// event.TypeIntelDeliveryDeliverAtUpdated event.
+func (p *Port) handleIntelDeliveryDeliverAtUpdated(ctx context.Context, _ pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var updatedEvent event.IntelDeliveryDeliverAtUpdated
+	err := json.Unmarshal(message.RawValue, &updatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"was": string(message.RawValue)})
+	}
+	err = handler.UpdateActiveIntelDeliveryDeliverAt(ctx, updatedEvent.ID, updatedEvent.DeliverAt)
+	if err != nil {
+		return meh.Wrap(err, "update active intel delivery deliver-at", meh.Details{
+			"delivery_id": updatedEvent.ID,
+			"deliver_at":  updatedEvent.DeliverAt,
+		})
+	}
+	return nil
+}
+
 // handleAddressBookEntryAutoDeliveryUpdated handles an
 // event.TypeAddressBookEntryAutoDeliveryUpdated event.
 func (p *Port) handleAddressBookEntryAutoDeliveryUpdated(ctx context.Context, _ pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
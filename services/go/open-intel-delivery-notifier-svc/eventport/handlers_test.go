@@ -62,6 +62,10 @@ func (m *HandlerMock) DeleteActiveIntelDeliveryByID(ctx context.Context, deliver
 	return m.Called(ctx, deliveryID).Error(0)
 }
 
+func (m *HandlerMock) UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	return m.Called(ctx, deliveryID, deliverAt).Error(0)
+}
+
 func (m *HandlerMock) CreateActiveIntelDeliveryAttempt(ctx context.Context, create store.ActiveIntelDeliveryAttempt) error {
 	return m.Called(ctx, create).Error(0)
 }
@@ -638,18 +642,20 @@ func (suite *portHandleIntelDeliveryCreatedSuite) SetupTest() {
 	suite.handler = &HandlerMock{}
 	suite.port = newMockPort()
 	suite.sampleEvent = event.IntelDeliveryCreated{
-		ID:       testutil.NewUUIDV4(),
-		Intel:    testutil.NewUUIDV4(),
-		To:       testutil.NewUUIDV4(),
-		IsActive: true,
-		Success:  false,
-		Note:     nulls.String{},
+		ID:        testutil.NewUUIDV4(),
+		Intel:     testutil.NewUUIDV4(),
+		To:        testutil.NewUUIDV4(),
+		IsActive:  true,
+		Success:   false,
+		Note:      nulls.String{},
+		DeliverAt: nulls.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)),
 	}
 	suite.sampleCreate = store.ActiveIntelDelivery{
-		ID:    suite.sampleEvent.ID,
-		Intel: suite.sampleEvent.Intel,
-		To:    suite.sampleEvent.To,
-		Note:  suite.sampleEvent.Note,
+		ID:        suite.sampleEvent.ID,
+		Intel:     suite.sampleEvent.Intel,
+		To:        suite.sampleEvent.To,
+		Note:      suite.sampleEvent.Note,
+		DeliverAt: suite.sampleEvent.DeliverAt,
 	}
 }
 
@@ -943,6 +949,62 @@ func TestPort_handleIntelDeliveryStatusUpdated(t *testing.T) {
 	suite.Run(t, new(portHandleIntelDeliveryStatusUpdatedSuite))
 }
 
+// portHandleIntelDeliveryDeliverAtUpdatedSuite tests
+// Port.handleIntelDeliveryDeliverAtUpdated.
+type portHandleIntelDeliveryDeliverAtUpdatedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.IntelDeliveryDeliverAtUpdated
+}
+
+func (suite *portHandleIntelDeliveryDeliverAtUpdatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.IntelDeliveryDeliverAtUpdated{
+		ID:        testutil.NewUUIDV4(),
+		DeliverAt: nulls.NewTime(time.Date(2022, 9, 1, 14, 0, 0, 0, time.UTC)),
+	}
+}
+
+func (suite *portHandleIntelDeliveryDeliverAtUpdatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.IntelDeliveriesTopic,
+		EventType: event.TypeIntelDeliveryDeliverAtUpdated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleIntelDeliveryDeliverAtUpdatedSuite) TestBadEventValue() {
+	tx := &testutil.DBTx{}
+	err := suite.handle(context.Background(), tx, json.RawMessage(`{invalid`))
+	suite.Error(err, "should fail")
+}
+
+func (suite *portHandleIntelDeliveryDeliverAtUpdatedSuite) TestUpdateFail() {
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateActiveIntelDeliveryDeliverAt", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	err := suite.handle(context.Background(), tx, testutil.MarshalJSONMust(suite.sampleEvent))
+	suite.Error(err, "should fail")
+}
+
+func (suite *portHandleIntelDeliveryDeliverAtUpdatedSuite) TestOK() {
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateActiveIntelDeliveryDeliverAt", mock.Anything, suite.sampleEvent.ID, suite.sampleEvent.DeliverAt).
+		Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	err := suite.handle(context.Background(), tx, testutil.MarshalJSONMust(suite.sampleEvent))
+	suite.NoError(err, "should not fail")
+}
+
+func TestPort_handleIntelDeliveryDeliverAtUpdated(t *testing.T) {
+	suite.Run(t, new(portHandleIntelDeliveryDeliverAtUpdatedSuite))
+}
+
 // portHandleAddressBookEntryAutoDeliveryUpdatedSuite tests
 // Port.handleAddressBookEntryAutoDeliveryUpdated.
 type portHandleAddressBookEntryAutoDeliveryUpdatedSuite struct {
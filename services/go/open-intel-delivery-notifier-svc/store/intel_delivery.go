@@ -8,6 +8,7 @@ import (
 	"github.com/lefinal/meh"
 	"github.com/lefinal/meh/mehpg"
 	"github.com/lefinal/nulls"
+	"time"
 )
 
 // ActiveIntelDelivery represents an intel delivery that is currently considered
@@ -21,6 +22,15 @@ type ActiveIntelDelivery struct {
 	To uuid.UUID
 	// Note contains additional (debug) information.
 	Note nulls.String
+	// DeliverAt is the optional timestamp, before which the delivery is only
+	// scheduled and no delivery attempts are expected.
+	DeliverAt nulls.Time
+}
+
+// IsScheduled describes whether the delivery is scheduled for the future via
+// DeliverAt.
+func (d ActiveIntelDelivery) IsScheduled() bool {
+	return d.DeliverAt.Valid && d.DeliverAt.Time.After(time.Now())
 }
 
 // ActiveIntelDeliveryAttempt represents an intel delivery attempt that is
@@ -105,10 +115,11 @@ func (m *Mall) IntelOperationByDelivery(ctx context.Context, tx pgx.Tx, attemptI
 // CreateActiveIntelDelivery creates the given ActiveIntelDelivery in the store.
 func (m *Mall) CreateActiveIntelDelivery(ctx context.Context, tx pgx.Tx, create ActiveIntelDelivery) error {
 	q, _, err := m.dialect.Insert(goqu.T("active_intel_deliveries")).Rows(goqu.Record{
-		"id":    create.ID,
-		"intel": create.Intel,
-		"to":    create.To,
-		"note":  create.Note,
+		"id":         create.ID,
+		"intel":      create.Intel,
+		"to":         create.To,
+		"note":       create.Note,
+		"deliver_at": utcNullTime(create.DeliverAt),
 	}).ToSQL()
 	_, err = tx.Exec(ctx, q)
 	if err != nil {
@@ -135,6 +146,33 @@ func (m *Mall) DeleteActiveIntelDeliveryByID(ctx context.Context, tx pgx.Tx, del
 	return nil
 }
 
+// UpdateActiveIntelDeliveryDeliverAt updates the ActiveIntelDelivery.DeliverAt
+// for the delivery with the given id.
+func (m *Mall) UpdateActiveIntelDeliveryDeliverAt(ctx context.Context, tx pgx.Tx, deliveryID uuid.UUID, deliverAt nulls.Time) error {
+	q, _, err := m.dialect.Update(goqu.T("active_intel_deliveries")).Set(goqu.Record{
+		"deliver_at": utcNullTime(deliverAt),
+	}).Where(goqu.C("id").Eq(deliveryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	return nil
+}
+
+// utcNullTime converts the given nulls.Time to UTC if set.
+func utcNullTime(t nulls.Time) nulls.Time {
+	if !t.Valid {
+		return t
+	}
+	return nulls.NewTime(t.Time.UTC())
+}
+
 // CreateActiveIntelDeliveryAttempt creates the given ActiveIntelDeliveryAttempt.
 func (m *Mall) CreateActiveIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, create ActiveIntelDeliveryAttempt) error {
 	q, _, err := m.dialect.Insert(goqu.T("active_intel_delivery_attempts")).Rows(goqu.Record{
@@ -229,6 +267,7 @@ func (m *Mall) OpenIntelDeliveriesByOperation(ctx context.Context, tx pgx.Tx, op
 			goqu.I("active_intel_deliveries.intel"),
 			goqu.I("active_intel_deliveries.to"),
 			goqu.I("active_intel_deliveries.note"),
+			goqu.I("active_intel_deliveries.deliver_at"),
 			goqu.I("intel.id"),
 			goqu.I("intel.created_at"),
 			goqu.I("intel.created_by"),
@@ -257,6 +296,7 @@ func (m *Mall) OpenIntelDeliveriesByOperation(ctx context.Context, tx pgx.Tx, op
 			&delivery.Delivery.Intel,
 			&delivery.Delivery.To,
 			&delivery.Delivery.Note,
+			&delivery.Delivery.DeliverAt,
 			&delivery.Intel.ID,
 			&delivery.Intel.CreatedAt,
 			&delivery.Intel.CreatedBy,
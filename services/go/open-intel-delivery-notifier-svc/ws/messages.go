@@ -52,10 +52,12 @@ type publicOpenIntelDeliverySummary struct {
 // publicActiveIntelDelivery is the public representation of
 // store.ActiveIntelDelivery.
 type publicActiveIntelDelivery struct {
-	ID    uuid.UUID    `json:"id"`
-	Intel uuid.UUID    `json:"intel"`
-	To    uuid.UUID    `json:"to"`
-	Note  nulls.String `json:"note"`
+	ID          uuid.UUID    `json:"id"`
+	Intel       uuid.UUID    `json:"intel"`
+	To          uuid.UUID    `json:"to"`
+	Note        nulls.String `json:"note"`
+	DeliverAt   nulls.Time   `json:"deliver_at"`
+	IsScheduled bool         `json:"is_scheduled"`
 }
 
 // publicIntel is the public representation of store.Intel.
@@ -73,10 +75,12 @@ type publicIntel struct {
 func publicOpenIntelDeliverySummaryFromStore(s store.OpenIntelDeliverySummary) publicOpenIntelDeliverySummary {
 	return publicOpenIntelDeliverySummary{
 		Delivery: publicActiveIntelDelivery{
-			ID:    s.Delivery.ID,
-			Intel: s.Delivery.Intel,
-			To:    s.Delivery.To,
-			Note:  s.Delivery.Note,
+			ID:          s.Delivery.ID,
+			Intel:       s.Delivery.Intel,
+			To:          s.Delivery.To,
+			Note:        s.Delivery.Note,
+			DeliverAt:   s.Delivery.DeliverAt,
+			IsScheduled: s.Delivery.IsScheduled(),
 		},
 		Intel: publicIntel{
 			ID:         s.Intel.ID,
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"go.uber.org/zap"
 )
 
@@ -21,10 +22,12 @@ type Store interface {
 }
 
 // Serve the endpoints via HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, s Store) error {
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, s Store,
+	deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	r := httpendpoints.NewEngine(logger)
 	populateRoutes(r, logger, authSecret, s)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, r, addr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
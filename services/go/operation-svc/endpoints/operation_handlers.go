@@ -139,13 +139,14 @@ func handleGetOperations(s handleGetOperationsStore) httpendpoints.HandlerFunc {
 			return meh.Wrap(err, "operation filters from request", nil)
 		}
 		// Check permissions.
-		ok, err := auth.HasPermission(token, permission.ViewAnyOperation())
+		scope, err := auth.GrantedOperations(token, permission.ViewAnyOperation())
 		if err != nil {
-			return meh.Wrap(err, "check permission", nil)
+			return meh.Wrap(err, "granted operations", nil)
 		}
-		if !ok {
+		if !scope.All {
 			// Overwrite for-user filter.
 			operationFilters.ForUser = nulls.NewUUID(token.UserID)
+			operationFilters.AlsoInclude = scope.Operations
 		}
 		// Params.
 		paginationParams, err := pagination.ParamsFromRequest(c)
@@ -237,10 +238,8 @@ type handleUpdateOperationStore interface {
 // handleUpdateOperation updates the operation with the given id.
 func handleUpdateOperation(s handleUpdateOperationStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
-		// Check permissions.
-		err := auth.AssurePermission(token, permission.UpdateOperation())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
 		// Extract id from params.
 		idFromQueryStr := c.Param("operationID")
@@ -248,6 +247,11 @@ func handleUpdateOperation(s handleUpdateOperationStore) httpendpoints.HandlerFu
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse operation id from query", meh.Details{"str": idFromQueryStr})
 		}
+		// Check permissions.
+		err = auth.AssurePermission(token, permission.InOperation(idFromQuery, permission.UpdateOperation()))
+		if err != nil {
+			return meh.Wrap(err, "check permissions", nil)
+		}
 		// Parse body.
 		var update publicOperation
 		err = json.NewDecoder(c.Request.Body).Decode(&update)
@@ -288,10 +292,8 @@ type handleGetOperationMembersByOperationStore interface {
 // operation.
 func handleGetOperationMembersByOperation(s handleGetOperationMembersByOperationStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
-		// Check permission.
-		err := auth.AssurePermission(token, permission.ViewOperationMembers())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
 		// Extract id from params.
 		idFromQueryStr := c.Param("operationID")
@@ -299,6 +301,11 @@ func handleGetOperationMembersByOperation(s handleGetOperationMembersByOperation
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse operation id from query", meh.Details{"str": idFromQueryStr})
 		}
+		// Check permission.
+		err = auth.AssurePermission(token, permission.InOperation(idFromQuery, permission.ViewOperationMembers()))
+		if err != nil {
+			return meh.Wrap(err, "check permissions", nil)
+		}
 		// Retrieve.
 		users, err := s.OperationMembersByOperation(c.Request.Context(), idFromQuery)
 		if err != nil {
@@ -323,10 +330,8 @@ type handleUpdateOperationMembersByOperationStore interface {
 // the given operation.
 func handleUpdateOperationMembersByOperation(s handleUpdateOperationMembersByOperationStore) httpendpoints.HandlerFunc {
 	return func(c *gin.Context, token auth.Token) error {
-		// Check permission.
-		err := auth.AssurePermission(token, permission.UpdateOperationMembers())
-		if err != nil {
-			return meh.Wrap(err, "check permissions", nil)
+		if !token.IsAuthenticated {
+			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
 		// Extract id from params.
 		idFromQueryStr := c.Param("operationID")
@@ -334,6 +339,11 @@ func handleUpdateOperationMembersByOperation(s handleUpdateOperationMembersByOpe
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse operation id from query", meh.Details{"str": idFromQueryStr})
 		}
+		// Check permission.
+		err = auth.AssurePermission(token, permission.InOperation(idFromQuery, permission.UpdateOperationMembers()))
+		if err != nil {
+			return meh.Wrap(err, "check permissions", nil)
+		}
 		// Parse body.
 		var members []uuid.UUID
 		err = json.NewDecoder(c.Request.Body).Decode(&members)
@@ -372,13 +382,14 @@ func handleSearchOperations(s handleSearchOperationsStore) httpendpoints.Handler
 			return meh.Wrap(err, "operation filters from request", nil)
 		}
 		// Check permissions.
-		ok, err := auth.HasPermission(token, permission.ViewAnyOperation())
+		scope, err := auth.GrantedOperations(token, permission.ViewAnyOperation())
 		if err != nil {
-			return meh.Wrap(err, "check permission", nil)
+			return meh.Wrap(err, "granted operations", nil)
 		}
-		if !ok {
+		if !scope.All {
 			// Overwrite for-user filter.
 			operationFilters.ForUser = nulls.NewUUID(token.UserID)
+			operationFilters.AlsoInclude = scope.Operations
 		}
 		// Extract params.
 		searchParams, err := search.ParamsFromRequest(c)
@@ -24,6 +24,19 @@ import (
 	"time"
 )
 
+// operationScopedPermission creates a permission.Permission with the given
+// name, that is limited to the operations with the given ids.
+func operationScopedPermission(name permission.Name, operations ...uuid.UUID) permission.Permission {
+	raw, err := json.Marshal(permission.OperationScopeOptions{Operations: operations})
+	if err != nil {
+		panic(err)
+	}
+	return permission.Permission{
+		Name:    name,
+		Options: nulls.NewJSONRawMessage(raw),
+	}
+}
+
 // handleGetOperationsSuite tests handleGetOperations.
 type handleGetOperationsSuite struct {
 	suite.Suite
@@ -114,6 +127,27 @@ func (suite *handleGetOperationsSuite) TestMissingPermission() {
 	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
 }
 
+func (suite *handleGetOperationsSuite) TestPermissionForOperations() {
+	scopedOperation := testutil.NewUUIDV4()
+	suite.tokenOK.Permissions = []permission.Permission{
+		operationScopedPermission(permission.ViewAnyOperationPermissionName, scopedOperation),
+	}
+	suite.s.On("Operations", mock.Anything, store.OperationRetrievalFilters{
+		ForUser:     nulls.NewUUID(suite.tokenOK.UserID),
+		AlsoInclude: []uuid.UUID{scopedOperation},
+	}, mock.Anything).Return(suite.sampleOperations, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/",
+		Token:  suite.tokenOK,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
 func (suite *handleGetOperationsSuite) TestInvalidFilterParams() {
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -476,6 +510,38 @@ func (suite *handleUpdateOperationSuite) TestMissingPermission() {
 	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
 }
 
+func (suite *handleUpdateOperationSuite) TestPermissionForOtherOperation() {
+	suite.tokenOK.Permissions = []permission.Permission{
+		operationScopedPermission(permission.UpdateOperationPermissionName, testutil.NewUUIDV4()),
+	}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/%s", suite.sampleUpdateID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicUpdate)),
+		Token:  suite.tokenOK,
+		Secret: "",
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateOperationSuite) TestPermissionForOperationOK() {
+	suite.tokenOK.Permissions = []permission.Permission{
+		operationScopedPermission(permission.UpdateOperationPermissionName, suite.sampleUpdateID),
+	}
+	suite.s.On("UpdateOperation", mock.Anything, suite.sampleUpdate).Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/%s", suite.sampleUpdateID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicUpdate)),
+		Token:  suite.tokenOK,
+		Secret: "",
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
 func (suite *handleUpdateOperationSuite) TestInvalidBody() {
 	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
 		Server: suite.r,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
@@ -35,6 +36,7 @@ var operationSearchIndexConfig = search.IndexConfig{
 		operationSearchAttrDescription,
 	},
 	Filterable: []search.Attribute{
+		operationSearchAttrID,
 		operationSearchAttrStartTS,
 		operationSearchAttrEndTS,
 		operationSearchAttrIsArchived,
@@ -138,6 +140,21 @@ type OperationRetrievalFilters struct {
 	IncludeArchived bool
 	// ForUser only includes operations, the user with the given id is member of.
 	ForUser uuid.NullUUID
+	// AlsoInclude includes the operations with the given ids, even if ForUser is
+	// set and the user is no member of them.
+	AlsoInclude []uuid.UUID
+}
+
+// forUserCondition returns the condition for OperationRetrievalFilters.ForUser
+// while respecting OperationRetrievalFilters.AlsoInclude.
+func (m *Mall) forUserCondition(operationFilters OperationRetrievalFilters) exp.Expression {
+	forUser := goqu.I("operations.id").In(m.dialect.From(goqu.T("operation_members")).
+		Select(goqu.I("operation_members.operation")).
+		Where(goqu.I("operation_members.user").Eq(operationFilters.ForUser.UUID)))
+	if len(operationFilters.AlsoInclude) == 0 {
+		return forUser
+	}
+	return goqu.Or(forUser, goqu.I("operations.id").In(operationFilters.AlsoInclude))
 }
 
 // Operations retrieves an Operation list.
@@ -160,8 +177,7 @@ func (m *Mall) Operations(ctx context.Context, tx pgx.Tx, operationFilters Opera
 		qb = qb.Where(goqu.I("operations.is_archived").IsFalse())
 	}
 	if operationFilters.ForUser.Valid {
-		qb = qb.Where(goqu.I("operations.id").In(m.dialect.From(goqu.T("operation_members")).
-			Select(goqu.I("operation_members.operation")).Where(goqu.I("operation_members.user").Eq(operationFilters.ForUser.UUID))))
+		qb = qb.Where(m.forUserCondition(operationFilters))
 	}
 	q, _, err := pagination.QueryToSQLWithPagination(qb, paginationParams, pagination.FieldMap{
 		"title":       goqu.I("operations.title"),
@@ -292,9 +308,13 @@ func (m *Mall) SearchOperations(ctx context.Context, tx pgx.Tx, operationFilters
 		})
 	}
 	if operationFilters.ForUser.Valid {
-		filters = append(filters, []string{
+		forUserFilter := []string{
 			fmt.Sprintf("%s = '%s'", operationSearchAttrMembers, operationFilters.ForUser.UUID.String()),
-		})
+		}
+		for _, operationID := range operationFilters.AlsoInclude {
+			forUserFilter = append(forUserFilter, fmt.Sprintf("%s = '%s'", operationSearchAttrID, operationID.String()))
+		}
+		filters = append(filters, forUserFilter)
 	}
 	resultUUIDs, err := search.UUIDSearch(m.searchClient, operationSearchIndex, searchParams, search.Request{
 		Filter: filters,
@@ -314,9 +334,7 @@ func (m *Mall) SearchOperations(ctx context.Context, tx pgx.Tx, operationFilters
 			goqu.I("operations.end_ts"),
 			goqu.I("operations.is_archived"))
 	if operationFilters.ForUser.Valid { // Safety.
-		qb = qb.Where(goqu.I("operations.id").In(m.dialect.From(goqu.T("operation_members")).
-			Select(goqu.I("operation_members.operation")).
-			Where(goqu.I("operation_members.user").Eq(operationFilters.ForUser.UUID))))
+		qb = qb.Where(m.forUserCondition(operationFilters))
 	}
 	q, _, err := pgutil.QueryWithOrdinalityUUID(qb, goqu.I("operations.id"), resultUUIDs.Hits).ToSQL()
 	if err != nil {
@@ -68,7 +68,7 @@ func Run(ctx context.Context) error {
 		})
 		// Check Kafka topics.
 		eg.Go(func() error {
-			err := kafkautil.AwaitTopics(egCtx, c.KafkaAddr, event.PermissionsTopic, event.UsersTopic)
+			err := kafkautil.AwaitTopics(egCtx, c.KafkaAddr, event.GroupsTopic, event.PermissionsTopic, event.UsersTopic)
 			return meh.NilOrWrap(err, "await topics", meh.Details{"kafka_addr": c.KafkaAddr})
 		})
 		// Check database.
@@ -83,7 +83,7 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
@@ -96,7 +96,7 @@ func Run(ctx context.Context) error {
 	}
 	// Serve endpoints.
 	eg.Go(func() error {
-		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl)
+		err := endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl, kafkaConnector.DeadLetterAdmin(sqlDB))
 		if err != nil {
 			return meh.Wrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 		}
@@ -106,7 +106,7 @@ func Run(ctx context.Context) error {
 	eg.Go(func() error {
 		logger := logger.Named("kafka-reader")
 		kafkaReader := kafkautil.NewReader(logger, c.KafkaAddr, kafkaGroupID,
-			[]event.Topic{event.UsersTopic})
+			[]event.Topic{event.GroupsTopic, event.UsersTopic})
 		kafkaWriter := kafkautil.NewWriter(logger.Named("kafka"), c.KafkaAddr)
 		err := kafkautil.RunConnector(egCtx, kafkaConnector, sqlDB, kafkaWriter, kafkaReader, eventPort.HandlerFn(ctrl))
 		if err != nil {
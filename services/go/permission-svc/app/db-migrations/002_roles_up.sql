@@ -0,0 +1,71 @@
+-- Create roles table.
+
+create table roles
+(
+    id          uuid primary key not null default uuid_generate_v4(),
+    label       varchar          not null,
+    description varchar          not null
+);
+
+comment on table roles is 'Named sets of permissions, that can be assigned to users and groups.';
+
+-- Create role permissions table.
+
+create table role_permissions
+(
+    role    uuid    not null references roles (id)
+        on delete cascade on update cascade,
+    name    varchar not null,
+    options jsonb
+);
+
+comment on column role_permissions.role is 'The id of the role the permission is part of.';
+comment on column role_permissions.name is 'The identifier of the permission.';
+comment on column role_permissions.options is 'Additional options for the permission.';
+
+create unique index role_permissions_role_name_ix on role_permissions (role, name);
+
+-- Create user roles table.
+
+create table user_roles
+(
+    "user" uuid not null references users (id)
+        on delete restrict on update restrict,
+    role   uuid not null references roles (id)
+        on delete cascade on update cascade
+);
+
+create unique index user_roles_user_role_ix on user_roles ("user", role);
+create index user_roles_role_ix on user_roles (role);
+
+-- Create groups table.
+
+create table groups
+(
+    id uuid primary key not null
+);
+
+create table group_members
+(
+    "group" uuid not null references groups (id)
+        on delete cascade on update cascade,
+    "user"  uuid not null
+);
+
+create index group_members_group_ix on group_members ("group");
+create index group_members_user_ix on group_members ("user");
+
+-- Create group roles table.
+
+create table group_roles
+(
+    "group" uuid not null references groups (id)
+        on delete cascade on update cascade,
+    role    uuid not null references roles (id)
+        on delete cascade on update cascade
+);
+
+comment on table group_roles is 'Roles, that are assigned to all members of a group.';
+
+create unique index group_roles_group_role_ix on group_roles ("group", role);
+create index group_roles_role_ix on group_roles (role);
@@ -29,6 +29,46 @@ type Store interface {
 	// UpdatePermissionsByUser updates the permissions for the user with the given
 	// id.
 	UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, permissions []store.Permission) error
+	// CreateRole creates the given store.Role and returns it with its assigned id.
+	CreateRole(ctx context.Context, tx pgx.Tx, create store.Role) (store.Role, error)
+	// UpdateRole updates the given store.Role, identified by its id.
+	UpdateRole(ctx context.Context, tx pgx.Tx, update store.Role) error
+	// DeleteRoleByID deletes the role with the given id.
+	DeleteRoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) error
+	// RoleByID retrieves the store.Role with the given id.
+	RoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) (store.Role, error)
+	// Roles retrieves all roles.
+	Roles(ctx context.Context, tx pgx.Tx) ([]store.Role, error)
+	// RolesByUser retrieves the ids of all roles, that are directly assigned to
+	// the user with the given id.
+	RolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]uuid.UUID, error)
+	// UpdateRolesByUser sets the roles, that are directly assigned to the user
+	// with the given id.
+	UpdateRolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, roles []uuid.UUID) error
+	// RolePermissionsByUser retrieves the permissions of all roles, that are
+	// assigned to the user with the given id, either directly or via group
+	// membership.
+	RolePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]store.Permission, error)
+	// UsersByRole retrieves the ids of all users, the role with the given id is
+	// assigned to, either directly or via group membership.
+	UsersByRole(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) ([]uuid.UUID, error)
+	// CreateGroup creates the given store.Group.
+	CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error
+	// UpdateGroup updates the given store.Group, identified by its id.
+	UpdateGroup(ctx context.Context, tx pgx.Tx, update store.Group) error
+	// DeleteGroupByID deletes the group with the given id.
+	DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error
+	// AssureGroupExists makes sure that the group with the given id exists.
+	AssureGroupExists(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error
+	// MembersByGroup retrieves the ids of all members of the group with the given
+	// id.
+	MembersByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error)
+	// RolesByGroup retrieves the ids of all roles, that are assigned to the group
+	// with the given id.
+	RolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error)
+	// UpdateRolesByGroup sets the roles, that are assigned to the group with the
+	// given id.
+	UpdateRolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID, roles []uuid.UUID) error
 }
 
 // Notifier sends event messages.
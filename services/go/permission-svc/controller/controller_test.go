@@ -63,6 +63,102 @@ func (m *StoreMock) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, user
 	return m.Called(ctx, tx, userID, permissions).Error(0)
 }
 
+func (m *StoreMock) CreateRole(ctx context.Context, tx pgx.Tx, create store.Role) (store.Role, error) {
+	args := m.Called(ctx, tx, create)
+	return args.Get(0).(store.Role), args.Error(1)
+}
+
+func (m *StoreMock) UpdateRole(ctx context.Context, tx pgx.Tx, update store.Role) error {
+	return m.Called(ctx, tx, update).Error(0)
+}
+
+func (m *StoreMock) DeleteRoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) error {
+	return m.Called(ctx, tx, roleID).Error(0)
+}
+
+func (m *StoreMock) RoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) (store.Role, error) {
+	args := m.Called(ctx, tx, roleID)
+	return args.Get(0).(store.Role), args.Error(1)
+}
+
+func (m *StoreMock) Roles(ctx context.Context, tx pgx.Tx) ([]store.Role, error) {
+	args := m.Called(ctx, tx)
+	var roles []store.Role
+	if argsRoles := args.Get(0); argsRoles != nil {
+		roles = argsRoles.([]store.Role)
+	}
+	return roles, args.Error(1)
+}
+
+func (m *StoreMock) RolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, userID)
+	var roles []uuid.UUID
+	if argsRoles := args.Get(0); argsRoles != nil {
+		roles = argsRoles.([]uuid.UUID)
+	}
+	return roles, args.Error(1)
+}
+
+func (m *StoreMock) UpdateRolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, roles []uuid.UUID) error {
+	return m.Called(ctx, tx, userID, roles).Error(0)
+}
+
+func (m *StoreMock) RolePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]store.Permission, error) {
+	args := m.Called(ctx, tx, userID)
+	var p []store.Permission
+	if argsPermissions := args.Get(0); argsPermissions != nil {
+		p = argsPermissions.([]store.Permission)
+	}
+	return p, args.Error(1)
+}
+
+func (m *StoreMock) UsersByRole(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, roleID)
+	var users []uuid.UUID
+	if argsUsers := args.Get(0); argsUsers != nil {
+		users = argsUsers.([]uuid.UUID)
+	}
+	return users, args.Error(1)
+}
+
+func (m *StoreMock) CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *StoreMock) UpdateGroup(ctx context.Context, tx pgx.Tx, update store.Group) error {
+	return m.Called(ctx, tx, update).Error(0)
+}
+
+func (m *StoreMock) DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	return m.Called(ctx, tx, groupID).Error(0)
+}
+
+func (m *StoreMock) AssureGroupExists(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	return m.Called(ctx, tx, groupID).Error(0)
+}
+
+func (m *StoreMock) MembersByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, groupID)
+	var members []uuid.UUID
+	if argsMembers := args.Get(0); argsMembers != nil {
+		members = argsMembers.([]uuid.UUID)
+	}
+	return members, args.Error(1)
+}
+
+func (m *StoreMock) RolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, tx, groupID)
+	var roles []uuid.UUID
+	if argsRoles := args.Get(0); argsRoles != nil {
+		roles = argsRoles.([]uuid.UUID)
+	}
+	return roles, args.Error(1)
+}
+
+func (m *StoreMock) UpdateRolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID, roles []uuid.UUID) error {
+	return m.Called(ctx, tx, groupID, roles).Error(0)
+}
+
 // NotifierMock mocks Notifier.
 type NotifierMock struct {
 	mock.Mock
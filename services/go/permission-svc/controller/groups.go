@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+)
+
+// CreateGroup creates the given store.Group.
+func (c *Controller) CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error {
+	err := c.Store.CreateGroup(ctx, tx, create)
+	if err != nil {
+		return meh.Wrap(err, "create group in store", meh.Details{"create": create})
+	}
+	return nil
+}
+
+// UpdateGroup updates the given store.Group. If roles are assigned to the
+// group, permissions for previous and new members are notified as being
+// updated.
+func (c *Controller) UpdateGroup(ctx context.Context, tx pgx.Tx, update store.Group) error {
+	previousMembers, err := c.Store.MembersByGroup(ctx, tx, update.ID)
+	if err != nil {
+		return meh.Wrap(err, "members by group from store", meh.Details{"group_id": update.ID})
+	}
+	roles, err := c.Store.RolesByGroup(ctx, tx, update.ID)
+	if err != nil {
+		return meh.Wrap(err, "roles by group from store", meh.Details{"group_id": update.ID})
+	}
+	err = c.Store.UpdateGroup(ctx, tx, update)
+	if err != nil {
+		return meh.Wrap(err, "update group in store", meh.Details{"update": update})
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	// Notify for all members, that left or joined.
+	previousMembersSet := make(map[uuid.UUID]struct{}, len(previousMembers))
+	for _, member := range previousMembers {
+		previousMembersSet[member] = struct{}{}
+	}
+	newMembersSet := make(map[uuid.UUID]struct{}, len(update.Members))
+	for _, member := range update.Members {
+		newMembersSet[member] = struct{}{}
+	}
+	affectedUsers := make([]uuid.UUID, 0)
+	for _, member := range previousMembers {
+		if _, ok := newMembersSet[member]; !ok {
+			affectedUsers = append(affectedUsers, member)
+		}
+	}
+	for _, member := range update.Members {
+		if _, ok := previousMembersSet[member]; !ok {
+			affectedUsers = append(affectedUsers, member)
+		}
+	}
+	err = c.notifyEffectivePermissionsUpdated(ctx, tx, affectedUsers...)
+	if err != nil {
+		return meh.Wrap(err, "notify effective permissions updated", meh.Details{"users": affectedUsers})
+	}
+	return nil
+}
+
+// DeleteGroupByID deletes the group with the given id. If roles were assigned
+// to the group, permissions for its members are notified as being updated.
+func (c *Controller) DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	members, err := c.Store.MembersByGroup(ctx, tx, groupID)
+	if err != nil {
+		return meh.Wrap(err, "members by group from store", meh.Details{"group_id": groupID})
+	}
+	roles, err := c.Store.RolesByGroup(ctx, tx, groupID)
+	if err != nil {
+		return meh.Wrap(err, "roles by group from store", meh.Details{"group_id": groupID})
+	}
+	err = c.Store.DeleteGroupByID(ctx, tx, groupID)
+	if err != nil {
+		return meh.Wrap(err, "delete group in store", meh.Details{"group_id": groupID})
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	err = c.notifyEffectivePermissionsUpdated(ctx, tx, members...)
+	if err != nil {
+		return meh.Wrap(err, "notify effective permissions updated", meh.Details{"members": members})
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerUpdateGroupSuite tests Controller.UpdateGroup.
+type ControllerUpdateGroupSuite struct {
+	suite.Suite
+	ctrl                  *ControllerMock
+	tx                    *testutil.DBTx
+	samplePreviousMembers []uuid.UUID
+	sampleUpdate          store.Group
+}
+
+func (suite *ControllerUpdateGroupSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.samplePreviousMembers = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+	suite.sampleUpdate = store.Group{
+		ID:      testutil.NewUUIDV4(),
+		Members: []uuid.UUID{suite.samplePreviousMembers[1], testutil.NewUUIDV4()},
+	}
+}
+
+func (suite *ControllerUpdateGroupSuite) TestRetrieveMembersFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateGroup(timeout, suite.tx, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateGroupSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return(suite.samplePreviousMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("UpdateGroup", timeout, suite.tx, suite.sampleUpdate).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateGroup(timeout, suite.tx, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateGroupSuite) TestOKWithoutRoles() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return(suite.samplePreviousMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("UpdateGroup", timeout, suite.tx, suite.sampleUpdate).Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateGroup(timeout, suite.tx, suite.sampleUpdate)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateGroupSuite) TestOKWithRoles() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return(suite.samplePreviousMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleUpdate.ID).Return([]uuid.UUID{testutil.NewUUIDV4()}, nil)
+	suite.ctrl.Store.On("UpdateGroup", timeout, suite.tx, suite.sampleUpdate).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, mock.Anything).Return([]store.Permission{}, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, mock.Anything).Return([]store.Permission{}, nil)
+	// Expect notifications only for the left and the joined member.
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, suite.samplePreviousMembers[0], mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, suite.sampleUpdate.Members[1], mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateGroup(timeout, suite.tx, suite.sampleUpdate)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateGroup(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateGroupSuite))
+}
+
+// ControllerDeleteGroupByIDSuite tests Controller.DeleteGroupByID.
+type ControllerDeleteGroupByIDSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleGroupID uuid.UUID
+	sampleMembers []uuid.UUID
+}
+
+func (suite *ControllerDeleteGroupByIDSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleGroupID = testutil.NewUUIDV4()
+	suite.sampleMembers = []uuid.UUID{testutil.NewUUIDV4()}
+}
+
+func (suite *ControllerDeleteGroupByIDSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleGroupID).Return(suite.sampleMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleGroupID).Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("DeleteGroupByID", timeout, suite.tx, suite.sampleGroupID).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteGroupByID(timeout, suite.tx, suite.sampleGroupID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteGroupByIDSuite) TestOKWithoutRoles() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleGroupID).Return(suite.sampleMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleGroupID).Return([]uuid.UUID{}, nil)
+	suite.ctrl.Store.On("DeleteGroupByID", timeout, suite.tx, suite.sampleGroupID).Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteGroupByID(timeout, suite.tx, suite.sampleGroupID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteGroupByIDSuite) TestOKWithRoles() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleGroupID).Return(suite.sampleMembers, nil)
+	suite.ctrl.Store.On("RolesByGroup", timeout, suite.tx, suite.sampleGroupID).Return([]uuid.UUID{testutil.NewUUIDV4()}, nil)
+	suite.ctrl.Store.On("DeleteGroupByID", timeout, suite.tx, suite.sampleGroupID).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.sampleMembers[0]).Return([]store.Permission{}, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, suite.sampleMembers[0]).Return([]store.Permission{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, suite.sampleMembers[0], []store.Permission{}).
+		Return(nil).Once()
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteGroupByID(timeout, suite.tx, suite.sampleGroupID)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_DeleteGroupByID(t *testing.T) {
+	suite.Run(t, new(ControllerDeleteGroupByIDSuite))
+}
@@ -6,9 +6,28 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 )
 
+// validatePermissions validates the given store.Permission list using
+// permission.Validate. If any validation errors are found, a meh.ErrBadInput is
+// returned.
+func validatePermissions(permissions []store.Permission) error {
+	toValidate := make([]permission.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		toValidate = append(toValidate, permission.Permission(p))
+	}
+	validationErrors, err := permission.Validate(toValidate)
+	if err != nil {
+		return meh.Wrap(err, "validate permissions", nil)
+	}
+	if len(validationErrors) > 0 {
+		return meh.NewBadInputErr("invalid permissions", meh.Details{"validation_errors": validationErrors})
+	}
+	return nil
+}
+
 // PermissionsByUser retrieves the permissions for the user with the given id.
 func (c *Controller) PermissionsByUser(ctx context.Context, userID uuid.UUID) ([]store.Permission, error) {
 	var permissions []store.Permission
@@ -34,7 +53,11 @@ func (c *Controller) PermissionsByUser(ctx context.Context, userID uuid.UUID) ([
 // UpdatePermissionsByUser updates and notifies about changed permissions for
 // the user with the given id.
 func (c *Controller) UpdatePermissionsByUser(ctx context.Context, userID uuid.UUID, permissions []store.Permission) error {
-	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+	err := validatePermissions(permissions)
+	if err != nil {
+		return meh.Wrap(err, "validate permissions", nil)
+	}
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
 		// Assure exists.
 		err := c.Store.AssureUserExists(ctx, tx, userID)
 		if err != nil {
@@ -49,6 +72,79 @@ func (c *Controller) UpdatePermissionsByUser(ctx context.Context, userID uuid.UU
 			})
 		}
 		// Notify.
+		err = c.notifyEffectivePermissionsUpdated(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "notify effective permissions updated", meh.Details{"user_id": userID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// EffectivePermissionsByUser retrieves the effective permissions for the user
+// with the given id. These are the directly granted ones, merged with the ones
+// from assigned roles.
+func (c *Controller) EffectivePermissionsByUser(ctx context.Context, userID uuid.UUID) ([]store.Permission, error) {
+	var permissions []store.Permission
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		// Assure exists.
+		err := c.Store.AssureUserExists(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "assure user exists", meh.Details{"user_id": userID})
+		}
+		permissions, err = c.effectivePermissionsByUser(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "effective permissions by user", meh.Details{"user_id": userID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return permissions, nil
+}
+
+// effectivePermissionsByUser retrieves the directly granted permissions for the
+// user with the given id as well as the ones from assigned roles and merges
+// them using permission.Merge.
+func (c *Controller) effectivePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]store.Permission, error) {
+	directPermissions, err := c.Store.PermissionsByUser(ctx, tx, userID)
+	if err != nil {
+		return nil, meh.Wrap(err, "permissions by user from store", nil)
+	}
+	rolePermissions, err := c.Store.RolePermissionsByUser(ctx, tx, userID)
+	if err != nil {
+		return nil, meh.Wrap(err, "role permissions by user from store", nil)
+	}
+	toMerge := make([]permission.Permission, 0, len(directPermissions)+len(rolePermissions))
+	for _, p := range directPermissions {
+		toMerge = append(toMerge, permission.Permission(p))
+	}
+	for _, p := range rolePermissions {
+		toMerge = append(toMerge, permission.Permission(p))
+	}
+	merged, err := permission.Merge(toMerge)
+	if err != nil {
+		return nil, meh.Wrap(err, "merge permissions", meh.Details{"to_merge": toMerge})
+	}
+	effectivePermissions := make([]store.Permission, 0, len(merged))
+	for _, p := range merged {
+		effectivePermissions = append(effectivePermissions, store.Permission(p))
+	}
+	return effectivePermissions, nil
+}
+
+// notifyEffectivePermissionsUpdated notifies about the effective permissions of
+// all users with the given ids being updated.
+func (c *Controller) notifyEffectivePermissionsUpdated(ctx context.Context, tx pgx.Tx, userIDs ...uuid.UUID) error {
+	for _, userID := range userIDs {
+		permissions, err := c.effectivePermissionsByUser(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "effective permissions by user", meh.Details{"user_id": userID})
+		}
 		err = c.Notifier.NotifyPermissionsUpdated(ctx, tx, userID, permissions)
 		if err != nil {
 			return meh.Wrap(err, "notify permissions updated", meh.Details{
@@ -56,10 +152,6 @@ func (c *Controller) UpdatePermissionsByUser(ctx context.Context, userID uuid.UU
 				"permissions": permissions,
 			})
 		}
-		return nil
-	})
-	if err != nil {
-		return meh.Wrap(err, "run in tx", nil)
 	}
 	return nil
 }
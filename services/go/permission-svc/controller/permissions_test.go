@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/stretchr/testify/suite"
@@ -107,9 +109,11 @@ func TestController_PermissionsByUser(t *testing.T) {
 // Controller.UpdatePermissionsByUser.
 type ControllerUpdatePermissionsByUserSuite struct {
 	suite.Suite
-	ctrl                     *ControllerMock
-	sampleUserID             uuid.UUID
-	sampleUpdatedPermissions []store.Permission
+	ctrl                       *ControllerMock
+	sampleUserID               uuid.UUID
+	sampleUpdatedPermissions   []store.Permission
+	sampleRolePermissions      []store.Permission
+	sampleEffectivePermissions []store.Permission
 }
 
 func (suite *ControllerUpdatePermissionsByUserSuite) SetupTest() {
@@ -119,6 +123,35 @@ func (suite *ControllerUpdatePermissionsByUserSuite) SetupTest() {
 		{Name: "meow"},
 		{Name: "woof"},
 	}
+	suite.sampleRolePermissions = []store.Permission{
+		{Name: "woof"},
+		{Name: "moo"},
+	}
+	suite.sampleEffectivePermissions = []store.Permission{
+		{Name: "meow"},
+		{Name: "woof"},
+		{Name: "moo"},
+	}
+}
+
+func (suite *ControllerUpdatePermissionsByUserSuite) TestInvalidPermissions() {
+	timeout, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.sampleUpdatedPermissions = append(suite.sampleUpdatedPermissions, store.Permission{
+		Name:    "meow",
+		Options: nulls.NewJSONRawMessage([]byte(`{}`)),
+	})
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, suite.sampleUserID, suite.sampleUpdatedPermissions)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	<-timeout.Done()
+	suite.NotEqual(context.DeadlineExceeded, timeout.Err(), "should not time out")
 }
 
 func (suite *ControllerUpdatePermissionsByUserSuite) TestTxFail() {
@@ -174,14 +207,38 @@ func (suite *ControllerUpdatePermissionsByUserSuite) TestStoreUpdateFail() {
 	suite.NotEqual(context.DeadlineExceeded, timeout.Err(), "should not time out")
 }
 
+func (suite *ControllerUpdatePermissionsByUserSuite) TestRetrieveRolePermissionsFail() {
+	timeout, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
+	suite.ctrl.Store.On("AssureUserExists", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(nil)
+	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(suite.sampleUpdatedPermissions, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePermissionsByUser(timeout, suite.sampleUserID, suite.sampleUpdatedPermissions)
+		suite.Error(err, "should fail")
+		suite.False(suite.ctrl.DB.Tx[0].IsCommitted, "should not have committed tx")
+	}()
+
+	<-timeout.Done()
+	suite.NotEqual(context.DeadlineExceeded, timeout.Err(), "should not time out")
+}
+
 func (suite *ControllerUpdatePermissionsByUserSuite) TestNotifyFail() {
 	timeout, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("AssureUserExists", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(nil)
 	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(suite.sampleUpdatedPermissions, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(suite.sampleRolePermissions, nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
-	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleUpdatedPermissions).
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleEffectivePermissions).
 		Return(errors.New("sad life"))
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
@@ -202,8 +259,10 @@ func (suite *ControllerUpdatePermissionsByUserSuite) TestOK() {
 	suite.ctrl.DB.Tx = []*testutil.DBTx{{}}
 	suite.ctrl.Store.On("AssureUserExists", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(nil)
 	suite.ctrl.Store.On("UpdatePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(suite.sampleUpdatedPermissions, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID).Return(suite.sampleRolePermissions, nil)
 	defer suite.ctrl.Store.AssertExpectations(suite.T())
-	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleUpdatedPermissions).Return(nil)
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.ctrl.DB.Tx[0], suite.sampleUserID, suite.sampleEffectivePermissions).Return(nil)
 	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
 
 	go func() {
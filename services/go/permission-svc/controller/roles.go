@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+)
+
+// Roles retrieves all roles.
+func (c *Controller) Roles(ctx context.Context) ([]store.Role, error) {
+	var roles []store.Role
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		roles, err = c.Store.Roles(ctx, tx)
+		if err != nil {
+			return meh.Wrap(err, "roles from store", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return roles, nil
+}
+
+// RoleByID retrieves the store.Role with the given id.
+func (c *Controller) RoleByID(ctx context.Context, roleID uuid.UUID) (store.Role, error) {
+	var role store.Role
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		role, err = c.Store.RoleByID(ctx, tx, roleID)
+		if err != nil {
+			return meh.Wrap(err, "role by id from store", meh.Details{"role_id": roleID})
+		}
+		return nil
+	})
+	if err != nil {
+		return store.Role{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return role, nil
+}
+
+// CreateRole creates the given store.Role after validating its permissions.
+func (c *Controller) CreateRole(ctx context.Context, create store.Role) (store.Role, error) {
+	err := validatePermissions(create.Permissions)
+	if err != nil {
+		return store.Role{}, meh.Wrap(err, "validate permissions", nil)
+	}
+	var created store.Role
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		created, err = c.Store.CreateRole(ctx, tx, create)
+		if err != nil {
+			return meh.Wrap(err, "create role in store", meh.Details{"create": create})
+		}
+		return nil
+	})
+	if err != nil {
+		return store.Role{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return created, nil
+}
+
+// UpdateRole updates the given store.Role after validating its permissions and
+// notifies about updated permissions for all users, the role is assigned to.
+func (c *Controller) UpdateRole(ctx context.Context, update store.Role) error {
+	err := validatePermissions(update.Permissions)
+	if err != nil {
+		return meh.Wrap(err, "validate permissions", nil)
+	}
+	err = pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.Store.UpdateRole(ctx, tx, update)
+		if err != nil {
+			return meh.Wrap(err, "update role in store", meh.Details{"update": update})
+		}
+		affectedUsers, err := c.Store.UsersByRole(ctx, tx, update.ID)
+		if err != nil {
+			return meh.Wrap(err, "users by role from store", meh.Details{"role_id": update.ID})
+		}
+		err = c.notifyEffectivePermissionsUpdated(ctx, tx, affectedUsers...)
+		if err != nil {
+			return meh.Wrap(err, "notify effective permissions updated", meh.Details{"users": affectedUsers})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// DeleteRoleByID deletes the role with the given id and notifies about updated
+// permissions for all users, the role was assigned to.
+func (c *Controller) DeleteRoleByID(ctx context.Context, roleID uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		affectedUsers, err := c.Store.UsersByRole(ctx, tx, roleID)
+		if err != nil {
+			return meh.Wrap(err, "users by role from store", meh.Details{"role_id": roleID})
+		}
+		err = c.Store.DeleteRoleByID(ctx, tx, roleID)
+		if err != nil {
+			return meh.Wrap(err, "delete role in store", meh.Details{"role_id": roleID})
+		}
+		err = c.notifyEffectivePermissionsUpdated(ctx, tx, affectedUsers...)
+		if err != nil {
+			return meh.Wrap(err, "notify effective permissions updated", meh.Details{"users": affectedUsers})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// assureRolesExist assures that all roles with the given ids exist and returns
+// them without duplicates. If a role is not found, a meh.ErrBadInput is
+// returned.
+func (c *Controller) assureRolesExist(ctx context.Context, tx pgx.Tx, roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	roles := make([]uuid.UUID, 0, len(roleIDs))
+	rolesSet := make(map[uuid.UUID]struct{}, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if _, ok := rolesSet[roleID]; ok {
+			continue
+		}
+		_, err := c.Store.RoleByID(ctx, tx, roleID)
+		if err != nil {
+			if meh.ErrorCode(err) == meh.ErrNotFound {
+				return nil, meh.NewBadInputErrFromErr(err, "role not found", meh.Details{"role_id": roleID})
+			}
+			return nil, meh.Wrap(err, "role by id from store", meh.Details{"role_id": roleID})
+		}
+		rolesSet[roleID] = struct{}{}
+		roles = append(roles, roleID)
+	}
+	return roles, nil
+}
+
+// RolesByUser retrieves the ids of all roles, that are directly assigned to the
+// user with the given id.
+func (c *Controller) RolesByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var roles []uuid.UUID
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.Store.AssureUserExists(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "assure user exists", meh.Details{"user_id": userID})
+		}
+		roles, err = c.Store.RolesByUser(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "roles by user from store", meh.Details{"user_id": userID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return roles, nil
+}
+
+// UpdateRolesByUser sets the roles, that are directly assigned to the user with
+// the given id, and notifies about updated permissions.
+func (c *Controller) UpdateRolesByUser(ctx context.Context, userID uuid.UUID, roles []uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.Store.AssureUserExists(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "assure user exists", meh.Details{"user_id": userID})
+		}
+		roles, err = c.assureRolesExist(ctx, tx, roles)
+		if err != nil {
+			return meh.Wrap(err, "assure roles exist", meh.Details{"roles": roles})
+		}
+		err = c.Store.UpdateRolesByUser(ctx, tx, userID, roles)
+		if err != nil {
+			return meh.Wrap(err, "update roles by user in store", meh.Details{
+				"user_id": userID,
+				"roles":   roles,
+			})
+		}
+		err = c.notifyEffectivePermissionsUpdated(ctx, tx, userID)
+		if err != nil {
+			return meh.Wrap(err, "notify effective permissions updated", meh.Details{"user_id": userID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// RolesByGroup retrieves the ids of all roles, that are assigned to the group
+// with the given id.
+func (c *Controller) RolesByGroup(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	var roles []uuid.UUID
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.Store.AssureGroupExists(ctx, tx, groupID)
+		if err != nil {
+			return meh.Wrap(err, "assure group exists", meh.Details{"group_id": groupID})
+		}
+		roles, err = c.Store.RolesByGroup(ctx, tx, groupID)
+		if err != nil {
+			return meh.Wrap(err, "roles by group from store", meh.Details{"group_id": groupID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return roles, nil
+}
+
+// UpdateRolesByGroup sets the roles, that are assigned to the group with the
+// given id, and notifies about updated permissions for all group members.
+func (c *Controller) UpdateRolesByGroup(ctx context.Context, groupID uuid.UUID, roles []uuid.UUID) error {
+	err := pgutil.RunInTx(ctx, c.DB, func(ctx context.Context, tx pgx.Tx) error {
+		err := c.Store.AssureGroupExists(ctx, tx, groupID)
+		if err != nil {
+			return meh.Wrap(err, "assure group exists", meh.Details{"group_id": groupID})
+		}
+		roles, err = c.assureRolesExist(ctx, tx, roles)
+		if err != nil {
+			return meh.Wrap(err, "assure roles exist", meh.Details{"roles": roles})
+		}
+		err = c.Store.UpdateRolesByGroup(ctx, tx, groupID, roles)
+		if err != nil {
+			return meh.Wrap(err, "update roles by group in store", meh.Details{
+				"group_id": groupID,
+				"roles":    roles,
+			})
+		}
+		members, err := c.Store.MembersByGroup(ctx, tx, groupID)
+		if err != nil {
+			return meh.Wrap(err, "members by group from store", meh.Details{"group_id": groupID})
+		}
+		err = c.notifyEffectivePermissionsUpdated(ctx, tx, members...)
+		if err != nil {
+			return meh.Wrap(err, "notify effective permissions updated", meh.Details{"members": members})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
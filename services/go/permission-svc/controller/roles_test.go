@@ -0,0 +1,535 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerRolesSuite tests Controller.Roles.
+type ControllerRolesSuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	tx          *testutil.DBTx
+	sampleRoles []store.Role
+}
+
+func (suite *ControllerRolesSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleRoles = []store.Role{
+		{
+			ID:          testutil.NewUUIDV4(),
+			Label:       "radio operator",
+			Description: "pink",
+			Permissions: []store.Permission{{Name: permission.DeliverAnyRadioDeliveryPermissionName}},
+		},
+		{
+			ID:          testutil.NewUUIDV4(),
+			Label:       "dispatcher",
+			Description: "lift",
+			Permissions: []store.Permission{},
+		},
+	}
+}
+
+func (suite *ControllerRolesSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.Roles(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRolesSuite) TestRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("Roles", timeout, suite.tx).Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.Roles(timeout)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerRolesSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("Roles", timeout, suite.tx).Return(suite.sampleRoles, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.Roles(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleRoles, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_Roles(t *testing.T) {
+	suite.Run(t, new(ControllerRolesSuite))
+}
+
+// ControllerCreateRoleSuite tests Controller.CreateRole.
+type ControllerCreateRoleSuite struct {
+	suite.Suite
+	ctrl         *ControllerMock
+	tx           *testutil.DBTx
+	sampleCreate store.Role
+}
+
+func (suite *ControllerCreateRoleSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleCreate = store.Role{
+		Label:       "dispatcher",
+		Description: "gold",
+		Permissions: []store.Permission{
+			{Name: permission.ManageIntelDeliveryPermissionName},
+			{
+				Name:    permission.ViewAnyIntelPermissionName,
+				Options: nulls.NewJSONRawMessage([]byte(`{"operations":[]}`)),
+			},
+		},
+	}
+}
+
+func (suite *ControllerCreateRoleSuite) TestInvalidPermissions() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleCreate.Permissions[0].Options = nulls.NewJSONRawMessage([]byte(`{"meow":"woof"}`))
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.CreateRole(timeout, suite.sampleCreate)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateRoleSuite) TestCreateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("CreateRole", timeout, suite.tx, suite.sampleCreate).
+		Return(store.Role{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.CreateRole(timeout, suite.sampleCreate)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateRoleSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	created := suite.sampleCreate
+	created.ID = testutil.NewUUIDV4()
+	suite.ctrl.Store.On("CreateRole", timeout, suite.tx, suite.sampleCreate).Return(created, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.CreateRole(timeout, suite.sampleCreate)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(created, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_CreateRole(t *testing.T) {
+	suite.Run(t, new(ControllerCreateRoleSuite))
+}
+
+// ControllerUpdateRoleSuite tests Controller.UpdateRole.
+type ControllerUpdateRoleSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleUpdate  store.Role
+	affectedUsers []uuid.UUID
+}
+
+func (suite *ControllerUpdateRoleSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleUpdate = store.Role{
+		ID:          testutil.NewUUIDV4(),
+		Label:       "operation lead",
+		Description: "weave",
+		Permissions: []store.Permission{{Name: permission.UpdateOperationPermissionName}},
+	}
+	suite.affectedUsers = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+}
+
+func (suite *ControllerUpdateRoleSuite) TestInvalidPermissions() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleUpdate.Permissions[0].Options = nulls.NewJSONRawMessage([]byte(`{"operations":"meow"}`))
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRole(timeout, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRoleSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateRole", timeout, suite.tx, suite.sampleUpdate).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRole(timeout, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRoleSuite) TestRetrieveAffectedUsersFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateRole", timeout, suite.tx, suite.sampleUpdate).Return(nil)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleUpdate.ID).Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRole(timeout, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRoleSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateRole", timeout, suite.tx, suite.sampleUpdate).Return(nil)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleUpdate.ID).Return(suite.affectedUsers, nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, mock.Anything).Return([]store.Permission{}, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, mock.Anything).Return(suite.sampleUpdate.Permissions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRole(timeout, suite.sampleUpdate)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRoleSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	directPermissions := []store.Permission{{Name: permission.CreateUserPermissionName}}
+	suite.ctrl.Store.On("UpdateRole", timeout, suite.tx, suite.sampleUpdate).Return(nil)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleUpdate.ID).Return(suite.affectedUsers, nil)
+	for _, userID := range suite.affectedUsers {
+		suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, userID).Return(directPermissions, nil).Once()
+		suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, userID).Return(suite.sampleUpdate.Permissions, nil).Once()
+		suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, userID, []store.Permission{
+			{Name: permission.CreateUserPermissionName},
+			{Name: permission.UpdateOperationPermissionName},
+		}).Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRole(timeout, suite.sampleUpdate)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateRole(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateRoleSuite))
+}
+
+// ControllerDeleteRoleByIDSuite tests Controller.DeleteRoleByID.
+type ControllerDeleteRoleByIDSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleRoleID  uuid.UUID
+	affectedUsers []uuid.UUID
+}
+
+func (suite *ControllerDeleteRoleByIDSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleRoleID = testutil.NewUUIDV4()
+	suite.affectedUsers = []uuid.UUID{testutil.NewUUIDV4()}
+}
+
+func (suite *ControllerDeleteRoleByIDSuite) TestRetrieveAffectedUsersFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleRoleID).Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteRoleByID(timeout, suite.sampleRoleID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteRoleByIDSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleRoleID).Return(suite.affectedUsers, nil)
+	suite.ctrl.Store.On("DeleteRoleByID", timeout, suite.tx, suite.sampleRoleID).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteRoleByID(timeout, suite.sampleRoleID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeleteRoleByIDSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UsersByRole", timeout, suite.tx, suite.sampleRoleID).Return(suite.affectedUsers, nil)
+	suite.ctrl.Store.On("DeleteRoleByID", timeout, suite.tx, suite.sampleRoleID).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.affectedUsers[0]).Return([]store.Permission{}, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, suite.affectedUsers[0]).Return([]store.Permission{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, suite.affectedUsers[0], []store.Permission{}).
+		Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeleteRoleByID(timeout, suite.sampleRoleID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_DeleteRoleByID(t *testing.T) {
+	suite.Run(t, new(ControllerDeleteRoleByIDSuite))
+}
+
+// ControllerUpdateRolesByUserSuite tests Controller.UpdateRolesByUser.
+type ControllerUpdateRolesByUserSuite struct {
+	suite.Suite
+	ctrl         *ControllerMock
+	tx           *testutil.DBTx
+	sampleUserID uuid.UUID
+	sampleRoles  []uuid.UUID
+}
+
+func (suite *ControllerUpdateRolesByUserSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.sampleRoles = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+}
+
+func (suite *ControllerUpdateRolesByUserSuite) TestAssureUserExistsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureUserExists", timeout, suite.tx, suite.sampleUserID).
+		Return(meh.NewNotFoundErr("sad life", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByUser(timeout, suite.sampleUserID, suite.sampleRoles)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrNotFound, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRolesByUserSuite) TestUnknownRole() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureUserExists", timeout, suite.tx, suite.sampleUserID).Return(nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, suite.sampleRoles[0]).Return(store.Role{}, nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, suite.sampleRoles[1]).
+		Return(store.Role{}, meh.NewNotFoundErr("sad life", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByUser(timeout, suite.sampleUserID, suite.sampleRoles)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRolesByUserSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureUserExists", timeout, suite.tx, suite.sampleUserID).Return(nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, mock.Anything).Return(store.Role{}, nil)
+	suite.ctrl.Store.On("UpdateRolesByUser", timeout, suite.tx, suite.sampleUserID, suite.sampleRoles).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByUser(timeout, suite.sampleUserID, suite.sampleRoles)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRolesByUserSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	rolePermissions := []store.Permission{{Name: permission.ViewAnyOperationPermissionName}}
+	suite.ctrl.Store.On("AssureUserExists", timeout, suite.tx, suite.sampleUserID).Return(nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, mock.Anything).Return(store.Role{}, nil).Times(2)
+	suite.ctrl.Store.On("UpdateRolesByUser", timeout, suite.tx, suite.sampleUserID, suite.sampleRoles).Return(nil)
+	suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, suite.sampleUserID).Return([]store.Permission{}, nil)
+	suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, suite.sampleUserID).Return(rolePermissions, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, suite.sampleUserID, rolePermissions).Return(nil)
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		// Pass duplicates in order to assure that they are removed.
+		err := suite.ctrl.Ctrl.UpdateRolesByUser(timeout, suite.sampleUserID,
+			append(suite.sampleRoles, suite.sampleRoles...))
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateRolesByUser(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateRolesByUserSuite))
+}
+
+// ControllerUpdateRolesByGroupSuite tests Controller.UpdateRolesByGroup.
+type ControllerUpdateRolesByGroupSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleGroupID uuid.UUID
+	sampleRoles   []uuid.UUID
+	sampleMembers []uuid.UUID
+}
+
+func (suite *ControllerUpdateRolesByGroupSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleGroupID = testutil.NewUUIDV4()
+	suite.sampleRoles = []uuid.UUID{testutil.NewUUIDV4()}
+	suite.sampleMembers = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+}
+
+func (suite *ControllerUpdateRolesByGroupSuite) TestAssureGroupExistsFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureGroupExists", timeout, suite.tx, suite.sampleGroupID).
+		Return(meh.NewNotFoundErr("sad life", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByGroup(timeout, suite.sampleGroupID, suite.sampleRoles)
+		suite.Error(err, "should fail")
+		suite.Equal(meh.ErrNotFound, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRolesByGroupSuite) TestRetrieveMembersFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureGroupExists", timeout, suite.tx, suite.sampleGroupID).Return(nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, suite.sampleRoles[0]).Return(store.Role{}, nil)
+	suite.ctrl.Store.On("UpdateRolesByGroup", timeout, suite.tx, suite.sampleGroupID, suite.sampleRoles).Return(nil)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleGroupID).Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByGroup(timeout, suite.sampleGroupID, suite.sampleRoles)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateRolesByGroupSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AssureGroupExists", timeout, suite.tx, suite.sampleGroupID).Return(nil)
+	suite.ctrl.Store.On("RoleByID", timeout, suite.tx, suite.sampleRoles[0]).Return(store.Role{}, nil)
+	suite.ctrl.Store.On("UpdateRolesByGroup", timeout, suite.tx, suite.sampleGroupID, suite.sampleRoles).Return(nil)
+	suite.ctrl.Store.On("MembersByGroup", timeout, suite.tx, suite.sampleGroupID).Return(suite.sampleMembers, nil)
+	for _, member := range suite.sampleMembers {
+		suite.ctrl.Store.On("PermissionsByUser", timeout, suite.tx, member).Return([]store.Permission{}, nil).Once()
+		suite.ctrl.Store.On("RolePermissionsByUser", timeout, suite.tx, member).Return([]store.Permission{}, nil).Once()
+		suite.ctrl.Notifier.On("NotifyPermissionsUpdated", timeout, suite.tx, member, []store.Permission{}).
+			Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateRolesByGroup(timeout, suite.sampleGroupID, suite.sampleRoles)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateRolesByGroup(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateRolesByGroupSuite))
+}
@@ -6,14 +6,17 @@ import (
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/controller"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"go.uber.org/zap"
 )
 
 // Serve the endpoints via HTTP.
-func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, ctrl *controller.Controller) error {
+func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret string, ctrl *controller.Controller,
+	deadLetters kafkautil.DeadLetterAdmin) error {
 	httpendpoints.ApplyDefaultErrorHTTPMapping()
 	r := httpendpoints.NewEngine(logger)
 	populateRoutes(r, logger, authSecret, ctrl)
+	kafkautil.PopulateDeadLetterRoutes(r, logger, authSecret, deadLetters)
 	err := httpendpoints.Serve(lifetime, r, addr)
 	if err != nil {
 		return meh.Wrap(err, "serve", meh.Details{"addr": addr})
@@ -24,4 +27,14 @@ func Serve(lifetime context.Context, logger *zap.Logger, addr string, authSecret
 func populateRoutes(r *gin.Engine, logger *zap.Logger, secret string, ctrl *controller.Controller) {
 	r.GET("/user/:userID", httpendpoints.GinHandlerFunc(logger, secret, handleGetPermissionsByUser(ctrl)))
 	r.PUT("/user/:userID", httpendpoints.GinHandlerFunc(logger, secret, handleUpdatePermissionsByUser(ctrl)))
+	r.GET("/user/:userID/roles", httpendpoints.GinHandlerFunc(logger, secret, handleGetRolesByUser(ctrl)))
+	r.PUT("/user/:userID/roles", httpendpoints.GinHandlerFunc(logger, secret, handleUpdateRolesByUser(ctrl)))
+	r.GET("/user/:userID/effective", httpendpoints.GinHandlerFunc(logger, secret, handleGetEffectivePermissionsByUser(ctrl)))
+	r.GET("/group/:groupID/roles", httpendpoints.GinHandlerFunc(logger, secret, handleGetRolesByGroup(ctrl)))
+	r.PUT("/group/:groupID/roles", httpendpoints.GinHandlerFunc(logger, secret, handleUpdateRolesByGroup(ctrl)))
+	r.GET("/roles", httpendpoints.GinHandlerFunc(logger, secret, handleGetRoles(ctrl)))
+	r.POST("/roles", httpendpoints.GinHandlerFunc(logger, secret, handleCreateRole(ctrl)))
+	r.GET("/roles/:roleID", httpendpoints.GinHandlerFunc(logger, secret, handleGetRoleByID(ctrl)))
+	r.PUT("/roles/:roleID", httpendpoints.GinHandlerFunc(logger, secret, handleUpdateRole(ctrl)))
+	r.DELETE("/roles/:roleID", httpendpoints.GinHandlerFunc(logger, secret, handleDeleteRoleByID(ctrl)))
 }
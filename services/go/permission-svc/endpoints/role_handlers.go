@@ -0,0 +1,399 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"net/http"
+)
+
+// publicRole is the public representation of store.Role.
+type publicRole struct {
+	// ID identifies the role.
+	ID uuid.UUID `json:"id"`
+	// Label of the role.
+	Label string `json:"label"`
+	// Description of the role.
+	Description string `json:"description"`
+	// Permissions granted by the role.
+	Permissions []publicPermission `json:"permissions"`
+}
+
+// publicRoleFromStore converts a store.Role to publicRole.
+func publicRoleFromStore(s store.Role) publicRole {
+	permissions := make([]publicPermission, 0, len(s.Permissions))
+	for _, p := range s.Permissions {
+		permissions = append(permissions, publicPermissionFromPermission(p))
+	}
+	return publicRole{
+		ID:          s.ID,
+		Label:       s.Label,
+		Description: s.Description,
+		Permissions: permissions,
+	}
+}
+
+// storeRoleFromPublic converts a publicRole to store.Role.
+func storeRoleFromPublic(public publicRole) store.Role {
+	permissions := make([]store.Permission, 0, len(public.Permissions))
+	for _, p := range public.Permissions {
+		permissions = append(permissions, permissionFromPublic(p))
+	}
+	return store.Role{
+		ID:          public.ID,
+		Label:       public.Label,
+		Description: public.Description,
+		Permissions: permissions,
+	}
+}
+
+// handleGetRolesStore are the dependencies needed for handleGetRoles.
+type handleGetRolesStore interface {
+	Roles(ctx context.Context) ([]store.Role, error)
+}
+
+// handleGetRoles retrieves all roles.
+func handleGetRoles(s handleGetRolesStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.ViewPermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Retrieve.
+		roles, err := s.Roles(c.Request.Context())
+		if err != nil {
+			return meh.Wrap(err, "retrieve roles", nil)
+		}
+		publicRoles := make([]publicRole, 0, len(roles))
+		for _, role := range roles {
+			publicRoles = append(publicRoles, publicRoleFromStore(role))
+		}
+		c.JSON(http.StatusOK, publicRoles)
+		return nil
+	}
+}
+
+// handleGetRoleByIDStore are the dependencies needed for handleGetRoleByID.
+type handleGetRoleByIDStore interface {
+	RoleByID(ctx context.Context, roleID uuid.UUID) (store.Role, error)
+}
+
+// handleGetRoleByID retrieves a role by its id.
+func handleGetRoleByID(s handleGetRoleByIDStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.ViewPermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract role id.
+		roleIDStr := c.Param("roleID")
+		roleID, err := uuid.FromString(roleIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse role id", meh.Details{"was": roleIDStr})
+		}
+		// Retrieve.
+		role, err := s.RoleByID(c.Request.Context(), roleID)
+		if err != nil {
+			return meh.Wrap(err, "retrieve role", meh.Details{"role_id": roleID})
+		}
+		c.JSON(http.StatusOK, publicRoleFromStore(role))
+		return nil
+	}
+}
+
+// handleCreateRoleStore are the dependencies needed for handleCreateRole.
+type handleCreateRoleStore interface {
+	CreateRole(ctx context.Context, create store.Role) (store.Role, error)
+}
+
+// handleCreateRole creates a role.
+func handleCreateRole(s handleCreateRoleStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.UpdatePermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Parse body.
+		var toCreatePublic publicRole
+		err = json.NewDecoder(c.Request.Body).Decode(&toCreatePublic)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		toCreate := storeRoleFromPublic(toCreatePublic)
+		// Create.
+		created, err := s.CreateRole(c.Request.Context(), toCreate)
+		if err != nil {
+			return meh.Wrap(err, "create role", meh.Details{"create": toCreate})
+		}
+		c.JSON(http.StatusOK, publicRoleFromStore(created))
+		return nil
+	}
+}
+
+// handleUpdateRoleStore are the dependencies needed for handleUpdateRole.
+type handleUpdateRoleStore interface {
+	UpdateRole(ctx context.Context, update store.Role) error
+}
+
+// handleUpdateRole updates a role.
+func handleUpdateRole(s handleUpdateRoleStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.UpdatePermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract role id.
+		roleIDStr := c.Param("roleID")
+		roleID, err := uuid.FromString(roleIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse role id", meh.Details{"was": roleIDStr})
+		}
+		// Parse body.
+		var toUpdatePublic publicRole
+		err = json.NewDecoder(c.Request.Body).Decode(&toUpdatePublic)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		if roleID != toUpdatePublic.ID {
+			return meh.NewBadInputErr("id mismatch", meh.Details{
+				"id_from_path": roleID,
+				"id_from_body": toUpdatePublic.ID,
+			})
+		}
+		toUpdate := storeRoleFromPublic(toUpdatePublic)
+		// Update.
+		err = s.UpdateRole(c.Request.Context(), toUpdate)
+		if err != nil {
+			return meh.Wrap(err, "update role", meh.Details{"update": toUpdate})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// handleDeleteRoleByIDStore are the dependencies needed for
+// handleDeleteRoleByID.
+type handleDeleteRoleByIDStore interface {
+	DeleteRoleByID(ctx context.Context, roleID uuid.UUID) error
+}
+
+// handleDeleteRoleByID deletes a role.
+func handleDeleteRoleByID(s handleDeleteRoleByIDStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.UpdatePermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract role id.
+		roleIDStr := c.Param("roleID")
+		roleID, err := uuid.FromString(roleIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse role id", meh.Details{"was": roleIDStr})
+		}
+		// Delete.
+		err = s.DeleteRoleByID(c.Request.Context(), roleID)
+		if err != nil {
+			return meh.Wrap(err, "delete role", meh.Details{"role_id": roleID})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// assureSelfOrViewPermissions assures that the given auth.Token belongs to the
+// user with the given id or has the permission for viewing permissions of other
+// users.
+func assureSelfOrViewPermissions(token auth.Token, userID uuid.UUID) error {
+	if token.IsAuthenticated && token.UserID == userID {
+		return nil
+	}
+	err := auth.AssurePermission(token, permission.ViewPermissions())
+	if err != nil {
+		return meh.Wrap(err, "assure permission", nil)
+	}
+	return nil
+}
+
+// handleGetRolesByUserStore are the dependencies needed for
+// handleGetRolesByUser.
+type handleGetRolesByUserStore interface {
+	RolesByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// handleGetRolesByUser retrieves the ids of roles, directly assigned to a user.
+func handleGetRolesByUser(s handleGetRolesByUserStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Extract user id.
+		userIDStr := c.Param("userID")
+		userID, err := uuid.FromString(userIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse user id", meh.Details{"was": userIDStr})
+		}
+		// Check permissions.
+		err = assureSelfOrViewPermissions(token, userID)
+		if err != nil {
+			return meh.Wrap(err, "assure self or view permissions", nil)
+		}
+		// Retrieve.
+		roles, err := s.RolesByUser(c.Request.Context(), userID)
+		if err != nil {
+			return meh.Wrap(err, "roles by user", meh.Details{"user_id": userID})
+		}
+		c.JSON(http.StatusOK, roles)
+		return nil
+	}
+}
+
+// handleUpdateRolesByUserStore are the dependencies needed for
+// handleUpdateRolesByUser.
+type handleUpdateRolesByUserStore interface {
+	UpdateRolesByUser(ctx context.Context, userID uuid.UUID, roles []uuid.UUID) error
+}
+
+// handleUpdateRolesByUser sets the roles, directly assigned to a user.
+func handleUpdateRolesByUser(s handleUpdateRolesByUserStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.UpdatePermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract user id.
+		userIDStr := c.Param("userID")
+		userID, err := uuid.FromString(userIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse user id", meh.Details{"was": userIDStr})
+		}
+		// Parse body.
+		var roles []uuid.UUID
+		err = json.NewDecoder(c.Request.Body).Decode(&roles)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		// Update.
+		err = s.UpdateRolesByUser(c.Request.Context(), userID, roles)
+		if err != nil {
+			return meh.Wrap(err, "update roles by user", meh.Details{
+				"user_id": userID,
+				"roles":   roles,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// handleGetEffectivePermissionsByUserStore are the dependencies needed for
+// handleGetEffectivePermissionsByUser.
+type handleGetEffectivePermissionsByUserStore interface {
+	EffectivePermissionsByUser(ctx context.Context, userID uuid.UUID) ([]store.Permission, error)
+}
+
+// handleGetEffectivePermissionsByUser retrieves the effective permissions of a
+// user, including the ones granted via roles.
+func handleGetEffectivePermissionsByUser(s handleGetEffectivePermissionsByUserStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Extract user id.
+		userIDStr := c.Param("userID")
+		userID, err := uuid.FromString(userIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse user id", meh.Details{"was": userIDStr})
+		}
+		// Check permissions.
+		err = assureSelfOrViewPermissions(token, userID)
+		if err != nil {
+			return meh.Wrap(err, "assure self or view permissions", nil)
+		}
+		// Retrieve.
+		permissions, err := s.EffectivePermissionsByUser(c.Request.Context(), userID)
+		if err != nil {
+			return meh.Wrap(err, "effective permissions by user", meh.Details{"user_id": userID})
+		}
+		publicPermissions := make([]publicPermission, 0, len(permissions))
+		for _, p := range permissions {
+			publicPermissions = append(publicPermissions, publicPermissionFromPermission(p))
+		}
+		c.JSON(http.StatusOK, publicPermissions)
+		return nil
+	}
+}
+
+// handleGetRolesByGroupStore are the dependencies needed for
+// handleGetRolesByGroup.
+type handleGetRolesByGroupStore interface {
+	RolesByGroup(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// handleGetRolesByGroup retrieves the ids of roles, assigned to a group.
+func handleGetRolesByGroup(s handleGetRolesByGroupStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.ViewPermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract group id.
+		groupIDStr := c.Param("groupID")
+		groupID, err := uuid.FromString(groupIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse group id", meh.Details{"was": groupIDStr})
+		}
+		// Retrieve.
+		roles, err := s.RolesByGroup(c.Request.Context(), groupID)
+		if err != nil {
+			return meh.Wrap(err, "roles by group", meh.Details{"group_id": groupID})
+		}
+		c.JSON(http.StatusOK, roles)
+		return nil
+	}
+}
+
+// handleUpdateRolesByGroupStore are the dependencies needed for
+// handleUpdateRolesByGroup.
+type handleUpdateRolesByGroupStore interface {
+	UpdateRolesByGroup(ctx context.Context, groupID uuid.UUID, roles []uuid.UUID) error
+}
+
+// handleUpdateRolesByGroup sets the roles, assigned to a group.
+func handleUpdateRolesByGroup(s handleUpdateRolesByGroupStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		// Check permissions.
+		err := auth.AssurePermission(token, permission.UpdatePermissions())
+		if err != nil {
+			return meh.Wrap(err, "assure permission", nil)
+		}
+		// Extract group id.
+		groupIDStr := c.Param("groupID")
+		groupID, err := uuid.FromString(groupIDStr)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse group id", meh.Details{"was": groupIDStr})
+		}
+		// Parse body.
+		var roles []uuid.UUID
+		err = json.NewDecoder(c.Request.Body).Decode(&roles)
+		if err != nil {
+			return meh.NewBadInputErrFromErr(err, "parse body", nil)
+		}
+		// Update.
+		err = s.UpdateRolesByGroup(c.Request.Context(), groupID, roles)
+		if err != nil {
+			return meh.Wrap(err, "update roles by group", meh.Details{
+				"group_id": groupID,
+				"roles":    roles,
+			})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
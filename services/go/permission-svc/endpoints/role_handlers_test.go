@@ -0,0 +1,702 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// handleGetRolesStoreMock mocks handleGetRolesStore.
+type handleGetRolesStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleGetRolesStoreMock) Roles(ctx context.Context) ([]store.Role, error) {
+	args := m.Called(ctx)
+	var roles []store.Role
+	if argsRoles := args.Get(0); argsRoles != nil {
+		roles = argsRoles.([]store.Role)
+	}
+	return roles, args.Error(1)
+}
+
+// handleGetRolesSuite tests handleGetRoles.
+type handleGetRolesSuite struct {
+	suite.Suite
+	s                 *handleGetRolesStoreMock
+	r                 *gin.Engine
+	tokenOK           auth.Token
+	sampleRoles       []store.Role
+	samplePublicRoles []publicRole
+}
+
+func (suite *handleGetRolesSuite) SetupTest() {
+	suite.s = &handleGetRolesStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.GET("/roles", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleGetRoles(suite.s)))
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.ViewPermissionsPermissionName}},
+	}
+	suite.sampleRoles = []store.Role{
+		{
+			ID:          testutil.NewUUIDV4(),
+			Label:       "radio operator",
+			Description: "whistle",
+			Permissions: []store.Permission{
+				{
+					Name:    permission.DeliverAnyRadioDeliveryPermissionName,
+					Options: nulls.NewJSONRawMessage([]byte(`{"operations":[]}`)),
+				},
+			},
+		},
+		{
+			ID:          testutil.NewUUIDV4(),
+			Label:       "dispatcher",
+			Description: "glove",
+			Permissions: []store.Permission{},
+		},
+	}
+	suite.samplePublicRoles = make([]publicRole, 0, len(suite.sampleRoles))
+	for _, role := range suite.sampleRoles {
+		suite.samplePublicRoles = append(suite.samplePublicRoles, publicRoleFromStore(role))
+	}
+}
+
+func (suite *handleGetRolesSuite) TestNotAuthenticated() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/roles",
+		Token:  auth.Token{IsAuthenticated: false},
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetRolesSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/roles",
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetRolesSuite) TestRetrieveFail() {
+	suite.s.On("Roles", mock.Anything).Return(nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/roles",
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetRolesSuite) TestOK() {
+	suite.s.On("Roles", mock.Anything).Return(suite.sampleRoles, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/roles",
+		Token:  suite.tokenOK,
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicRole
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicRoles, got, "should return correct body")
+}
+
+func Test_handleGetRoles(t *testing.T) {
+	suite.Run(t, new(handleGetRolesSuite))
+}
+
+// handleCreateRoleStoreMock mocks handleCreateRoleStore.
+type handleCreateRoleStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleCreateRoleStoreMock) CreateRole(ctx context.Context, create store.Role) (store.Role, error) {
+	args := m.Called(ctx, create)
+	return args.Get(0).(store.Role), args.Error(1)
+}
+
+// handleCreateRoleSuite tests handleCreateRole.
+type handleCreateRoleSuite struct {
+	suite.Suite
+	s            *handleCreateRoleStoreMock
+	r            *gin.Engine
+	tokenOK      auth.Token
+	sampleCreate store.Role
+}
+
+func (suite *handleCreateRoleSuite) SetupTest() {
+	suite.s = &handleCreateRoleStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.POST("/roles", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleCreateRole(suite.s)))
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.UpdatePermissionsPermissionName}},
+	}
+	suite.sampleCreate = store.Role{
+		Label:       "operation lead",
+		Description: "branch",
+		Permissions: []store.Permission{{Name: permission.UpdateOperationPermissionName}},
+	}
+}
+
+func (suite *handleCreateRoleSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{{Name: permission.ViewPermissionsPermissionName}}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/roles",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleCreate))),
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleCreateRoleSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/roles",
+		Body:   strings.NewReader("{invalid"),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleCreateRoleSuite) TestInvalidPermissions() {
+	suite.s.On("CreateRole", mock.Anything, suite.sampleCreate).
+		Return(store.Role{}, meh.NewBadInputErr("sad life", nil))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/roles",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleCreate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleCreateRoleSuite) TestOK() {
+	created := suite.sampleCreate
+	created.ID = testutil.NewUUIDV4()
+	suite.s.On("CreateRole", mock.Anything, suite.sampleCreate).Return(created, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/roles",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleCreate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got publicRole
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(publicRoleFromStore(created), got, "should return correct body")
+}
+
+func Test_handleCreateRole(t *testing.T) {
+	suite.Run(t, new(handleCreateRoleSuite))
+}
+
+// handleUpdateRoleStoreMock mocks handleUpdateRoleStore.
+type handleUpdateRoleStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleUpdateRoleStoreMock) UpdateRole(ctx context.Context, update store.Role) error {
+	return m.Called(ctx, update).Error(0)
+}
+
+// handleUpdateRoleSuite tests handleUpdateRole.
+type handleUpdateRoleSuite struct {
+	suite.Suite
+	s            *handleUpdateRoleStoreMock
+	r            *gin.Engine
+	tokenOK      auth.Token
+	sampleUpdate store.Role
+}
+
+func (suite *handleUpdateRoleSuite) SetupTest() {
+	suite.s = &handleUpdateRoleStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.PUT("/roles/:roleID", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleUpdateRole(suite.s)))
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.UpdatePermissionsPermissionName}},
+	}
+	suite.sampleUpdate = store.Role{
+		ID:          testutil.NewUUIDV4(),
+		Label:       "dispatcher",
+		Description: "staple",
+		Permissions: []store.Permission{{Name: permission.ManageIntelDeliveryPermissionName}},
+	}
+}
+
+func (suite *handleUpdateRoleSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleUpdate.ID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleUpdate))),
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRoleSuite) TestInvalidID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    "/roles/meow",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleUpdate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRoleSuite) TestIDMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/roles/%s", testutil.NewUUIDV4().String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleUpdate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRoleSuite) TestUpdateFail() {
+	suite.s.On("UpdateRole", mock.Anything, suite.sampleUpdate).Return(errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleUpdate.ID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleUpdate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRoleSuite) TestOK() {
+	suite.s.On("UpdateRole", mock.Anything, suite.sampleUpdate).Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleUpdate.ID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(publicRoleFromStore(suite.sampleUpdate))),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUpdateRole(t *testing.T) {
+	suite.Run(t, new(handleUpdateRoleSuite))
+}
+
+// handleDeleteRoleByIDStoreMock mocks handleDeleteRoleByIDStore.
+type handleDeleteRoleByIDStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleDeleteRoleByIDStoreMock) DeleteRoleByID(ctx context.Context, roleID uuid.UUID) error {
+	return m.Called(ctx, roleID).Error(0)
+}
+
+// handleDeleteRoleByIDSuite tests handleDeleteRoleByID.
+type handleDeleteRoleByIDSuite struct {
+	suite.Suite
+	s            *handleDeleteRoleByIDStoreMock
+	r            *gin.Engine
+	tokenOK      auth.Token
+	sampleRoleID uuid.UUID
+}
+
+func (suite *handleDeleteRoleByIDSuite) SetupTest() {
+	suite.s = &handleDeleteRoleByIDStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.DELETE("/roles/:roleID", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleDeleteRoleByID(suite.s)))
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.UpdatePermissionsPermissionName}},
+	}
+	suite.sampleRoleID = testutil.NewUUIDV4()
+}
+
+func (suite *handleDeleteRoleByIDSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleRoleID.String()),
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleDeleteRoleByIDSuite) TestNotFound() {
+	suite.s.On("DeleteRoleByID", mock.Anything, suite.sampleRoleID).Return(meh.NewNotFoundErr("sad life", nil))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleRoleID.String()),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusNotFound, rr.Code, "should return correct code")
+}
+
+func (suite *handleDeleteRoleByIDSuite) TestOK() {
+	suite.s.On("DeleteRoleByID", mock.Anything, suite.sampleRoleID).Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/roles/%s", suite.sampleRoleID.String()),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleDeleteRoleByID(t *testing.T) {
+	suite.Run(t, new(handleDeleteRoleByIDSuite))
+}
+
+// handleUpdateRolesByUserStoreMock mocks handleUpdateRolesByUserStore.
+type handleUpdateRolesByUserStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleUpdateRolesByUserStoreMock) UpdateRolesByUser(ctx context.Context, userID uuid.UUID, roles []uuid.UUID) error {
+	return m.Called(ctx, userID, roles).Error(0)
+}
+
+// handleUpdateRolesByUserSuite tests handleUpdateRolesByUser.
+type handleUpdateRolesByUserSuite struct {
+	suite.Suite
+	s            *handleUpdateRolesByUserStoreMock
+	r            *gin.Engine
+	tokenOK      auth.Token
+	sampleUserID uuid.UUID
+	sampleRoles  []uuid.UUID
+}
+
+func (suite *handleUpdateRolesByUserSuite) SetupTest() {
+	suite.s = &handleUpdateRolesByUserStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.PUT("/user/:userID/roles", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleUpdateRolesByUser(suite.s)))
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.tokenOK = auth.Token{
+		UserID:          suite.sampleUserID,
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.UpdatePermissionsPermissionName}},
+	}
+	suite.sampleRoles = []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()}
+}
+
+func (suite *handleUpdateRolesByUserSuite) TestSelfWithoutPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/user/%s/roles", suite.sampleUserID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByUserSuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/user/%s/roles", suite.sampleUserID.String()),
+		Body:   strings.NewReader(`["meow"]`),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByUserSuite) TestUpdateFail() {
+	suite.s.On("UpdateRolesByUser", mock.Anything, suite.sampleUserID, suite.sampleRoles).Return(errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/user/%s/roles", suite.sampleUserID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByUserSuite) TestOK() {
+	suite.s.On("UpdateRolesByUser", mock.Anything, suite.sampleUserID, suite.sampleRoles).Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/user/%s/roles", suite.sampleUserID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUpdateRolesByUser(t *testing.T) {
+	suite.Run(t, new(handleUpdateRolesByUserSuite))
+}
+
+// handleGetEffectivePermissionsByUserStoreMock mocks
+// handleGetEffectivePermissionsByUserStore.
+type handleGetEffectivePermissionsByUserStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleGetEffectivePermissionsByUserStoreMock) EffectivePermissionsByUser(ctx context.Context, userID uuid.UUID) ([]store.Permission, error) {
+	args := m.Called(ctx, userID)
+	var p []store.Permission
+	if argsPermissions := args.Get(0); argsPermissions != nil {
+		p = argsPermissions.([]store.Permission)
+	}
+	return p, args.Error(1)
+}
+
+// handleGetEffectivePermissionsByUserSuite tests
+// handleGetEffectivePermissionsByUser.
+type handleGetEffectivePermissionsByUserSuite struct {
+	suite.Suite
+	s                 *handleGetEffectivePermissionsByUserStoreMock
+	r                 *gin.Engine
+	tokenOK           auth.Token
+	sampleUserID      uuid.UUID
+	samplePermissions []store.Permission
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) SetupTest() {
+	suite.s = &handleGetEffectivePermissionsByUserStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.GET("/user/:userID/effective", httpendpoints.GinHandlerFunc(zap.NewNop(), "",
+		handleGetEffectivePermissionsByUser(suite.s)))
+	suite.sampleUserID = testutil.NewUUIDV4()
+	suite.tokenOK = auth.Token{
+		UserID:          suite.sampleUserID,
+		IsAuthenticated: true,
+	}
+	suite.samplePermissions = []store.Permission{
+		{Name: permission.ViewUserPermissionName},
+		{
+			Name:    permission.ViewAnyIntelPermissionName,
+			Options: nulls.NewJSONRawMessage([]byte(`{"operations":[]}`)),
+		},
+	}
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) TestNotAuthenticated() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/user/%s/effective", suite.sampleUserID.String()),
+		Token:  auth.Token{UserID: suite.sampleUserID},
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) TestOtherWithoutPermission() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/user/%s/effective", testutil.NewUUIDV4().String()),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) TestRetrieveFail() {
+	suite.s.On("EffectivePermissionsByUser", mock.Anything, suite.sampleUserID).Return(nil, errors.New("sad life"))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/user/%s/effective", suite.sampleUserID.String()),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) TestOKOther() {
+	otherUserID := testutil.NewUUIDV4()
+	token := suite.tokenOK
+	token.Permissions = []permission.Permission{{Name: permission.ViewPermissionsPermissionName}}
+	suite.s.On("EffectivePermissionsByUser", mock.Anything, otherUserID).Return(suite.samplePermissions, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/user/%s/effective", otherUserID.String()),
+		Token:  token,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetEffectivePermissionsByUserSuite) TestOKSelf() {
+	suite.s.On("EffectivePermissionsByUser", mock.Anything, suite.sampleUserID).Return(suite.samplePermissions, nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/user/%s/effective", suite.sampleUserID.String()),
+		Token:  suite.tokenOK,
+	})
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got []publicPermission
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal([]publicPermission{
+		publicPermissionFromPermission(suite.samplePermissions[0]),
+		publicPermissionFromPermission(suite.samplePermissions[1]),
+	}, got, "should return correct body")
+}
+
+func Test_handleGetEffectivePermissionsByUser(t *testing.T) {
+	suite.Run(t, new(handleGetEffectivePermissionsByUserSuite))
+}
+
+// handleUpdateRolesByGroupStoreMock mocks handleUpdateRolesByGroupStore.
+type handleUpdateRolesByGroupStoreMock struct {
+	mock.Mock
+}
+
+func (m *handleUpdateRolesByGroupStoreMock) UpdateRolesByGroup(ctx context.Context, groupID uuid.UUID, roles []uuid.UUID) error {
+	return m.Called(ctx, groupID, roles).Error(0)
+}
+
+// handleUpdateRolesByGroupSuite tests handleUpdateRolesByGroup.
+type handleUpdateRolesByGroupSuite struct {
+	suite.Suite
+	s             *handleUpdateRolesByGroupStoreMock
+	r             *gin.Engine
+	tokenOK       auth.Token
+	sampleGroupID uuid.UUID
+	sampleRoles   []uuid.UUID
+}
+
+func (suite *handleUpdateRolesByGroupSuite) SetupTest() {
+	suite.s = &handleUpdateRolesByGroupStoreMock{}
+	suite.r = testutil.NewGinEngine()
+	suite.r.PUT("/group/:groupID/roles", httpendpoints.GinHandlerFunc(zap.NewNop(), "", handleUpdateRolesByGroup(suite.s)))
+	suite.tokenOK = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{{Name: permission.UpdatePermissionsPermissionName}},
+	}
+	suite.sampleGroupID = testutil.NewUUIDV4()
+	suite.sampleRoles = []uuid.UUID{testutil.NewUUIDV4()}
+}
+
+func (suite *handleUpdateRolesByGroupSuite) TestMissingPermission() {
+	token := suite.tokenOK
+	token.Permissions = nil
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/group/%s/roles", suite.sampleGroupID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  token,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByGroupSuite) TestInvalidID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    "/group/meow/roles",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByGroupSuite) TestGroupNotFound() {
+	suite.s.On("UpdateRolesByGroup", mock.Anything, suite.sampleGroupID, suite.sampleRoles).
+		Return(meh.NewNotFoundErr("sad life", nil))
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/group/%s/roles", suite.sampleGroupID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusNotFound, rr.Code, "should return correct code")
+}
+
+func (suite *handleUpdateRolesByGroupSuite) TestOK() {
+	suite.s.On("UpdateRolesByGroup", mock.Anything, suite.sampleGroupID, suite.sampleRoles).Return(nil)
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPut,
+		URL:    fmt.Sprintf("/group/%s/roles", suite.sampleGroupID.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.sampleRoles)),
+		Token:  suite.tokenOK,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleUpdateRolesByGroup(t *testing.T) {
+	suite.Run(t, new(handleUpdateRolesByGroupSuite))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 )
@@ -14,12 +15,20 @@ import (
 type Handler interface {
 	// CreateUser creates the user with the given id.
 	CreateUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error
+	// CreateGroup creates the given store.Group.
+	CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error
+	// UpdateGroup updates the given store.Group, identified by its id.
+	UpdateGroup(ctx context.Context, tx pgx.Tx, update store.Group) error
+	// DeleteGroupByID deletes the group with the given id.
+	DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error
 }
 
 // HandlerFn for handling messages.
 func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 	return func(ctx context.Context, tx pgx.Tx, message kafkautil.InboundMessage) error {
 		switch message.Topic {
+		case event.GroupsTopic:
+			return meh.NilOrWrap(p.handleGroupsTopic(ctx, tx, handler, message), "handle groups topic", nil)
 		case event.UsersTopic:
 			return meh.NilOrWrap(p.handleUsersTopic(ctx, tx, handler, message), "handle users topic", nil)
 		}
@@ -50,3 +59,66 @@ func (p *Port) handleUserCreated(ctx context.Context, tx pgx.Tx, handler Handler
 	}
 	return nil
 }
+
+// handleGroupsTopic handles the event.GroupsTopic.
+func (p *Port) handleGroupsTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeGroupCreated:
+		return meh.NilOrWrap(p.handleGroupCreated(ctx, tx, handler, message), "handle group created", nil)
+	case event.TypeGroupDeleted:
+		return meh.NilOrWrap(p.handleGroupDeleted(ctx, tx, handler, message), "handle group deleted", nil)
+	case event.TypeGroupUpdated:
+		return meh.NilOrWrap(p.handleGroupUpdated(ctx, tx, handler, message), "handle group updated", nil)
+	}
+	return nil
+}
+
+// handleGroupCreated handles an event.TypeGroupCreated event.
+func (p *Port) handleGroupCreated(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var groupCreatedEvent event.GroupCreated
+	err := json.Unmarshal(message.RawValue, &groupCreatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", nil)
+	}
+	create := store.Group{
+		ID:      groupCreatedEvent.ID,
+		Members: groupCreatedEvent.Members,
+	}
+	err = handler.CreateGroup(ctx, tx, create)
+	if err != nil {
+		return meh.Wrap(err, "create group", meh.Details{"create": create})
+	}
+	return nil
+}
+
+// handleGroupUpdated handles an event.TypeGroupUpdated event.
+func (p *Port) handleGroupUpdated(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var groupUpdatedEvent event.GroupUpdated
+	err := json.Unmarshal(message.RawValue, &groupUpdatedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", nil)
+	}
+	update := store.Group{
+		ID:      groupUpdatedEvent.ID,
+		Members: groupUpdatedEvent.Members,
+	}
+	err = handler.UpdateGroup(ctx, tx, update)
+	if err != nil {
+		return meh.Wrap(err, "update group", meh.Details{"update": update})
+	}
+	return nil
+}
+
+// handleGroupDeleted handles an event.TypeGroupDeleted event.
+func (p *Port) handleGroupDeleted(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var groupDeletedEvent event.GroupDeleted
+	err := json.Unmarshal(message.RawValue, &groupDeletedEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", nil)
+	}
+	err = handler.DeleteGroupByID(ctx, tx, groupDeletedEvent.ID)
+	if err != nil {
+		return meh.Wrap(err, "delete group", meh.Details{"group_id": groupDeletedEvent.ID})
+	}
+	return nil
+}
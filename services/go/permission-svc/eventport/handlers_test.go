@@ -6,6 +6,7 @@ import (
 	"errors"
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/permission-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
@@ -28,6 +29,18 @@ func (m *HandlerMock) UpdatePermissionsByUser(ctx context.Context, tx pgx.Tx, us
 	return m.Called(ctx, tx, userID, permissions).Error(0)
 }
 
+func (m *HandlerMock) CreateGroup(ctx context.Context, tx pgx.Tx, create store.Group) error {
+	return m.Called(ctx, tx, create).Error(0)
+}
+
+func (m *HandlerMock) UpdateGroup(ctx context.Context, tx pgx.Tx, update store.Group) error {
+	return m.Called(ctx, tx, update).Error(0)
+}
+
+func (m *HandlerMock) DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	return m.Called(ctx, tx, groupID).Error(0)
+}
+
 // PortHandleUserCreatedSuite tests Port.handleUserCreated.
 type PortHandleUserCreatedSuite struct {
 	suite.Suite
@@ -101,3 +114,236 @@ func (suite *PortHandleUserCreatedSuite) TestOK() {
 func TestPort_handlerUserCreated(t *testing.T) {
 	suite.Run(t, new(PortHandleUserCreatedSuite))
 }
+
+// portHandleGroupCreatedSuite tests Port.handleGroupCreated.
+type portHandleGroupCreatedSuite struct {
+	suite.Suite
+	handler      *HandlerMock
+	port         *PortMock
+	sampleEvent  event.GroupCreated
+	sampleCreate store.Group
+}
+
+func (suite *portHandleGroupCreatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.GroupCreated{
+		ID:          testutil.NewUUIDV4(),
+		Title:       "poison",
+		Description: "dead",
+		Operation:   nulls.NewUUID(testutil.NewUUIDV4()),
+		Members:     []uuid.UUID{testutil.NewUUIDV4(), testutil.NewUUIDV4()},
+	}
+	suite.sampleCreate = store.Group{
+		ID:      suite.sampleEvent.ID,
+		Members: suite.sampleEvent.Members,
+	}
+}
+
+func (suite *portHandleGroupCreatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.GroupsTopic,
+		EventType: event.TypeGroupCreated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleGroupCreatedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupCreatedSuite) TestCreateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("CreateGroup", timeout, tx, suite.sampleCreate).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupCreatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("CreateGroup", timeout, tx, suite.sampleCreate).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleGroupCreated(t *testing.T) {
+	suite.Run(t, new(portHandleGroupCreatedSuite))
+}
+
+// portHandleGroupUpdatedSuite tests Port.handleGroupUpdated.
+type portHandleGroupUpdatedSuite struct {
+	suite.Suite
+	handler      *HandlerMock
+	port         *PortMock
+	sampleEvent  event.GroupUpdated
+	sampleUpdate store.Group
+}
+
+func (suite *portHandleGroupUpdatedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.GroupUpdated{
+		ID:          testutil.NewUUIDV4(),
+		Title:       "shallow",
+		Description: "heat",
+		Members:     []uuid.UUID{testutil.NewUUIDV4()},
+	}
+	suite.sampleUpdate = store.Group{
+		ID:      suite.sampleEvent.ID,
+		Members: suite.sampleEvent.Members,
+	}
+}
+
+func (suite *portHandleGroupUpdatedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.GroupsTopic,
+		EventType: event.TypeGroupUpdated,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleGroupUpdatedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupUpdatedSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateGroup", timeout, tx, suite.sampleUpdate).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupUpdatedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("UpdateGroup", timeout, tx, suite.sampleUpdate).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleGroupUpdated(t *testing.T) {
+	suite.Run(t, new(portHandleGroupUpdatedSuite))
+}
+
+// portHandleGroupDeletedSuite tests Port.handleGroupDeleted.
+type portHandleGroupDeletedSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.GroupDeleted
+}
+
+func (suite *portHandleGroupDeletedSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.GroupDeleted{
+		ID: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *portHandleGroupDeletedSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.GroupsTopic,
+		EventType: event.TypeGroupDeleted,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleGroupDeletedSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupDeletedSuite) TestDeleteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("DeleteGroupByID", timeout, tx, suite.sampleEvent.ID).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleGroupDeletedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("DeleteGroupByID", timeout, tx, suite.sampleEvent.ID).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleGroupDeleted(t *testing.T) {
+	suite.Run(t, new(portHandleGroupDeletedSuite))
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+)
+
+// Group of users, that roles can be assigned to.
+type Group struct {
+	// ID identifies the group.
+	ID uuid.UUID
+	// Members of the group.
+	Members []uuid.UUID
+}
+
+// CreateGroup creates the given Group.
+func (m *Mall) CreateGroup(ctx context.Context, tx pgx.Tx, create Group) error {
+	q, _, err := m.dialect.Insert(goqu.T("groups")).Rows(goqu.Record{
+		"id": create.ID,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	err = m.updateGroupMembers(ctx, tx, create.ID, create.Members)
+	if err != nil {
+		return meh.Wrap(err, "update group members", meh.Details{
+			"group_id":      create.ID,
+			"group_members": create.Members,
+		})
+	}
+	return nil
+}
+
+// UpdateGroup updates the given Group, identified by its id.
+func (m *Mall) UpdateGroup(ctx context.Context, tx pgx.Tx, update Group) error {
+	err := m.updateGroupMembers(ctx, tx, update.ID, update.Members)
+	if err != nil {
+		return meh.Wrap(err, "update group members", meh.Details{
+			"group_id":      update.ID,
+			"group_members": update.Members,
+		})
+	}
+	return nil
+}
+
+// updateGroupMembers clears all members for the group with the given id and
+// inserts the new given ones.
+func (m *Mall) updateGroupMembers(ctx context.Context, tx pgx.Tx, groupID uuid.UUID, members []uuid.UUID) error {
+	// Clear.
+	clearMembersQuery, _, err := m.dialect.Delete(goqu.T("group_members")).
+		Where(goqu.C("group").Eq(groupID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "clear-members-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, clearMembersQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec clear-members-query", clearMembersQuery)
+	}
+	// Insert members.
+	if len(members) == 0 {
+		return nil
+	}
+	records := make([]any, 0, len(members))
+	for _, member := range members {
+		records = append(records, goqu.Record{
+			"group": groupID,
+			"user":  member,
+		})
+	}
+	insertMembersQuery, _, err := m.dialect.Insert(goqu.T("group_members")).
+		Rows(records...).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "insert-members-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, insertMembersQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec insert-members-query", insertMembersQuery)
+	}
+	return nil
+}
+
+// DeleteGroupByID deletes the group with the given id.
+func (m *Mall) DeleteGroupByID(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("groups")).
+		Where(goqu.C("id").Eq(groupID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// AssureGroupExists makes sure that the group with the given id exists.
+func (m *Mall) AssureGroupExists(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) error {
+	q, _, err := m.dialect.From(goqu.T("groups")).
+		Select(goqu.C("id")).
+		Where(goqu.C("id").Eq(groupID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return meh.NewNotFoundErr("group not found", nil)
+	}
+	rows.Close()
+	return nil
+}
+
+// MembersByGroup retrieves the ids of all members of the group with the given
+// id.
+func (m *Mall) MembersByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("group_members")).
+		Select(goqu.C("user")).
+		Where(goqu.C("group").Eq(groupID)).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	members, err := m.queryUUIDs(ctx, tx, q)
+	if err != nil {
+		return nil, meh.Wrap(err, "query uuids", nil)
+	}
+	return members, nil
+}
+
+// RolesByGroup retrieves the ids of all roles, that are assigned to the group
+// with the given id.
+func (m *Mall) RolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("group_roles")).
+		Select(goqu.C("role")).
+		Where(goqu.C("group").Eq(groupID)).
+		Order(goqu.C("role").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	roles, err := m.queryUUIDs(ctx, tx, q)
+	if err != nil {
+		return nil, meh.Wrap(err, "query uuids", nil)
+	}
+	return roles, nil
+}
+
+// UpdateRolesByGroup sets the roles, that are assigned to the group with the
+// given id.
+func (m *Mall) UpdateRolesByGroup(ctx context.Context, tx pgx.Tx, groupID uuid.UUID, roles []uuid.UUID) error {
+	// Clear.
+	clearQuery, _, err := m.dialect.Delete(goqu.T("group_roles")).
+		Where(goqu.C("group").Eq(groupID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "clear-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, clearQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec clear-query", clearQuery)
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	// Insert.
+	records := make([]any, 0, len(roles))
+	for _, role := range roles {
+		records = append(records, goqu.Record{
+			"group": groupID,
+			"role":  role,
+		})
+	}
+	insertQuery, _, err := m.dialect.Insert(goqu.T("group_roles")).Rows(records...).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "insert-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, insertQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec insert-query", insertQuery)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+)
+
+// Role is a named set of permissions, that can be assigned to users and
+// groups.
+type Role struct {
+	// ID identifies the role.
+	ID uuid.UUID
+	// Label is a human-readable label for the role like "radio operator".
+	Label string
+	// Description of the role.
+	Description string
+	// Permissions that are granted with the role.
+	Permissions []Permission
+}
+
+// CreateRole creates the given Role and returns it with its assigned id.
+func (m *Mall) CreateRole(ctx context.Context, tx pgx.Tx, create Role) (Role, error) {
+	// Create metadata.
+	q, _, err := m.dialect.Insert(goqu.T("roles")).Rows(goqu.Record{
+		"label":       create.Label,
+		"description": create.Description,
+	}).Returning(goqu.C("id")).ToSQL()
+	if err != nil {
+		return Role{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return Role{}, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Role{}, meh.NewInternalErr("no rows returned", meh.Details{"query": q})
+	}
+	err = rows.Scan(&create.ID)
+	if err != nil {
+		return Role{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	// Create permissions.
+	err = m.updateRolePermissions(ctx, tx, create.ID, create.Permissions)
+	if err != nil {
+		return Role{}, meh.Wrap(err, "update role permissions", meh.Details{"role_id": create.ID})
+	}
+	return create, nil
+}
+
+// updateRolePermissions clears all permissions for the role with the given id
+// and inserts the new given ones.
+func (m *Mall) updateRolePermissions(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, permissions []Permission) error {
+	// Clear.
+	clearQuery, _, err := m.dialect.Delete(goqu.T("role_permissions")).
+		Where(goqu.C("role").Eq(roleID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "clear-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, clearQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec clear-query", clearQuery)
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	// Insert.
+	records := make([]any, 0, len(permissions))
+	for _, p := range permissions {
+		records = append(records, goqu.Record{
+			"role":    roleID,
+			"name":    p.Name,
+			"options": p.Options,
+		})
+	}
+	insertQuery, _, err := m.dialect.Insert(goqu.T("role_permissions")).Rows(records...).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "insert-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, insertQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec insert-query", insertQuery)
+	}
+	return nil
+}
+
+// UpdateRole updates the given Role, identified by its id.
+func (m *Mall) UpdateRole(ctx context.Context, tx pgx.Tx, update Role) error {
+	// Update metadata.
+	q, _, err := m.dialect.Update(goqu.T("roles")).Set(goqu.Record{
+		"label":       update.Label,
+		"description": update.Description,
+	}).Where(goqu.C("id").Eq(update.ID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	// Update permissions.
+	err = m.updateRolePermissions(ctx, tx, update.ID, update.Permissions)
+	if err != nil {
+		return meh.Wrap(err, "update role permissions", meh.Details{"role_id": update.ID})
+	}
+	return nil
+}
+
+// DeleteRoleByID deletes the role with the given id. Assignments to users and
+// groups are removed as well.
+func (m *Mall) DeleteRoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("roles")).
+		Where(goqu.C("id").Eq(roleID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("not found", nil)
+	}
+	return nil
+}
+
+// RoleByID retrieves the Role with the given id.
+func (m *Mall) RoleByID(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) (Role, error) {
+	roles, err := m.rolesWhere(ctx, tx, goqu.C("id").Eq(roleID))
+	if err != nil {
+		return Role{}, meh.Wrap(err, "roles where", meh.Details{"role_id": roleID})
+	}
+	if len(roles) == 0 {
+		return Role{}, meh.NewNotFoundErr("not found", nil)
+	}
+	return roles[0], nil
+}
+
+// Roles retrieves all roles, sorted by their label.
+func (m *Mall) Roles(ctx context.Context, tx pgx.Tx) ([]Role, error) {
+	roles, err := m.rolesWhere(ctx, tx, goqu.L("true"))
+	if err != nil {
+		return nil, meh.Wrap(err, "roles where", nil)
+	}
+	return roles, nil
+}
+
+// rolesWhere retrieves all roles matching the given expression, sorted by their
+// label. Permissions are included.
+func (m *Mall) rolesWhere(ctx context.Context, tx pgx.Tx, where exp.Expression) ([]Role, error) {
+	// Retrieve metadata.
+	q, _, err := m.dialect.From(goqu.T("roles")).
+		Select(goqu.C("id"),
+			goqu.C("label"),
+			goqu.C("description")).
+		Where(where).
+		Order(goqu.C("label").Asc(), goqu.C("id").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	roles := make([]Role, 0)
+	roleIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var role Role
+		err = rows.Scan(&role.ID,
+			&role.Label,
+			&role.Description)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		role.Permissions = make([]Permission, 0)
+		roles = append(roles, role)
+		roleIDs = append(roleIDs, role.ID)
+	}
+	rows.Close()
+	if len(roles) == 0 {
+		return roles, nil
+	}
+	// Retrieve permissions.
+	permissionsQuery, _, err := m.dialect.From(goqu.T("role_permissions")).
+		Select(goqu.C("role"),
+			goqu.C("name"),
+			goqu.C("options")).
+		Where(goqu.C("role").In(roleIDs)).
+		Order(goqu.C("name").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "permissions-query to sql", nil)
+	}
+	permissionRows, err := tx.Query(ctx, permissionsQuery)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query permissions", permissionsQuery)
+	}
+	defer permissionRows.Close()
+	permissionsByRole := make(map[uuid.UUID][]Permission)
+	for permissionRows.Next() {
+		var roleID uuid.UUID
+		var perm Permission
+		err = permissionRows.Scan(&roleID,
+			&perm.Name,
+			&perm.Options)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan permission row", permissionsQuery)
+		}
+		permissionsByRole[roleID] = append(permissionsByRole[roleID], perm)
+	}
+	permissionRows.Close()
+	for i, role := range roles {
+		if permissions, ok := permissionsByRole[role.ID]; ok {
+			roles[i].Permissions = permissions
+		}
+	}
+	return roles, nil
+}
+
+// RolesByUser retrieves the ids of all roles, that are directly assigned to the
+// user with the given id.
+func (m *Mall) RolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("user_roles")).
+		Select(goqu.C("role")).
+		Where(goqu.C("user").Eq(userID)).
+		Order(goqu.C("role").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	roles, err := m.queryUUIDs(ctx, tx, q)
+	if err != nil {
+		return nil, meh.Wrap(err, "query uuids", nil)
+	}
+	return roles, nil
+}
+
+// UpdateRolesByUser sets the roles, that are directly assigned to the user with
+// the given id.
+func (m *Mall) UpdateRolesByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, roles []uuid.UUID) error {
+	// Clear.
+	clearQuery, _, err := m.dialect.Delete(goqu.T("user_roles")).
+		Where(goqu.C("user").Eq(userID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "clear-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, clearQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec clear-query", clearQuery)
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	// Insert.
+	records := make([]any, 0, len(roles))
+	for _, role := range roles {
+		records = append(records, goqu.Record{
+			"user": userID,
+			"role": role,
+		})
+	}
+	insertQuery, _, err := m.dialect.Insert(goqu.T("user_roles")).Rows(records...).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "insert-query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, insertQuery)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec insert-query", insertQuery)
+	}
+	return nil
+}
+
+// RolePermissionsByUser retrieves the permissions of all roles, that are
+// assigned to the user with the given id, either directly or via group
+// membership. Permissions with the same name might be returned multiple times.
+func (m *Mall) RolePermissionsByUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]Permission, error) {
+	q, _, err := m.dialect.From(goqu.T("role_permissions")).
+		Select(goqu.C("name"),
+			goqu.C("options")).
+		Where(goqu.Or(
+			goqu.C("role").In(m.dialect.From(goqu.T("user_roles")).
+				Select(goqu.C("role")).
+				Where(goqu.C("user").Eq(userID))),
+			goqu.C("role").In(m.dialect.From(goqu.T("group_roles")).
+				Select(goqu.C("role")).
+				Where(goqu.C("group").In(m.dialect.From(goqu.T("group_members")).
+					Select(goqu.C("group")).
+					Where(goqu.C("user").Eq(userID))))),
+		)).
+		Order(goqu.C("name").Asc()).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	permissions := make([]Permission, 0)
+	for rows.Next() {
+		var perm Permission
+		err = rows.Scan(&perm.Name,
+			&perm.Options)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		permissions = append(permissions, perm)
+	}
+	return permissions, nil
+}
+
+// UsersByRole retrieves the ids of all users, the role with the given id is
+// assigned to, either directly or via group membership.
+func (m *Mall) UsersByRole(ctx context.Context, tx pgx.Tx, roleID uuid.UUID) ([]uuid.UUID, error) {
+	q, _, err := m.dialect.From(goqu.T("user_roles")).
+		Select(goqu.C("user")).
+		Where(goqu.C("role").Eq(roleID)).
+		Union(m.dialect.From(goqu.T("group_members")).
+			Select(goqu.C("user")).
+			Where(goqu.C("group").In(m.dialect.From(goqu.T("group_roles")).
+				Select(goqu.C("group")).
+				Where(goqu.C("role").Eq(roleID))))).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	users, err := m.queryUUIDs(ctx, tx, q)
+	if err != nil {
+		return nil, meh.Wrap(err, "query uuids", nil)
+	}
+	return users, nil
+}
+
+// queryUUIDs runs the given query and scans the first column of each row as
+// uuid.UUID.
+func (m *Mall) queryUUIDs(ctx context.Context, tx pgx.Tx, q string) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
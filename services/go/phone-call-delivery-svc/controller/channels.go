@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+)
+
+// UpdatePhoneCallChannelsByEntry deletes and recreates the phone-call-channels for the
+// address book entry with the given id.
+func (c *Controller) UpdatePhoneCallChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, create []store.PhoneCallChannel) error {
+	// Delete old channels.
+	err := c.store.DeletePhoneCallChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete phone-call-channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	// Create new channels.
+	for _, newChannel := range create {
+		err = c.store.CreatePhoneCallChannel(ctx, tx, newChannel)
+		if err != nil {
+			return meh.Wrap(err, "create phone-call-channel in store", meh.Details{"create": create})
+		}
+	}
+	return nil
+}
+
+// DeletePhoneCallChannelsByEntry deletes all phone-call-channels for the entry with the
+// given id.
+func (c *Controller) DeletePhoneCallChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	err := c.store.DeletePhoneCallChannelsByEntry(ctx, tx, entryID)
+	if err != nil {
+		return meh.Wrap(err, "delete phone call channels by entry in store", meh.Details{"entry_id": entryID})
+	}
+	return nil
+}
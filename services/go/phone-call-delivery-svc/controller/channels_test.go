@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerUpdatePhoneCallChannelsByEntrySuite tests
+// Controller.UpdatePhoneCallChannelsByEntry.
+type ControllerUpdatePhoneCallChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl           *ControllerMock
+	tx             *testutil.DBTx
+	sampleEntry    uuid.UUID
+	sampleChannels []store.PhoneCallChannel
+}
+
+func (suite *ControllerUpdatePhoneCallChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleEntry = testutil.NewUUIDV4()
+	genChannel := func() store.PhoneCallChannel {
+		return store.PhoneCallChannel{
+			ID:      testutil.NewUUIDV4(),
+			Entry:   suite.sampleEntry,
+			Label:   "death",
+			Timeout: 776,
+		}
+	}
+	suite.sampleChannels = []store.PhoneCallChannel{
+		genChannel(),
+		genChannel(),
+		genChannel(),
+	}
+}
+
+func (suite *ControllerUpdatePhoneCallChannelsByEntrySuite) TestDeleteInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeletePhoneCallChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePhoneCallChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdatePhoneCallChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeletePhoneCallChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	suite.ctrl.Store.On("CreatePhoneCallChannel", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePhoneCallChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdatePhoneCallChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("DeletePhoneCallChannelsByEntry", timeout, suite.tx, suite.sampleEntry).
+		Return(nil).Once()
+	for _, channel := range suite.sampleChannels {
+		suite.ctrl.Store.On("CreatePhoneCallChannel", timeout, suite.tx, channel).
+			Return(nil).Once()
+	}
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdatePhoneCallChannelsByEntry(timeout, suite.tx, suite.sampleEntry, suite.sampleChannels)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdatePhoneCallChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerUpdatePhoneCallChannelsByEntrySuite))
+}
+
+// ControllerDeletePhoneCallChannelsByEntrySuite tests
+// Controller.DeletePhoneCallChannelsByEntry.
+type ControllerDeletePhoneCallChannelsByEntrySuite struct {
+	suite.Suite
+	ctrl        *ControllerMock
+	sampleEntry uuid.UUID
+}
+
+func (suite *ControllerDeletePhoneCallChannelsByEntrySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.sampleEntry = testutil.NewUUIDV4()
+}
+
+func (suite *ControllerDeletePhoneCallChannelsByEntrySuite) TestCreateInStoreFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeletePhoneCallChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeletePhoneCallChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerDeletePhoneCallChannelsByEntrySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.ctrl.Store.On("DeletePhoneCallChannelsByEntry", timeout, tx, suite.sampleEntry).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.DeletePhoneCallChannelsByEntry(timeout, tx, suite.sampleEntry)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_DeletePhoneCallChannelsByEntry(t *testing.T) {
+	suite.Run(t, new(ControllerDeletePhoneCallChannelsByEntrySuite))
+}
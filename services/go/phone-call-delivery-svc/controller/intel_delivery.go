@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"time"
+)
+
+// CreateIntelDeliveryAttempt creates the given
+// store.AcceptedIntelDeliveryAttempt, phone-call-delivery and notifies. If the
+// referenced phone call channel cannot be found, no attempt will be created.
+func (c *Controller) CreateIntelDeliveryAttempt(ctx context.Context, tx pgx.Tx, attempt store.AcceptedIntelDeliveryAttempt) error {
+	channel, err := c.store.PhoneCallChannelByID(ctx, tx, attempt.Channel)
+	if err != nil {
+		if meh.ErrorCode(err) == meh.ErrNotFound {
+			// Ignore.
+			return nil
+		}
+		return meh.Wrap(err, "phone call channel by id from store", meh.Details{"channel_id": attempt.Channel})
+	}
+	attempt.AcceptedAt = time.Now()
+	attempt.Phone = channel.Phone
+	// Add to store.
+	err = c.store.CreateAcceptedIntelDeliveryAttempt(ctx, tx, attempt)
+	if err != nil {
+		return meh.Wrap(err, "create accepted intel delivery attempt", nil)
+	}
+	err = c.store.CreatePhoneCallDelivery(ctx, tx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "create phone call delivery", nil)
+	}
+	createdPhoneCallDelivery, err := c.store.PhoneCallDeliveryByAttempt(ctx, tx, attempt.ID)
+	if err != nil {
+		return meh.Wrap(err, "retrieve created phone call delivery from store", meh.Details{"attempt_id": attempt.ID})
+	}
+	// Notify about accepted attempt.
+	err = c.notifier.NotifyPhoneCallDeliveryReadyForPickup(ctx, tx, attempt, createdPhoneCallDelivery.Note)
+	if err != nil {
+		return meh.Wrap(err, "notify phone call delivery ready for pickup", meh.Details{"attempt_id": attempt.Delivery})
+	}
+	c.connUpdateNotifier.scheduleNotifyUpdatesForOperations(ctx, attempt.IntelOperation)
+	return nil
+}
+
+// UpdateIntelDeliveryAttemptStatus updates the status for the given
+// intel-delivery-attempt. If it is not active anymore but has an associated
+// active phone call delivery, it will be marked as failed.
+func (c *Controller) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pgx.Tx, newStatus store.AcceptedIntelDeliveryAttemptStatus) error {
+	// Update attempt.
+	err := c.store.UpdateAcceptedIntelDeliveryAttemptStatus(ctx, tx, newStatus)
+	if err != nil {
+		if meh.ErrorCode(err) == meh.ErrNotFound {
+			// Skip.
+			return nil
+		}
+		return meh.Wrap(err, "update accepted intel delivery attempt status in store", meh.Details{"new_status": newStatus})
+	}
+	// Only update phone call delivery, if attempt is not active anymore and discard
+	// other changes.
+	if newStatus.IsActive {
+		// No changes for phone call deliveries -> skip.
+		return nil
+	}
+	// Attempt not active anymore. Update phone call delivery if still active.
+	phoneCallDelivery, err := c.store.PhoneCallDeliveryByAttempt(ctx, tx, newStatus.ID)
+	if err != nil {
+		return meh.Wrap(err, "phone call delivery for attempt from store", meh.Details{"id": newStatus.ID})
+	}
+	if phoneCallDelivery.Success.Valid {
+		// Inactive -> skip.
+		return nil
+	}
+	err = c.store.UpdatePhoneCallDeliveryStatusByAttempt(ctx, tx, newStatus.ID, nulls.NewBool(false), "attempt not active anymore")
+	if err != nil {
+		return meh.Wrap(err, "update phone call delivery status by attempt", meh.Details{"attempt_id": newStatus.ID})
+	}
+	// Retrieve updated.
+	phoneCallDelivery, err = c.store.PhoneCallDeliveryByAttempt(ctx, tx, newStatus.ID)
+	if err != nil {
+		return meh.Wrap(err, "retrieve updated phone call delivery", meh.Details{"attempt_id": newStatus.ID})
+	}
+	// Notify.
+	err = c.notifier.NotifyPhoneCallDeliveryFinished(ctx, tx, phoneCallDelivery)
+	if err != nil {
+		return meh.Wrap(err, "notify about finished phone call delivery", meh.Details{"phone_call_delivery": phoneCallDelivery})
+	}
+	return nil
+}
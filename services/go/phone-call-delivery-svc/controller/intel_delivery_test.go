@@ -0,0 +1,418 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// ControllerCreateIntelDeliveryAttemptSuite tests
+// Controller.CreateIntelDeliveryAttempt.
+type ControllerCreateIntelDeliveryAttemptSuite struct {
+	suite.Suite
+	ctrl                           *ControllerMock
+	tx                             *testutil.DBTx
+	sampleAttempt                  store.AcceptedIntelDeliveryAttempt
+	samplePhoneCallChannel         store.PhoneCallChannel
+	sampleCreatedPhoneCallDelivery store.PhoneCallDelivery
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 950,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "not",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("these"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.samplePhoneCallChannel = store.PhoneCallChannel{
+		ID:      suite.sampleAttempt.Channel,
+		Entry:   testutil.NewUUIDV4(),
+		Label:   "thicken",
+		Timeout: 530,
+		Phone:   "+491234567890",
+	}
+	suite.sampleCreatedPhoneCallDelivery = store.PhoneCallDelivery{
+		Attempt:    suite.sampleAttempt.ID,
+		PickedUpBy: uuid.NullUUID{},
+		PickedUpAt: nulls.Time{},
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "sun",
+	}
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestPhoneCallChannelByIDFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.PhoneCallChannel{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestPhoneCallChannelNotFound() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(store.PhoneCallChannel{}, meh.NewNotFoundErr("not found", nil))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreateAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.samplePhoneCallChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestCreatePhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.samplePhoneCallChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreatePhoneCallDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestRetrieveCreatedPhoneCallDelivery() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.samplePhoneCallChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreatePhoneCallDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.PhoneCallDelivery{}, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.samplePhoneCallChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx, mock.Anything).
+		Return(nil)
+	suite.ctrl.Store.On("CreatePhoneCallDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleCreatedPhoneCallDelivery, nil)
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReadyForPickup", timeout, suite.tx, mock.Anything,
+		suite.sampleCreatedPhoneCallDelivery.Note).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerCreateIntelDeliveryAttemptSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallChannelByID", timeout, suite.tx, suite.sampleAttempt.Channel).
+		Return(suite.samplePhoneCallChannel, nil)
+	suite.ctrl.Store.On("CreateAcceptedIntelDeliveryAttempt", timeout, suite.tx,
+		mock.MatchedBy(func(create store.AcceptedIntelDeliveryAttempt) bool {
+			return create.Phone == suite.samplePhoneCallChannel.Phone
+		})).
+		Return(nil)
+	suite.ctrl.Store.On("CreatePhoneCallDelivery", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(nil)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleCreatedPhoneCallDelivery, nil)
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReadyForPickup", timeout, suite.tx, mock.Anything,
+		suite.sampleCreatedPhoneCallDelivery.Note).
+		Return(nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.CreateIntelDeliveryAttempt(timeout, suite.tx, suite.sampleAttempt)
+		suite.NoError(err, "should not fail")
+		// Assure notify request scheduled.
+		select {
+		case <-timeout.Done():
+			suite.Fail("no notify request for operation")
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Equal(op, suite.sampleAttempt.IntelOperation, "should have scheduled notify request for operation")
+		}
+	}()
+
+	wait()
+}
+
+func TestController_CreateIntelDeliveryAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerCreateIntelDeliveryAttemptSuite))
+}
+
+// ControllerUpdateIntelDeliveryAttemptStatusSuite tests
+// Controller.UpdateIntelDeliveryAttemptStatus.
+type ControllerUpdateIntelDeliveryAttemptStatusSuite struct {
+	suite.Suite
+	ctrl                           *ControllerMock
+	tx                             *testutil.DBTx
+	sampleNewStatus                store.AcceptedIntelDeliveryAttemptStatus
+	samplePhoneCallDelivery        store.PhoneCallDelivery
+	sampleUpdatedPhoneCallDelivery store.PhoneCallDelivery
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleNewStatus = store.AcceptedIntelDeliveryAttemptStatus{
+		ID:       testutil.NewUUIDV4(),
+		IsActive: false,
+		StatusTS: testutil.NewRandomTime(),
+		Note:     nulls.NewString("lovely"),
+	}
+	suite.samplePhoneCallDelivery = store.PhoneCallDelivery{
+		Attempt:    suite.sampleNewStatus.ID,
+		PickedUpBy: uuid.NullUUID{},
+		PickedUpAt: nulls.Time{},
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "sun",
+	}
+	suite.sampleUpdatedPhoneCallDelivery = suite.samplePhoneCallDelivery
+	suite.sampleUpdatedPhoneCallDelivery.Success = nulls.NewBool(false)
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestUpdateFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestAttemptNotFound() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(meh.NewNotFoundErr("not found", nil)).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestNewActive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.sampleNewStatus.IsActive = true
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestRetrievePhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(store.PhoneCallDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestPhoneCallDeliveryAlreadyFinished() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.samplePhoneCallDelivery.Success = nulls.NewBool(false)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestUpdatePhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdatePhoneCallDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestRetrieveUpdatedPhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdatePhoneCallDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(store.PhoneCallDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdatePhoneCallDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleUpdatedPhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryFinished", timeout, suite.tx, suite.sampleUpdatedPhoneCallDelivery).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("UpdateAcceptedIntelDeliveryAttemptStatus", timeout, suite.tx, suite.sampleNewStatus).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("UpdatePhoneCallDeliveryStatusByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID,
+		nulls.NewBool(false), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleNewStatus.ID).
+		Return(suite.sampleUpdatedPhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryFinished", timeout, suite.tx, suite.sampleUpdatedPhoneCallDelivery).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.UpdateIntelDeliveryAttemptStatus(timeout, suite.tx, suite.sampleNewStatus)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestController_UpdateIntelDeliveryAttemptStatus(t *testing.T) {
+	suite.Run(t, new(ControllerUpdateIntelDeliveryAttemptStatusSuite))
+}
@@ -0,0 +1,1133 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// ControllerPickUpNextPhoneCallDeliverySuite tests
+// Controller.PickUpNextPhoneCallDelivery.
+type ControllerPickUpNextPhoneCallDeliverySuite struct {
+	suite.Suite
+	ctrl                       *ControllerMock
+	tx                         *testutil.DBTx
+	sampleOperationID          uuid.UUID
+	sampleBy                   uuid.UUID
+	sampleOperationsOfBy       []uuid.UUID
+	sampleActiveDeliveries     []store.ActivePhoneCallDelivery
+	sampleIntelDeliveryAttempt store.AcceptedIntelDeliveryAttempt
+	samplePhoneCallDelivery    store.PhoneCallDelivery
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) genActiveDelivery(importance int, pickedUp bool) store.ActivePhoneCallDelivery {
+	var pickedUpAt nulls.Time
+	if pickedUp {
+		pickedUpAt = nulls.NewTime(testutil.NewRandomTime())
+	}
+	return store.ActivePhoneCallDelivery{
+		Attempt:          testutil.NewUUIDV4(),
+		PickedUpAt:       pickedUpAt,
+		IntelOperation:   testutil.NewUUIDV4(),
+		IntelImportance:  importance,
+		AttemptCreatedAt: testutil.NewRandomTime(),
+	}
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleOperationID = testutil.NewUUIDV4()
+	suite.sampleBy = testutil.NewUUIDV4()
+	suite.sampleOperationsOfBy = []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		suite.sampleOperationID,
+		testutil.NewUUIDV4(),
+	}
+	suite.sampleActiveDeliveries = []store.ActivePhoneCallDelivery{
+		suite.genActiveDelivery(267, true),
+		suite.genActiveDelivery(755, false),
+		suite.genActiveDelivery(445, false),
+		suite.genActiveDelivery(516, true),
+		suite.genActiveDelivery(445, false),
+		suite.genActiveDelivery(516, true),
+		suite.genActiveDelivery(516, true),
+	}
+	suite.sampleIntelDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              suite.sampleActiveDeliveries[0].Attempt,
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  suite.sampleOperationID,
+		IntelImportance: suite.sampleActiveDeliveries[0].IntelImportance,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "defend",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("young"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.samplePhoneCallDelivery = store.PhoneCallDelivery{
+		Attempt:    suite.sampleIntelDeliveryAttempt.ID,
+		PickedUpBy: nulls.NewUUID(suite.sampleBy),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "ease",
+	}
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestRetrieveOperationsForUserFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestNoMemberOfOperation1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return([]uuid.UUID{
+			testutil.NewUUIDV4(),
+			testutil.NewUUIDV4(),
+			testutil.NewUUIDV4(),
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return error with correct code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestNoMemberOfOperation2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return([]uuid.UUID{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return error with correct code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestRetrieveActivePhoneCallDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestNoOpenPhoneCallDeliveries1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return([]store.ActivePhoneCallDelivery{
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+			{PickedUpAt: nulls.NewTime(testutil.NewRandomTime())},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.False(ok, "should return false")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestNoOpenPhoneCallDeliveries2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return([]store.ActivePhoneCallDelivery{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.False(ok, "should return false")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestMarkPhoneCallDeliveryAsPickedUpFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestRetrieveIntelDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestRetrievePhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(store.PhoneCallDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, mock.Anything, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, _, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(suite.sampleActiveDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, mock.Anything).
+		Return(suite.sampleIntelDeliveryAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, mock.Anything).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, mock.Anything, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestPickSortByOldestFirst1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activePhoneCallDeliveries := []store.ActivePhoneCallDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(200, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(400, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+	}
+	shouldPick := activePhoneCallDeliveries[0].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activePhoneCallDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestPickSortByOldestFirst2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activePhoneCallDeliveries := []store.ActivePhoneCallDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(700, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(600, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(800, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activePhoneCallDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activePhoneCallDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestPickSortByImportanceFirst1() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activePhoneCallDeliveries := []store.ActivePhoneCallDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(200, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(400, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  900,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activePhoneCallDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activePhoneCallDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestPickSortByImportanceFirst2() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activePhoneCallDeliveries := []store.ActivePhoneCallDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(100, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(300, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.NewTime(testutil.NewRandomTime()),
+			AttemptCreatedAt: time.Date(800, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+	}
+	shouldPick := activePhoneCallDeliveries[2].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activePhoneCallDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerPickUpNextPhoneCallDeliverySuite) TestPickSortByImportanceFirst3() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	activePhoneCallDeliveries := []store.ActivePhoneCallDelivery{
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(100, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  200,
+		},
+		{
+			Attempt:          testutil.NewUUIDV4(),
+			PickedUpAt:       nulls.Time{},
+			AttemptCreatedAt: time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC),
+			IntelImportance:  300,
+		},
+	}
+	shouldPick := activePhoneCallDeliveries[1].Attempt
+	pickedAttempt := suite.sampleIntelDeliveryAttempt
+	pickedAttempt.ID = shouldPick
+	suite.ctrl.Store.On("OperationsByMember", timeout, suite.tx, suite.sampleBy).
+		Return(suite.sampleOperationsOfBy, nil).Once()
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, nulls.NewUUID(suite.sampleOperationID)).
+		Return(activePhoneCallDeliveries, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, mock.Anything,
+		nulls.NewUUID(suite.sampleBy), mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, shouldPick).
+		Return(pickedAttempt, nil).Once()
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, shouldPick).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryPickedUp", timeout, suite.tx, shouldPick, suite.sampleBy,
+		suite.samplePhoneCallDelivery.PickedUpAt.Time).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		attempt, ok, err := suite.ctrl.Ctrl.PickUpNextPhoneCallDelivery(timeout, suite.sampleOperationID, suite.sampleBy)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(ok, "should return true")
+		suite.Equal(shouldPick, attempt.ID, "should pick correct attempt")
+	}()
+
+	wait()
+}
+
+func TestController_PickUpNextPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerPickUpNextPhoneCallDeliverySuite))
+}
+
+// ControllerReleasePickedUpPhoneCallDeliverySuite tests
+// Controller.ReleasePickedUpPhoneCallDelivery.
+type ControllerReleasePickedUpPhoneCallDeliverySuite struct {
+	suite.Suite
+	ctrl                    *ControllerMock
+	tx                      *testutil.DBTx
+	sampleAttemptID         uuid.UUID
+	samplePhoneCallDelivery store.PhoneCallDelivery
+	sampleAttempt           store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePhoneCallDelivery = store.PhoneCallDelivery{
+		Attempt:    suite.sampleAttemptID,
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.Bool{},
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "upper",
+	}
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             suite.sampleAttemptID,
+		IntelOperation: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestRetrievePhoneCallDeliveryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.PhoneCallDelivery{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestRetrieveIntelDeliveryAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestDeliveryNotActiveAnymore() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.samplePhoneCallDelivery.Success = nulls.NewBool(false)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestDeliveryNotPickedUp() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.samplePhoneCallDelivery.PickedUpBy = uuid.NullUUID{}
+	suite.samplePhoneCallDelivery.PickedUpAt = nulls.Time{}
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrBadInput, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestLimitToPickedUpByNotOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.samplePhoneCallDelivery.PickedUpBy = nulls.NewUUID(testutil.NewUUIDV4())
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, nulls.NewUUID(testutil.NewUUIDV4()))
+		suite.Require().Error(err, "should fail")
+		suite.Equal(meh.ErrForbidden, meh.ErrorCode(err), "should return correct error code")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestReleaseFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReleased", timeout, suite.tx, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerReleasePickedUpPhoneCallDeliverySuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("PhoneCallDeliveryByAttempt", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.samplePhoneCallDelivery, nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttemptID).
+		Return(suite.sampleAttempt, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleAttemptID,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReleased", timeout, suite.tx, suite.sampleAttemptID, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ReleasePickedUpPhoneCallDelivery(timeout, suite.sampleAttemptID, uuid.NullUUID{})
+		suite.Require().NoError(err, "should not fail")
+		// Assure notification-update scheduled.
+		select {
+		case <-timeout.Done():
+			suite.Fail("should schedule notification-update")
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Equal(suite.sampleAttempt.IntelOperation, op, "should schedule notification-update for correct operation")
+		}
+	}()
+
+	wait()
+}
+
+func TestController_ReleasePickedUpPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, new(ControllerReleasePickedUpPhoneCallDeliverySuite))
+}
+
+// controllerReleaseTimedOutPhoneCallDeliveriesSuite tests
+// Controller.releaseTimedOutPhoneCallDeliveries.
+type controllerReleaseTimedOutPhoneCallDeliveriesSuite struct {
+	suite.Suite
+	ctrl                               *ControllerMock
+	tx                                 *testutil.DBTx
+	sampleActiveDelivery               store.ActivePhoneCallDelivery
+	sampleUpdatedActiveDeliveryAttempt store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleActiveDelivery = store.ActivePhoneCallDelivery{
+		Attempt:          testutil.NewUUIDV4(),
+		PickedUpAt:       nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout - 10*time.Second)),
+		IntelOperation:   testutil.NewUUIDV4(),
+		IntelImportance:  469,
+		AttemptCreatedAt: testutil.NewRandomTime(),
+	}
+	suite.sampleUpdatedActiveDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             suite.sampleActiveDelivery.Attempt,
+		IntelOperation: suite.sampleActiveDelivery.IntelOperation,
+	}
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) expectNotifyUpdatesForOperations(ctx context.Context, forOperations ...uuid.UUID) {
+	// Special case: none expected.
+	if len(forOperations) == 0 {
+		select {
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Failf("unexpected", "no notify-updates expected but got for %v", op)
+		default:
+			return
+		}
+	}
+	// Regular behavior.
+	operations := make(map[uuid.UUID]int, 0)
+	for _, operation := range forOperations {
+		operations[operation] = operations[operation] + 1
+	}
+	for len(operations) > 0 {
+		select {
+		case <-ctx.Done():
+			suite.Failf("timeout", "expected still updates for %d operations", len(operations))
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			remaining := operations[op]
+			if remaining <= 0 {
+				suite.Failf("unexpected", "unexpected notify-update for operation %v", op)
+			} else {
+				remaining--
+				if remaining <= 0 {
+					delete(operations, op)
+				}
+			}
+		}
+	}
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestRetrieveActiveDeliveriesFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return(nil, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestNoActiveDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestNoPickedUpDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{
+			{},
+			{},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestNoTimedOutDeliveries() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{
+			{PickedUpAt: nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout / 2))},
+			{PickedUpAt: nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout / 2))},
+		}, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestReleaseFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestRetrieveUpdatedAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(suite.sampleUpdatedActiveDeliveryAttempt, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReadyForPickup", timeout, suite.tx,
+		suite.sampleUpdatedActiveDeliveryAttempt, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Error(err, "should fail")
+		suite.expectNotifyUpdatesForOperations(timeout)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestOKSingle() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return([]store.ActivePhoneCallDelivery{suite.sampleActiveDelivery}, nil).Once()
+	suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, suite.sampleActiveDelivery.Attempt,
+		uuid.NullUUID{}, mock.Anything).
+		Return(nil).Once()
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleActiveDelivery.Attempt).
+		Return(suite.sampleUpdatedActiveDeliveryAttempt, nil).Once()
+	suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReadyForPickup", timeout, suite.tx,
+		suite.sampleUpdatedActiveDeliveryAttempt, mock.Anything).
+		Return(nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout, suite.sampleActiveDelivery.IntelOperation)
+	}()
+
+	wait()
+}
+
+func (suite *controllerReleaseTimedOutPhoneCallDeliveriesSuite) TestOKMulti() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	// Gen data.
+	timedOutCount := 16
+	okCount := 64
+	activeDeliveries := make([]store.ActivePhoneCallDelivery, 0, timedOutCount+okCount)
+	expectNotifyForOperations := []uuid.UUID{
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+		testutil.NewUUIDV4(),
+	}
+	for timedOutCount > 0 || okCount > 0 {
+		genTimeout := timedOutCount > 0
+		if genTimeout && okCount > 0 {
+			genTimeout = rand.Intn(10) > 7
+		}
+		if genTimeout {
+			timedOutCount--
+			d := store.ActivePhoneCallDelivery{
+				Attempt:          testutil.NewUUIDV4(),
+				PickedUpAt:       nulls.NewTime(time.Now().Add(-suite.ctrl.samplePickedUpTimeout - 10*time.Second)),
+				IntelOperation:   expectNotifyForOperations[timedOutCount%len(expectNotifyForOperations)],
+				IntelImportance:  574,
+				AttemptCreatedAt: testutil.NewRandomTime(),
+			}
+			activeDeliveries = append(activeDeliveries, d)
+			suite.ctrl.Store.On("MarkPhoneCallDeliveryAsPickedUpByAttempt", timeout, suite.tx, d.Attempt, uuid.NullUUID{}, mock.Anything).
+				Return(nil).Once()
+			updatedAttempt := store.AcceptedIntelDeliveryAttempt{
+				ID:             d.Attempt,
+				IntelOperation: d.IntelOperation,
+			}
+			suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, d.Attempt).
+				Return(updatedAttempt, nil).Once()
+			suite.ctrl.Notifier.On("NotifyPhoneCallDeliveryReadyForPickup", timeout, suite.tx, updatedAttempt, mock.Anything).
+				Return(nil).Once()
+		} else {
+			okCount--
+			activeDeliveries = append(activeDeliveries, store.ActivePhoneCallDelivery{
+				Attempt:          testutil.NewUUIDV4(),
+				PickedUpAt:       nulls.NewTime(time.Now().Add(10 * time.Second)),
+				IntelOperation:   testutil.NewUUIDV4(),
+				IntelImportance:  153,
+				AttemptCreatedAt: testutil.NewRandomTime(),
+			})
+		}
+	}
+	suite.ctrl.Store.On("ActivePhoneCallDeliveriesAndLockOrWait", timeout, suite.tx, uuid.NullUUID{}).
+		Return(activeDeliveries, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.releaseTimedOutPhoneCallDeliveries(timeout)
+		suite.Require().NoError(err, "should not fail")
+		suite.expectNotifyUpdatesForOperations(timeout, expectNotifyForOperations...)
+	}()
+
+	wait()
+}
+
+func TestController_releaseTimedOutPhoneCallDeliveries(t *testing.T) {
+	suite.Run(t, new(controllerReleaseTimedOutPhoneCallDeliveriesSuite))
+}
@@ -0,0 +1,498 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// handleGetNextPhoneCallDeliverySuite tests handleGetNextPhoneCallDelivery.
+type handleGetNextPhoneCallDeliverySuite struct {
+	suite.Suite
+	s                        *StoreMock
+	r                        *gin.Engine
+	sampleToken              auth.Token
+	sampleStoreNextDelivery  store.AcceptedIntelDeliveryAttempt
+	samplePublicNextDelivery publicAcceptedIntelDeliveryAttempt
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName}},
+	}
+	suite.sampleStoreNextDelivery = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 884,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "baggage",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("front"),
+		AcceptedAt:      testutil.NewRandomTime(),
+		Phone:           "+4915112345678",
+	}
+	suite.samplePublicNextDelivery = publicAcceptedIntelDeliveryAttemptFromStore(suite.sampleStoreNextDelivery)
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestInvalidOperationID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/operations/abc/next",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestPickUpNextFail() {
+	suite.s.On("PickUpNextPhoneCallDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, false, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestNoNext() {
+	suite.s.On("PickUpNextPhoneCallDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, false, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusNoContent, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextPhoneCallDeliverySuite) TestOK() {
+	suite.s.On("PickUpNextPhoneCallDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(suite.sampleStoreNextDelivery, true, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicNextDelivery)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got publicAcceptedIntelDeliveryAttempt
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(suite.samplePublicNextDelivery, got, "should return correct body")
+}
+
+func Test_handleGetNextPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, new(handleGetNextPhoneCallDeliverySuite))
+}
+
+// handleReleasePickedUpPhoneCallDeliverySuite tests
+// handleReleasePickedUpPhoneCallDelivery.
+type handleReleasePickedUpPhoneCallDeliverySuite struct {
+	suite.Suite
+	s               *StoreMock
+	r               *gin.Engine
+	sampleToken     auth.Token
+	sampleAttemptID uuid.UUID
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName}},
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/release",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestReleaseFail() {
+	suite.s.On("ReleasePickedUpPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestOK() {
+	suite.s.On("ReleasePickedUpPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, mock.Anything).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestOnlyDeliverPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName}}
+	suite.s.On("ReleasePickedUpPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestOnlyManagePermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.ManageAnyPhoneCallDeliveryPermissionName}}
+	suite.s.On("ReleasePickedUpPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpPhoneCallDeliverySuite) TestDeliverAndManagePermissions() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName},
+		{Name: permission.ManageAnyPhoneCallDeliveryPermissionName},
+	}
+	suite.s.On("ReleasePickedUpPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleReleasePickedUpPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, new(handleReleasePickedUpPhoneCallDeliverySuite))
+}
+
+// handleFinishPhoneCallDeliverySuite tests
+// handleFinishPhoneCallDelivery.
+type handleFinishPhoneCallDeliverySuite struct {
+	suite.Suite
+	s                         *StoreMock
+	r                         *gin.Engine
+	sampleToken               auth.Token
+	sampleAttemptID           uuid.UUID
+	samplePublicFinishDetails publicFinishPhoneCallDeliveryDetails
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) SetupTest() {
+	suite.s = &StoreMock{}
+	suite.r = testutil.NewGinEngine()
+	populateRoutes(suite.r, zap.NewNop(), "", suite.s, &wsHubStub{})
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "organize",
+		IsAuthenticated: true,
+		IsAdmin:         false,
+		Permissions:     []permission.Permission{{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName}},
+	}
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePublicFinishDetails = publicFinishPhoneCallDeliveryDetails{
+		Success: true,
+		Note:    "seat",
+	}
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestSecretMismatch() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+		Secret: "meow",
+	})
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusUnauthorized, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestInvalidAttemptID() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    "/abc/finish",
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestInvalidBody() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   strings.NewReader(`{invalid`),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestMissingPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestFinishFail() {
+	suite.s.On("FinishPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, mock.Anything).
+		Return(errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestOK() {
+	suite.s.On("FinishPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, mock.Anything).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestOnlyDeliverPermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName}}
+	suite.s.On("FinishPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestOnlyManagePermission() {
+	suite.sampleToken.Permissions = []permission.Permission{{Name: permission.ManageAnyPhoneCallDeliveryPermissionName}}
+	suite.s.On("FinishPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleFinishPhoneCallDeliverySuite) TestDeliverAndManagePermissions() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		{Name: permission.DeliverAnyPhoneCallDeliveryPermissionName},
+		{Name: permission.ManageAnyPhoneCallDeliveryPermissionName},
+	}
+	suite.s.On("FinishPhoneCallDelivery", mock.Anything, suite.sampleAttemptID, suite.samplePublicFinishDetails.Success,
+		suite.samplePublicFinishDetails.Note, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/finish", suite.sampleAttemptID),
+		Body:   bytes.NewReader(testutil.MarshalJSONMust(suite.samplePublicFinishDetails)),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_handleFinishPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, new(handleFinishPhoneCallDeliverySuite))
+}
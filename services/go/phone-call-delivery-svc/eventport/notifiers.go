@@ -0,0 +1,96 @@
+package eventport
+
+import (
+	"context"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"time"
+)
+
+// NotifyPhoneCallDeliveryReadyForPickup emeits an
+// event.TypePhoneCallDeliveryReadyForPickup event.
+func (p *Port) NotifyPhoneCallDeliveryReadyForPickup(ctx context.Context, tx pgx.Tx, intelDeliveryAttempt store.AcceptedIntelDeliveryAttempt,
+	phoneCallDeliveryNote string) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       intelDeliveryAttempt.ID.String(),
+		EventType: event.TypePhoneCallDeliveryReadyForPickup,
+		Value: event.PhoneCallDeliveryReadyForPickup{
+			Attempt:                intelDeliveryAttempt.ID,
+			Intel:                  intelDeliveryAttempt.Intel,
+			IntelOperation:         intelDeliveryAttempt.IntelOperation,
+			IntelImportance:        intelDeliveryAttempt.IntelImportance,
+			AttemptAssignedTo:      intelDeliveryAttempt.AssignedTo,
+			AttemptAssignedToLabel: intelDeliveryAttempt.AssignedToLabel,
+			Delivery:               intelDeliveryAttempt.Delivery,
+			Channel:                intelDeliveryAttempt.Channel,
+			Note:                   phoneCallDeliveryNote,
+			AttemptAcceptedAt:      intelDeliveryAttempt.AcceptedAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyPhoneCallDeliveryPickedUp emits an event.NotifyPhoneCallDeliveryPickedUp event.
+func (p *Port) NotifyPhoneCallDeliveryPickedUp(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, pickedUpBy uuid.UUID,
+	pickedUpAt time.Time) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypePhoneCallDeliveryPickedUp,
+		Value: event.PhoneCallDeliveryPickedUp{
+			Attempt:    attemptID,
+			PickedUpBy: pickedUpBy,
+			PickedUpAt: pickedUpAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyPhoneCallDeliveryReleased emits an event.NotifyPhoneCallDeliveryReleased event.
+func (p *Port) NotifyPhoneCallDeliveryReleased(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID, releasedAt time.Time) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       attemptID.String(),
+		EventType: event.TypePhoneCallDeliveryReleased,
+		Value: event.PhoneCallDeliveryReleased{
+			Attempt:    attemptID,
+			ReleasedAt: releasedAt,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
+
+// NotifyPhoneCallDeliveryFinished emits an event.NotifyPhoneCallDeliveryFinished event.
+func (p *Port) NotifyPhoneCallDeliveryFinished(ctx context.Context, tx pgx.Tx, phoneCallDelivery store.PhoneCallDelivery) error {
+	err := p.writer.AddOutboxMessages(ctx, tx, kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       phoneCallDelivery.Attempt.String(),
+		EventType: event.TypePhoneCallDeliveryFinished,
+		Value: event.PhoneCallDeliveryFinished{
+			Attempt:    phoneCallDelivery.Attempt,
+			PickedUpBy: phoneCallDelivery.PickedUpBy,
+			PickedUpAt: phoneCallDelivery.PickedUpAt,
+			Success:    phoneCallDelivery.Success.Bool,
+			FinishedAt: phoneCallDelivery.SuccessTS,
+			Note:       phoneCallDelivery.Note,
+		},
+	})
+	if err != nil {
+		return meh.Wrap(err, "write kafka messages", nil)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package eventport
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/phone-call-delivery-svc/store"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// PortNotifyPhoneCallDeliveryReadyForPickupSuite tests
+// Port.NotifyPhoneCallDeliveryReadyForPickup.
+type PortNotifyPhoneCallDeliveryReadyForPickupSuite struct {
+	suite.Suite
+	port                        *PortMock
+	sampleIntelDeliveryAttempt  store.AcceptedIntelDeliveryAttempt
+	samplePhoneCallDeliveryNote string
+	expectedMessage             kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReadyForPickupSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleIntelDeliveryAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:              testutil.NewUUIDV4(),
+		Intel:           testutil.NewUUIDV4(),
+		IntelOperation:  testutil.NewUUIDV4(),
+		IntelImportance: 403,
+		AssignedTo:      testutil.NewUUIDV4(),
+		AssignedToLabel: "explore",
+		AssignedToUser:  nulls.NewUUID(testutil.NewUUIDV4()),
+		Delivery:        testutil.NewUUIDV4(),
+		Channel:         testutil.NewUUIDV4(),
+		CreatedAt:       testutil.NewRandomTime(),
+		IsActive:        true,
+		StatusTS:        testutil.NewRandomTime(),
+		Note:            nulls.NewString("gaiety"),
+		AcceptedAt:      testutil.NewRandomTime(),
+	}
+	suite.samplePhoneCallDeliveryNote = "overflow"
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       suite.sampleIntelDeliveryAttempt.ID.String(),
+		EventType: event.TypePhoneCallDeliveryReadyForPickup,
+		Value: event.PhoneCallDeliveryReadyForPickup{
+			Attempt:                suite.sampleIntelDeliveryAttempt.ID,
+			Intel:                  suite.sampleIntelDeliveryAttempt.Intel,
+			IntelOperation:         suite.sampleIntelDeliveryAttempt.IntelOperation,
+			IntelImportance:        suite.sampleIntelDeliveryAttempt.IntelImportance,
+			AttemptAssignedTo:      suite.sampleIntelDeliveryAttempt.AssignedTo,
+			AttemptAssignedToLabel: suite.sampleIntelDeliveryAttempt.AssignedToLabel,
+			Delivery:               suite.sampleIntelDeliveryAttempt.Delivery,
+			Channel:                suite.sampleIntelDeliveryAttempt.Channel,
+			Note:                   suite.samplePhoneCallDeliveryNote,
+			AttemptAcceptedAt:      suite.sampleIntelDeliveryAttempt.AcceptedAt,
+		},
+	}
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReadyForPickupSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryReadyForPickup(timeout, &testutil.DBTx{},
+			suite.sampleIntelDeliveryAttempt, suite.samplePhoneCallDeliveryNote)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReadyForPickupSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryReadyForPickup(timeout, &testutil.DBTx{},
+			suite.sampleIntelDeliveryAttempt, suite.samplePhoneCallDeliveryNote)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyPhoneCallDeliveryReadyForPickup(t *testing.T) {
+	suite.Run(t, new(PortNotifyPhoneCallDeliveryReadyForPickupSuite))
+}
+
+// PortNotifyPhoneCallDeliveryPickedUpSuite tests Port.NotifyPhoneCallDeliveryPickedUp.
+type PortNotifyPhoneCallDeliveryPickedUpSuite struct {
+	suite.Suite
+	port             *PortMock
+	sampleAttemptID  uuid.UUID
+	samplePickedUpBy uuid.UUID
+	samplePickedUpAt time.Time
+	expectedMessage  kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyPhoneCallDeliveryPickedUpSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.samplePickedUpBy = testutil.NewUUIDV4()
+	suite.samplePickedUpAt = testutil.NewRandomTime()
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       suite.sampleAttemptID.String(),
+		EventType: event.TypePhoneCallDeliveryPickedUp,
+		Value: event.PhoneCallDeliveryPickedUp{
+			Attempt:    suite.sampleAttemptID,
+			PickedUpBy: suite.samplePickedUpBy,
+			PickedUpAt: suite.samplePickedUpAt,
+		},
+	}
+}
+
+func (suite *PortNotifyPhoneCallDeliveryPickedUpSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryPickedUp(timeout, &testutil.DBTx{},
+			suite.sampleAttemptID, suite.samplePickedUpBy, suite.samplePickedUpAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyPhoneCallDeliveryPickedUpSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryPickedUp(timeout, &testutil.DBTx{},
+			suite.sampleAttemptID, suite.samplePickedUpBy, suite.samplePickedUpAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyPhoneCallDeliveryPickedUp(t *testing.T) {
+	suite.Run(t, new(PortNotifyPhoneCallDeliveryPickedUpSuite))
+}
+
+// PortNotifyPhoneCallDeliveryReleasedSuite tests Port.NotifyPhoneCallDeliveryReleased.
+type PortNotifyPhoneCallDeliveryReleasedSuite struct {
+	suite.Suite
+	port             *PortMock
+	sampleAttemptID  uuid.UUID
+	sampleReleasedAt time.Time
+	expectedMessage  kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReleasedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.sampleAttemptID = testutil.NewUUIDV4()
+	suite.sampleReleasedAt = testutil.NewRandomTime()
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       suite.sampleAttemptID.String(),
+		EventType: event.TypePhoneCallDeliveryReleased,
+		Value: event.PhoneCallDeliveryReleased{
+			Attempt:    suite.sampleAttemptID,
+			ReleasedAt: suite.sampleReleasedAt,
+		},
+	}
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReleasedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryReleased(timeout, &testutil.DBTx{}, suite.sampleAttemptID, suite.sampleReleasedAt)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyPhoneCallDeliveryReleasedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryReleased(timeout, &testutil.DBTx{}, suite.sampleAttemptID, suite.sampleReleasedAt)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyPhoneCallDeliveryReleased(t *testing.T) {
+	suite.Run(t, new(PortNotifyPhoneCallDeliveryReleasedSuite))
+}
+
+// PortNotifyPhoneCallDeliveryFinishedSuite tests Port.NotifyPhoneCallDeliveryFinished.
+type PortNotifyPhoneCallDeliveryFinishedSuite struct {
+	suite.Suite
+	port                    *PortMock
+	samplePhoneCallDelivery store.PhoneCallDelivery
+	expectedMessage         kafkautil.OutboundMessage
+}
+
+func (suite *PortNotifyPhoneCallDeliveryFinishedSuite) SetupTest() {
+	suite.port = newMockPort()
+	suite.samplePhoneCallDelivery = store.PhoneCallDelivery{
+		Attempt:    testutil.NewUUIDV4(),
+		PickedUpBy: nulls.NewUUID(testutil.NewUUIDV4()),
+		PickedUpAt: nulls.NewTime(testutil.NewRandomTime()),
+		Success:    nulls.NewBool(true),
+		SuccessTS:  testutil.NewRandomTime(),
+		Note:       "cape",
+	}
+	suite.expectedMessage = kafkautil.OutboundMessage{
+		Topic:     event.PhoneCallDeliveriesTopic,
+		Key:       suite.samplePhoneCallDelivery.Attempt.String(),
+		EventType: event.TypePhoneCallDeliveryFinished,
+		Value: event.PhoneCallDeliveryFinished{
+			Attempt:    suite.samplePhoneCallDelivery.Attempt,
+			PickedUpBy: suite.samplePhoneCallDelivery.PickedUpBy,
+			PickedUpAt: suite.samplePhoneCallDelivery.PickedUpAt,
+			Success:    suite.samplePhoneCallDelivery.Success.Bool,
+			FinishedAt: suite.samplePhoneCallDelivery.SuccessTS,
+			Note:       suite.samplePhoneCallDelivery.Note,
+		},
+	}
+}
+
+func (suite *PortNotifyPhoneCallDeliveryFinishedSuite) TestWriteFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.port.recorder.WriteFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryFinished(timeout, &testutil.DBTx{}, suite.samplePhoneCallDelivery)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *PortNotifyPhoneCallDeliveryFinishedSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.port.Port.NotifyPhoneCallDeliveryFinished(timeout, &testutil.DBTx{}, suite.samplePhoneCallDelivery)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal([]kafkautil.OutboundMessage{suite.expectedMessage}, suite.port.recorder.Recorded, "should write correct message")
+	}()
+
+	wait()
+}
+
+func TestPort_NotifyPhoneCallDeliveryFinished(t *testing.T) {
+	suite.Run(t, new(PortNotifyPhoneCallDeliveryFinishedSuite))
+}
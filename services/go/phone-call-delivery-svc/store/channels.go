@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"time"
+)
+
+// PhoneCallChannel that is used for delivery-attempts.
+type PhoneCallChannel struct {
+	// ID identifies the channel.
+	ID uuid.UUID
+	// Entry is the id of the referenced address book entry.
+	Entry uuid.UUID
+	// Label is a human-readable label for the channel.
+	Label string
+	// Timeout until delivery-attempts time out for this channel.
+	Timeout time.Duration
+	// Phone is the phone number to call.
+	Phone string
+}
+
+// CreatePhoneCallChannel creates the given PhoneCallChannel in the database.
+func (m *Mall) CreatePhoneCallChannel(ctx context.Context, tx pgx.Tx, create PhoneCallChannel) error {
+	q, _, err := m.dialect.Insert(goqu.T("phone_call_channels")).Rows(goqu.Record{
+		"id":      create.ID,
+		"entry":   create.Entry,
+		"label":   create.Label,
+		"timeout": create.Timeout,
+		"phone":   create.Phone,
+	}).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// DeletePhoneCallChannelsByEntry deletes the phone call channels in the database
+// associated with the address book entry with the given id.
+func (m *Mall) DeletePhoneCallChannelsByEntry(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	q, _, err := m.dialect.Delete(goqu.T("phone_call_channels")).
+		Where(goqu.C("entry").Eq(entryID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	_, err = tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return nil
+}
+
+// PhoneCallChannelByID retrieves the PhoneCallChannel from the database with the given
+// id.
+func (m *Mall) PhoneCallChannelByID(ctx context.Context, tx pgx.Tx, channelID uuid.UUID) (PhoneCallChannel, error) {
+	q, _, err := m.dialect.From(goqu.T("phone_call_channels")).
+		Select(goqu.C("id"),
+			goqu.C("entry"),
+			goqu.C("label"),
+			goqu.C("timeout"),
+			goqu.C("phone")).
+		Where(goqu.C("id").Eq(channelID)).ToSQL()
+	if err != nil {
+		return PhoneCallChannel{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return PhoneCallChannel{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return PhoneCallChannel{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var channel PhoneCallChannel
+	err = rows.Scan(&channel.ID,
+		&channel.Entry,
+		&channel.Label,
+		&channel.Timeout,
+		&channel.Phone)
+	if err != nil {
+		return PhoneCallChannel{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return channel, nil
+}
@@ -0,0 +1,3 @@
+-- Add index for looking up attempts by intel, for example, when intel expires.
+
+create index accepted_intel_delivery_attempts_intel_ix on accepted_intel_delivery_attempts (intel);
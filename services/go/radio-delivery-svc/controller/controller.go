@@ -100,6 +100,9 @@ type Store interface {
 	// ActiveRadioDeliveriesAndLockOrWait locks and retrieves all radio-deliveries
 	// being active (success is NULL) from the database.
 	ActiveRadioDeliveriesAndLockOrWait(ctx context.Context, tx pgx.Tx, byOperation uuid.NullUUID) ([]store.ActiveRadioDelivery, error)
+	// ActiveRadioDeliveriesByIntelAndLockOrWait locks and retrieves all active
+	// radio-deliveries for the intel with the given id.
+	ActiveRadioDeliveriesByIntelAndLockOrWait(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]store.ActiveRadioDelivery, error)
 	// AcceptedIntelDeliveryAttemptByID retrieves the
 	// store.AcceptedIntelDeliveryAttempt with the given id from the store.
 	AcceptedIntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx, attemptID uuid.UUID) (store.AcceptedIntelDeliveryAttempt, error)
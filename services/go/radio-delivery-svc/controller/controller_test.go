@@ -111,6 +111,16 @@ func (m *StoreMock) ActiveRadioDeliveriesAndLockOrWait(ctx context.Context, tx p
 	return deliveries, args.Error(1)
 }
 
+func (m *StoreMock) ActiveRadioDeliveriesByIntelAndLockOrWait(ctx context.Context, tx pgx.Tx,
+	intelID uuid.UUID) ([]store.ActiveRadioDelivery, error) {
+	args := m.Called(ctx, tx, intelID)
+	var deliveries []store.ActiveRadioDelivery
+	if a := args.Get(0); a != nil {
+		deliveries = a.([]store.ActiveRadioDelivery)
+	}
+	return deliveries, args.Error(1)
+}
+
 func (m *StoreMock) AcceptedIntelDeliveryAttemptByID(ctx context.Context, tx pgx.Tx,
 	attemptID uuid.UUID) (store.AcceptedIntelDeliveryAttempt, error) {
 	args := m.Called(ctx, tx, attemptID)
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/lefinal/nulls"
@@ -88,3 +89,32 @@ func (c *Controller) UpdateIntelDeliveryAttemptStatus(ctx context.Context, tx pg
 	}
 	return nil
 }
+
+// ExpireIntel marks all active radio deliveries for the intel with the given id
+// as failed, as the intel expired and does not need to be delivered anymore.
+func (c *Controller) ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	activeDeliveries, err := c.store.ActiveRadioDeliveriesByIntelAndLockOrWait(ctx, tx, intelID)
+	if err != nil {
+		return meh.Wrap(err, "active radio deliveries by intel from store", meh.Details{"intel_id": intelID})
+	}
+	operations := make([]uuid.UUID, 0, len(activeDeliveries))
+	for _, activeDelivery := range activeDeliveries {
+		err = c.store.UpdateRadioDeliveryStatusByAttempt(ctx, tx, activeDelivery.Attempt, nulls.NewBool(false), "intel expired")
+		if err != nil {
+			return meh.Wrap(err, "update radio delivery status by attempt", meh.Details{"attempt_id": activeDelivery.Attempt})
+		}
+		radioDelivery, err := c.store.RadioDeliveryByAttempt(ctx, tx, activeDelivery.Attempt)
+		if err != nil {
+			return meh.Wrap(err, "retrieve updated radio delivery", meh.Details{"attempt_id": activeDelivery.Attempt})
+		}
+		err = c.notifier.NotifyRadioDeliveryFinished(ctx, tx, radioDelivery)
+		if err != nil {
+			return meh.Wrap(err, "notify about finished radio delivery", meh.Details{"radio_delivery": radioDelivery})
+		}
+		operations = append(operations, activeDelivery.IntelOperation)
+	}
+	if len(operations) > 0 {
+		c.connUpdateNotifier.scheduleNotifyUpdatesForOperations(ctx, operations...)
+	}
+	return nil
+}
@@ -413,3 +413,132 @@ func (suite *ControllerUpdateIntelDeliveryAttemptStatusSuite) TestOK() {
 func TestController_UpdateIntelDeliveryAttemptStatus(t *testing.T) {
 	suite.Run(t, new(ControllerUpdateIntelDeliveryAttemptStatusSuite))
 }
+
+// ControllerExpireIntelSuite tests Controller.ExpireIntel.
+type ControllerExpireIntelSuite struct {
+	suite.Suite
+	ctrl                       *ControllerMock
+	tx                         *testutil.DBTx
+	sampleIntelID              uuid.UUID
+	sampleActive               store.ActiveRadioDelivery
+	sampleUpdatedRadioDelivery store.RadioDelivery
+}
+
+func (suite *ControllerExpireIntelSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.sampleIntelID = testutil.NewUUIDV4()
+	suite.sampleActive = store.ActiveRadioDelivery{
+		Attempt:          testutil.NewUUIDV4(),
+		IntelOperation:   testutil.NewUUIDV4(),
+		IntelImportance:  120,
+		AttemptCreatedAt: testutil.NewRandomTime(),
+	}
+	suite.sampleUpdatedRadioDelivery = store.RadioDelivery{
+		Attempt:   suite.sampleActive.Attempt,
+		Success:   nulls.NewBool(false),
+		SuccessTS: testutil.NewRandomTime(),
+		Note:      "intel expired",
+	}
+
+	suite.ctrl.Store.On("ActiveRadioDeliveriesByIntelAndLockOrWait", mock.Anything, suite.tx, suite.sampleIntelID).
+		Return([]store.ActiveRadioDelivery{suite.sampleActive}, nil).Maybe()
+	suite.ctrl.Store.On("UpdateRadioDeliveryStatusByAttempt", mock.Anything, suite.tx, suite.sampleActive.Attempt,
+		nulls.NewBool(false), "intel expired").Return(nil).Maybe()
+	suite.ctrl.Store.On("RadioDeliveryByAttempt", mock.Anything, suite.tx, suite.sampleActive.Attempt).
+		Return(suite.sampleUpdatedRadioDelivery, nil).Maybe()
+	suite.ctrl.Notifier.On("NotifyRadioDeliveryFinished", mock.Anything, suite.tx, suite.sampleUpdatedRadioDelivery).
+		Return(nil).Maybe()
+}
+
+func (suite *ControllerExpireIntelSuite) TestRetrieveActiveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ActiveRadioDeliveriesByIntelAndLockOrWait")
+	suite.ctrl.Store.On("ActiveRadioDeliveriesByIntelAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestNoneActive() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "ActiveRadioDeliveriesByIntelAndLockOrWait")
+	suite.ctrl.Store.On("ActiveRadioDeliveriesByIntelAndLockOrWait", mock.Anything, mock.Anything, mock.Anything).
+		Return([]store.ActiveRadioDelivery{}, nil)
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.NoError(err, "should not fail")
+		suite.Empty(suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation, "should not schedule notify requests")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestUpdateStatusFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Store.Mock, "UpdateRadioDeliveryStatusByAttempt")
+	suite.ctrl.Store.On("UpdateRadioDeliveryStatusByAttempt", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(errors.New("sad life"))
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestNotifyFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	testutil.UnsetCallByMethod(&suite.ctrl.Notifier.Mock, "NotifyRadioDeliveryFinished")
+	suite.ctrl.Notifier.On("NotifyRadioDeliveryFinished", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("sad life"))
+	defer suite.ctrl.Notifier.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerExpireIntelSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+
+	go func() {
+		defer cancel()
+		err := suite.ctrl.Ctrl.ExpireIntel(timeout, suite.tx, suite.sampleIntelID)
+		suite.Require().NoError(err, "should not fail")
+		suite.ctrl.Store.AssertCalled(suite.T(), "UpdateRadioDeliveryStatusByAttempt", timeout, suite.tx,
+			suite.sampleActive.Attempt, nulls.NewBool(false), "intel expired")
+		suite.ctrl.Notifier.AssertCalled(suite.T(), "NotifyRadioDeliveryFinished", timeout, suite.tx,
+			suite.sampleUpdatedRadioDelivery)
+		// Assure notify request scheduled.
+		select {
+		case <-timeout.Done():
+			suite.Fail("no notify request for operation")
+		case op := <-suite.ctrl.Ctrl.connUpdateNotifier.notifyRequestsForOperation:
+			suite.Equal(suite.sampleActive.IntelOperation, op, "should have scheduled notify request for operation")
+		}
+	}()
+
+	wait()
+}
+
+func TestController_ExpireIntel(t *testing.T) {
+	suite.Run(t, new(ControllerExpireIntelSuite))
+}
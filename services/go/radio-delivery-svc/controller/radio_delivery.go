@@ -107,6 +107,24 @@ func (c *Controller) PickUpNextRadioDelivery(ctx context.Context, operationID uu
 	return attempt, ok, nil
 }
 
+// OperationByAttempt retrieves the operation of the intel, the accepted
+// intel-delivery-attempt with the given id belongs to.
+func (c *Controller) OperationByAttempt(ctx context.Context, attemptID uuid.UUID) (uuid.UUID, error) {
+	var operationID uuid.UUID
+	err := pgutil.RunInTx(ctx, c.db, func(ctx context.Context, tx pgx.Tx) error {
+		attempt, err := c.store.AcceptedIntelDeliveryAttemptByID(ctx, tx, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "accepted intel-delivery-attempt from store", meh.Details{"attempt_id": attemptID})
+		}
+		operationID = attempt.IntelOperation
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, meh.Wrap(err, "run in tx", nil)
+	}
+	return operationID, nil
+}
+
 // ReleasePickedUpRadioDelivery releases the picked up delivery for the attempt
 // with the given id. If limit is set, the delivery must be picked up by the
 // user with the given id.
@@ -621,6 +621,74 @@ func TestController_PickUpNextRadioDelivery(t *testing.T) {
 	suite.Run(t, new(ControllerPickUpNextRadioDeliverySuite))
 }
 
+// ControllerOperationByAttemptSuite tests Controller.OperationByAttempt.
+type ControllerOperationByAttemptSuite struct {
+	suite.Suite
+	ctrl          *ControllerMock
+	tx            *testutil.DBTx
+	sampleAttempt store.AcceptedIntelDeliveryAttempt
+}
+
+func (suite *ControllerOperationByAttemptSuite) SetupTest() {
+	suite.ctrl = NewMockController()
+	suite.tx = &testutil.DBTx{}
+	suite.ctrl.DB.Tx = []*testutil.DBTx{suite.tx}
+	suite.sampleAttempt = store.AcceptedIntelDeliveryAttempt{
+		ID:             testutil.NewUUIDV4(),
+		IntelOperation: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *ControllerOperationByAttemptSuite) TestBeginTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.DB.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.OperationByAttempt(timeout, suite.sampleAttempt.ID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerOperationByAttemptSuite) TestRetrieveAttemptFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(store.AcceptedIntelDeliveryAttempt{}, errors.New("sad life")).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.ctrl.Ctrl.OperationByAttempt(timeout, suite.sampleAttempt.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *ControllerOperationByAttemptSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.ctrl.Store.On("AcceptedIntelDeliveryAttemptByID", timeout, suite.tx, suite.sampleAttempt.ID).
+		Return(suite.sampleAttempt, nil).Once()
+	defer suite.ctrl.Store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.ctrl.Ctrl.OperationByAttempt(timeout, suite.sampleAttempt.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.True(suite.tx.IsCommitted, "should commit tx")
+		suite.Equal(suite.sampleAttempt.IntelOperation, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func TestController_OperationByAttempt(t *testing.T) {
+	suite.Run(t, new(ControllerOperationByAttemptSuite))
+}
+
 // ControllerReleasePickedUpRadioDeliverySuite tests
 // Controller.ReleasePickedUpRadioDelivery.
 type ControllerReleasePickedUpRadioDeliverySuite struct {
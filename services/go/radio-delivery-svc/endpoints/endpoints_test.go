@@ -2,11 +2,14 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/radio-delivery-svc/store"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/stretchr/testify/mock"
 	"net/http"
 )
@@ -30,6 +33,24 @@ func (m *StoreMock) ReleasePickedUpRadioDelivery(ctx context.Context, attemptID
 	return m.Called(ctx, attemptID, limitToPickedUpBy).Error(0)
 }
 
+func (m *StoreMock) OperationByAttempt(ctx context.Context, attemptID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, attemptID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// operationScopedPermission creates a permission.Permission with the given
+// name, that is limited to the operations with the given ids.
+func operationScopedPermission(name permission.Name, operations ...uuid.UUID) permission.Permission {
+	raw, err := json.Marshal(permission.OperationScopeOptions{Operations: operations})
+	if err != nil {
+		panic(err)
+	}
+	return permission.Permission{
+		Name:    name,
+		Options: nulls.NewJSONRawMessage(raw),
+	}
+}
+
 type wsHubStub struct {
 }
 
@@ -70,7 +70,7 @@ func handleGetNextRadioDelivery(s handleGetNextRadioDeliveryStore) httpendpoints
 			return meh.NewBadInputErrFromErr(err, "parse operation id", meh.Details{"was": operationIDStr})
 		}
 		// Check permissions.
-		err = auth.AssurePermission(token, permission.DeliverAnyRadioDelivery())
+		err = auth.AssurePermission(token, permission.InOperation(operationID, permission.DeliverAnyRadioDelivery()))
 		if err != nil {
 			return meh.Wrap(err, "assure permission", nil)
 		}
@@ -91,9 +91,54 @@ func handleGetNextRadioDelivery(s handleGetNextRadioDeliveryStore) httpendpoints
 	}
 }
 
+// operationByAttemptStore are the dependencies needed for looking up the
+// operation of accepted intel-delivery-attempts.
+type operationByAttemptStore interface {
+	OperationByAttempt(ctx context.Context, attemptID uuid.UUID) (uuid.UUID, error)
+}
+
+// limitToPickedUpByForAttempt assures, that either
+// permission.DeliverAnyRadioDelivery or permission.ManageAnyRadioDelivery is
+// granted for the operation of the attempt with the given id. If managing is
+// granted, no limit is returned. Otherwise, the limit is set to the user of the
+// given auth.Token. The operation is only looked up if any of the permissions
+// is limited to certain operations.
+func limitToPickedUpByForAttempt(ctx context.Context, s operationByAttemptStore, token auth.Token,
+	attemptID uuid.UUID) (uuid.NullUUID, error) {
+	deliverScope, err := auth.GrantedOperations(token, permission.DeliverAnyRadioDelivery())
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "granted operations for deliver-permission", nil)
+	}
+	manageScope, err := auth.GrantedOperations(token, permission.ManageAnyRadioDelivery())
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "granted operations for manage-permission", nil)
+	}
+	if deliverScope.Empty() && manageScope.Empty() {
+		return uuid.NullUUID{}, meh.NewForbiddenErr("missing permission", nil)
+	}
+	if manageScope.All {
+		return uuid.NullUUID{}, nil
+	}
+	if deliverScope.All && manageScope.Empty() {
+		return nulls.NewUUID(token.UserID), nil
+	}
+	operationID, err := s.OperationByAttempt(ctx, attemptID)
+	if err != nil {
+		return uuid.NullUUID{}, meh.Wrap(err, "operation by attempt", meh.Details{"attempt_id": attemptID})
+	}
+	if manageScope.Includes(operationID) {
+		return uuid.NullUUID{}, nil
+	}
+	if deliverScope.Includes(operationID) {
+		return nulls.NewUUID(token.UserID), nil
+	}
+	return uuid.NullUUID{}, meh.NewForbiddenErr("permission not granted for operation", meh.Details{"operation_id": operationID})
+}
+
 // handleReleasePickedUpRadioDeliveryStore are the dependencies needed for
 // handleReleasePickedUpRadioDelivery.
 type handleReleasePickedUpRadioDeliveryStore interface {
+	operationByAttemptStore
 	ReleasePickedUpRadioDelivery(ctx context.Context, attemptID uuid.UUID, limitToPickedUpBy uuid.NullUUID) error
 }
 
@@ -104,28 +149,17 @@ func handleReleasePickedUpRadioDelivery(s handleReleasePickedUpRadioDeliveryStor
 		if !token.IsAuthenticated {
 			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
-		// Check permissions.
-		deliveryGranted, err := auth.HasPermission(token, permission.DeliverAnyRadioDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check deliver-permission", nil)
-		}
-		manageDeliveriesGranted, err := auth.HasPermission(token, permission.ManageAnyRadioDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check manage-permission", nil)
-		}
-		if !deliveryGranted && !manageDeliveriesGranted {
-			return meh.NewForbiddenErr("missing permission", nil)
-		}
-		limitToPickedUpBy := nulls.NewUUID(token.UserID)
-		if manageDeliveriesGranted {
-			limitToPickedUpBy = uuid.NullUUID{}
-		}
 		// Extract attempt id.
 		attemptIDStr := c.Param("attemptID")
 		attemptID, err := uuid.FromString(attemptIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
 		}
+		// Check permissions.
+		limitToPickedUpBy, err := limitToPickedUpByForAttempt(c.Request.Context(), s, token, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "limit to picked up by for attempt", meh.Details{"attempt_id": attemptID})
+		}
 		// Release.
 		err = s.ReleasePickedUpRadioDelivery(c.Request.Context(), attemptID, limitToPickedUpBy)
 		if err != nil {
@@ -149,6 +183,7 @@ type publicFinishRadioDeliveryDetails struct {
 // handleFinishRadioDeliveryStore are the dependencies needed for
 // handleFinishRadioDelivery.
 type handleFinishRadioDeliveryStore interface {
+	operationByAttemptStore
 	FinishRadioDelivery(ctx context.Context, attemptID uuid.UUID, success bool, note string, limitToPickedUpBy uuid.NullUUID) error
 }
 
@@ -158,28 +193,17 @@ func handleFinishRadioDelivery(s handleFinishRadioDeliveryStore) httpendpoints.H
 		if !token.IsAuthenticated {
 			return meh.NewUnauthorizedErr("not authenticated", nil)
 		}
-		// Check permissions.
-		deliveryGranted, err := auth.HasPermission(token, permission.DeliverAnyRadioDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check deliver-permission", nil)
-		}
-		manageDeliveriesGranted, err := auth.HasPermission(token, permission.ManageAnyRadioDelivery())
-		if err != nil {
-			return meh.Wrap(err, "check manage-permission", nil)
-		}
-		if !deliveryGranted && !manageDeliveriesGranted {
-			return meh.NewForbiddenErr("missing permission", nil)
-		}
-		limitToPickedUpBy := nulls.NewUUID(token.UserID)
-		if manageDeliveriesGranted {
-			limitToPickedUpBy = uuid.NullUUID{}
-		}
 		// Extract attempt id.
 		attemptIDStr := c.Param("attemptID")
 		attemptID, err := uuid.FromString(attemptIDStr)
 		if err != nil {
 			return meh.NewBadInputErrFromErr(err, "parse attempt id", meh.Details{"was": attemptIDStr})
 		}
+		// Check permissions.
+		limitToPickedUpBy, err := limitToPickedUpByForAttempt(c.Request.Context(), s, token, attemptID)
+		if err != nil {
+			return meh.Wrap(err, "limit to picked up by for attempt", meh.Details{"attempt_id": attemptID})
+		}
 		// Parse body.
 		var finishDetails publicFinishRadioDeliveryDetails
 		err = c.BindJSON(&finishDetails)
@@ -107,6 +107,36 @@ func (suite *handleGetNextRadioDeliverySuite) TestMissingPermission() {
 	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
 }
 
+func (suite *handleGetNextRadioDeliverySuite) TestPermissionForOtherOperation() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		operationScopedPermission(permission.DeliverAnyRadioDeliveryPermissionName, testutil.NewUUIDV4()),
+	}
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleGetNextRadioDeliverySuite) TestPermissionForOperationOK() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		operationScopedPermission(permission.DeliverAnyRadioDeliveryPermissionName, suite.sampleStoreNextDelivery.IntelOperation),
+	}
+	suite.s.On("PickUpNextRadioDelivery", mock.Anything, suite.sampleStoreNextDelivery.IntelOperation, suite.sampleToken.UserID).
+		Return(suite.sampleStoreNextDelivery, true, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/operations/%s/next", suite.sampleStoreNextDelivery.IntelOperation.String()),
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
 func (suite *handleGetNextRadioDeliverySuite) TestPickUpNextFail() {
 	suite.s.On("PickUpNextRadioDelivery", mock.Anything, suite.samplePublicNextDelivery.IntelOperation, suite.sampleToken.UserID).
 		Return(store.AcceptedIntelDeliveryAttempt{}, false, errors.New("sad life")).Once()
@@ -310,6 +340,85 @@ func (suite *handleReleasePickedUpRadioDeliverySuite) TestDeliverAndManagePermis
 	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
 }
 
+func (suite *handleReleasePickedUpRadioDeliverySuite) TestRetrieveOperationFail() {
+	suite.sampleToken.Permissions = []permission.Permission{
+		operationScopedPermission(permission.ManageAnyRadioDeliveryPermissionName, testutil.NewUUIDV4()),
+	}
+	suite.s.On("OperationByAttempt", mock.Anything, suite.sampleAttemptID).
+		Return(uuid.Nil, errors.New("sad life")).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpRadioDeliverySuite) TestPermissionsForOtherOperation() {
+	operationID := testutil.NewUUIDV4()
+	suite.sampleToken.Permissions = []permission.Permission{
+		operationScopedPermission(permission.DeliverAnyRadioDeliveryPermissionName, testutil.NewUUIDV4()),
+		operationScopedPermission(permission.ManageAnyRadioDeliveryPermissionName, testutil.NewUUIDV4()),
+	}
+	suite.s.On("OperationByAttempt", mock.Anything, suite.sampleAttemptID).Return(operationID, nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusForbidden, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpRadioDeliverySuite) TestDeliverPermissionForOperation() {
+	operationID := testutil.NewUUIDV4()
+	suite.sampleToken.Permissions = []permission.Permission{
+		operationScopedPermission(permission.DeliverAnyRadioDeliveryPermissionName, operationID),
+		operationScopedPermission(permission.ManageAnyRadioDeliveryPermissionName, testutil.NewUUIDV4()),
+	}
+	suite.s.On("OperationByAttempt", mock.Anything, suite.sampleAttemptID).Return(operationID, nil).Once()
+	suite.s.On("ReleasePickedUpRadioDelivery", mock.Anything, suite.sampleAttemptID, nulls.NewUUID(suite.sampleToken.UserID)).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *handleReleasePickedUpRadioDeliverySuite) TestManagePermissionForOperation() {
+	operationID := testutil.NewUUIDV4()
+	suite.sampleToken.Permissions = []permission.Permission{
+		{Name: permission.DeliverAnyRadioDeliveryPermissionName},
+		operationScopedPermission(permission.ManageAnyRadioDeliveryPermissionName, operationID),
+	}
+	suite.s.On("OperationByAttempt", mock.Anything, suite.sampleAttemptID).Return(operationID, nil).Once()
+	suite.s.On("ReleasePickedUpRadioDelivery", mock.Anything, suite.sampleAttemptID, uuid.NullUUID{}).
+		Return(nil).Once()
+	defer suite.s.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/%s/release", suite.sampleAttemptID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
 func Test_handleReleasePickedUpRadioDelivery(t *testing.T) {
 	suite.Run(t, new(handleReleasePickedUpRadioDeliverySuite))
 }
@@ -35,6 +35,9 @@ type Handler interface {
 	// UpdateOperationMembersByOperation replaces the associated operation members
 	// for the operation with the given id with the new given ones.
 	UpdateOperationMembersByOperation(ctx context.Context, tx pgx.Tx, operationID uuid.UUID, newMembers []uuid.UUID) error
+	// ExpireIntel marks all active radio deliveries for the intel with the given id
+	// as failed.
+	ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error
 }
 
 // HandlerFn for handling messages.
@@ -49,6 +52,8 @@ func (p *Port) HandlerFn(handler Handler) kafkautil.HandlerFunc {
 			return meh.NilOrWrap(p.handleUsersTopic(ctx, tx, handler, message), "handle users topic", nil)
 		case event.OperationsTopic:
 			return meh.NilOrWrap(p.handleOperationsTopic(ctx, tx, handler, message), "handle operations topic", nil)
+		case event.IntelTopic:
+			return meh.NilOrWrap(p.handleIntelTopic(ctx, tx, handler, message), "handle intel topic", nil)
 		}
 		return nil
 	}
@@ -257,3 +262,26 @@ func (p *Port) handleOperationMembersUpdated(ctx context.Context, tx pgx.Tx, han
 	}
 	return nil
 }
+
+// handleIntelTopic handles the event.IntelTopic.
+func (p *Port) handleIntelTopic(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	switch message.EventType {
+	case event.TypeIntelExpired:
+		return meh.NilOrWrap(p.handleIntelExpired(ctx, tx, handler, message), "handle intel expired", nil)
+	}
+	return nil
+}
+
+// handleIntelExpired handles an event.TypeIntelExpired event.
+func (p *Port) handleIntelExpired(ctx context.Context, tx pgx.Tx, handler Handler, message kafkautil.InboundMessage) error {
+	var intelExpiredEvent event.IntelExpired
+	err := json.Unmarshal(message.RawValue, &intelExpiredEvent)
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "unmarshal event", meh.Details{"raw": string(message.RawValue)})
+	}
+	err = handler.ExpireIntel(ctx, tx, intelExpiredEvent.ID)
+	if err != nil {
+		return meh.Wrap(err, "expire intel", meh.Details{"intel_id": intelExpiredEvent.ID})
+	}
+	return nil
+}
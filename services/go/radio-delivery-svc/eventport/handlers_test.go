@@ -51,6 +51,10 @@ func (m *HandlerMock) UpdateOperationMembersByOperation(ctx context.Context, tx
 	return m.Called(ctx, tx, operationID, newMembers).Error(0)
 }
 
+func (m *HandlerMock) ExpireIntel(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) error {
+	return m.Called(ctx, tx, intelID).Error(0)
+}
+
 // portHandleUserCreatedSuite tests Port.handleUserCreated.
 type portHandleUserCreatedSuite struct {
 	suite.Suite
@@ -704,3 +708,75 @@ func (suite *portHandleOperationMembersUpdatedSuite) TestOK() {
 func TestPort_handleOperationMembersUpdated(t *testing.T) {
 	suite.Run(t, new(portHandleOperationMembersUpdatedSuite))
 }
+
+// portHandleIntelExpiredSuite tests Port.handleIntelExpired.
+type portHandleIntelExpiredSuite struct {
+	suite.Suite
+	handler     *HandlerMock
+	port        *PortMock
+	sampleEvent event.IntelExpired
+}
+
+func (suite *portHandleIntelExpiredSuite) SetupTest() {
+	suite.handler = &HandlerMock{}
+	suite.port = newMockPort()
+	suite.sampleEvent = event.IntelExpired{
+		ID: testutil.NewUUIDV4(),
+	}
+}
+
+func (suite *portHandleIntelExpiredSuite) handle(ctx context.Context, tx pgx.Tx, rawValue json.RawMessage) error {
+	return suite.port.Port.HandlerFn(suite.handler)(ctx, tx, kafkautil.InboundMessage{
+		Topic:     event.IntelTopic,
+		EventType: event.TypeIntelExpired,
+		RawValue:  rawValue,
+	})
+}
+
+func (suite *portHandleIntelExpiredSuite) TestBadEventValue() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, json.RawMessage(`{invalid`))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelExpiredSuite) TestExpireFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("ExpireIntel", timeout, tx, suite.sampleEvent.ID).
+		Return(errors.New("sad life"))
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *portHandleIntelExpiredSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.handler.On("ExpireIntel", timeout, tx, suite.sampleEvent.ID).Return(nil)
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.handle(timeout, tx, testutil.MarshalJSONMust(suite.sampleEvent))
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func TestPort_handleIntelExpired(t *testing.T) {
+	suite.Run(t, new(portHandleIntelExpiredSuite))
+}
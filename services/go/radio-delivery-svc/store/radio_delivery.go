@@ -153,6 +153,31 @@ type ActiveRadioDelivery struct {
 // ActiveRadioDeliveriesAndLockOrWait locks and retrieves all radio-deliveries
 // being active (success is NULL) from the database.
 func (m *Mall) ActiveRadioDeliveriesAndLockOrWait(ctx context.Context, tx pgx.Tx, byOperation uuid.NullUUID) ([]ActiveRadioDelivery, error) {
+	var conditions []exp.Expression
+	if byOperation.Valid {
+		conditions = append(conditions, goqu.I("accepted_intel_delivery_attempts.intel_operation").Eq(byOperation.UUID))
+	}
+	radioDeliveries, err := m.activeRadioDeliveriesAndLockOrWait(ctx, tx, conditions...)
+	if err != nil {
+		return nil, meh.Wrap(err, "active radio deliveries and lock or wait", nil)
+	}
+	return radioDeliveries, nil
+}
+
+// ActiveRadioDeliveriesByIntelAndLockOrWait locks and retrieves all active
+// radio-deliveries for the intel with the given id.
+func (m *Mall) ActiveRadioDeliveriesByIntelAndLockOrWait(ctx context.Context, tx pgx.Tx, intelID uuid.UUID) ([]ActiveRadioDelivery, error) {
+	radioDeliveries, err := m.activeRadioDeliveriesAndLockOrWait(ctx, tx,
+		goqu.I("accepted_intel_delivery_attempts.intel").Eq(intelID))
+	if err != nil {
+		return nil, meh.Wrap(err, "active radio deliveries and lock or wait", nil)
+	}
+	return radioDeliveries, nil
+}
+
+// activeRadioDeliveriesAndLockOrWait locks and retrieves all active
+// radio-deliveries, matching the given conditions.
+func (m *Mall) activeRadioDeliveriesAndLockOrWait(ctx context.Context, tx pgx.Tx, conditions ...exp.Expression) ([]ActiveRadioDelivery, error) {
 	qb := m.dialect.From(goqu.T("radio_deliveries")).
 		InnerJoin(goqu.T("accepted_intel_delivery_attempts"),
 			goqu.On(goqu.I("accepted_intel_delivery_attempts.id").Eq(goqu.I("radio_deliveries.attempt")))).
@@ -163,8 +188,8 @@ func (m *Mall) ActiveRadioDeliveriesAndLockOrWait(ctx context.Context, tx pgx.Tx
 			goqu.I("accepted_intel_delivery_attempts.created_at")).
 		ForUpdate(exp.Wait).
 		Where(goqu.I("radio_deliveries.success").IsNull())
-	if byOperation.Valid {
-		qb = qb.Where(goqu.I("accepted_intel_delivery_attempts.intel_operation").Eq(byOperation.UUID))
+	if len(conditions) > 0 {
+		qb = qb.Where(conditions...)
 	}
 	q, _, err := qb.ToSQL()
 	if err != nil {
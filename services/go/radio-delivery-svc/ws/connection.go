@@ -40,11 +40,11 @@ func (conn *connection) UserID() uuid.UUID {
 // NotifyNewAvailable sends a message with
 // messageTypeNewRadioDeliveriesAvailable for the operation with the given id.
 func (conn *connection) NotifyNewAvailable(ctx context.Context, operationID uuid.UUID) error {
-	deliverGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.DeliverAnyRadioDelivery())
+	deliverGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.InOperation(operationID, permission.DeliverAnyRadioDelivery()))
 	if err != nil {
 		return meh.Wrap(err, "check permission", nil)
 	}
-	manageGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.ManageAnyRadioDelivery())
+	manageGranted, err := auth.HasPermission(conn.conn.AuthToken(), permission.InOperation(operationID, permission.ManageAnyRadioDelivery()))
 	if err != nil {
 		return meh.Wrap(err, "check permission", nil)
 	}
@@ -137,3 +137,21 @@ func AssurePermission(token Token, toHave ...permission.Matcher) error {
 	}
 	return nil
 }
+
+// GrantedOperations returns the permission.OperationScope, the given
+// permission.Matcher is granted for. If not authenticated, an empty scope is
+// returned. If the token claims to be admin, the permission is granted for all
+// operations.
+func GrantedOperations(token Token, matcher permission.Matcher) (permission.OperationScope, error) {
+	if !token.IsAuthenticated {
+		return permission.OperationScope{}, nil
+	}
+	if token.IsAdmin {
+		return permission.OperationScope{All: true}, nil
+	}
+	scope, err := permission.GrantedOperations(token.Permissions, matcher)
+	if err != nil {
+		return permission.OperationScope{}, meh.Wrap(err, "granted operations", meh.Details{"permissions": token.Permissions})
+	}
+	return scope, nil
+}
@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt"
+	"github.com/lefinal/nulls"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/permission"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -138,3 +142,51 @@ func (suite *HasPermissionSuite) TestOK() {
 func TestHasPermission(t *testing.T) {
 	suite.Run(t, new(HasPermissionSuite))
 }
+
+// GrantedOperationsSuite tests GrantedOperations.
+type GrantedOperationsSuite struct {
+	suite.Suite
+	sampleOperation  uuid.UUID
+	samplePermission permission.Permission
+}
+
+func (suite *GrantedOperationsSuite) SetupTest() {
+	suite.sampleOperation = uuid.Must(uuid.NewV4())
+	suite.samplePermission = permission.Permission{
+		Name:    permission.ViewAnyIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(fmt.Sprintf(`{"operations":["%s"]}`, suite.sampleOperation))),
+	}
+}
+
+func (suite *GrantedOperationsSuite) TestNotAuthenticated() {
+	scope, err := GrantedOperations(Token{
+		IsAuthenticated: false,
+		IsAdmin:         true,
+		Permissions:     []permission.Permission{suite.samplePermission},
+	}, permission.ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(scope.Empty(), "should return empty scope")
+}
+
+func (suite *GrantedOperationsSuite) TestAdmin() {
+	scope, err := GrantedOperations(Token{
+		IsAuthenticated: true,
+		IsAdmin:         true,
+	}, permission.ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(scope.All, "should return all")
+}
+
+func (suite *GrantedOperationsSuite) TestOK() {
+	scope, err := GrantedOperations(Token{
+		IsAuthenticated: true,
+		Permissions:     []permission.Permission{suite.samplePermission},
+	}, permission.ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(permission.OperationScope{Operations: []uuid.UUID{suite.sampleOperation}}, scope,
+		"should return correct scope")
+}
+
+func TestGrantedOperations(t *testing.T) {
+	suite.Run(t, new(GrantedOperationsSuite))
+}
@@ -4,11 +4,14 @@ package basicconfig
 
 import (
 	"github.com/lefinal/meh"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/kafkautil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/logging"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/ready"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"strconv"
+	"time"
 )
 
 const (
@@ -24,6 +27,18 @@ const (
 	EnvAuthTokenSecret = "MDS_AUTH_TOKEN_SECRET"
 	// EnvReadyProbeServeAddr for Config.ReadyProbeServeAddr.
 	EnvReadyProbeServeAddr = "MDS_READY_PROBE_SERVE_ADDR"
+	// EnvKafkaInboxMaxAttempts for kafkautil.ConnectorConfig.InboxMaxAttempts in
+	// Config.KafkaConnector.
+	EnvKafkaInboxMaxAttempts = "MDS_KAFKA_INBOX_MAX_ATTEMPTS"
+	// EnvKafkaInboxProcessedRetention for kafkautil.RetentionConfig.ProcessedInbox
+	// in Config.KafkaConnector.
+	EnvKafkaInboxProcessedRetention = "MDS_KAFKA_INBOX_PROCESSED_RETENTION"
+	// EnvKafkaInboxSkippedRetention for kafkautil.RetentionConfig.SkippedInbox in
+	// Config.KafkaConnector.
+	EnvKafkaInboxSkippedRetention = "MDS_KAFKA_INBOX_SKIPPED_RETENTION"
+	// EnvKafkaOutboxSentRetention for kafkautil.RetentionConfig.SentOutbox in
+	// Config.KafkaConnector.
+	EnvKafkaOutboxSentRetention = "MDS_KAFKA_OUTBOX_SENT_RETENTION"
 )
 
 // Config is a basic configuration with support for database and Kafka
@@ -44,6 +59,9 @@ type Config struct {
 	// ready-probe-endpoints. ParseFromEnv will set this to ready.DefaultServeAddr
 	// if not provided otherwise.
 	ReadyProbeServeAddr string `json:"ready_probe_serve_addr"`
+	// KafkaConnector is the configuration for the kafkautil.Connector. Values not
+	// provided are zero, which results in the defaults being used.
+	KafkaConnector kafkautil.ConnectorConfig `json:"kafka_connector"`
 }
 
 // ParseFromEnv parses a Config from the related environment variables like
@@ -88,5 +106,48 @@ func ParseFromEnv() (Config, error) {
 	if c.ReadyProbeServeAddr == "" {
 		c.ReadyProbeServeAddr = ready.DefaultServeAddr
 	}
+	// Kafka inbox max attempts.
+	kafkaInboxMaxAttemptsStr := os.Getenv(EnvKafkaInboxMaxAttempts)
+	if kafkaInboxMaxAttemptsStr != "" {
+		kafkaInboxMaxAttempts, err := strconv.Atoi(kafkaInboxMaxAttemptsStr)
+		if err != nil {
+			return Config{}, meh.NewBadInputErrFromErr(err, "parse kafka inbox max attempts", meh.Details{
+				"env": EnvKafkaInboxMaxAttempts,
+				"was": kafkaInboxMaxAttemptsStr,
+			})
+		}
+		c.KafkaConnector.InboxMaxAttempts = kafkaInboxMaxAttempts
+	}
+	// Kafka retention.
+	var err error
+	c.KafkaConnector.Retention.ProcessedInbox, err = durationFromEnv(EnvKafkaInboxProcessedRetention)
+	if err != nil {
+		return Config{}, meh.Wrap(err, "kafka inbox processed retention from env", nil)
+	}
+	c.KafkaConnector.Retention.SkippedInbox, err = durationFromEnv(EnvKafkaInboxSkippedRetention)
+	if err != nil {
+		return Config{}, meh.Wrap(err, "kafka inbox skipped retention from env", nil)
+	}
+	c.KafkaConnector.Retention.SentOutbox, err = durationFromEnv(EnvKafkaOutboxSentRetention)
+	if err != nil {
+		return Config{}, meh.Wrap(err, "kafka outbox sent retention from env", nil)
+	}
 	return c, nil
 }
+
+// durationFromEnv parses the duration from the environment variable with the
+// given name. If not set, zero is returned.
+func durationFromEnv(env string) (time.Duration, error) {
+	durationStr := os.Getenv(env)
+	if durationStr == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return 0, meh.NewBadInputErrFromErr(err, "parse duration", meh.Details{
+			"env": env,
+			"was": durationStr,
+		})
+	}
+	return duration, nil
+}
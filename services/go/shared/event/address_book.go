@@ -105,6 +105,8 @@ type AddressBookEntryEmailChannelDetails struct {
 type AddressBookEntryForwardToGroupChannelDetails struct {
 	// ForwardToGroup is the id of the group that should be forwarded to.
 	ForwardToGroup []uuid.UUID `json:"forward_to_group"`
+	// Quorum is the number of forwarded deliveries that need to succeed.
+	Quorum int32 `json:"quorum"`
 }
 
 // AddressBookEntryForwardToUserChannelDetails holds channel details for
@@ -112,6 +114,8 @@ type AddressBookEntryForwardToGroupChannelDetails struct {
 type AddressBookEntryForwardToUserChannelDetails struct {
 	// ForwardToUser is the id of the user that should be forwarded to.
 	ForwardToUser []uuid.UUID `json:"forward_to_user"`
+	// Quorum is the number of forwarded deliveries that need to succeed.
+	Quorum int32 `json:"quorum"`
 }
 
 // AddressBookEntryInAppNotificationChannelDetails holds channel details for
@@ -35,3 +35,67 @@ type UserLoggedOut struct {
 	// RemoteAddr from http.Request.
 	RemoteAddr string
 }
+
+// TypeUserLoginFailed is used when a login attempt failed.
+const TypeUserLoginFailed Type = "user-login-failed"
+
+// UserLoginFailedReason describes why a login attempt failed.
+type UserLoginFailedReason string
+
+const (
+	// UserLoginFailedReasonUnknownUser is used when no user with the given
+	// username exists.
+	UserLoginFailedReasonUnknownUser UserLoginFailedReason = "unknown-user"
+	// UserLoginFailedReasonInactive is used when the user is inactive.
+	UserLoginFailedReasonInactive UserLoginFailedReason = "inactive"
+	// UserLoginFailedReasonWrongPass is used when the provided password is wrong.
+	UserLoginFailedReasonWrongPass UserLoginFailedReason = "wrong-pass"
+	// UserLoginFailedReasonLockedOut is used when login is currently locked
+	// because of too many failed attempts.
+	UserLoginFailedReasonLockedOut UserLoginFailedReason = "locked-out"
+)
+
+// UserLoginFailed is the value for TypeUserLoginFailed.
+type UserLoginFailed struct {
+	// Username that was used for logging in.
+	Username string
+	// User is the id of the user with the username, if known.
+	User uuid.NullUUID
+	// Reason describes why the attempt failed.
+	Reason UserLoginFailedReason
+	// Host from http.Request.
+	Host string
+	// UserAgent from http.Request.
+	UserAgent string
+	// RemoteAddr from http.Request.
+	RemoteAddr string
+}
+
+// TypeSessionRevoked is used when a session was revoked, either manually or
+// because of being expired.
+const TypeSessionRevoked Type = "session-revoked"
+
+// SessionRevokedReason describes why a session was revoked.
+type SessionRevokedReason string
+
+const (
+	// SessionRevokedReasonRevoked is used for sessions that were revoked
+	// manually.
+	SessionRevokedReasonRevoked SessionRevokedReason = "revoked"
+	// SessionRevokedReasonExpired is used for sessions that were removed because
+	// of exceeding idle or absolute lifetime.
+	SessionRevokedReasonExpired SessionRevokedReason = "expired"
+)
+
+// SessionRevoked is the value for TypeSessionRevoked.
+type SessionRevoked struct {
+	// Session is the id of the revoked session.
+	Session uuid.UUID
+	// User is the id of the user the session belonged to.
+	User uuid.UUID
+	// Reason for revocation.
+	Reason SessionRevokedReason
+	// RevokedBy is the id of the user that revoked the session. Not set, if
+	// Reason is SessionRevokedReasonExpired.
+	RevokedBy uuid.NullUUID
+}
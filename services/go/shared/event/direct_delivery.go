@@ -0,0 +1,91 @@
+package event
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// TypeDirectDeliveryReadyForPickup notifies that a direct delivery for
+// an intel-delivery-attempt is ready for pickup by a user for delivery.
+const TypeDirectDeliveryReadyForPickup Type = "direct-delivery-ready-for-pickup"
+
+// DirectDeliveryReadyForPickup is the content for
+// TypeDirectDeliveryReadyForPickup.
+type DirectDeliveryReadyForPickup struct {
+	// Attempt identifies the intel-delivery-attempt.
+	Attempt uuid.UUID
+	// Intel is the id of the referenced intel.
+	Intel uuid.UUID
+	// IntelOperation is the operation the referenced intel is assigned to.
+	IntelOperation uuid.UUID
+	// IntelImportance is the importance of the referenced intel.
+	IntelImportance int
+	// AttemptAssignedTo is the id of the assigned address book entry.
+	AttemptAssignedTo uuid.UUID
+	// AttemptAssignedToLabel is the label of the assigned address book entry.
+	AttemptAssignedToLabel string
+	// Delivery is the id of the referenced delivery.
+	Delivery uuid.UUID
+	// Channel is the id of the channel to use for this attempt.
+	Channel uuid.UUID
+	// Note contains optional human-readable information regarding the direct
+	// delivery.
+	Note string
+	// AttemptAcceptedAt is the timestamp when the intel-delivery-attempt was
+	// accepted by the direct-delivery-service.
+	AttemptAcceptedAt time.Time
+}
+
+// TypeDirectDeliveryPickedUp for a direct delivery being picked up.
+const TypeDirectDeliveryPickedUp Type = "direct-delivery-picked-up"
+
+// DirectDeliveryPickedUp is the content for TypeDirectDeliveryPickedUp.
+type DirectDeliveryPickedUp struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// PickedUpBy is the id of the user that picked up the direct delivery.
+	PickedUpBy uuid.UUID
+	// PickedUpAt is the timestamp when the direct delivery was picked up.
+	PickedUpAt time.Time
+}
+
+// TypeDirectDeliveryReleased for picked up direct deliveries being
+// released again and therefore ready for pickup.
+const TypeDirectDeliveryReleased Type = "direct-delivery-released"
+
+// DirectDeliveryReleased is the content for TypeDirectDeliveryReleased.
+type DirectDeliveryReleased struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// ReleasedAt is the timestamp when the direct delivery was released.
+	ReleasedAt time.Time
+}
+
+// TypeDirectDeliveryFinished when a direct delivery was
+// finished/canceled.
+const TypeDirectDeliveryFinished Type = "direct-delivery-finished"
+
+// DirectDeliveryFinished is the content for TypeDirectDeliveryFinished.
+type DirectDeliveryFinished struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// PickedUpBy is the id of the user that picked up the direct delivery.
+	PickedUpBy uuid.NullUUID
+	// PickedUpAt is the timestamp when the user from PickedUpBy picked up the
+	// direct delivery.
+	PickedUpAt nulls.Time
+	// Success of the direct delivery.
+	Success bool
+	// FinishedAt is the timestamp when the direct delivery was marked as
+	// finished.
+	FinishedAt time.Time
+	// Note contains optional information.
+	Note string
+	// HandedOverAt is the timestamp when the intel was handed over in person to the
+	// recipient. Only set for successful hand-overs.
+	HandedOverAt nulls.Time
+	// RecipientConfirmation is the name or confirmation given by the recipient
+	// when the intel was handed over.
+	RecipientConfirmation nulls.String
+}
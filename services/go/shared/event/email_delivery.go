@@ -0,0 +1,44 @@
+package event
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// TypeEmailForIntelPending is used when an email for an intel-delivery-attempt
+// was accepted and is pending for being sent.
+const TypeEmailForIntelPending Type = "email-for-intel-pending"
+
+// EmailForIntelPending is the value for TypeEmailForIntelPending.
+type EmailForIntelPending struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID `json:"attempt"`
+	// Since is the timestamp since when the email is pending.
+	Since time.Time `json:"since"`
+}
+
+// TypeEmailForIntelSent is used when an email for an intel-delivery-attempt was
+// successfully handed over to the mail server.
+const TypeEmailForIntelSent Type = "email-for-intel-sent"
+
+// EmailForIntelSent is the value for TypeEmailForIntelSent.
+type EmailForIntelSent struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID `json:"attempt"`
+	// SentAt is the timestamp when the email was sent.
+	SentAt time.Time `json:"sent_at"`
+}
+
+// TypeEmailForIntelFailed is used when an email for an intel-delivery-attempt
+// could not be sent.
+const TypeEmailForIntelFailed Type = "email-for-intel-failed"
+
+// EmailForIntelFailed is the value for TypeEmailForIntelFailed.
+type EmailForIntelFailed struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID `json:"attempt"`
+	// FailedAt is the timestamp when sending the email failed.
+	FailedAt time.Time `json:"failed_at"`
+	// Reason is a human-readable description of why sending failed.
+	Reason string `json:"reason"`
+}
@@ -30,3 +30,50 @@ type InAppNotificationForIntelSent struct {
 	// SentAt is the timestamp when the notification was sent.
 	SentAt time.Time `json:"sent_at"`
 }
+
+// TypeInAppNotificationForIntelReceived is used when the recipient's client
+// reported an in-app-notification for an intel as received.
+const TypeInAppNotificationForIntelReceived Type = "in-app-notification-for-intel-received"
+
+// InAppNotificationForIntelReceived is the value for
+// TypeInAppNotificationForIntelReceived.
+type InAppNotificationForIntelReceived struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID `json:"attempt"`
+	// ReceivedBy is the id of the user that received the notification.
+	ReceivedBy uuid.UUID `json:"received_by"`
+	// ReceivedAt is the timestamp when the notification was reported as received.
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// TypeInAppNotificationForIntelAcknowledged is used when the recipient read and
+// acknowledged an in-app-notification for an intel.
+const TypeInAppNotificationForIntelAcknowledged Type = "in-app-notification-for-intel-acknowledged"
+
+// InAppNotificationForIntelAcknowledged is the value for
+// TypeInAppNotificationForIntelAcknowledged.
+type InAppNotificationForIntelAcknowledged struct {
+	// Attempt is the id of the associated intel-delivery-attempt.
+	Attempt uuid.UUID `json:"attempt"`
+	// AcknowledgedBy is the id of the user that acknowledged the notification.
+	AcknowledgedBy uuid.UUID `json:"acknowledged_by"`
+	// AcknowledgedAt is the timestamp when the notification was acknowledged.
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// TypeUserPresenceUpdated is used when a user connected for the first time or
+// when the last connection of a user was closed.
+const TypeUserPresenceUpdated Type = "user-presence-updated"
+
+// UserPresenceUpdated is the value for TypeUserPresenceUpdated.
+type UserPresenceUpdated struct {
+	// User is the id of the user.
+	User uuid.UUID `json:"user"`
+	// IsOnline describes whether the user currently has at least one open
+	// connection.
+	IsOnline bool `json:"is_online"`
+	// LastSeen is the timestamp of the presence change. If the user is online,
+	// this is when the user connected. Otherwise, this is when the last connection
+	// was closed.
+	LastSeen time.Time `json:"last_seen"`
+}
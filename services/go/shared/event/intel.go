@@ -34,6 +34,14 @@ type IntelCreated struct {
 	// IsValid describes whether the intel is still valid or marked as invalid
 	// (equals deletion).
 	IsValid bool `json:"is_valid"`
+	// PreviousVersion is the id of the intel, this one is an amended version of.
+	PreviousVersion uuid.NullUUID `json:"previous_version"`
+	// Version is the number in the version chain, starting with 1 for the
+	// original intel.
+	Version int `json:"version"`
+	// ValidUntil is the optional timestamp after which the intel expires. This is
+	// announced via TypeIntelExpired.
+	ValidUntil nulls.Time `json:"valid_until"`
 }
 
 // TypeIntelInvalidated for intel, that has been invalidated.
@@ -46,3 +54,31 @@ type IntelInvalidated struct {
 	// By is the id of the user that invalidated the intel.
 	By uuid.UUID `json:"by"`
 }
+
+// TypeIntelExpired for intel, that expired because of IntelCreated.ValidUntil
+// being reached. All active deliveries for the intel are canceled.
+const TypeIntelExpired Type = "intel-expired"
+
+// IntelExpired for TypeIntelExpired.
+type IntelExpired struct {
+	// ID identifies the intel.
+	ID uuid.UUID `json:"id"`
+}
+
+// TypeIntelAmended for intel, that has been amended by creating a new version
+// of it. The new version is announced via TypeIntelCreated and the amended one
+// is invalidated via TypeIntelInvalidated before.
+const TypeIntelAmended Type = "intel-amended"
+
+// IntelAmended for TypeIntelAmended.
+type IntelAmended struct {
+	// ID identifies the amended intel.
+	ID uuid.UUID `json:"id"`
+	// NewVersion is the id of the intel, that is the new version of the amended
+	// one.
+	NewVersion uuid.UUID `json:"new_version"`
+	// Version is the version number of NewVersion.
+	Version int `json:"version"`
+	// By is the id of the user that amended the intel.
+	By uuid.UUID `json:"by"`
+}
@@ -53,6 +53,9 @@ type IntelDeliveryCreated struct {
 	Success bool `json:"success"`
 	// Note contains optional human-readable information regarding the delivery.
 	Note nulls.String `json:"note"`
+	// DeliverAt is the optional timestamp, before which the delivery is only
+	// scheduled and no delivery attempts are made.
+	DeliverAt nulls.Time `json:"deliver_at"`
 }
 
 // TypeIntelDeliveryAttemptCreated when an attempt for intel-delivery for a
@@ -173,6 +176,9 @@ type IntelDeliveryAttemptStatusUpdated struct {
 	StatusTS time.Time `json:"status_ts"`
 	// Note contains optional human-readable information regarding the attempt.
 	Note nulls.String `json:"note"`
+	// AcknowledgedBy is the id of the user that acknowledged the delivered intel,
+	// if reported by the channel.
+	AcknowledgedBy uuid.NullUUID `json:"acknowledged_by"`
 }
 
 // TypeIntelDeliveryStatusUpdated for updated status of an intel-delivery. In
@@ -191,6 +197,19 @@ type IntelDeliveryStatusUpdated struct {
 	Note nulls.String `json:"note"`
 }
 
+// TypeIntelDeliveryDeliverAtUpdated for when the time to deliver a scheduled
+// intel-delivery at is updated.
+const TypeIntelDeliveryDeliverAtUpdated Type = "intel-delivery-deliver-at-updated"
+
+// IntelDeliveryDeliverAtUpdated for TypeIntelDeliveryDeliverAtUpdated.
+type IntelDeliveryDeliverAtUpdated struct {
+	// ID identifies the delivery.
+	ID uuid.UUID `json:"id"`
+	// DeliverAt is the new optional timestamp, before which the delivery is only
+	// scheduled. If not set, the delivery is not scheduled anymore.
+	DeliverAt nulls.Time `json:"deliver_at"`
+}
+
 // TypeAddressBookEntryAutoDeliveryUpdated for when auto intel delivery for an
 // address book entry is enabled/disabled.
 const TypeAddressBookEntryAutoDeliveryUpdated Type = "address-book-entry-auto-delivery-updated"
@@ -204,3 +223,44 @@ type AddressBookEntryAutoDeliveryUpdated struct {
 	// the address book entry with ID.
 	IsAutoDeliveryEnabled bool `json:"is_auto_delivery_enabled"`
 }
+
+// TypeIntelDeliveryEscalated for when an intel-delivery is escalated because of
+// an escalation rule of the operation.
+const TypeIntelDeliveryEscalated Type = "intel-delivery-escalated"
+
+// IntelDeliveryEscalationTrigger is the reason for an intel-delivery being
+// escalated.
+type IntelDeliveryEscalationTrigger string
+
+const (
+	// IntelDeliveryEscalationTriggerFailed for deliveries that failed because of no
+	// more channels to try.
+	IntelDeliveryEscalationTriggerFailed IntelDeliveryEscalationTrigger = "failed"
+	// IntelDeliveryEscalationTriggerOpenTimeout for deliveries that are still open
+	// after the timeout of the escalation rule.
+	IntelDeliveryEscalationTriggerOpenTimeout IntelDeliveryEscalationTrigger = "open-timeout"
+)
+
+// IntelDeliveryEscalated for TypeIntelDeliveryEscalated.
+type IntelDeliveryEscalated struct {
+	// ID identifies the escalation.
+	ID uuid.UUID `json:"id"`
+	// Delivery is the id of the escalated delivery.
+	Delivery uuid.UUID `json:"delivery"`
+	// Intel is the id of the intel being delivered.
+	Intel uuid.UUID `json:"intel"`
+	// Operation is the id of the operation the intel is assigned to.
+	Operation uuid.UUID `json:"operation"`
+	// Trigger is the reason for the escalation.
+	Trigger IntelDeliveryEscalationTrigger `json:"trigger"`
+	// EscalatedAt is the timestamp when the delivery was escalated.
+	EscalatedAt time.Time `json:"escalated_at"`
+	// EscalatedTo is the id of the delivery that was created to the fallback
+	// address book entry.
+	EscalatedTo uuid.NullUUID `json:"escalated_to"`
+	// EscalatedToEntry is the id of the fallback address book entry.
+	EscalatedToEntry uuid.NullUUID `json:"escalated_to_entry"`
+	// NotifyManagers describes whether users, that are allowed to manage
+	// intel-deliveries in the operation, should be notified.
+	NotifyManagers bool `json:"notify_managers"`
+}
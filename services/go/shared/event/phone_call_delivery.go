@@ -0,0 +1,85 @@
+package event
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"time"
+)
+
+// TypePhoneCallDeliveryReadyForPickup notifies that a phone call delivery for
+// an intel-delivery-attempt is ready for pickup by a user for delivery.
+const TypePhoneCallDeliveryReadyForPickup Type = "phone-call-delivery-ready-for-pickup"
+
+// PhoneCallDeliveryReadyForPickup is the content for
+// TypePhoneCallDeliveryReadyForPickup.
+type PhoneCallDeliveryReadyForPickup struct {
+	// Attempt identifies the intel-delivery-attempt.
+	Attempt uuid.UUID
+	// Intel is the id of the referenced intel.
+	Intel uuid.UUID
+	// IntelOperation is the operation the referenced intel is assigned to.
+	IntelOperation uuid.UUID
+	// IntelImportance is the importance of the referenced intel.
+	IntelImportance int
+	// AttemptAssignedTo is the id of the assigned address book entry.
+	AttemptAssignedTo uuid.UUID
+	// AttemptAssignedToLabel is the label of the assigned address book entry.
+	AttemptAssignedToLabel string
+	// Delivery is the id of the referenced delivery.
+	Delivery uuid.UUID
+	// Channel is the id of the channel to use for this attempt.
+	Channel uuid.UUID
+	// Note contains optional human-readable information regarding the phone call
+	// delivery.
+	Note string
+	// AttemptAcceptedAt is the timestamp when the intel-delivery-attempt was
+	// accepted by the phone-call-delivery-service.
+	AttemptAcceptedAt time.Time
+}
+
+// TypePhoneCallDeliveryPickedUp for a phone call delivery being picked up.
+const TypePhoneCallDeliveryPickedUp Type = "phone-call-delivery-picked-up"
+
+// PhoneCallDeliveryPickedUp is the content for TypePhoneCallDeliveryPickedUp.
+type PhoneCallDeliveryPickedUp struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// PickedUpBy is the id of the user that picked up the phone call delivery.
+	PickedUpBy uuid.UUID
+	// PickedUpAt is the timestamp when the phone call delivery was picked up.
+	PickedUpAt time.Time
+}
+
+// TypePhoneCallDeliveryReleased for picked up phone call deliveries being
+// released again and therefore ready for pickup.
+const TypePhoneCallDeliveryReleased Type = "phone-call-delivery-released"
+
+// PhoneCallDeliveryReleased is the content for TypePhoneCallDeliveryReleased.
+type PhoneCallDeliveryReleased struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// ReleasedAt is the timestamp when the phone call delivery was released.
+	ReleasedAt time.Time
+}
+
+// TypePhoneCallDeliveryFinished when a phone call delivery was
+// finished/canceled.
+const TypePhoneCallDeliveryFinished Type = "phone-call-delivery-finished"
+
+// PhoneCallDeliveryFinished is the content for TypePhoneCallDeliveryFinished.
+type PhoneCallDeliveryFinished struct {
+	// Attempt is the id of the referenced intel-delivery-attempt.
+	Attempt uuid.UUID
+	// PickedUpBy is the id of the user that picked up the phone call delivery.
+	PickedUpBy uuid.NullUUID
+	// PickedUpAt is the timestamp when the user from PickedUpBy picked up the
+	// phone call delivery.
+	PickedUpAt nulls.Time
+	// Success of the phone call delivery.
+	Success bool
+	// FinishedAt is the timestamp when the phone call delivery was marked as
+	// finished.
+	FinishedAt time.Time
+	// Note contains optional information.
+	Note string
+}
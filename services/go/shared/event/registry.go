@@ -0,0 +1,151 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/lefinal/meh"
+	"sort"
+)
+
+// Version is the schema version of an event value.
+type Version int
+
+// InitialVersion is the first schema version of each event value. Values,
+// being sent before versioning was introduced, are treated as having this
+// version.
+const InitialVersion Version = 1
+
+// Upcaster converts a raw event value from one schema version to the next one.
+type Upcaster func(raw json.RawMessage) (json.RawMessage, error)
+
+// schema holds registry information for a Type.
+type schema struct {
+	// version is the current version.
+	version Version
+	// newValue returns a pointer to a new value with the current version.
+	newValue func() any
+	// upcasters by the version they upcast from.
+	upcasters map[Version]Upcaster
+}
+
+// Registry maps a Type and its current Version to a Go type. It allows
+// upcasting raw values of older versions to the current one, so that consumers
+// only need to handle the current one.
+type Registry struct {
+	schemas map[Type]schema
+}
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: make(map[Type]schema),
+	}
+}
+
+// Register registers the given Type with its current Version and a function,
+// returning a pointer to a new value. It panics if the Type is already
+// registered or the Version is invalid, as this is a programming error.
+func (r *Registry) Register(eventType Type, version Version, newValue func() any) {
+	if _, ok := r.schemas[eventType]; ok {
+		panic(fmt.Sprintf("duplicate registration for event type %q", eventType))
+	}
+	if version < InitialVersion {
+		panic(fmt.Sprintf("invalid version %d for event type %q", version, eventType))
+	}
+	r.schemas[eventType] = schema{
+		version:   version,
+		newValue:  newValue,
+		upcasters: make(map[Version]Upcaster),
+	}
+}
+
+// RegisterUpcaster registers the given Upcaster for the Type, converting values
+// from the given Version to the next one. It panics if the Type is not
+// registered or the Version is not older than the current one, as this is a
+// programming error.
+func (r *Registry) RegisterUpcaster(eventType Type, from Version, upcaster Upcaster) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		panic(fmt.Sprintf("upcaster for unregistered event type %q", eventType))
+	}
+	if from < InitialVersion || from >= s.version {
+		panic(fmt.Sprintf("invalid upcaster version %d for event type %q with current version %d", from, eventType, s.version))
+	}
+	if _, ok := s.upcasters[from]; ok {
+		panic(fmt.Sprintf("duplicate upcaster from version %d for event type %q", from, eventType))
+	}
+	s.upcasters[from] = upcaster
+}
+
+// Version returns the current Version for the given Type. If the Type is not
+// registered, false is returned.
+func (r *Registry) Version(eventType Type) (Version, bool) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return 0, false
+	}
+	return s.version, true
+}
+
+// NewValue returns a pointer to a new value for the given Type with the current
+// Version. If the Type is not registered, false is returned.
+func (r *Registry) NewValue(eventType Type) (any, bool) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return nil, false
+	}
+	return s.newValue(), true
+}
+
+// Types returns all registered Type values in sorted order.
+func (r *Registry) Types() []Type {
+	types := make([]Type, 0, len(r.schemas))
+	for eventType := range r.schemas {
+		types = append(types, eventType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// Upcast converts the given raw value of the Type with the given Version to the
+// current one by applying all registered Upcaster in order. It returns the
+// upcasted value and its Version. Values of unregistered types are returned
+// as-is. Versions older than InitialVersion are treated as InitialVersion.
+// Values with a newer version than the current one are rejected, as they cannot
+// be handled.
+func (r *Registry) Upcast(eventType Type, version Version, raw json.RawMessage) (json.RawMessage, Version, error) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return raw, version, nil
+	}
+	if version < InitialVersion {
+		version = InitialVersion
+	}
+	if version > s.version {
+		return nil, 0, meh.NewInternalErr("version newer than current one", meh.Details{
+			"event_type":      eventType,
+			"version":         version,
+			"current_version": s.version,
+		})
+	}
+	for ; version < s.version; version++ {
+		upcaster, ok := s.upcasters[version]
+		if !ok {
+			return nil, 0, meh.NewInternalErr("missing upcaster", meh.Details{
+				"event_type": eventType,
+				"from":       version,
+			})
+		}
+		var err error
+		raw, err = upcaster(raw)
+		if err != nil {
+			return nil, 0, meh.NewInternalErrFromErr(err, "upcast", meh.Details{
+				"event_type": eventType,
+				"from":       version,
+			})
+		}
+	}
+	return raw, version, nil
+}
@@ -0,0 +1,169 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// TestSchemasRoundTrip assures that all events in Schemas survive a round trip
+// through JSON.
+func TestSchemasRoundTrip(t *testing.T) {
+	assert.NoError(t, CheckRoundTrip(Schemas))
+}
+
+type sampleEventV3 struct {
+	Name string `json:"name"`
+}
+
+type brokenSampleEvent struct {
+	Name string `json:"-"`
+}
+
+func TestCheckRoundTripBroken(t *testing.T) {
+	r := NewRegistry()
+	r.Register("broken", InitialVersion, func() any { return &brokenSampleEvent{} })
+	assert.Error(t, CheckRoundTrip(r), "should fail")
+}
+
+// registrySuite tests Registry.
+type registrySuite struct {
+	suite.Suite
+	r *Registry
+}
+
+func (suite *registrySuite) SetupTest() {
+	suite.r = NewRegistry()
+	suite.r.Register("sample", 3, func() any { return &sampleEventV3{} })
+	suite.r.RegisterUpcaster("sample", 1, func(raw json.RawMessage) (json.RawMessage, error) {
+		var v1 struct {
+			FirstName string `json:"first_name"`
+		}
+		err := json.Unmarshal(raw, &v1)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"full_name": v1.FirstName})
+	})
+	suite.r.RegisterUpcaster("sample", 2, func(raw json.RawMessage) (json.RawMessage, error) {
+		var v2 struct {
+			FullName string `json:"full_name"`
+		}
+		err := json.Unmarshal(raw, &v2)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(sampleEventV3{Name: v2.FullName})
+	})
+}
+
+func (suite *registrySuite) TestRegisterDuplicate() {
+	suite.Panics(func() {
+		suite.r.Register("sample", 1, func() any { return &sampleEventV3{} })
+	})
+}
+
+func (suite *registrySuite) TestRegisterInvalidVersion() {
+	suite.Panics(func() {
+		suite.r.Register("other", 0, func() any { return &sampleEventV3{} })
+	})
+}
+
+func (suite *registrySuite) TestRegisterUpcasterUnregistered() {
+	suite.Panics(func() {
+		suite.r.RegisterUpcaster("other", 1, nil)
+	})
+}
+
+func (suite *registrySuite) TestRegisterUpcasterFromCurrent() {
+	suite.Panics(func() {
+		suite.r.RegisterUpcaster("sample", 3, nil)
+	})
+}
+
+func (suite *registrySuite) TestRegisterUpcasterDuplicate() {
+	suite.Panics(func() {
+		suite.r.RegisterUpcaster("sample", 2, nil)
+	})
+}
+
+func (suite *registrySuite) TestVersion() {
+	version, ok := suite.r.Version("sample")
+	suite.True(ok, "should be registered")
+	suite.Equal(Version(3), version, "should return current version")
+	_, ok = suite.r.Version("other")
+	suite.False(ok, "should not be registered")
+}
+
+func (suite *registrySuite) TestNewValue() {
+	value, ok := suite.r.NewValue("sample")
+	suite.True(ok, "should be registered")
+	suite.IsType(&sampleEventV3{}, value, "should return pointer to new value")
+	_, ok = suite.r.NewValue("other")
+	suite.False(ok, "should not be registered")
+}
+
+func (suite *registrySuite) TestTypes() {
+	suite.r.Register("apple", InitialVersion, func() any { return &sampleEventV3{} })
+	suite.Equal([]Type{"apple", "sample"}, suite.r.Types())
+}
+
+func (suite *registrySuite) TestUpcastUnregistered() {
+	raw, version, err := suite.r.Upcast("other", 4, json.RawMessage(`{}`))
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(Version(4), version, "should keep version")
+	suite.Equal(json.RawMessage(`{}`), raw, "should not change value")
+}
+
+func (suite *registrySuite) TestUpcastNewer() {
+	_, _, err := suite.r.Upcast("sample", 4, json.RawMessage(`{}`))
+	suite.Error(err, "should fail")
+}
+
+func (suite *registrySuite) TestUpcastFail() {
+	_, _, err := suite.r.Upcast("sample", 2, json.RawMessage(`{`))
+	suite.Error(err, "should fail")
+}
+
+func (suite *registrySuite) TestUpcastMissingUpcaster() {
+	suite.r.Register("gap", 2, func() any { return &sampleEventV3{} })
+	_, _, err := suite.r.Upcast("gap", 1, json.RawMessage(`{}`))
+	suite.Error(err, "should fail")
+}
+
+func (suite *registrySuite) TestUpcastCurrent() {
+	raw, version, err := suite.r.Upcast("sample", 3, json.RawMessage(`{"name":"Alice"}`))
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(Version(3), version, "should return current version")
+	suite.JSONEq(`{"name":"Alice"}`, string(raw), "should not change value")
+}
+
+func (suite *registrySuite) TestUpcastChain() {
+	raw, version, err := suite.r.Upcast("sample", 1, json.RawMessage(`{"first_name":"Alice"}`))
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(Version(3), version, "should return current version")
+	suite.JSONEq(`{"name":"Alice"}`, string(raw), "should have upcasted value")
+}
+
+func (suite *registrySuite) TestUpcastUnversioned() {
+	raw, version, err := suite.r.Upcast("sample", 0, json.RawMessage(`{"first_name":"Alice"}`))
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(Version(3), version, "should return current version")
+	suite.JSONEq(`{"name":"Alice"}`, string(raw), "should treat as initial version")
+}
+
+func (suite *registrySuite) TestUpcasterError() {
+	r := NewRegistry()
+	r.Register("fail", 2, func() any { return &sampleEventV3{} })
+	r.RegisterUpcaster("fail", 1, func(_ json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("sad life")
+	})
+	_, _, err := r.Upcast("fail", 1, json.RawMessage(`{}`))
+	suite.Error(err, "should fail")
+}
+
+func TestRegistry(t *testing.T) {
+	suite.Run(t, new(registrySuite))
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"encoding/json"
+	"github.com/lefinal/meh"
+	"reflect"
+	"time"
+)
+
+// CheckRoundTrip is a test helper that verifies that values of all registered
+// types in the given Registry survive marshalling to and unmarshalling from
+// JSON unchanged. Each value is filled with sample data for all exported fields
+// beforehand, so that fields with missing or broken JSON tags are detected.
+func CheckRoundTrip(r *Registry) error {
+	for _, eventType := range r.Types() {
+		value, _ := r.NewValue(eventType)
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return meh.NewInternalErr("new value is no pointer", meh.Details{"event_type": eventType})
+		}
+		fillSample(v.Elem())
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return meh.NewInternalErrFromErr(err, "marshal value", meh.Details{"event_type": eventType})
+		}
+		unmarshalled, _ := r.NewValue(eventType)
+		err = json.Unmarshal(raw, unmarshalled)
+		if err != nil {
+			return meh.NewInternalErrFromErr(err, "unmarshal value", meh.Details{
+				"event_type": eventType,
+				"raw":        string(raw),
+			})
+		}
+		if !reflect.DeepEqual(value, unmarshalled) {
+			return meh.NewInternalErr("value changed after round trip", meh.Details{
+				"event_type":   eventType,
+				"raw":          string(raw),
+				"original":     value,
+				"unmarshalled": unmarshalled,
+			})
+		}
+	}
+	return nil
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// fillSample fills the given settable value with non-zero sample data. Slices
+// and maps get one entry. Interfaces and unexported fields are left untouched.
+func fillSample(v reflect.Value) {
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(time.Date(2022, 9, 1, 12, 13, 14, 0, time.UTC)))
+		return
+	case jsonRawMessageType:
+		v.Set(reflect.ValueOf(json.RawMessage(`{"hello":"world"}`)))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(7.5)
+	case reflect.String:
+		v.SetString("sample")
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillSample(v.Index(i))
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillSample(v.Index(0))
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		fillSample(key)
+		value := reflect.New(v.Type().Elem()).Elem()
+		fillSample(value)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, value)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillSample(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			fillSample(v.Field(i))
+		}
+	}
+}
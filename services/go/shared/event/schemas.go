@@ -0,0 +1,82 @@
+package event
+
+// Schemas is the Registry with all events. Consumers receive values of older
+// versions upcasted to the current one.
+var Schemas = NewRegistry()
+
+func init() {
+	// Address book.
+	Schemas.Register(TypeAddressBookEntryCreated, InitialVersion, func() any { return &AddressBookEntryCreated{} })
+	Schemas.Register(TypeAddressBookEntryUpdated, InitialVersion, func() any { return &AddressBookEntryUpdated{} })
+	Schemas.Register(TypeAddressBookEntryDeleted, InitialVersion, func() any { return &AddressBookEntryDeleted{} })
+	Schemas.Register(TypeAddressBookEntryChannelsUpdated, InitialVersion, func() any { return &AddressBookEntryChannelsUpdated{} })
+
+	// Auth.
+	Schemas.Register(TypeUserLoggedIn, InitialVersion, func() any { return &UserLoggedIn{} })
+	Schemas.Register(TypeUserLoggedOut, InitialVersion, func() any { return &UserLoggedOut{} })
+	Schemas.Register(TypeUserLoginFailed, InitialVersion, func() any { return &UserLoginFailed{} })
+	Schemas.Register(TypeSessionRevoked, InitialVersion, func() any { return &SessionRevoked{} })
+
+	// Direct delivery.
+	Schemas.Register(TypeDirectDeliveryReadyForPickup, InitialVersion, func() any { return &DirectDeliveryReadyForPickup{} })
+	Schemas.Register(TypeDirectDeliveryPickedUp, InitialVersion, func() any { return &DirectDeliveryPickedUp{} })
+	Schemas.Register(TypeDirectDeliveryReleased, InitialVersion, func() any { return &DirectDeliveryReleased{} })
+	Schemas.Register(TypeDirectDeliveryFinished, InitialVersion, func() any { return &DirectDeliveryFinished{} })
+
+	// Email delivery.
+	Schemas.Register(TypeEmailForIntelPending, InitialVersion, func() any { return &EmailForIntelPending{} })
+	Schemas.Register(TypeEmailForIntelSent, InitialVersion, func() any { return &EmailForIntelSent{} })
+	Schemas.Register(TypeEmailForIntelFailed, InitialVersion, func() any { return &EmailForIntelFailed{} })
+
+	// Groups.
+	Schemas.Register(TypeGroupCreated, InitialVersion, func() any { return &GroupCreated{} })
+	Schemas.Register(TypeGroupUpdated, InitialVersion, func() any { return &GroupUpdated{} })
+	Schemas.Register(TypeGroupDeleted, InitialVersion, func() any { return &GroupDeleted{} })
+
+	// In-app notifications.
+	Schemas.Register(TypeInAppNotificationForIntelPending, InitialVersion, func() any { return &InAppNotificationForIntelPending{} })
+	Schemas.Register(TypeInAppNotificationForIntelSent, InitialVersion, func() any { return &InAppNotificationForIntelSent{} })
+	Schemas.Register(TypeInAppNotificationForIntelReceived, InitialVersion, func() any { return &InAppNotificationForIntelReceived{} })
+	Schemas.Register(TypeInAppNotificationForIntelAcknowledged, InitialVersion, func() any { return &InAppNotificationForIntelAcknowledged{} })
+	Schemas.Register(TypeUserPresenceUpdated, InitialVersion, func() any { return &UserPresenceUpdated{} })
+
+	// Intel.
+	Schemas.Register(TypeIntelCreated, InitialVersion, func() any { return &IntelCreated{} })
+	Schemas.Register(TypeIntelInvalidated, InitialVersion, func() any { return &IntelInvalidated{} })
+	Schemas.Register(TypeIntelExpired, InitialVersion, func() any { return &IntelExpired{} })
+	Schemas.Register(TypeIntelAmended, InitialVersion, func() any { return &IntelAmended{} })
+
+	// Intel delivery.
+	Schemas.Register(TypeIntelDeliveryCreated, InitialVersion, func() any { return &IntelDeliveryCreated{} })
+	Schemas.Register(TypeIntelDeliveryAttemptCreated, InitialVersion, func() any { return &IntelDeliveryAttemptCreated{} })
+	Schemas.Register(TypeIntelDeliveryAttemptStatusUpdated, InitialVersion, func() any { return &IntelDeliveryAttemptStatusUpdated{} })
+	Schemas.Register(TypeIntelDeliveryStatusUpdated, InitialVersion, func() any { return &IntelDeliveryStatusUpdated{} })
+	Schemas.Register(TypeIntelDeliveryDeliverAtUpdated, InitialVersion, func() any { return &IntelDeliveryDeliverAtUpdated{} })
+	Schemas.Register(TypeAddressBookEntryAutoDeliveryUpdated, InitialVersion, func() any { return &AddressBookEntryAutoDeliveryUpdated{} })
+	Schemas.Register(TypeIntelDeliveryEscalated, InitialVersion, func() any { return &IntelDeliveryEscalated{} })
+
+	// Operations.
+	Schemas.Register(TypeOperationCreated, InitialVersion, func() any { return &OperationCreated{} })
+	Schemas.Register(TypeOperationUpdated, InitialVersion, func() any { return &OperationUpdated{} })
+	Schemas.Register(TypeOperationMembersUpdated, InitialVersion, func() any { return &OperationMembersUpdated{} })
+
+	// Permissions.
+	Schemas.Register(TypePermissionsUpdated, InitialVersion, func() any { return &PermissionsUpdated{} })
+
+	// Phone call delivery.
+	Schemas.Register(TypePhoneCallDeliveryReadyForPickup, InitialVersion, func() any { return &PhoneCallDeliveryReadyForPickup{} })
+	Schemas.Register(TypePhoneCallDeliveryPickedUp, InitialVersion, func() any { return &PhoneCallDeliveryPickedUp{} })
+	Schemas.Register(TypePhoneCallDeliveryReleased, InitialVersion, func() any { return &PhoneCallDeliveryReleased{} })
+	Schemas.Register(TypePhoneCallDeliveryFinished, InitialVersion, func() any { return &PhoneCallDeliveryFinished{} })
+
+	// Radio delivery.
+	Schemas.Register(TypeRadioDeliveryReadyForPickup, InitialVersion, func() any { return &RadioDeliveryReadyForPickup{} })
+	Schemas.Register(TypeRadioDeliveryPickedUp, InitialVersion, func() any { return &RadioDeliveryPickedUp{} })
+	Schemas.Register(TypeRadioDeliveryReleased, InitialVersion, func() any { return &RadioDeliveryReleased{} })
+	Schemas.Register(TypeRadioDeliveryFinished, InitialVersion, func() any { return &RadioDeliveryFinished{} })
+
+	// Users.
+	Schemas.Register(TypeUserCreated, InitialVersion, func() any { return &UserCreated{} })
+	Schemas.Register(TypeUserUpdated, InitialVersion, func() any { return &UserUpdated{} })
+	Schemas.Register(TypeUserPassUpdated, InitialVersion, func() any { return &UserPassUpdated{} })
+}
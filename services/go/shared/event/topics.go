@@ -9,6 +9,10 @@ const (
 	AddressBookTopic Topic = "logistics.address-book.0"
 	// AuthTopic is the Kafka topic for all events related to authentication.
 	AuthTopic Topic = "core.auth.0"
+	// DirectDeliveriesTopic is the Kafka topic for delivering intel in person.
+	DirectDeliveriesTopic Topic = "delivery.direct.0"
+	// EmailDeliveriesTopic is the Kafka topic for delivering intel via email.
+	EmailDeliveriesTopic Topic = "delivery.email.0"
 	// GroupsTopic is the Kafka topic for groups.
 	GroupsTopic Topic = "orga.groups.0"
 	// InAppNotificationsTopic is the Kafka topic for in-app-notifications.
@@ -22,8 +26,14 @@ const (
 	OperationsTopic Topic = "operations.operations.0"
 	// PermissionsTopic is the Kafka topic for all events related to permissions.
 	PermissionsTopic Topic = "core.permissions.0"
+	// PhoneCallDeliveriesTopic is the Kafka topic for delivering intel via phone
+	// call.
+	PhoneCallDeliveriesTopic Topic = "delivery.phone-call.0"
 	// RadioDeliveriesTopic is the Kafka topic for delivering intel over radio.
 	RadioDeliveriesTopic Topic = "delivery.radio.0"
+	// UserPresenceTopic is the Kafka topic for user presence, meaning whether
+	// users are currently connected.
+	UserPresenceTopic Topic = "notifications.user-presence.0"
 	// UsersTopic is the Kafka topic to write user events to.
 	UsersTopic Topic = "core.users.0"
 )
@@ -0,0 +1,23 @@
+-- Track processing failures for inbox messages.
+
+alter table __message_inbox
+    add column attempts int not null default 0;
+
+alter table __message_inbox
+    add column last_error text;
+
+alter table __message_inbox
+    add column last_error_ts timestamp;
+
+-- Only pending messages are considered for processing. Dead-lettered and skipped
+-- ones must not be included anymore.
+
+drop index __message_inbox_status_ix;
+
+create index __message_inbox_pending_ix on __message_inbox (status)
+    where status = 0;
+
+-- Create index for dead-lettered messages.
+
+create index __message_inbox_dead_letter_ix on __message_inbox (id)
+    where status = 500;
@@ -0,0 +1,7 @@
+-- Create indices for deleting handled messages that exceeded their retention.
+
+create index __message_inbox_status_ts_ix on __message_inbox (status, status_ts)
+    where status != 0;
+
+create index __message_outbox_status_ts_ix on __message_outbox (status, status_ts)
+    where status = 200;
@@ -0,0 +1,25 @@
+-- Remove duplicates, that were added because of Kafka redelivering messages.
+-- We keep the oldest one.
+
+delete
+from __message_inbox duplicate
+    using __message_inbox original
+where duplicate.topic = original.topic
+  and duplicate.partition = original.partition
+  and duplicate."offset" = original."offset"
+  and duplicate.id > original.id;
+
+-- Enforce idempotency for Kafka messages.
+
+create unique index __message_inbox_topic_partition_offset_ux on __message_inbox (topic, partition, "offset");
+
+-- Add event ids, assigned by the outbox of the sending service.
+
+alter table __message_outbox
+    add column event_id uuid not null default uuid_generate_v4();
+
+alter table __message_inbox
+    add column event_id uuid;
+
+create unique index __message_inbox_event_id_ux on __message_inbox (event_id)
+    where event_id is not null;
@@ -0,0 +1,5 @@
+-- Create index for retrieving pending messages per segment when claiming
+-- batches.
+
+create index __message_outbox_segment_ix on __message_outbox (topic, key, id)
+    where status != 200;
@@ -0,0 +1,6 @@
+-- Back off exponentially when retrying failed inbox messages.
+
+alter table __message_inbox
+    add column next_attempt_at timestamp;
+
+comment on column __message_inbox.next_attempt_at is 'The timestamp before which a failed message is not retried.';
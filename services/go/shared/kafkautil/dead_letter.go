@@ -0,0 +1,296 @@
+package kafkautil
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"time"
+)
+
+// DeadLetter is an inbox message that failed to process too often and was
+// therefore moved to the dead letters.
+type DeadLetter struct {
+	// ID identifies the message in the inbox.
+	ID int
+	// Topic the message was consumed from.
+	Topic event.Topic
+	// The partition from Kafka.
+	Partition int
+	// Offset is the message offset from Kafka.
+	Offset int
+	// HighWaterMark from Kafka.
+	HighWaterMark int
+	// TS is the timestamp from Kafka.
+	TS time.Time
+	// Key is message key.
+	Key string
+	// EventType is the type of event.
+	EventType event.Type
+	// RawValue is the raw message value. It is not guaranteed to be valid JSON.
+	RawValue string
+	// Headers for the message.
+	Headers []MessageHeader
+	// Attempts is the number of failed processing attempts.
+	Attempts int
+	// LastError is the error message of the last failed processing attempt.
+	LastError nulls.String
+	// LastErrorTS is the timestamp of the last failed processing attempt.
+	LastErrorTS nulls.Time
+	// DeadLetteredAt is the timestamp when the message was moved to the dead
+	// letters.
+	DeadLetteredAt time.Time
+}
+
+// DeadLetterAdmin allows managing dead-lettered inbox messages.
+type DeadLetterAdmin interface {
+	// DeadLetters retrieves a paginated DeadLetter list.
+	DeadLetters(ctx context.Context, page pagination.Params) (pagination.Paginated[DeadLetter], error)
+	// DeadLetterByID retrieves the DeadLetter with the given id.
+	DeadLetterByID(ctx context.Context, messageID int) (DeadLetter, error)
+	// RetryDeadLetter resets the attempts of the DeadLetter with the given id and
+	// marks it as pending again, so that it is processed like any other message.
+	RetryDeadLetter(ctx context.Context, messageID int) error
+	// SkipDeadLetter marks the DeadLetter with the given id as skipped. It will
+	// never be processed.
+	SkipDeadLetter(ctx context.Context, messageID int) error
+}
+
+// deadLetterAdmin is the implementation of DeadLetterAdmin.
+type deadLetterAdmin struct {
+	instanceID uuid.UUID
+	store      store
+	txSupplier pgutil.DBTxSupplier
+}
+
+// DeadLetterAdmin returns a DeadLetterAdmin that uses the store of the
+// connector.
+func (c *connector) DeadLetterAdmin(txSupplier pgutil.DBTxSupplier) DeadLetterAdmin {
+	return &deadLetterAdmin{
+		instanceID: c.id,
+		store:      c.store,
+		txSupplier: txSupplier,
+	}
+}
+
+// DeadLetters retrieves a paginated DeadLetter list.
+func (a *deadLetterAdmin) DeadLetters(ctx context.Context, page pagination.Params) (pagination.Paginated[DeadLetter], error) {
+	var deadLetters pagination.Paginated[DeadLetter]
+	err := pgutil.RunInTx(ctx, a.txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		deadLetters, err = a.store.deadLetters(ctx, tx, page)
+		if err != nil {
+			return meh.Wrap(err, "dead letters from store", meh.Details{"page": page})
+		}
+		return nil
+	})
+	if err != nil {
+		return pagination.Paginated[DeadLetter]{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return deadLetters, nil
+}
+
+// DeadLetterByID retrieves the DeadLetter with the given id.
+func (a *deadLetterAdmin) DeadLetterByID(ctx context.Context, messageID int) (DeadLetter, error) {
+	var deadLetter DeadLetter
+	err := pgutil.RunInTx(ctx, a.txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		deadLetter, err = a.store.deadLetterByID(ctx, tx, messageID)
+		if err != nil {
+			return meh.Wrap(err, "dead letter by id from store", meh.Details{"message_id": messageID})
+		}
+		return nil
+	})
+	if err != nil {
+		return DeadLetter{}, meh.Wrap(err, "run in tx", nil)
+	}
+	return deadLetter, nil
+}
+
+// RetryDeadLetter marks the DeadLetter with the given id as pending and resets
+// its attempts.
+func (a *deadLetterAdmin) RetryDeadLetter(ctx context.Context, messageID int) error {
+	err := pgutil.RunInTx(ctx, a.txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		err := a.store.setDeadLetterStatus(ctx, tx, a.instanceID, messageID, inboxMessageStatusPending, true)
+		if err != nil {
+			return meh.Wrap(err, "set dead letter status to pending in store", meh.Details{"message_id": messageID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// SkipDeadLetter marks the DeadLetter with the given id as skipped.
+func (a *deadLetterAdmin) SkipDeadLetter(ctx context.Context, messageID int) error {
+	err := pgutil.RunInTx(ctx, a.txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		err := a.store.setDeadLetterStatus(ctx, tx, a.instanceID, messageID, inboxMessageStatusSkipped, false)
+		if err != nil {
+			return meh.Wrap(err, "set dead letter status to skipped in store", meh.Details{"message_id": messageID})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	return nil
+}
+
+// deadLetterSelect is the select-query for DeadLetter. Use scanDeadLetter for
+// scanning.
+func (s *dbStore) deadLetterSelect() *goqu.SelectDataset {
+	return s.dialect.From(goqu.T("__message_inbox")).
+		Select(goqu.C("id"),
+			goqu.C("topic"),
+			goqu.C("partition"),
+			goqu.C("offset"),
+			goqu.C("ts"),
+			goqu.C("high_water_mark"),
+			goqu.C("key"),
+			goqu.C("value"),
+			goqu.C("event_type"),
+			goqu.C("header_keys"),
+			goqu.C("header_values"),
+			goqu.C("attempts"),
+			goqu.C("last_error"),
+			goqu.C("last_error_ts"),
+			goqu.C("status_ts")).
+		Where(goqu.C("status").Eq(inboxMessageStatusDeadLettered))
+}
+
+// scanDeadLetter scans a DeadLetter, selected via deadLetterSelect, with
+// optional additional destinations.
+func scanDeadLetter(rows pgx.Rows, deadLetter *DeadLetter, additionalDest ...any) error {
+	var headerKeys []string
+	var headerValues []string
+	dest := []any{
+		&deadLetter.ID,
+		&deadLetter.Topic,
+		&deadLetter.Partition,
+		&deadLetter.Offset,
+		&deadLetter.TS,
+		&deadLetter.HighWaterMark,
+		&deadLetter.Key,
+		&deadLetter.RawValue,
+		&deadLetter.EventType,
+		&headerKeys,
+		&headerValues,
+		&deadLetter.Attempts,
+		&deadLetter.LastError,
+		&deadLetter.LastErrorTS,
+		&deadLetter.DeadLetteredAt,
+	}
+	err := rows.Scan(append(dest, additionalDest...)...)
+	if err != nil {
+		return err
+	}
+	if len(headerKeys) != len(headerValues) {
+		return meh.NewInternalErr("list length mismatch for header keys and values", meh.Details{
+			"message_header_keys":   headerKeys,
+			"message_header_values": headerValues,
+		})
+	}
+	deadLetter.Headers = make([]MessageHeader, 0, len(headerKeys))
+	for i := range headerKeys {
+		deadLetter.Headers = append(deadLetter.Headers, MessageHeader{
+			Key:   headerKeys[i],
+			Value: headerValues[i],
+		})
+	}
+	return nil
+}
+
+// deadLetters retrieves a paginated DeadLetter list from the inbox table in the
+// database, sorted ascending by id if not specified otherwise.
+func (s *dbStore) deadLetters(ctx context.Context, tx pgx.Tx, page pagination.Params) (pagination.Paginated[DeadLetter], error) {
+	qb := s.deadLetterSelect().Order(goqu.C("id").Asc())
+	q, _, err := pagination.QueryToSQLWithPagination(qb, page, pagination.FieldMap{
+		"id":               goqu.C("id"),
+		"topic":            goqu.C("topic"),
+		"attempts":         goqu.C("attempts"),
+		"dead_lettered_at": goqu.C("status_ts"),
+	})
+	if err != nil {
+		return pagination.Paginated[DeadLetter]{}, meh.Wrap(err, "query to sql with pagination", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return pagination.Paginated[DeadLetter]{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	deadLetters := make([]DeadLetter, 0)
+	var total int
+	for rows.Next() {
+		var deadLetter DeadLetter
+		err = scanDeadLetter(rows, &deadLetter, &total)
+		if err != nil {
+			return pagination.Paginated[DeadLetter]{}, mehpg.NewScanRowsErr(err, "scan row", q)
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	rows.Close()
+	return pagination.NewPaginated(page, deadLetters, total), nil
+}
+
+// deadLetterByID retrieves the DeadLetter with the given id from the inbox table
+// in the database.
+func (s *dbStore) deadLetterByID(ctx context.Context, tx pgx.Tx, messageID int) (DeadLetter, error) {
+	q, _, err := s.deadLetterSelect().Where(goqu.C("id").Eq(messageID)).ToSQL()
+	if err != nil {
+		return DeadLetter{}, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return DeadLetter{}, mehpg.NewQueryDBErr(err, "query db", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return DeadLetter{}, meh.NewNotFoundErr("not found", meh.Details{"query": q})
+	}
+	var deadLetter DeadLetter
+	err = scanDeadLetter(rows, &deadLetter)
+	if err != nil {
+		return DeadLetter{}, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return deadLetter, nil
+}
+
+// setDeadLetterStatus updates the status and update timestamp for the given
+// message in the inbox table in the database, having
+// inboxMessageStatusDeadLettered. If resetAttempts is set, the attempts are set
+// to zero and the backoff is reset.
+func (s *dbStore) setDeadLetterStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int,
+	status inboxMessageStatus, resetAttempts bool) error {
+	record := goqu.Record{
+		"status":    status,
+		"status_ts": time.Now().UTC(),
+		"status_by": instanceID,
+	}
+	if resetAttempts {
+		record["attempts"] = 0
+		record["next_attempt_at"] = nil
+	}
+	q, _, err := s.dialect.Update(goqu.T("__message_inbox")).Set(record).
+		Where(goqu.C("status").Eq(inboxMessageStatusDeadLettered),
+			goqu.C("id").Eq(messageID)).ToSQL()
+	if err != nil {
+		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	if result.RowsAffected() == 0 {
+		return meh.NewNotFoundErr("dead letter not found", meh.Details{"query": q})
+	}
+	return nil
+}
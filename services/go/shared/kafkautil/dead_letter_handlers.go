@@ -0,0 +1,210 @@
+package kafkautil
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/httpendpoints"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PopulateDeadLetterRoutes adds the admin routes for managing dead-lettered
+// inbox messages via the given DeadLetterAdmin to the given gin.IRouter. All
+// routes require the requesting user to be an admin.
+func PopulateDeadLetterRoutes(r gin.IRouter, logger *zap.Logger, secret string, admin DeadLetterAdmin) {
+	r.GET("/kafka-inbox/dead-letters", httpendpoints.GinHandlerFunc(logger, secret, handleGetDeadLetters(admin)))
+	r.GET("/kafka-inbox/dead-letters/:messageID", httpendpoints.GinHandlerFunc(logger, secret, handleGetDeadLetterByID(admin)))
+	r.POST("/kafka-inbox/dead-letters/:messageID/retry", httpendpoints.GinHandlerFunc(logger, secret, handleRetryDeadLetter(admin)))
+	r.POST("/kafka-inbox/dead-letters/:messageID/skip", httpendpoints.GinHandlerFunc(logger, secret, handleSkipDeadLetter(admin)))
+}
+
+// assureAdmin returns an error if the given auth.Token is not authenticated or
+// does not belong to an admin.
+func assureAdmin(token auth.Token) error {
+	if !token.IsAuthenticated {
+		return meh.NewUnauthorizedErr("not authenticated", nil)
+	}
+	if !token.IsAdmin {
+		return meh.NewForbiddenErr("admin required", nil)
+	}
+	return nil
+}
+
+// messageIDFromRequest extracts the message id from the path parameter
+// messageID.
+func messageIDFromRequest(c *gin.Context) (int, error) {
+	messageIDStr := c.Param("messageID")
+	messageID, err := strconv.Atoi(messageIDStr)
+	if err != nil {
+		return 0, meh.NewBadInputErrFromErr(err, "parse message id", meh.Details{"was": messageIDStr})
+	}
+	return messageID, nil
+}
+
+// publicDeadLetter is the public representation of DeadLetter.
+type publicDeadLetter struct {
+	ID             int                   `json:"id"`
+	Topic          string                `json:"topic"`
+	Partition      int                   `json:"partition"`
+	Offset         int                   `json:"offset"`
+	TS             time.Time             `json:"ts"`
+	Key            string                `json:"key"`
+	EventType      string                `json:"event_type"`
+	Value          string                `json:"value"`
+	Headers        []publicMessageHeader `json:"headers"`
+	Attempts       int                   `json:"attempts"`
+	LastError      nulls.String          `json:"last_error"`
+	LastErrorTS    nulls.Time            `json:"last_error_ts"`
+	DeadLetteredAt time.Time             `json:"dead_lettered_at"`
+}
+
+// publicMessageHeader is the public representation of MessageHeader.
+type publicMessageHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// publicDeadLetterFromDeadLetter converts DeadLetter to publicDeadLetter.
+func publicDeadLetterFromDeadLetter(d DeadLetter) publicDeadLetter {
+	headers := make([]publicMessageHeader, 0, len(d.Headers))
+	for _, header := range d.Headers {
+		headers = append(headers, publicMessageHeader{
+			Key:   header.Key,
+			Value: header.Value,
+		})
+	}
+	return publicDeadLetter{
+		ID:             d.ID,
+		Topic:          string(d.Topic),
+		Partition:      d.Partition,
+		Offset:         d.Offset,
+		TS:             d.TS,
+		Key:            d.Key,
+		EventType:      string(d.EventType),
+		Value:          d.RawValue,
+		Headers:        headers,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		LastErrorTS:    d.LastErrorTS,
+		DeadLetteredAt: d.DeadLetteredAt,
+	}
+}
+
+// handleGetDeadLettersStore are the dependencies needed for
+// handleGetDeadLetters.
+type handleGetDeadLettersStore interface {
+	DeadLetters(ctx context.Context, page pagination.Params) (pagination.Paginated[DeadLetter], error)
+}
+
+// handleGetDeadLetters retrieves a paginated list of dead-lettered inbox
+// messages.
+func handleGetDeadLetters(s handleGetDeadLettersStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		err := assureAdmin(token)
+		if err != nil {
+			return meh.Wrap(err, "assure admin", nil)
+		}
+		// Parse pagination params.
+		paginationParams, err := pagination.ParamsFromRequest(c)
+		if err != nil {
+			return meh.Wrap(err, "pagination params from request", nil)
+		}
+		// Retrieve.
+		deadLetters, err := s.DeadLetters(c.Request.Context(), paginationParams)
+		if err != nil {
+			return meh.Wrap(err, "dead letters", meh.Details{"params": paginationParams})
+		}
+		c.JSON(http.StatusOK, pagination.MapPaginated(deadLetters, publicDeadLetterFromDeadLetter))
+		return nil
+	}
+}
+
+// handleGetDeadLetterByIDStore are the dependencies needed for
+// handleGetDeadLetterByID.
+type handleGetDeadLetterByIDStore interface {
+	DeadLetterByID(ctx context.Context, messageID int) (DeadLetter, error)
+}
+
+// handleGetDeadLetterByID retrieves the dead-lettered inbox message with the
+// given id.
+func handleGetDeadLetterByID(s handleGetDeadLetterByIDStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		err := assureAdmin(token)
+		if err != nil {
+			return meh.Wrap(err, "assure admin", nil)
+		}
+		messageID, err := messageIDFromRequest(c)
+		if err != nil {
+			return meh.Wrap(err, "message id from request", nil)
+		}
+		// Retrieve.
+		deadLetter, err := s.DeadLetterByID(c.Request.Context(), messageID)
+		if err != nil {
+			return meh.Wrap(err, "dead letter by id", meh.Details{"message_id": messageID})
+		}
+		c.JSON(http.StatusOK, publicDeadLetterFromDeadLetter(deadLetter))
+		return nil
+	}
+}
+
+// handleRetryDeadLetterStore are the dependencies needed for
+// handleRetryDeadLetter.
+type handleRetryDeadLetterStore interface {
+	RetryDeadLetter(ctx context.Context, messageID int) error
+}
+
+// handleRetryDeadLetter marks the dead-lettered inbox message with the given id
+// as pending again.
+func handleRetryDeadLetter(s handleRetryDeadLetterStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		err := assureAdmin(token)
+		if err != nil {
+			return meh.Wrap(err, "assure admin", nil)
+		}
+		messageID, err := messageIDFromRequest(c)
+		if err != nil {
+			return meh.Wrap(err, "message id from request", nil)
+		}
+		// Retry.
+		err = s.RetryDeadLetter(c.Request.Context(), messageID)
+		if err != nil {
+			return meh.Wrap(err, "retry dead letter", meh.Details{"message_id": messageID})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
+
+// handleSkipDeadLetterStore are the dependencies needed for
+// handleSkipDeadLetter.
+type handleSkipDeadLetterStore interface {
+	SkipDeadLetter(ctx context.Context, messageID int) error
+}
+
+// handleSkipDeadLetter marks the dead-lettered inbox message with the given id
+// as skipped.
+func handleSkipDeadLetter(s handleSkipDeadLetterStore) httpendpoints.HandlerFunc {
+	return func(c *gin.Context, token auth.Token) error {
+		err := assureAdmin(token)
+		if err != nil {
+			return meh.Wrap(err, "assure admin", nil)
+		}
+		messageID, err := messageIDFromRequest(c)
+		if err != nil {
+			return meh.Wrap(err, "message id from request", nil)
+		}
+		// Skip.
+		err = s.SkipDeadLetter(c.Request.Context(), messageID)
+		if err != nil {
+			return meh.Wrap(err, "skip dead letter", meh.Details{"message_id": messageID})
+		}
+		c.Status(http.StatusOK)
+		return nil
+	}
+}
@@ -0,0 +1,258 @@
+package kafkautil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/auth"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// deadLetterHandlersSuite tests the handlers, added via
+// PopulateDeadLetterRoutes.
+type deadLetterHandlersSuite struct {
+	suite.Suite
+	admin            *DeadLetterAdminMock
+	r                *gin.Engine
+	sampleToken      auth.Token
+	sampleDeadLetter DeadLetter
+}
+
+func (suite *deadLetterHandlersSuite) SetupTest() {
+	suite.admin = &DeadLetterAdminMock{}
+	suite.r = testutil.NewGinEngine()
+	PopulateDeadLetterRoutes(suite.r, zap.NewNop(), "", suite.admin)
+	suite.sampleToken = auth.Token{
+		UserID:          testutil.NewUUIDV4(),
+		Username:        "plate",
+		IsAuthenticated: true,
+		IsAdmin:         true,
+	}
+	suite.sampleDeadLetter = DeadLetter{
+		ID:             54,
+		Topic:          "shirt",
+		Partition:      2,
+		Offset:         841,
+		HighWaterMark:  900,
+		TS:             time.Date(2022, 8, 15, 17, 13, 27, 0, time.UTC),
+		Key:            "tidy",
+		EventType:      "meet",
+		RawValue:       `{"hello":`,
+		Headers:        []MessageHeader{{Key: "event-type", Value: "meet"}},
+		Attempts:       16,
+		LastError:      nulls.NewString("parse failed"),
+		LastErrorTS:    nulls.NewTime(time.Date(2022, 8, 15, 17, 20, 0, 0, time.UTC)),
+		DeadLetteredAt: time.Date(2022, 8, 15, 17, 20, 0, 0, time.UTC),
+	}
+}
+
+// requests returns one request per route for testing common behavior.
+func (suite *deadLetterHandlersSuite) requests() []testutil.HTTPRequestProps {
+	return []testutil.HTTPRequestProps{
+		{Method: http.MethodGet, URL: "/kafka-inbox/dead-letters"},
+		{Method: http.MethodGet, URL: fmt.Sprintf("/kafka-inbox/dead-letters/%d", suite.sampleDeadLetter.ID)},
+		{Method: http.MethodPost, URL: fmt.Sprintf("/kafka-inbox/dead-letters/%d/retry", suite.sampleDeadLetter.ID)},
+		{Method: http.MethodPost, URL: fmt.Sprintf("/kafka-inbox/dead-letters/%d/skip", suite.sampleDeadLetter.ID)},
+	}
+}
+
+func (suite *deadLetterHandlersSuite) TestSecretMismatch() {
+	for _, props := range suite.requests() {
+		props.Server = suite.r
+		props.Token = suite.sampleToken
+		props.Secret = "meow"
+		rr := testutil.DoHTTPRequestMust(props)
+		suite.Equalf(http.StatusInternalServerError, rr.Code, "should return correct code for %s", props.URL)
+	}
+}
+
+func (suite *deadLetterHandlersSuite) TestNotAuthenticated() {
+	suite.sampleToken.IsAuthenticated = false
+	for _, props := range suite.requests() {
+		props.Server = suite.r
+		props.Token = suite.sampleToken
+		rr := testutil.DoHTTPRequestMust(props)
+		suite.Equalf(http.StatusUnauthorized, rr.Code, "should return correct code for %s", props.URL)
+	}
+}
+
+func (suite *deadLetterHandlersSuite) TestNotAdmin() {
+	suite.sampleToken.IsAdmin = false
+	for _, props := range suite.requests() {
+		props.Server = suite.r
+		props.Token = suite.sampleToken
+		rr := testutil.DoHTTPRequestMust(props)
+		suite.Equalf(http.StatusForbidden, rr.Code, "should return correct code for %s", props.URL)
+	}
+}
+
+func (suite *deadLetterHandlersSuite) TestInvalidMessageID() {
+	requests := []testutil.HTTPRequestProps{
+		{Method: http.MethodGet, URL: "/kafka-inbox/dead-letters/abc"},
+		{Method: http.MethodPost, URL: "/kafka-inbox/dead-letters/abc/retry"},
+		{Method: http.MethodPost, URL: "/kafka-inbox/dead-letters/abc/skip"},
+	}
+	for _, props := range requests {
+		props.Server = suite.r
+		props.Token = suite.sampleToken
+		rr := testutil.DoHTTPRequestMust(props)
+		suite.Equalf(http.StatusBadRequest, rr.Code, "should return correct code for %s", props.URL)
+	}
+}
+
+func (suite *deadLetterHandlersSuite) TestGetDeadLettersInvalidPaginationParams() {
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/kafka-inbox/dead-letters?limit=abc",
+		Token:  suite.sampleToken,
+	})
+	suite.Equal(http.StatusBadRequest, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestGetDeadLettersFail() {
+	suite.admin.On("DeadLetters", mock.Anything, mock.Anything).
+		Return(pagination.Paginated[DeadLetter]{}, errors.New("sad life")).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/kafka-inbox/dead-letters",
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestGetDeadLettersOK() {
+	page := pagination.Params{
+		Limit:          3,
+		Offset:         1,
+		OrderBy:        nulls.NewString("dead_lettered_at"),
+		OrderDirection: pagination.OrderDirDesc,
+	}
+	suite.admin.On("DeadLetters", mock.Anything, page).
+		Return(pagination.NewPaginated(page, []DeadLetter{suite.sampleDeadLetter}, 7), nil).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    "/kafka-inbox/dead-letters?" + pagination.ParamsToQueryString(page),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got pagination.Paginated[publicDeadLetter]
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(pagination.NewPaginated(page, []publicDeadLetter{publicDeadLetterFromDeadLetter(suite.sampleDeadLetter)}, 7),
+		got, "should return correct body")
+}
+
+func (suite *deadLetterHandlersSuite) TestGetDeadLetterByIDNotFound() {
+	suite.admin.On("DeadLetterByID", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(DeadLetter{}, meh.NewNotFoundErr("sad life", nil)).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusNotFound, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestGetDeadLetterByIDOK() {
+	suite.admin.On("DeadLetterByID", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(suite.sampleDeadLetter, nil).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Require().Equal(http.StatusOK, rr.Code, "should return correct code")
+	var got publicDeadLetter
+	suite.Require().NoError(json.NewDecoder(rr.Body).Decode(&got), "should return valid body")
+	suite.Equal(publicDeadLetterFromDeadLetter(suite.sampleDeadLetter), got, "should return correct body")
+}
+
+func (suite *deadLetterHandlersSuite) TestRetryFail() {
+	suite.admin.On("RetryDeadLetter", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(errors.New("sad life")).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d/retry", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestRetryOK() {
+	suite.admin.On("RetryDeadLetter", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(nil).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d/retry", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestSkipFail() {
+	suite.admin.On("SkipDeadLetter", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(errors.New("sad life")).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d/skip", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusInternalServerError, rr.Code, "should return correct code")
+}
+
+func (suite *deadLetterHandlersSuite) TestSkipOK() {
+	suite.admin.On("SkipDeadLetter", mock.Anything, suite.sampleDeadLetter.ID).
+		Return(nil).Once()
+	defer suite.admin.AssertExpectations(suite.T())
+
+	rr := testutil.DoHTTPRequestMust(testutil.HTTPRequestProps{
+		Server: suite.r,
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("/kafka-inbox/dead-letters/%d/skip", suite.sampleDeadLetter.ID),
+		Token:  suite.sampleToken,
+	})
+
+	suite.Equal(http.StatusOK, rr.Code, "should return correct code")
+}
+
+func Test_deadLetterHandlers(t *testing.T) {
+	suite.Run(t, new(deadLetterHandlersSuite))
+}
@@ -0,0 +1,237 @@
+package kafkautil
+
+import (
+	"context"
+	"errors"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// deadLetterAdminSuite tests deadLetterAdmin.
+type deadLetterAdminSuite struct {
+	suite.Suite
+	c                 *connector
+	store             *storeMock
+	txSupplier        *testutil.DBTxSupplier
+	admin             DeadLetterAdmin
+	samplePage        pagination.Params
+	sampleDeadLetter  DeadLetter
+	sampleDeadLetters pagination.Paginated[DeadLetter]
+}
+
+func (suite *deadLetterAdminSuite) SetupTest() {
+	suite.store = &storeMock{}
+	suite.txSupplier = &testutil.DBTxSupplier{}
+	var err error
+	suite.c, err = newConnector(zap.NewNop(), suite.store, ConnectorConfig{})
+	suite.Require().NoError(err, "connector creation should not fail")
+	suite.admin = suite.c.DeadLetterAdmin(suite.txSupplier)
+	suite.samplePage = pagination.Params{
+		Limit:          5,
+		Offset:         2,
+		OrderBy:        nulls.NewString("id"),
+		OrderDirection: pagination.OrderDirDesc,
+	}
+	suite.sampleDeadLetter = DeadLetter{
+		ID:             54,
+		Topic:          "shirt",
+		Partition:      2,
+		Offset:         841,
+		HighWaterMark:  900,
+		TS:             time.Date(2022, 8, 15, 17, 13, 27, 0, time.UTC),
+		Key:            "tidy",
+		EventType:      "meet",
+		RawValue:       `{"hello":`,
+		Headers:        []MessageHeader{{Key: "event-type", Value: "meet"}},
+		Attempts:       16,
+		LastError:      nulls.NewString("parse failed"),
+		LastErrorTS:    nulls.NewTime(time.Date(2022, 8, 15, 17, 20, 0, 0, time.UTC)),
+		DeadLetteredAt: time.Date(2022, 8, 15, 17, 20, 0, 0, time.UTC),
+	}
+	suite.sampleDeadLetters = pagination.NewPaginated(suite.samplePage, []DeadLetter{suite.sampleDeadLetter}, 3)
+}
+
+func (suite *deadLetterAdminSuite) TestDeadLettersTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.BeginFail = true
+
+	go func() {
+		defer cancel()
+		_, err := suite.admin.DeadLetters(timeout, suite.samplePage)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestDeadLettersRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("deadLetters", mock.Anything, suite.txSupplier.Tx[0], suite.samplePage).
+		Return(pagination.Paginated[DeadLetter]{}, errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.admin.DeadLetters(timeout, suite.samplePage)
+		suite.Error(err, "should fail")
+		suite.False(suite.txSupplier.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestDeadLettersOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("deadLetters", mock.Anything, suite.txSupplier.Tx[0], suite.samplePage).
+		Return(suite.sampleDeadLetters, nil)
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.admin.DeadLetters(timeout, suite.samplePage)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.sampleDeadLetters, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestDeadLetterByIDRetrieveFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("deadLetterByID", mock.Anything, suite.txSupplier.Tx[0], suite.sampleDeadLetter.ID).
+		Return(DeadLetter{}, errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		_, err := suite.admin.DeadLetterByID(timeout, suite.sampleDeadLetter.ID)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestDeadLetterByIDOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("deadLetterByID", mock.Anything, suite.txSupplier.Tx[0], suite.sampleDeadLetter.ID).
+		Return(suite.sampleDeadLetter, nil)
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		got, err := suite.admin.DeadLetterByID(timeout, suite.sampleDeadLetter.ID)
+		suite.Require().NoError(err, "should not fail")
+		suite.Equal(suite.sampleDeadLetter, got, "should return correct value")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestRetryFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("setDeadLetterStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		suite.sampleDeadLetter.ID, inboxMessageStatusPending, true).
+		Return(errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.admin.RetryDeadLetter(timeout, suite.sampleDeadLetter.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.txSupplier.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestRetryOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("setDeadLetterStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		suite.sampleDeadLetter.ID, inboxMessageStatusPending, true).
+		Return(nil)
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.admin.RetryDeadLetter(timeout, suite.sampleDeadLetter.ID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.txSupplier.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestSkipFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("setDeadLetterStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		suite.sampleDeadLetter.ID, inboxMessageStatusSkipped, false).
+		Return(errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.admin.SkipDeadLetter(timeout, suite.sampleDeadLetter.ID)
+		suite.Error(err, "should fail")
+		suite.False(suite.txSupplier.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *deadLetterAdminSuite) TestSkipOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.store.On("setDeadLetterStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		suite.sampleDeadLetter.ID, inboxMessageStatusSkipped, false).
+		Return(nil)
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.admin.SkipDeadLetter(timeout, suite.sampleDeadLetter.ID)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.txSupplier.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func Test_deadLetterAdmin(t *testing.T) {
+	suite.Run(t, new(deadLetterAdminSuite))
+}
+
+// DeadLetterAdminMock mocks DeadLetterAdmin.
+type DeadLetterAdminMock struct {
+	mock.Mock
+}
+
+func (m *DeadLetterAdminMock) DeadLetters(ctx context.Context, page pagination.Params) (pagination.Paginated[DeadLetter], error) {
+	args := m.Called(ctx, page)
+	return args.Get(0).(pagination.Paginated[DeadLetter]), args.Error(1)
+}
+
+func (m *DeadLetterAdminMock) DeadLetterByID(ctx context.Context, messageID int) (DeadLetter, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).(DeadLetter), args.Error(1)
+}
+
+func (m *DeadLetterAdminMock) RetryDeadLetter(ctx context.Context, messageID int) error {
+	return m.Called(ctx, messageID).Error(0)
+}
+
+func (m *DeadLetterAdminMock) SkipDeadLetter(ctx context.Context, messageID int) error {
+	return m.Called(ctx, messageID).Error(0)
+}
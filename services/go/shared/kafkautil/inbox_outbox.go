@@ -13,7 +13,9 @@ import (
 	"github.com/lefinal/meh/mehlog"
 	"github.com/lefinal/meh/mehpg"
 	"github.com/lib/pq"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/logging"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgconnect"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgmigrate"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
@@ -22,6 +24,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"io/fs"
 	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
@@ -51,8 +54,40 @@ const (
 	inboxMessageStatusPending inboxMessageStatus = 0
 	// inboxMessageStatusProcessed for messages that have been processed.
 	inboxMessageStatusProcessed inboxMessageStatus = 200
+	// inboxMessageStatusSkipped for dead-lettered messages that were skipped
+	// manually and will therefore never be processed.
+	inboxMessageStatusSkipped inboxMessageStatus = 210
+	// inboxMessageStatusDeadLettered for messages that failed to process too often.
+	// They are not processed anymore until being retried manually.
+	inboxMessageStatusDeadLettered inboxMessageStatus = 500
 )
 
+// DefaultInboxMaxAttempts is the default maximum number of attempts for
+// processing an inbox message, before it is moved to the dead letters. With the
+// backoff between attempts, this takes about 40 minutes.
+const DefaultInboxMaxAttempts = 16
+
+const (
+	// inboxRetryBaseDelay is the delay before retrying to process an inbox
+	// message after the first failed attempt. It is doubled for each following
+	// failed attempt until reaching inboxRetryMaxDelay.
+	inboxRetryBaseDelay = 1 * time.Second
+	// inboxRetryMaxDelay is the maximum delay before retrying to process an inbox
+	// message.
+	inboxRetryMaxDelay = 5 * time.Minute
+)
+
+// ConnectorConfig is the configuration for a Connector.
+type ConnectorConfig struct {
+	// InboxMaxAttempts is the maximum number of attempts for processing an inbox
+	// message before it is moved to the dead letters. If not positive,
+	// DefaultInboxMaxAttempts is used.
+	InboxMaxAttempts int
+	// Retention for handled inbox and outbox messages, applied by
+	// Connector.RunJanitor.
+	Retention RetentionConfig
+}
+
 // outboxMessageStatus for messages in the outbox.
 type outboxMessageStatus int
 
@@ -88,10 +123,18 @@ type Connector interface {
 	// ProcessIncoming processes messages in the inbox with the given HandlerFunc
 	// and blocks until the given context is done.
 	ProcessIncoming(ctx context.Context, txSupplier pgutil.DBTxSupplier, handlerFn HandlerFunc) error
+	// DeadLetterAdmin returns a DeadLetterAdmin for managing dead-lettered inbox
+	// messages using the given pgutil.DBTxSupplier.
+	DeadLetterAdmin(txSupplier pgutil.DBTxSupplier) DeadLetterAdmin
+	// RunJanitor periodically deletes handled inbox and outbox messages, that
+	// exceeded their retention, and logs table statistics. It blocks until the
+	// given context is done.
+	RunJanitor(ctx context.Context, txSupplier pgutil.DBTxSupplier) error
 }
 
 // RunConnector serves as a wrapper for calling Connector.PumpOutgoing,
-// Connector.Read and Connector.ProcessIncoming and blocks.
+// Connector.Read, Connector.ProcessIncoming and Connector.RunJanitor and
+// blocks.
 func RunConnector(ctx context.Context, c Connector, txSupplier pgutil.DBTxSupplier, writer Writer, reader Reader, handlerFn HandlerFunc) error {
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
@@ -103,6 +146,9 @@ func RunConnector(ctx context.Context, c Connector, txSupplier pgutil.DBTxSuppli
 	eg.Go(func() error {
 		return meh.NilOrWrap(c.ProcessIncoming(egCtx, txSupplier, handlerFn), "process incoming", nil)
 	})
+	eg.Go(func() error {
+		return meh.NilOrWrap(c.RunJanitor(egCtx, txSupplier), "run janitor", nil)
+	})
 	return eg.Wait()
 }
 
@@ -113,13 +159,24 @@ type connector struct {
 	// updated status for events.
 	id    uuid.UUID
 	store store
+	// inboxMaxAttempts is the maximum number of attempts for processing an inbox
+	// message before it is moved to the dead letters.
+	inboxMaxAttempts int
+	// retention is used by RunJanitor.
+	retention RetentionConfig
+	// skippedDuplicates is the number of inbox messages, that were skipped in Read
+	// because of already being in the inbox.
+	skippedDuplicates atomic.Uint64
+	// schemas is used for upcasting message values in ProcessIncoming.
+	schemas *event.Registry
 }
 
-// InitNewConnector creates and initializes a new Connector.
-func InitNewConnector(ctx context.Context, logger *zap.Logger, connPool *pgxpool.Pool) (Connector, error) {
+// InitNewConnector creates and initializes a new Connector with the given
+// ConnectorConfig.
+func InitNewConnector(ctx context.Context, logger *zap.Logger, connPool *pgxpool.Pool, config ConnectorConfig) (Connector, error) {
 	c, err := newConnector(logger, &dbStore{
 		dialect: goqu.Dialect("postgres"),
-	})
+	}, config)
 	if err != nil {
 		return nil, meh.Wrap(err, "new connector", nil)
 	}
@@ -130,19 +187,25 @@ func InitNewConnector(ctx context.Context, logger *zap.Logger, connPool *pgxpool
 	return c, nil
 }
 
-func newConnector(logger *zap.Logger, store store) (*connector, error) {
+func newConnector(logger *zap.Logger, store store, config ConnectorConfig) (*connector, error) {
 	id, err := uuid.NewV4()
 	if err != nil {
 		return nil, meh.NewInternalErrFromErr(err, "new id", nil)
 	}
 	c := &connector{
-		logger: logger,
-		id:     id,
-		store:  store,
+		logger:           logger,
+		id:               id,
+		store:            store,
+		inboxMaxAttempts: config.InboxMaxAttempts,
+		retention:        config.Retention.withDefaults(),
+		schemas:          event.Schemas,
 	}
 	if c.logger == nil {
 		c.logger = logging.DebugLogger()
 	}
+	if c.inboxMaxAttempts <= 0 {
+		c.inboxMaxAttempts = DefaultInboxMaxAttempts
+	}
 	return c, nil
 }
 
@@ -179,7 +242,7 @@ func (c *connector) PumpOutgoing(ctx context.Context, txSupplier pgutil.DBTxSupp
 	logger := c.logger.Named("outgoing-pump")
 	// Spawn workers.
 	eg, egCtx := errgroup.WithContext(ctx)
-	for i := 0; i < writerBatchSize; i++ {
+	for i := 0; i < pumpOutgoingWorkers; i++ {
 		eg.Go(func() error {
 			return pumpOutgoing(egCtx, logger, c.id, c.store, txSupplier, writer)
 		})
@@ -190,33 +253,40 @@ func (c *connector) PumpOutgoing(ctx context.Context, txSupplier pgutil.DBTxSupp
 const (
 	pumpOutgoingPollWait      = 500 * time.Millisecond
 	pumpOutgoingErrorCooldown = 3 * time.Second
+	// pumpOutgoingWorkers is the number of workers in Connector.PumpOutgoing.
+	pumpOutgoingWorkers = 4
 )
 
-// pumpOutgoing is a worker that sends outbound messages. It only returns an
-// error in fatal cases or when the context.Context is done.
+// pumpOutgoing is a worker that sends outbound messages in batches per segment
+// (topic, key). It only returns an error in fatal cases or when the
+// context.Context is done.
 func pumpOutgoing(ctx context.Context, logger *zap.Logger, instanceID uuid.UUID, store store,
 	txSupplier pgutil.DBTxSupplier, writer Writer) error {
 	for {
 		wait := time.Duration(0)
 		err := pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
 			// Retrieve next.
-			next, ok, err := store.nextOutboxMessage(ctx, tx)
+			batch, err := store.nextOutboxMessageBatch(ctx, tx, writerBatchSize)
 			if err != nil {
-				return meh.Wrap(err, "next outbox message from store", nil)
+				return meh.Wrap(err, "next outbox message batch from store", nil)
 			}
-			if !ok {
+			if len(batch) == 0 {
 				wait = pumpOutgoingPollWait
 				return nil
 			}
 			// Send.
-			err = WriteMessages(writer, next)
+			err = WriteMessages(writer, batch...)
 			if err != nil {
-				return meh.Wrap(err, "write messages", meh.Details{"message": next})
+				return meh.Wrap(err, "write messages", meh.Details{"messages": batch})
 			}
 			// Update status.
-			err = store.setOutboxMessageStatus(ctx, tx, instanceID, next.id, outboxMessageStatusSent)
+			messageIDs := make([]int, 0, len(batch))
+			for _, message := range batch {
+				messageIDs = append(messageIDs, message.id)
+			}
+			err = store.setOutboxMessagesStatus(ctx, tx, instanceID, messageIDs, outboxMessageStatusSent)
 			if err != nil {
-				return meh.Wrap(err, "set outbox-message-status to sent", meh.Details{"message_id": next.id})
+				return meh.Wrap(err, "set outbox-messages-status to sent", meh.Details{"message_ids": messageIDs})
 			}
 			return nil
 		})
@@ -257,10 +327,18 @@ func (c *connector) Read(ctx context.Context, txSupplier pgutil.DBTxSupplier, re
 			err = pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
 				m := inboundMessageFromKafkaMessage(kafkaMessage)
 				// Add to inbox.
-				err := c.store.addInboxMessages(ctx, tx, c.id, m)
+				added, err := c.store.addInboxMessages(ctx, tx, c.id, m)
 				if err != nil {
 					return meh.Wrap(err, "add inbox message to store", meh.Details{"message": m})
 				}
+				if added == 0 {
+					c.skippedDuplicates.Add(1)
+					logger.Debug("skipped duplicate inbox message",
+						zap.Any("topic", m.Topic),
+						zap.Int("partition", m.Partition),
+						zap.Int("offset", m.Offset),
+						zap.Any("event_id", m.EventID))
+				}
 				// Commit.
 				err = reader.CommitMessages(ctx, kafkaMessage)
 				if err != nil {
@@ -300,9 +378,12 @@ func (c *connector) ProcessIncoming(ctx context.Context, txSupplier pgutil.DBTxS
 	lastProcessFailed := false
 	for {
 		wait := time.Duration(0)
+		var next InboundMessage
+		var ok bool
 		err := pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+			var err error
 			// Retrieve next.
-			next, ok, err := c.store.nextInboxMessage(ctx, tx, lastProcessFailed)
+			next, ok, err = c.store.nextInboxMessage(ctx, tx, lastProcessFailed)
 			if err != nil {
 				return meh.Wrap(err, "next inbox message from store", nil)
 			}
@@ -310,6 +391,11 @@ func (c *connector) ProcessIncoming(ctx context.Context, txSupplier pgutil.DBTxS
 				wait = processIncomingPollWait
 				return nil
 			}
+			// Upcast to the current version, so that handlers only need to handle this one.
+			next.RawValue, next.SchemaVersion, err = c.schemas.Upcast(next.EventType, next.SchemaVersion, next.RawValue)
+			if err != nil {
+				return meh.Wrap(err, "upcast message value", meh.Details{"message": next})
+			}
 			// Process.
 			err = handlerFn(ctx, tx, next)
 			if err != nil {
@@ -330,6 +416,12 @@ func (c *connector) ProcessIncoming(ctx context.Context, txSupplier pgutil.DBTxS
 		if err != nil {
 			mehlog.Log(logger, meh.Wrap(err, "run in tx", nil))
 			wait = processIncomingErrorCooldown
+			if ok {
+				err = c.recordInboxMessageFailure(ctx, logger, txSupplier, next, err)
+				if err != nil {
+					mehlog.Log(logger, meh.Wrap(err, "record inbox message failure", meh.Details{"message_id": next.id}))
+				}
+			}
 		}
 		if wait == 0 {
 			continue
@@ -343,9 +435,39 @@ func (c *connector) ProcessIncoming(ctx context.Context, txSupplier pgutil.DBTxS
 	}
 }
 
+// recordInboxMessageFailure records the given processing error for the given
+// InboundMessage. If the maximum number of attempts is reached, the message is
+// moved to the dead letters.
+func (c *connector) recordInboxMessageFailure(ctx context.Context, logger *zap.Logger, txSupplier pgutil.DBTxSupplier,
+	message InboundMessage, processErr error) error {
+	var deadLettered bool
+	err := pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		deadLettered, err = c.store.recordInboxMessageFailure(ctx, tx, c.id, message.id, processErr.Error(), c.inboxMaxAttempts)
+		if err != nil {
+			return meh.Wrap(err, "record inbox message failure in store", meh.Details{"message_id": message.id})
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	if deadLettered {
+		logger.Warn("moved inbox message to dead letters",
+			zap.Int("message_id", message.id),
+			zap.String("topic", string(message.Topic)),
+			zap.Int("partition", message.Partition),
+			zap.Int("offset", message.Offset),
+			zap.Int("max_attempts", c.inboxMaxAttempts))
+	}
+	return nil
+}
+
 type store interface {
-	// addInboxMessages adds the given messages to the inbox.
-	addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) error
+	// addInboxMessages adds the given messages to the inbox. Messages, already
+	// being in the inbox with the same topic, partition and offset or with the same
+	// event id, are skipped. It returns the number of added messages.
+	addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) (int, error)
 	// nextInboxMessage retrieves the next inbox message to process and locks it. If
 	// the random-flag is set, a random one of possible messages will be chosen. This
 	// may lead to falsy return values but is an accepted tradeoff when handling
@@ -354,12 +476,36 @@ type store interface {
 	nextInboxMessage(ctx context.Context, tx pgx.Tx, selectRandomSegment bool) (InboundMessage, bool, error)
 	// setInboxMessageStatus updates the status for the given message.
 	setInboxMessageStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status inboxMessageStatus) error
+	// recordInboxMessageFailure increments the attempts for the given pending
+	// message and remembers the given error. If the maximum number of attempts is
+	// reached, the message is moved to the dead letters, which is reported via the
+	// returned flag.
+	recordInboxMessageFailure(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, failure string, maxAttempts int) (bool, error)
+	// deadLetters retrieves a paginated DeadLetter list.
+	deadLetters(ctx context.Context, tx pgx.Tx, page pagination.Params) (pagination.Paginated[DeadLetter], error)
+	// deadLetterByID retrieves the DeadLetter with the given id.
+	deadLetterByID(ctx context.Context, tx pgx.Tx, messageID int) (DeadLetter, error)
+	// setDeadLetterStatus updates the status of the dead-lettered message with the
+	// given id. If resetAttempts is set, the attempt counter is reset as well.
+	setDeadLetterStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status inboxMessageStatus, resetAttempts bool) error
+	// deleteInboxMessagesBefore deletes at most the given limit of inbox messages
+	// with the given status, having their status updated before the given time.
+	// It returns the number of deleted messages.
+	deleteInboxMessagesBefore(ctx context.Context, tx pgx.Tx, status inboxMessageStatus, before time.Time, limit int) (int, error)
+	// deleteOutboxMessagesBefore deletes at most the given limit of outbox messages
+	// with the given status, having their status updated before the given time.
+	// It returns the number of deleted messages.
+	deleteOutboxMessagesBefore(ctx context.Context, tx pgx.Tx, status outboxMessageStatus, before time.Time, limit int) (int, error)
+	// messageTableStats retrieves messageTableStats for the inbox and outbox table.
+	messageTableStats(ctx context.Context, tx pgx.Tx) (inboxStats messageTableStats, outboxStats messageTableStats, err error)
 	// addOutboxMessages adds the given messages to the message outbox.
 	addOutboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...OutboundMessage) error
-	// nextOutboxMessage retrieves the next message to send allows concurrency.
-	nextOutboxMessage(ctx context.Context, tx pgx.Tx) (OutboundMessage, bool, error)
-	// setOutboxMessageStatus sets the status for the message with the given id.
-	setOutboxMessageStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status outboxMessageStatus) error
+	// nextOutboxMessageBatch retrieves and locks at most the given limit of the
+	// next messages to send. All of them belong to the same segment (topic, key)
+	// and are sorted by their order of creation. It allows concurrency.
+	nextOutboxMessageBatch(ctx context.Context, tx pgx.Tx, limit int) ([]OutboundMessage, error)
+	// setOutboxMessagesStatus sets the status for the messages with the given ids.
+	setOutboxMessagesStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageIDs []int, status outboxMessageStatus) error
 }
 
 type dbStore struct {
@@ -367,9 +513,10 @@ type dbStore struct {
 }
 
 // addInboxMessages adds the given messages to the inbox table in the database.
-func (s *dbStore) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) error {
+// Duplicates are skipped because of unique indices in the database.
+func (s *dbStore) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) (int, error) {
 	if len(messages) == 0 {
-		return nil
+		return 0, nil
 	}
 	rows := make([]any, 0, len(messages))
 	for _, message := range messages {
@@ -388,6 +535,7 @@ func (s *dbStore) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uu
 			"key":             message.Key,
 			"value":           string(message.RawValue),
 			"event_type":      message.EventType,
+			"event_id":        message.EventID,
 			"header_keys":     pq.Array(headerKeys),
 			"header_values":   pq.Array(headerValues),
 			"status":          inboxMessageStatusPending,
@@ -396,15 +544,16 @@ func (s *dbStore) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uu
 		})
 	}
 	q, _, err := s.dialect.Insert(goqu.T("__message_inbox")).
-		Rows(rows...).ToSQL()
+		Rows(rows...).
+		OnConflict(goqu.DoNothing()).ToSQL()
 	if err != nil {
-		return meh.NewInternalErrFromErr(err, "query to sql", nil)
+		return 0, meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
-	_, err = tx.Exec(ctx, q)
+	result, err := tx.Exec(ctx, q)
 	if err != nil {
-		return mehpg.NewQueryDBErr(err, "exec query", q)
+		return 0, mehpg.NewQueryDBErr(err, "exec query", q)
 	}
-	return nil
+	return int(result.RowsAffected()), nil
 }
 
 // nextInboxMessage retrieves the next inbox message to process and locks it. If
@@ -418,18 +567,20 @@ func (s *dbStore) nextInboxMessage(ctx context.Context, tx pgx.Tx, selectRandomS
 			goqu.I("__message_inbox.partition"),
 			goqu.I("__message_inbox.key"),
 			goqu.MIN(goqu.I("__message_inbox.offset")).As("offset")).
-		Where(goqu.I("__message_inbox.status").Neq(inboxMessageStatusProcessed)).
+		Where(goqu.I("__message_inbox.status").Eq(inboxMessageStatusPending)).
 		GroupBy(goqu.I("__message_inbox.topic"),
 			goqu.I("__message_inbox.partition"),
 			goqu.I("__message_inbox.key"))
 	possibleNextQuery, _, err := s.dialect.From(goqu.T("__message_inbox")).
 		InnerJoin(oldestPendingPerSegment, goqu.On(
-			goqu.I("__message_inbox.status").Neq(inboxMessageStatusProcessed), // Because of partial index in database.
+			goqu.I("__message_inbox.status").Eq(inboxMessageStatusPending), // Because of partial index in database.
 			goqu.I("__message_inbox.topic").Eq(goqu.I("oldest_pending.topic")),
 			goqu.I("__message_inbox.partition").Eq(goqu.I("oldest_pending.partition")),
 			goqu.I("__message_inbox.offset").Eq(goqu.I("oldest_pending.offset")),
 		)).
-		Select(goqu.I("__message_inbox.id")).ToSQL()
+		Select(goqu.I("__message_inbox.id")).
+		Where(goqu.Or(goqu.I("__message_inbox.next_attempt_at").IsNull(),
+			goqu.I("__message_inbox.next_attempt_at").Lte(time.Now().UTC()))).ToSQL()
 	if err != nil {
 		return InboundMessage{}, false, meh.NewInternalErrFromErr(err, "possible-next-query to sql", nil)
 	}
@@ -459,22 +610,18 @@ func (s *dbStore) nextInboxMessage(ctx context.Context, tx pgx.Tx, selectRandomS
 	// Choose the oldest one that matches conditions.
 	nextQuery, _, err := s.dialect.From(goqu.T("__message_inbox")).
 		Select(goqu.I("__message_inbox.id"),
-												goqu.I("__message_inbox.topic"),
-												goqu.I("__message_inbox.partition"),
-												goqu.I("__message_inbox.offset"),
-												goqu.I("__message_inbox.ts"),
-												goqu.I("__message_inbox.high_water_mark"),
-												goqu.I("__message_inbox.key"),
-												goqu.I("__message_inbox.value"),
-												goqu.I("__message_inbox.event_type"),
-												goqu.I("__message_inbox.header_keys"),
-												goqu.I("__message_inbox.header_values")).
-		Where(goqu.I("__message_inbox.status").Neq(inboxMessageStatusProcessed), // Because of partial index.
-			goqu.I("__message_inbox.id").In(possibleNext),
-			goqu.Or(
-				// Common case.
-				goqu.I("__message_inbox.status").Eq(inboxMessageStatusPending),
-			)).
+											goqu.I("__message_inbox.topic"),
+											goqu.I("__message_inbox.partition"),
+											goqu.I("__message_inbox.offset"),
+											goqu.I("__message_inbox.ts"),
+											goqu.I("__message_inbox.high_water_mark"),
+											goqu.I("__message_inbox.key"),
+											goqu.I("__message_inbox.value"),
+											goqu.I("__message_inbox.event_type"),
+											goqu.I("__message_inbox.header_keys"),
+											goqu.I("__message_inbox.header_values")).
+		Where(goqu.I("__message_inbox.status").Eq(inboxMessageStatusPending), // Because of partial index.
+			goqu.I("__message_inbox.id").In(possibleNext)).
 		Order(goqu.I("__message_inbox.status_ts").Asc()).
 		ForUpdate(exp.SkipLocked).
 		Limit(1).ToSQL()
@@ -523,18 +670,20 @@ func (s *dbStore) nextInboxMessage(ctx context.Context, tx pgx.Tx, selectRandomS
 			Value: mHeaderValues[i],
 		})
 	}
+	m.EventID = eventIDFromHeaders(m.Headers)
+	m.SchemaVersion = schemaVersionFromHeaders(m.Headers)
 	return m, true, nil
 }
 
 // setInboxMessageStatus updates the status and update timestamp for the given
-// message in the inbox table in the database, not having
-// inboxMessageStatusProcessed.
+// message in the inbox table in the database, having
+// inboxMessageStatusPending.
 func (s *dbStore) setInboxMessageStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status inboxMessageStatus) error {
 	q, _, err := s.dialect.Update(goqu.T("__message_inbox")).Set(goqu.Record{
 		"status":    status,
 		"status_ts": time.Now().UTC(),
 		"status_by": instanceID,
-	}).Where(goqu.C("status").Neq(inboxMessageStatusProcessed),
+	}).Where(goqu.C("status").Eq(inboxMessageStatusPending),
 		goqu.C("id").Eq(messageID)).ToSQL()
 	if err != nil {
 		return meh.NewInternalErrFromErr(err, "query to sql", nil)
@@ -549,6 +698,48 @@ func (s *dbStore) setInboxMessageStatus(ctx context.Context, tx pgx.Tx, instance
 	return nil
 }
 
+// recordInboxMessageFailure increments the attempts for the given message in the
+// inbox table in the database, having inboxMessageStatusPending, and sets the
+// last error. The next attempt is delayed exponentially, starting with
+// inboxRetryBaseDelay up to inboxRetryMaxDelay. If the given maximum number of
+// attempts is reached, the status is set to inboxMessageStatusDeadLettered.
+func (s *dbStore) recordInboxMessageFailure(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int,
+	failure string, maxAttempts int) (bool, error) {
+	now := time.Now().UTC()
+	exhausted := goqu.L("? + 1", goqu.C("attempts")).Gte(maxAttempts)
+	nextAttemptAt := goqu.L("?::timestamp + make_interval(secs => least(? * power(2, ?), ?))",
+		now, inboxRetryBaseDelay.Seconds(), goqu.C("attempts"), inboxRetryMaxDelay.Seconds())
+	q, _, err := s.dialect.Update(goqu.T("__message_inbox")).Set(goqu.Record{
+		"attempts":        goqu.L("? + 1", goqu.C("attempts")),
+		"last_error":      failure,
+		"last_error_ts":   now,
+		"next_attempt_at": nextAttemptAt,
+		"status":          goqu.Case().When(exhausted, inboxMessageStatusDeadLettered).Else(goqu.C("status")),
+		"status_ts":       goqu.Case().When(exhausted, now).Else(goqu.C("status_ts")),
+		"status_by":       goqu.Case().When(exhausted, instanceID).Else(goqu.C("status_by")),
+	}).Where(goqu.C("status").Eq(inboxMessageStatusPending),
+		goqu.C("id").Eq(messageID)).
+		Returning(goqu.C("status")).ToSQL()
+	if err != nil {
+		return false, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return false, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return false, meh.NewNotFoundErr("message not found", meh.Details{"query": q})
+	}
+	var status inboxMessageStatus
+	err = rows.Scan(&status)
+	if err != nil {
+		return false, mehpg.NewScanRowsErr(err, "scan row", q)
+	}
+	rows.Close()
+	return status == inboxMessageStatusDeadLettered, nil
+}
+
 // addOutboxMessages inserts the given messages into the outbox table in the
 // database.
 func (s *dbStore) addOutboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...OutboundMessage) error {
@@ -592,8 +783,13 @@ func (s *dbStore) addOutboxMessages(ctx context.Context, tx pgx.Tx, instanceID u
 	return nil
 }
 
-// nextOutboxMessage retrieves the next outbox message to send and locks it.
-func (s *dbStore) nextOutboxMessage(ctx context.Context, tx pgx.Tx) (OutboundMessage, bool, error) {
+// nextOutboxMessageBatch retrieves the next batch of outbox messages to send
+// and locks them. All messages belong to the same segment (topic, key) and are
+// sorted ascending by id. The oldest pending message of a segment acts as lock
+// for the whole segment. As it is locked with skip-locked semantics, other
+// workers and instances continue with other segments, which preserves ordering
+// per segment.
+func (s *dbStore) nextOutboxMessageBatch(ctx context.Context, tx pgx.Tx, limit int) ([]OutboundMessage, error) {
 	// Gather identifiers for the oldest unprocessed message per segment (topic,
 	// key).
 	possibleNextQuery, _, err := s.dialect.From(goqu.T("__message_outbox")).As("oldest_pending").
@@ -602,11 +798,11 @@ func (s *dbStore) nextOutboxMessage(ctx context.Context, tx pgx.Tx) (OutboundMes
 		GroupBy(goqu.I("__message_outbox.topic"),
 			goqu.I("__message_outbox.key")).ToSQL()
 	if err != nil {
-		return OutboundMessage{}, false, meh.NewInternalErrFromErr(err, "possible-next-query to sql", nil)
+		return nil, meh.NewInternalErrFromErr(err, "possible-next-query to sql", nil)
 	}
 	possibleNextRows, err := tx.Query(ctx, possibleNextQuery)
 	if err != nil {
-		return OutboundMessage{}, false, mehpg.NewQueryDBErr(err, "exec possible-next-query", possibleNextQuery)
+		return nil, mehpg.NewQueryDBErr(err, "exec possible-next-query", possibleNextQuery)
 	}
 	defer possibleNextRows.Close()
 	possibleNext := make([]int, 0)
@@ -614,85 +810,122 @@ func (s *dbStore) nextOutboxMessage(ctx context.Context, tx pgx.Tx) (OutboundMes
 	for possibleNextRows.Next() {
 		err = possibleNextRows.Scan(&possibleNextID)
 		if err != nil {
-			return OutboundMessage{}, false, mehpg.NewScanRowsErr(err, "scan possible-next-row", possibleNextQuery)
+			return nil, mehpg.NewScanRowsErr(err, "scan possible-next-row", possibleNextQuery)
 		}
 		possibleNext = append(possibleNext, possibleNextID)
 	}
 	possibleNextRows.Close()
 	if len(possibleNext) == 0 {
-		return OutboundMessage{}, false, nil
+		return []OutboundMessage{}, nil
 	}
-	// Choose the oldest one that matches conditions.
-	nextQuery, _, err := s.dialect.From(goqu.T("__message_outbox")).
+	// Claim the oldest segment, that is not locked by another worker.
+	headQuery, _, err := s.dialect.From(goqu.T("__message_outbox")).
 		Select(goqu.I("__message_outbox.id"),
 											goqu.I("__message_outbox.topic"),
-											goqu.I("__message_outbox.key"),
-											goqu.I("__message_outbox.value"),
-											goqu.I("__message_outbox.event_type"),
-											goqu.I("__message_outbox.header_keys"),
-											goqu.I("__message_outbox.header_values")).
+											goqu.I("__message_outbox.key")).
 		Where(goqu.I("__message_outbox.status").Neq(outboxMessageStatusSent), // Because of partial index.
 			goqu.I("__message_outbox.id").In(possibleNext),
-			goqu.Or(
-				// Common case.
-				goqu.I("__message_outbox.status").Eq(outboxMessageStatusPending),
-			)).
+			goqu.I("__message_outbox.status").Eq(outboxMessageStatusPending)).
 		Order(goqu.I("__message_outbox.id").Asc()).
 		ForUpdate(exp.SkipLocked).
 		Limit(1).ToSQL()
 	if err != nil {
-		return OutboundMessage{}, false, meh.NewInternalErrFromErr(err, "next-query to sql", nil)
+		return nil, meh.NewInternalErrFromErr(err, "head-query to sql", nil)
 	}
-	// Retrieve.
-	nextRows, err := tx.Query(ctx, nextQuery)
+	headRows, err := tx.Query(ctx, headQuery)
 	if err != nil {
-		return OutboundMessage{}, false, mehpg.NewQueryDBErr(err, "exec next-query", nextQuery)
+		return nil, mehpg.NewQueryDBErr(err, "exec head-query", headQuery)
 	}
-	defer nextRows.Close()
-	if !nextRows.Next() {
-		return OutboundMessage{}, false, nil
+	defer headRows.Close()
+	if !headRows.Next() {
+		return []OutboundMessage{}, nil
 	}
-	var m OutboundMessage
-	var mValue string
-	var mHeaderKeys []string
-	var mHeaderValues []string
-	err = nextRows.Scan(&m.id,
-		&m.Topic,
-		&m.Key,
-		&mValue,
-		&m.EventType,
-		&mHeaderKeys,
-		&mHeaderValues)
+	var headID int
+	var headTopic string
+	var headKey string
+	err = headRows.Scan(&headID, &headTopic, &headKey)
 	if err != nil {
-		return OutboundMessage{}, false, mehpg.NewScanRowsErr(err, "scan row", nextQuery)
+		return nil, mehpg.NewScanRowsErr(err, "scan head-row", headQuery)
 	}
-	m.Value = json.RawMessage(mValue)
-	if len(mHeaderKeys) != len(mHeaderValues) {
-		return OutboundMessage{}, false, meh.NewInternalErr("list length mismatch for header keys and values", meh.Details{
-			"message_header_keys":    mHeaderKeys,
-			"message_header_values":  mHeaderValues,
-			"next_message_until_now": m,
-			"query":                  nextQuery,
-		})
+	headRows.Close()
+	// Retrieve the batch for the claimed segment. We do not need to skip locked
+	// messages here as only the holder of the lock for the oldest message locks
+	// the following ones.
+	batchQuery, _, err := s.dialect.From(goqu.T("__message_outbox")).
+		Select(goqu.I("__message_outbox.id"),
+											goqu.I("__message_outbox.topic"),
+											goqu.I("__message_outbox.key"),
+											goqu.I("__message_outbox.value"),
+											goqu.I("__message_outbox.event_type"),
+											goqu.I("__message_outbox.event_id"),
+											goqu.I("__message_outbox.header_keys"),
+											goqu.I("__message_outbox.header_values")).
+		Where(goqu.I("__message_outbox.status").Neq(outboxMessageStatusSent), // Because of partial index.
+			goqu.I("__message_outbox.status").Eq(outboxMessageStatusPending),
+			goqu.I("__message_outbox.topic").Eq(headTopic),
+			goqu.I("__message_outbox.key").Eq(headKey),
+			goqu.I("__message_outbox.id").Gte(headID)).
+		Order(goqu.I("__message_outbox.id").Asc()).
+		ForUpdate(exp.Wait).
+		Limit(uint(limit)).ToSQL()
+	if err != nil {
+		return nil, meh.NewInternalErrFromErr(err, "batch-query to sql", nil)
 	}
-	m.Headers = make([]MessageHeader, 0, len(mHeaderKeys))
-	for i := range mHeaderKeys {
-		m.Headers = append(m.Headers, MessageHeader{
-			Key:   mHeaderKeys[i],
-			Value: mHeaderValues[i],
-		})
+	batchRows, err := tx.Query(ctx, batchQuery)
+	if err != nil {
+		return nil, mehpg.NewQueryDBErr(err, "exec batch-query", batchQuery)
+	}
+	defer batchRows.Close()
+	batch := make([]OutboundMessage, 0, limit)
+	for batchRows.Next() {
+		var m OutboundMessage
+		var mValue string
+		var mHeaderKeys []string
+		var mHeaderValues []string
+		err = batchRows.Scan(&m.id,
+			&m.Topic,
+			&m.Key,
+			&mValue,
+			&m.EventType,
+			&m.eventID,
+			&mHeaderKeys,
+			&mHeaderValues)
+		if err != nil {
+			return nil, mehpg.NewScanRowsErr(err, "scan batch-row", batchQuery)
+		}
+		m.Value = json.RawMessage(mValue)
+		if len(mHeaderKeys) != len(mHeaderValues) {
+			return nil, meh.NewInternalErr("list length mismatch for header keys and values", meh.Details{
+				"message_header_keys":    mHeaderKeys,
+				"message_header_values":  mHeaderValues,
+				"next_message_until_now": m,
+				"query":                  batchQuery,
+			})
+		}
+		m.Headers = make([]MessageHeader, 0, len(mHeaderKeys))
+		for i := range mHeaderKeys {
+			m.Headers = append(m.Headers, MessageHeader{
+				Key:   mHeaderKeys[i],
+				Value: mHeaderValues[i],
+			})
+		}
+		batch = append(batch, m)
 	}
-	return m, true, nil
+	batchRows.Close()
+	return batch, nil
 }
 
-// setOutboxMessageStatus updates the status and update timestamp for the given
-// message in the outbox table in the database.
-func (s *dbStore) setOutboxMessageStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status outboxMessageStatus) error {
+// setOutboxMessagesStatus updates the status and update timestamp for the
+// given messages in the outbox table in the database.
+func (s *dbStore) setOutboxMessagesStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageIDs []int, status outboxMessageStatus) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
 	q, _, err := s.dialect.Update(goqu.T("__message_outbox")).Set(goqu.Record{
 		"status":    status,
 		"status_ts": time.Now().UTC(),
 		"status_by": instanceID,
-	}).Where(goqu.C("id").Eq(messageID)).ToSQL()
+	}).Where(goqu.C("id").In(messageIDs)).ToSQL()
 	if err != nil {
 		return meh.NewInternalErrFromErr(err, "query to sql", nil)
 	}
@@ -700,8 +933,11 @@ func (s *dbStore) setOutboxMessageStatus(ctx context.Context, tx pgx.Tx, instanc
 	if err != nil {
 		return mehpg.NewQueryDBErr(err, "exec query", q)
 	}
-	if result.RowsAffected() == 0 {
-		return meh.NewNotFoundErr("message not found", meh.Details{"query": q})
+	if result.RowsAffected() != int64(len(messageIDs)) {
+		return meh.NewNotFoundErr("messages not found", meh.Details{
+			"query":         q,
+			"rows_affected": result.RowsAffected(),
+		})
 	}
 	return nil
 }
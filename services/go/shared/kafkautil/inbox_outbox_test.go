@@ -7,6 +7,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/zaprec"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pagination"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
 	"github.com/segmentio/kafka-go"
@@ -63,13 +65,22 @@ func (m *ConnectorMock) ProcessIncoming(ctx context.Context, txSupplier pgutil.D
 	return m.Called(ctx, txSupplier, handlerFn).Error(0)
 }
 
+func (m *ConnectorMock) DeadLetterAdmin(txSupplier pgutil.DBTxSupplier) DeadLetterAdmin {
+	return m.Called(txSupplier).Get(0).(DeadLetterAdmin)
+}
+
+func (m *ConnectorMock) RunJanitor(ctx context.Context, txSupplier pgutil.DBTxSupplier) error {
+	return m.Called(ctx, txSupplier).Error(0)
+}
+
 // storeMock mocks store.
 type storeMock struct {
 	mock.Mock
 }
 
-func (m *storeMock) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) error {
-	return m.Called(ctx, tx, instanceID, messages).Error(0)
+func (m *storeMock) addInboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...InboundMessage) (int, error) {
+	args := m.Called(ctx, tx, instanceID, messages)
+	return args.Int(0), args.Error(1)
 }
 
 func (m *storeMock) nextInboxMessage(ctx context.Context, tx pgx.Tx, selectRandomSegment bool) (InboundMessage, bool, error) {
@@ -81,17 +92,51 @@ func (m *storeMock) setInboxMessageStatus(ctx context.Context, tx pgx.Tx, instan
 	return m.Called(ctx, tx, instanceID, messageID, status).Error(0)
 }
 
+func (m *storeMock) recordInboxMessageFailure(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, failure string, maxAttempts int) (bool, error) {
+	args := m.Called(ctx, tx, instanceID, messageID, failure, maxAttempts)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *storeMock) deadLetters(ctx context.Context, tx pgx.Tx, page pagination.Params) (pagination.Paginated[DeadLetter], error) {
+	args := m.Called(ctx, tx, page)
+	return args.Get(0).(pagination.Paginated[DeadLetter]), args.Error(1)
+}
+
+func (m *storeMock) deadLetterByID(ctx context.Context, tx pgx.Tx, messageID int) (DeadLetter, error) {
+	args := m.Called(ctx, tx, messageID)
+	return args.Get(0).(DeadLetter), args.Error(1)
+}
+
+func (m *storeMock) setDeadLetterStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status inboxMessageStatus, resetAttempts bool) error {
+	return m.Called(ctx, tx, instanceID, messageID, status, resetAttempts).Error(0)
+}
+
+func (m *storeMock) deleteInboxMessagesBefore(ctx context.Context, tx pgx.Tx, status inboxMessageStatus, before time.Time, limit int) (int, error) {
+	args := m.Called(ctx, tx, status, before, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *storeMock) deleteOutboxMessagesBefore(ctx context.Context, tx pgx.Tx, status outboxMessageStatus, before time.Time, limit int) (int, error) {
+	args := m.Called(ctx, tx, status, before, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *storeMock) messageTableStats(ctx context.Context, tx pgx.Tx) (messageTableStats, messageTableStats, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).(messageTableStats), args.Get(1).(messageTableStats), args.Error(2)
+}
+
 func (m *storeMock) addOutboxMessages(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messages ...OutboundMessage) error {
 	return m.Called(ctx, tx, instanceID, messages).Error(0)
 }
 
-func (m *storeMock) nextOutboxMessage(ctx context.Context, tx pgx.Tx) (OutboundMessage, bool, error) {
-	args := m.Called(ctx, tx)
-	return args.Get(0).(OutboundMessage), args.Bool(1), args.Error(2)
+func (m *storeMock) nextOutboxMessageBatch(ctx context.Context, tx pgx.Tx, limit int) ([]OutboundMessage, error) {
+	args := m.Called(ctx, tx, limit)
+	return args.Get(0).([]OutboundMessage), args.Error(1)
 }
 
-func (m *storeMock) setOutboxMessageStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageID int, status outboxMessageStatus) error {
-	return m.Called(ctx, tx, instanceID, messageID, status).Error(0)
+func (m *storeMock) setOutboxMessagesStatus(ctx context.Context, tx pgx.Tx, instanceID uuid.UUID, messageIDs []int, status outboxMessageStatus) error {
+	return m.Called(ctx, tx, instanceID, messageIDs, status).Error(0)
 }
 
 func TestRunConnector(t *testing.T) {
@@ -109,6 +154,8 @@ func TestRunConnector(t *testing.T) {
 		Return(errors.New("sad life")).Once()
 	c.On("ProcessIncoming", mock.Anything, txSupplier, mock.Anything).
 		Return(errors.New("sad life")).Once()
+	c.On("RunJanitor", mock.Anything, txSupplier).
+		Return(errors.New("sad life")).Once()
 	defer c.AssertExpectations(t)
 
 	go func() {
@@ -126,18 +173,47 @@ type newConnectorSuite struct {
 }
 
 func (suite *newConnectorSuite) TestNilLogger() {
-	c, err := newConnector(nil, nil)
+	c, err := newConnector(nil, nil, ConnectorConfig{})
 	suite.Require().NoError(err, "should not fail")
 	suite.NotNil(c.logger, "should have set shared debug logger")
 }
 
+func (suite *newConnectorSuite) TestDefaultInboxMaxAttempts() {
+	c, err := newConnector(zap.NewNop(), &storeMock{}, ConnectorConfig{})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(DefaultInboxMaxAttempts, c.inboxMaxAttempts, "should have set default max attempts")
+}
+
+func (suite *newConnectorSuite) TestDefaultRetention() {
+	c, err := newConnector(zap.NewNop(), &storeMock{}, ConnectorConfig{})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(RetentionConfig{
+		ProcessedInbox: DefaultProcessedInboxRetention,
+		SkippedInbox:   DefaultSkippedInboxRetention,
+		SentOutbox:     DefaultSentOutboxRetention,
+	}, c.retention, "should have set default retention")
+}
+
 func (suite *newConnectorSuite) TestOK() {
-	c, err := newConnector(zap.NewNop(), &storeMock{})
+	c, err := newConnector(zap.NewNop(), &storeMock{}, ConnectorConfig{
+		InboxMaxAttempts: 3,
+		Retention: RetentionConfig{
+			ProcessedInbox: time.Hour,
+			SkippedInbox:   -1,
+			SentOutbox:     2 * time.Hour,
+		},
+	})
 	suite.Require().NoError(err, "should not fail")
 	suite.NotNil(c.logger, "should have set logger")
 	suite.NotEmpty(c.id, "should have set id")
 	suite.NotNil(c.logger, "should have set logger")
 	suite.NotNil(c.store, "should have set store")
+	suite.Equal(3, c.inboxMaxAttempts, "should have set max attempts")
+	suite.Equal(RetentionConfig{
+		ProcessedInbox: time.Hour,
+		SkippedInbox:   -1,
+		SentOutbox:     2 * time.Hour,
+	}, c.retention, "should have set retention")
 }
 
 func Test_newConnector(t *testing.T) {
@@ -149,7 +225,7 @@ func Test_connectorAddOutboxMessages(t *testing.T) {
 	tx := &testutil.DBTx{}
 	messages := []OutboundMessage{{}, {}}
 	s := &storeMock{}
-	c, err := newConnector(zap.NewNop(), s)
+	c, err := newConnector(zap.NewNop(), s, ConnectorConfig{})
 	require.NoError(t, err, "connector creation should not fail")
 	s.On("addOutboxMessages", timeout, tx, c.id, messages).Return(errors.New("sad life"))
 	defer s.AssertExpectations(t)
@@ -166,13 +242,13 @@ func Test_connectorAddOutboxMessages(t *testing.T) {
 // pumpOutgoingSuite tests pumpOutgoing.
 type pumpOutgoingSuite struct {
 	suite.Suite
-	instanceID    uuid.UUID
-	store         *storeMock
-	txSupplier    *testutil.DBTxSupplier
-	writer        *WriterMock
-	logger        *zap.Logger
-	recorder      *zaprec.RecordStore
-	sampleMessage OutboundMessage
+	instanceID  uuid.UUID
+	store       *storeMock
+	txSupplier  *testutil.DBTxSupplier
+	writer      *WriterMock
+	logger      *zap.Logger
+	recorder    *zaprec.RecordStore
+	sampleBatch []OutboundMessage
 }
 
 func (suite *pumpOutgoingSuite) SetupTest() {
@@ -181,13 +257,23 @@ func (suite *pumpOutgoingSuite) SetupTest() {
 	suite.txSupplier = &testutil.DBTxSupplier{}
 	suite.writer = &WriterMock{}
 	suite.logger, suite.recorder = zaprec.NewRecorder(zap.ErrorLevel)
-	suite.sampleMessage = OutboundMessage{
-		id:        14,
-		Topic:     "read",
-		Key:       "heaven",
-		EventType: "because",
-		Value:     OutboundMessage{},
-		Headers:   nil,
+	suite.sampleBatch = []OutboundMessage{
+		{
+			id:        14,
+			Topic:     "read",
+			Key:       "heaven",
+			EventType: "because",
+			Value:     OutboundMessage{},
+			Headers:   nil,
+		},
+		{
+			id:        17,
+			Topic:     "read",
+			Key:       "heaven",
+			EventType: "stairs",
+			Value:     OutboundMessage{},
+			Headers:   nil,
+		},
 	}
 }
 
@@ -215,8 +301,8 @@ func (suite *pumpOutgoingSuite) TestTxFail() {
 func (suite *pumpOutgoingSuite) TestNextFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.txSupplier.Tx = []*testutil.DBTx{{}}
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[0]).
-		Return(OutboundMessage{}, false, errors.New("sad life"))
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[0], writerBatchSize).
+		Return([]OutboundMessage{}, errors.New("sad life"))
 	defer suite.store.AssertExpectations(suite.T())
 
 	go func() {
@@ -232,8 +318,8 @@ func (suite *pumpOutgoingSuite) TestNextFail() {
 func (suite *pumpOutgoingSuite) TestNoNext() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.txSupplier.Tx = []*testutil.DBTx{{}}
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[0]).
-		Return(OutboundMessage{}, false, nil)
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[0], writerBatchSize).
+		Return([]OutboundMessage{}, nil)
 	defer suite.store.AssertExpectations(suite.T())
 
 	go func() {
@@ -249,8 +335,8 @@ func (suite *pumpOutgoingSuite) TestNoNext() {
 func (suite *pumpOutgoingSuite) TestWriteFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.txSupplier.Tx = []*testutil.DBTx{{}}
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[0]).
-		Return(suite.sampleMessage, true, nil)
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[0], writerBatchSize).
+		Return(suite.sampleBatch, nil)
 	suite.writer.On("WriteMessages", mock.Anything, mock.Anything).
 		Return(errors.New("sad life"))
 	defer suite.store.AssertExpectations(suite.T())
@@ -269,12 +355,12 @@ func (suite *pumpOutgoingSuite) TestWriteFail() {
 func (suite *pumpOutgoingSuite) TestSentStatusUpdateFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.txSupplier.Tx = []*testutil.DBTx{{}}
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[0]).
-		Return(suite.sampleMessage, true, nil)
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[0], writerBatchSize).
+		Return(suite.sampleBatch, nil)
 	suite.writer.On("WriteMessages", mock.Anything, mock.Anything).
 		Return(nil)
-	suite.store.On("setOutboxMessageStatus", mock.Anything, suite.txSupplier.Tx[0], suite.instanceID,
-		suite.sampleMessage.id, outboxMessageStatusSent).
+	suite.store.On("setOutboxMessagesStatus", mock.Anything, suite.txSupplier.Tx[0], suite.instanceID,
+		[]int{14, 17}, outboxMessageStatusSent).
 		Return(errors.New("sad life"))
 	defer suite.store.AssertExpectations(suite.T())
 	defer suite.writer.AssertExpectations(suite.T())
@@ -292,15 +378,16 @@ func (suite *pumpOutgoingSuite) TestSentStatusUpdateFail() {
 func (suite *pumpOutgoingSuite) TestOK() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
 	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}} // 2 because of immediate proceed.
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[0]).
-		Return(suite.sampleMessage, true, nil).Once()
-	suite.writer.On("WriteMessages", mock.Anything, mock.Anything).
-		Return(nil)
-	suite.store.On("setOutboxMessageStatus", mock.Anything, suite.txSupplier.Tx[0], suite.instanceID,
-		suite.sampleMessage.id, outboxMessageStatusSent).
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[0], writerBatchSize).
+		Return(suite.sampleBatch, nil).Once()
+	suite.writer.On("WriteMessages", mock.Anything, mock.MatchedBy(func(messages []kafka.Message) bool {
+		return len(messages) == len(suite.sampleBatch)
+	})).Return(nil).Once()
+	suite.store.On("setOutboxMessagesStatus", mock.Anything, suite.txSupplier.Tx[0], suite.instanceID,
+		[]int{14, 17}, outboxMessageStatusSent).
 		Return(nil)
-	suite.store.On("nextOutboxMessage", mock.Anything, suite.txSupplier.Tx[1]).
-		Return(OutboundMessage{}, false, nil).Once()
+	suite.store.On("nextOutboxMessageBatch", mock.Anything, suite.txSupplier.Tx[1], writerBatchSize).
+		Return([]OutboundMessage{}, nil).Once()
 	defer suite.store.AssertExpectations(suite.T())
 	defer suite.writer.AssertExpectations(suite.T())
 
@@ -337,7 +424,7 @@ func (suite *connectorReadSuite) SetupTest() {
 	suite.txSupplier = &testutil.DBTxSupplier{}
 	suite.reader = &ReaderMock{}
 	var err error
-	suite.c, err = newConnector(suite.logger, suite.store)
+	suite.c, err = newConnector(suite.logger, suite.store, ConnectorConfig{})
 	suite.Require().NoError(err, "connector creation should not fail")
 	suite.sampleKafkaMessage = kafka.Message{
 		Topic:         "read",
@@ -397,7 +484,7 @@ func (suite *connectorReadSuite) TestAddFail() {
 		Return(suite.sampleKafkaMessage, nil)
 	suite.store.On("addInboxMessages", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
 		[]InboundMessage{suite.sampleMessage}).
-		Return(errors.New("sad life"))
+		Return(0, errors.New("sad life"))
 	defer suite.reader.AssertExpectations(suite.T())
 	defer suite.store.AssertExpectations(suite.T())
 
@@ -419,7 +506,7 @@ func (suite *connectorReadSuite) TestCommitFail() {
 		Return(suite.sampleKafkaMessage, nil)
 	suite.store.On("addInboxMessages", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
 		[]InboundMessage{suite.sampleMessage}).
-		Return(nil)
+		Return(1, nil)
 	suite.reader.On("CommitMessages", mock.Anything, []kafka.Message{suite.sampleKafkaMessage}).
 		Return(errors.New("sad life"))
 	defer suite.reader.AssertExpectations(suite.T())
@@ -445,7 +532,32 @@ func (suite *connectorReadSuite) TestOK() {
 		Return(kafka.Message{}, context.Canceled).Once()
 	suite.store.On("addInboxMessages", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
 		[]InboundMessage{suite.sampleMessage}).
+		Return(1, nil)
+	suite.reader.On("CommitMessages", mock.Anything, []kafka.Message{suite.sampleKafkaMessage}).
 		Return(nil)
+	defer suite.reader.AssertExpectations(suite.T())
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.test(timeout)
+		suite.NoError(err, "should not fail")
+		suite.True(suite.txSupplier.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *connectorReadSuite) TestDuplicate() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
+	suite.reader.On("FetchMessage", mock.Anything).
+		Return(suite.sampleKafkaMessage, nil).Once()
+	suite.reader.On("FetchMessage", mock.Anything).
+		Return(kafka.Message{}, context.Canceled).Once()
+	suite.store.On("addInboxMessages", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		[]InboundMessage{suite.sampleMessage}).
+		Return(0, nil)
 	suite.reader.On("CommitMessages", mock.Anything, []kafka.Message{suite.sampleKafkaMessage}).
 		Return(nil)
 	defer suite.reader.AssertExpectations(suite.T())
@@ -456,6 +568,7 @@ func (suite *connectorReadSuite) TestOK() {
 		err := suite.test(timeout)
 		suite.NoError(err, "should not fail")
 		suite.True(suite.txSupplier.Tx[0].IsCommitted, "should commit tx")
+		suite.EqualValues(1, suite.c.skippedDuplicates.Load(), "should count skipped duplicate")
 	}()
 
 	wait()
@@ -494,7 +607,7 @@ func (suite *connectorProcessIncomingSuite) SetupTest() {
 	suite.reader = &ReaderMock{}
 	suite.handler = &handlerFnMock{}
 	var err error
-	suite.c, err = newConnector(suite.logger, suite.store)
+	suite.c, err = newConnector(suite.logger, suite.store, ConnectorConfig{})
 	suite.Require().NoError(err, "connector creation should not fail")
 	suite.sampleMessage = InboundMessage{
 		id:            665,
@@ -567,13 +680,78 @@ func (suite *connectorProcessIncomingSuite) TestNoNext() {
 	wait()
 }
 
+func (suite *connectorProcessIncomingSuite) TestUpcastFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
+	suite.c.schemas = event.NewRegistry()
+	suite.c.schemas.Register(suite.sampleMessage.EventType, 2, func() any { return &struct{}{} })
+	suite.c.schemas.RegisterUpcaster(suite.sampleMessage.EventType, 1, func(_ json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("sad life")
+	})
+	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
+		Return(suite.sampleMessage, true, nil).Once()
+	suite.store.On("recordInboxMessageFailure", mock.Anything, suite.txSupplier.Tx[1], suite.c.id,
+		suite.sampleMessage.id, mock.Anything, DefaultInboxMaxAttempts).
+		Return(false, nil).Once()
+	defer suite.store.AssertExpectations(suite.T())
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.test(timeout)
+		suite.NoError(err, "should not fail")
+		suite.Len(suite.recorder.Records(), 1, "should have logged errors")
+		suite.False(suite.txSupplier.Tx[0].IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *connectorProcessIncomingSuite) TestUpcast() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
+	suite.sampleMessage.SchemaVersion = 1
+	suite.c.schemas = event.NewRegistry()
+	suite.c.schemas.Register(suite.sampleMessage.EventType, 2, func() any { return &struct{}{} })
+	suite.c.schemas.RegisterUpcaster(suite.sampleMessage.EventType, 1, func(_ json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"hello":"upcasted"}`), nil
+	})
+	upcasted := suite.sampleMessage
+	upcasted.RawValue = json.RawMessage(`{"hello":"upcasted"}`)
+	upcasted.SchemaVersion = 2
+	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
+		Return(suite.sampleMessage, true, nil).Once()
+	suite.handler.On("fn", mock.Anything, suite.txSupplier.Tx[0], upcasted).
+		Return(nil)
+	suite.store.On("setInboxMessageStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
+		suite.sampleMessage.id, inboxMessageStatusProcessed).
+		Return(nil)
+	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[1], false).
+		Return(InboundMessage{}, false, nil).Once()
+	defer suite.store.AssertExpectations(suite.T())
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.test(timeout)
+		suite.NoError(err, "should not fail")
+		suite.Empty(suite.recorder.Records(), "should not have logged errors")
+		suite.True(suite.txSupplier.Tx[0].IsCommitted, "should commit tx")
+	}()
+
+	wait()
+}
+
 func (suite *connectorProcessIncomingSuite) TestHandleFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
-	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
 	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
 		Return(suite.sampleMessage, true, nil).Once()
 	suite.handler.On("fn", mock.Anything, suite.txSupplier.Tx[0], suite.sampleMessage).
 		Return(errors.New("sad life"))
+	suite.store.On("recordInboxMessageFailure", mock.Anything, suite.txSupplier.Tx[1], suite.c.id,
+		suite.sampleMessage.id, mock.Anything, DefaultInboxMaxAttempts).
+		Return(false, nil).Once()
 	defer suite.store.AssertExpectations(suite.T())
 	defer suite.handler.AssertExpectations(suite.T())
 
@@ -583,6 +761,55 @@ func (suite *connectorProcessIncomingSuite) TestHandleFail() {
 		suite.NoError(err, "should not fail")
 		suite.Len(suite.recorder.Records(), 1, "should have logged errors")
 		suite.False(suite.txSupplier.Tx[0].IsCommitted, "should not commit tx")
+		suite.True(suite.txSupplier.Tx[1].IsCommitted, "should commit failure tx")
+	}()
+
+	wait()
+}
+
+func (suite *connectorProcessIncomingSuite) TestHandleFailRecordFailureFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
+	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
+		Return(suite.sampleMessage, true, nil).Once()
+	suite.handler.On("fn", mock.Anything, suite.txSupplier.Tx[0], suite.sampleMessage).
+		Return(errors.New("sad life"))
+	suite.store.On("recordInboxMessageFailure", mock.Anything, suite.txSupplier.Tx[1], suite.c.id,
+		suite.sampleMessage.id, mock.Anything, DefaultInboxMaxAttempts).
+		Return(false, errors.New("sad life")).Once()
+	defer suite.store.AssertExpectations(suite.T())
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.test(timeout)
+		suite.NoError(err, "should not fail")
+		suite.Len(suite.recorder.Records(), 2, "should have logged both errors")
+		suite.False(suite.txSupplier.Tx[1].IsCommitted, "should not commit failure tx")
+	}()
+
+	wait()
+}
+
+func (suite *connectorProcessIncomingSuite) TestHandleFailDeadLettered() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
+	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
+		Return(suite.sampleMessage, true, nil).Once()
+	suite.handler.On("fn", mock.Anything, suite.txSupplier.Tx[0], suite.sampleMessage).
+		Return(errors.New("sad life"))
+	suite.store.On("recordInboxMessageFailure", mock.Anything, suite.txSupplier.Tx[1], suite.c.id,
+		suite.sampleMessage.id, mock.Anything, DefaultInboxMaxAttempts).
+		Return(true, nil).Once()
+	defer suite.store.AssertExpectations(suite.T())
+	defer suite.handler.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.test(timeout)
+		suite.NoError(err, "should not fail")
+		suite.Len(suite.recorder.Records(), 1, "should have logged errors")
+		suite.True(suite.txSupplier.Tx[1].IsCommitted, "should commit failure tx")
 	}()
 
 	wait()
@@ -590,7 +817,7 @@ func (suite *connectorProcessIncomingSuite) TestHandleFail() {
 
 func (suite *connectorProcessIncomingSuite) TestProcessedStatusUpdateFail() {
 	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
-	suite.txSupplier.Tx = []*testutil.DBTx{{}}
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}}
 	suite.store.On("nextInboxMessage", mock.Anything, suite.txSupplier.Tx[0], false).
 		Return(suite.sampleMessage, true, nil).Once()
 	suite.handler.On("fn", mock.Anything, suite.txSupplier.Tx[0], suite.sampleMessage).
@@ -598,6 +825,9 @@ func (suite *connectorProcessIncomingSuite) TestProcessedStatusUpdateFail() {
 	suite.store.On("setInboxMessageStatus", mock.Anything, suite.txSupplier.Tx[0], suite.c.id,
 		suite.sampleMessage.id, inboxMessageStatusProcessed).
 		Return(errors.New("sad life"))
+	suite.store.On("recordInboxMessageFailure", mock.Anything, suite.txSupplier.Tx[1], suite.c.id,
+		suite.sampleMessage.id, mock.Anything, DefaultInboxMaxAttempts).
+		Return(false, nil).Once()
 	defer suite.store.AssertExpectations(suite.T())
 	defer suite.handler.AssertExpectations(suite.T())
 
@@ -0,0 +1,313 @@
+package kafkautil
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/meh/mehlog"
+	"github.com/lefinal/meh/mehpg"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// DefaultProcessedInboxRetention is the default retention for processed inbox
+	// messages.
+	DefaultProcessedInboxRetention = 7 * 24 * time.Hour
+	// DefaultSkippedInboxRetention is the default retention for skipped inbox
+	// messages.
+	DefaultSkippedInboxRetention = 30 * 24 * time.Hour
+	// DefaultSentOutboxRetention is the default retention for sent outbox
+	// messages.
+	DefaultSentOutboxRetention = 7 * 24 * time.Hour
+)
+
+// RetentionConfig holds retention durations for handled inbox and outbox
+// messages. A zero value means that the default one is used. Negative values
+// disable deletion. Pending and dead-lettered messages are never deleted.
+type RetentionConfig struct {
+	// ProcessedInbox is the retention for processed inbox messages.
+	ProcessedInbox time.Duration
+	// SkippedInbox is the retention for inbox messages that were skipped via
+	// DeadLetterAdmin.SkipDeadLetter.
+	SkippedInbox time.Duration
+	// SentOutbox is the retention for sent outbox messages.
+	SentOutbox time.Duration
+}
+
+// withDefaults returns a copy of the RetentionConfig with zero values being
+// replaced with the defaults.
+func (rc RetentionConfig) withDefaults() RetentionConfig {
+	if rc.ProcessedInbox == 0 {
+		rc.ProcessedInbox = DefaultProcessedInboxRetention
+	}
+	if rc.SkippedInbox == 0 {
+		rc.SkippedInbox = DefaultSkippedInboxRetention
+	}
+	if rc.SentOutbox == 0 {
+		rc.SentOutbox = DefaultSentOutboxRetention
+	}
+	return rc
+}
+
+const (
+	// janitorInterval is the interval in which the janitor runs.
+	janitorInterval = 5 * time.Minute
+	// janitorDeleteBatchSize is the maximum number of messages to delete in one
+	// transaction. We keep it small in order to avoid long-running locks.
+	janitorDeleteBatchSize = 500
+)
+
+// messageTableStats holds statistics for the inbox or outbox table.
+type messageTableStats struct {
+	// countByStatus is the number of messages per status.
+	countByStatus map[int]int
+	// oldestPendingTS is the status timestamp of the oldest pending message.
+	oldestPendingTS nulls.Time
+	// sizeBytes is the total size of the table including indices.
+	sizeBytes int64
+}
+
+// lag returns the duration since the oldest pending message was added at the
+// given time. If there are no pending messages, zero is returned. It is only
+// logged by connector.logStats and not exposed as metric.
+func (stats messageTableStats) lag(now time.Time) time.Duration {
+	if !stats.oldestPendingTS.Valid {
+		return 0
+	}
+	return now.Sub(stats.oldestPendingTS.Time)
+}
+
+// RunJanitor periodically deletes handled inbox and outbox messages, that
+// exceeded their retention, and logs table statistics. Errors are logged and
+// the next run is performed as usual.
+func (c *connector) RunJanitor(ctx context.Context, txSupplier pgutil.DBTxSupplier) error {
+	logger := c.logger.Named("janitor")
+	for {
+		err := c.cleanUp(ctx, txSupplier, time.Now().UTC())
+		if err != nil {
+			mehlog.Log(logger, meh.Wrap(err, "clean up", nil))
+		}
+		err = c.logStats(ctx, logger, txSupplier)
+		if err != nil {
+			mehlog.Log(logger, meh.Wrap(err, "log stats", nil))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(janitorInterval):
+		}
+	}
+}
+
+// cleanUp deletes all inbox and outbox messages that exceeded their retention
+// at the given time.
+func (c *connector) cleanUp(ctx context.Context, txSupplier pgutil.DBTxSupplier, now time.Time) error {
+	// Inbox.
+	inboxRetentions := []struct {
+		status    inboxMessageStatus
+		retention time.Duration
+	}{
+		{status: inboxMessageStatusProcessed, retention: c.retention.ProcessedInbox},
+		{status: inboxMessageStatusSkipped, retention: c.retention.SkippedInbox},
+	}
+	for _, inboxRetention := range inboxRetentions {
+		if inboxRetention.retention < 0 {
+			continue
+		}
+		status := inboxRetention.status
+		before := now.Add(-inboxRetention.retention)
+		err := deleteInBatches(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) (int, error) {
+			return c.store.deleteInboxMessagesBefore(ctx, tx, status, before, janitorDeleteBatchSize)
+		})
+		if err != nil {
+			return meh.Wrap(err, "delete inbox messages in batches", meh.Details{
+				"status": status,
+				"before": before,
+			})
+		}
+	}
+	// Outbox.
+	if c.retention.SentOutbox >= 0 {
+		before := now.Add(-c.retention.SentOutbox)
+		err := deleteInBatches(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) (int, error) {
+			return c.store.deleteOutboxMessagesBefore(ctx, tx, outboxMessageStatusSent, before, janitorDeleteBatchSize)
+		})
+		if err != nil {
+			return meh.Wrap(err, "delete outbox messages in batches", meh.Details{"before": before})
+		}
+	}
+	return nil
+}
+
+// deleteInBatches calls the given function in a separate transaction each, until
+// less than janitorDeleteBatchSize messages were deleted.
+func deleteInBatches(ctx context.Context, txSupplier pgutil.DBTxSupplier, deleteFn func(ctx context.Context, tx pgx.Tx) (int, error)) error {
+	for {
+		var deleted int
+		err := pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+			var err error
+			deleted, err = deleteFn(ctx, tx)
+			if err != nil {
+				return meh.Wrap(err, "delete", nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return meh.Wrap(err, "run in tx", nil)
+		}
+		if deleted < janitorDeleteBatchSize {
+			return nil
+		}
+	}
+}
+
+// logStats retrieves messageTableStats for the inbox and outbox table and logs
+// them. Services do not expose any metrics, so logging is the only way of
+// publishing them.
+func (c *connector) logStats(ctx context.Context, logger *zap.Logger, txSupplier pgutil.DBTxSupplier) error {
+	var inboxStats messageTableStats
+	var outboxStats messageTableStats
+	err := pgutil.RunInTx(ctx, txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		inboxStats, outboxStats, err = c.store.messageTableStats(ctx, tx)
+		if err != nil {
+			return meh.Wrap(err, "message table stats from store", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return meh.Wrap(err, "run in tx", nil)
+	}
+	now := time.Now()
+	logger.Info("inbox stats",
+		zap.Int("pending", inboxStats.countByStatus[int(inboxMessageStatusPending)]),
+		zap.Int("processed", inboxStats.countByStatus[int(inboxMessageStatusProcessed)]),
+		zap.Int("skipped", inboxStats.countByStatus[int(inboxMessageStatusSkipped)]),
+		zap.Int("dead_lettered", inboxStats.countByStatus[int(inboxMessageStatusDeadLettered)]),
+		zap.Any("oldest_pending_ts", inboxStats.oldestPendingTS),
+		zap.Duration("lag", inboxStats.lag(now)),
+		zap.Int64("size_bytes", inboxStats.sizeBytes),
+		zap.Uint64("skipped_duplicates", c.skippedDuplicates.Load()))
+	logger.Info("outbox stats",
+		zap.Int("pending", outboxStats.countByStatus[int(outboxMessageStatusPending)]),
+		zap.Int("sent", outboxStats.countByStatus[int(outboxMessageStatusSent)]),
+		zap.Any("oldest_pending_ts", outboxStats.oldestPendingTS),
+		zap.Duration("lag", outboxStats.lag(now)),
+		zap.Int64("size_bytes", outboxStats.sizeBytes))
+	return nil
+}
+
+// deleteMessagesBefore deletes at most the given limit of messages from the
+// given table with the given status, having their status updated before the
+// given time. Rows, locked by other transactions, are skipped.
+func (s *dbStore) deleteMessagesBefore(ctx context.Context, tx pgx.Tx, table string, status int, before time.Time, limit int) (int, error) {
+	toDelete := s.dialect.From(goqu.T(table)).
+		Select(goqu.C("id")).
+		Where(goqu.C("status").Eq(status),
+			goqu.C("status_ts").Lt(before.UTC())).
+		Limit(uint(limit)).
+		ForUpdate(exp.SkipLocked)
+	q, _, err := s.dialect.Delete(goqu.T(table)).
+		Where(goqu.C("id").In(toDelete)).ToSQL()
+	if err != nil {
+		return 0, meh.NewInternalErrFromErr(err, "query to sql", nil)
+	}
+	result, err := tx.Exec(ctx, q)
+	if err != nil {
+		return 0, mehpg.NewQueryDBErr(err, "exec query", q)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// deleteInboxMessagesBefore deletes at most the given limit of inbox messages
+// with the given status, having their status updated before the given time.
+func (s *dbStore) deleteInboxMessagesBefore(ctx context.Context, tx pgx.Tx, status inboxMessageStatus, before time.Time, limit int) (int, error) {
+	deleted, err := s.deleteMessagesBefore(ctx, tx, "__message_inbox", int(status), before, limit)
+	if err != nil {
+		return 0, meh.Wrap(err, "delete messages before", nil)
+	}
+	return deleted, nil
+}
+
+// deleteOutboxMessagesBefore deletes at most the given limit of outbox messages
+// with the given status, having their status updated before the given time.
+func (s *dbStore) deleteOutboxMessagesBefore(ctx context.Context, tx pgx.Tx, status outboxMessageStatus, before time.Time, limit int) (int, error) {
+	deleted, err := s.deleteMessagesBefore(ctx, tx, "__message_outbox", int(status), before, limit)
+	if err != nil {
+		return 0, meh.Wrap(err, "delete messages before", nil)
+	}
+	return deleted, nil
+}
+
+// tableStats retrieves messageTableStats for the given table.
+func (s *dbStore) tableStats(ctx context.Context, tx pgx.Tx, table string) (messageTableStats, error) {
+	stats := messageTableStats{
+		countByStatus: make(map[int]int),
+	}
+	// Count by status.
+	countQuery, _, err := s.dialect.From(goqu.T(table)).
+		Select(goqu.C("status"),
+			goqu.COUNT("*"),
+			goqu.MIN(goqu.C("status_ts"))).
+		GroupBy(goqu.C("status")).ToSQL()
+	if err != nil {
+		return messageTableStats{}, meh.NewInternalErrFromErr(err, "count-query to sql", nil)
+	}
+	countRows, err := tx.Query(ctx, countQuery)
+	if err != nil {
+		return messageTableStats{}, mehpg.NewQueryDBErr(err, "exec count-query", countQuery)
+	}
+	defer countRows.Close()
+	for countRows.Next() {
+		var status int
+		var count int
+		var oldestTS time.Time
+		err = countRows.Scan(&status, &count, &oldestTS)
+		if err != nil {
+			return messageTableStats{}, mehpg.NewScanRowsErr(err, "scan count-row", countQuery)
+		}
+		stats.countByStatus[status] = count
+		// Pending status is zero for both inbox and outbox.
+		if status == int(inboxMessageStatusPending) {
+			stats.oldestPendingTS = nulls.NewTime(oldestTS)
+		}
+	}
+	countRows.Close()
+	// Size.
+	sizeQuery, _, err := s.dialect.Select(goqu.Func("pg_total_relation_size", table)).ToSQL()
+	if err != nil {
+		return messageTableStats{}, meh.NewInternalErrFromErr(err, "size-query to sql", nil)
+	}
+	sizeRows, err := tx.Query(ctx, sizeQuery)
+	if err != nil {
+		return messageTableStats{}, mehpg.NewQueryDBErr(err, "exec size-query", sizeQuery)
+	}
+	defer sizeRows.Close()
+	if !sizeRows.Next() {
+		return messageTableStats{}, meh.NewInternalErr("no rows for size-query", meh.Details{"query": sizeQuery})
+	}
+	err = sizeRows.Scan(&stats.sizeBytes)
+	if err != nil {
+		return messageTableStats{}, mehpg.NewScanRowsErr(err, "scan size-row", sizeQuery)
+	}
+	sizeRows.Close()
+	return stats, nil
+}
+
+// messageTableStats retrieves messageTableStats for the inbox and outbox table.
+func (s *dbStore) messageTableStats(ctx context.Context, tx pgx.Tx) (messageTableStats, messageTableStats, error) {
+	inboxStats, err := s.tableStats(ctx, tx, "__message_inbox")
+	if err != nil {
+		return messageTableStats{}, messageTableStats{}, meh.Wrap(err, "inbox table stats", nil)
+	}
+	outboxStats, err := s.tableStats(ctx, tx, "__message_outbox")
+	if err != nil {
+		return messageTableStats{}, messageTableStats{}, meh.Wrap(err, "outbox table stats", nil)
+	}
+	return inboxStats, outboxStats, nil
+}
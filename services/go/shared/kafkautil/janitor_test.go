@@ -0,0 +1,182 @@
+package kafkautil
+
+import (
+	"context"
+	"errors"
+	"github.com/lefinal/nulls"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// connectorCleanUpSuite tests connector.cleanUp.
+type connectorCleanUpSuite struct {
+	suite.Suite
+	c          *connector
+	store      *storeMock
+	txSupplier *testutil.DBTxSupplier
+	now        time.Time
+}
+
+func (suite *connectorCleanUpSuite) SetupTest() {
+	suite.store = &storeMock{}
+	suite.txSupplier = &testutil.DBTxSupplier{}
+	var err error
+	suite.c, err = newConnector(zap.NewNop(), suite.store, ConnectorConfig{
+		Retention: RetentionConfig{
+			ProcessedInbox: time.Hour,
+			SkippedInbox:   2 * time.Hour,
+			SentOutbox:     3 * time.Hour,
+		},
+	})
+	suite.Require().NoError(err, "connector creation should not fail")
+	suite.now = time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *connectorCleanUpSuite) TestTxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.BeginFail = true
+
+	go func() {
+		defer cancel()
+		err := suite.c.cleanUp(timeout, suite.txSupplier, suite.now)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *connectorCleanUpSuite) TestDeleteInboxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	tx := &testutil.DBTx{}
+	suite.txSupplier.Tx = []*testutil.DBTx{tx}
+	suite.store.On("deleteInboxMessagesBefore", mock.Anything, tx, inboxMessageStatusProcessed,
+		suite.now.Add(-time.Hour), janitorDeleteBatchSize).
+		Return(0, errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.c.cleanUp(timeout, suite.txSupplier, suite.now)
+		suite.Error(err, "should fail")
+		suite.False(tx.IsCommitted, "should not commit tx")
+	}()
+
+	wait()
+}
+
+func (suite *connectorCleanUpSuite) TestDeleteOutboxFail() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}, {}}
+	suite.store.On("deleteInboxMessagesBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(0, nil)
+	suite.store.On("deleteOutboxMessagesBefore", mock.Anything, suite.txSupplier.Tx[2], outboxMessageStatusSent,
+		suite.now.Add(-3*time.Hour), janitorDeleteBatchSize).
+		Return(0, errors.New("sad life"))
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.c.cleanUp(timeout, suite.txSupplier, suite.now)
+		suite.Error(err, "should fail")
+	}()
+
+	wait()
+}
+
+func (suite *connectorCleanUpSuite) TestDisabled() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.c.retention = RetentionConfig{
+		ProcessedInbox: -1,
+		SkippedInbox:   -1,
+		SentOutbox:     -1,
+	}
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.c.cleanUp(timeout, suite.txSupplier, suite.now)
+		suite.NoError(err, "should not fail")
+	}()
+
+	wait()
+}
+
+func (suite *connectorCleanUpSuite) TestOK() {
+	timeout, cancel, wait := testutil.NewTimeout(suite, timeout)
+	suite.txSupplier.Tx = []*testutil.DBTx{{}, {}, {}, {}, {}}
+	// Processed inbox messages need two batches.
+	suite.store.On("deleteInboxMessagesBefore", mock.Anything, suite.txSupplier.Tx[0], inboxMessageStatusProcessed,
+		suite.now.Add(-time.Hour), janitorDeleteBatchSize).
+		Return(janitorDeleteBatchSize, nil).Once()
+	suite.store.On("deleteInboxMessagesBefore", mock.Anything, suite.txSupplier.Tx[1], inboxMessageStatusProcessed,
+		suite.now.Add(-time.Hour), janitorDeleteBatchSize).
+		Return(12, nil).Once()
+	suite.store.On("deleteInboxMessagesBefore", mock.Anything, suite.txSupplier.Tx[2], inboxMessageStatusSkipped,
+		suite.now.Add(-2*time.Hour), janitorDeleteBatchSize).
+		Return(0, nil).Once()
+	suite.store.On("deleteOutboxMessagesBefore", mock.Anything, suite.txSupplier.Tx[3], outboxMessageStatusSent,
+		suite.now.Add(-3*time.Hour), janitorDeleteBatchSize).
+		Return(3, nil).Once()
+	defer suite.store.AssertExpectations(suite.T())
+
+	go func() {
+		defer cancel()
+		err := suite.c.cleanUp(timeout, suite.txSupplier, suite.now)
+		suite.Require().NoError(err, "should not fail")
+		for i := 0; i < 4; i++ {
+			suite.True(suite.txSupplier.Tx[i].IsCommitted, "should commit tx")
+		}
+	}()
+
+	wait()
+}
+
+func Test_connectorCleanUp(t *testing.T) {
+	suite.Run(t, new(connectorCleanUpSuite))
+}
+
+func TestConnector_RunJanitor(t *testing.T) {
+	timeout, cancel, wait := testutil.NewTimeout(testutil.TestFailerFromT(t), timeout)
+	s := &storeMock{}
+	c, err := newConnector(zap.NewNop(), s, ConnectorConfig{})
+	require.NoError(t, err, "connector creation should not fail")
+	txSupplier := &testutil.DBTxSupplier{Tx: []*testutil.DBTx{{}, {}, {}, {}}}
+	janitorCtx, cancelJanitor := context.WithCancel(timeout)
+	defer cancelJanitor()
+	s.On("deleteInboxMessagesBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(0, nil).Twice()
+	s.On("deleteOutboxMessagesBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(0, nil).Once()
+	s.On("messageTableStats", mock.Anything, txSupplier.Tx[3]).
+		Return(messageTableStats{
+			countByStatus:   map[int]int{0: 2, 200: 10},
+			oldestPendingTS: nulls.NewTime(time.Now()),
+			sizeBytes:       1024,
+		}, messageTableStats{}, nil).
+		Run(func(_ mock.Arguments) {
+			cancelJanitor()
+		}).Once()
+	defer s.AssertExpectations(t)
+
+	go func() {
+		defer cancel()
+		err := c.RunJanitor(janitorCtx, txSupplier)
+		assert.NoError(t, err, "should not fail")
+	}()
+
+	wait()
+}
+
+func Test_messageTableStatsLag(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	assert.Zero(t, messageTableStats{}.lag(now), "should return zero without pending messages")
+	assert.Equal(t, 3*time.Minute, messageTableStats{
+		oldestPendingTS: nulls.NewTime(now.Add(-3 * time.Minute)),
+	}.lag(now), "should return duration since oldest pending message")
+}
@@ -4,19 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/lefinal/meh"
 	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
+	"strconv"
 	"time"
 )
 
-// writerBatchSize is the size for event batches to use for writing. We set this
-// to a low value because of the outbox-pattern and concurrent message sending,
-// leading to multiple open connections for pumps. This means, writerBatchSize
-// is also used in Connector.PumpOutgoing.
-const writerBatchSize = 4
+// writerBatchSize is the size for event batches to use for writing. It is also
+// used as the maximum number of messages, Connector.PumpOutgoing claims per
+// segment and writes at once, so that a batch is written in a single request.
+const writerBatchSize = 16
 
 // Writer is an abstraction for kafka.Writer for writing messages.
 type Writer interface {
@@ -32,6 +33,14 @@ const writeMessagesTimeout = 10 * time.Second
 // stored in.
 const kafkaMessageEventTypeHeader = "event-type"
 
+// kafkaMessageEventIDHeader is the header name where the event id, assigned by
+// the outbox, is stored in. It is used for deduplication in the inbox.
+const kafkaMessageEventIDHeader = "event-id"
+
+// kafkaMessageEventSchemaVersionHeader is the header name where the
+// event.Version of the message value is stored in.
+const kafkaMessageEventSchemaVersionHeader = "event-schema-version"
+
 // InboundMessage acts as a replacement of kafka.Message for easier usage with
 // received messages.
 type InboundMessage struct {
@@ -51,6 +60,13 @@ type InboundMessage struct {
 	Key string
 	// EventType is the type of event, taken from/put into Headers.
 	EventType event.Type
+	// EventID is the optional id of the event, taken from Headers. It is set by
+	// the outbox of the sending service.
+	EventID uuid.NullUUID
+	// SchemaVersion is the event.Version of RawValue, taken from Headers. If not
+	// provided, it is event.InitialVersion. Before passing messages to a
+	// HandlerFunc, RawValue is upcasted to the current version.
+	SchemaVersion event.Version
 	// RawValue is the marshalled Value.
 	RawValue json.RawMessage
 	// Headers for the message (translated to and from kafka.Header).
@@ -61,6 +77,8 @@ type InboundMessage struct {
 // outbound messages.
 type OutboundMessage struct {
 	id int
+	// eventID is assigned by the outbox and put into Headers.
+	eventID uuid.UUID
 	// Topic indicates the topic the message was consumed from or should be written
 	// to if not specified otherwise.
 	Topic event.Topic
@@ -92,11 +110,29 @@ func inboundMessageFromKafkaMessage(kafkaMessage kafka.Message) InboundMessage {
 		TS:            kafkaMessage.Time,
 		Key:           string(kafkaMessage.Key),
 		EventType:     eventType,
+		EventID:       eventIDFromHeaders(headers),
+		SchemaVersion: schemaVersionFromHeaders(headers),
 		RawValue:      kafkaMessage.Value,
 		Headers:       headers,
 	}
 }
 
+// eventIDFromHeaders extracts the event id from the kafkaMessageEventIDHeader in
+// the given MessageHeader list. If not found or invalid, it is not valid.
+func eventIDFromHeaders(headers []MessageHeader) uuid.NullUUID {
+	for _, header := range headers {
+		if header.Key != kafkaMessageEventIDHeader {
+			continue
+		}
+		eventID, err := uuid.FromString(header.Value)
+		if err != nil {
+			return uuid.NullUUID{}
+		}
+		return uuid.NullUUID{UUID: eventID, Valid: true}
+	}
+	return uuid.NullUUID{}
+}
+
 // headersFromKafkaHeaders converts a kafka.Header list to MessageHeader list.
 // Additionally, the event.Type is extracted from kafkaMessageEventTypeHeader.
 func headersFromKafkaHeaders(kafkaHeaders []kafka.Header) ([]MessageHeader, event.Type) {
@@ -131,8 +167,28 @@ func kafkaHeadersFromHeaders(headers []MessageHeader, eventType event.Type) []ka
 	return kafkaHeaders
 }
 
+// schemaVersionFromHeaders extracts the event.Version from the
+// kafkaMessageEventSchemaVersionHeader in the given MessageHeader list. If not
+// found or invalid, event.InitialVersion is returned.
+func schemaVersionFromHeaders(headers []MessageHeader) event.Version {
+	for _, header := range headers {
+		if header.Key != kafkaMessageEventSchemaVersionHeader {
+			continue
+		}
+		version, err := strconv.Atoi(header.Value)
+		if err != nil || event.Version(version) < event.InitialVersion {
+			return event.InitialVersion
+		}
+		return event.Version(version)
+	}
+	return event.InitialVersion
+}
+
 // KafkaMessageFromOutboundMessage converts an OutboundMessage to kafka.Message
-// and marshals the OutboundMessage.Value as JSON if not nil.
+// and marshals the OutboundMessage.Value as JSON if not nil. If the message was
+// retrieved from the outbox, the event id is added via
+// kafkaMessageEventIDHeader. If the event type is registered in event.Schemas,
+// its current version is added via kafkaMessageEventSchemaVersionHeader.
 func KafkaMessageFromOutboundMessage(message OutboundMessage) (kafka.Message, error) {
 	var rawMessageValue json.RawMessage
 	if message.Value != nil {
@@ -142,11 +198,24 @@ func KafkaMessageFromOutboundMessage(message OutboundMessage) (kafka.Message, er
 			return kafka.Message{}, meh.NewInternalErrFromErr(err, "marshal message value", nil)
 		}
 	}
+	headers := kafkaHeadersFromHeaders(message.Headers, message.EventType)
+	if !message.eventID.IsNil() {
+		headers = append(headers, kafka.Header{
+			Key:   kafkaMessageEventIDHeader,
+			Value: []byte(message.eventID.String()),
+		})
+	}
+	if version, ok := event.Schemas.Version(message.EventType); ok {
+		headers = append(headers, kafka.Header{
+			Key:   kafkaMessageEventSchemaVersionHeader,
+			Value: []byte(strconv.Itoa(int(version))),
+		})
+	}
 	return kafka.Message{
 		Topic:   string(message.Topic),
 		Key:     []byte(message.Key),
 		Value:   rawMessageValue,
-		Headers: kafkaHeadersFromHeaders(message.Headers, message.EventType),
+		Headers: headers,
 	}, nil
 }
 
@@ -180,6 +249,9 @@ func NewWriter(logger *zap.Logger, addr string) Writer {
 		MaxAttempts:  16,
 		BatchTimeout: 50 * time.Millisecond,
 		BatchSize:    writerBatchSize,
+		// Messages with the same key need to be written to the same partition in order
+		// to preserve ordering.
+		Balancer: &kafka.Hash{},
 	}
 }
 
@@ -1,5 +1,108 @@
 package kafkautil
 
-import "time"
+import (
+	"github.com/gofrs/uuid"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/event"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
 
 const timeout = 5 * time.Second
+
+func Test_inboundMessageFromKafkaMessageEventID(t *testing.T) {
+	eventID := testutil.NewUUIDV4()
+	m := inboundMessageFromKafkaMessage(kafka.Message{
+		Headers: []kafka.Header{
+			{Key: kafkaMessageEventTypeHeader, Value: []byte("dance")},
+			{Key: kafkaMessageEventIDHeader, Value: []byte(eventID.String())},
+		},
+	})
+	assert.Equal(t, uuid.NullUUID{UUID: eventID, Valid: true}, m.EventID, "should have extracted event id")
+}
+
+func Test_inboundMessageFromKafkaMessageInvalidEventID(t *testing.T) {
+	m := inboundMessageFromKafkaMessage(kafka.Message{
+		Headers: []kafka.Header{
+			{Key: kafkaMessageEventIDHeader, Value: []byte("meow")},
+		},
+	})
+	assert.False(t, m.EventID.Valid, "should not have set event id")
+}
+
+func Test_inboundMessageFromKafkaMessageNoEventID(t *testing.T) {
+	m := inboundMessageFromKafkaMessage(kafka.Message{})
+	assert.False(t, m.EventID.Valid, "should not have set event id")
+}
+
+func TestKafkaMessageFromOutboundMessageEventID(t *testing.T) {
+	eventID := testutil.NewUUIDV4()
+	kafkaMessage, err := KafkaMessageFromOutboundMessage(OutboundMessage{
+		eventID:   eventID,
+		EventType: "dance",
+	})
+	require.NoError(t, err, "should not fail")
+	assert.Contains(t, kafkaMessage.Headers, kafka.Header{
+		Key:   kafkaMessageEventIDHeader,
+		Value: []byte(eventID.String()),
+	}, "should have added event id header")
+}
+
+func TestKafkaMessageFromOutboundMessageNoEventID(t *testing.T) {
+	kafkaMessage, err := KafkaMessageFromOutboundMessage(OutboundMessage{
+		EventType: "dance",
+	})
+	require.NoError(t, err, "should not fail")
+	for _, header := range kafkaMessage.Headers {
+		assert.NotEqual(t, kafkaMessageEventIDHeader, header.Key, "should not have added event id header")
+	}
+}
+
+func Test_inboundMessageFromKafkaMessageSchemaVersion(t *testing.T) {
+	m := inboundMessageFromKafkaMessage(kafka.Message{
+		Headers: []kafka.Header{
+			{Key: kafkaMessageEventSchemaVersionHeader, Value: []byte("3")},
+		},
+	})
+	assert.Equal(t, event.Version(3), m.SchemaVersion, "should have extracted schema version")
+}
+
+func Test_inboundMessageFromKafkaMessageInvalidSchemaVersion(t *testing.T) {
+	m := inboundMessageFromKafkaMessage(kafka.Message{
+		Headers: []kafka.Header{
+			{Key: kafkaMessageEventSchemaVersionHeader, Value: []byte("meow")},
+		},
+	})
+	assert.Equal(t, event.InitialVersion, m.SchemaVersion, "should fall back to initial version")
+}
+
+func Test_inboundMessageFromKafkaMessageNoSchemaVersion(t *testing.T) {
+	m := inboundMessageFromKafkaMessage(kafka.Message{})
+	assert.Equal(t, event.InitialVersion, m.SchemaVersion, "should fall back to initial version")
+}
+
+func TestKafkaMessageFromOutboundMessageSchemaVersion(t *testing.T) {
+	kafkaMessage, err := KafkaMessageFromOutboundMessage(OutboundMessage{
+		EventType: event.TypeUserCreated,
+	})
+	require.NoError(t, err, "should not fail")
+	version, _ := event.Schemas.Version(event.TypeUserCreated)
+	assert.Contains(t, kafkaMessage.Headers, kafka.Header{
+		Key:   kafkaMessageEventSchemaVersionHeader,
+		Value: []byte(strconv.Itoa(int(version))),
+	}, "should have added schema version header")
+}
+
+func TestKafkaMessageFromOutboundMessageUnregisteredSchema(t *testing.T) {
+	kafkaMessage, err := KafkaMessageFromOutboundMessage(OutboundMessage{
+		EventType: "dance",
+	})
+	require.NoError(t, err, "should not fail")
+	for _, header := range kafkaMessage.Headers {
+		assert.NotEqual(t, kafkaMessageEventSchemaVersionHeader, header.Key, "should not have added schema version header")
+	}
+}
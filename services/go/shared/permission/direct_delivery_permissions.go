@@ -0,0 +1,31 @@
+package permission
+
+// DeliverAnyDirectDeliveryPermissionName for DeliverAnyDirectDelivery.
+const DeliverAnyDirectDeliveryPermissionName Name = "direct-delivery.deliver.any"
+
+// DeliverAnyDirectDelivery allows delivering any direct delivery for
+// operations, the user is part of.
+func DeliverAnyDirectDelivery() Matcher {
+	return Matcher{
+		Name: "deliver-any-direct-delivery",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[DeliverAnyDirectDeliveryPermissionName]
+			return ok, nil
+		},
+	}
+}
+
+// ManageAnyDirectDeliveryPermissionName for ManageAnyDirectDelivery.
+const ManageAnyDirectDeliveryPermissionName Name = "direct-delivery.manage.any"
+
+// ManageAnyDirectDelivery allows releasing any direct delivery from
+// operations being picked up by anybody.
+func ManageAnyDirectDelivery() Matcher {
+	return Matcher{
+		Name: "manage-any-direct-delivery",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[ManageAnyDirectDeliveryPermissionName]
+			return ok, nil
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package permission
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestDeliverAnyDirectDelivery(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "deliver-any-direct-delivery",
+		Matcher:     DeliverAnyDirectDelivery(),
+		Granted:     DeliverAnyDirectDeliveryPermissionName,
+		Others: []Name{
+			UpdateAnyAddressBookEntryPermissionName,
+			DeleteAnyAddressBookEntryPermissionName,
+			CreateGroupPermissionName,
+			ManageAnyDirectDeliveryPermissionName,
+		},
+	})
+}
+
+func TestManageAnyDirectDelivery(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "manage-any-direct-delivery",
+		Matcher:     ManageAnyDirectDelivery(),
+		Granted:     ManageAnyDirectDeliveryPermissionName,
+		Others: []Name{
+			UpdateAnyAddressBookEntryPermissionName,
+			DeleteAnyAddressBookEntryPermissionName,
+			DeliverAnyDirectDeliveryPermissionName,
+			ViewAnyAddressBookEntryPermissionName,
+		},
+	})
+}
@@ -14,6 +14,20 @@ func CreateIntel() Matcher {
 	}
 }
 
+// AmendIntelPermissionName for AmendIntel.
+const AmendIntelPermissionName Name = "intelligence.intel.amend"
+
+// AmendIntel allows amending intel by creating new versions of it.
+func AmendIntel() Matcher {
+	return Matcher{
+		Name: "amend-intel",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[AmendIntelPermissionName]
+			return ok, nil
+		},
+	}
+}
+
 // InvalidateIntelPermissionName for InvalidateIntel.
 const InvalidateIntelPermissionName Name = "intelligence.intel.invalidate"
 
@@ -11,6 +11,22 @@ func TestCreateIntelPermission(t *testing.T) {
 		Matcher:     CreateIntel(),
 		Granted:     CreateIntelPermissionName,
 		Others: []Name{
+			AmendIntelPermissionName,
+			InvalidateIntelPermissionName,
+			ViewAnyIntelPermissionName,
+			CreateGroupPermissionName,
+			ViewAnyAddressBookEntryPermissionName,
+		},
+	})
+}
+
+func TestAmendIntel(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "amend-intel",
+		Matcher:     AmendIntel(),
+		Granted:     AmendIntelPermissionName,
+		Others: []Name{
+			CreateIntelPermissionName,
 			InvalidateIntelPermissionName,
 			ViewAnyIntelPermissionName,
 			CreateGroupPermissionName,
@@ -26,6 +42,7 @@ func TestInvalidateIntel(t *testing.T) {
 		Granted:     InvalidateIntelPermissionName,
 		Others: []Name{
 			CreateIntelPermissionName,
+			AmendIntelPermissionName,
 			ViewAnyIntelPermissionName,
 			CreateGroupPermissionName,
 			ViewAnyAddressBookEntryPermissionName,
@@ -40,6 +57,7 @@ func TestViewAnyIntel(t *testing.T) {
 		Granted:     ViewAnyIntelPermissionName,
 		Others: []Name{
 			CreateIntelPermissionName,
+			AmendIntelPermissionName,
 			InvalidateIntelPermissionName,
 			CreateGroupPermissionName,
 			ViewAnyAddressBookEntryPermissionName,
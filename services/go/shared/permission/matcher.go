@@ -1,6 +1,9 @@
 package permission
 
-import "github.com/lefinal/meh"
+import (
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+)
 
 // Matcher for a Permission list that checks, whether permissions are granted.
 type Matcher struct {
@@ -9,18 +12,33 @@ type Matcher struct {
 	// MatchFn matches the given Permission list against criteria and returns
 	// whether a permission was given or not.
 	MatchFn func(granted map[Name]Permission) (bool, error)
+	// operation is the id of the operation, permissions being limited to
+	// operations are considered for. Set via InOperation.
+	operation uuid.NullUUID
 }
 
-// Has checks if the given Permission was wanted.
-func Has(granted []Permission, toHave ...Matcher) (bool, error) {
-	// Build map.
+// match the given Permission list against the Matcher. Permissions being
+// limited to operations are only passed to Matcher.MatchFn if the limitation
+// includes Matcher.operation.
+func (matcher Matcher) match(granted []Permission) (bool, error) {
 	permissions := make(map[Name]Permission, len(granted))
 	for _, permission := range granted {
+		scope, err := operationScopeFromPermission(permission)
+		if err != nil {
+			return false, meh.Wrap(err, "operation scope from permission", nil)
+		}
+		if !scope.All && (!matcher.operation.Valid || !scope.Includes(matcher.operation.UUID)) {
+			continue
+		}
 		permissions[permission.Name] = permission
 	}
-	// Match.
+	return matcher.MatchFn(permissions)
+}
+
+// Has checks if the given Permission was wanted.
+func Has(granted []Permission, toHave ...Matcher) (bool, error) {
 	for _, matcher := range toHave {
-		ok, err := matcher.MatchFn(permissions)
+		ok, err := matcher.match(granted)
 		if err != nil {
 			return false, meh.Wrap(err, "match permission", meh.Details{"matcher_name": matcher.Name})
 		}
@@ -35,14 +53,8 @@ func Has(granted []Permission, toHave ...Matcher) (bool, error) {
 // returns not-ok, an meh.Forbidden error will be returned. If a Matcher fails,
 // an meh.ErrInternal will be returned.
 func Assure(granted []Permission, toHave ...Matcher) error {
-	// Build map.
-	permissions := make(map[Name]Permission, len(granted))
-	for _, permission := range granted {
-		permissions[permission.Name] = permission
-	}
-	// Match.
 	for i, matcher := range toHave {
-		ok, err := matcher.MatchFn(permissions)
+		ok, err := matcher.match(granted)
 		if err != nil {
 			return meh.ApplyCode(meh.Wrap(err, "match permission", meh.Details{
 				"matcher_name": matcher.Name,
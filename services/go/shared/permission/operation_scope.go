@@ -0,0 +1,219 @@
+package permission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/meh"
+	"github.com/lefinal/nulls"
+)
+
+// OperationScopeOptions are the options for permissions, that support being
+// limited to operations. Use OperationScoped for checking whether a permission
+// supports them.
+type OperationScopeOptions struct {
+	// Operations the permission is limited to. If not set, the permission is
+	// granted for all operations.
+	Operations []uuid.UUID `json:"operations"`
+}
+
+// operationScopedPermissions holds all permission names, that support
+// OperationScopeOptions.
+var operationScopedPermissions = map[Name]struct{}{
+	CreateIntelPermissionName:             {},
+	AmendIntelPermissionName:              {},
+	InvalidateIntelPermissionName:         {},
+	ViewAnyIntelPermissionName:            {},
+	ManageIntelDeliveryPermissionName:     {},
+	DeliverIntelPermissionName:            {},
+	ViewAnyOperationPermissionName:        {},
+	UpdateOperationPermissionName:         {},
+	ViewOperationMembersPermissionName:    {},
+	UpdateOperationMembersPermissionName:  {},
+	DeliverAnyRadioDeliveryPermissionName: {},
+	ManageAnyRadioDeliveryPermissionName:  {},
+}
+
+// OperationScoped checks whether the permission with the given Name supports
+// being limited to operations via OperationScopeOptions.
+func OperationScoped(name Name) bool {
+	_, ok := operationScopedPermissions[name]
+	return ok
+}
+
+// OperationScope describes the operations, a permission is granted for.
+type OperationScope struct {
+	// All describes whether the permission is granted for all operations. If set,
+	// Operations is empty.
+	All bool
+	// Operations the permission is granted for if not All.
+	Operations []uuid.UUID
+}
+
+// Includes checks whether the OperationScope includes the operation with the
+// given id.
+func (scope OperationScope) Includes(operationID uuid.UUID) bool {
+	if scope.All {
+		return true
+	}
+	for _, included := range scope.Operations {
+		if included == operationID {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty checks whether the OperationScope includes no operations at all.
+func (scope OperationScope) Empty() bool {
+	return !scope.All && len(scope.Operations) == 0
+}
+
+// operationScopeFromPermission parses the OperationScope from the options of
+// the given Permission. If the permission does not support OperationScopeOptions
+// or no operations are set, OperationScope.All is set.
+func operationScopeFromPermission(permission Permission) (OperationScope, error) {
+	if !OperationScoped(permission.Name) || !permission.Options.Valid ||
+		bytes.Equal(bytes.TrimSpace(permission.Options.RawMessage), []byte("null")) {
+		return OperationScope{All: true}, nil
+	}
+	var options OperationScopeOptions
+	err := json.Unmarshal(permission.Options.RawMessage, &options)
+	if err != nil {
+		return OperationScope{}, meh.NewInternalErrFromErr(err, "unmarshal operation scope options", meh.Details{
+			"permission_name": permission.Name,
+			"options":         string(permission.Options.RawMessage),
+		})
+	}
+	if options.Operations == nil {
+		return OperationScope{All: true}, nil
+	}
+	return OperationScope{Operations: options.Operations}, nil
+}
+
+// InOperation returns the given Matcher, that additionally considers
+// permissions being limited to the operation with the given id. Without
+// InOperation, only permissions granted for all operations are considered.
+func InOperation(operationID uuid.UUID, matcher Matcher) Matcher {
+	matcher.Name = fmt.Sprintf("%s-in-operation", matcher.Name)
+	matcher.operation = uuid.NullUUID{UUID: operationID, Valid: true}
+	return matcher
+}
+
+// GrantedOperations returns the OperationScope, the given Matcher matches the
+// granted Permission list for.
+func GrantedOperations(granted []Permission, matcher Matcher) (OperationScope, error) {
+	matcher.operation = uuid.NullUUID{}
+	ok, err := Has(granted, matcher)
+	if err != nil {
+		return OperationScope{}, meh.Wrap(err, "has permission for all operations", nil)
+	}
+	if ok {
+		return OperationScope{All: true}, nil
+	}
+	// Check each operation, any permission is limited to.
+	candidates := make([]uuid.UUID, 0)
+	candidatesSet := make(map[uuid.UUID]struct{})
+	for _, permission := range granted {
+		scope, err := operationScopeFromPermission(permission)
+		if err != nil {
+			return OperationScope{}, meh.Wrap(err, "operation scope from permission", nil)
+		}
+		for _, operationID := range scope.Operations {
+			if _, ok := candidatesSet[operationID]; ok {
+				continue
+			}
+			candidatesSet[operationID] = struct{}{}
+			candidates = append(candidates, operationID)
+		}
+	}
+	var scope OperationScope
+	for _, operationID := range candidates {
+		ok, err := Has(granted, InOperation(operationID, matcher))
+		if err != nil {
+			return OperationScope{}, meh.Wrap(err, "has permission in operation", meh.Details{"operation_id": operationID})
+		}
+		if ok {
+			scope.Operations = append(scope.Operations, operationID)
+		}
+	}
+	return scope, nil
+}
+
+// validateOperationScopeOptions assures, that options are only set for
+// permissions supporting OperationScopeOptions and that they are well-formed.
+func validateOperationScopeOptions(granted map[Name]Permission) ([]string, error) {
+	validationErrors := make([]string, 0)
+	for name, permission := range granted {
+		if !permission.Options.Valid {
+			continue
+		}
+		if !OperationScoped(name) {
+			validationErrors = append(validationErrors, fmt.Sprintf("permission %s does not support options", name))
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(permission.Options.RawMessage))
+		decoder.DisallowUnknownFields()
+		var options OperationScopeOptions
+		err := decoder.Decode(&options)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("invalid options for permission %s: %s", name, err.Error()))
+		}
+	}
+	return validationErrors, nil
+}
+
+// Merge merges the given Permission list so that each permission name is only
+// present once. The order of first occurrence is kept. For permissions
+// supporting OperationScopeOptions, the operations are united. If any of them
+// is granted for all operations, the merged one is as well. For all other
+// permissions, the first occurrence is used.
+func Merge(permissions []Permission) ([]Permission, error) {
+	merged := make([]Permission, 0, len(permissions))
+	indexByName := make(map[Name]int)
+	scopeByName := make(map[Name]OperationScope)
+	for _, permission := range permissions {
+		i, ok := indexByName[permission.Name]
+		if !ok {
+			indexByName[permission.Name] = len(merged)
+			merged = append(merged, permission)
+		}
+		if !OperationScoped(permission.Name) {
+			continue
+		}
+		scope, err := operationScopeFromPermission(permission)
+		if err != nil {
+			return nil, meh.Wrap(err, "operation scope from permission", meh.Details{"permission_name": permission.Name})
+		}
+		if !ok {
+			scopeByName[permission.Name] = scope
+			continue
+		}
+		// Unite with the already present one.
+		mergedScope := scopeByName[permission.Name]
+		switch {
+		case mergedScope.All:
+			continue
+		case scope.All:
+			mergedScope = OperationScope{All: true}
+			merged[i].Options = nulls.JSONRawMessage{}
+		default:
+			for _, operationID := range scope.Operations {
+				if !mergedScope.Includes(operationID) {
+					mergedScope.Operations = append(mergedScope.Operations, operationID)
+				}
+			}
+			if mergedScope.Operations == nil {
+				mergedScope.Operations = make([]uuid.UUID, 0)
+			}
+			raw, err := json.Marshal(OperationScopeOptions{Operations: mergedScope.Operations})
+			if err != nil {
+				return nil, meh.NewInternalErrFromErr(err, "marshal operation scope options", nil)
+			}
+			merged[i].Options = nulls.NewJSONRawMessage(raw)
+		}
+		scopeByName[permission.Name] = mergedScope
+	}
+	return merged, nil
+}
@@ -0,0 +1,306 @@
+package permission
+
+import (
+	"encoding/json"
+	"github.com/gofrs/uuid"
+	"github.com/lefinal/nulls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+// newOperationScopedPermission creates a Permission with the given name, that
+// is limited to the operations with the given ids.
+func newOperationScopedPermission(name Name, operations ...uuid.UUID) Permission {
+	if operations == nil {
+		operations = make([]uuid.UUID, 0)
+	}
+	raw, err := json.Marshal(OperationScopeOptions{Operations: operations})
+	if err != nil {
+		panic(err)
+	}
+	return Permission{
+		Name:    name,
+		Options: nulls.NewJSONRawMessage(raw),
+	}
+}
+
+func TestOperationScope_Includes(t *testing.T) {
+	operationID := uuid.Must(uuid.NewV4())
+	assert.True(t, OperationScope{All: true}.Includes(operationID), "should include for all")
+	assert.True(t, OperationScope{Operations: []uuid.UUID{uuid.Must(uuid.NewV4()), operationID}}.Includes(operationID),
+		"should include if in operations")
+	assert.False(t, OperationScope{Operations: []uuid.UUID{uuid.Must(uuid.NewV4())}}.Includes(operationID),
+		"should not include if not in operations")
+	assert.False(t, OperationScope{}.Includes(operationID), "should not include for empty")
+}
+
+func TestOperationScope_Empty(t *testing.T) {
+	assert.False(t, OperationScope{All: true}.Empty(), "should not be empty for all")
+	assert.False(t, OperationScope{Operations: []uuid.UUID{uuid.Must(uuid.NewV4())}}.Empty(),
+		"should not be empty with operations")
+	assert.True(t, OperationScope{Operations: []uuid.UUID{}}.Empty(), "should be empty without operations")
+}
+
+// InOperationSuite tests InOperation.
+type InOperationSuite struct {
+	suite.Suite
+	sampleOperation uuid.UUID
+}
+
+func (suite *InOperationSuite) SetupTest() {
+	suite.sampleOperation = uuid.Must(uuid.NewV4())
+}
+
+func (suite *InOperationSuite) TestMatcherName() {
+	suite.Equal("view-any-intel-in-operation", InOperation(suite.sampleOperation, ViewAnyIntel()).Name,
+		"should have correct matcher name")
+}
+
+func (suite *InOperationSuite) TestUnscopedWithoutInOperation() {
+	ok, err := Has([]Permission{{Name: ViewAnyIntelPermissionName}}, ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func (suite *InOperationSuite) TestUnscopedInOperation() {
+	ok, err := Has([]Permission{{Name: ViewAnyIntelPermissionName}}, InOperation(suite.sampleOperation, ViewAnyIntel()))
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func (suite *InOperationSuite) TestScopedWithoutInOperation() {
+	ok, err := Has([]Permission{newOperationScopedPermission(ViewAnyIntelPermissionName, suite.sampleOperation)},
+		ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not be granted")
+}
+
+func (suite *InOperationSuite) TestScopedInOtherOperation() {
+	ok, err := Has([]Permission{newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4()))},
+		InOperation(suite.sampleOperation, ViewAnyIntel()))
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not be granted")
+}
+
+func (suite *InOperationSuite) TestScopedInOperation() {
+	ok, err := Has([]Permission{newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4()), suite.sampleOperation)},
+		InOperation(suite.sampleOperation, ViewAnyIntel()))
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func (suite *InOperationSuite) TestEmptyScope() {
+	ok, err := Has([]Permission{newOperationScopedPermission(ViewAnyIntelPermissionName)},
+		InOperation(suite.sampleOperation, ViewAnyIntel()))
+	suite.Require().NoError(err, "should not fail")
+	suite.False(ok, "should not be granted")
+}
+
+func (suite *InOperationSuite) TestNullOptions() {
+	ok, err := Has([]Permission{{
+		Name:    ViewAnyIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(`{}`)),
+	}}, ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func (suite *InOperationSuite) TestInvalidOptions() {
+	_, err := Has([]Permission{{
+		Name:    ViewAnyIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(`{invalid`)),
+	}}, ViewAnyIntel())
+	suite.Error(err, "should fail")
+}
+
+func (suite *InOperationSuite) TestOptionsIgnoredForUnscopedPermission() {
+	ok, err := Has([]Permission{newOperationScopedPermission(CreateUserPermissionName, uuid.Must(uuid.NewV4()))},
+		CreateUser())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func (suite *InOperationSuite) TestCombinedMatcher() {
+	ok, err := Has([]Permission{newOperationScopedPermission(ManageIntelDeliveryPermissionName, suite.sampleOperation)},
+		InOperation(suite.sampleOperation, DeliverIntel()))
+	suite.Require().NoError(err, "should not fail")
+	suite.True(ok, "should be granted")
+}
+
+func TestInOperation(t *testing.T) {
+	suite.Run(t, new(InOperationSuite))
+}
+
+// GrantedOperationsSuite tests GrantedOperations.
+type GrantedOperationsSuite struct {
+	suite.Suite
+}
+
+func (suite *GrantedOperationsSuite) TestNone() {
+	scope, err := GrantedOperations([]Permission{{Name: CreateUserPermissionName}}, ViewAnyIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.True(scope.Empty(), "should return empty scope")
+}
+
+func (suite *GrantedOperationsSuite) TestAll() {
+	scope, err := GrantedOperations([]Permission{
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+		{Name: ManageIntelDeliveryPermissionName},
+	}, DeliverIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(OperationScope{All: true}, scope, "should return correct scope")
+}
+
+func (suite *GrantedOperationsSuite) TestScoped() {
+	operation1 := uuid.Must(uuid.NewV4())
+	operation2 := uuid.Must(uuid.NewV4())
+	operation3 := uuid.Must(uuid.NewV4())
+	scope, err := GrantedOperations([]Permission{
+		newOperationScopedPermission(DeliverIntelPermissionName, operation1, operation2),
+		newOperationScopedPermission(ManageIntelDeliveryPermissionName, operation2, operation3),
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+	}, DeliverIntel())
+	suite.Require().NoError(err, "should not fail")
+	suite.False(scope.All, "should not return all")
+	suite.ElementsMatch([]uuid.UUID{operation1, operation2, operation3}, scope.Operations,
+		"should return correct operations")
+}
+
+func (suite *GrantedOperationsSuite) TestInvalidOptions() {
+	_, err := GrantedOperations([]Permission{{
+		Name:    DeliverIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(`{invalid`)),
+	}}, DeliverIntel())
+	suite.Error(err, "should fail")
+}
+
+func TestGrantedOperations(t *testing.T) {
+	suite.Run(t, new(GrantedOperationsSuite))
+}
+
+// validateOperationScopeOptionsSuite tests validateOperationScopeOptions.
+type validateOperationScopeOptionsSuite struct {
+	suite.Suite
+}
+
+func (suite *validateOperationScopeOptionsSuite) validate(permissions ...Permission) []string {
+	validationErrors, err := Validate(permissions)
+	suite.Require().NoError(err, "should not fail")
+	return validationErrors
+}
+
+func (suite *validateOperationScopeOptionsSuite) TestNoOptions() {
+	suite.Empty(suite.validate(Permission{Name: ViewAnyIntelPermissionName}, Permission{Name: CreateUserPermissionName}),
+		"should not report errors")
+}
+
+func (suite *validateOperationScopeOptionsSuite) TestOK() {
+	suite.Empty(suite.validate(newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4()))),
+		"should not report errors")
+}
+
+func (suite *validateOperationScopeOptionsSuite) TestOptionsNotSupported() {
+	suite.Len(suite.validate(newOperationScopedPermission(CreateUserPermissionName, uuid.Must(uuid.NewV4()))), 1,
+		"should report error")
+}
+
+func (suite *validateOperationScopeOptionsSuite) TestUnknownField() {
+	suite.Len(suite.validate(Permission{
+		Name:    ViewAnyIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(`{"operation":[]}`)),
+	}), 1, "should report error")
+}
+
+func (suite *validateOperationScopeOptionsSuite) TestInvalidOperationID() {
+	suite.Len(suite.validate(Permission{
+		Name:    ViewAnyIntelPermissionName,
+		Options: nulls.NewJSONRawMessage(json.RawMessage(`{"operations":["meow"]}`)),
+	}), 1, "should report error")
+}
+
+func Test_validateOperationScopeOptions(t *testing.T) {
+	suite.Run(t, new(validateOperationScopeOptionsSuite))
+}
+
+// MergeSuite tests Merge.
+type MergeSuite struct {
+	suite.Suite
+}
+
+func (suite *MergeSuite) TestEmpty() {
+	got, err := Merge([]Permission{})
+	suite.Require().NoError(err, "should not fail")
+	suite.Empty(got, "should return empty list")
+}
+
+func (suite *MergeSuite) TestNoDuplicates() {
+	permissions := []Permission{
+		{Name: CreateUserPermissionName},
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+		{Name: ViewAnyOperationPermissionName},
+	}
+	got, err := Merge(permissions)
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal(permissions, got, "should return permissions unchanged")
+}
+
+func (suite *MergeSuite) TestDuplicatesWithoutOptions() {
+	got, err := Merge([]Permission{
+		{Name: CreateUserPermissionName},
+		{Name: ViewAnyOperationPermissionName},
+		{Name: CreateUserPermissionName},
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal([]Permission{
+		{Name: CreateUserPermissionName},
+		{Name: ViewAnyOperationPermissionName},
+	}, got, "should return correct value")
+}
+
+func (suite *MergeSuite) TestUniteOperations() {
+	operation1 := uuid.Must(uuid.NewV4())
+	operation2 := uuid.Must(uuid.NewV4())
+	operation3 := uuid.Must(uuid.NewV4())
+	got, err := Merge([]Permission{
+		newOperationScopedPermission(ViewAnyIntelPermissionName, operation1, operation2),
+		{Name: CreateUserPermissionName},
+		newOperationScopedPermission(ViewAnyIntelPermissionName, operation2, operation3),
+		newOperationScopedPermission(ViewAnyIntelPermissionName),
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal([]Permission{
+		newOperationScopedPermission(ViewAnyIntelPermissionName, operation1, operation2, operation3),
+		{Name: CreateUserPermissionName},
+	}, got, "should return correct value")
+}
+
+func (suite *MergeSuite) TestAllOperationsLater() {
+	got, err := Merge([]Permission{
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+		{Name: ViewAnyIntelPermissionName},
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal([]Permission{{Name: ViewAnyIntelPermissionName}}, got, "should return correct value")
+}
+
+func (suite *MergeSuite) TestAllOperationsFirst() {
+	got, err := Merge([]Permission{
+		{Name: ViewAnyIntelPermissionName},
+		newOperationScopedPermission(ViewAnyIntelPermissionName, uuid.Must(uuid.NewV4())),
+	})
+	suite.Require().NoError(err, "should not fail")
+	suite.Equal([]Permission{{Name: ViewAnyIntelPermissionName}}, got, "should return correct value")
+}
+
+func (suite *MergeSuite) TestInvalidOptions() {
+	_, err := Merge([]Permission{
+		{Name: ViewAnyIntelPermissionName, Options: nulls.NewJSONRawMessage([]byte(`{"operations":1}`))},
+	})
+	suite.Error(err, "should fail")
+}
+
+func TestMerge(t *testing.T) {
+	suite.Run(t, new(MergeSuite))
+}
@@ -0,0 +1,31 @@
+package permission
+
+// DeliverAnyPhoneCallDeliveryPermissionName for DeliverAnyPhoneCallDelivery.
+const DeliverAnyPhoneCallDeliveryPermissionName Name = "phone-call-delivery.deliver.any"
+
+// DeliverAnyPhoneCallDelivery allows delivering any phone call delivery for
+// operations, the user is part of.
+func DeliverAnyPhoneCallDelivery() Matcher {
+	return Matcher{
+		Name: "deliver-any-phone-call-delivery",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[DeliverAnyPhoneCallDeliveryPermissionName]
+			return ok, nil
+		},
+	}
+}
+
+// ManageAnyPhoneCallDeliveryPermissionName for ManageAnyPhoneCallDelivery.
+const ManageAnyPhoneCallDeliveryPermissionName Name = "phone-call-delivery.manage.any"
+
+// ManageAnyPhoneCallDelivery allows releasing any phone call delivery from
+// operations being picked up by anybody.
+func ManageAnyPhoneCallDelivery() Matcher {
+	return Matcher{
+		Name: "manage-any-phone-call-delivery",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[ManageAnyPhoneCallDeliveryPermissionName]
+			return ok, nil
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package permission
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestDeliverAnyPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "deliver-any-phone-call-delivery",
+		Matcher:     DeliverAnyPhoneCallDelivery(),
+		Granted:     DeliverAnyPhoneCallDeliveryPermissionName,
+		Others: []Name{
+			UpdateAnyAddressBookEntryPermissionName,
+			DeleteAnyAddressBookEntryPermissionName,
+			CreateGroupPermissionName,
+			ManageAnyPhoneCallDeliveryPermissionName,
+		},
+	})
+}
+
+func TestManageAnyPhoneCallDelivery(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "manage-any-phone-call-delivery",
+		Matcher:     ManageAnyPhoneCallDelivery(),
+		Granted:     ManageAnyPhoneCallDeliveryPermissionName,
+		Others: []Name{
+			UpdateAnyAddressBookEntryPermissionName,
+			DeleteAnyAddressBookEntryPermissionName,
+			DeliverAnyPhoneCallDeliveryPermissionName,
+			ViewAnyAddressBookEntryPermissionName,
+		},
+	})
+}
@@ -85,3 +85,33 @@ func ViewUser() Matcher {
 		},
 	}
 }
+
+// ManageAnyUserSessionsPermissionName for ManageAnyUserSessions.
+const ManageAnyUserSessionsPermissionName Name = "user.sessions.manage-any"
+
+// ManageAnyUserSessions allows listing and revoking sessions of other users.
+// Of course, a user can always list and revoke its own sessions.
+func ManageAnyUserSessions() Matcher {
+	return Matcher{
+		Name: "manage-any-user-sessions",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[ManageAnyUserSessionsPermissionName]
+			return ok, nil
+		},
+	}
+}
+
+// UnlockUserLoginPermissionName for UnlockUserLogin.
+const UnlockUserLoginPermissionName Name = "user.login.unlock"
+
+// UnlockUserLogin allows lifting login lockouts that were imposed because of
+// too many failed login attempts.
+func UnlockUserLogin() Matcher {
+	return Matcher{
+		Name: "unlock-user-login",
+		MatchFn: func(granted map[Name]Permission) (bool, error) {
+			_, ok := granted[UnlockUserLoginPermissionName]
+			return ok, nil
+		},
+	}
+}
@@ -19,6 +19,8 @@ func TestCreateUser(t *testing.T) {
 			SetAdminUserPermissionName,
 			ViewUserPermissionName,
 			UpdateUserPassPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
 		},
 	})
 }
@@ -37,6 +39,8 @@ func TestSetUserActiveState(t *testing.T) {
 			SetAdminUserPermissionName,
 			ViewUserPermissionName,
 			UpdateUserPassPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
 		},
 	})
 }
@@ -55,6 +59,8 @@ func TestUpdateUser(t *testing.T) {
 			SetAdminUserPermissionName,
 			ViewUserPermissionName,
 			UpdateUserPassPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
 		},
 	})
 }
@@ -73,6 +79,8 @@ func TestSetAdminUser(t *testing.T) {
 			UpdateUserPermissionName,
 			ViewUserPermissionName,
 			UpdateUserPassPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
 		},
 	})
 }
@@ -91,6 +99,8 @@ func TestUpdateUserPass(t *testing.T) {
 			UpdateUserPermissionName,
 			ViewUserPermissionName,
 			SetAdminUserPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
 		},
 	})
 }
@@ -109,6 +119,48 @@ func TestViewUser(t *testing.T) {
 			UpdateUserPermissionName,
 			UpdateUserPassPermissionName,
 			SetAdminUserPermissionName,
+			ManageAnyUserSessionsPermissionName,
+			UnlockUserLoginPermissionName,
+		},
+	})
+}
+
+func TestManageAnyUserSessions(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "manage-any-user-sessions",
+		Matcher:     ManageAnyUserSessions(),
+		Granted:     ManageAnyUserSessionsPermissionName,
+		Others: []Name{
+			UpdateGroupPermissionName,
+			CreateOperationPermissionName,
+			UpdateOperationPermissionName,
+			SetUserActiveStatePermission,
+			CreateUserPermissionName,
+			UpdateUserPermissionName,
+			UpdateUserPassPermissionName,
+			SetAdminUserPermissionName,
+			ViewUserPermissionName,
+			UnlockUserLoginPermissionName,
+		},
+	})
+}
+
+func TestUnlockUserLogin(t *testing.T) {
+	suite.Run(t, &NameMatcherSuite{
+		MatcherName: "unlock-user-login",
+		Matcher:     UnlockUserLogin(),
+		Granted:     UnlockUserLoginPermissionName,
+		Others: []Name{
+			UpdateGroupPermissionName,
+			CreateOperationPermissionName,
+			UpdateOperationPermissionName,
+			SetUserActiveStatePermission,
+			CreateUserPermissionName,
+			UpdateUserPermissionName,
+			UpdateUserPassPermissionName,
+			SetAdminUserPermissionName,
+			ViewUserPermissionName,
+			ManageAnyUserSessionsPermissionName,
 		},
 	})
 }
@@ -29,5 +29,7 @@ func Validate(granted []Permission) ([]string, error) {
 
 // validators provides a central location to for registering validators.
 func validators() map[string]validator {
-	return map[string]validator{}
+	return map[string]validator{
+		"operation-scope-options": validateOperationScopeOptions,
+	}
 }
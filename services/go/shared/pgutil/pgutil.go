@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -44,8 +45,40 @@ type DBTxSupplier interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+// afterCommitHooks holds the functions, registered via AfterCommit, for a
+// transaction in RunInTx.
+type afterCommitHooks struct {
+	m     sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+// afterCommitHooksByTx holds the afterCommitHooks by the pgx.Tx of running
+// RunInTx calls.
+var afterCommitHooksByTx sync.Map
+
+// AfterCommit registers the given function to be called after the given
+// transaction of RunInTx was committed successfully. This is useful for actions
+// that must not be visible to others before the changes of the transaction,
+// like invalidating caches or waking up workers. Functions are called in order
+// of registration with the context, that was passed to RunInTx. If the
+// transaction is rolled back, they are not called. If the transaction does not
+// originate from RunInTx, the function is called immediately with the given
+// context.
+func AfterCommit(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context)) {
+	hooksRaw, ok := afterCommitHooksByTx.Load(tx)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	hooks := hooksRaw.(*afterCommitHooks)
+	hooks.m.Lock()
+	defer hooks.m.Unlock()
+	hooks.hooks = append(hooks.hooks, fn)
+}
+
 // RunInTx is a transaction wrapper for the given function, that needs
 // isolation. If function execution fails, the transaction is rolled back.
+// Functions, registered via AfterCommit, are called after successful commit.
 func RunInTx(ctx context.Context, txSupplier DBTxSupplier, fn func(ctx context.Context, tx pgx.Tx) error) error {
 	// Begin tx.
 	tx, err := txSupplier.Begin(ctx)
@@ -53,6 +86,9 @@ func RunInTx(ctx context.Context, txSupplier DBTxSupplier, fn func(ctx context.C
 		return meh.NewInternalErrFromErr(err, "begin tx", nil)
 	}
 	// Run stuff.
+	hooks := &afterCommitHooks{}
+	afterCommitHooksByTx.Store(tx, hooks)
+	defer afterCommitHooksByTx.Delete(tx)
 	err = fn(ctx, tx)
 	if err != nil {
 		// Rollback.
@@ -84,6 +120,11 @@ func RunInTx(ctx context.Context, txSupplier DBTxSupplier, fn func(ctx context.C
 		}
 		return meh.Wrap(err, "commit tx", details)
 	}
+	hooks.m.Lock()
+	defer hooks.m.Unlock()
+	for _, hook := range hooks.hooks {
+		hook(ctx)
+	}
 	return nil
 }
 
@@ -0,0 +1,64 @@
+package pgutil_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/pgutil"
+	"github.com/mobile-directing-system/mds-server/services/go/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAfterCommitOutsideTx(t *testing.T) {
+	called := false
+	pgutil.AfterCommit(context.Background(), &testutil.DBTx{}, func(_ context.Context) {
+		called = true
+	})
+	assert.True(t, called, "should call immediately")
+}
+
+func TestAfterCommitFnFail(t *testing.T) {
+	txSupplier := &testutil.DBTxSupplier{Tx: []*testutil.DBTx{{}}}
+	called := false
+	err := pgutil.RunInTx(context.Background(), txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		pgutil.AfterCommit(ctx, tx, func(_ context.Context) {
+			called = true
+		})
+		return errors.New("sad life")
+	})
+	assert.Error(t, err, "should fail")
+	assert.False(t, called, "should not call hook")
+}
+
+func TestAfterCommitCommitFail(t *testing.T) {
+	txSupplier := &testutil.DBTxSupplier{Tx: []*testutil.DBTx{{CommitFail: true}}}
+	called := false
+	err := pgutil.RunInTx(context.Background(), txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		pgutil.AfterCommit(ctx, tx, func(_ context.Context) {
+			called = true
+		})
+		return nil
+	})
+	assert.Error(t, err, "should fail")
+	assert.False(t, called, "should not call hook")
+}
+
+func TestAfterCommit(t *testing.T) {
+	committedTx := &testutil.DBTx{}
+	txSupplier := &testutil.DBTxSupplier{Tx: []*testutil.DBTx{committedTx}}
+	calls := make([]int, 0)
+	err := pgutil.RunInTx(context.Background(), txSupplier, func(ctx context.Context, tx pgx.Tx) error {
+		pgutil.AfterCommit(ctx, tx, func(_ context.Context) {
+			assert.True(t, committedTx.IsCommitted, "should be committed before calling hook")
+			calls = append(calls, 1)
+		})
+		pgutil.AfterCommit(ctx, tx, func(_ context.Context) {
+			calls = append(calls, 2)
+		})
+		assert.Empty(t, calls, "should not call hooks before commit")
+		return nil
+	})
+	assert.NoError(t, err, "should not fail")
+	assert.Equal(t, []int{1, 2}, calls, "should call hooks in order")
+}
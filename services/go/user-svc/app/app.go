@@ -81,7 +81,7 @@ func Run(ctx context.Context) error {
 		return meh.Wrap(err, "await ready", nil)
 	}
 	// Setup.
-	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB)
+	kafkaConnector, err := kafkautil.InitNewConnector(ctx, logger.Named("kafka-connector"), sqlDB, c.KafkaConnector)
 	if err != nil {
 		return meh.Wrap(err, "init new kafka connector", nil)
 	}
@@ -114,7 +114,7 @@ func Run(ctx context.Context) error {
 	})
 	// Serve endpoints.
 	eg.Go(func() error {
-		err = endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl)
+		err = endpoints.Serve(egCtx, logger.Named("endpoints"), c.ServeAddr, c.AuthTokenSecret, ctrl, kafkaConnector.DeadLetterAdmin(sqlDB))
 		if err != nil {
 			return meh.Wrap(err, "serve endpoints", meh.Details{"serve_addr": c.ServeAddr})
 		}
@@ -130,6 +130,14 @@ func Run(ctx context.Context) error {
 		}
 		return nil
 	})
+	// Run Kafka connector janitor.
+	eg.Go(func() error {
+		err := kafkaConnector.RunJanitor(egCtx, sqlDB)
+		if err != nil {
+			return meh.Wrap(err, "run janitor", nil)
+		}
+		return nil
+	})
 	startUpCompleted(readyCheck)
 	return eg.Wait()
 }
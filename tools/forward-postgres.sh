@@ -27,6 +27,9 @@ forwardPostgreSQLInBackground "service/mds-logistics-svc-postgres-service"
 forwardPostgreSQLInBackground "service/mds-in-app-notifier-svc-postgres-service"
 forwardPostgreSQLInBackground "service/mds-radio-delivery-svc-postgres-service"
 forwardPostgreSQLInBackground "service/mds-open-intel-delivery-notif-postgres-service"
+forwardPostgreSQLInBackground "service/mds-email-delivery-svc-postgres-service"
+forwardPostgreSQLInBackground "service/mds-phone-call-delivery-svc-postgres-service"
+forwardPostgreSQLInBackground "service/mds-direct-delivery-svc-postgres-service"
 
 sleep 1
 echo "*********************************"